@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	analyzeJSON bool // Print the analysis as JSON instead of human-readable output
+)
+
+// analyzeCmd represents the analyze command
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze [file]",
+	Short: "Analyze a video without compressing it",
+	Long: `Analyze a video file and show the detected content type, motion
+complexity, recommended compression settings and estimated output size
+without compressing anything.
+
+Examples:
+  compressvideo analyze input.mp4
+  compressvideo analyze -i input.mp4 -q 4 --json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+		return analyzeCommand(cmd)
+	},
+}
+
+// analyzeOutput is the JSON document printed by the analyze command
+type analyzeOutput struct {
+	File                 string                   `json:"file"`
+	Format               string                   `json:"format"`
+	Size                 int64                    `json:"size"`
+	Duration             float64                  `json:"duration"`
+	BitRate              int64                    `json:"bitrate"`
+	Video                ffmpeg.VideoStreamInfo   `json:"video"`
+	Audio                []ffmpeg.AudioStreamInfo `json:"audio"`
+	ContentType          string                   `json:"content_type"`
+	MotionComplexity     string                   `json:"motion_complexity"`
+	SceneChanges         int                      `json:"scene_changes"`
+	FrameComplexity      float64                  `json:"frame_complexity"`
+	SpatialComplexity    float64                  `json:"spatial_complexity"`
+	CompressionPotential int                      `json:"compression_potential"`
+	RecommendedCodec     string                   `json:"recommended_codec"`
+	OptimalBitrate       int64                    `json:"optimal_bitrate"`
+	SourceBitrate        *analyzer.BitrateStats   `json:"source_bitrate,omitempty"`
+	AnalysisParams       analyzer.AnalysisParams  `json:"analysis_params"`
+	Settings             map[string]string        `json:"settings"`
+	EstimatedSize        int64                    `json:"estimated_size"`
+	EstimatedSavings     float64                  `json:"estimated_savings_percent"`
+	Decisions            []analyzer.Decision      `json:"decisions,omitempty"`
+}
+
+func init() {
+	rootCmd.AddCommand(analyzeCmd)
+
+	analyzeCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input video file")
+	analyzeCmd.Flags().IntVarP(&quality, "quality", "q", 3, "Quality level used for the recommended settings (1-5)")
+	analyzeCmd.Flags().BoolVar(&analyzeJSON, "json", false, "Print the analysis as JSON")
+	analyzeCmd.Flags().StringVar(&hwaccel, "hwaccel", "", "Decode on the GPU during analysis (cuda, qsv, vaapi)")
+	analyzeCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+}
+
+// analyzeCommand runs GetVideoInfo and AnalyzeVideo and prints the results
+func analyzeCommand(cmd *cobra.Command) error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	if analyzeJSON {
+		// Keep stdout clean for the JSON document; errors still go to stderr
+		logger.SetLevel(util.LogLevelError)
+	} else {
+		logger.Title("CompressVideo - Video Analysis")
+	}
+
+	if inputFile == "" {
+		return withExitCode(exitBadInput, i18n.Errorf("an input file is required (compressvideo analyze <file>)"))
+	}
+	if ffmpeg.IsRemote(inputFile) {
+		// FFmpeg reads URLs directly
+	} else if stat, err := os.Stat(inputFile); err != nil {
+		return withExitCode(exitBadInput, i18n.Errorf("input file does not exist: %s", inputFile))
+	} else if stat.IsDir() {
+		return withExitCode(exitBadInput, i18n.Errorf("input must be a file, not a directory: %s", inputFile))
+	}
+	if quality < 1 || quality > 5 {
+		return withExitCode(exitBadInput, i18n.Errorf("quality must be between 1-5 (got %d)", quality))
+	}
+	if !ffmpeg.ValidHWAccel(hwaccel) {
+		return withExitCode(exitBadInput, i18n.Errorf("hwaccel must be one of: cuda, qsv, vaapi (got %s)", hwaccel))
+	}
+	if err := loadAnalysisParams(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+
+	ffmpegInstance := ffmpeg.NewFFmpeg(inputFile, "", nil, logger)
+	ffmpegInstance.Options.HWAccel = hwaccel
+	contentAnalyzer := analyzer.NewContentAnalyzer(ffmpegInstance, logger)
+	contentAnalyzer.Params = analysisParams
+	contentAnalyzer.Explain = explain
+
+	videoFile, err := ffmpegInstance.GetVideoInfo(inputFile)
+	if err != nil {
+		return withExitCode(exitBadInput, i18n.Errorf("failed to get video info: %v", err))
+	}
+
+	analysis, err := contentAnalyzer.AnalyzeVideo(videoFile)
+	if err != nil {
+		return i18n.Errorf("failed to analyze video: %v", err)
+	}
+
+	settings, err := contentAnalyzer.GetCompressionSettings(analysis, quality)
+	if err != nil {
+		return i18n.Errorf("failed to determine compression settings: %v", err)
+	}
+
+	estimatedSize := analyzer.EstimateOutputSize(analysis, settings, compressor.MappedAudio(settings, videoFile.AudioInfo))
+	savings := 0.0
+	if videoFile.Size > 0 && estimatedSize > 0 {
+		savings = (1 - float64(estimatedSize)/float64(videoFile.Size)) * 100
+	}
+
+	if analyzeJSON {
+		output := analyzeOutput{
+			File:                 videoFile.Path,
+			Format:               videoFile.Format,
+			Size:                 videoFile.Size,
+			Duration:             videoFile.Duration,
+			BitRate:              videoFile.BitRate,
+			Video:                videoFile.VideoInfo,
+			Audio:                videoFile.AudioInfo,
+			ContentType:          analysis.ContentType.String(),
+			MotionComplexity:     analysis.MotionComplexity.String(),
+			SceneChanges:         analysis.SceneChanges,
+			FrameComplexity:      analysis.FrameComplexity,
+			SpatialComplexity:    analysis.SpatialComplexity,
+			CompressionPotential: analysis.CompressionPotential,
+			RecommendedCodec:     analysis.RecommendedCodec,
+			OptimalBitrate:       analysis.OptimalBitrate,
+			SourceBitrate:        analysis.Bitrate,
+			AnalysisParams:       analysis.Params,
+			Settings:             settings,
+			EstimatedSize:        estimatedSize,
+			EstimatedSavings:     savings,
+			Decisions:            contentAnalyzer.Decisions(),
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(output)
+	}
+
+	displayVideoInfo(videoFile)
+	displayAnalysisResults(analysis)
+
+	logger.Section("Recommended Settings")
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		logger.Field(key, "%s", settings[key])
+	}
+
+	if decisions := contentAnalyzer.Decisions(); len(decisions) > 0 {
+		logger.Section("Decisions")
+		for _, decision := range decisions {
+			logger.Info("%s", decision)
+		}
+	}
+
+	logger.Section("Estimate")
+	if estimatedSize > 0 {
+		logger.Field("Estimated Output Size", "%s", formatSize(estimatedSize))
+		logger.Field("Estimated Savings", "%.1f%%", savings)
+	} else {
+		logger.Field("Estimated Output Size", "unknown (duration not available)")
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchResultsPath string // Where the results manifest is written
+)
+
+// batchCmd represents the batch command
+var batchCmd = &cobra.Command{
+	Use:   "batch [manifest]",
+	Short: "Compress the files listed in a YAML or JSON manifest",
+	Long: `Compress every file listed in a YAML or JSON manifest, with per-file
+quality, preset and output overrides, and write a results manifest.
+
+Example manifest:
+  defaults:
+    quality: 3
+    preset: balanced
+    output_dir: compressed
+  jobs:
+    - input: talks/keynote.mp4
+      quality: 4
+    - input: raw/interview.mov
+      output: archive/interview.mp4
+      preset: thorough
+      target_vmaf: 93
+
+Relative paths are resolved against the manifest's directory. Results are
+written to <manifest>.results.yaml (or .json) unless --results is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return batchCommand(cmd, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().StringVar(&batchResultsPath, "results", "", "Results manifest path (default: <manifest>.results.<ext>)")
+	batchCmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite outputs that already exist")
+	batchCmd.Flags().StringVar(&nameTemplate, "name-template", batch.DefaultNameTemplate, "Name template for jobs without an output, with {name}, {ext}, {codec}, {quality} and {preset}")
+	batchCmd.Flags().BoolVarP(&useCache, "use-cache", "c", false, "Use the analysis cache")
+	batchCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+	batchCmd.Flags().DurationVar(&timeoutPerFile, "timeout-per-file", 0, "Stop FFmpeg when one job takes longer than this (e.g. 2h), record it as failed and move on (0 = no limit)")
+	batchCmd.Flags().Float64Var(&wattsPerCore, "watts-per-core", compressor.DefaultWattsPerCore, "Power one busy CPU core draws, for the energy estimates of the jobs (e.g. 5 for a laptop, 15 for a desktop)")
+	batchCmd.Flags().DurationVar(&maxRuntime, "max-runtime", 0, "Stop the whole batch after this long (e.g. 8h); jobs not started are recorded as skipped (0 = no limit)")
+}
+
+// batchCommand runs every job of the manifest through the job queue and
+// records the outcome of each one
+func batchCommand(cmd *cobra.Command, manifestPath string) error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Batch")
+
+	if err := batch.ValidateNameTemplate(nameTemplate); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := validateTimeouts(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := validateWattsPerCore(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := validateRetry(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := validateSchedule(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := validateThrottle(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := loadAnalysisParams(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	jobs, err := batch.LoadManifest(manifestPath, nameTemplate)
+	if err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
+	// Validate every job before starting, so a typo does not stop the run halfway
+	for _, job := range jobs {
+		if job.Quality < 1 || job.Quality > 5 {
+			return withExitCode(exitBadInput, i18n.Errorf("%s: quality must be between 1-5 (got %d)", job.Input, job.Quality))
+		}
+		if job.Preset != "fast" && job.Preset != "balanced" && job.Preset != "thorough" {
+			return withExitCode(exitBadInput, i18n.Errorf("%s: preset must be one of: fast, balanced, thorough (got %s)", job.Input, job.Preset))
+		}
+		if job.TargetVMAF < 0 || job.TargetVMAF > 100 {
+			return withExitCode(exitBadInput, i18n.Errorf("%s: target VMAF must be between 0-100 (got %.1f)", job.Input, job.TargetVMAF))
+		}
+	}
+
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+	startRunDeadline()
+	stopPauseControls := startPauseControls()
+	defer stopPauseControls()
+	stopSchedule := startSchedule()
+	defer stopSchedule()
+	stopThrottle := startThrottle()
+	defer stopThrottle()
+	stopBatteryWatch := startBatteryWatch()
+	defer stopBatteryWatch()
+
+	setupNotifier(cmd)
+	stopMetrics := startMetrics()
+	defer stopMetrics()
+
+	var videoCache *cache.VideoAnalysisCache
+	if useCache {
+		videoCache, err = cache.NewVideoAnalysisCache(logger)
+		if err != nil {
+			logger.Warning("Failed to initialize cache: %v", err)
+			logger.Warning("Continuing without cache...")
+			useCache = false
+		} else {
+			defer videoCache.Close()
+		}
+	}
+	// Record the compressions in the ledger even without -c
+	if videoCache == nil {
+		if videoCache = openLedger(); videoCache != nil {
+			defer videoCache.Close()
+		}
+	}
+
+	resultsPath := batchResultsPath
+	if resultsPath == "" {
+		resultsPath = batch.ResultsPath(manifestPath)
+	}
+
+	results := make([]batch.JobResult, 0, len(jobs))
+	failedCount := 0
+	var firstErr error
+	batchStart := time.Now()
+	startCPUTime := runCPUTime
+	batchResults = nil
+	defer func() { batchPosition = "" }()
+
+	notStarted := 0
+	for i, job := range jobs {
+		// Out of time: the remaining jobs are left for the next run
+		if runDeadlineReached() {
+			if notStarted == 0 {
+				logger.Warning("--max-runtime of %s reached, %d files were not processed", maxRuntime, len(jobs)-i)
+			}
+			notStarted++
+			results = append(results, batch.JobResult{Input: job.Input, Output: job.Output, Quality: job.Quality,
+				Preset: job.Preset, Status: batch.StatusSkipped, Error: i18n.T("not started before --max-runtime")})
+			continue
+		}
+		batchPosition = fmt.Sprintf("%d/%d", i+1, len(jobs))
+		setQueueDepth(len(jobs) - i - 1)
+
+		// Each job runs with its own overrides
+		quality, preset, targetVMAF = job.Quality, job.Preset, job.TargetVMAF
+		result := batch.JobResult{Input: job.Input, Output: job.Output, Quality: job.Quality, Preset: job.Preset}
+
+		logger.Section(i18n.T("Job %s: %s", batchPosition, filepath.Base(job.Input)))
+		if _, exists := batch.ExistingOutput(job.Output); exists && !force {
+			logger.Warning("Skipping %s: output file already exists (use -f to force overwrite)", job.Input)
+			result.Status = batch.StatusSkipped
+			results = append(results, result)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(job.Output), 0755); err != nil {
+			logger.Error("Failed to process %s: %v", job.Input, err)
+			result.Status = batch.StatusFailed
+			result.Error = err.Error()
+			failedCount++
+			results = append(results, result)
+			continue
+		}
+
+		start, jobCPUTime := time.Now(), runCPUTime
+		lastResult = nil
+		attempts, err := runJobWithRetries(job.Input, job.Output, videoCache)
+		if attempts > 1 {
+			result.Attempts = attempts
+		}
+		result.Duration = time.Since(start).Seconds()
+		if cpuTime := runCPUTime - jobCPUTime; cpuTime > 0 {
+			result.CPUSeconds = cpuTime.Seconds()
+			result.EnergyWh = compressor.EstimateEnergy(cpuTime, wattsPerCore)
+		}
+		if err != nil {
+			logger.Error("Failed to process %s: %v", job.Input, err)
+			result.Status = batch.StatusFailed
+			result.Error = err.Error()
+			result.ErrorClass = errorClass(err)
+			result.Quarantined = quarantineSource(job.Input, filepath.Dir(job.Input), attempts, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failedCount++
+		} else {
+			result.Status = batch.StatusSuccess
+			if lastResult != nil {
+				result.Output = lastResult.OutputFile
+				result.OriginalSize = lastResult.OriginalSize
+				result.CompressedSize = lastResult.CompressedSize
+				result.SavedPercent = lastResult.SavedSpacePercent
+				result.VMAFScore = lastResult.VMAFScore
+				if lastResult.HardwareFallback != nil {
+					result.HardwareFallback = lastResult.HardwareFallback.String()
+				}
+				if lastResult.Recovery != nil {
+					result.Recovered = lastResult.Recovery.String()
+				}
+			}
+		}
+		results = append(results, result)
+	}
+
+	notifyBatch(manifestPath, failedCount, batchStart)
+	logRunCPUTime(startCPUTime)
+
+	if err := batch.WriteResults(resultsPath, results); err != nil {
+		return i18n.Errorf("failed to write results manifest: %w", err)
+	}
+	logger.Info("Results saved to: %s", resultsPath)
+
+	if failedCount > 0 {
+		return withExitCode(exitCode(firstErr), i18n.Errorf("%d of %d jobs failed", failedCount, len(jobs)))
+	}
+	if notStarted > 0 {
+		return withExitCode(exitTimedOut, i18n.Errorf("%d of %d files were not processed before --max-runtime", notStarted, len(jobs)))
+	}
+	logger.Success("Processed %d video files", len(jobs))
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pauseOnBattery bool // Pause encoding while the machine runs on battery
+	slowOnBattery  bool // Encode one segment at a time while the machine runs on battery
+)
+
+// pauseBattery is the reason of the pauses of --pause-on-battery
+const pauseBattery = "battery"
+
+// batteryCheckInterval is how often the power source is checked
+var batteryCheckInterval = 30 * time.Second
+
+func init() {
+	for _, command := range []*cobra.Command{rootCmd, batchCmd} {
+		command.Flags().BoolVar(&pauseOnBattery, "pause-on-battery", false, "Pause encoding while a laptop runs on battery, and resume where it stopped when it is plugged in again")
+		command.Flags().BoolVar(&slowOnBattery, "slow-on-battery", false, "Encode long videos one segment at a time while a laptop runs on battery, instead of in parallel")
+	}
+}
+
+// startBatteryWatch checks the power source every batteryCheckInterval and
+// pauses or slows the encodes while the machine runs on battery, as
+// --pause-on-battery and --slow-on-battery ask. Call it after startThrottle,
+// whose throttle it shares. The returned function stops it.
+func startBatteryWatch() (stop func()) {
+	if !pauseOnBattery && !slowOnBattery {
+		return func() {}
+	}
+	if _, err := util.OnBattery(); err != nil {
+		logger.Warning("The power source cannot be read on this system, --pause-on-battery and --slow-on-battery have no effect")
+		return func() {}
+	}
+	if slowOnBattery && encodeThrottle == nil {
+		encodeThrottle = &compressor.Throttle{}
+	}
+
+	unplugged := false
+	check := func() {
+		onBattery, err := util.OnBattery()
+		if err != nil || onBattery == unplugged {
+			return
+		}
+		unplugged = onBattery
+		if onBattery {
+			if pauseOnBattery {
+				if err := ffmpeg.Hold(pauseBattery); err != nil {
+					logger.Warning("Could not pause every FFmpeg process: %v", err)
+				}
+				logger.Info("Running on battery, encoding paused until the power is plugged in")
+			} else {
+				encodeThrottle.SetCap(1)
+				logger.Info("Running on battery, encoding one segment at a time")
+			}
+			return
+		}
+		if pauseOnBattery {
+			ffmpeg.Release(pauseBattery)
+		} else {
+			encodeThrottle.SetCap(0)
+		}
+		logger.Info("Power plugged in, encoding at full speed again")
+	}
+	check()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(batteryCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		ffmpeg.Release(pauseBattery)
+	}
+}
@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/bot"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	telegramToken   string   // Token of the Telegram bot
+	telegramAPI     string   // Address of the Telegram Bot API
+	discordToken    string   // Token of the Discord bot
+	discordChannels []string // Discord channels the bot watches
+	botAllow        []string // Chats and users allowed to use the bot, everyone when empty
+	botUploadLimit  string   // Size the replies must fit, instead of the platform's limit
+)
+
+// botAudioBitrate is the audio bitrate of the videos the bot sends back, in
+// bits/s, so the video gets most of the size limit
+const botAudioBitrate = 96000
+
+// botRetryDelay is how long the bot waits after a failed poll
+var botRetryDelay = 30 * time.Second
+
+// botCmd compresses the videos sent to a chat bot
+var botCmd = &cobra.Command{
+	Use:   "bot",
+	Short: "Compress the videos sent to a Telegram or Discord bot and reply with the result",
+	Long: `Run a Telegram or Discord bot that compresses the videos it is sent and
+replies with the compressed file, encoded to fit the platform's upload
+limit: 50 MB on Telegram and 10 MB on Discord, or --upload-limit.
+
+Telegram: create a bot with @BotFather and pass its token with
+--telegram-token. Bots can only fetch files up to 20 MB from Telegram's
+servers; a local Bot API server (--telegram-api) lifts that limit.
+
+Discord: create a bot with the Message Content intent, invite it with the
+Read Message History and Attach Files permissions, and pass its token with
+--discord-token and the channels to watch with --discord-channel.
+
+The tokens can also be set in COMPRESSVIDEO_TELEGRAM_TOKEN and
+COMPRESSVIDEO_DISCORD_TOKEN, which keeps them out of the process list.
+Anyone who can message the bot can use the machine's CPU, so limit it to
+your chats or users with --allow.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return botCommand(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(botCmd)
+
+	botCmd.Flags().StringVar(&telegramToken, "telegram-token", os.Getenv("COMPRESSVIDEO_TELEGRAM_TOKEN"), "Token of the Telegram bot, from @BotFather")
+	botCmd.Flags().StringVar(&telegramAPI, "telegram-api", bot.TelegramAPI, "Address of the Telegram Bot API, e.g. a local Bot API server for files over 20 MB")
+	botCmd.Flags().StringVar(&discordToken, "discord-token", os.Getenv("COMPRESSVIDEO_DISCORD_TOKEN"), "Token of the Discord bot")
+	botCmd.Flags().StringSliceVar(&discordChannels, "discord-channel", nil, "ID of a Discord channel to take videos from (repeatable)")
+	botCmd.Flags().StringSliceVar(&botAllow, "allow", nil, "Only compress videos from these chat, channel or user IDs (repeatable; default: everyone)")
+	botCmd.Flags().StringVar(&botUploadLimit, "upload-limit", "", "Size the compressed videos must fit, e.g. 25M for a boosted Discord server (default: the platform's limit)")
+	botCmd.Flags().IntVarP(&quality, "quality", "q", 3, "Quality level (1-5, 1=max compression, 5=max quality)")
+	botCmd.Flags().StringVarP(&preset, "preset", "p", "balanced", "Compression preset (fast, balanced, thorough)")
+	botCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+}
+
+// botCommand answers the videos sent to the bots until interrupted
+func botCommand(cmd *cobra.Command) error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Bot")
+
+	platforms, err := botPlatforms()
+	if err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if quality < 1 || quality > 5 {
+		return withExitCode(exitBadInput, i18n.Errorf("quality must be between 1-5 (got %d)", quality))
+	}
+	if preset != "fast" && preset != "balanced" && preset != "thorough" {
+		return withExitCode(exitBadInput, i18n.Errorf("preset must be one of: fast, balanced, thorough (got %s)", preset))
+	}
+	if err := loadAnalysisParams(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+	if len(botAllow) == 0 {
+		logger.Warning("Anyone who can message the bot can use it, limit it to your chats or users with --allow")
+	}
+
+	// Ctrl+C stops the bots and the running encode
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	jobContext = ctx
+	defer func() { jobContext = context.Background() }()
+
+	// The encodes run one at a time, whichever bot received the video
+	type botJob struct {
+		platform bot.Platform
+		video    bot.Video
+	}
+	jobs := make(chan botJob)
+	for _, platform := range platforms {
+		logger.Info("Waiting for videos on %s (replies up to %s)", platform.Name(), formatSize(platform.UploadLimit()))
+		go func() {
+			for ctx.Err() == nil {
+				videos, err := platform.Receive(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						logger.Warning("Could not check %s for new videos: %v", platform.Name(), err)
+						sleepContext(ctx, botRetryDelay)
+					}
+					continue
+				}
+				for _, video := range videos {
+					select {
+					case jobs <- botJob{platform, video}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+
+	for {
+		select {
+		case job := <-jobs:
+			compressForBot(ctx, job.platform, job.video)
+		case <-ctx.Done():
+			logger.Info("Bot stopped")
+			return nil
+		}
+	}
+}
+
+// botPlatforms returns the bots the flags configure
+func botPlatforms() ([]bot.Platform, error) {
+	limit, err := batch.ParseSize(botUploadLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var platforms []bot.Platform
+	if telegramToken != "" {
+		telegram := bot.NewTelegram(telegramToken)
+		telegram.APIURL = strings.TrimRight(telegramAPI, "/")
+		telegram.Limit = limit
+		platforms = append(platforms, telegram)
+	}
+	if discordToken != "" {
+		if len(discordChannels) == 0 {
+			return nil, i18n.Errorf("--discord-token needs the channels to watch in --discord-channel")
+		}
+		discord := bot.NewDiscord(discordToken, discordChannels)
+		discord.Limit = limit
+		platforms = append(platforms, discord)
+	} else if len(discordChannels) > 0 {
+		return nil, i18n.Errorf("--discord-channel needs --discord-token")
+	}
+	if len(platforms) == 0 {
+		return nil, i18n.Errorf("give a bot token with --telegram-token or --discord-token")
+	}
+	return platforms, nil
+}
+
+// botCompat returns the --compat target whose players the videos sent on
+// platform must play in
+func botCompat(platform bot.Platform) string {
+	if _, ok := platform.(*bot.Telegram); ok {
+		return "telegram"
+	}
+	return "web"
+}
+
+// compressForBot compresses a video sent to a bot to fit the platform's
+// upload limit and replies with the result, or with why it failed
+func compressForBot(ctx context.Context, platform bot.Platform, video bot.Video) {
+	if !bot.Allowed(botAllow, video) {
+		logger.Warning("Ignoring %s from user %s in chat %s, neither is in --allow", video.FileName, video.From, video.Chat)
+		return
+	}
+	logger.Section(fmt.Sprintf("%s: %s", platform.Name(), video.FileName))
+
+	reply := func(text, path string) {
+		if err := platform.Reply(ctx, video, text, path); err != nil && ctx.Err() == nil {
+			logger.Warning("Could not reply on %s: %v", platform.Name(), err)
+		}
+	}
+	fail := func(text string) {
+		logger.Error("%s", text)
+		reply(text, "")
+	}
+
+	if limit := platform.DownloadLimit(); limit > 0 && video.Size > limit {
+		fail(i18n.T("%s is %s, larger than the %s that %s lets bots download", video.FileName, formatSize(video.Size), formatSize(limit), platform.Name()))
+		return
+	}
+
+	dir, err := util.NewJobTempDir()
+	if err != nil {
+		fail(i18n.T("Could not compress %s: %v", video.FileName, err))
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	inputFile := filepath.Join(dir, "input"+video.Ext())
+	if err := platform.Download(ctx, video, inputFile); err != nil {
+		if ctx.Err() == nil {
+			fail(i18n.T("Could not download %s: %v", video.FileName, err))
+		}
+		return
+	}
+	outputFile := filepath.Join(dir, strings.TrimSuffix(filepath.Base(video.FileName), filepath.Ext(video.FileName))+"_compressed.mp4")
+
+	videoFile, err := ffmpeg.NewFFmpeg(inputFile, outputFile, &ffmpeg.Options{}, logger).GetVideoInfo(inputFile)
+	if err != nil {
+		fail(i18n.T("%s is not a video that can be read: %v", video.FileName, err))
+		return
+	}
+
+	limit := platform.UploadLimit()
+	bitrate, err := bot.VideoBitrate(limit, videoFile.Duration, botAudioBitrate)
+	if errors.Is(err, bot.ErrTooLong) {
+		fail(i18n.T("%s is too long to fit in %s at a watchable quality", video.FileName, formatSize(limit)))
+		return
+	} else if err != nil {
+		fail(i18n.T("Could not compress %s: %v", video.FileName, err))
+		return
+	}
+
+	// The bitrate is capped rather than fixed, so simple videos come out
+	// smaller still; one that overshoots is encoded again with a lower cap
+	compat, force = botCompat(platform), true
+	audioBitrateOverride = fmt.Sprintf("%dk", botAudioBitrate/1000)
+	for attempt := 1; ; attempt++ {
+		extraFFmpegArgs = fmt.Sprintf("-maxrate %d -bufsize %d", bitrate, 2*bitrate)
+		logger.Info("Encoding %s with the video bitrate capped at %s to fit in %s", video.FileName, formatBitrate(bitrate), formatSize(limit))
+		if err := runJob(inputFile, outputFile, nil); err != nil {
+			if ctx.Err() == nil {
+				fail(i18n.T("Could not compress %s: %v", video.FileName, err))
+			}
+			return
+		}
+
+		info, err := os.Stat(outputFile)
+		if err != nil {
+			fail(i18n.T("Could not compress %s: %v", video.FileName, err))
+			return
+		}
+		if info.Size() <= limit {
+			text := fmt.Sprintf("%s: %s", video.FileName, formatSize(info.Size()))
+			if input, err := os.Stat(inputFile); err == nil && input.Size() > info.Size() {
+				text = i18n.T("%s: %s, %.0f%% smaller", video.FileName, formatSize(info.Size()), 100-float64(info.Size())*100/float64(input.Size()))
+			}
+			logger.Success("Sending %s (%s) on %s", filepath.Base(outputFile), formatSize(info.Size()), platform.Name())
+			reply(text, outputFile)
+			return
+		}
+		if attempt == 2 {
+			fail(i18n.T("%s came out at %s, still over the %s limit", video.FileName, formatSize(info.Size()), formatSize(limit)))
+			return
+		}
+		bitrate = int64(float64(bitrate) * float64(limit) / float64(info.Size()) * 0.9)
+		if bitrate < bot.MinVideoBitrate {
+			fail(i18n.T("%s is too long to fit in %s at a watchable quality", video.FileName, formatSize(limit)))
+			return
+		}
+		logger.Warning("%s came out at %s, over the %s limit; encoding it again", video.FileName, formatSize(info.Size()), formatSize(limit))
+	}
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
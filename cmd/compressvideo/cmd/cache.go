@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/cccarv82/compressvideo/pkg/cache"
-	"github.com/cccarv82/compressvideo/pkg/util"
 	"github.com/spf13/cobra"
 )
 
@@ -37,7 +36,10 @@ func init() {
 
 func manageCacheCommand() {
 	// Configure logger
-	logger = util.NewLogger(verbose)
+	if err := setupLogger(); err != nil {
+		logger.Fatal("%v", err)
+	}
+	defer logger.Close()
 	logger.Title("CompressVideo - Cache Manager")
 
 	// Initialize cache
@@ -69,6 +71,10 @@ func manageCacheCommand() {
 		if err != nil {
 			logger.Fatal("Failed to clear cache: %v", err)
 		}
+		_, err = videoCache.DB.Exec("DELETE FROM compression_outcomes")
+		if err != nil {
+			logger.Fatal("Failed to clear cache: %v", err)
+		}
 		
 		logger.Success("All cache entries cleared successfully")
 		return
@@ -114,4 +120,5 @@ func manageCacheCommand() {
 	logger.Info("• Regular cleaning keeps the cache size manageable")
 	logger.Info("• Cache entries expire automatically after 30 days by default")
 	logger.Info("• Set expiration period with '--cache-max-age' or '-A' flag")
+	logger.Info("• Limit the cache size with '--cache-max-size' or 'cache prune --max-size'")
 } 
\ No newline at end of file
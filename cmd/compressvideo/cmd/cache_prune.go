@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneMaxSizeMB  int
+	pruneMaxEntries int
+)
+
+// cachePruneCmd evicts least recently used cache entries
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Shrink the analysis cache",
+	Long: `Evict the least recently used cache entries until the cache
+fits within --max-size megabytes and/or --max-entries entries.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pruneCacheCommand()
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cachePruneCmd)
+
+	cachePruneCmd.Flags().IntVar(&pruneMaxSizeMB, "max-size", 0, "Maximum size of the cached data in MB")
+	cachePruneCmd.Flags().IntVar(&pruneMaxEntries, "max-entries", 0, "Maximum number of cached analyses")
+}
+
+func pruneCacheCommand() {
+	if err := setupLogger(); err != nil {
+		logger.Fatal("%v", err)
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Cache Manager")
+
+	if pruneMaxSizeMB <= 0 && pruneMaxEntries <= 0 {
+		logger.Fatal("Specify --max-size and/or --max-entries")
+	}
+
+	videoCache, err := cache.NewVideoAnalysisCache(logger)
+	if err != nil {
+		logger.Fatal("Failed to initialize cache: %v", err)
+	}
+	defer videoCache.Close()
+
+	evicted, err := videoCache.Prune(pruneMaxEntries, int64(pruneMaxSizeMB)*1024*1024)
+	if err != nil {
+		logger.Fatal("Failed to prune cache: %v", err)
+	}
+
+	entries, size, err := videoCache.Usage()
+	if err != nil {
+		logger.Fatal("Failed to get cache statistics: %v", err)
+	}
+
+	logger.Success("Evicted %d cache entries", evicted)
+	logger.Info("Cache now holds %d entries (%s)", entries, formatSize(size))
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupMaxAge time.Duration // Age after which unclaimed temporary files are removed
+	cleanupDryRun bool          // Only list what would be removed
+)
+
+// cleanupCmd removes temporary files left behind by interrupted runs
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Remove temporary files left by interrupted runs",
+	Long: `Remove the temporary directories (segments, VMAF probes, two-pass logs,
+staged downloads) that crashed or killed runs left behind.
+
+Every run keeps its temporary files in its own directory under the system
+temporary directory (compressvideo/job-<pid>-...). Directories of processes
+that are no longer running are removed, as are other leftovers older than
+--max-age.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cleanupCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cleanupCmd)
+
+	cleanupCmd.Flags().DurationVar(&cleanupMaxAge, "max-age", 24*time.Hour, "Remove other leftovers older than this")
+	cleanupCmd.Flags().BoolVar(&cleanupDryRun, "dry-run", false, "List what would be removed without removing it")
+}
+
+func cleanupCommand() error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Cleanup")
+	logger.Field("Temporary Directory", util.TempRoot())
+
+	stale, err := util.CleanTempFiles(cleanupMaxAge, cleanupDryRun)
+	var total int64
+	for _, file := range stale {
+		logger.Info("%s (%s)", file.Path, formatSize(file.Size))
+		total += file.Size
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(stale) == 0:
+		logger.Success("No leftover temporary files found")
+	case cleanupDryRun:
+		logger.Info("Would remove %d temporary directories (%s)", len(stale), formatSize(total))
+	default:
+		logger.Success("Removed %d temporary directories (%s)", len(stale), formatSize(total))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	makeComparison bool   // Write a clip comparing the source and the output
+	comparisonMode string // Layout of the comparison clip
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&makeComparison, "make-comparison", false, "Write a 10 second clip of the busiest scene showing the source and the compressed video together as <output>-comparison.mp4, to check the quality before compressing a whole library")
+	rootCmd.Flags().StringVar(&comparisonMode, "comparison-mode", ffmpeg.ComparisonSideBySide, "Layout of the comparison clip: side-by-side (source on the left) or interleave (source and output take turns every second, source first)")
+}
+
+// validateComparison checks the comparison clip options
+func validateComparison() error {
+	if comparisonMode != ffmpeg.ComparisonSideBySide && comparisonMode != ffmpeg.ComparisonInterleave {
+		return i18n.Errorf("unknown comparison mode %q (use %s or %s)", comparisonMode, ffmpeg.ComparisonSideBySide, ffmpeg.ComparisonInterleave)
+	}
+	return nil
+}
+
+// writeComparison writes the comparison clip requested with --make-comparison
+// next to outputFile, from the busiest scene of the source and the compressed
+// video at videoPath. Failures only warn: the compressed video is already
+// done.
+func writeComparison(inputFile, outputFile, videoPath string, analysis *analyzer.VideoAnalysis, ffmpegInstance *ffmpeg.FFmpeg) {
+	if !makeComparison {
+		return
+	}
+
+	// The source is scaled to the output, whose size filters may have changed
+	width, height := analysis.VideoFile.VideoInfo.Width, analysis.VideoFile.VideoInfo.Height
+	if output, err := ffmpegInstance.GetVideoInfo(videoPath); err == nil {
+		width, height = output.VideoInfo.Width, output.VideoInfo.Height
+	}
+
+	start := analysis.BusiestScene(ffmpeg.ComparisonSeconds)
+	clipPath := strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + "-comparison.mp4"
+	if err := ffmpegInstance.MakeComparison(inputFile, videoPath, clipPath, start, ffmpeg.ComparisonSeconds, comparisonMode, width, height); err != nil {
+		logger.Warning("Failed to write comparison clip: %v", err)
+		return
+	}
+	logger.Field("Comparison Clip", "%s (%s from %s)", clipPath, comparisonMode, time.Duration(start*float64(time.Second)).Round(time.Second))
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var compat string // Devices the output must play on
+
+func init() {
+	rootCmd.Flags().StringVar(&compat, "compat", "", "Keep the output playable on these devices, whatever the analyzer picks: "+strings.Join(compressor.CompatTargetNames(), ", "))
+}
+
+// validateCompat checks --compat and the options it cannot be combined with
+func validateCompat() error {
+	if compat == "" {
+		return nil
+	}
+	target, err := compressor.LookupCompat(compat)
+	if err != nil {
+		return err
+	}
+	if codec != "" && codec != analyzer.CodecAuto && !target.Plays(codec) {
+		return i18n.Errorf("--compat %s devices do not play %s (use --codec %s)", target.Name, codec, target.Codecs[0])
+	}
+	if copyVideo {
+		return i18n.Errorf("--compat cannot be used with --copy-video")
+	}
+	// Hardware encoders take neither the profile nor the level of the target
+	if hwEncoder != "" {
+		return i18n.Errorf("--compat cannot be used with --hw-encoder, which ignores the profile and level the devices need")
+	}
+	if pixFmtOverride != "" && pixFmtOverride != target.PixFmt {
+		return i18n.Errorf("--compat %s devices need pixel format %s (got --pix-fmt %s)", target.Name, target.PixFmt, pixFmtOverride)
+	}
+	return nil
+}
+
+// compatCodec returns the codec to encode with: the one chosen, or the one
+// --compat prefers when the choice was left to the analyzer
+func compatCodec() string {
+	if compat == "" || codec != "" && codec != analyzer.CodecAuto {
+		return codec
+	}
+	// Checked by validateCompat
+	target, _ := compressor.LookupCompat(compat)
+	return target.Codecs[0]
+}
+
+// applyCompat keeps the settings within what the --compat devices play,
+// recording each change as a decision for --explain
+func applyCompat(settings map[string]string, outputFile string, videoFile *ffmpeg.VideoFile, contentAnalyzer *analyzer.ContentAnalyzer) error {
+	if compat == "" {
+		return nil
+	}
+	target, err := compressor.LookupCompat(compat)
+	if err != nil {
+		return err
+	}
+
+	// Renditions are scaled to the heights of the ladder instead
+	if renditions != "" {
+		target.MaxSize = [2]int{}
+	}
+	changed, err := compressor.ApplyCompat(settings, target, videoFile, compressor.MappedAudio(settings, videoFile.AudioInfo))
+	if err != nil {
+		return err
+	}
+
+	for _, name := range changed {
+		value := settings[name]
+		switch name {
+		case "scale":
+			value = ffmpeg.VideoFilter(settings, ffmpeg.FilterScale)
+		case "fps":
+			value = ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS)
+		}
+		logger.Info("Using %s %s for --compat %s", name, value, target.Name)
+		contentAnalyzer.Record(analyzer.Decision{Name: name, Value: value, Reason: "--compat " + target.Name + " devices play it"})
+	}
+	if !target.OpensContainer(outputFile) {
+		logger.Warning("%s devices may not open %s files; name the output %s", target.Name, filepath.Ext(outputFile), target.Containers[0])
+	}
+	return nil
+}
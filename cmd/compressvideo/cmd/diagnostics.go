@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/diagnostics"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// diagnosticJob is the file being processed, described in diagnostic bundles
+var diagnosticJob struct {
+	input    string
+	ffmpeg   *ffmpeg.FFmpeg    // Probes the input for the bundle
+	settings map[string]string // Encoder settings, once chosen
+}
+
+// startDiagnosticJob records the file being processed
+func startDiagnosticJob(input string, f *ffmpeg.FFmpeg) {
+	diagnosticJob.input = input
+	diagnosticJob.ffmpeg = f
+	diagnosticJob.settings = nil
+}
+
+// writeDiagnostics writes a diagnostic bundle about the current file and
+// returns its path. output is what FFmpeg printed, stack the stack trace of
+// a crash.
+func writeDiagnostics(reason, stack, output string) (string, error) {
+	bundle := &diagnostics.Bundle{
+		Time:         time.Now(),
+		Reason:       reason,
+		Stack:        stack,
+		Args:         os.Args,
+		Input:        diagnosticJob.input,
+		Settings:     diagnosticJob.settings,
+		FFmpegOutput: output,
+	}
+	if info, err := util.FindFFmpeg(); err == nil && info.Available {
+		bundle.FFmpegPath = info.Path
+		bundle.FFmpegVersion = info.Version
+	}
+	if diagnosticJob.ffmpeg != nil && diagnosticJob.input != "" {
+		probe, err := diagnosticJob.ffmpeg.ProbeJSON(diagnosticJob.input)
+		if err != nil {
+			bundle.Probe = err.Error()
+		} else {
+			bundle.Probe = string(probe)
+		}
+	}
+	return bundle.Write(diagnostics.Dir())
+}
+
+// reportEncodeFailure logs everything FFmpeg printed before a failed encode
+// and, when FFmpeg failed for none of the causes ffmpeg.ClassifyError
+// recognizes, writes a diagnostic bundle for a bug report
+func reportEncodeFailure(err error) {
+	logFFmpegOutput(err)
+
+	// Stopped encodes and known causes come with their own explanation
+	var ffmpegErr *ffmpeg.Error
+	if !errors.As(err, &ffmpegErr) || ffmpegErr.Kind != nil || jobContext.Err() != nil ||
+		compressionExitCode(err) != exitEncodeFailed {
+		return
+	}
+
+	path, writeErr := writeDiagnostics(err.Error(), "", ffmpegErr.Output)
+	if writeErr != nil {
+		logger.Debug("Could not write a diagnostic bundle: %v", writeErr)
+		return
+	}
+	logger.Warning("A diagnostic bundle was written to %s; attach it when reporting the problem at %s", path, diagnostics.IssuesURL)
+}
+
+// recoverPanic turns a crash into a diagnostic bundle and a short message
+// instead of a bare stack trace. It must be deferred by Execute.
+func recoverPanic() {
+	r := recover()
+	if r == nil {
+		return
+	}
+	stack := string(debug.Stack())
+
+	fmt.Fprintf(os.Stderr, "\n%s\n", i18n.T("CompressVideo crashed: %v", r))
+	path, err := writeDiagnostics(fmt.Sprint(r), stack, "")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n%s", i18n.T("Could not write a diagnostic bundle: %v", err), stack)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s\n", i18n.T("A diagnostic bundle was written to %s; attach it when reporting the problem at %s", path, diagnostics.IssuesURL))
+	}
+	os.Exit(exitFailure)
+}
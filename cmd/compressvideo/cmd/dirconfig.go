@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// dirFlags holds the settings given on the command line, which the
+// .compressvideo.yaml files of a directory run do not override
+var dirFlags batch.DirConfig
+
+// setupDirFlags records the settings given on the command line. The skip
+// filters have no default, so they are always taken as given.
+func setupDirFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+	dirFlags = batch.DirConfig{SkipCodecs: skipCodecs, SkipBelowBitrate: skipBelowBitrate}
+	if flags.Changed("quality") {
+		dirFlags.Settings.Quality = quality
+	}
+	if flags.Changed("preset") {
+		dirFlags.Settings.Preset = preset
+	}
+	if flags.Changed("codec") {
+		dirFlags.Settings.Codec = codec
+	}
+	if flags.Changed("screencast-roi") {
+		dirFlags.Settings.ScreencastROI = screencastROI
+	}
+}
+
+// applyDirConfig sets the options the .compressvideo.yaml files chose for a
+// job and returns a function that restores the run's own
+func applyDirConfig(job batch.Job) func() {
+	savedQuality, savedPreset, savedCodec, savedROI, savedRoutes := quality, preset, codec, screencastROI, outputRoutes
+	restore := func() {
+		quality, preset, codec, screencastROI, outputRoutes = savedQuality, savedPreset, savedCodec, savedROI, savedRoutes
+	}
+	if len(job.ConfigFiles) == 0 {
+		return restore
+	}
+
+	settings := job.Config.Settings
+	if settings.Quality != 0 {
+		quality = settings.Quality
+	}
+	if settings.Preset != "" {
+		preset = settings.Preset
+	}
+	if settings.Codec != "" {
+		codec = settings.Codec
+	}
+	if settings.ScreencastROI {
+		screencastROI = true
+	}
+
+	// The routes of the directory files are checked before the run's
+	if len(settings.Routes) > 0 {
+		outputRoutes = append(append([]config.Route(nil), settings.Routes...), outputRoutes...)
+	}
+	logger.Debug("Using %s: %s", strings.Join(job.ConfigFiles, ", "),
+		profileArgs(config.Profile{Quality: quality, Preset: preset, Codec: codec, ScreencastROI: screencastROI}))
+	return restore
+}
+
+// jobProbeFilter returns the codec and bitrate filter of a job, from the
+// flags or the .compressvideo.yaml files above it. Both were validated.
+func jobProbeFilter(job batch.Job) batch.ProbeFilter {
+	minBitrate, _ := batch.ParseMinBitrate(job.Config.SkipBelowBitrate)
+	return batch.ProbeFilter{SkipCodecs: batch.ParseCodecList(job.Config.SkipCodecs), MinBitrate: minBitrate}
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// Results of a doctor check
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+	checkSkip = "SKIP" // Not applicable to this installation
+)
+
+// Free space below these limits fails or warns. Temporary files and outputs
+// can be as large as the input videos.
+const (
+	minFreeSpace  = 1 << 30  // 1 GB
+	warnFreeSpace = 10 << 30 // 10 GB
+)
+
+// doctorCheck is one line of the doctor report
+type doctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// encoderFamily is a video format and the encoders that produce it, in the
+// order the compressor falls back through them
+type encoderFamily struct {
+	Name     string
+	Encoders []string
+	Required bool // Missing fails the check instead of warning
+	Hardware bool // Absent from most FFmpeg builds, so missing is skipped
+}
+
+// doctorEncoders lists the encoders the analyzer can pick
+var doctorEncoders = []encoderFamily{
+	{Name: "H.264 (x264)", Encoders: []string{"libx264", "libopenh264"}, Required: true},
+	{Name: "HEVC (x265)", Encoders: []string{"libx265"}},
+	{Name: "VP9", Encoders: []string{"libvpx-vp9"}},
+	{Name: "AV1", Encoders: []string{"libsvtav1", "libaom-av1"}},
+	{Name: "NVIDIA NVENC", Encoders: []string{"h264_nvenc", "hevc_nvenc"}, Hardware: true},
+	{Name: "Intel Quick Sync", Encoders: []string{"h264_qsv", "hevc_qsv"}, Hardware: true},
+	{Name: "VAAPI", Encoders: []string{"h264_vaapi", "hevc_vaapi"}, Hardware: true},
+	{Name: "AMD AMF", Encoders: []string{"h264_amf", "hevc_amf"}, Hardware: true},
+}
+
+// doctorCmd checks the installation CompressVideo depends on
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check FFmpeg, encoders and disk space",
+	Long: `Check that everything CompressVideo depends on works and print a
+pass/fail report:
+
+- FFmpeg and FFprobe are installed and run, and their versions
+- Each video encoder (x264, x265, VP9, AV1, NVENC, Quick Sync, VAAPI, AMF)
+  is compiled in and can encode a test pattern
+- The cache and temporary directories are writable
+- There is enough free disk space for temporary files and outputs
+
+Unlike repair-ffmpeg, doctor never downloads or changes anything. It exits
+with status 3 when no working FFmpeg is found and 1 when another check fails.`,
+	Args: cobra.NoArgs,
+	// A failed check is a finding, not a usage error
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return doctorCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+func doctorCommand() error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Doctor")
+
+	checks, ffmpegFound := checkFFmpeg()
+	checks = append(checks, checkDirectory("Cache directory", cache.Dir()))
+	checks = append(checks, checkFreeSpace("Cache disk space", cache.Dir()))
+	checks = append(checks, checkDirectory("Temporary directory", util.TempRoot()))
+	checks = append(checks, checkFreeSpace("Temporary disk space", util.TempRoot()))
+	if dir, err := os.Getwd(); err == nil {
+		checks = append(checks, checkFreeSpace("Working directory disk space", dir))
+	}
+
+	rows := make([][]string, len(checks))
+	failed, warned := 0, 0
+	for i, check := range checks {
+		rows[i] = []string{i18n.Tr(check.Name), check.Status, check.Detail}
+		switch check.Status {
+		case checkFail:
+			failed++
+		case checkWarn:
+			warned++
+		}
+	}
+	logger.Table([]string{"Check", "Status", "Details"}, rows)
+
+	switch {
+	case !ffmpegFound:
+		return withExitCode(exitFFmpegMissing, i18n.Errorf("FFmpeg was not found; run repair-ffmpeg or install it"))
+	case failed > 0:
+		return i18n.Errorf("%d of %d checks failed", failed, len(checks))
+	case warned > 0:
+		logger.Success("No checks failed (%d warnings)", warned)
+		return nil
+	}
+	logger.Success("All checks passed")
+	return nil
+}
+
+// checkFFmpeg checks the FFmpeg and FFprobe binaries and the encoders of the
+// FFmpeg build, and reports whether a working FFmpeg was found
+func checkFFmpeg() ([]doctorCheck, bool) {
+	info, err := util.FindFFmpeg()
+	if err != nil || !info.Available {
+		detail := i18n.T("not found or not working")
+		if err != nil {
+			detail = err.Error()
+		}
+		return []doctorCheck{
+			{Name: "FFmpeg", Status: checkFail, Detail: detail},
+			{Name: "FFprobe", Status: checkSkip},
+		}, false
+	}
+
+	source := i18n.T("system")
+	if info.IsDownloaded {
+		source = i18n.T("downloaded")
+	}
+	checks := []doctorCheck{{Name: "FFmpeg", Status: checkPass, Detail: fmt.Sprintf("%s (%s, %s)", info.Version, info.Path, source)}}
+	if info.Version == "Unknown" {
+		checks[0].Status = checkWarn
+	}
+	if !util.FFmpegVersionAtLeast(info.Version, util.MinFFmpegVersion) {
+		checks[0].Status = checkFail
+		checks[0].Detail += "; " + i18n.T("older than %s, the oldest version CompressVideo supports", util.MinFFmpegVersion)
+	} else if info.Outdated {
+		checks[0].Status = checkWarn
+		checks[0].Detail += "; " + i18n.T("older than %s, run repair-ffmpeg to install a newer build", util.MinSystemFFmpegVersion)
+	}
+	if info.ProbeMissing {
+		checks = append(checks, doctorCheck{Name: "FFprobe", Status: checkWarn,
+			Detail: i18n.T("not found; video information is read from FFmpeg output, with fewer details")})
+	} else {
+		checks = append(checks, doctorCheck{Name: "FFprobe", Status: checkPass, Detail: info.FFprobePath})
+	}
+
+	f := ffmpeg.NewFFmpeg("", "", nil, logger)
+	f.Runner = ffmpeg.NewRunner(info.Path, info.FFprobePath)
+	encoders, err := f.Encoders()
+	if err != nil {
+		return append(checks, doctorCheck{Name: "Encoders", Status: checkFail, Detail: err.Error()}), true
+	}
+	for _, family := range doctorEncoders {
+		checks = append(checks, checkEncoders(f, family, encoders))
+	}
+
+	// Optional features need encoders or filters not every build includes
+	capabilities, err := f.Capabilities()
+	if err != nil {
+		return append(checks, doctorCheck{Name: "Features", Status: checkWarn, Detail: err.Error()}), true
+	}
+	for _, feature := range ffmpeg.Features {
+		check := doctorCheck{Name: feature.Name, Status: checkPass, Detail: feature.Encoder + feature.Filter}
+		if !capabilities.Has(feature) {
+			check.Status = checkSkip
+			check.Detail = i18n.T("not in this FFmpeg build")
+		}
+		checks = append(checks, check)
+	}
+	return checks, true
+}
+
+// checkEncoders test encodes with each encoder of family that is compiled
+// into FFmpeg. One working encoder passes the check.
+func checkEncoders(f *ffmpeg.FFmpeg, family encoderFamily, available map[string]bool) doctorCheck {
+	check := doctorCheck{Name: family.Name}
+	var working, broken []string
+	var lastErr error
+	for _, encoder := range family.Encoders {
+		if !available[encoder] {
+			continue
+		}
+		if err := f.TryEncoder(encoder); err != nil {
+			broken = append(broken, encoder)
+			lastErr = err
+			continue
+		}
+		working = append(working, encoder)
+	}
+
+	switch {
+	case len(working) > 0:
+		check.Status = checkPass
+		check.Detail = strings.Join(working, ", ")
+	case len(broken) > 0 && family.Hardware:
+		check.Status = checkWarn
+		check.Detail = i18n.T("%s compiled in, but no usable GPU or driver: %v", strings.Join(broken, ", "), lastErr)
+	case len(broken) > 0:
+		check.Status = checkFail
+		check.Detail = i18n.T("%s failed a test encode: %v", strings.Join(broken, ", "), lastErr)
+	case family.Hardware:
+		check.Status = checkSkip
+		check.Detail = i18n.T("not in this FFmpeg build")
+	case family.Required:
+		check.Status = checkFail
+		check.Detail = i18n.T("not in this FFmpeg build; run repair-ffmpeg for a full build")
+	default:
+		check.Status = checkWarn
+		check.Detail = i18n.T("not in this FFmpeg build; other codecs are used instead")
+	}
+	return check
+}
+
+// checkDirectory checks that files can be created in dir
+func checkDirectory(name, dir string) doctorCheck {
+	check := doctorCheck{Name: name, Status: checkPass, Detail: dir}
+	if err := util.CheckWritable(dir); err != nil {
+		check.Status = checkFail
+		check.Detail = i18n.T("%s is not writable: %v", dir, err)
+	}
+	return check
+}
+
+// checkFreeSpace checks the free space on the disk that holds dir
+func checkFreeSpace(name, dir string) doctorCheck {
+	check := doctorCheck{Name: name}
+	free, err := util.FreeDiskSpace(dir)
+	if err != nil {
+		check.Status = checkWarn
+		check.Detail = i18n.T("could not be determined: %v", err)
+		return check
+	}
+
+	check.Detail = i18n.T("%s free", formatSize(int64(free)))
+	switch {
+	case free < minFreeSpace:
+		check.Status = checkFail
+	case free < warnFreeSpace:
+		check.Status = checkWarn
+	default:
+		check.Status = checkPass
+	}
+	return check
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// downloadLimit caps the speed of the FFmpeg download, e.g. 2MB/s
+var downloadLimit string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&downloadLimit, "download-limit", "", "Limit the speed of the automatic FFmpeg download, e.g. 2MB/s or 500KB/s (default: no limit)")
+	// Set directly, since commands that never download FFmpeg, such as
+	// version and doctor, also look it up
+	rootCmd.PersistentFlags().BoolVar(&util.PreferDownloaded, "prefer-downloaded", false, "Use (and download when missing) the FFmpeg build managed by CompressVideo even when FFmpeg is installed on the system")
+}
+
+// parseDownloadLimit parses a speed such as "2MB/s" or "500k" into bytes
+// per second; an empty value means no limit
+func parseDownloadLimit(value string) (int64, error) {
+	size := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(value)), "/s")
+	limit, err := batch.ParseSize(size)
+	if err != nil || (limit == 0 && size != "") {
+		return 0, i18n.Errorf("download-limit must be a speed such as 2MB/s or 500KB/s (got %s)", value)
+	}
+	return limit, nil
+}
+
+// applyDownloadLimit sets the speed limit of FFmpeg downloads from
+// --download-limit
+func applyDownloadLimit() error {
+	limit, err := parseDownloadLimit(downloadLimit)
+	if err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	util.DownloadLimit = limit
+	if limit > 0 {
+		logger.Debug("FFmpeg downloads limited to %s/s", util.FormatSize(limit))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	wattsPerCore float64       // Power one busy CPU core draws, for the energy estimates
+	runCPUTime   time.Duration // CPU time of the FFmpeg processes of the run so far
+)
+
+// validateWattsPerCore checks --watts-per-core
+func validateWattsPerCore() error {
+	if wattsPerCore < 0 {
+		return i18n.Errorf("watts-per-core must be 0 or more (got %g)", wattsPerCore)
+	}
+	return nil
+}
+
+// meterJob measures the CPU time of the FFmpeg processes of one file. The
+// returned function adds it to the run's total once the file is done.
+func meterJob(f *ffmpeg.FFmpeg) (*ffmpeg.CPUMeter, func()) {
+	meter := &ffmpeg.CPUMeter{}
+	f.Runner = ffmpeg.WithCPUMeter(f.Runner, meter)
+	return meter, func() { runCPUTime += meter.Total() }
+}
+
+// setCPUTime records the CPU time of the file so far, and the energy it
+// took, in its result
+func setCPUTime(result *compressor.CompressionResult, meter *ffmpeg.CPUMeter) {
+	if result == nil {
+		return
+	}
+	result.CPUTime = meter.Total()
+	result.EnergyWh = compressor.EstimateEnergy(result.CPUTime, wattsPerCore)
+}
+
+// logRunCPUTime shows the CPU time and energy of a directory or batch run,
+// from the CPU time total at its start
+func logRunCPUTime(startCPUTime time.Duration) {
+	cpuTime := runCPUTime - startCPUTime
+	if cpuTime <= 0 {
+		return
+	}
+	logger.Info("FFmpeg used %s of CPU time, about %.2f Wh at %g W per core",
+		cpuTime.Round(time.Second), compressor.EstimateEnergy(cpuTime, wattsPerCore), wattsPerCore)
+}
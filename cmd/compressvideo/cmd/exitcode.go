@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Exit codes let scripts, CI jobs and container orchestrators tell failures apart
+const (
+	exitFailure            = 1 // Any other error
+	exitBadInput           = 2 // Invalid flags or an unreadable input
+	exitFFmpegMissing      = 3 // No working FFmpeg, or one lacking a needed feature
+	exitEncodeFailed       = 4 // FFmpeg failed to encode
+	exitVerificationFailed = 5 // The encoded output is incomplete or unreadable
+	exitTimedOut           = 6 // --timeout-per-file or --max-runtime stopped FFmpeg
+)
+
+// commandStarted is set once Cobra has parsed the flags and arguments and a
+// command starts running; errors before that are usage errors
+var commandStarted bool
+
+// exitError attaches an exit code to an error
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode marks err to end the program with code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// compressionExitCode classifies an error returned by the compressor. FFmpeg
+// failures caused by the input file count as bad input.
+func compressionExitCode(err error) int {
+	switch {
+	case errors.Is(err, compressor.ErrVerificationFailed):
+		return exitVerificationFailed
+	case errors.Is(err, ffmpeg.ErrTruncatedInput), errors.Is(err, ffmpeg.ErrInvalidData),
+		errors.Is(err, ffmpeg.ErrFileNotFound):
+		return exitBadInput
+	}
+	return exitEncodeFailed
+}
+
+// logFFmpegOutput writes everything FFmpeg printed before failing with err to
+// the debug log; the error message only keeps the lines that explain it
+func logFFmpegOutput(err error) {
+	var ffmpegErr *ffmpeg.Error
+	if errors.As(err, &ffmpegErr) && ffmpegErr.Output != "" {
+		logger.Debug("FFmpeg output:\n%s", ffmpegErr.Output)
+	}
+}
+
+// exitCode returns the exit code for an error returned by a command
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// requireFFmpeg makes sure a working FFmpeg is installed, downloading one
+// when none is found, and fails with exitFFmpegMissing otherwise
+func requireFFmpeg() error {
+	if err := applyDownloadLimit(); err != nil {
+		return err
+	}
+	info, err := util.EnsureFFmpeg(logger)
+	if err != nil {
+		return withExitCode(exitFFmpegMissing, err)
+	}
+	if !util.FFmpegVersionAtLeast(info.Version, util.MinFFmpegVersion) {
+		return withExitCode(exitFFmpegMissing, i18n.Errorf("your FFmpeg %s is older than %s, the oldest version CompressVideo supports; run repair-ffmpeg to install a newer build", info.Version, util.MinFFmpegVersion))
+	}
+	return nil
+}
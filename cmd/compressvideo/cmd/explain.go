@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explain bool // Record why each setting was chosen and include it in the report
+)
+
+func init() {
+	for _, command := range []*cobra.Command{rootCmd, analyzeCmd, batchCmd} {
+		command.Flags().BoolVar(&explain, "explain", false, "Explain why the analyzer chose each setting, in the output and the report")
+	}
+}
+
+// explainReusedAnalysis records that the analysis of a file came from the
+// cache, so the reasons for its content type and motion are not known.
+// Running without flag analyzes the file again.
+func explainReusedAnalysis(contentAnalyzer *analyzer.ContentAnalyzer, analysis *analyzer.VideoAnalysis, source, flag string) {
+	contentAnalyzer.Record(analyzer.Decision{
+		Name:   "analysis",
+		Value:  analysis.ContentType.String() + ", " + analysis.MotionComplexity.String() + " motion",
+		Reason: "the cached analysis of " + source + " was reused; run without " + flag + " for the reasons",
+	})
+}
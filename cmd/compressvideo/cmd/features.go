@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// codecFeatures are the codecs --codec checks the encoder of: without it
+// the encode would fall back to another codec. H.264 falls back to
+// OpenH264, which still produces H.264.
+var codecFeatures = map[string]ffmpeg.Feature{
+	"hevc": {Name: "HEVC encoding", Encoder: "libx265", Hint: "use --codec h264"},
+	"vp9":  {Name: "VP9 encoding", Encoder: "libvpx-vp9", Hint: "use --codec h264"},
+}
+
+// requiredFeatures returns the optional FFmpeg features the options need
+func requiredFeatures() []ffmpeg.Feature {
+	var features []ffmpeg.Feature
+	if targetVMAF > 0 {
+		features = append(features, ffmpeg.FeatureVMAF)
+	}
+	if tonemap {
+		features = append(features, ffmpeg.FeatureTonemap)
+	}
+	// Hardware encoders replace the software encoder of the codec
+	if feature, ok := codecFeatures[codec]; ok && hwEncoder == "" {
+		features = append(features, feature)
+	}
+	return features
+}
+
+// checkFFmpegFeatures fails before a file is analyzed when FFmpeg lacks a
+// feature the options need, instead of deep in the encode
+func checkFFmpegFeatures(f *ffmpeg.FFmpeg) error {
+	features := requiredFeatures()
+	if len(features) == 0 {
+		return nil
+	}
+
+	capabilities, err := f.Capabilities()
+	if err != nil {
+		// Without the lists, let FFmpeg report any problem itself
+		logger.Debug("Could not probe FFmpeg capabilities: %v", err)
+		return nil
+	}
+	for _, feature := range features {
+		if err := capabilities.Require(feature); err != nil {
+			return withExitCode(exitFFmpegMissing, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/sidecar"
+)
+
+var (
+	deinterlace bool    // Turn interlaced fields into frames
+	outputFPS   float64 // Output frame rate, 0 for the source's
+	crop        string  // Region of the frame to keep
+	denoise     string  // Strength of the noise removal
+	tonemap     bool    // Map HDR video to SDR
+	scale       string  // Output size
+
+	squarePixels bool   // Resize anamorphic video to square pixels
+	burnSubs     string // Subtitles to draw into the picture: a file or auto
+)
+
+// burnSubsAuto makes --burn-subs pick the subtitles of the input
+const burnSubsAuto = "auto"
+
+func init() {
+	rootCmd.Flags().BoolVar(&deinterlace, "deinterlace", false, "Deinterlace frames flagged as interlaced, e.g. from TV recordings or DV tapes")
+	rootCmd.Flags().Float64Var(&outputFPS, "fps", 0, "Output frame rate (0 = source frame rate)")
+	rootCmd.Flags().StringVar(&crop, "crop", "", "Keep only this region of the frame, as width:height:x:y (e.g. 1920:800:0:140 to remove black bars)")
+	rootCmd.Flags().StringVar(&denoise, "denoise", "", "Remove grain and noise before encoding: "+strings.Join(compressor.DenoiseStrengths, ", "))
+	rootCmd.Flags().BoolVar(&tonemap, "tonemap", false, "Convert HDR video to SDR for screens without HDR (needs FFmpeg with zscale)")
+	rootCmd.Flags().StringVar(&scale, "scale", "", "Output size as WIDTHxHEIGHT, or a height that keeps the aspect ratio (e.g. 1280x720 or 720)")
+	rootCmd.Flags().BoolVar(&squarePixels, "square-pixels", false, "Resize anamorphic video (DV, DVD rips) to square pixels at its display aspect ratio, for players that show it stretched; otherwise its pixel aspect ratio is kept")
+	rootCmd.Flags().StringVar(&burnSubs, "burn-subs", "", "Draw subtitles into the picture, for players and devices without subtitle support: an SRT, ASS, SSA or WebVTT file, or auto for the subtitle file named after the input, else its default text subtitle stream")
+}
+
+// videoFilters returns the filters given on the command line
+func videoFilters() compressor.VideoFilters {
+	return compressor.VideoFilters{
+		Deinterlace: deinterlace,
+		FPS:         outputFPS,
+		Crop:        crop,
+		Denoise:     denoise,
+		Tonemap:     tonemap,
+		Scale:       scale,
+
+		SquarePixels: squarePixels,
+		Subtitles:    burnSubs,
+	}
+}
+
+// validateVideoFilters checks the filter flags and the options they cannot
+// be combined with. Whether a crop fits is checked once the video is known.
+func validateVideoFilters() error {
+	f := videoFilters()
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	if copyVideo && len(f.Stages()) > 0 {
+		return i18n.Errorf("video filters cannot be used with --copy-video")
+	}
+	if f.Scale != "" && renditions != "" {
+		return i18n.Errorf("--scale cannot be used with --renditions, which scale each output")
+	}
+	if f.SquarePixels && renditions != "" {
+		return i18n.Errorf("--square-pixels cannot be used with --renditions, which scale each output")
+	}
+	if burnSubs != "" && burnSubs != burnSubsAuto {
+		if !sidecar.IsTextSubtitle(burnSubs) {
+			return i18n.Errorf("burn-subs must be auto or an SRT, ASS, SSA or WebVTT file (got %s)", burnSubs)
+		}
+		if _, err := os.Stat(burnSubs); err != nil {
+			return i18n.Errorf("subtitle file does not exist: %s", burnSubs)
+		}
+	}
+	return nil
+}
+
+// applyVideoFilters adds the filters given on the command line to the video
+// filter chain of settings
+func applyVideoFilters(settings map[string]string, inputFile string, videoFile *ffmpeg.VideoFile) error {
+	f := videoFilters()
+	if f.Subtitles != "" {
+		subtitles, stream, err := subtitlesToBurn(inputFile, videoFile)
+		if err != nil {
+			return err
+		}
+		f.Subtitles, f.SubtitleStream = subtitles, stream
+	}
+	if err := compressor.ApplyVideoFilters(settings, f, videoFile); err != nil {
+		return err
+	}
+	if f.Tonemap && !videoFile.VideoInfo.IsHDR {
+		logger.Info("The video is not HDR, so it is not tone mapped")
+	}
+	if f.SquarePixels && !videoFile.VideoInfo.IsAnamorphic() {
+		logger.Info("The video already has square pixels")
+	} else if !f.SquarePixels && videoFile.VideoInfo.IsAnamorphic() {
+		logger.Info("Keeping the pixel aspect ratio %s of the anamorphic video; use --square-pixels if players show it stretched", videoFile.VideoInfo.SAR)
+	}
+	return nil
+}
+
+// subtitlesToBurn returns the subtitles --burn-subs draws into the picture:
+// the file given or, with auto, the text subtitle file named after the
+// input, preferring a full one to a forced one, else the default text
+// subtitle stream of the video. It returns "" when auto finds none.
+func subtitlesToBurn(inputFile string, videoFile *ffmpeg.VideoFile) (string, int, error) {
+	if burnSubs != burnSubsAuto {
+		logger.Info("Burning in the subtitles of %s", burnSubs)
+		return burnSubs, 0, nil
+	}
+
+	if !ffmpeg.IsRemote(inputFile) {
+		files, err := sidecar.Find(inputFile, isVideoFile)
+		if err != nil {
+			logger.Warning("Could not look for sidecar files: %v", err)
+		}
+		var found *sidecar.File
+		for i, file := range files {
+			if file.Muxable() && (found == nil || found.Forced() && !file.Forced()) {
+				found = &files[i]
+			}
+		}
+		if found != nil {
+			logger.Info("Burning in the subtitles of %s", found.Path)
+			return found.Path, 0, nil
+		}
+	}
+
+	stream, bitmap := -1, ""
+	for i, sub := range videoFile.SubtitleInfo {
+		if !sub.IsText() {
+			bitmap = sub.Codec
+		} else if stream < 0 || sub.Default && !videoFile.SubtitleInfo[stream].Default {
+			stream = i
+		}
+	}
+	if stream >= 0 {
+		if language := videoFile.SubtitleInfo[stream].Language; language != "" {
+			logger.Info("Burning in subtitle stream %d (%s)", stream, language)
+		} else {
+			logger.Info("Burning in subtitle stream %d", stream)
+		}
+		return inputFile, stream, nil
+	}
+	if bitmap != "" {
+		return "", 0, i18n.Errorf("the subtitles of the video are images (%s), which cannot be burned in; give a subtitle file to --burn-subs", bitmap)
+	}
+	logger.Info("The video has no subtitles to burn in")
+	return "", 0, nil
+}
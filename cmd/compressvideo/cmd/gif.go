@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	animatedFormat   string  // Animated image format (gif, webp, avif)
+	animatedWidth    int     // Output width in pixels, 0 keeps the source width
+	animatedFPS      int     // Output frame rate, 0 keeps the source frame rate
+	animatedStart    float64 // Seconds skipped at the start of the clip
+	animatedDuration float64 // Seconds converted, 0 for the rest of the clip
+)
+
+// longAnimationSeconds is the clip length above which animated images get
+// large enough to suggest converting only a part
+const longAnimationSeconds = 30
+
+// gifCmd converts clips to animated images
+var gifCmd = &cobra.Command{
+	Use:   "gif [file]",
+	Short: "Convert a short clip to an animated GIF, WebP or AVIF",
+	Long: `Convert a short clip to an optimized animated image, e.g. to share it
+in a chat. GIFs are made in two passes: the first computes the best
+256-color palette for the clip and the second maps every frame onto it.
+WebP and AVIF are much smaller for the same quality where they are supported.
+
+Examples:
+  compressvideo gif clip.mp4
+  compressvideo gif clip.mp4 --width 320 --fps 10 --start 5 --duration 3
+  compressvideo gif clip.mp4 --to webp -o reaction.webp`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+		return gifCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gifCmd)
+
+	gifCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input video file or http(s) URL")
+	gifCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output image (default: named by --name-template with the format's extension)")
+	gifCmd.Flags().StringVar(&animatedFormat, "to", compressor.AnimatedGIF, "Image format: gif, webp or avif")
+	gifCmd.Flags().IntVar(&animatedWidth, "width", 480, "Output width in pixels, the height keeps the aspect ratio (0 = source width)")
+	gifCmd.Flags().IntVar(&animatedFPS, "fps", 15, "Output frame rate (0 = source frame rate)")
+	gifCmd.Flags().Float64Var(&animatedStart, "start", 0, "Start this many seconds into the clip")
+	gifCmd.Flags().Float64Var(&animatedDuration, "duration", 0, "Convert only this many seconds (0 = to the end)")
+	gifCmd.Flags().IntVarP(&quality, "quality", "q", 3, "Quality level (1-5): palette size and dithering for GIF, quality for WebP and AVIF")
+	gifCmd.Flags().StringVar(&nameTemplate, "name-template", batch.DefaultNameTemplate, "Output file name template with {name}, {ext}, {codec}, {quality} and {preset}")
+	gifCmd.Flags().BoolVarP(&force, "force", "f", false, "Force overwrite output file if it exists")
+	gifCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+}
+
+// validateGIFFlags checks the options of the gif command
+func validateGIFFlags() error {
+	if inputFile == "" {
+		return i18n.Errorf("an input file is required (compressvideo gif <file>)")
+	}
+	if ffmpeg.IsRemote(inputFile) {
+		// FFmpeg reads URLs directly
+	} else if stat, err := os.Stat(inputFile); err != nil {
+		return i18n.Errorf("input file does not exist: %s", inputFile)
+	} else if stat.IsDir() {
+		return i18n.Errorf("input must be a file, not a directory: %s", inputFile)
+	}
+	if !compressor.ValidAnimatedFormat(animatedFormat) {
+		return i18n.Errorf("animated format must be gif, webp or avif (got %s)", animatedFormat)
+	}
+	if quality < 1 || quality > 5 {
+		return i18n.Errorf("quality must be between 1-5 (got %d)", quality)
+	}
+	if animatedWidth < 0 || animatedFPS < 0 || animatedStart < 0 || animatedDuration < 0 {
+		return i18n.Errorf("width, fps, start and duration cannot be negative")
+	}
+	return batch.ValidateNameTemplate(nameTemplate)
+}
+
+// gifCommand converts the input to an animated image
+func gifCommand() error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Animated Image")
+
+	if err := validateGIFFlags(); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
+	// Name the image like a compressed video, with the format's extension
+	if outputFile == "" {
+		name := filepath.Base(inputFile)
+		dir := filepath.Dir(inputFile)
+		if ffmpeg.IsRemote(inputFile) {
+			name, dir = ffmpeg.RemoteBaseName(inputFile), "."
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name)) + "." + animatedFormat
+		outputFile = batch.ExpandCodec(filepath.Join(dir, defaultOutputName(name)), animatedFormat)
+	}
+	if _, err := os.Stat(outputFile); err == nil && !force {
+		return withExitCode(exitBadInput, i18n.Errorf("output file already exists (use -f to force overwrite): %s", outputFile))
+	}
+
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+
+	ffmpegInstance := ffmpeg.NewFFmpeg(inputFile, outputFile, &ffmpeg.Options{Quality: quality}, logger)
+	startDiagnosticJob(inputFile, ffmpegInstance)
+	videoFile, err := ffmpegInstance.GetVideoInfo(inputFile)
+	if err != nil {
+		return withExitCode(exitBadInput, i18n.Errorf("failed to get video info: %v", err))
+	}
+	if animatedDuration == 0 && videoFile.Duration-animatedStart > longAnimationSeconds {
+		logger.Warning("Animated images of long clips get large; use --start and --duration to convert a part")
+	}
+
+	logger.Field("Input File", "%s", inputFile)
+	logger.Field("Output File", "%s", outputFile)
+
+	progressBar := util.NewProgressTrackerWithOptions(util.ProgressTrackerOptions{
+		Total:          100,
+		Description:    i18n.T("Converting to %s", strings.ToUpper(animatedFormat)),
+		Logger:         logger,
+		ShowPercentage: true,
+		ShowSpeed:      true,
+	})
+
+	videoCompressor := compressor.NewVideoCompressor(ffmpegInstance, nil, logger)
+	opts := compressor.AnimatedOptions{
+		Format:   animatedFormat,
+		Width:    animatedWidth,
+		FPS:      animatedFPS,
+		Start:    animatedStart,
+		Duration: animatedDuration,
+	}
+	result, err := videoCompressor.ConvertAnimated(inputFile, outputFile, opts, quality, progressBar)
+	if err != nil {
+		logger.Error("Conversion failed: %v", err)
+		reportEncodeFailure(err)
+		return withExitCode(compressionExitCode(err), err)
+	}
+	progressBar.Finish()
+
+	logger.Success("Animated image saved to %s", outputFile)
+	logger.Field("Size", "%s", formatSize(result.CompressedSize))
+	if result.OriginalSize > 0 {
+		logger.Field("Size Change", "%.1f%% of the source", float64(result.CompressedSize)/float64(result.OriginalSize)*100)
+	}
+	return nil
+}
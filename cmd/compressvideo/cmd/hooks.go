@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/hooks"
+	"github.com/cccarv82/compressvideo/pkg/notify"
+)
+
+var (
+	hookFlags []string // Hooks given on the command line, as point=command
+
+	jobHooks *hooks.Hooks
+)
+
+func init() {
+	rootCmd.Flags().StringArrayVar(&hookFlags, "hook", nil, "Run a command with the job as JSON on stdin, as point=command; points are after_analysis, before_encode, after_encode and after_batch (repeatable)")
+}
+
+// validateHooks checks the --hook flags
+func validateHooks() error {
+	for _, value := range hookFlags {
+		if _, _, err := hooks.ParseFlag(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setupHooks collects the hooks of the config file and then those of the
+// command line, which run after them
+func setupHooks() {
+	commands := make(map[hooks.Point][]string)
+
+	// setupNotifier already reported a config file that cannot be read
+	if cfg, err := config.Load(config.DefaultPath()); err == nil {
+		for name, list := range cfg.Hooks {
+			if !hooks.ValidPoint(name) {
+				logger.Warning("Ignoring unknown hook point %s in the config file", name)
+				continue
+			}
+			commands[hooks.Point(name)] = append(commands[hooks.Point(name)], list...)
+		}
+	}
+	for _, value := range hookFlags {
+		point, command, _ := hooks.ParseFlag(value)
+		commands[point] = append(commands[point], command)
+	}
+
+	jobHooks = hooks.New(commands, logger)
+}
+
+// hookAnalysis returns the analysis of a video as hooks receive it
+func hookAnalysis(videoFile *ffmpeg.VideoFile, analysis *analyzer.VideoAnalysis) *hooks.Analysis {
+	return &hooks.Analysis{
+		ContentType:      analysis.ContentType.String(),
+		MotionComplexity: analysis.MotionComplexity.String(),
+		Duration:         videoFile.Duration,
+		Width:            videoFile.VideoInfo.Width,
+		Height:           videoFile.VideoInfo.Height,
+		FPS:              videoFile.VideoInfo.FPS,
+		VideoCodec:       videoFile.VideoInfo.Codec,
+		BitRate:          videoFile.BitRate,
+	}
+}
+
+// runAfterEncodeHooks runs the after_encode hooks with the result of a
+// file, which tells a failed encode by its error. A failing hook is
+// reported but leaves the output in place.
+func runAfterEncodeHooks(job hooks.Job, result *compressor.CompressionResult) {
+	if result != nil {
+		payload := notify.NewResultPayload(result)
+		job.Result = &payload
+		if result.Settings != nil {
+			job.Settings = result.Settings
+		}
+	}
+	if err := jobHooks.Run(hooks.AfterEncode, job); err != nil {
+		logger.Warning("%v", err)
+	}
+}
+
+// runAfterBatchHooks runs the after_batch hooks with the summary of a
+// directory run
+func runAfterBatchHooks(dir, outputDir string, failed int, started time.Time) {
+	if !jobHooks.Enabled(hooks.AfterBatch) {
+		return
+	}
+	summary := batchSummary(dir, failed, started)
+	job := hooks.Job{InputFile: dir, OutputFile: outputDir, Batch: &summary}
+	if err := jobHooks.Run(hooks.AfterBatch, job); err != nil {
+		logger.Warning("%v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	keyint     int     // Frames between keyframes
+	gopSeconds float64 // Seconds between keyframes
+)
+
+func init() {
+	rootCmd.Flags().IntVar(&keyint, "keyint", 0, "Frames between keyframes, instead of the analyzer's interval (1 second for screencasts, 2 for other content)")
+	rootCmd.Flags().Float64Var(&gopSeconds, "gop-seconds", 0, "Seconds between keyframes, e.g. 2 for streaming; turned into frames at the output frame rate")
+}
+
+// keyframes returns the keyframe interval given on the command line
+func keyframes() compressor.Keyframes {
+	return compressor.Keyframes{Frames: keyint, Seconds: gopSeconds}
+}
+
+// validateKeyframes checks the keyframe flags and the options they cannot
+// be combined with
+func validateKeyframes() error {
+	k := keyframes()
+	if err := k.Validate(); err != nil {
+		return err
+	}
+	if k.IsSet() && copyVideo {
+		return i18n.Errorf("the keyframe interval cannot be changed with --copy-video")
+	}
+	return nil
+}
+
+// applyKeyframes replaces the keyframe interval of settings with the one
+// given on the command line, recording it as a decision for --explain
+func applyKeyframes(settings map[string]string, videoFile *ffmpeg.VideoFile, contentAnalyzer *analyzer.ContentAnalyzer) error {
+	k := keyframes()
+	if !k.IsSet() {
+		return nil
+	}
+	if err := compressor.ApplyKeyframes(settings, k, videoFile.VideoInfo.FPS); err != nil {
+		return err
+	}
+	flag := "--keyint"
+	if k.Seconds > 0 {
+		flag = "--gop-seconds"
+	}
+	logger.Info("Using a keyframe every %s frames from %s", settings["gop"], flag)
+	contentAnalyzer.Record(analyzer.Decision{Name: "gop", Value: settings["gop"], Reason: flag + " was given", Override: flag})
+	return nil
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var lang string // Language for messages (en or pt-BR)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Language for messages: en or pt-BR (default: from LANG)")
+
+	// Select the message catalog before any command runs
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		commandStarted = true
+		i18n.SetLanguage(i18n.Detect(lang))
+	}
+}
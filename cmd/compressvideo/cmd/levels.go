@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// applyLevel brings the level of settings in line with the video the
+// filters and options produce, recording a change as a decision for
+// --explain, and fails when it is higher than the --compat devices decode
+func applyLevel(settings map[string]string, videoFile *ffmpeg.VideoFile, contentAnalyzer *analyzer.ContentAnalyzer) error {
+	changed, err := compressor.ApplyLevel(settings, videoFile)
+	if err != nil {
+		logger.Warning("%v", err)
+	}
+	if changed {
+		width, height := compressor.OutputSize(settings, videoFile)
+		fps := ffmpeg.OutputFPS(settings, videoFile.VideoInfo.FPS)
+		logger.Info("Using level %s for the %dx%d output at %.3g fps", settings["level"], width, height, fps)
+		contentAnalyzer.Record(analyzer.Decision{
+			Name:   "level",
+			Value:  settings["level"],
+			Reason: fmt.Sprintf("lowest level for the %dx%d output at %.3g fps", width, height, fps),
+		})
+	}
+
+	// Renditions are smaller than the source the settings describe
+	if compat == "" || renditions != "" {
+		return nil
+	}
+	// Checked by validateCompat
+	target, _ := compressor.LookupCompat(compat)
+	return target.CheckLevel(settings, videoFile)
+}
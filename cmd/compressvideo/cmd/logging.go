@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+var (
+	logFile       string // Write the full log, debug included, to this file
+	logFormat     string // Log record format (text or json)
+	logMaxSize    int    // Rotate the log file after this many MB
+	logMaxBackups int    // Number of rotated log files to keep
+	plainOutput   bool   // No colors or progress bars, for CI logs and containers
+	noProgress    bool   // Log progress lines instead of drawing progress bars
+	debugCommands bool   // Record every FFmpeg and FFprobe command to a session log
+)
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Also write the full log (debug included) to this file")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format (text or json); applies to the log file, or to the terminal without --log-file")
+	rootCmd.PersistentFlags().IntVar(&logMaxSize, "log-max-size", 10, "Rotate the log file when it reaches this size in MB (0 disables rotation)")
+	rootCmd.PersistentFlags().IntVar(&logMaxBackups, "log-max-backups", 3, "Number of rotated log files to keep")
+	rootCmd.PersistentFlags().BoolVar(&plainOutput, "plain", false, "Plain output for logs and CI: no colors or ANSI sequences, progress as log lines (default when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Log progress every 10% instead of drawing progress bars")
+	rootCmd.PersistentFlags().BoolVar(&debugCommands, "debug-commands", false, "Record every FFmpeg and FFprobe command, with its exit code and error output, to a log in ~/.compressvideo/logs")
+}
+
+// setupLogger creates the package logger from the verbose and logging flags
+func setupLogger() error {
+	logger = util.NewLogger(verbose)
+
+	format, err := util.ParseLogFormat(logFormat)
+	if err != nil {
+		return err
+	}
+	logger.SetFormat(format)
+
+	// The logger already goes plain when stdout is not a terminal
+	if plainOutput {
+		logger.SetUseColors(false)
+	}
+	if plainOutput || noProgress {
+		logger.PlainProgress = true
+	}
+
+	if logFile != "" {
+		if err := logger.SetLogFile(logFile, int64(logMaxSize)*1024*1024, logMaxBackups); err != nil {
+			return err
+		}
+	}
+
+	if debugCommands {
+		path, err := ffmpeg.StartCommandLog(ffmpeg.CommandLogDir())
+		if err != nil {
+			logger.Warning("Could not log FFmpeg commands: %v", err)
+		} else {
+			logger.Info("Logging FFmpeg commands to %s", path)
+		}
+	}
+
+	return nil
+}
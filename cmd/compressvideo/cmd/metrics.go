@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/metrics"
+)
+
+var (
+	metricsAddr      string // Address for the Prometheus /metrics endpoint
+	metricsToken     string // Bearer token the /metrics endpoint requires
+	metricsBasicAuth string // user:password the /metrics endpoint requires
+	metricsTLSCert   string // TLS certificate for the /metrics endpoint
+	metricsTLSKey    string // TLS private key for the /metrics endpoint
+
+	jobMetrics *metrics.JobMetrics
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&metricsToken, "metrics-token", "", "Require this token in an \"Authorization: Bearer\" header on the metrics endpoint")
+	rootCmd.Flags().StringVar(&metricsBasicAuth, "metrics-basic-auth", "", "Require HTTP basic auth as user:password on the metrics endpoint")
+	rootCmd.Flags().StringVar(&metricsTLSCert, "metrics-tls-cert", "", "Serve the metrics endpoint over HTTPS with this certificate file (PEM)")
+	rootCmd.Flags().StringVar(&metricsTLSKey, "metrics-tls-key", "", "Private key file (PEM) of --metrics-tls-cert")
+}
+
+// validateMetrics checks the protection options of the metrics endpoint
+func validateMetrics() error {
+	if metricsAddr == "" {
+		if metricsToken != "" || metricsBasicAuth != "" || metricsTLSCert != "" || metricsTLSKey != "" {
+			return i18n.Errorf("--metrics-token, --metrics-basic-auth and the --metrics-tls options need --metrics-addr")
+		}
+		return nil
+	}
+	if metricsBasicAuth != "" {
+		if user, _, ok := strings.Cut(metricsBasicAuth, ":"); !ok || user == "" {
+			return i18n.Errorf("--metrics-basic-auth must be user:password")
+		}
+	}
+	if (metricsTLSCert == "") != (metricsTLSKey == "") {
+		return i18n.Errorf("--metrics-tls-cert and --metrics-tls-key must be set together")
+	}
+	return nil
+}
+
+// metricsServeOptions returns the protection of the metrics endpoint the
+// flags ask for
+func metricsServeOptions() metrics.ServeOptions {
+	options := metrics.ServeOptions{
+		Auth:     metrics.Auth{Token: metricsToken},
+		CertFile: metricsTLSCert,
+		KeyFile:  metricsTLSKey,
+	}
+	if metricsBasicAuth != "" {
+		options.Auth.User, options.Auth.Password, _ = strings.Cut(metricsBasicAuth, ":")
+	}
+	return options
+}
+
+// startMetrics serves /metrics on --metrics-addr for the duration of the run.
+// It returns a function that stops the server.
+func startMetrics() func() {
+	if metricsAddr == "" {
+		return func() {}
+	}
+
+	jobMetrics = metrics.NewJobMetrics()
+	options := metricsServeOptions()
+	server, addr, err := jobMetrics.ServeWith(metricsAddr, options)
+	if err != nil {
+		logger.Warning("Failed to start metrics endpoint on %s: %v", metricsAddr, err)
+		jobMetrics = nil
+		return func() {}
+	}
+
+	if options.CertFile != "" {
+		logger.Info("Metrics available at https://%s/metrics", addr)
+	} else {
+		logger.Info("Metrics available at http://%s/metrics", addr)
+	}
+	if options.Auth.IsZero() && !isLoopback(addr) {
+		logger.Warning("The metrics endpoint is open to the network without credentials, protect it with --metrics-token or --metrics-basic-auth")
+	}
+	return func() { server.Close() }
+}
+
+// isLoopback reports whether a bound address only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// runJob processes one file and records the outcome in the metrics
+func runJob(inputFile, outputFile string, videoCache *cache.VideoAnalysisCache) error {
+	if jobMetrics == nil {
+		return runWithTimeouts(inputFile, outputFile, videoCache)
+	}
+
+	jobMetrics.ActiveJobs.Add(1)
+	defer jobMetrics.ActiveJobs.Add(-1)
+
+	start := time.Now()
+	err := runWithTimeouts(inputFile, outputFile, videoCache)
+	if err != nil {
+		jobMetrics.JobFailed()
+		return err
+	}
+
+	var inputSize, savedBytes int64
+	if stat, statErr := os.Stat(inputFile); statErr == nil {
+		inputSize = stat.Size()
+		if out, outErr := os.Stat(outputFile); outErr == nil {
+			savedBytes = inputSize - out.Size()
+		}
+	}
+	jobMetrics.JobSucceeded(inputSize, savedBytes, time.Since(start))
+
+	return nil
+}
+
+// setQueueDepth reports how many files are still waiting in a batch
+func setQueueDepth(n int) {
+	if jobMetrics != nil {
+		jobMetrics.QueueDepth.Set(float64(n))
+	}
+}
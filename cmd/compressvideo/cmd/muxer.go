@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+var (
+	noFaststart bool // Leave the MP4 index at the end of the file
+	fragmented  bool // Write fragmented MP4 for streaming
+)
+
+func init() {
+	rootCmd.Flags().BoolVar(&noFaststart, "no-faststart", false, "Leave the index of MP4 and MOV outputs at the end of the file, saving the pass that moves it to the front for playback while downloading")
+	rootCmd.Flags().BoolVar(&fragmented, "fragmented", false, "Write MP4 and MOV outputs as fragmented MP4, a fragment per keyframe, for streaming servers and players that play files as they arrive")
+}
+
+// applyMuxer sets how outputFile is laid out for playback
+func applyMuxer(settings map[string]string, outputFile string, videoFile *ffmpeg.VideoFile) error {
+	options := compressor.MuxerOptions{NoFaststart: noFaststart, Fragmented: fragmented}
+	if err := compressor.ApplyMuxer(settings, outputFile, videoFile.Duration, options); err != nil {
+		return err
+	}
+	if fragmented {
+		logger.Info("Writing fragmented MP4 for streaming")
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/hooks"
+	"github.com/cccarv82/compressvideo/pkg/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	notifyURL     string // Webhook that receives results
+	notifyDesktop bool   // Show desktop notifications
+
+	notifier     *notify.Notifier
+	batchResults []notify.ResultPayload        // Results collected during a directory run
+	lastResult   *compressor.CompressionResult // Result of the most recent file
+)
+
+// setupNotifier builds the notifier from flags, falling back to the config file
+func setupNotifier(cmd *cobra.Command) {
+	url, desktop := notifyURL, notifyDesktop
+
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		logger.Warning("%v", err)
+	} else {
+		if !cmd.Flags().Changed("notify-url") {
+			url = cfg.Notify.URL
+		}
+		if !cmd.Flags().Changed("notify-desktop") {
+			desktop = cfg.Notify.Desktop
+		}
+	}
+
+	notifier = notify.NewNotifier(url, desktop, logger)
+}
+
+// recordResult notifies about a finished file, or keeps it for the batch
+// summary sent to the notifier and the after_batch hooks
+func recordResult(result *compressor.CompressionResult) {
+	lastResult = result
+	if result == nil || !(notifier.Enabled() || jobHooks.Enabled(hooks.AfterBatch)) {
+		return
+	}
+	if batchPosition != "" {
+		batchResults = append(batchResults, notify.NewResultPayload(result))
+		return
+	}
+	notifier.FileCompleted(result)
+}
+
+// notifyBatch sends the summary of a directory run
+func notifyBatch(dir string, failed int, started time.Time) {
+	if !notifier.Enabled() {
+		return
+	}
+	notifier.BatchCompleted(batchSummary(dir, failed, started))
+}
+
+// batchSummary sums up the results of a directory run
+func batchSummary(dir string, failed int, started time.Time) notify.BatchSummary {
+	summary := notify.BatchSummary{
+		Directory: dir,
+		Failed:    failed,
+		Duration:  time.Since(started).Seconds(),
+		Results:   batchResults,
+	}
+	for _, result := range batchResults {
+		if result.Error == "" {
+			summary.Processed++
+			summary.SavedBytes += result.SavedSpaceBytes
+		}
+	}
+	return summary
+}
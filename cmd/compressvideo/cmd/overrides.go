@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	crfOverride          string // CRF that replaces the analyzer's
+	videoBitrateOverride string // Video bitrate that replaces the analyzer's
+	audioBitrateOverride string // Audio bitrate that replaces the analyzer's
+	pixFmtOverride       string // Pixel format that replaces the analyzer's
+	tuneOverride         string // Encoder tuning that replaces the analyzer's
+	extraFFmpegArgs      string // FFmpeg options added to the encoding command, quoted as in a shell
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&crfOverride, "crf", "", "CRF to encode with instead of the analyzer's, on the scale of the codec used (0-51 for h264 and hevc, 0-63 for vp9)")
+	rootCmd.Flags().StringVar(&videoBitrateOverride, "video-bitrate", "", "Video bitrate to encode with instead of the analyzer's (e.g. 4M or 2500k)")
+	rootCmd.Flags().StringVar(&audioBitrateOverride, "audio-bitrate", "", "Audio bitrate to encode with instead of the analyzer's (e.g. 128k); copied audio is re-encoded to AAC")
+	rootCmd.Flags().StringVar(&pixFmtOverride, "pix-fmt", "", "Pixel format to encode with instead of yuv420p (e.g. yuv420p10le)")
+	rootCmd.Flags().StringVar(&tuneOverride, "tune", "", "Encoder tuning to use instead of the analyzer's (e.g. film, animation or grain for h264)")
+	rootCmd.Flags().StringVar(&extraFFmpegArgs, "extra-ffmpeg-args", "", "FFmpeg output options to add after the tool's, quoted as in a shell (e.g. \"-movflags +faststart -bf 4\"); -i, -y and the options the tool relies on are refused")
+}
+
+// overrides returns the encoder settings given on the command line
+func overrides() compressor.Overrides {
+	// Quoting errors are reported by validateOverrides
+	extraArgs, _ := ffmpeg.SplitArgs(extraFFmpegArgs)
+	return compressor.Overrides{
+		CRF:          crfOverride,
+		VideoBitrate: videoBitrateOverride,
+		AudioBitrate: audioBitrateOverride,
+		PixFmt:       pixFmtOverride,
+		Tune:         tuneOverride,
+		ExtraArgs:    extraArgs,
+	}
+}
+
+// validateOverrides checks the override flags and the options they cannot
+// be combined with. Whether they suit the codec is checked once it is known.
+func validateOverrides() error {
+	if _, err := ffmpeg.SplitArgs(extraFFmpegArgs); err != nil {
+		return i18n.Errorf("invalid --extra-ffmpeg-args: %v", err)
+	}
+	o := overrides()
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	if o.CRF != "" && targetVMAF > 0 {
+		return i18n.Errorf("--crf and --target-vmaf cannot be used together")
+	}
+	// Hardware encoders run in constant quality mode with settings of their own
+	if hwEncoder != "" {
+		for _, name := range []string{"crf", "bitrate", "pix_fmt", "tune"} {
+			if contains(o.Names(), name) {
+				return i18n.Errorf("%s applies to software encoders and cannot be used with --hw-encoder", compressor.OverrideFlag(name))
+			}
+		}
+	}
+	if o.VideoBitrate != "" && renditions != "" {
+		return i18n.Errorf("--video-bitrate cannot be used with --renditions, whose bitrates follow each height")
+	}
+	if o.AudioBitrate != "" && copyAudio {
+		return i18n.Errorf("--audio-bitrate cannot be used with --copy-audio")
+	}
+	return nil
+}
+
+// applyOverrides replaces the analyzer's settings with the ones given on the
+// command line, recording each as a decision for --explain
+func applyOverrides(settings map[string]string, contentAnalyzer *analyzer.ContentAnalyzer) error {
+	o := overrides()
+	if err := compressor.ApplyOverrides(settings, o); err != nil {
+		return err
+	}
+	for _, name := range o.Names() {
+		// Files without audio have no audio bitrate to set
+		if settings[name] == "" {
+			continue
+		}
+		flag := compressor.OverrideFlag(name)
+		logger.Info("Using %s %s from %s instead of the analyzer's", name, settings[name], flag)
+		contentAnalyzer.Record(analyzer.Decision{Name: name, Value: settings[name], Reason: flag + " was given", Override: flag})
+	}
+	if len(o.ExtraArgs) > 0 {
+		logger.Info("Adding FFmpeg options from --extra-ffmpeg-args: %s", strings.Join(o.ExtraArgs, " "))
+	}
+	return nil
+}
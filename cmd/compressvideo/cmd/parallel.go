@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	parallelMode string // Parallel mode (auto, on, off)
+	segmentCount int    // Number of parallel segments (0 = picked from the machine)
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&parallelMode, "parallel", compressor.ParallelAuto, "Encode long videos in parallel segments: auto decides from the encoder, CPU cores, RAM and source bitrate, on always splits, off never does")
+	rootCmd.Flags().IntVar(&segmentCount, "segments", 0, "Number of segments encoded at once, splitting even when --parallel auto would not (0 = one per CPU core, at most 8, fewer when RAM is short)")
+}
+
+// validateParallel checks the parallel flags and the options they cannot be
+// combined with
+func validateParallel() error {
+	if !compressor.ValidParallel(parallelMode) {
+		return i18n.Errorf("parallel must be one of: auto, on, off (got %s)", parallelMode)
+	}
+	if segmentCount < 0 || segmentCount == 1 {
+		return i18n.Errorf("segments must be 2 or more (got %d)", segmentCount)
+	}
+	if segmentCount > 0 && parallelMode == compressor.ParallelOff {
+		return i18n.Errorf("--segments cannot be used with --parallel off")
+	}
+	return nil
+}
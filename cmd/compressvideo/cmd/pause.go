@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// pauseKey toggles the pause of the running encodes on a terminal
+const pauseKey = 'p'
+
+// exitInterrupted is the conventional exit code after Ctrl+C
+const exitInterrupted = 130
+
+// startPauseControls lets the user pause the running encodes to free the
+// CPU and resume them later: the p key toggles the pause when stdin is a
+// terminal, and the signals in util.PauseSignals toggle it otherwise.
+// --confirm reads its answers from stdin, so it leaves the keyboard alone.
+// The returned function stops the controls.
+func startPauseControls() (stop func()) {
+	toggles := make(chan os.Signal, 1)
+	if len(util.PauseSignals) > 0 {
+		signal.Notify(toggles, util.PauseSignals...)
+	}
+
+	keys := false
+	restore := func() {}
+	if !confirm && util.IsTerminal(os.Stdin) {
+		if restoreTerminal, err := util.EnableKeyPresses(os.Stdin); err != nil {
+			logger.Debug("Keyboard controls unavailable: %v", err)
+		} else {
+			keys = true
+			restore = restoreTerminal
+		}
+	}
+
+	done := make(chan struct{})
+	if keys {
+		logger.Info("Press p to pause or resume encoding")
+		go readPauseKeys(toggles, done)
+
+		// Ctrl+C would otherwise leave the terminal without echo and
+		// paused FFmpeg processes behind
+		interrupts := make(chan os.Signal, 1)
+		signal.Notify(interrupts, os.Interrupt)
+		go func() {
+			select {
+			case <-interrupts:
+				restore()
+				ffmpeg.ReleaseAll()
+				os.Exit(exitInterrupted)
+			case <-done:
+				signal.Stop(interrupts)
+			}
+		}()
+	}
+
+	go func() {
+		for {
+			select {
+			case <-toggles:
+				togglePause(keys)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(toggles)
+		close(done)
+		restore()
+		ffmpeg.ReleaseAll()
+	}
+}
+
+// readPauseKeys turns presses of the pause key into toggles until done
+func readPauseKeys(toggles chan<- os.Signal, done <-chan struct{}) {
+	buf := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(buf); err != nil {
+			return
+		}
+		if buf[0] != pauseKey && buf[0] != pauseKey-'a'+'A' {
+			continue
+		}
+		select {
+		case toggles <- os.Interrupt: // Any value toggles
+		case <-done:
+			return
+		}
+	}
+}
+
+// togglePause pauses the running encodes, or resumes them when the user
+// paused them. Pauses held for other reasons, such as --only-between, stay.
+func togglePause(keys bool) {
+	if ffmpeg.Held(ffmpeg.PauseUser) {
+		if err := ffmpeg.Resume(); err != nil {
+			logger.Warning("Could not resume every FFmpeg process: %v", err)
+		}
+		if reasons := ffmpeg.PauseReasons(); len(reasons) > 0 {
+			logger.Info("Encoding stays paused for: %s", strings.Join(reasons, ", "))
+			return
+		}
+		logger.Info("Encoding resumed")
+		return
+	}
+
+	if err := ffmpeg.Pause(); err != nil {
+		logger.Warning("Could not pause every FFmpeg process: %v", err)
+	}
+	if keys {
+		logger.Info("Encoding paused, press p to resume")
+	} else {
+		logger.Info("Encoding paused, send the same signal again to resume")
+	}
+}
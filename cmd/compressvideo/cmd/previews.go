@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// validatePreviews checks the poster and sprite options
+func validatePreviews() error {
+	if spriteInterval <= 0 {
+		return i18n.Errorf("sprite interval must be more than 0 seconds (got %g)", spriteInterval)
+	}
+	return nil
+}
+
+// writePreviews writes the poster frame and preview sprite requested with
+// --poster and --sprite next to outputFile, taken from the compressed video
+// at videoPath. Failures only warn: the compressed video is already done.
+func writePreviews(outputFile, videoPath string, videoFile *ffmpeg.VideoFile, ffmpegInstance *ffmpeg.FFmpeg) {
+	if !writePoster && !writeSprite {
+		return
+	}
+	base := strings.TrimSuffix(outputFile, filepath.Ext(outputFile))
+
+	if writePoster {
+		posterPath := base + ".jpg"
+		if err := ffmpegInstance.ExtractPoster(videoPath, posterPath, videoFile.Duration); err != nil {
+			logger.Warning("Failed to write poster frame: %v", err)
+		} else {
+			logger.Field("Poster", "%s", posterPath)
+		}
+	}
+
+	if writeSprite {
+		// Cropping and scaling may have changed the frame size of the source
+		preview, err := ffmpegInstance.GetVideoInfo(videoPath)
+		if err != nil {
+			copied := *videoFile
+			copied.Path = videoPath
+			preview = &copied
+		}
+		imagePath := base + "-sprite.jpg"
+		vttPath := base + "-sprite.vtt"
+
+		opts := ffmpeg.DefaultSpriteOptions()
+		opts.Interval = spriteInterval
+		layout, err := ffmpegInstance.GenerateSprite(preview, imagePath, filepath.Base(imagePath), vttPath, opts)
+		if err != nil {
+			logger.Warning("Failed to write preview sprite: %v", err)
+		} else {
+			logger.Field("Preview Sprite", "%s (%d thumbnails, one every %gs)", vttPath, layout.Count, layout.Interval)
+		}
+	}
+}
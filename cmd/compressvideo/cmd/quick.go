@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// quickCmd compresses videos without asking anything, for file manager
+// entries such as Send to and Quick Actions
+var quickCmd = &cobra.Command{
+	Use:   "quick [files]",
+	Short: "Compress videos with the default settings, next to the originals, and notify when done",
+	Long: `Compress each video with the default settings and write it next to the
+original as <name>-compressed<ext>, numbered when that name is taken. Nothing
+is asked and a desktop notification shows the result of each file, so it
+suits a file manager entry.
+
+'compressvideo quick install' adds "Compress with CompressVideo" to the file
+manager: the Send to menu on Windows, the Quick Actions of Finder on macOS
+and the Scripts menu of Nautilus on Linux. 'compressvideo quick uninstall'
+removes it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return quickCommand(cmd, args)
+	},
+}
+
+// quickInstallCmd adds the file manager entry
+var quickInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Add \"Compress with CompressVideo\" to the file manager (Send to, Quick Actions or Nautilus scripts)",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		executable, err := os.Executable()
+		if err == nil {
+			executable, err = filepath.EvalSymlinks(executable)
+		}
+		if err != nil {
+			return i18n.Errorf("could not find the path of compressvideo: %v", err)
+		}
+		path, err := util.InstallQuickAction(executable)
+		if err != nil {
+			return err
+		}
+		cmd.Println(i18n.T("Installed %q at %s", util.QuickActionName, path))
+		return nil
+	},
+}
+
+// quickUninstallCmd removes the file manager entry
+var quickUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove \"Compress with CompressVideo\" from the file manager",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := util.UninstallQuickAction()
+		if err != nil {
+			return err
+		}
+		cmd.Println(i18n.T("Removed %s", path))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(quickCmd)
+	quickCmd.AddCommand(quickInstallCmd)
+	quickCmd.AddCommand(quickUninstallCmd)
+}
+
+// quickCommand compresses each file next to itself with the default
+// settings, notifying the desktop of each result
+func quickCommand(cmd *cobra.Command, files []string) error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Quick")
+
+	if err := loadAnalysisParams(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+
+	// The notification is the only sign of the result when no window is open
+	setupNotifier(cmd)
+	notifier.Desktop = true
+
+	videoCache := openLedger()
+	if videoCache != nil {
+		defer videoCache.Close()
+	}
+
+	var firstErr error
+	for _, file := range files {
+		inputFile, err := filepath.Abs(file)
+		if err == nil {
+			if info, statErr := os.Stat(inputFile); statErr != nil {
+				err = withExitCode(exitBadInput, statErr)
+			} else if info.IsDir() {
+				err = withExitCode(exitBadInput, i18n.Errorf("%s is a folder, not a video", file))
+			}
+		}
+		outputFile := batch.FreeName(defaultOutputName(inputFile))
+
+		lastResult = nil
+		if err == nil {
+			logger.Section(filepath.Base(inputFile))
+			err = runJob(inputFile, outputFile, videoCache)
+		}
+		if err == nil {
+			continue
+		}
+
+		logger.Error("Failed to process %s: %v", file, err)
+		if lastResult == nil || lastResult.Error == nil {
+			notifier.FileCompleted(&compressor.CompressionResult{InputFile: inputFile, Error: err})
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
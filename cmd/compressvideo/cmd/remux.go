@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+var remuxOnly string // Container to copy the streams into, without encoding
+
+func init() {
+	rootCmd.Flags().StringVar(&remuxOnly, "remux-only", "", "Copy the streams unchanged into another container ("+strings.Join(compressor.RemuxContainers, ", ")+"), e.g. MKV to MP4, without analyzing or encoding; the output gets faststart and loses the tags of the old file")
+}
+
+// validateRemux checks --remux-only and the options it cannot be combined with
+func validateRemux() error {
+	if remuxOnly == "" {
+		return nil
+	}
+	remuxOnly = strings.ToLower(strings.TrimPrefix(remuxOnly, "."))
+	if !compressor.ValidRemuxContainer(remuxOnly) {
+		return i18n.Errorf("remux container must be one of: %s (got %s)", strings.Join(compressor.RemuxContainers, ", "), remuxOnly)
+	}
+	conflicts := []struct {
+		flag string
+		set  bool
+	}{
+		{"--codec", codec != "" && codec != analyzer.CodecAuto},
+		{"--target-vmaf", targetVMAF > 0},
+		{"--hw-encoder", hwEncoder != ""},
+		{"--copy-video", copyVideo},
+		{"--copy-audio", copyAudio},
+		{"--no-audio", noAudio},
+		{"--audio-channels", audioChannels != "" && audioChannels != compressor.AudioChannelsKeep},
+		{"--keep-audio-langs", keepAudioLangs != ""},
+		{"--compat", compat != ""},
+		{"--renditions", renditions != ""},
+		{"--make-comparison", makeComparison},
+		{"--keyint", keyframes().IsSet()},
+		{"video filters", len(videoFilters().Stages()) > 0},
+		{"--extra-ffmpeg-args", extraFFmpegArgs != ""},
+	}
+	if names := overrides().Names(); len(names) > 0 {
+		return i18n.Errorf("--remux-only copies the streams unchanged and cannot be used with %s", compressor.OverrideFlag(names[0]))
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			return i18n.Errorf("--remux-only copies the streams unchanged and cannot be used with %s", conflict.flag)
+		}
+	}
+	return nil
+}
+
+// remuxExt returns the extension of the outputs of --remux-only, empty
+// when the container is kept
+func remuxExt() string {
+	if remuxOnly == "" {
+		return ""
+	}
+	return "." + remuxOnly
+}
+
+// remuxFile copies the streams of inputFile into the container of
+// --remux-only, recording the result like an encode so later runs skip it
+func remuxFile(inputFile, outputFile string, ffmpegInstance *ffmpeg.FFmpeg, videoCache *cache.VideoAnalysisCache, paramsHash string) error {
+	if ext := strings.ToLower(filepath.Ext(outputFile)); ext != remuxExt() && !(remuxOnly == "mp4" && ext == ".m4v") {
+		return i18n.Errorf("--remux-only %s needs a .%s output (got %s)", remuxOnly, remuxOnly, filepath.Base(outputFile))
+	}
+
+	videoFile, err := ffmpegInstance.GetVideoInfo(inputFile)
+	if err != nil {
+		return withExitCode(exitBadInput, i18n.Errorf("failed to get video info: %v", err))
+	}
+	displayVideoInfo(videoFile)
+
+	logger.Section("Remux")
+	logger.Info("Copying the streams of %s into %s", filepath.Base(inputFile), filepath.Base(outputFile))
+	videoCompressor := compressor.NewVideoCompressor(ffmpegInstance, nil, logger)
+	result, err := videoCompressor.Remux(inputFile, outputFile, videoFile, compressor.MuxerOptions{NoFaststart: noFaststart, Fragmented: fragmented})
+	if err != nil {
+		logger.Error("Remux failed: %v", err)
+		return err
+	}
+
+	handleSidecars(inputFile, outputFile, result.Settings, videoCompressor)
+	if preserveTimes && !ffmpeg.IsRemote(inputFile) {
+		if err := util.PreserveFileAttributes(inputFile, outputFile); err != nil {
+			logger.Warning("Failed to preserve file times: %v", err)
+		}
+	}
+
+	logger.Success("Remuxed %s to %s in %s (%s, was %s)", filepath.Base(inputFile), filepath.Base(outputFile),
+		result.ProcessingTime.Round(1e6), formatSize(result.CompressedSize), formatSize(result.OriginalSize))
+
+	if videoCache != nil && useCache {
+		if err := videoCache.PutOutcome(inputFile, paramsHash, result); err != nil {
+			logger.Warning("Failed to cache compression outcome: %v", err)
+		}
+	}
+	if videoCache != nil {
+		if err := videoCache.RecordCompression(inputFile, outputFile); err != nil {
+			logger.Warning("Failed to record the compression in the ledger: %v", err)
+		}
+	}
+	recordResult(result)
+	return nil
+}
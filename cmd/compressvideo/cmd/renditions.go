@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Layouts of a quality ladder accepted by --renditions-format
+const (
+	renditionsFiles = "files" // One video file per rendition
+	renditionsHLS   = "hls"   // HLS media playlists and a master playlist
+)
+
+// validateRenditions checks the quality ladder options
+func validateRenditions() error {
+	if renditionsFormat != renditionsFiles && renditionsFormat != renditionsHLS {
+		return i18n.Errorf("renditions format must be files or hls (got %s)", renditionsFormat)
+	}
+	if renditions == "" {
+		return nil
+	}
+	if _, err := compressor.ParseRenditions(renditions); err != nil {
+		return err
+	}
+	if copyVideo {
+		return i18n.Errorf("--renditions scales the video and cannot be used with --copy-video")
+	}
+	if s3Output != "" {
+		return i18n.Errorf("--renditions cannot be used with s3:// outputs")
+	}
+	return nil
+}
+
+// renditionLadder names the outputs of each rendition after outputFile:
+// clip-compressed.mp4 becomes clip-compressed-720p.mp4, or for HLS the
+// directory clip-compressed with master.m3u8 and 720p/index.m3u8
+func renditionLadder(outputFile string, renditions []compressor.Rendition) compressor.Ladder {
+	ext := filepath.Ext(outputFile)
+	base := strings.TrimSuffix(outputFile, ext)
+
+	var ladder compressor.Ladder
+	if renditionsFormat == renditionsHLS {
+		ladder.MasterPlaylist = filepath.Join(base, "master.m3u8")
+	}
+	for _, r := range renditions {
+		output := base + "-" + r.Name + ext
+		if ladder.MasterPlaylist != "" {
+			output = filepath.Join(base, r.Name, "index.m3u8")
+		}
+		ladder.Outputs = append(ladder.Outputs, compressor.RenditionOutput{Rendition: r, OutputFile: output})
+	}
+	return ladder
+}
+
+// compressRenditions encodes the --renditions ladder of inputFile with the
+// settings of its analysis
+func compressRenditions(inputFile, outputFile string, videoFile *ffmpeg.VideoFile, analysis *analyzer.VideoAnalysis,
+	settings map[string]string, ffmpegInstance *ffmpeg.FFmpeg, contentAnalyzer *analyzer.ContentAnalyzer,
+	videoCache *cache.VideoAnalysisCache) error {
+	// Renditions were checked by validateFlags
+	all, _ := compressor.ParseRenditions(renditions)
+
+	// Upscaling only wastes bits
+	kept, dropped := compressor.LadderFor(all, videoFile.VideoInfo.Height)
+	for _, r := range dropped {
+		logger.Info("Skipping rendition %s: the source is only %dp", r.Name, videoFile.VideoInfo.Height)
+	}
+	if len(kept) == 0 {
+		return withExitCode(exitBadInput, i18n.Errorf("every rendition is taller than the %dp source", videoFile.VideoInfo.Height))
+	}
+
+	ladder := renditionLadder(outputFile, kept)
+	outputs := []string{ladder.MasterPlaylist}
+	if ladder.MasterPlaylist == "" {
+		outputs = outputs[:0]
+		for _, output := range ladder.Outputs {
+			outputs = append(outputs, output.OutputFile)
+		}
+	}
+	for _, output := range outputs {
+		if _, err := os.Stat(output); err == nil && !force {
+			return i18n.Errorf("output file already exists (use -f to force overwrite): %s", output)
+		}
+	}
+
+	logger.Section("Renditions")
+	for _, output := range ladder.Outputs {
+		logger.Field(output.Name, "%s", output.OutputFile)
+	}
+	if ladder.MasterPlaylist != "" {
+		logger.Field("Master Playlist", "%s", ladder.MasterPlaylist)
+	}
+
+	videoCompressor := compressor.NewVideoCompressor(ffmpegInstance, contentAnalyzer, logger)
+	videoCompressor.TargetVMAF = targetVMAF
+	videoCompressor.HardwareEncoder = hwEncoder
+	videoCompressor.Threads = threads
+	videoCompressor.IgnoreErrors = ignoreErrors
+	videoCompressor.Overrides = overrides()
+	videoCompressor.HDR10Plus = keepHDR10Plus
+	videoCompressor.Throttle = encodeThrottle
+
+	description := i18n.T("Compressing %d renditions", len(ladder.Outputs))
+	if batchPosition != "" {
+		description = i18n.T("[%s] Compressing %s", batchPosition, filepath.Base(inputFile))
+	}
+	progressBar := util.NewProgressTrackerWithOptions(util.ProgressTrackerOptions{
+		Total:          100,
+		Description:    description,
+		Logger:         logger,
+		ShowPercentage: true,
+		ShowSpeed:      true,
+	})
+
+	logger.Section("Compression Process")
+	results, err := videoCompressor.CompressRenditions(inputFile, ladder, analysis, settings, quality, preset, progressBar)
+	if err != nil {
+		logger.Error("Compression failed: %v", err)
+		reportEncodeFailure(err)
+		var failed *compressor.CompressionResult
+		for _, result := range results {
+			if result != nil {
+				failed = result
+			}
+		}
+		recordResult(failed)
+		return withExitCode(compressionExitCode(err), err)
+	}
+	progressBar.Finish()
+
+	logger.Success("Encoded %d renditions of %s", len(results), filepath.Base(inputFile))
+	for i, result := range results {
+		logger.Field(ladder.Outputs[i].Name, "%s (%.1f%% smaller)", formatSize(result.CompressedSize), result.SavedSpacePercent)
+
+		if preserveTimes && ladder.MasterPlaylist == "" && !ffmpeg.IsRemote(inputFile) {
+			if err := util.PreserveFileAttributes(inputFile, result.OutputFile); err != nil {
+				logger.Warning("Failed to preserve file times: %v", err)
+			}
+		}
+	}
+
+	// Poster, seek previews and comparison come from the largest rendition
+	writePreviews(outputFile, results[0].OutputFile, videoFile, ffmpegInstance)
+	writeComparison(inputFile, outputFile, results[0].OutputFile, analysis, ffmpegInstance)
+
+	// Later runs skip the source and the renditions wherever they end up
+	if videoCache != nil {
+		for _, output := range outputs {
+			if err := videoCache.RecordCompression(inputFile, output); err != nil {
+				logger.Warning("Failed to record the compression in the ledger: %v", err)
+			}
+		}
+	}
+
+	// Notifications and batch results describe the largest rendition
+	recordResult(results[0])
+	return nil
+}
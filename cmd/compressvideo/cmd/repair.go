@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// repairFFmpegCmd reinstalls the FFmpeg build managed by CompressVideo
+var repairFFmpegCmd = &cobra.Command{
+	Use:   "repair-ffmpeg",
+	Short: "Repair FFmpeg installation",
+	Long: `Repair the FFmpeg installation used by CompressVideo.
+
+This command is useful when you encounter issues with FFmpeg, such as:
+- "Failed to get video information" errors
+- Exit status errors with FFmpeg or FFprobe
+- Missing codecs or format support
+
+The repair process will:
+1. Remove the existing FFmpeg installation
+2. Download a fresh copy of FFmpeg
+3. Verify the installation works correctly`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return repairFFmpegCommand()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(repairFFmpegCmd)
+}
+
+func repairFFmpegCommand() error {
+	// The download steps are only shown in verbose mode
+	verbose = true
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - FFmpeg Repair Tool")
+	logger.Info("Starting FFmpeg repair process...")
+
+	if err := applyDownloadLimit(); err != nil {
+		return err
+	}
+
+	info, err := util.RepairFFmpeg(logger)
+	if err != nil {
+		return withExitCode(exitFFmpegMissing, err)
+	}
+
+	logger.Success("FFmpeg repair completed successfully!")
+	logger.Field("FFmpeg Version", "%s", info.Version)
+	logger.Field("FFmpeg Path", "%s", info.Path)
+	if info.FFprobePath != "" {
+		logger.Field("FFprobe Path", "%s", info.FFprobePath)
+	}
+	logger.Info("You can now use CompressVideo normally.")
+	return nil
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var resume bool // Continue the last directory run of the same command
+
+// runProgress is the progress file of the current directory run, nil for
+// single files
+var runProgress *batch.Progress
+
+func init() {
+	rootCmd.Flags().BoolVar(&resume, "resume", false, "Continue the last directory run of the same command: skip the files it completed, even if their outputs were moved, and retry those that failed")
+}
+
+// validateResume checks that --resume is given a directory
+func validateResume() error {
+	if !resume {
+		return nil
+	}
+	if info, err := os.Stat(inputFile); err != nil || !info.IsDir() {
+		return i18n.Errorf("--resume requires a directory input")
+	}
+	return nil
+}
+
+// startProgress opens the progress file of a directory run. With --resume
+// it is the one of the last run of the same input, output and options, with
+// any new files added as pending; otherwise every file starts pending.
+func startProgress(inputDir, outputDir string, jobs []batch.Job) *batch.Progress {
+	params := compressionParamsHash()
+	path := batch.ProgressPath(batch.ProgressDir(), inputDir, outputDir, params)
+	if resume {
+		progress, err := batch.LoadProgress(path)
+		if err == nil {
+			progress.Plan(jobs)
+			completed, failed, pending := progress.Counts()
+			logger.Info("Resuming the last run: %d completed, %d failed, %d pending", completed, failed, pending)
+			return progress
+		}
+		if os.IsNotExist(err) {
+			logger.Warning("No earlier run of this command to resume, starting from the beginning")
+		} else {
+			logger.Warning("Failed to read the progress of the last run, starting from the beginning: %v", err)
+		}
+	}
+
+	progress := batch.NewProgress(path, inputDir, outputDir, params, jobs)
+	if err := progress.Save(); err != nil {
+		logger.Warning("Failed to save the progress of this run, it cannot be resumed: %v", err)
+	} else {
+		logger.Debug("Progress file: %s", progress.Path())
+	}
+	return progress
+}
+
+// recordProgress records the status of input in the progress file of the
+// directory run
+func recordProgress(input, status string) {
+	if runProgress == nil {
+		return
+	}
+	if err := runProgress.Set(input, status); err != nil {
+		logger.Debug("Failed to save the progress of this run: %v", err)
+	}
+}
+
+// recordFailure records that input failed after attempts, with the class
+// of err, in the progress file of the directory run
+func recordFailure(input string, attempts int, err error) {
+	if runProgress == nil {
+		return
+	}
+	failure := batch.Failure{Input: input, Class: errorClass(err), Error: err.Error(), Attempts: attempts, Time: time.Now()}
+	if saveErr := runProgress.Fail(failure); saveErr != nil {
+		logger.Debug("Failed to save the progress of this run: %v", saveErr)
+	}
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	retries       int           // Times a failed file of a batch is tried again
+	retryBackoff  time.Duration // Wait before the first retry, doubled before each next one
+	retryWith     string        // Alternative settings of the retries, see batch.RetryAlternatives
+	quarantineDir string        // Directory the sources that keep failing are moved to
+)
+
+// Error classes recorded for failed files
+const (
+	errorClassTruncated    = "truncated"
+	errorClassInvalidData  = "invalid_data"
+	errorClassNotFound     = "not_found"
+	errorClassPermission   = "permission"
+	errorClassDiskFull     = "disk_full"
+	errorClassPixelFormat  = "pixel_format"
+	errorClassEncoder      = "encoder"
+	errorClassNetwork      = "network"
+	errorClassVerification = "verification"
+	errorClassTimeout      = "timeout"
+	errorClassOther        = "other"
+)
+
+// errorClasses maps the failures FFmpeg reports to their class
+var errorClasses = []struct {
+	kind  error
+	class string
+}{
+	{ffmpeg.ErrTruncatedInput, errorClassTruncated},
+	{ffmpeg.ErrInvalidData, errorClassInvalidData},
+	{ffmpeg.ErrFileNotFound, errorClassNotFound},
+	{ffmpeg.ErrPermissionDenied, errorClassPermission},
+	{ffmpeg.ErrDiskFull, errorClassDiskFull},
+	{ffmpeg.ErrPixelFormat, errorClassPixelFormat},
+	{ffmpeg.ErrUnknownEncoder, errorClassEncoder},
+	{ffmpeg.ErrNetwork, errorClassNetwork},
+	{compressor.ErrVerificationFailed, errorClassVerification},
+}
+
+func init() {
+	for _, command := range []*cobra.Command{rootCmd, batchCmd} {
+		command.Flags().IntVar(&retries, "retries", 0, "In batches, try a file that fails again up to this many times before moving on")
+		command.Flags().DurationVar(&retryBackoff, "retry-backoff", 30*time.Second, "Wait this long before the first retry of a file, twice as long before each next one (at most 1h)")
+		command.Flags().StringVar(&retryWith, "retry-with", "", "Settings each retry adds, in order: "+strings.Join(batch.RetryAlternatives, ", ")+" (e.g. software,ignore-errors)")
+		command.Flags().StringVar(&quarantineDir, "quarantine-dir", "", "Move the sources that still fail after every retry into this directory, with a note of the error, for inspection")
+	}
+}
+
+// validateRetry checks --retries, --retry-backoff, --retry-with and --quarantine-dir
+func validateRetry() error {
+	if retries < 0 {
+		return i18n.Errorf("retries must be 0 or more (got %d)", retries)
+	}
+	if retryBackoff < 0 {
+		return i18n.Errorf("retry-backoff must be 0 or more (got %s)", retryBackoff)
+	}
+	if _, err := batch.ParseRetryWith(retryWith); err != nil {
+		return err
+	}
+	if quarantineDir != "" {
+		if info, err := os.Stat(quarantineDir); err == nil && !info.IsDir() {
+			return i18n.Errorf("quarantine-dir is not a directory: %s", quarantineDir)
+		}
+	}
+	return nil
+}
+
+// retryPolicy returns the retry policy of the flags, checked by validateRetry
+func retryPolicy() batch.RetryPolicy {
+	with, _ := batch.ParseRetryWith(retryWith)
+	return batch.RetryPolicy{Retries: retries, Backoff: retryBackoff, With: with}
+}
+
+// errorClass returns the class of a failure to compress a file
+func errorClass(err error) string {
+	for _, entry := range errorClasses {
+		if errors.Is(err, entry.kind) {
+			return entry.class
+		}
+	}
+	if exitCode(err) == exitTimedOut {
+		return errorClassTimeout
+	}
+	return errorClassOther
+}
+
+// retryable reports whether a failure of class may go away on another try.
+// Missing and unreadable sources fail the same way every time.
+func retryable(class string) bool {
+	return class != errorClassNotFound && class != errorClassPermission
+}
+
+// runJobWithRetries processes one file of a batch, trying it again as the
+// retry policy says when it fails. It returns how many attempts were made.
+func runJobWithRetries(inputFile, outputFile string, videoCache *cache.VideoAnalysisCache) (int, error) {
+	policy := retryPolicy()
+	start := time.Now()
+	err := runJob(inputFile, outputFile, videoCache)
+	attempts := 1
+	for retry := 1; err != nil && retry <= policy.Retries; retry++ {
+		class := errorClass(err)
+		if !retryable(class) {
+			logger.Debug("Not retrying %s: %s errors do not go away on another try", filepath.Base(inputFile), class)
+			break
+		}
+		delay := policy.Delay(retry)
+		if !runDeadline.IsZero() && !time.Now().Add(delay).Before(runDeadline) {
+			logger.Warning("Not retrying %s: --max-runtime would be reached", filepath.Base(inputFile))
+			break
+		}
+
+		alternatives := policy.Alternatives(retry)
+		if len(alternatives) > 0 {
+			logger.Warning("%s failed (%s error), retrying in %s with %s (retry %d of %d)", filepath.Base(inputFile), class, delay, strings.Join(alternatives, ", "), retry, policy.Retries)
+		} else {
+			logger.Warning("%s failed (%s error), retrying in %s (retry %d of %d)", filepath.Base(inputFile), class, delay, retry, policy.Retries)
+		}
+		time.Sleep(delay)
+
+		// What the failed attempt left of the output is replaced, outputs of
+		// earlier runs are not
+		if info, statErr := os.Stat(outputFile); statErr == nil && !info.ModTime().Before(start) {
+			os.Remove(outputFile)
+		}
+		restore := applyRetryAlternatives(alternatives)
+		err = runJob(inputFile, outputFile, videoCache)
+		restore()
+		attempts++
+	}
+	return attempts, err
+}
+
+// applyRetryAlternatives sets the options of the alternatives of a retry
+// and returns a function that restores them
+func applyRetryAlternatives(alternatives []string) func() {
+	savedHWAccel, savedHWEncoder, savedIgnoreErrors := hwaccel, hwEncoder, ignoreErrors
+	for _, alternative := range alternatives {
+		switch alternative {
+		case batch.RetrySoftware:
+			hwaccel, hwEncoder = "", ""
+		case batch.RetryIgnoreErrors:
+			ignoreErrors = true
+		}
+	}
+	return func() { hwaccel, hwEncoder, ignoreErrors = savedHWAccel, savedHWEncoder, savedIgnoreErrors }
+}
+
+// quarantineSource moves a source that failed every attempt into
+// --quarantine-dir, keeping its path under root, and returns its new path,
+// empty when it stays where it is
+func quarantineSource(inputFile, root string, attempts int, err error) string {
+	if quarantineDir == "" {
+		return ""
+	}
+	class := errorClass(err)
+	if !batch.Quarantinable(class) {
+		// A full disk or a missing encoder says nothing about the source
+		logger.Debug("Not quarantining %s: %s errors are not caused by the source", filepath.Base(inputFile), class)
+		return ""
+	}
+	target, moveErr := batch.Quarantine(quarantineDir, root, batch.Failure{
+		Input:    inputFile,
+		Class:    class,
+		Error:    err.Error(),
+		Attempts: attempts,
+		Time:     time.Now(),
+	})
+	if moveErr != nil {
+		logger.Warning("Failed to quarantine %s: %v", inputFile, moveErr)
+		return ""
+	}
+	logger.Warning("Moved %s to %s", filepath.Base(inputFile), target)
+	return target
+}
@@ -2,17 +2,24 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/batch"
 	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/config"
 	"github.com/cccarv82/compressvideo/pkg/compressor"
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/hooks"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 	"github.com/cccarv82/compressvideo/pkg/reporter"
+	"github.com/cccarv82/compressvideo/pkg/storage"
 	"github.com/cccarv82/compressvideo/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -25,18 +32,62 @@ var (
 	// Compression options
 	quality int     // 1-5 (1 = max compression, 5 = max quality)
 	preset  string  // fast, balanced, thorough
+	codec   string  // Video codec (auto, h264, hevc, vp9)
 	force   bool    // Overwrite output if exists
 	verbose bool    // Verbose logging
+	targetVMAF float64 // Target VMAF score (0 = use analyzer CRF)
+	hwaccel    string  // Hardware decoder (cuda, qsv, vaapi), empty for CPU decoding
+	hwEncoder  string  // Hardware encoder family (auto, nvenc, qsv, amf), empty for software encoding
+	threads     int  // Maximum encoder threads (0 = FFmpeg default)
+	lowPriority bool // Run FFmpeg at reduced CPU and I/O priority
+	screencastROI bool // Detect webcam overlays and tune static screen areas in screencasts
+	ignoreErrors bool // Decode past damaged data of partial recordings and downloads
+	keepHDR10Plus bool // Copy the HDR10+ metadata of HDR10+ sources
+	copyVideo  bool    // Copy the video stream and re-encode audio only
+	copyAudio  bool    // Copy the audio streams unchanged
+	noAudio    bool    // Drop the audio streams
+	audioChannels string // Audio channel layout (stereo, mono, keep)
+	keepAudioLangs string // Languages of the audio tracks to keep, in order of preference
+	confirm    bool    // Ask before starting long encodes
+	writeChecksums bool // Write SHA-256 and provenance sidecars next to the output
+	preserveTimes  bool // Copy the source timestamps and permissions to the output
+	recursive  bool    // Process subdirectories of an input directory
+	flatten    bool    // Write all outputs of a recursive run into one directory
+	outputRoot string  // Output directory for directory runs
+	nameTemplate string // Template for output file names ({name}, {ext}, {codec}, {quality}, {preset})
+	skipCodecs       string // Comma-separated video codecs to leave alone in directory runs
+	skipBelowBitrate string // Leave files below this video bitrate alone in directory runs
+	minSize   string // Only process files at least this large in directory runs
+	maxSize   string // Only process files at most this large in directory runs
+	olderThan string // Only process files modified before this age or date in directory runs
+	newerThan string // Only process files modified after this age or date in directory runs
+	reportFormat   string // Report file format (txt, json, md, html)
+	reportPath     string // Report file or directory (default: next to the output)
+	renditions       string // Heights of a quality ladder encoded from each input (e.g. 1080p,720p,480p)
+	renditionsFormat string // Layout of the ladder (files or hls)
+	writePoster      bool    // Write a poster frame next to the output
+	writeSprite      bool    // Write a preview sprite sheet and WebVTT index next to the output
+	spriteInterval   float64 // Seconds between sprite thumbnails
 	
 	// Cache options
 	useCache        bool   // Whether to use analysis cache
 	cacheClearExpired bool // Whether to clear expired cache entries
 	cacheMaxAge     int    // Maximum age of cache entries in days
+	cacheFingerprint string // How cached files are identified (path or content)
+	cacheMaxSizeMB   int    // Maximum size of the cached data in MB (0 = unlimited)
+	cacheMaxEntries  int    // Maximum number of cached analyses (0 = unlimited)
+	fastAnalysis     bool   // Reuse the analysis of a similar cached video instead of analyzing
+
+	// Position of the current file in a directory batch ("2/5"), empty for single files
+	batchPosition string
 
 	// Logger
 	logger *util.Logger
 )
 
+// longJobThreshold is the estimated encode time above which --confirm asks before starting
+const longJobThreshold = 10 * time.Minute
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "compressvideo",
@@ -55,38 +106,95 @@ Examples:
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
+// The exit code tells the kind of failure apart (see exitcode.go).
 func Execute() {
+	defer recoverPanic()
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		if !commandStarted {
+			// Unknown flags, missing required flags and bad arguments
+			os.Exit(exitBadInput)
+		}
+		os.Exit(exitCode(err))
 	}
 }
 
 func init() {
 	// Define required flags
-	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input video file (required)")
+	rootCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input video file, directory or http(s) URL (required)")
 	rootCmd.MarkFlagRequired("input")
 
 	// Define optional flags
-	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: input-compressed.ext)")
+	rootCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file (default: named by --name-template)")
+	rootCmd.Flags().StringVar(&nameTemplate, "name-template", batch.DefaultNameTemplate, "Output file name template with {name}, {ext}, {codec}, {quality} and {preset}, e.g. {name}-{codec}-q{quality}{ext}")
+	rootCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Process subdirectories recursively when input is a directory, mirroring them under the output directory")
+	rootCmd.Flags().BoolVar(&flatten, "flatten", false, "With -r, write every output directly into the output directory instead of mirroring subdirectories")
+	rootCmd.Flags().StringVar(&outputRoot, "output-root", "", "Output directory for directory input (default: -o, or <input>_compressed)")
+	rootCmd.Flags().StringVar(&skipCodecs, "skip-codecs", "", "In directory runs, skip files whose video is already in one of these codecs (e.g. hevc,av1), as reported by ffprobe")
+	rootCmd.Flags().StringVar(&skipBelowBitrate, "skip-below-bitrate", "", "In directory runs, skip files whose video bitrate is already below this value (e.g. 2M)")
+	rootCmd.Flags().StringVar(&minSize, "min-size", "", "In directory runs, only process files at least this large (e.g. 500M, 2G)")
+	rootCmd.Flags().StringVar(&maxSize, "max-size", "", "In directory runs, only process files at most this large (e.g. 10G)")
+	rootCmd.Flags().StringVar(&olderThan, "older-than", "", "In directory runs, only process files last modified before this age (e.g. 30d, 2w, 12h) or date (YYYY-MM-DD)")
+	rootCmd.Flags().StringVar(&newerThan, "newer-than", "", "In directory runs, only process files last modified after this age (e.g. 30d, 2w, 12h) or date (YYYY-MM-DD)")
 	rootCmd.Flags().IntVarP(&quality, "quality", "q", 3, "Quality level (1-5, 1=max compression, 5=max quality)")
 	rootCmd.Flags().StringVarP(&preset, "preset", "p", "balanced", "Compression preset (fast, balanced, thorough)")
+	rootCmd.Flags().StringVar(&codec, "codec", analyzer.CodecAuto, "Video codec: auto (picked for the content), h264 for the widest compatibility, hevc or vp9 for smaller files")
 	rootCmd.Flags().BoolVarP(&force, "force", "f", false, "Force overwrite output file if it exists")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+	rootCmd.Flags().Float64Var(&targetVMAF, "target-vmaf", 0, "Pick the highest CRF that reaches this VMAF score (e.g. 93) by probing short clips")
+	rootCmd.Flags().StringVar(&hwaccel, "hwaccel", "", "Decode on the GPU for analysis and encoding (cuda, qsv, vaapi); frames stay on the GPU with a matching hardware encoder")
+	rootCmd.Flags().StringVar(&hwEncoder, "hw-encoder", "", "Encode on the GPU: auto, nvenc (NVIDIA), qsv (Intel) or amf (AMD), mapped from the same quality scale")
+	rootCmd.Flags().IntVar(&threads, "threads", 0, "Maximum encoder threads, shared by parallel segments (0 = FFmpeg default)")
+	rootCmd.Flags().BoolVar(&lowPriority, "low-priority", false, "Run FFmpeg at reduced CPU and disk priority (nice/ionice, below-normal on Windows) so the machine stays usable")
+	rootCmd.Flags().BoolVar(&screencastROI, "screencast-roi", false, "For screencasts, detect a webcam overlay and use long GOPs, still-image tuning and a lower frame rate for the static screen")
+	rootCmd.Flags().BoolVar(&ignoreErrors, "ignore-errors", false, "Salvage partially damaged inputs (cut-off recordings, interrupted downloads) by skipping corrupt data; the report shows how much was recovered")
+	rootCmd.Flags().BoolVar(&keepHDR10Plus, "keep-hdr10plus", false, "Copy the HDR10+ dynamic metadata of HEVC sources into the x265 output (needs hdr10plus_tool on the PATH); otherwise it is lost, with a warning")
+	rootCmd.Flags().DurationVar(&timeoutPerFile, "timeout-per-file", 0, "Stop FFmpeg when one file takes longer than this (e.g. 2h) and move on to the next file (0 = no limit)")
+	rootCmd.Flags().DurationVar(&maxRuntime, "max-runtime", 0, "Stop the whole run after this long (e.g. 8h); files not started are left for the next run (0 = no limit)")
+	rootCmd.Flags().Float64Var(&wattsPerCore, "watts-per-core", compressor.DefaultWattsPerCore, "Power one busy CPU core draws, for the energy estimate next to the CPU time of each file (e.g. 5 for a laptop, 15 for a desktop)")
+	rootCmd.Flags().BoolVar(&copyVideo, "copy-video", false, "Copy the video stream unchanged and re-encode only the audio (e.g. large PCM tracks to AAC)")
+	rootCmd.Flags().BoolVar(&copyAudio, "copy-audio", false, "Copy the audio streams unchanged")
+	rootCmd.Flags().BoolVar(&noAudio, "no-audio", false, "Remove the audio streams from the output")
+	rootCmd.Flags().StringVar(&renditions, "renditions", "", "Encode a quality ladder of these heights (e.g. 1080p,720p,480p) from one analysis, decoding the input once")
+	rootCmd.Flags().StringVar(&renditionsFormat, "renditions-format", renditionsFiles, "Layout of --renditions: files (<output>-720p.mp4) or hls (playlists and a master.m3u8 in <output>/)")
+	rootCmd.Flags().BoolVar(&writePoster, "poster", false, "Write a poster frame of the compressed video as <output>.jpg")
+	rootCmd.Flags().BoolVar(&writeSprite, "sprite", false, "Write a preview sprite sheet (<output>-sprite.jpg) and its WebVTT index (<output>-sprite.vtt) for web player seek previews")
+	rootCmd.Flags().Float64Var(&spriteInterval, "sprite-interval", ffmpeg.DefaultSpriteOptions().Interval, "Seconds between sprite thumbnails, longer for very long videos")
+	rootCmd.Flags().StringVar(&audioChannels, "audio-channels", compressor.AudioChannelsKeep, "Audio channel layout: stereo or mono downmix surround tracks, keep leaves them unchanged")
+	rootCmd.Flags().StringVar(&keepAudioLangs, "keep-audio-langs", "", "Keep only the audio tracks in these languages, e.g. eng,por; the first language listed becomes the default track")
+	rootCmd.Flags().StringVar(&reportFormat, "report-format", "txt", "Report file format (txt, json, md, html with before/after frames)")
+	rootCmd.Flags().StringVar(&reportPath, "report-path", "", "Report file path, or a directory for batch runs (default: next to the output)")
+	rootCmd.Flags().BoolVar(&writeChecksums, "write-checksums", false, "Write <output>.sha256 and a <output>.provenance.json manifest with hashes, settings and tool version")
+	rootCmd.Flags().BoolVar(&preserveTimes, "preserve-times", false, "Copy the source file's access/modification times (and permissions on Unix) to the output")
+	rootCmd.Flags().StringVar(&notifyURL, "notify-url", "", "Post the result as JSON to this webhook when a file or batch finishes (Slack, Discord or generic HTTP)")
+	rootCmd.Flags().BoolVar(&notifyDesktop, "notify-desktop", false, "Show a desktop notification when a file or batch finishes")
+	rootCmd.Flags().StringVar(&metricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9090) at /metrics while running")
+	rootCmd.Flags().BoolVar(&confirm, "confirm", false, "Ask for confirmation before encodes estimated to take longer than 10 minutes")
 	rootCmd.Flags().BoolVarP(&useCache, "use-cache", "c", false, "Whether to use analysis cache")
 	rootCmd.Flags().BoolVarP(&cacheClearExpired, "clear-cache", "C", false, "Whether to clear expired cache entries")
 	rootCmd.Flags().IntVarP(&cacheMaxAge, "cache-max-age", "A", 7, "Maximum age of cache entries in days")
+	rootCmd.Flags().StringVar(&cacheFingerprint, "cache-fingerprint", "path", "Identify cached files by path (path, size and mtime) or content (size and first/last 4 MB), which survives renames and copies")
+	rootCmd.Flags().IntVar(&cacheMaxSizeMB, "cache-max-size", 0, "Maximum size of the cached data in MB, least recently used entries are evicted (0 = unlimited)")
+	rootCmd.Flags().IntVar(&cacheMaxEntries, "cache-max-entries", 0, "Maximum number of cached analyses, least recently used entries are evicted (0 = unlimited)")
+	rootCmd.Flags().BoolVar(&fastAnalysis, "fast-analysis", false, "On a cache miss, reuse the analysis of a cached video with the same resolution, codec and similar duration instead of analyzing (requires --use-cache)")
 }
 
 // validateFlags validates the input flags
 func validateFlags() error {
-	// Validate input file exists
-	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-		return fmt.Errorf("input file does not exist: %s", inputFile)
+	// Validate input file exists; URLs are checked by FFmpeg when probing
+	if ffmpeg.IsRemote(inputFile) || storage.IsS3(inputFile) {
+		// Nothing to check locally
+	} else if _, err := os.Stat(inputFile); os.IsNotExist(err) {
+		return i18n.Errorf("input file does not exist: %s", inputFile)
 	}
 
 	// Validate quality level
 	if quality < 1 || quality > 5 {
-		return fmt.Errorf("quality must be between 1-5 (got %d)", quality)
+		return i18n.Errorf("quality must be between 1-5 (got %d)", quality)
+	}
+
+	// Validate output naming
+	if err := batch.ValidateNameTemplate(nameTemplate); err != nil {
+		return err
 	}
 
 	// Validate preset
@@ -96,20 +204,194 @@ func validateFlags() error {
 		"thorough":  true,
 	}
 	if !validPresets[preset] {
-		return fmt.Errorf("preset must be one of: fast, balanced, thorough (got %s)", preset)
+		return i18n.Errorf("preset must be one of: fast, balanced, thorough (got %s)", preset)
+	}
+
+	// Validate codec
+	if !analyzer.ValidCodec(codec) {
+		return i18n.Errorf("codec must be one of: auto, h264, hevc, vp9 (got %s)", codec)
+	}
+
+	// Validate report format
+	if !reporter.ValidReportFormat(reportFormat) {
+		return i18n.Errorf("report format must be one of: txt, json, md, html (got %s)", reportFormat)
+	}
+
+	// Validate target VMAF
+	if targetVMAF < 0 || targetVMAF > 100 {
+		return i18n.Errorf("target VMAF must be between 0-100 (got %.1f)", targetVMAF)
+	}
+
+	if fastAnalysis && !useCache {
+		return i18n.Errorf("--fast-analysis requires --use-cache")
+	}
+
+	// Validate hardware decoder
+	if !ffmpeg.ValidHWAccel(hwaccel) {
+		return i18n.Errorf("hwaccel must be one of: cuda, qsv, vaapi (got %s)", hwaccel)
+	}
+
+	// Validate stream passthrough modes
+	if err := streamModes().Validate(); err != nil {
+		return err
+	}
+
+	// Validate audio channel layout
+	if !compressor.ValidAudioChannels(audioChannels) {
+		return i18n.Errorf("audio channels must be one of: stereo, mono, keep (got %s)", audioChannels)
+	}
+	if copyAudio && audioChannels != compressor.AudioChannelsKeep {
+		return i18n.Errorf("--audio-channels requires re-encoding the audio and cannot be used with --copy-audio")
+	}
+	if _, err := compressor.ParseLanguages(keepAudioLangs); err != nil {
+		return err
+	}
+	if keepAudioLangs != "" && noAudio {
+		return i18n.Errorf("--keep-audio-langs cannot be used with --no-audio")
+	}
+
+	// Validate the encoder settings that replace the analyzer's
+	if err := validateOverrides(); err != nil {
+		return err
+	}
+
+	// Validate the video filters
+	if err := validateVideoFilters(); err != nil {
+		return err
+	}
+
+	// Validate the keyframe interval
+	if err := validateKeyframes(); err != nil {
+		return err
+	}
+
+	// Validate the parallel mode
+	if err := validateParallel(); err != nil {
+		return err
+	}
+
+	// Validate the hooks
+	if err := validateHooks(); err != nil {
+		return err
+	}
+
+	if err := validateSidecars(); err != nil {
+		return err
+	}
+
+	// Validate the quality ladder
+	if err := validateRenditions(); err != nil {
+		return err
+	}
+
+	// Validate poster and sprite options
+	if err := validatePreviews(); err != nil {
+		return err
+	}
+
+	// Validate the compatibility target
+	if err := validateCompat(); err != nil {
+		return err
+	}
+
+	// Validate the comparison clip options
+	if err := validateComparison(); err != nil {
+		return err
+	}
+
+	// Validate the remux container
+	if err := validateRemux(); err != nil {
+		return err
+	}
+
+	// Validate timeouts
+	if err := validateTimeouts(); err != nil {
+		return err
+	}
+	if err := validateResume(); err != nil {
+		return err
+	}
+	if err := validateRetry(); err != nil {
+		return err
+	}
+	if err := validateSchedule(); err != nil {
+		return err
+	}
+	if err := validateThrottle(); err != nil {
+		return err
+	}
+	if err := validateMetrics(); err != nil {
+		return err
+	}
+	if err := validateWattsPerCore(); err != nil {
+		return err
+	}
+
+	// Validate thread limit
+	if threads < 0 {
+		return i18n.Errorf("threads must be 0 or more (got %d)", threads)
+	}
+
+	// Validate hardware encoder
+	validHWEncoders := map[string]bool{
+		"":                    true,
+		"auto":                true,
+		ffmpeg.HWEncoderNVENC: true,
+		ffmpeg.HWEncoderQSV:   true,
+		ffmpeg.HWEncoderAMF:   true,
+	}
+	if !validHWEncoders[hwEncoder] {
+		return i18n.Errorf("hw-encoder must be one of: auto, nvenc, qsv, amf (got %s)", hwEncoder)
+	}
+
+	// Validate cache fingerprint mode
+	if _, err := cache.ParseFingerprintMode(cacheFingerprint); err != nil {
+		return i18n.Errorf("cache fingerprint must be path or content (got %s)", cacheFingerprint)
+	}
+
+	// Validate batch filters
+	if _, err := batch.ParseMinBitrate(skipBelowBitrate); err != nil {
+		return i18n.Errorf("skip-below-bitrate must be a bitrate such as 2M or 800k (got %s)", skipBelowBitrate)
+	}
+
+	if _, err := batchFileFilter(); err != nil {
+		return err
+	}
+
+	// Validate recursive options
+	if flatten && !recursive {
+		return i18n.Errorf("--flatten requires --recursive")
+	}
+
+	// Directory runs check each output on its own and default to <input>_compressed
+	if info, err := os.Stat(inputFile); err == nil && info.IsDir() {
+		// An s3:// output is uploaded as a single object
+		if storage.IsS3(outputFile) {
+			return i18n.Errorf("s3:// outputs take a single file, not a folder of outputs: %s", outputFile)
+		}
+		return nil
+	}
+
+	// s3:// inputs and outputs are checked and named by stageS3
+	if storage.IsS3(outputFile) || storage.IsS3(inputFile) && outputFile == "" {
+		return nil
+	}
+
+	// Outputs of URLs are named after the remote file, in the current directory
+	if ffmpeg.IsRemote(inputFile) && outputFile == "" {
+		outputFile = defaultOutputName(ffmpeg.RemoteBaseName(inputFile))
 	}
 
 	// Validate output file
 	if outputFile != "" {
-		// Check if output file already exists and not force flag
-		if _, err := os.Stat(outputFile); err == nil && !force {
-			return fmt.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
+		// Check if output file already exists and not force flag; renditions
+		// are checked once their names are known
+		if _, err := os.Stat(outputFile); err == nil && !force && renditions == "" {
+			return i18n.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
 		}
 	} else {
 		// Generate output filename if not provided
-		ext := filepath.Ext(inputFile)
-		base := strings.TrimSuffix(inputFile, ext)
-		outputFile = base + "-compressed" + ext
+		outputFile = filepath.Join(filepath.Dir(inputFile), defaultOutputName(filepath.Base(inputFile)))
 	}
 
 	return nil
@@ -118,28 +400,84 @@ func validateFlags() error {
 // process runs the main compression process
 func process(cmd *cobra.Command, args []string) error {
 	// Configure logger
-	logger = util.NewLogger(verbose)
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
 	logger.Title("CompressVideo - Smart Video Compression")
 
+	// Fill in the options of the chosen profile
+	if err := applyProfile(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+	setupDirFlags(cmd)
+	if err := loadAnalysisParams(cmd); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
 	// Validate required flags
 	err := validateFlags()
 	if err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
+	if err := requireFFmpeg(); err != nil {
 		return err
 	}
-	
-	// Resolve output file if not specified
-	if outputFile == "" {
-		dir := filepath.Dir(inputFile)
-		ext := filepath.Ext(inputFile)
-		base := filepath.Base(inputFile)
-		base = strings.TrimSuffix(base, ext)
-		outputFile = filepath.Join(dir, base+"-compressed"+ext)
+
+	// Work on local copies of s3:// inputs and outputs, once the options
+	// are known to be good
+	cleanupS3, err := stageS3()
+	defer cleanupS3()
+	if err != nil {
+		return err
+	}
+	startRunDeadline()
+	stopPauseControls := startPauseControls()
+	defer stopPauseControls()
+	stopSchedule := startSchedule()
+	defer stopSchedule()
+	stopThrottle := startThrottle()
+	defer stopThrottle()
+	stopBatteryWatch := startBatteryWatch()
+	defer stopBatteryWatch()
+
+	// Keep the machine responsive while encoding in the background
+	if lowPriority {
+		ffmpeg.SetLowPriority(true)
+		if err := util.LowerProcessPriority(); err != nil {
+			logger.Warning("Failed to lower process priority: %v", err)
+		} else {
+			logger.Debug("Running at reduced CPU and I/O priority")
+		}
 	}
 
+	// Notifications and hooks can also be configured in ~/.compressvideo/config.yaml
+	setupNotifier(cmd)
+	setupHooks()
+
+	// Expose /metrics while the run is in progress
+	stopMetrics := startMetrics()
+	defer stopMetrics()
+	
 	// Check if input file is a directory
-	fileInfo, err := os.Stat(inputFile)
-	if err != nil {
-		return fmt.Errorf("error accessing input file: %w", err)
+	isDir := false
+	if !ffmpeg.IsRemote(inputFile) {
+		fileInfo, err := os.Stat(inputFile)
+		if err != nil {
+			return withExitCode(exitBadInput, i18n.Errorf("error accessing input file: %w", err))
+		}
+		isDir = fileInfo.IsDir()
+	}
+
+	// Outputs not named with -o go where the routing rules send them
+	if err := setupRoutes(cmd, isDir); err != nil {
+		return withExitCode(exitBadInput, err)
+	}
+
+	// Resolve output file if not specified; directories get their own default below
+	if outputFile == "" && !isDir {
+		outputFile = filepath.Join(filepath.Dir(inputFile), defaultOutputName(filepath.Base(inputFile)))
 	}
 
 	// Initialize cache if enabled
@@ -153,6 +491,9 @@ func process(cmd *cobra.Command, args []string) error {
 		} else {
 			// Set cache options
 			videoCache.SetMaxAge(cacheMaxAge * 24) // Convert days to hours
+			mode, _ := cache.ParseFingerprintMode(cacheFingerprint)
+			videoCache.SetFingerprintMode(mode)
+			videoCache.SetSizeLimit(cacheMaxEntries, int64(cacheMaxSizeMB)*1024*1024)
 			
 			// Clean expired cache entries if requested
 			if cacheClearExpired {
@@ -181,19 +522,36 @@ func process(cmd *cobra.Command, args []string) error {
 		}()
 	}
 
+	// Without -c the cache database still holds the compression ledger
+	if videoCache == nil {
+		if videoCache = openLedger(); videoCache != nil {
+			defer videoCache.Close()
+		}
+	}
+
 	// Process directory or single file
-	if fileInfo.IsDir() {
+	if isDir {
 		// Directory provided
 		logger.Section("Processing Directory")
 		logger.Field("Input Directory", inputFile)
 		
-		// If no output directory provided, create one with _compressed suffix
-		if outputFile == "" {
+		// --output-root takes precedence, otherwise -o names the directory
+		if outputRoot != "" {
+			outputFile = outputRoot
+		} else if outputFile == "" {
+			// If no output directory provided, create one with _compressed suffix
 			outputFile = inputFile + "_compressed"
 		}
 		
 		logger.Field("Output Directory", outputFile)
 		
+		// Each file gets its own report, so the report path must be a directory
+		if reportPath != "" {
+			if err := os.MkdirAll(reportPath, 0755); err != nil {
+				return i18n.Errorf("failed to create report directory: %w", err)
+			}
+		}
+		
 		// Process the directory
 		return processDirectory(inputFile, outputFile, videoCache)
 	}
@@ -206,7 +564,10 @@ func process(cmd *cobra.Command, args []string) error {
 	logger.Field("Preset", preset)
 	
 	// Process the file
-	return processSingleFile(inputFile, outputFile, videoCache)
+	if err := runJob(inputFile, outputFile, videoCache); err != nil {
+		return err
+	}
+	return uploadS3Output()
 }
 
 // Função auxiliar para verificar se um slice contém um determinado valor
@@ -239,6 +600,10 @@ func displayVideoInfo(videoFile *ffmpeg.VideoFile) {
 	logger.Info("\nVideo Stream:")
 	logger.Field("  Codec", "%s", videoFile.VideoInfo.Codec)
 	logger.Field("  Resolution", "%dx%d", videoFile.VideoInfo.Width, videoFile.VideoInfo.Height)
+	if videoFile.VideoInfo.IsAnamorphic() {
+		logger.Field("  Aspect Ratio", "SAR %s, DAR %s, shown at %dx%d", videoFile.VideoInfo.SAR, videoFile.VideoInfo.DAR,
+			videoFile.VideoInfo.DisplayWidth(), videoFile.VideoInfo.Height)
+	}
 	logger.Field("  Frame Rate", "%.2f fps", videoFile.VideoInfo.FPS)
 	
 	if videoFile.VideoInfo.BitRate > 0 {
@@ -289,6 +654,12 @@ func displayAnalysisResults(analysis *analyzer.VideoAnalysis) {
 	logger.Field("Spatial Complexity", "%.2f", analysis.SpatialComplexity)
 	logger.Field("Recommended Codec", "%s", analysis.RecommendedCodec)
 	
+	if stats := analysis.Bitrate; stats != nil {
+		logger.Field("Source Bitrate (median)", "%s", formatBitrate(stats.P50))
+		logger.Field("Source Bitrate (95th pct)", "%s", formatBitrate(stats.P95))
+		logger.Field("Source Bitrate (peak)", "%s", formatBitrate(stats.Max))
+	}
+	
 	bitrateStr := formatBitrate(analysis.OptimalBitrate)
 	logger.Field("Optimal Bitrate", "%s", bitrateStr)
 	
@@ -374,6 +745,30 @@ func formatBitrate(bitrate int64) string {
 	}
 }
 
+// formatEstimate returns a rounded, human-readable duration for estimates
+func formatEstimate(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return i18n.Tr("less than a minute")
+	case d < time.Hour:
+		return fmt.Sprintf("~%d min", int(d.Round(time.Minute).Minutes()))
+	default:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("~%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// askConfirmation prints a yes/no question and reads the answer from stdin
+func askConfirmation(question string) bool {
+	fmt.Printf("%s %s: ", question, i18n.Tr("[y/N]"))
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes" || answer == "s" || answer == "sim"
+}
+
 // getFileExtension returns the file extension including the dot
 func getFileExtension(filename string) string {
 	for i := len(filename) - 1; i >= 0; i-- {
@@ -386,65 +781,191 @@ func getFileExtension(filename string) string {
 
 // processDirectory processes a directory of video files
 func processDirectory(inputDir, outputDir string, videoCache *cache.VideoAnalysisCache) error {
+	// Deep libraries and network shares need extended-length paths on Windows
+	inputDir, outputDir = util.LongPath(inputDir), util.LongPath(outputDir)
+
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return i18n.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Size and age limits were checked by validateFlags
+	fileFilter, _ := batchFileFilter()
+
+	// The ledger tells outputs of earlier runs apart; without it only the
+	// file names can
+	match := isVideoFile
+	if videoCache == nil {
+		match = isSourceVideo
+	}
+
+	// Directory files may name the profiles of the config file
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return err
 	}
 
-	// List files in the input directory
-	files, err := os.ReadDir(inputDir)
+	// Plan the outputs, mirroring the input tree unless --flatten is set
+	jobs, err := batch.Plan(inputDir, outputDir, batch.Options{
+		Recursive:  recursive,
+		Flatten:    flatten,
+		Match:      match,
+		Files:      fileFilter,
+		Template:   nameTemplate,
+		Name:       batch.NameFields{Quality: quality, Preset: preset, Ext: remuxExt()},
+		DirConfigs: true,
+		Profiles:   cfg.Profiles,
+		Flags:      dirFlags,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to read input directory: %w", err)
+		return i18n.Errorf("failed to read input directory: %w", err)
+	}
+	if err := batch.CreateOutputDirs(jobs); err != nil {
+		return i18n.Errorf("failed to create output directory: %w", err)
 	}
 
 	// Count of video files found
-	videoCount := 0
+	videoCount := len(jobs)
+	failedCount := 0
+	var firstErr error
+	skippedCount := 0
+	batchStart := time.Now()
+	startCPUTime := runCPUTime
+	batchResults = nil
+	routeRoot = outputDir
+	planSeries(jobs)
+	runProgress = startProgress(inputDir, outputDir, jobs)
+	defer func() { batchPosition, routeRoot, seriesOf, runProgress = "", "", nil, nil }()
 
 	// Process each file
-	for _, file := range files {
-		if file.IsDir() {
-			continue // Skip subdirectories for now
+	notStarted := 0
+	for i, job := range jobs {
+		if runDeadlineReached() {
+			notStarted = videoCount - i
+			logger.Warning("--max-runtime of %s reached, %d files were not processed", maxRuntime, notStarted)
+			break
 		}
+		batchPosition = fmt.Sprintf("%d/%d", i+1, videoCount)
+		setQueueDepth(videoCount - i - 1)
 
-		// Check if it's a video file
-		fileName := file.Name()
-		inputPath := filepath.Join(inputDir, fileName)
-		if !isVideoFile(fileName) {
-			continue
+		// Name files by their path in the tree so recursive runs are unambiguous
+		fileName, err := filepath.Rel(inputDir, job.Input)
+		if err != nil {
+			fileName = filepath.Base(job.Input)
 		}
 
-		videoCount++
+		// Files an earlier run of the same command completed are done, wherever their outputs went
+		if runProgress.Completed(job.Input) {
+			logger.Info("Skipping %s: completed by an earlier run", fileName)
+			continue
+		}
+		if runProgress.Failed(job.Input) {
+			logger.Info("Retrying %s, which failed in the last run", fileName)
+		}
 
-		// Define output path
-		outputFileName := strings.TrimSuffix(fileName, filepath.Ext(fileName)) + "-compressed" + filepath.Ext(fileName)
-		outputPath := filepath.Join(outputDir, outputFileName)
+		// Files the ledger knows were compressed, or came out of a compression, are left alone
+		if reason := ledgerSkipReason(videoCache, job.Input); reason != "" {
+			logger.Info("Skipping %s: %s", fileName, reason)
+			continue
+		}
 
 		// Check if output file exists and handle overwrite
-		if _, err := os.Stat(outputPath); err == nil && !force {
+		if _, exists := batch.ExistingOutput(job.Output); exists && !force {
 			logger.Warning("Skipping %s: output file already exists (use -f to force overwrite)", fileName)
 			continue
 		}
 
+		// Decide on the probed streams, not the file name. Files already in
+		// efficient codecs or at low bitrates are left alone.
+		if probeFilter := jobProbeFilter(job); probeFilter.Active() {
+			prober := ffmpeg.NewFFmpeg(job.Input, job.Output, &ffmpeg.Options{}, logger)
+			videoFile, err := prober.GetVideoInfo(job.Input)
+			if err != nil {
+				logger.Warning("Could not probe %s, compressing it anyway: %v", fileName, err)
+			} else if reason := probeFilter.SkipReason(videoFile); reason != "" {
+				logger.Info("Skipping %s: %s", fileName, reason)
+				skippedCount++
+				continue
+			}
+		}
+
 		// Process the video file
 		logger.Info("Processing video %s...", fileName)
-		err = processSingleFile(inputPath, outputPath, videoCache)
+		restore := applyDirConfig(job)
+		attempts, err := runJobWithRetries(job.Input, job.Output, videoCache)
+		restore()
 		if err != nil {
 			logger.Error("Failed to process %s: %v", fileName, err)
+			recordFailure(job.Input, attempts, err)
+			quarantineSource(job.Input, inputDir, attempts, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failedCount++
 			continue
 		}
+		recordProgress(job.Input, batch.ProgressCompleted)
+	}
+
+	if videoCount > 0 {
+		notifyBatch(inputDir, failedCount, batchStart)
+		runAfterBatchHooks(inputDir, outputDir, failedCount, batchStart)
 	}
 
 	if videoCount == 0 {
 		logger.Warning("No video files found in directory")
-	} else {
-		logger.Success("Processed %d video files", videoCount)
+		return nil
+	}
+	if skippedCount > 0 {
+		logger.Info("Skipped %d files by codec or bitrate", skippedCount)
 	}
+	logRunCPUTime(startCPUTime)
 
+	if failedCount > 0 || notStarted > 0 {
+		logger.Info("Run the same command with --resume to continue with the files left")
+	}
+
+	// The run fails with the exit code of its first failed file
+	if failedCount > 0 {
+		return withExitCode(exitCode(firstErr), i18n.Errorf("%d of %d files failed", failedCount, videoCount))
+	}
+	if notStarted > 0 {
+		return withExitCode(exitTimedOut, i18n.Errorf("%d of %d files were not processed before --max-runtime", notStarted, videoCount))
+	}
+	logger.Success("Processed %d video files", videoCount)
 	return nil
 }
 
+// batchFileFilter builds the size and age limits of a directory run from the flags
+func batchFileFilter() (batch.FileFilter, error) {
+	var filter batch.FileFilter
+	var err error
+
+	if filter.MinSize, err = batch.ParseSize(minSize); err != nil {
+		return filter, i18n.Errorf("min-size must be a size such as 500M or 2G (got %s)", minSize)
+	}
+	if filter.MaxSize, err = batch.ParseSize(maxSize); err != nil {
+		return filter, i18n.Errorf("max-size must be a size such as 500M or 2G (got %s)", maxSize)
+	}
+	if filter.MaxSize > 0 && filter.MinSize > filter.MaxSize {
+		return filter, i18n.Errorf("min-size cannot be larger than max-size")
+	}
+
+	now := time.Now()
+	if filter.OlderThan, err = batch.ParseAge(olderThan, now); err != nil {
+		return filter, i18n.Errorf("older-than must be an age such as 30d, 2w or 12h, or a YYYY-MM-DD date (got %s)", olderThan)
+	}
+	if filter.NewerThan, err = batch.ParseAge(newerThan, now); err != nil {
+		return filter, i18n.Errorf("newer-than must be an age such as 30d, 2w or 12h, or a YYYY-MM-DD date (got %s)", newerThan)
+	}
+	return filter, nil
+}
+
 // processSingleFile processes a single video file
 func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnalysisCache) error {
+	// Deep folders and network shares need extended-length paths on Windows
+	inputFile, outputFile = util.LongPath(inputFile), util.LongPath(outputFile)
+
 	if verbose {
 		logger.Debug("File Info:")
 		logger.Debug("  Input Path: %s", inputFile)
@@ -454,22 +975,74 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 		logger.Debug("  Force Overwrite: %v", force)
 	}
 
+	// The cache is keyed on local files
+	if ffmpeg.IsRemote(inputFile) && videoCache != nil {
+		logger.Debug("Not using the cache for remote input %s", inputFile)
+		videoCache = nil
+	}
+
+	// Check whether this exact file was already compressed with these settings
+	paramsHash := compressionParamsHash()
+	if videoCache != nil && useCache {
+		outcome, found, err := videoCache.GetOutcome(inputFile, paramsHash)
+		if err != nil {
+			logger.Warning("Error reading from cache: %v", err)
+		}
+		if found && outcome.OutputIntact() {
+			if outcome.OutputPath == outputFile && !force {
+				logger.Success("%s was already compressed to %s (%s, %.1f%% smaller) with these settings, skipping",
+					filepath.Base(inputFile), outcome.OutputPath, formatSize(outcome.CompressedSize), outcome.SavedPercent)
+				return nil
+			}
+			logger.Warning("%s was already compressed to %s (%s, %.1f%% smaller) with these settings",
+				filepath.Base(inputFile), outcome.OutputPath, formatSize(outcome.CompressedSize), outcome.SavedPercent)
+		}
+	}
+
+	// Compressing an output again rarely helps, but the file was asked for by name
+	if videoCache != nil {
+		if entry, found, err := videoCache.CompressedFrom(inputFile); err != nil {
+			logger.Debug("Could not check the compression ledger: %v", err)
+		} else if found {
+			logger.Warning("%s is the output of an earlier run (compressed from %s)", filepath.Base(inputFile), entry.SourcePath)
+		}
+	}
+
 	// Check if output file exists and handle overwrite
-	if _, err := os.Stat(outputFile); err == nil && !force {
-		return fmt.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
+	if _, err := os.Stat(outputFile); err == nil && !force && renditions == "" {
+		return i18n.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
 	}
 
 	// Configure FFmpeg options
 	options := &ffmpeg.Options{
 		Quality: quality,
 		Preset:  preset,
+		HWAccel: hwaccel,
 	}
 
 	// Create FFmpeg instance
 	ffmpegInstance := ffmpeg.NewFFmpeg(inputFile, outputFile, options, logger)
+	ffmpegInstance.Runner = ffmpeg.WithContext(ffmpegInstance.Runner, jobContext)
+	cpuMeter, stopMeter := meterJob(ffmpegInstance)
+	defer stopMeter()
+	startDiagnosticJob(inputFile, ffmpegInstance)
+
+	// Stop here when FFmpeg lacks a feature the options need
+	if err := checkFFmpegFeatures(ffmpegInstance); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Changing the container needs neither analysis nor encoding
+	if remuxOnly != "" {
+		return remuxFile(inputFile, outputFile, ffmpegInstance, videoCache, paramsHash)
+	}
 
 	// Create analyzer
 	contentAnalyzer := analyzer.NewContentAnalyzer(ffmpegInstance, logger)
+	contentAnalyzer.Codec = compatCodec()
+	contentAnalyzer.Params = analysisParams
+	contentAnalyzer.Explain = explain
 
 	// Variables to hold video info and analysis
 	var videoFile *ffmpeg.VideoFile
@@ -485,8 +1058,15 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 			logger.Warning("Error reading from cache: %v", err)
 		}
 		
+		// Analyses made with other tunables would not be reproducible
+		if cacheUsed && analysis.AnalyzedWith() != analysisParams {
+			logger.Info("Cached analysis of %s used other analysis parameters", filepath.Base(inputFile))
+			cacheUsed = false
+		}
+		
 		if cacheUsed {
 			logger.Info("Using cached analysis for %s", filepath.Base(inputFile))
+			explainReusedAnalysis(contentAnalyzer, analysis, filepath.Base(inputFile), "--use-cache")
 		} else {
 			logger.Info("No valid cache entry found, analyzing video...")
 		}
@@ -497,20 +1077,53 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 		// Get video info
 		videoFile, err = ffmpegInstance.GetVideoInfo(inputFile)
 		if err != nil {
-			return fmt.Errorf("falha ao obter informações do vídeo: %v", err)
+			return withExitCode(exitBadInput, i18n.Errorf("failed to get video info: %v", err))
 		}
 
 		// Display video info
 		displayVideoInfo(videoFile)
 
+		// Episodes of a series take the analysis shared by the series, or
+		// their own when they were analyzed as one of its samples
+		var priorUsed, analyzed bool
+		if seriesOf != nil {
+			var seriesResult *analyzer.VideoAnalysis
+			var own bool
+			if seriesResult, own = seriesAnalysis(inputFile, videoFile, options, contentAnalyzer); seriesResult != nil {
+				analysis, priorUsed, analyzed = seriesResult, !own, own
+			}
+		}
+
+		// Reuse the analysis of a similar video as a prior when asked to
+		if fastAnalysis && videoCache != nil && useCache && !priorUsed && !analyzed {
+			var source string
+			analysis, source, priorUsed, err = videoCache.SimilarAnalysis(videoFile, cache.DefaultSimilarityTolerance)
+			if err != nil {
+				logger.Warning("Error reading from cache: %v", err)
+			}
+			if priorUsed && analysis.AnalyzedWith() != analysisParams {
+				logger.Info("Cached analysis of %s used other analysis parameters", filepath.Base(source))
+				priorUsed = false
+			}
+			if priorUsed {
+				logger.Info("Fast analysis: reusing the analysis of similar video %s", filepath.Base(source))
+				explainReusedAnalysis(contentAnalyzer, analysis, "the similar video "+filepath.Base(source), "--fast-analysis")
+			} else {
+				logger.Info("No similar video in the cache, analyzing video...")
+			}
+		}
+
 		// Analyze video
-		analysis, err = contentAnalyzer.AnalyzeVideo(videoFile)
-		if err != nil {
-			return fmt.Errorf("falha ao analisar vídeo: %v", err)
+		if !priorUsed && !analyzed {
+			analysis, err = contentAnalyzer.AnalyzeVideo(videoFile)
+			if err != nil {
+				return i18n.Errorf("failed to analyze video: %v", err)
+			}
 		}
 
-		// Store in cache for future use if cache is enabled
-		if videoCache != nil && useCache {
+		// Store in cache for future use if cache is enabled. Borrowed analyses
+		// are not stored, so later runs still measure the file itself.
+		if videoCache != nil && useCache && !priorUsed {
 			err = videoCache.Put(inputFile, analysis, videoFile)
 			if err != nil {
 				logger.Warning("Failed to cache analysis: %v", err)
@@ -526,12 +1139,104 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 	// Display analysis results
 	displayAnalysisResults(analysis)
 
+	// Send the output where the routing rules put videos like this one
+	if outputFile, err = routeOutput(outputFile, videoFile, analysis); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+	ffmpegInstance.OutputFile = outputFile
+
+	// Hooks see the analysis and can stop the file
+	hookJob := hooks.Job{InputFile: inputFile, OutputFile: outputFile, Analysis: hookAnalysis(videoFile, analysis)}
+	if err := jobHooks.Run(hooks.AfterAnalysis, hookJob); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
 	// Get compression settings based on analysis
 	compressionSettings, err := contentAnalyzer.GetCompressionSettings(analysis, quality)
 	if err != nil {
 		logger.Error("Failed to determine compression settings: %v", err)
 		return err
 	}
+	diagnosticJob.settings = compressionSettings
+
+	// Add the video filters given on the command line
+	if err := applyVideoFilters(compressionSettings, inputFile, videoFile); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Optimize static screen areas of screencasts
+	if screencastROI && analysis.ContentType == analyzer.ContentTypeScreencast {
+		layout, err := contentAnalyzer.DetectScreencastLayout(videoFile)
+		if err != nil {
+			logger.Warning("Screencast region analysis failed: %v", err)
+		} else {
+			if layout.Overlay != nil {
+				logger.Info("Webcam overlay detected at %s", layout.Overlay)
+			} else {
+				logger.Info("No webcam overlay detected, treating the whole screen as static")
+			}
+			analyzer.ApplyScreencastROI(compressionSettings, layout, videoFile)
+		}
+	}
+
+	// Copy or drop streams the user does not want re-encoded
+	if err := compressor.ApplyStreamModes(compressionSettings, streamModes()); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Keep the audio tracks in the languages asked for
+	applyAudioLanguages(compressionSettings, videoFile)
+
+	// Downmix surround audio when a smaller layout was requested
+	mappedAudio := compressor.MappedAudio(compressionSettings, videoFile.AudioInfo)
+	if err := compressor.ApplyAudioChannels(compressionSettings, mappedAudio, audioChannels); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Keep to what the devices of --compat play
+	if err := applyCompat(compressionSettings, outputFile, videoFile, contentAnalyzer); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Place keyframes at the interval given on the command line
+	if err := applyKeyframes(compressionSettings, videoFile, contentAnalyzer); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Settings given on the command line win over the analyzer's
+	if err := applyOverrides(compressionSettings, contentAnalyzer); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Signal the level of the video actually encoded
+	if err := applyLevel(compressionSettings, videoFile, contentAnalyzer); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Lay the output out for playback
+	if err := applyMuxer(compressionSettings, outputFile, videoFile); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
+	// Names with the codec could only be completed once the settings were known
+	if strings.Contains(filepath.Base(outputFile), batch.CodecPlaceholder) {
+		outputFile = batch.ExpandCodec(outputFile, compressionSettings["codec"])
+		if _, err := os.Stat(outputFile); err == nil && !force && renditions == "" {
+			return i18n.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
+		}
+		ffmpegInstance.OutputFile = outputFile
+		logger.Field("Output File", outputFile)
+	}
 
 	// Display recommended settings
 	logger.Info("Recommended compression settings:")
@@ -539,8 +1244,35 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 		logger.Info("  %s: %s", key, value)
 	}
 
+	// A quality ladder shares the analysis and settings across its renditions
+	if renditions != "" {
+		return compressRenditions(inputFile, outputFile, videoFile, analysis, compressionSettings, ffmpegInstance, contentAnalyzer, videoCache)
+	}
+
 	// Create a new video compressor
 	videoCompressor := compressor.NewVideoCompressor(ffmpegInstance, contentAnalyzer, logger)
+	videoCompressor.TargetVMAF = targetVMAF
+	videoCompressor.HardwareEncoder = hwEncoder
+	videoCompressor.Threads = threads
+	videoCompressor.IgnoreErrors = ignoreErrors
+	videoCompressor.Overrides = overrides()
+	videoCompressor.Parallel = parallelMode
+	videoCompressor.Segments = segmentCount
+	videoCompressor.HDR10Plus = keepHDR10Plus
+	videoCompressor.Throttle = encodeThrottle
+
+	// Estimate the result before starting so long jobs are not a surprise
+	estimatedSize, estimatedTime := videoCompressor.EstimateCompression(analysis, compressionSettings, preset)
+	if estimatedSize > 0 {
+		logger.Field("Estimated Output Size", "%s", formatSize(estimatedSize))
+		logger.Field("Estimated Encode Time", "%s", formatEstimate(estimatedTime))
+	}
+
+	if confirm && estimatedTime > longJobThreshold {
+		if !askConfirmation(i18n.T("Encoding %s is estimated to take %s. Continue?", filepath.Base(inputFile), formatEstimate(estimatedTime))) {
+			return i18n.Errorf("compression cancelled by user")
+		}
+	}
 
 	// Initialize the report generator
 	reportGenerator := reporter.NewReportGenerator(logger, ffmpegInstance)
@@ -549,9 +1281,13 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 	report := reportGenerator.CreateReport(inputFile, outputFile, videoFile, analysis)
 
 	// Create a more advanced progress tracker
+	description := i18n.Tr("Compressing Video")
+	if batchPosition != "" {
+		description = i18n.T("[%s] Compressing %s", batchPosition, filepath.Base(inputFile))
+	}
 	progressOptions := util.ProgressTrackerOptions{
 		Total:          100,
-		Description:    "Compressing Video",
+		Description:    description,
 		Logger:         logger,
 		ShowPercentage: true,
 		ShowSpeed:      true,
@@ -560,11 +1296,25 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 	progressBar := util.NewProgressTrackerWithOptions(progressOptions)
 
 	// Set up status callback for real-time updates
-	progressBar.SetStatusCallback(func(progress int64, timeRemaining time.Duration, rate float64) {
+	progressBar.SetStatusCallback(func(status util.ProgressStatus) {
+		if status.Encode != nil {
+			logger.Debug("Compression Status: %d%% complete, %.1f seconds remaining, %.0f fps, %.0f kbit/s, q %.1f, %.2fx",
+				status.Progress, status.TimeRemaining.Seconds(), status.Encode.FPS, status.Encode.Bitrate,
+				status.Encode.Q, status.Encode.Speed)
+			return
+		}
 		logger.Debug("Compression Status: %d%% complete, %.1f seconds remaining", 
-			progress, timeRemaining.Seconds())
+			status.Progress, status.TimeRemaining.Seconds())
 	})
 
+	// Hooks see the final settings and can stop the file before the encode
+	hookJob.OutputFile = outputFile
+	hookJob.Settings = compressionSettings
+	if err := jobHooks.Run(hooks.BeforeEncode, hookJob); err != nil {
+		logger.Error("%v", err)
+		return err
+	}
+
 	// Start compression
 	logger.Section("Compression Process")
 	logger.Info("Starting compression process...")
@@ -580,28 +1330,58 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 		progressBar,
 	)
 
+	setCPUTime(result, cpuMeter)
 	if err != nil {
 		logger.Error("Compression failed: %v", err)
-		return err
+		reportEncodeFailure(err)
+		recordResult(result)
+		runAfterEncodeHooks(hookJob, result)
+		return withExitCode(compressionExitCode(err), err)
 	}
 
 	// Ensure progress bar is completed
 	progressBar.Finish()
 
+	// Bring the subtitles, artwork and metadata files of the input along
+	handleSidecars(inputFile, outputFile, compressionSettings, videoCompressor)
+
+	// Keep the source timestamps so library sort orders are not disturbed
+	if preserveTimes && !ffmpeg.IsRemote(inputFile) {
+		if err := util.PreserveFileAttributes(inputFile, outputFile); err != nil {
+			logger.Warning("Failed to preserve file times: %v", err)
+		}
+	}
+
 	// Complete the report with results
 	report = reportGenerator.FinalizeReport(report, result)
+	report.Decisions = analyzer.FinalDecisions(contentAnalyzer.Decisions(), result.Settings)
+	report.FrameDiffs = reportGenerator.DiffFrames(report)
 
 	// Display comprehensive report to console
 	reportGenerator.DisplayReportToConsole(report)
 
 	// Save report to file
-	reportPath, err := reportGenerator.SaveReportToFile(report)
+	savedReportPath, err := reportGenerator.SaveReport(report, reportFormat, reportPath)
 	if err != nil {
 		logger.Warning("Failed to save report to file: %v", err)
 	} else {
-		logger.Info("Compression report saved to: %s", reportPath)
+		logger.Info("Compression report saved to: %s", savedReportPath)
+	}
+
+	// Write checksum and provenance sidecars
+	if writeChecksums {
+		manifestPath, err := reportGenerator.WriteChecksums(report)
+		if err != nil {
+			logger.Warning("Failed to write checksums: %v", err)
+		} else {
+			logger.Info("Checksums saved to: %s", manifestPath)
+		}
 	}
 
+	// Poster and seek previews for web players
+	writePreviews(outputFile, outputFile, videoFile, ffmpegInstance)
+	writeComparison(inputFile, outputFile, outputFile, analysis, ffmpegInstance)
+
 	// Display a user-friendly completion message
 	processingTime := time.Since(startTime).Round(time.Second)
 	savings := fmt.Sprintf("%.1f%%", result.SavedSpacePercent)
@@ -617,9 +1397,147 @@ func processSingleFile(inputFile, outputFile string, videoCache *cache.VideoAnal
 		logger.Info("Analysis time saved by using cache!")
 	}
 
+	// Remember the outcome so identical re-runs can be skipped
+	if videoCache != nil && useCache {
+		if err := videoCache.PutOutcome(inputFile, paramsHash, result); err != nil {
+			logger.Warning("Failed to cache compression outcome: %v", err)
+		}
+	}
+
+	// Later runs skip the source and the output wherever they end up
+	if videoCache != nil {
+		if err := videoCache.RecordCompression(inputFile, outputFile); err != nil {
+			logger.Warning("Failed to record the compression in the ledger: %v", err)
+		}
+	}
+
+	recordResult(result)
+	runAfterEncodeHooks(hookJob, result)
+
 	return nil
 }
 
+// streamModes returns the stream passthrough modes selected on the command line
+func streamModes() compressor.StreamModes {
+	return compressor.StreamModes{CopyVideo: copyVideo, CopyAudio: copyAudio, NoAudio: noAudio}
+}
+
+// applyAudioLanguages keeps the audio tracks --keep-audio-langs asks for.
+// A file with none of them keeps its audio as it is, rather than losing its
+// sound.
+func applyAudioLanguages(settings map[string]string, videoFile *ffmpeg.VideoFile) {
+	languages, _ := compressor.ParseLanguages(keepAudioLangs) // Validated with the flags
+	if compressor.ApplyAudioLanguages(settings, videoFile.AudioInfo, languages) {
+		if kept := compressor.MappedAudio(settings, videoFile.AudioInfo); settings["audio_streams"] != "" {
+			names := make([]string, len(kept))
+			for i, stream := range kept {
+				names[i] = stream.Language
+			}
+			logger.Info("Keeping %d of %d audio tracks: %s", len(kept), len(videoFile.AudioInfo), strings.Join(names, ", "))
+		}
+		return
+	}
+	logger.Warning("No audio track is in %s, keeping the audio as it is", keepAudioLangs)
+}
+
+// compressionParamsHash hashes the options that determine the output of an
+// encode, so cached outcomes are only reused for identical runs
+func compressionParamsHash() string {
+	return cache.HashParams(map[string]string{
+		"quality":        strconv.Itoa(quality),
+		"preset":         preset,
+		"codec":          codec,
+		"target_vmaf":    strconv.FormatFloat(targetVMAF, 'f', -1, 64),
+		"hwaccel":        hwaccel,
+		"hw_encoder":     hwEncoder,
+		"roi":            strconv.FormatBool(screencastROI),
+		"ignore_errors":  strconv.FormatBool(ignoreErrors),
+		"copy_video":     strconv.FormatBool(copyVideo),
+		"copy_audio":     strconv.FormatBool(copyAudio),
+		"no_audio":       strconv.FormatBool(noAudio),
+		"audio_channels": audioChannels,
+		"audio_langs":    keepAudioLangs,
+		"compat":         compat,
+		"crf":            crfOverride,
+		"video_bitrate":  videoBitrateOverride,
+		"audio_bitrate":  audioBitrateOverride,
+		"pix_fmt":        pixFmtOverride,
+		"tune":           tuneOverride,
+		"extra_args":     extraFFmpegArgs,
+		"deinterlace":    strconv.FormatBool(deinterlace),
+		"fps":            strconv.FormatFloat(outputFPS, 'f', -1, 64),
+		"crop":           crop,
+		"denoise":        denoise,
+		"tonemap":        strconv.FormatBool(tonemap),
+		"scale":          scale,
+		"square_pixels":  strconv.FormatBool(squarePixels),
+		"burn_subs":      burnSubs,
+		"hdr10plus":      strconv.FormatBool(keepHDR10Plus),
+		"keyint":         strconv.Itoa(keyint),
+		"gop_seconds":    strconv.FormatFloat(gopSeconds, 'f', -1, 64),
+		"no_faststart":   strconv.FormatBool(noFaststart),
+		"fragmented":     strconv.FormatBool(fragmented),
+		"remux_only":     remuxOnly,
+		"version":        util.Version,
+	})
+}
+
+// openLedger opens the cache database for the compression ledger alone,
+// leaving the analysis cache disabled
+func openLedger() *cache.VideoAnalysisCache {
+	videoCache, err := cache.NewVideoAnalysisCache(logger)
+	if err != nil {
+		logger.Debug("Compression ledger unavailable, recognizing outputs by name: %v", err)
+		return nil
+	}
+	videoCache.Enabled = false
+	return videoCache
+}
+
+// ledgerSkipReason returns why a directory run should leave inputFile alone
+// according to the compression ledger, or an empty string. Outputs of
+// earlier runs are always skipped; sources that were already compressed are
+// compressed again with -f.
+func ledgerSkipReason(videoCache *cache.VideoAnalysisCache, inputFile string) string {
+	if videoCache == nil {
+		return ""
+	}
+	if entry, found, err := videoCache.CompressedFrom(inputFile); err != nil {
+		logger.Debug("Could not check the compression ledger: %v", err)
+		return ""
+	} else if found {
+		return i18n.T("it is the output of an earlier run (compressed from %s)", entry.SourcePath)
+	}
+	if force {
+		return ""
+	}
+	entries, err := videoCache.CompressedTo(inputFile)
+	if err != nil {
+		logger.Debug("Could not check the compression ledger: %v", err)
+		return ""
+	}
+	if len(entries) > 0 {
+		return i18n.T("already compressed to %s (use -f to compress it again)", entries[0].OutputPath)
+	}
+	return ""
+}
+
+// isSourceVideo reports whether a directory run should compress filename
+// when there is no ledger: a video not named like an output of an earlier run
+func isSourceVideo(filename string) bool {
+	return isVideoFile(filename) && !hasCompressedSuffix(filename)
+}
+
+// hasCompressedSuffix checks if a filename is named like an output of --name-template
+func hasCompressedSuffix(filename string) bool {
+	return batch.IsOutputName(nameTemplate, filename)
+}
+
+// defaultOutputName names the output of the file name input with --name-template
+func defaultOutputName(input string) string {
+	return batch.OutputName(nameTemplate, input, batch.NameFields{Quality: quality, Preset: preset, Ext: remuxExt()})
+}
+
 // isVideoFile checks if a file is a video based on its extension
 func isVideoFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
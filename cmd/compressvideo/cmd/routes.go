@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+var (
+	outputRoutes []config.Route // Routing rules of the run, those of --profile first
+	routeRoot    string         // Output directory of a directory run, whose layout routed outputs keep
+)
+
+// setupRoutes reads the routing rules of the --profile profile and the
+// config file. An output file named with -o is never routed; the outputs
+// of a directory run are.
+func setupRoutes(cmd *cobra.Command, isDir bool) error {
+	outputRoutes = nil
+	if !isDir && cmd.Flags().Changed("output") {
+		return nil
+	}
+
+	// setupNotifier already reported a config file that cannot be read
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		return nil
+	}
+	if profileName != "" {
+		outputRoutes = append(outputRoutes, cfg.Profiles[profileName].Routes...)
+	}
+	outputRoutes = append(outputRoutes, cfg.Routes...)
+	if err := batch.ValidateRoutes(outputRoutes); err != nil {
+		return i18n.Errorf("invalid routes in %s: %v", config.DefaultPath(), err)
+	}
+	return nil
+}
+
+// routeOutput sends outputFile where the first matching route puts videos
+// like the analyzed one, creating the directory. It returns outputFile when
+// no route matches.
+func routeOutput(outputFile string, videoFile *ffmpeg.VideoFile, analysis *analyzer.VideoAnalysis) (string, error) {
+	routed := batch.RouteOutput(outputRoutes, outputFile, routeRoot, analysis.ContentType, videoFile.VideoInfo.Height)
+	if routed == outputFile {
+		return outputFile, nil
+	}
+
+	// Names with the codec are checked once it is known
+	if !strings.Contains(filepath.Base(routed), batch.CodecPlaceholder) && renditions == "" && !force {
+		if _, err := os.Stat(routed); err == nil {
+			return "", i18n.Errorf("output file already exists (use -f to force overwrite): %s", routed)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(routed), 0755); err != nil {
+		return "", i18n.Errorf("failed to create output directory: %w", err)
+	}
+	logger.Info("Routing the %s output to %s", analysis.ContentType.Name(), filepath.Dir(routed))
+	return routed, nil
+}
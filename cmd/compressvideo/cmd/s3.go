@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/storage"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+var (
+	s3Endpoint string // Endpoint of an S3-compatible service such as MinIO
+
+	s3Output string      // s3:// destination of the staged output, uploaded after compression
+	s3Store  *storage.S3 // Client created while staging
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&s3Endpoint, "s3-endpoint", "", "Endpoint of an S3-compatible service such as MinIO (e.g. http://localhost:9000) for s3:// inputs and outputs")
+}
+
+// stageS3 downloads an s3:// input and points an s3:// output at a local
+// file, so the rest of the run works on local paths. The returned function
+// removes the staged files.
+func stageS3() (func(), error) {
+	cleanup := func() {}
+	if !storage.IsS3(inputFile) && !storage.IsS3(outputFile) {
+		return cleanup, nil
+	}
+
+	ctx := context.Background()
+	store, err := storage.NewS3(ctx, s3Endpoint)
+	if err != nil {
+		return cleanup, err
+	}
+	s3Store = store
+
+	stageDir, err := util.NewJobTempDir()
+	if err != nil {
+		return cleanup, i18n.Errorf("failed to create staging directory: %w", err)
+	}
+	cleanup = func() { os.RemoveAll(stageDir) }
+
+	if storage.IsS3(outputFile) {
+		if _, _, err := storage.ParseS3URI(outputFile); err != nil {
+			return cleanup, err
+		}
+		if !force {
+			exists, err := store.Exists(ctx, outputFile)
+			if err != nil {
+				return cleanup, i18n.Errorf("failed to check %s: %w", outputFile, err)
+			}
+			if exists {
+				return cleanup, i18n.Errorf("output file already exists (use -f to force overwrite): %s", outputFile)
+			}
+		}
+		s3Output = outputFile
+		outputFile = filepath.Join(stageDir, "output", storage.BaseName(outputFile))
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+			return cleanup, i18n.Errorf("failed to create staging directory: %w", err)
+		}
+	}
+
+	if storage.IsS3(inputFile) {
+		name := storage.BaseName(inputFile)
+		if name == "" {
+			_, _, err := storage.ParseS3URI(inputFile)
+			return cleanup, err
+		}
+
+		// The staged copy is deleted afterwards, so a local output goes to
+		// the current directory
+		if outputFile == "" {
+			outputFile = defaultOutputName(name)
+		}
+
+		local := filepath.Join(stageDir, name)
+		logger.Info("Downloading %s...", inputFile)
+		size, err := store.Download(ctx, inputFile, local)
+		if err != nil {
+			return cleanup, err
+		}
+		logger.Info("Downloaded %s (%s)", inputFile, formatSize(size))
+		inputFile = local
+	}
+
+	return cleanup, nil
+}
+
+// uploadS3Output uploads the compressed file to its s3:// destination
+func uploadS3Output() error {
+	if s3Output == "" {
+		return nil
+	}
+
+	logger.Info("Uploading %s...", s3Output)
+	if err := s3Store.Upload(context.Background(), outputFile, s3Output); err != nil {
+		return err
+	}
+	logger.Success("Uploaded %s", s3Output)
+	return nil
+}
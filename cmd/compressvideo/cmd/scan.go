@@ -0,0 +1,284 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	scanSort string // Column the scan is sorted by, see batch.ScanSortKeys
+	scanCSV  string // File the scan is written to as CSV, - for stdout
+	scanJSON bool   // Print the scan as JSON
+	scanTop  int    // Files listed, 0 for all
+)
+
+// scanCmd surveys a video library without compressing anything
+var scanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Estimate the savings of compressing a video library",
+	Long: `Probe every video of a directory, without decoding or encoding, and
+list their size, codec, resolution and the space compressing them is
+estimated to save, with totals by codec and resolution, so you know what
+to compress first.
+
+Savings are estimated like those of analyze, from the settings the
+analyzer recommends at the quality of -q. The analysis behind them comes
+from the stream properties ffprobe reports, or is the full analysis of
+files an earlier run analyzed with the cache on. Outputs and sources
+compressed before are left out.
+
+Examples:
+  compressvideo scan ~/Videos -r
+  compressvideo scan ~/Videos -r --sort size --top 20
+  compressvideo scan ~/Videos -r --csv library.csv`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			inputFile = args[0]
+		}
+		return scanCommand()
+	},
+}
+
+// scanOutput is the JSON document printed by scan --json
+type scanOutput struct {
+	Directory string            `json:"directory"`
+	Files     []batch.ScanEntry `json:"files"`
+	Summary   batch.ScanSummary `json:"summary"`
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+
+	scanCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Directory to scan")
+	scanCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Scan subdirectories too")
+	scanCmd.Flags().IntVarP(&quality, "quality", "q", 3, "Quality level the savings are estimated for (1-5)")
+	scanCmd.Flags().StringVar(&scanSort, "sort", "savings", "Column to sort by: "+strings.Join(batch.ScanSortKeys, ", ")+"; sizes, savings and rates from the largest")
+	scanCmd.Flags().StringVar(&scanCSV, "csv", "", "Write the list as CSV to this file, or to stdout with -")
+	scanCmd.Flags().BoolVar(&scanJSON, "json", false, "Print the list and totals as JSON")
+	scanCmd.Flags().IntVar(&scanTop, "top", 0, "List only this many files, after sorting (0 = all); totals count every file")
+	scanCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show verbose output")
+}
+
+// scanCommand probes the videos of a directory and prints what compressing
+// them would save
+func scanCommand() error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	quiet := scanJSON || scanCSV == "-"
+	if quiet {
+		// Keep stdout clean for the document; errors still go to stderr
+		logger.SetLevel(util.LogLevelError)
+	} else {
+		logger.Title("CompressVideo - Library Scan")
+	}
+
+	if inputFile == "" {
+		return withExitCode(exitBadInput, i18n.Errorf("a directory is required (compressvideo scan <dir>)"))
+	}
+	if stat, err := os.Stat(inputFile); err != nil || !stat.IsDir() {
+		return withExitCode(exitBadInput, i18n.Errorf("input must be a directory: %s", inputFile))
+	}
+	if !batch.ValidScanSort(scanSort) {
+		return withExitCode(exitBadInput, i18n.Errorf("sort must be one of: %s (got %s)", strings.Join(batch.ScanSortKeys, ", "), scanSort))
+	}
+	if quality < 1 || quality > 5 {
+		return withExitCode(exitBadInput, i18n.Errorf("quality must be between 1-5 (got %d)", quality))
+	}
+	if scanTop < 0 {
+		return withExitCode(exitBadInput, i18n.Errorf("--top must be 0 or more (got %d)", scanTop))
+	}
+	if err := requireFFmpeg(); err != nil {
+		return err
+	}
+
+	// Cached analyses make better estimates, and the ledger tells outputs
+	// of earlier runs apart
+	videoCache, err := cache.NewVideoAnalysisCache(logger)
+	if err != nil {
+		logger.Debug("Analysis cache unavailable, estimating from the streams alone: %v", err)
+		videoCache = nil
+	} else {
+		defer videoCache.Close()
+	}
+	match := isVideoFile
+	if videoCache == nil {
+		match = isSourceVideo
+	}
+	jobs, err := batch.Plan(inputFile, inputFile, batch.Options{Recursive: recursive, Match: match})
+	if err != nil {
+		return i18n.Errorf("failed to read input directory: %w", err)
+	}
+
+	ffmpegInstance := ffmpeg.NewFFmpeg(inputFile, "", nil, logger)
+	contentAnalyzer := analyzer.NewContentAnalyzer(ffmpegInstance, logger)
+	entries := make([]batch.ScanEntry, 0, len(jobs))
+	for i, job := range jobs {
+		if reason := ledgerSkipReason(videoCache, job.Input); reason != "" {
+			logger.Debug("Skipping %s: %s", job.Input, reason)
+			continue
+		}
+		logger.Progress("Probing %d/%d: %s", i+1, len(jobs), scanName(job.Input))
+		entries = append(entries, scanFile(job.Input, ffmpegInstance, contentAnalyzer, videoCache))
+	}
+
+	summary := batch.SummarizeScan(entries)
+	batch.SortScan(entries, scanSort)
+	listed := entries
+	if scanTop > 0 && len(listed) > scanTop {
+		listed = listed[:scanTop]
+	}
+
+	if scanCSV != "" {
+		if err := writeScanCSV(listed); err != nil {
+			return i18n.Errorf("failed to write %s: %v", scanCSV, err)
+		}
+	}
+	if scanJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(scanOutput{Directory: inputFile, Files: listed, Summary: summary})
+	}
+	if quiet {
+		return nil
+	}
+
+	displayScan(listed, summary)
+	if scanCSV != "" {
+		logger.Success("Scan written to %s", scanCSV)
+	}
+	return nil
+}
+
+// scanFile probes one video and estimates what compressing it saves, from
+// its cached analysis when an earlier run made one
+func scanFile(path string, ffmpegInstance *ffmpeg.FFmpeg, contentAnalyzer *analyzer.ContentAnalyzer, videoCache *cache.VideoAnalysisCache) batch.ScanEntry {
+	entry := batch.ScanEntry{Path: path, Estimate: batch.EstimateProbe}
+	var analysis *analyzer.VideoAnalysis
+	var videoFile *ffmpeg.VideoFile
+	if videoCache != nil {
+		if cached, cachedFile, found, err := videoCache.Get(path); err == nil && found {
+			analysis, videoFile = cached, cachedFile
+			analysis.VideoFile = videoFile
+			entry.Estimate = batch.EstimateAnalysis
+		}
+	}
+	if analysis == nil {
+		var err error
+		if videoFile, err = ffmpegInstance.GetVideoInfo(path); err != nil {
+			logger.Warning("Failed to probe %s: %v", path, err)
+			entry.Error = err.Error()
+			entry.Estimate = ""
+			if stat, statErr := os.Stat(path); statErr == nil {
+				entry.Size = stat.Size()
+			}
+			return entry
+		}
+		analysis = contentAnalyzer.EstimateFromProbe(videoFile)
+	}
+
+	entry.Size = videoFile.Size
+	entry.Duration = videoFile.Duration
+	entry.Codec = videoFile.VideoInfo.Codec
+	entry.Width, entry.Height = videoFile.VideoInfo.Width, videoFile.VideoInfo.Height
+	entry.Bitrate = videoFile.BitRate
+	entry.ContentType = analysis.ContentType.String()
+	entry.RecommendedCodec = analysis.RecommendedCodec
+
+	// Savings are estimated like those of analyze, from the size of the
+	// output of the settings the analysis recommends
+	settings, err := contentAnalyzer.GetCompressionSettings(analysis, quality)
+	if err != nil {
+		return entry
+	}
+	if estimated := analyzer.EstimateOutputSize(analysis, settings, compressor.MappedAudio(settings, videoFile.AudioInfo)); estimated > 0 && estimated < videoFile.Size {
+		entry.EstimatedSavings = videoFile.Size - estimated
+		entry.Potential = int(entry.EstimatedSavings * 100 / videoFile.Size)
+	}
+	return entry
+}
+
+// writeScanCSV writes the scan to the file of --csv, or to stdout
+func writeScanCSV(entries []batch.ScanEntry) error {
+	var w io.Writer = os.Stdout
+	if scanCSV != "-" {
+		file, err := os.Create(scanCSV)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+	return batch.WriteScanCSV(w, entries)
+}
+
+// displayScan prints the scanned files and their totals as tables
+func displayScan(entries []batch.ScanEntry, summary batch.ScanSummary) {
+	if len(entries) == 0 {
+		logger.Warning("No video files found in %s", inputFile)
+		return
+	}
+
+	rows := make([][]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Error != "" {
+			rows = append(rows, []string{"-", formatSize(e.Size), "-", "-", "-", "-", "-", "-", scanName(e.Path)})
+			continue
+		}
+		rows = append(rows, []string{formatSize(e.EstimatedSavings), formatSize(e.Size), fmt.Sprintf("%d%%", e.Potential),
+			e.Codec, e.Resolution(), formatBitrate(e.Bitrate), util.FormatDuration(int(math.Round(e.Duration))), e.ContentType, scanName(e.Path)})
+	}
+	logger.Section("Videos")
+	logger.Table([]string{"Est. Savings", "Size", "Potential", "Codec", "Resolution", "Bitrate", "Duration", "Content", "File"}, rows)
+
+	logger.Section("By Codec")
+	logger.Table([]string{"Codec", "Files", "Size", "Est. Savings"}, scanGroupRows(summary.Codecs))
+	logger.Section("By Resolution")
+	logger.Table([]string{"Resolution", "Files", "Size", "Est. Savings"}, scanGroupRows(summary.Resolutions))
+
+	logger.Section("Totals")
+	logger.Field("Files", "%d", summary.Files)
+	if summary.Failed > 0 {
+		logger.Field("Could Not Probe", "%d", summary.Failed)
+	}
+	logger.Field("Total Size", "%s", formatSize(summary.Size))
+	logger.Field("Total Duration", "%s", util.FormatDuration(int(math.Round(summary.Duration))))
+	savedPercent := 0.0
+	if summary.Size > 0 {
+		savedPercent = float64(summary.EstimatedSavings) / float64(summary.Size) * 100
+	}
+	logger.Field("Estimated Savings", "%s (%.1f%%)", formatSize(summary.EstimatedSavings), savedPercent)
+}
+
+// scanGroupRows returns the table rows of scan totals
+func scanGroupRows(groups []batch.ScanGroup) [][]string {
+	rows := make([][]string, 0, len(groups))
+	for _, group := range groups {
+		rows = append(rows, []string{group.Name, fmt.Sprint(group.Files), formatSize(group.Size), formatSize(group.EstimatedSavings)})
+	}
+	return rows
+}
+
+// scanName returns path relative to the scanned directory
+func scanName(path string) string {
+	if name, err := filepath.Rel(inputFile, path); err == nil {
+		return name
+	}
+	return filepath.Base(path)
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/spf13/cobra"
+)
+
+var onlyBetween string // Time of day encoding is allowed in, e.g. 01:00-07:00
+
+// pauseSchedule is the reason of the pauses outside --only-between
+const pauseSchedule = "schedule"
+
+// scheduleCheckInterval is how often the clock is checked against --only-between
+var scheduleCheckInterval = 30 * time.Second
+
+func init() {
+	for _, command := range []*cobra.Command{rootCmd, batchCmd} {
+		command.Flags().StringVar(&onlyBetween, "only-between", "", "Only encode between these times of day, e.g. 01:00-07:00 (or 22:00-06:00 past midnight); encoding pauses outside them and resumes where it stopped")
+	}
+}
+
+// validateSchedule checks --only-between
+func validateSchedule() error {
+	_, err := batch.ParseWindow(onlyBetween)
+	return err
+}
+
+// startSchedule pauses the encodes whenever the clock is outside
+// --only-between, and resumes them when the window opens again. The
+// returned function stops it.
+func startSchedule() (stop func()) {
+	window, _ := batch.ParseWindow(onlyBetween)
+	if window.IsZero() {
+		return func() {}
+	}
+
+	check := func() {
+		now := time.Now()
+		if window.Contains(now) {
+			if ffmpeg.Held(pauseSchedule) {
+				ffmpeg.Release(pauseSchedule)
+				logger.Info("Inside --only-between %s, encoding resumed", window)
+			}
+			return
+		}
+		if !ffmpeg.Held(pauseSchedule) {
+			if err := ffmpeg.Hold(pauseSchedule); err != nil {
+				logger.Warning("Could not pause every FFmpeg process: %v", err)
+			}
+			logger.Info("Outside --only-between %s, encoding paused until %s", window, window.Next(now).Format("2006-01-02 15:04"))
+		}
+	}
+	check()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		ffmpeg.Release(pauseSchedule)
+	}
+}
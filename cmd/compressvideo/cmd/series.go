@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/batch"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var seriesMode bool // Share the analysis of a few episodes across each series
+
+// seriesState is what a directory run knows of one series
+type seriesState struct {
+	name      string
+	samples   []string                           // Episodes analyzed in full
+	analyses  map[string]*analyzer.VideoAnalysis // Analyses of the samples, by input
+	shared    *analyzer.VideoAnalysis            // Analysis derived from the samples, nil when none could be analyzed
+	reference *ffmpeg.VideoFile                  // First sample, which the other episodes must look like
+	analyzed  bool
+}
+
+// seriesOf holds the series of each episode of a --series directory run,
+// by input path
+var seriesOf map[string]*seriesState
+
+func init() {
+	rootCmd.Flags().BoolVar(&seriesMode, "series", false, "In directory runs, treat folders of episodes (S01E02, 1x02, Episode 3, at least 5 of them) as series: analyze 3 episodes of each in full and give the others their shared analysis after checking their resolution, codec, frame rate and duration match")
+}
+
+// planSeries finds the series among the jobs of a directory run
+func planSeries(jobs []batch.Job) {
+	seriesOf = nil
+	if !seriesMode {
+		return
+	}
+	seriesOf = make(map[string]*seriesState)
+	for _, series := range batch.GroupSeries(jobs) {
+		state := &seriesState{name: series.Name, analyses: make(map[string]*analyzer.VideoAnalysis)}
+		for _, i := range series.Samples() {
+			state.samples = append(state.samples, jobs[i].Input)
+		}
+		for _, i := range series.Jobs {
+			seriesOf[jobs[i].Input] = state
+		}
+		logger.Info("Found series %s with %d episodes", series.Name, len(series.Jobs))
+	}
+}
+
+// seriesAnalysis returns the analysis of an episode of a series: its own
+// when it is one of the samples, otherwise the shared one when the episode
+// looks like the others. The samples are analyzed when the first episode
+// comes up. It returns nil when the episode has to be analyzed itself, and
+// reports whether the analysis is the episode's own.
+func seriesAnalysis(inputFile string, videoFile *ffmpeg.VideoFile, options *ffmpeg.Options, contentAnalyzer *analyzer.ContentAnalyzer) (*analyzer.VideoAnalysis, bool) {
+	state := seriesOf[inputFile]
+	if state == nil {
+		return nil, false
+	}
+	if !state.analyzed {
+		state.analyzed = true
+		analyzeSeriesSamples(state, inputFile, videoFile, options)
+	}
+
+	if own, ok := state.analyses[inputFile]; ok {
+		return own, true
+	}
+	if state.shared == nil {
+		return nil, false
+	}
+	if reason := batch.SameSeries(state.reference, videoFile); reason != "" {
+		logger.Info("%s does not match series %s (%s), analyzing it in full", filepath.Base(inputFile), state.name, i18n.T(reason))
+		return nil, false
+	}
+
+	analysis := state.shared.ForVideo(videoFile)
+	names := make([]string, len(state.samples))
+	for i, sample := range state.samples {
+		names[i] = filepath.Base(sample)
+	}
+	logger.Info("Using the shared analysis of series %s", state.name)
+	contentAnalyzer.Record(analyzer.Decision{
+		Name:   "analysis",
+		Value:  analysis.ContentType.String() + ", " + analysis.MotionComplexity.String() + " motion",
+		Reason: "shared by the episodes of " + state.name + ", from the analyses of " + strings.Join(names, ", ") + "; run without --series for the reasons",
+	})
+	return analysis, false
+}
+
+// analyzeSeriesSamples analyzes the sample episodes of a series in full and
+// derives the analysis the other episodes share. inputFile is the episode
+// being processed, already probed as videoFile.
+func analyzeSeriesSamples(state *seriesState, inputFile string, videoFile *ffmpeg.VideoFile, options *ffmpeg.Options) {
+	logger.Info("Analyzing %d episodes of series %s to share their analysis with the others", len(state.samples), state.name)
+	var analyses []*analyzer.VideoAnalysis
+	for _, sample := range state.samples {
+		sampleFFmpeg := ffmpeg.NewFFmpeg(sample, "", options, logger)
+		sampleFFmpeg.Runner = ffmpeg.WithContext(sampleFFmpeg.Runner, jobContext)
+
+		sampleFile := videoFile
+		if sample != inputFile {
+			var err error
+			if sampleFile, err = sampleFFmpeg.GetVideoInfo(sample); err != nil {
+				logger.Warning("Could not analyze episode %s: %v", filepath.Base(sample), err)
+				continue
+			}
+		}
+
+		sampleAnalyzer := analyzer.NewContentAnalyzer(sampleFFmpeg, logger)
+		sampleAnalyzer.Codec = compatCodec()
+		sampleAnalyzer.Params = analysisParams
+		analysis, err := sampleAnalyzer.AnalyzeVideo(sampleFile)
+		if err != nil {
+			logger.Warning("Could not analyze episode %s: %v", filepath.Base(sample), err)
+			continue
+		}
+		if state.reference == nil {
+			state.reference = sampleFile
+		}
+		state.analyses[sample] = analysis
+		analyses = append(analyses, analysis)
+	}
+
+	state.shared = analyzer.SharedAnalysis(analyses)
+	if state.shared == nil {
+		logger.Warning("No episode of series %s could be analyzed, analyzing each in full", state.name)
+		return
+	}
+	logger.Info("Series %s: %s content, %s motion", state.name, state.shared.ContentType, state.shared.MotionComplexity)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/sidecar"
+)
+
+var (
+	sidecarMode string // Copy or move the subtitles, artwork and NFO files of the input
+	muxSubs     bool   // Add external text subtitles to the output as streams
+)
+
+func init() {
+	rootCmd.Flags().StringVar(&sidecarMode, "sidecars", "", "Bring the subtitles, artwork and NFO files named after the input (clip.en.srt, clip-poster.jpg, clip.nfo) along, renamed after the output: copy or move")
+	rootCmd.Flags().BoolVar(&muxSubs, "mux-subs", false, "Add the external SRT, ASS and WebVTT subtitles of the input to the output as subtitle streams, tagged with the language of their names")
+}
+
+// validateSidecars checks the sidecar flags
+func validateSidecars() error {
+	if !sidecar.ValidMode(sidecarMode) {
+		return i18n.Errorf("sidecars must be one of: copy, move (got %s)", sidecarMode)
+	}
+	return nil
+}
+
+// handleSidecars brings the sidecar files of inputFile along to outputFile:
+// with --mux-subs its text subtitles are added to the output, and with
+// --sidecars the other files are copied or moved next to it. The encode
+// already succeeded, so failures are only reported. settings are those the
+// output was encoded with.
+func handleSidecars(inputFile, outputFile string, settings map[string]string, videoCompressor *compressor.VideoCompressor) {
+	if ffmpeg.IsRemote(inputFile) {
+		return
+	}
+	files, err := sidecar.Find(inputFile, isVideoFile)
+	if err != nil {
+		logger.Warning("Could not look for sidecar files: %v", err)
+		return
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	if muxSubs {
+		var subs, rest []sidecar.File
+		for _, file := range files {
+			if file.Muxable() {
+				subs = append(subs, file)
+			} else {
+				rest = append(rest, file)
+			}
+		}
+		if len(subs) > 0 {
+			if !compressor.CanMuxSubtitles(outputFile) {
+				logger.Warning("Subtitles cannot be muxed into %s files, leaving them as sidecar files", filepath.Ext(outputFile))
+			} else if err := videoCompressor.MuxSubtitles(outputFile, subs, settings); err != nil {
+				logger.Warning("%v", err)
+			} else {
+				logger.Info("Muxed %d subtitle files into the output: %s", len(subs), sidecarNames(subs))
+				files = rest
+			}
+		}
+	}
+	if len(files) == 0 {
+		return
+	}
+
+	if sidecarMode == "" {
+		logger.Info("Found %d sidecar files next to the input (use --sidecars copy to bring them along): %s", len(files), sidecarNames(files))
+		return
+	}
+	move := sidecarMode == sidecar.ModeMove
+	placed, skipped, err := sidecar.Place(files, outputFile, move, force)
+	for _, path := range skipped {
+		logger.Warning("Skipping sidecar file %s: it already exists (use -f to force overwrite)", path)
+	}
+	if err != nil && move {
+		logger.Warning("Failed to move sidecar files: %v", err)
+	} else if err != nil {
+		logger.Warning("Failed to copy sidecar files: %v", err)
+	}
+	if len(placed) > 0 {
+		names := make([]string, len(placed))
+		for i, path := range placed {
+			names[i] = filepath.Base(path)
+		}
+		logger.Info("Sidecar files next to the output: %s", strings.Join(names, ", "))
+	}
+}
+
+// sidecarNames lists the file names of sidecars
+func sidecarNames(files []sidecar.File) string {
+	names := make([]string, len(files))
+	for i, file := range files {
+		names[i] = filepath.Base(file.Path)
+	}
+	return strings.Join(names, ", ")
+}
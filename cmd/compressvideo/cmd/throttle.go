@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	maxLoad        float64 // Load average above which encoding is cut back, 0 for no limit
+	maxTemperature float64 // CPU temperature in °C above which encoding is cut back, 0 for no limit
+
+	// encodeThrottle cuts back the encodes while the machine runs hot, nil
+	// without --max-load or --max-temp
+	encodeThrottle *compressor.Throttle
+)
+
+// pauseThrottle is the reason of the pauses of --max-load and --max-temp
+const pauseThrottle = "load"
+
+// throttleCheckInterval is how often the load and temperature are read
+var throttleCheckInterval = 15 * time.Second
+
+func init() {
+	for _, command := range []*cobra.Command{rootCmd, batchCmd} {
+		command.Flags().Float64Var(&maxLoad, "max-load", 0, "Cut back encoding while the load average is above this (e.g. the number of CPU cores): halve the parallel segments, then pause until it falls (0 = no limit)")
+		command.Flags().Float64Var(&maxTemperature, "max-temp", 0, "Cut back encoding while the CPU is hotter than this many °C, like --max-load (Linux only; 0 = no limit)")
+	}
+}
+
+// validateThrottle checks --max-load and --max-temp
+func validateThrottle() error {
+	if maxLoad < 0 {
+		return i18n.Errorf("max-load must be 0 or more (got %g)", maxLoad)
+	}
+	if maxTemperature < 0 {
+		return i18n.Errorf("max-temp must be 0 or more (got %g)", maxTemperature)
+	}
+	return nil
+}
+
+// startThrottle reads the load average and the CPU temperature every
+// throttleCheckInterval and cuts back the encodes while either is above
+// its limit. The returned function stops it.
+func startThrottle() (stop func()) {
+	encodeThrottle = nil
+	if maxLoad <= 0 && maxTemperature <= 0 {
+		return func() {}
+	}
+
+	load, temperature := readMachineHeat()
+	if maxLoad > 0 && math.IsNaN(load) {
+		logger.Warning("The load average cannot be read on this system, --max-load has no effect")
+	}
+	if maxTemperature > 0 && math.IsNaN(temperature) {
+		logger.Warning("The CPU temperature cannot be read on this system, --max-temp has no effect")
+	}
+
+	encodeThrottle = &compressor.Throttle{MaxLoad: maxLoad, MaxTemperature: maxTemperature}
+	check := func(load, temperature float64) {
+		reading := heatReading(load, temperature)
+		switch encodeThrottle.Observe(load, temperature) {
+		case compressor.ThrottleFewer:
+			logger.Warning("Machine running hot (%s), encoding fewer segments at once", reading)
+		case compressor.ThrottlePause:
+			if err := ffmpeg.Hold(pauseThrottle); err != nil {
+				logger.Warning("Could not pause every FFmpeg process: %v", err)
+			}
+			logger.Warning("Machine running hot (%s), encoding paused until it cools down", reading)
+		case compressor.ThrottleResume:
+			ffmpeg.Release(pauseThrottle)
+			logger.Info("Machine cooled down (%s), encoding resumed", reading)
+		case compressor.ThrottleMore:
+			logger.Info("Machine cooled down (%s), encoding more segments at once", reading)
+		}
+	}
+	check(load, temperature)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(throttleCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				check(readMachineHeat())
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		ffmpeg.Release(pauseThrottle)
+	}
+}
+
+// readMachineHeat returns the load average and the CPU temperature, NaN
+// for those not read: without a limit, or unavailable on this system
+func readMachineHeat() (load, temperature float64) {
+	load, temperature = math.NaN(), math.NaN()
+	if maxLoad > 0 {
+		if value, err := util.LoadAverage(); err == nil {
+			load = value
+		}
+	}
+	if maxTemperature > 0 {
+		if value, err := util.CPUTemperature(); err == nil {
+			temperature = value
+		}
+	}
+	return load, temperature
+}
+
+// heatReading describes a reading for the log, e.g. "load 9.2, 84°C"
+func heatReading(load, temperature float64) string {
+	var parts []string
+	if !math.IsNaN(load) {
+		parts = append(parts, i18n.T("load %.1f", load))
+	}
+	if !math.IsNaN(temperature) {
+		parts = append(parts, fmt.Sprintf("%.0f°C", temperature))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/cache"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	timeoutPerFile time.Duration // Longest one file may take, not counting pauses, 0 for no limit
+	maxRuntime     time.Duration // Longest the whole run may take, 0 for no limit
+
+	runDeadline time.Time                              // End of --max-runtime, zero without one
+	jobContext  context.Context = context.Background() // Stops the FFmpeg processes of the current file
+)
+
+// Causes of a stopped FFmpeg process
+var (
+	errFileTimedOut = errors.New("per-file timeout reached")
+	errRunTimedOut  = errors.New("maximum run time reached")
+)
+
+// validateTimeouts checks --timeout-per-file and --max-runtime
+func validateTimeouts() error {
+	if timeoutPerFile < 0 {
+		return i18n.Errorf("timeout-per-file must be 0 or more (got %s)", timeoutPerFile)
+	}
+	if maxRuntime < 0 {
+		return i18n.Errorf("max-runtime must be 0 or more (got %s)", maxRuntime)
+	}
+	return nil
+}
+
+// startRunDeadline starts the --max-runtime clock
+func startRunDeadline() {
+	runDeadline = time.Time{}
+	if maxRuntime > 0 {
+		runDeadline = time.Now().Add(maxRuntime)
+	}
+}
+
+// runDeadlineReached reports whether the run is out of time, so no further
+// files should be started
+func runDeadlineReached() bool {
+	return !runDeadline.IsZero() && !time.Now().Before(runDeadline)
+}
+
+// withActiveTimeout is like context.WithTimeoutCause but leaves out the time
+// the encodes spend paused, so a paused encode is not taken for a hung one
+func withActiveTimeout(parent context.Context, timeout time.Duration, cause error) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	deadline := time.Now().Add(timeout)
+	pausedBefore := ffmpeg.PausedTime()
+	timer := time.NewTimer(timeout)
+	go func() {
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			// A pause pushes the deadline back by its length
+			if wait := time.Until(deadline.Add(ffmpeg.PausedTime() - pausedBefore)); wait > 0 {
+				timer.Reset(wait)
+				continue
+			}
+			cancel(cause)
+			return
+		}
+	}()
+	return ctx, func() { cancel(nil) }
+}
+
+// runWithTimeouts processes one file with its FFmpeg processes stopped at
+// the per-file timeout or the run deadline. An output left incomplete by a
+// stopped encode is removed.
+func runWithTimeouts(inputFile, outputFile string, videoCache *cache.VideoAnalysisCache) error {
+	if timeoutPerFile <= 0 && runDeadline.IsZero() {
+		return processSingleFile(inputFile, outputFile, videoCache)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if timeoutPerFile > 0 {
+		ctx, cancel = withActiveTimeout(ctx, timeoutPerFile, errFileTimedOut)
+		defer cancel()
+	}
+	if !runDeadline.IsZero() {
+		ctx, cancel = context.WithDeadlineCause(ctx, runDeadline, errRunTimedOut)
+		defer cancel()
+	}
+	jobContext = ctx
+	defer func() { jobContext = context.Background() }()
+
+	start := time.Now()
+	err := processSingleFile(inputFile, outputFile, videoCache)
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+
+	// Outputs from earlier runs that this one never reached are kept
+	if info, statErr := os.Stat(outputFile); statErr == nil && !info.ModTime().Before(start) {
+		os.Remove(outputFile)
+	}
+	if errors.Is(context.Cause(ctx), errRunTimedOut) {
+		logger.Warning("Stopped %s: --max-runtime of %s reached", filepath.Base(inputFile), maxRuntime)
+	} else {
+		logger.Warning("Stopped %s after --timeout-per-file of %s", filepath.Base(inputFile), timeoutPerFile)
+	}
+	return withExitCode(exitTimedOut, err)
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sceneThreshold     float64 // Scene score above which a frame starts a new scene
+	complexityInterval float64 // Seconds between the frames sampled for complexity, 0 for keyframes
+	motionCutoffs      string  // Upper bounds of low, medium and high motion
+
+	analysisParams = analyzer.DefaultAnalysisParams() // Tunables of the run, from the flags and config file
+)
+
+func init() {
+	defaults := analyzer.DefaultAnalysisParams()
+	for _, command := range []*cobra.Command{rootCmd, analyzeCmd, batchCmd} {
+		command.Flags().Float64Var(&sceneThreshold, "scene-threshold", defaults.SceneThreshold, "Scene score (0-1) above which a frame counts as a scene change; lower finds more cuts")
+		command.Flags().Float64Var(&complexityInterval, "complexity-interval", defaults.ComplexityInterval, "Seconds between the frames sampled for frame complexity (0 = keyframes)")
+		command.Flags().StringVar(&motionCutoffs, "motion-cutoffs", analyzer.FormatMotionCutoffs(defaults.MotionCutoffs), "Upper bounds of low, medium and high motion as scenes-per-minute:frame-complexity pairs")
+	}
+}
+
+// loadAnalysisParams sets the analysis tunables from the analysis section of
+// the config file, overridden by the flags given on the command line
+func loadAnalysisParams(cmd *cobra.Command) error {
+	params := analyzer.DefaultAnalysisParams()
+
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		logger.Warning("%v", err)
+		cfg = &config.Config{}
+	}
+	if cfg.Analysis.SceneThreshold != 0 {
+		params.SceneThreshold = cfg.Analysis.SceneThreshold
+	}
+	if cfg.Analysis.ComplexityInterval != 0 {
+		params.ComplexityInterval = cfg.Analysis.ComplexityInterval
+	}
+	if cfg.Analysis.MotionCutoffs != "" {
+		if params.MotionCutoffs, err = analyzer.ParseMotionCutoffs(cfg.Analysis.MotionCutoffs); err != nil {
+			return err
+		}
+	}
+
+	flags := cmd.Flags()
+	if flags.Changed("scene-threshold") {
+		params.SceneThreshold = sceneThreshold
+	}
+	if flags.Changed("complexity-interval") {
+		params.ComplexityInterval = complexityInterval
+	}
+	if flags.Changed("motion-cutoffs") {
+		if params.MotionCutoffs, err = analyzer.ParseMotionCutoffs(motionCutoffs); err != nil {
+			return err
+		}
+	}
+
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	analysisParams = params
+	return nil
+}
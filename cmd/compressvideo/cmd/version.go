@@ -1,22 +1,149 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 	"github.com/cccarv82/compressvideo/pkg/util"
 	"github.com/spf13/cobra"
 )
 
+// versionJSON prints the version information as JSON
+var versionJSON bool
+
+// versionReport is the version information printed by 'version --json'
+type versionReport struct {
+	util.BuildInfo
+	FFmpeg *ffmpegVersion `json:"ffmpeg"` // Nil when FFmpeg is not found
+}
+
+// ffmpegVersion describes the FFmpeg installation the tool uses
+type ffmpegVersion struct {
+	Version     string              `json:"version"`
+	Path        string              `json:"path"`
+	FFprobePath string              `json:"ffprobe_path,omitempty"` // Empty when only ffmpeg is installed
+	Downloaded  bool                `json:"downloaded"`             // Installed by CompressVideo
+	Encoders    map[string][]string `json:"encoders"`               // Compiled-in encoders per format
+	Error       string              `json:"error,omitempty"`        // Why the encoders could not be listed
+}
+
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Display the version information",
-	Long:  `Display the version information of CompressVideo.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println(util.GetVersionInfo())
+	Long: `Display the version information of CompressVideo: the release, git
+commit and build date, the Go version and platform, and the FFmpeg
+installation it uses with the encoders compiled into it.
+
+Include this output in bug reports.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return versionCommand()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
-} 
\ No newline at end of file
+
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print the version information as JSON")
+}
+
+func versionCommand() error {
+	report := versionReport{BuildInfo: util.GetBuildInfo(), FFmpeg: detectFFmpegVersion()}
+	if versionJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Println(report.BuildInfo.String())
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+
+	logger.Section("Build")
+	logger.Field("Commit", "%s", valueOrUnknown(report.Commit))
+	logger.Field("Build Date", "%s", valueOrUnknown(report.Date))
+	logger.Field("Go Version", "%s", report.GoVersion)
+	logger.Field("Platform", "%s", report.Platform)
+
+	logger.Section("FFmpeg")
+	if report.FFmpeg == nil {
+		logger.Field("FFmpeg", "%s", i18n.Tr("not found; run repair-ffmpeg or install it"))
+		return nil
+	}
+	source := i18n.Tr("system")
+	if report.FFmpeg.Downloaded {
+		source = i18n.Tr("downloaded")
+	}
+	logger.Field("Version", "%s (%s)", report.FFmpeg.Version, source)
+	logger.Field("Path", "%s", report.FFmpeg.Path)
+	if report.FFmpeg.FFprobePath != "" {
+		logger.Field("FFprobe", "%s", report.FFmpeg.FFprobePath)
+	} else {
+		logger.Field("FFprobe", "%s", i18n.Tr("not found"))
+	}
+	if report.FFmpeg.Error != "" {
+		logger.Field("Encoders", "%s", report.FFmpeg.Error)
+		return nil
+	}
+	for _, family := range doctorEncoders {
+		encoders := report.FFmpeg.Encoders[family.Name]
+		if len(encoders) == 0 {
+			logger.Field(family.Name, "%s", i18n.Tr("not in this FFmpeg build"))
+			continue
+		}
+		logger.Field(family.Name, "%s", strings.Join(encoders, ", "))
+	}
+	return nil
+}
+
+// detectFFmpegVersion finds the FFmpeg installation and the encoders
+// compiled into it, without downloading or test encoding anything
+func detectFFmpegVersion() *ffmpegVersion {
+	info, err := util.FindFFmpeg()
+	if err != nil || !info.Available {
+		return nil
+	}
+
+	version := &ffmpegVersion{
+		Version:    info.Version,
+		Path:       info.Path,
+		Downloaded: info.IsDownloaded,
+		Encoders:   map[string][]string{},
+	}
+	if !info.ProbeMissing {
+		version.FFprobePath = info.FFprobePath
+	}
+
+	f := ffmpeg.NewFFmpeg("", "", nil, util.NewLogger(false))
+	f.Runner = ffmpeg.NewRunner(info.Path, info.FFprobePath)
+	available, err := f.Encoders()
+	if err != nil {
+		version.Error = err.Error()
+		return version
+	}
+	for _, family := range doctorEncoders {
+		for _, encoder := range family.Encoders {
+			if available[encoder] {
+				version.Encoders[family.Name] = append(version.Encoders[family.Name], encoder)
+			}
+		}
+	}
+	return version
+}
+
+// valueOrUnknown returns value, or "unknown" when it is empty
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return i18n.Tr("unknown")
+	}
+	return value
+}
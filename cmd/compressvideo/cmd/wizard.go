@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/spf13/cobra"
+)
+
+// profileName is the config file profile whose settings fill in the
+// options not given on the command line
+var profileName string
+
+// wizardCmd picks compression settings from plain-language answers
+var wizardCmd = &cobra.Command{
+	Use:   "wizard",
+	Short: "Choose compression settings by answering a few questions",
+	Long: `Ask what the video contains, where it will be played, how much quality
+loss is acceptable and how long the encode may take, then show the matching
+quality level, preset and codec.
+
+The settings can be saved as a named profile in ~/.compressvideo/config.yaml
+and used with 'compressvideo -i <file> --profile <name>'.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return wizardCommand(os.Stdin)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(wizardCmd)
+
+	rootCmd.Flags().StringVar(&profileName, "profile", "", "Use the quality, preset and codec of this profile from the config file (created by 'compressvideo wizard'); flags given on the command line take precedence")
+}
+
+// wizardQuestion is a multiple-choice question of the wizard
+type wizardQuestion struct {
+	prompt   string
+	choices  []string
+	fallback int // Choice used when the answer is left empty
+}
+
+// Answers to the wizard questions
+const (
+	contentCamera = iota
+	contentScreen
+	contentFastMotion
+	contentAnimation
+	contentUnknown
+)
+
+// wizardQuestions are asked in order: content, playback, quality loss and speed
+var wizardQuestions = []wizardQuestion{
+	{"What is in the video?", []string{
+		"Camera footage (family, events, travel)",
+		"Screen recording or tutorial",
+		"Gameplay or sports with fast motion",
+		"Animation or cartoons",
+		"Not sure",
+	}, contentUnknown},
+	{"Where will it be played?", []string{
+		"Anywhere, including older devices and chat apps",
+		"Modern phones, tablets and TVs",
+		"Web browsers",
+		"Nowhere in particular, it is an archive copy",
+	}, 0},
+	{"How much quality loss is acceptable?", []string{
+		"None that I can see",
+		"Barely noticeable",
+		"A little, for a much smaller file",
+		"Noticeable, but fine for sharing",
+		"Whatever it takes for the smallest file",
+	}, 2},
+	{"How long can the compression take?", []string{
+		"As short as possible",
+		"A balance of speed and file size",
+		"As long as needed for the smallest file",
+	}, 1},
+}
+
+// playbackCodecs is the codec for each playback answer: H.264 plays
+// everywhere, HEVC on recent devices and VP9 in every browser
+var playbackCodecs = []string{"h264", "hevc", "vp9", analyzer.CodecAuto}
+
+// wizardPresets is the preset for each speed answer
+var wizardPresets = []string{"fast", "balanced", "thorough"}
+
+// wizardProfile maps the answers, indexes into the choices of
+// wizardQuestions, to compression settings
+func wizardProfile(content, playback, loss, speed int) config.Profile {
+	profile := config.Profile{
+		Quality: 5 - loss,
+		Preset:  wizardPresets[speed],
+		Codec:   playbackCodecs[playback],
+	}
+	switch content {
+	case contentScreen:
+		profile.ScreencastROI = true
+	case contentFastMotion:
+		// Fast motion needs more bits to look the same
+		if profile.Quality < 5 {
+			profile.Quality++
+		}
+	}
+	return profile
+}
+
+// profileArgs returns the command line options equivalent to profile
+func profileArgs(profile config.Profile) string {
+	args := fmt.Sprintf("-q %d -p %s", profile.Quality, profile.Preset)
+	if profile.Codec != "" && profile.Codec != analyzer.CodecAuto {
+		args += " --codec " + profile.Codec
+	}
+	if profile.ScreencastROI {
+		args += " --screencast-roi"
+	}
+	return args
+}
+
+// wizardCommand asks the wizard questions on in and shows the settings,
+// saving them as a profile when the user names one
+func wizardCommand(in io.Reader) error {
+	if err := setupLogger(); err != nil {
+		return err
+	}
+	defer logger.Close()
+	logger.Title("CompressVideo - Settings Wizard")
+
+	reader := bufio.NewReader(in)
+	answers := make([]int, len(wizardQuestions))
+	for i, question := range wizardQuestions {
+		answer, err := askChoice(reader, question)
+		if err != nil {
+			return err
+		}
+		answers[i] = answer
+	}
+	profile := wizardProfile(answers[0], answers[1], answers[2], answers[3])
+
+	logger.Section("Recommended Settings")
+	logger.Field("Quality Level", "%d/5", profile.Quality)
+	logger.Field("Preset", "%s", profile.Preset)
+	logger.Field("Codec", "%s", profile.Codec)
+	if profile.ScreencastROI {
+		logger.Field("Screencast Tuning", "%s", i18n.Tr("on"))
+	}
+	logger.Field("Command", "compressvideo -i <file> %s", profileArgs(profile))
+
+	fmt.Printf("\n%s ", i18n.Tr("Save these settings as a profile? Enter a name, or leave empty to skip:"))
+	name, err := reader.ReadString('\n')
+	name = strings.TrimSpace(name)
+	if name == "" {
+		if err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	}
+
+	path := config.DefaultPath()
+	if err := config.SaveProfile(path, name, profile); err != nil {
+		return err
+	}
+	logger.Success("Saved profile %s to %s", name, path)
+	logger.Info("Use it with: compressvideo -i <file> --profile %s", name)
+	return nil
+}
+
+// askChoice prints question and reads the number of a choice, returning
+// its index. An empty answer picks the question's fallback.
+func askChoice(reader *bufio.Reader, question wizardQuestion) (int, error) {
+	fmt.Printf("\n%s\n", i18n.Tr(question.prompt))
+	for i, choice := range question.choices {
+		fmt.Printf("  %d) %s\n", i+1, i18n.Tr(choice))
+	}
+
+	for {
+		fmt.Printf("%s [%d]: ", i18n.Tr("Choice"), question.fallback+1)
+		line, err := reader.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if err != nil && err != io.EOF {
+				return 0, err
+			}
+			return question.fallback, nil
+		}
+
+		choice, convErr := strconv.Atoi(line)
+		if convErr == nil && choice >= 1 && choice <= len(question.choices) {
+			return choice - 1, nil
+		}
+		if err != nil {
+			return 0, i18n.Errorf("invalid choice: %s", line)
+		}
+		fmt.Printf("%s\n", i18n.T("Please enter a number from 1 to %d", len(question.choices)))
+	}
+}
+
+// applyProfile fills in the options of the --profile profile that were not
+// given on the command line
+func applyProfile(cmd *cobra.Command) error {
+	if profileName == "" {
+		return nil
+	}
+
+	path := config.DefaultPath()
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	profile, ok := cfg.Profiles[profileName]
+	if !ok {
+		return i18n.Errorf("profile %s not found in %s (create one with 'compressvideo wizard')", profileName, path)
+	}
+
+	flags := cmd.Flags()
+	if profile.Quality != 0 && !flags.Changed("quality") {
+		quality = profile.Quality
+	}
+	if profile.Preset != "" && !flags.Changed("preset") {
+		preset = profile.Preset
+	}
+	if profile.Codec != "" && !flags.Changed("codec") {
+		codec = profile.Codec
+	}
+	if profile.ScreencastROI && !flags.Changed("screencast-roi") {
+		screencastROI = true
+	}
+	logger.Debug("Using profile %s: %s", profileName, profileArgs(profile))
+	return nil
+}
@@ -1,9 +1,9 @@
 package main
 
 import (
-	"github.com/cccarv82/compressvideo/internal/cmd"
+	"github.com/cccarv82/compressvideo/cmd/compressvideo/cmd"
 )
 
 func main() {
 	cmd.Execute()
-} 
\ No newline at end of file
+}
@@ -0,0 +1,153 @@
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+const (
+	// bitrateHistogramBuckets is how many equal ranges between the lowest
+	// and highest bitrate of a second the histogram has
+	bitrateHistogramBuckets = 10
+
+	// spikyPeakRatio is how many times the median bitrate the 95th
+	// percentile reaches in sources whose bitrate comes in spikes, such as
+	// VBR encodes of content that alternates calm and busy scenes
+	spikyPeakRatio = 2.0
+
+	// maxSpikeHeadroom caps the extra bitrate given to spiky sources
+	maxSpikeHeadroom = 1.5
+)
+
+// BitrateStats describes how the video bitrate of a source varies from one
+// second to the next, measured from the sizes of its packets
+type BitrateStats struct {
+	Seconds   int             `json:"seconds"`   // Seconds of video measured
+	Mean      int64           `json:"mean"`      // Bits per second over the whole video
+	Min       int64           `json:"min"`       // Bits of the lightest second
+	P10       int64           `json:"p10"`       // Bits of a second at the 10th percentile
+	P50       int64           `json:"p50"`       // Median bits of a second
+	P90       int64           `json:"p90"`       // Bits of a second at the 90th percentile
+	P95       int64           `json:"p95"`       // Bits of a second at the 95th percentile
+	P99       int64           `json:"p99"`       // Bits of a second at the 99th percentile
+	Max       int64           `json:"max"`       // Bits of the busiest second
+	Histogram []BitrateBucket `json:"histogram"` // Seconds per bitrate range, lowest first
+}
+
+// BitrateBucket is a range of the bitrate histogram
+type BitrateBucket struct {
+	Low     int64 `json:"low"`     // Lowest bits per second of the range
+	High    int64 `json:"high"`    // Highest bits per second of the range
+	Seconds int   `json:"seconds"` // Seconds whose bitrate is in the range
+}
+
+// NewBitrateStats computes the statistics of the bitrate of each second of
+// a video, nil without any
+func NewBitrateStats(perSecond []int64) *BitrateStats {
+	if len(perSecond) == 0 {
+		return nil
+	}
+	sorted := append([]int64(nil), perSecond...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total int64
+	for _, bits := range sorted {
+		total += bits
+	}
+	stats := &BitrateStats{
+		Seconds: len(sorted),
+		Mean:    total / int64(len(sorted)),
+		Min:     sorted[0],
+		P10:     percentile(sorted, 10),
+		P50:     percentile(sorted, 50),
+		P90:     percentile(sorted, 90),
+		P95:     percentile(sorted, 95),
+		P99:     percentile(sorted, 99),
+		Max:     sorted[len(sorted)-1],
+	}
+
+	// Equal ranges from the lowest to the highest second, one for a steady
+	// bitrate
+	buckets := bitrateHistogramBuckets
+	if stats.Max == stats.Min {
+		buckets = 1
+	}
+	width := float64(stats.Max-stats.Min) / float64(buckets)
+	for i := 0; i < buckets; i++ {
+		stats.Histogram = append(stats.Histogram, BitrateBucket{
+			Low:  stats.Min + int64(math.Round(width*float64(i))),
+			High: stats.Min + int64(math.Round(width*float64(i+1))),
+		})
+	}
+	for _, bits := range sorted {
+		i := buckets - 1
+		if width > 0 {
+			i = int(math.Min(float64(bits-stats.Min)/width, float64(buckets-1)))
+		}
+		stats.Histogram[i].Seconds++
+	}
+	return stats
+}
+
+// percentile returns the nearest-rank percentile p of sorted values
+func percentile(sorted []int64, p float64) int64 {
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// PeakRatio returns how many times the median bitrate the 95th percentile
+// is, 1 for a constant bitrate
+func (s *BitrateStats) PeakRatio() float64 {
+	if s == nil || s.P50 <= 0 {
+		return 1
+	}
+	return float64(s.P95) / float64(s.P50)
+}
+
+// Spiky reports whether the bitrate of the source comes in spikes, its
+// busy seconds carrying at least twice the bits of a typical one
+func (s *BitrateStats) Spiky() bool {
+	return s.PeakRatio() >= spikyPeakRatio
+}
+
+// String summarizes the statistics, e.g. "mean 3.40 Mbps, median 3.20
+// Mbps, 90th percentile 5.90 Mbps, 95th percentile 7.10 Mbps, peak 12.40
+// Mbps over 600 s"
+func (s *BitrateStats) String() string {
+	return fmt.Sprintf("mean %s, median %s, 90th percentile %s, 95th percentile %s, peak %s over %d s",
+		util.FormatBitrate(s.Mean), util.FormatBitrate(s.P50), util.FormatBitrate(s.P90),
+		util.FormatBitrate(s.P95), util.FormatBitrate(s.Max), s.Seconds)
+}
+
+// fitToSource adjusts a bitrate, in bits per second, to how the bitrate of
+// the source varies. Spiky sources get up to half again as much, so their
+// busy scenes are not starved of the bits the average leaves them, and no
+// bitrate goes above the 95th percentile of the source, since more than
+// the source spends on its busy seconds only keeps its artifacts at a
+// larger size. It returns the bitrate and why it changed, empty when it
+// did not.
+func fitToSource(bitrate float64, stats *BitrateStats) (float64, string) {
+	if stats == nil {
+		return bitrate, ""
+	}
+	reason := ""
+	if stats.Spiky() {
+		headroom := math.Min(1+(stats.PeakRatio()-1)/4, maxSpikeHeadroom)
+		bitrate *= headroom
+		reason = fmt.Sprintf("x%.2f for a spiky source, its 95th percentile %.1f times its median", headroom, stats.PeakRatio())
+	}
+	if ceiling := float64(stats.P95); ceiling > 0 && bitrate > ceiling {
+		bitrate = ceiling
+		if reason != "" {
+			reason += ", "
+		}
+		reason += fmt.Sprintf("capped at the 95th percentile of the source, %s", util.FormatBitrate(stats.P95))
+	}
+	return bitrate, reason
+}
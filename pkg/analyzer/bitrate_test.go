@@ -0,0 +1,76 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBitrateStats(t *testing.T) {
+	assert.Nil(t, NewBitrateStats(nil))
+
+	// Nine calm seconds at 1 Mbps and a spike at 10 Mbps
+	perSecond := []int64{1000000, 1000000, 1000000, 1000000, 10000000, 1000000, 1000000, 1000000, 1000000, 1000000}
+	stats := NewBitrateStats(perSecond)
+	assert.Equal(t, 10, stats.Seconds)
+	assert.Equal(t, int64(1900000), stats.Mean)
+	assert.Equal(t, int64(1000000), stats.Min)
+	assert.Equal(t, int64(1000000), stats.P50)
+	assert.Equal(t, int64(1000000), stats.P90)
+	assert.Equal(t, int64(10000000), stats.P95)
+	assert.Equal(t, int64(10000000), stats.Max)
+	assert.Len(t, stats.Histogram, 10)
+	assert.Equal(t, BitrateBucket{Low: 1000000, High: 1900000, Seconds: 9}, stats.Histogram[0])
+	assert.Equal(t, BitrateBucket{Low: 9100000, High: 10000000, Seconds: 1}, stats.Histogram[9])
+	assert.True(t, stats.Spiky())
+	assert.InDelta(t, 10.0, stats.PeakRatio(), 0.001)
+
+	// A steady bitrate has a single range
+	stats = NewBitrateStats([]int64{2000000, 2000000, 2000000})
+	assert.Equal(t, []BitrateBucket{{Low: 2000000, High: 2000000, Seconds: 3}}, stats.Histogram)
+	assert.False(t, stats.Spiky())
+
+	var missing *BitrateStats
+	assert.False(t, missing.Spiky())
+}
+
+func TestFitToSource(t *testing.T) {
+	bitrate, reason := fitToSource(3000000, nil)
+	assert.Equal(t, 3000000.0, bitrate)
+	assert.Empty(t, reason)
+
+	// Steady sources are capped at their busy seconds
+	steady := &BitrateStats{P50: 2000000, P95: 2500000}
+	bitrate, reason = fitToSource(3000000, steady)
+	assert.Equal(t, 2500000.0, bitrate)
+	assert.Contains(t, reason, "capped at the 95th percentile")
+	bitrate, reason = fitToSource(1000000, steady)
+	assert.Equal(t, 1000000.0, bitrate)
+	assert.Empty(t, reason)
+
+	// Spiky sources get room for their spikes, at most half again as much
+	spiky := &BitrateStats{P50: 2000000, P95: 6000000}
+	bitrate, reason = fitToSource(2000000, spiky)
+	assert.Equal(t, 3000000.0, bitrate)
+	assert.Contains(t, reason, "x1.50 for a spiky source")
+	bitrate, _ = fitToSource(2000000, &BitrateStats{P50: 2000000, P95: 4000000})
+	assert.Equal(t, 2500000.0, bitrate)
+}
+
+func TestOptimalBitrateFollowsSource(t *testing.T) {
+	ca := &ContentAnalyzer{Explain: true}
+	analysis := &VideoAnalysis{
+		VideoFile:        &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 30}},
+		ContentType:      ContentTypeLiveAction,
+		MotionComplexity: MotionComplexityMedium,
+	}
+	assert.Equal(t, "4976k", ca.calculateOptimalBitrateString(analysis, 3))
+
+	// A source that never spends more than 3 Mbps gets no more
+	ca = &ContentAnalyzer{Explain: true}
+	analysis.Bitrate = &BitrateStats{P50: 2500000, P95: 3000000}
+	assert.Equal(t, "3000k", ca.calculateOptimalBitrateString(analysis, 3))
+	assert.Equal(t, "3000k", ca.Decisions()[0].Value)
+	assert.Contains(t, ca.Decisions()[0].Reason, "capped at the 95th percentile of the source, 3.00 Mbps")
+}
@@ -0,0 +1,17 @@
+package analyzer
+
+// CodecAuto leaves the choice of codec to the content analysis
+const CodecAuto = "auto"
+
+// codecEncoders maps the codecs a user can choose to their software encoders
+var codecEncoders = map[string]string{
+	"h264": "libx264",
+	"hevc": "libx265",
+	"vp9":  "libvpx-vp9",
+}
+
+// ValidCodec reports whether codec is auto or a codec users can choose
+func ValidCodec(codec string) bool {
+	_, ok := codecEncoders[codec]
+	return ok || codec == CodecAuto || codec == ""
+}
@@ -0,0 +1,50 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetCompressionSettingsCodec tests that a chosen codec replaces the one
+// picked for the content, with that codec's settings
+func TestGetCompressionSettingsCodec(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile:        &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1280, Height: 720, FPS: 30}},
+		ContentType:      ContentTypeLiveAction,
+		MotionComplexity: MotionComplexityMedium,
+	}
+	ca := NewContentAnalyzer(nil, nil)
+
+	settings, err := ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "libx264", settings["codec"])
+
+	ca.Codec = "vp9"
+	settings, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "libvpx-vp9", settings["codec"])
+	assert.Equal(t, "0", settings["bitrate"]) // Constant quality
+
+	ca.Codec = "hevc"
+	settings, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "libx265", settings["codec"])
+	assert.Equal(t, "main", settings["profile"])
+
+	ca.Codec = CodecAuto
+	settings, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "libx264", settings["codec"])
+}
+
+// TestValidCodec tests the codecs users can choose
+func TestValidCodec(t *testing.T) {
+	for _, codec := range []string{"", "auto", "h264", "hevc", "vp9"} {
+		assert.True(t, ValidCodec(codec), codec)
+	}
+	for _, codec := range []string{"av1", "libx264", "H264"} {
+		assert.False(t, ValidCodec(codec), codec)
+	}
+}
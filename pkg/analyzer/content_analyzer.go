@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
 	"github.com/cccarv82/compressvideo/pkg/util"
@@ -51,6 +52,24 @@ func (c ContentType) String() string {
 	}
 }
 
+// Name returns the content type as config files write it: lower case with
+// underscores, e.g. live_action
+func (c ContentType) Name() string {
+	return strings.ReplaceAll(strings.ToLower(c.String()), " ", "_")
+}
+
+// ParseContentType returns the content type of a name such as screencast or
+// live_action. Case, spaces and hyphens do not matter.
+func ParseContentType(name string) (ContentType, bool) {
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(strings.TrimSpace(name)))
+	for c := ContentTypeUnknown; c <= ContentTypeDocumentary; c++ {
+		if c.Name() == name {
+			return c, true
+		}
+	}
+	return ContentTypeUnknown, false
+}
+
 // MotionComplexity represents the level of motion complexity in a video
 type MotionComplexity int
 
@@ -87,6 +106,7 @@ type VideoAnalysis struct {
 	ContentType     ContentType        // Detected content type
 	MotionComplexity MotionComplexity  // Motion complexity level
 	SceneChanges    int                // Number of scene changes
+	SceneTimes      []float64          // Times of the scene changes in seconds
 	FrameComplexity float64            // Average frame complexity
 	CompressionPotential int           // Estimated compression potential (%)
 	RecommendedCodec string            // Recommended codec for compression
@@ -94,12 +114,20 @@ type VideoAnalysis struct {
 	SpatialComplexity float64          // Spatial complexity (detail level)
 	IsHDContent     bool               // Whether the content is HD (720p+)
 	IsUHDContent    bool               // Whether the content is UHD (4K+)
+	Bitrate         *BitrateStats      // Video bitrate of each second of the source, nil when its packets could not be read
+	Params          AnalysisParams     // Tunables the analysis was made with
 }
 
 // ContentAnalyzer analyzes video content to determine optimal compression settings
 type ContentAnalyzer struct {
 	FFmpeg *ffmpeg.FFmpeg
 	Logger *util.Logger
+	Codec  string // Codec chosen by the user (h264, hevc, vp9), empty or auto to pick one for the content
+	Params AnalysisParams // Scene threshold, complexity sampling and motion cutoffs
+	Explain bool          // Record why each setting was chosen, see Decisions
+
+	decisionsMu sync.Mutex
+	decisions   []Decision
 }
 
 // NewContentAnalyzer creates a new content analyzer
@@ -107,15 +135,26 @@ func NewContentAnalyzer(ffmpeg *ffmpeg.FFmpeg, logger *util.Logger) *ContentAnal
 	return &ContentAnalyzer{
 		FFmpeg: ffmpeg,
 		Logger: logger,
+		Params: DefaultAnalysisParams(),
 	}
 }
 
+// params returns the analyzer's tunables, the defaults when none were set
+func (ca *ContentAnalyzer) params() AnalysisParams {
+	if ca.Params == (AnalysisParams{}) {
+		return DefaultAnalysisParams()
+	}
+	return ca.Params
+}
+
 // AnalyzeVideo performs comprehensive analysis of a video file
 func (ca *ContentAnalyzer) AnalyzeVideo(videoFile *ffmpeg.VideoFile) (*VideoAnalysis, error) {
 	ca.Logger.Info("Analyzing video content: %s", filepath.Base(videoFile.Path))
 	
+	params := ca.params()
 	analysis := &VideoAnalysis{
 		VideoFile: videoFile,
+		Params:    params,
 	}
 	
 	// Detect content type based on filename, format, and video properties
@@ -123,28 +162,27 @@ func (ca *ContentAnalyzer) AnalyzeVideo(videoFile *ffmpeg.VideoFile) (*VideoAnal
 	ca.Logger.Info("Detected content type: %s", analysis.ContentType)
 	
 	// Analyze scene changes to determine content complexity
-	sceneChanges, err := ca.FFmpeg.DetectSceneChanges(videoFile.Path, 0.3)
+	sceneChanges, err := ca.FFmpeg.DetectSceneChanges(videoFile.Path, params.SceneThreshold)
 	if err != nil {
 		ca.Logger.Error("Failed to detect scene changes: %v", err)
 		// Continue with analysis, as this is not critical
 	} else {
 		analysis.SceneChanges = len(sceneChanges)
+		analysis.SceneTimes = sceneChanges
+		
+		// Remote inputs are only sampled; extrapolate to the whole video
+		if ffmpeg.IsRemote(videoFile.Path) && videoFile.Duration > ffmpeg.RemoteSampleSeconds {
+			analysis.SceneChanges = int(float64(analysis.SceneChanges) * videoFile.Duration / ffmpeg.RemoteSampleSeconds)
+		}
 		ca.Logger.Debug("Detected %d scene changes", analysis.SceneChanges)
 	}
 	
 	// Calculate frame complexity
-	frameComplexity, err := ca.FFmpeg.CalculateFrameComplexity(videoFile.Path)
+	frameComplexity, err := ca.FFmpeg.CalculateFrameComplexity(videoFile.Path, params.ComplexityInterval)
 	if err != nil {
 		ca.Logger.Error("Failed to calculate frame complexity: %v", err)
 		// Use a default value based on content type
-		switch analysis.ContentType {
-		case ContentTypeScreencast:
-			frameComplexity = 100
-		case ContentTypeAnimation:
-			frameComplexity = 200
-		default:
-			frameComplexity = 500
-		}
+		frameComplexity = defaultFrameComplexity(analysis.ContentType)
 	}
 	analysis.FrameComplexity = frameComplexity
 	
@@ -163,6 +201,14 @@ func (ca *ContentAnalyzer) AnalyzeVideo(videoFile *ffmpeg.VideoFile) (*VideoAnal
 	analysis.RecommendedCodec = ca.determineOptimalCodec(videoFile, analysis.ContentType)
 	ca.Logger.Info("Recommended codec: %s", analysis.RecommendedCodec)
 	
+	// Measure how the bitrate of the source varies, for spiky and VBR sources
+	if bitrates, err := ca.FFmpeg.VideoBitrates(videoFile.Path); err != nil {
+		ca.Logger.Warning("Failed to measure the bitrate of each second: %v", err)
+	} else {
+		analysis.Bitrate = NewBitrateStats(bitrates)
+		ca.Logger.Info("Source bitrate: %s", analysis.Bitrate)
+	}
+	
 	// Calculate optimal bitrate
 	analysis.OptimalBitrate = ca.calculateOptimalBitrate(videoFile, analysis)
 	ca.Logger.Info("Optimal bitrate: %d kbps", analysis.OptimalBitrate/1000)
@@ -179,51 +225,52 @@ func (ca *ContentAnalyzer) detectContentType(videoFile *ffmpeg.VideoFile) Conten
 	filename := strings.ToLower(filepath.Base(videoFile.Path))
 	
 	// Check for common indicators in filename
-	if containsAny(filename, []string{"screencast", "screen", "capture", "tutorial", "recording", "desktop", "presentation"}) {
-		return ContentTypeScreencast
-	}
-	
-	if containsAny(filename, []string{"anime", "animation", "cartoon", "animated", "3d", "cgi"}) {
-		return ContentTypeAnimation
-	}
-	
-	if containsAny(filename, []string{"game", "gaming", "gameplay", "playthrough", "walkthrough", "let's play"}) {
-		return ContentTypeGaming
-	}
-	
-	if containsAny(filename, []string{"sports", "football", "soccer", "basketball", "hockey", "match", "race"}) {
-		return ContentTypeSportsAction
-	}
-	
-	if containsAny(filename, []string{"documentary", "nature", "wildlife", "science", "history"}) {
-		return ContentTypeDocumentary
+	for _, indicator := range []struct {
+		contentType ContentType
+		keywords    []string
+	}{
+		{ContentTypeScreencast, []string{"screencast", "screen", "capture", "tutorial", "recording", "desktop", "presentation"}},
+		{ContentTypeAnimation, []string{"anime", "animation", "cartoon", "animated", "3d", "cgi"}},
+		{ContentTypeGaming, []string{"game", "gaming", "gameplay", "playthrough", "walkthrough", "let's play"}},
+		{ContentTypeSportsAction, []string{"sports", "football", "soccer", "basketball", "hockey", "match", "race"}},
+		{ContentTypeDocumentary, []string{"documentary", "nature", "wildlife", "science", "history"}},
+	} {
+		if keyword := matchingKeyword(filename, indicator.keywords); keyword != "" {
+			ca.explain("content_type", indicator.contentType.String(), "", "the file name contains %q", keyword)
+			return indicator.contentType
+		}
 	}
 	
 	// If no match in filename, analyze video properties
+	info := videoFile.VideoInfo
 	
 	// Screencasts typically have very steady frame rates and limited color palettes
-	if videoFile.VideoInfo.FPS <= 30 && 
-	   (videoFile.VideoInfo.Width == 1920 || videoFile.VideoInfo.Width == 1280 || 
-		videoFile.VideoInfo.Width == 1366 || videoFile.VideoInfo.Width == 1440) {
+	if info.FPS <= 30 && 
+	   (info.Width == 1920 || info.Width == 1280 || 
+		info.Width == 1366 || info.Width == 1440) {
 		// Common screen resolutions for screencasts
+		ca.explain("content_type", ContentTypeScreencast.String(), "", "%g fps <= 30 at the screen width %d", info.FPS, info.Width)
 		return ContentTypeScreencast
 	}
 	
 	// Animation often has specific aspect ratios and frame rates
-	if videoFile.VideoInfo.FPS < 30 && 
-	   (videoFile.VideoInfo.Width == 1920 || videoFile.VideoInfo.Width == 1280) &&
-	   !videoFile.VideoInfo.IsHDR {
+	if info.FPS < 30 && 
+	   (info.Width == 1920 || info.Width == 1280) &&
+	   !info.IsHDR {
+		ca.explain("content_type", ContentTypeAnimation.String(), "", "%g fps < 30 at width %d without HDR", info.FPS, info.Width)
 		return ContentTypeAnimation
 	}
 	
 	// Gaming content often has specific frame rates and resolutions
-	if (videoFile.VideoInfo.FPS == 30 || videoFile.VideoInfo.FPS == 60) && 
-	   (videoFile.VideoInfo.Width == 1920 || videoFile.VideoInfo.Width == 2560 || 
-		videoFile.VideoInfo.Width == 3840) {
+	if (info.FPS == 30 || info.FPS == 60) && 
+	   (info.Width == 1920 || info.Width == 2560 || 
+		info.Width == 3840) {
+		ca.explain("content_type", ContentTypeGaming.String(), "", "%g fps at the gaming width %d", info.FPS, info.Width)
 		return ContentTypeGaming
 	}
 	
 	// Default to live action for real-world content
+	ca.explain("content_type", ContentTypeLiveAction.String(), "", "neither the file name nor the frame rate and width point to other content")
 	return ContentTypeLiveAction
 }
 
@@ -237,15 +284,19 @@ func (ca *ContentAnalyzer) determineMotionComplexity(videoFile *ffmpeg.VideoFile
 	sceneChangesPerMinute := float64(sceneChanges) / durationMinutes
 	
 	// Determine based on scene changes and frame complexity
-	if sceneChangesPerMinute < 2 && frameComplexity < 200 {
-		return MotionComplexityLow
-	} else if sceneChangesPerMinute < 5 && frameComplexity < 500 {
-		return MotionComplexityMedium
-	} else if sceneChangesPerMinute < 10 && frameComplexity < 1000 {
-		return MotionComplexityHigh
-	} else {
-		return MotionComplexityVeryHigh
+	levels := []MotionComplexity{MotionComplexityLow, MotionComplexityMedium, MotionComplexityHigh}
+	cutoffs := ca.params().MotionCutoffs
+	for i, cutoff := range cutoffs {
+		if sceneChangesPerMinute < cutoff.ScenesPerMinute && frameComplexity < cutoff.FrameComplexity {
+			ca.explain("motion", levels[i].String(), "--motion-cutoffs", "%.1f scene changes/min < %g and frame complexity %.0f < %g",
+				sceneChangesPerMinute, cutoff.ScenesPerMinute, frameComplexity, cutoff.FrameComplexity)
+			return levels[i]
+		}
 	}
+	last := cutoffs[len(cutoffs)-1]
+	ca.explain("motion", MotionComplexityVeryHigh.String(), "--motion-cutoffs", "%.1f scene changes/min >= %g or frame complexity %.0f >= %g",
+		sceneChangesPerMinute, last.ScenesPerMinute, frameComplexity, last.FrameComplexity)
+	return MotionComplexityVeryHigh
 }
 
 // calculateSpatialComplexity determines the level of detail in the video frames
@@ -278,32 +329,36 @@ func (ca *ContentAnalyzer) determineOptimalCodec(videoFile *ffmpeg.VideoFile, co
 	
 	// If content is HDR, must use a codec that supports it
 	if videoFile.VideoInfo.IsHDR {
+		ca.explain("recommended_codec", "hevc", "", "the video is HDR")
 		return "hevc" // H.265 has better HDR support
 	}
 	
+	height := videoFile.VideoInfo.Height
+	
 	// Select codec based on content type
 	switch contentType {
 	case ContentTypeAnimation:
+		ca.explain("recommended_codec", "vp9", "", "content=%s", contentType)
 		return "vp9" // VP9 is often better for animation
 	case ContentTypeScreencast:
+		ca.explain("recommended_codec", "hevc", "", "content=%s", contentType)
 		return "hevc" // H.265 works well for screencast content
-	case ContentTypeGaming:
-		// Gaming benefits from superior texture preservation
-		if videoFile.VideoInfo.Height >= 1080 {
-			return "hevc"
-		}
-		return "h264"
-	case ContentTypeSportsAction:
-		// Sports needs good motion handling
-		if videoFile.VideoInfo.Height >= 1080 {
+	case ContentTypeGaming, ContentTypeSportsAction:
+		// Gaming benefits from superior texture preservation, sports needs
+		// good motion handling
+		if height >= 1080 {
+			ca.explain("recommended_codec", "hevc", "", "height %d >= 1080 and content=%s", height, contentType)
 			return "hevc"
 		}
+		ca.explain("recommended_codec", "h264", "", "height %d < 1080 and content=%s", height, contentType)
 		return "h264"
 	default:
 		// For general live action, H.264 has best compatibility
-		if videoFile.VideoInfo.Height >= 1440 {
+		if height >= 1440 {
+			ca.explain("recommended_codec", "hevc", "", "height %d >= 1440 and content=%s", height, contentType)
 			return "hevc"
 		}
+		ca.explain("recommended_codec", "h264", "", "height %d < 1440 and content=%s, for compatibility", height, contentType)
 		return "h264"
 	}
 }
@@ -374,13 +429,29 @@ func (ca *ContentAnalyzer) calculateOptimalBitrate(videoFile *ffmpeg.VideoFile,
 		adjustedBitrate = maxBitrate
 	}
 	
+	// Follow the spikes of the source, without going above its busy seconds
+	adjustedBitrate, _ = fitToSource(adjustedBitrate, analysis.Bitrate)
+	
 	return int64(adjustedBitrate)
 }
 
 // estimateCompressionPotential estimates the potential compression percentage
 func (ca *ContentAnalyzer) estimateCompressionPotential(videoFile *ffmpeg.VideoFile, analysis *VideoAnalysis) int {
+	// Sources whose streams carry no bitrate have it measured from packets,
+	// or taken from the container without the audio
+	sourceBitrate := videoFile.VideoInfo.BitRate
+	if sourceBitrate <= 0 && analysis.Bitrate != nil {
+		sourceBitrate = analysis.Bitrate.Mean
+	}
+	if sourceBitrate <= 0 {
+		sourceBitrate = videoFile.BitRate
+		for _, audio := range videoFile.AudioInfo {
+			sourceBitrate -= audio.BitRate
+		}
+	}
+	
 	// If we can't determine bitrate, make an estimate based on other factors
-	if videoFile.VideoInfo.BitRate <= 0 {
+	if sourceBitrate <= 0 {
 		// Estimate based on content type and resolution
 		if analysis.IsUHDContent { // 4K
 			return 50
@@ -391,7 +462,7 @@ func (ca *ContentAnalyzer) estimateCompressionPotential(videoFile *ffmpeg.VideoF
 	}
 	
 	// Calculate based on optimal bitrate vs current bitrate
-	compressionRatio := float64(videoFile.VideoInfo.BitRate) / float64(analysis.OptimalBitrate)
+	compressionRatio := float64(sourceBitrate) / float64(analysis.OptimalBitrate)
 	
 	// Convert to percentage potential
 	potential := int((1.0 - (1.0 / compressionRatio)) * 100)
@@ -408,12 +479,7 @@ func (ca *ContentAnalyzer) estimateCompressionPotential(videoFile *ffmpeg.VideoF
 
 // containsAny checks if a string contains any of the given substrings
 func containsAny(s string, substrings []string) bool {
-	for _, sub := range substrings {
-		if strings.Contains(s, sub) {
-			return true
-		}
-	}
-	return false
+	return matchingKeyword(s, substrings) != ""
 }
 
 // GetCompressionSettings calculates the optimal compression settings based on video analysis
@@ -427,6 +493,12 @@ func (ca *ContentAnalyzer) GetCompressionSettings(analysis *VideoAnalysis, quali
 	
 	// Select codec based on content type
 	settings["codec"] = ca.selectCodec(analysis.ContentType)
+	if encoder, ok := codecEncoders[ca.Codec]; ok {
+		settings["codec"] = encoder
+		ca.explain("codec", encoder, "--codec", "--codec %s was given", ca.Codec)
+	} else {
+		ca.explain("codec", settings["codec"], "--codec", "content=%s", analysis.ContentType)
+	}
 	
 	// Calculate optimal quality (CRF) value based on quality level and content type
 	settings["crf"] = ca.calculateCRF(analysis.ContentType, analysis.MotionComplexity, qualityLevel)
@@ -437,12 +509,22 @@ func (ca *ContentAnalyzer) GetCompressionSettings(analysis *VideoAnalysis, quali
 	// Add codec-specific settings
 	ca.addCodecSpecificSettings(settings, analysis)
 	
+	// Place keyframes at a steady cadence for seeking and streaming
+	ca.setKeyframeInterval(settings, analysis)
+	
 	// Calculate optimal bitrate if needed
 	optimalBitrateStr := ca.calculateOptimalBitrateString(analysis, qualityLevel)
 	if optimalBitrateStr != "" {
 		settings["bitrate"] = optimalBitrateStr
 	}
 	
+	// VP9 runs in constant quality mode on its own CRF scale
+	if settings["codec"] == "libvpx-vp9" {
+		addVP9Settings(settings, analysis)
+		ca.amend("crf", settings["crf"], "%+d for the VP9 scale, clamped to 15-50", VP9CRFOffset)
+		ca.amend("bitrate", settings["bitrate"], "0 puts VP9 in constant quality mode")
+	}
+	
 	// Audio settings
 	ca.setAudioSettings(settings, analysis)
 	
@@ -452,10 +534,13 @@ func (ca *ContentAnalyzer) GetCompressionSettings(analysis *VideoAnalysis, quali
 // selectCodec chooses the most appropriate codec for the content type
 func (ca *ContentAnalyzer) selectCodec(contentType ContentType) string {
 	switch contentType {
-	case ContentTypeScreencast, ContentTypeAnimation:
-		// Screencasts and animations tend to have large flat areas and sharp edges
+	case ContentTypeScreencast:
+		// Screencasts tend to have large flat areas and sharp edges
 		// HEVC/H.265 is more efficient for this type of content
 		return "libx265"
+	case ContentTypeAnimation:
+		// VP9 handles the flat colors and hard edges of animation best
+		return "libvpx-vp9"
 	case ContentTypeGaming:
 		// Gaming has a mix of complex motion and UI elements
 		// H.264 offers a good balance of compatibility and efficiency
@@ -471,6 +556,7 @@ func (ca *ContentAnalyzer) calculateCRF(contentType ContentType, motionComplexit
 	// Base CRF values per codec and content type
 	// Lower CRF = Higher quality
 	baseCRF := 23 // Default for H.264
+	var reason strings.Builder
 	
 	// Adjust for content type
 	switch contentType {
@@ -483,12 +569,15 @@ func (ca *ContentAnalyzer) calculateCRF(contentType ContentType, motionComplexit
 	case ContentTypeLiveAction, ContentTypeDocumentary:
 		baseCRF = 20 // Natural content needs lower CRF (higher quality)
 	}
+	fmt.Fprintf(&reason, "base %d (content=%s)", baseCRF, contentType)
 	
 	// Adjust for motion complexity
 	if motionComplexity == MotionComplexityHigh {
 		baseCRF -= 2 // Higher motion needs better quality
+		reason.WriteString(" + motion High (-2)")
 	} else if motionComplexity == MotionComplexityLow {
 		baseCRF += 2 // Lower motion can use higher CRF
+		reason.WriteString(" + motion Low (+2)")
 	}
 	
 	// Adjust for quality level (1-5)
@@ -497,13 +586,17 @@ func (ca *ContentAnalyzer) calculateCRF(contentType ContentType, motionComplexit
 	qualityAdjustment := (3 - qualityLevel) * 2
 	
 	finalCRF := baseCRF + qualityAdjustment
+	fmt.Fprintf(&reason, " + quality %d (%+d)", qualityLevel, qualityAdjustment)
 	
 	// Ensure CRF is within valid range
 	if finalCRF < 18 {
 		finalCRF = 18
+		reason.WriteString(", raised to the minimum 18")
 	} else if finalCRF > 32 {
 		finalCRF = 32
+		reason.WriteString(", lowered to the maximum 32")
 	}
+	ca.explain("crf", strconv.Itoa(finalCRF), "-q or --target-vmaf", "%s", reason.String())
 	
 	return strconv.Itoa(finalCRF)
 }
@@ -514,6 +607,17 @@ func (ca *ContentAnalyzer) selectPreset(qualityLevel int, motionComplexity Motio
 	
 	// Base preset based on quality level
 	// Higher quality levels use slower presets for better compression
+	preset := ca.basePreset(qualityLevel, motionComplexity)
+	if qualityLevel == 1 && motionComplexity == MotionComplexityHigh {
+		ca.explain("preset", preset, "-q or --preset", "quality %d and motion %s", qualityLevel, motionComplexity)
+	} else {
+		ca.explain("preset", preset, "-q or --preset", "quality %d", qualityLevel)
+	}
+	return preset
+}
+
+// basePreset returns the FFmpeg preset for a quality level and motion complexity
+func (ca *ContentAnalyzer) basePreset(qualityLevel int, motionComplexity MotionComplexity) string {
 	switch qualityLevel {
 	case 1: // Maximum compression
 		if motionComplexity == MotionComplexityHigh {
@@ -557,10 +661,8 @@ func (ca *ContentAnalyzer) addCodecSpecificSettings(settings map[string]string,
 		// Set appropriate profile
 		if analysis.ContentType == ContentTypeGaming || analysis.ContentType == ContentTypeLiveAction {
 			settings["profile"] = "high"
-			settings["level"] = "4.1"
 		} else {
 			settings["profile"] = "main"
-			settings["level"] = "3.1"
 		}
 		
 		// For film content, use film tuning
@@ -569,6 +671,15 @@ func (ca *ContentAnalyzer) addCodecSpecificSettings(settings map[string]string,
 		}
 	}
 	
+	// Signal the lowest level that holds the frame size and rate, which
+	// players check before they decode
+	if codec == "libx264" || codec == "libx265" {
+		videoInfo := analysis.VideoFile.VideoInfo
+		level, _ := CodecLevel(codec, settings["profile"], videoInfo.Width, videoInfo.Height, videoInfo.FPS, 0)
+		settings["level"] = level
+		ca.explain("level", level, "", "lowest level for %dx%d at %.3g fps", videoInfo.Width, videoInfo.Height, videoInfo.FPS)
+	}
+	
 	// Set appropriate pixel format
 	settings["pix_fmt"] = "yuv420p" // Most compatible format
 }
@@ -621,7 +732,17 @@ func (ca *ContentAnalyzer) calculateOptimalBitrateString(analysis *VideoAnalysis
 	
 	// Ensure bitrate is not below minimum
 	if bitrate < minBitrate {
+		ca.explain("bitrate", fmt.Sprintf("%dk", minBitrate), "", "%dk is the minimum for %dx%d", minBitrate, width, height)
 		bitrate = minBitrate
+	} else {
+		ca.explain("bitrate", fmt.Sprintf("%dk", bitrate), "", "%.2f megapixels x %.0fk per megapixel (content=%s, motion %s) x %.1f for quality %d",
+			pixelsPerFrame, baseBitrateFactor, analysis.ContentType, analysis.MotionComplexity, qualityMultiplier, qualityLevel)
+	}
+	
+	// Follow the spikes of the source, without going above its busy seconds
+	if fitted, reason := fitToSource(float64(bitrate)*1000, analysis.Bitrate); reason != "" {
+		bitrate = int(fitted / 1000)
+		ca.amend("bitrate", fmt.Sprintf("%dk", bitrate), "%s", reason)
 	}
 	
 	// Return bitrate in Kbps
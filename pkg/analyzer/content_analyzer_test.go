@@ -383,7 +383,7 @@ func Test_SelectCodec(t *testing.T) {
 		{
 			name:        "Animation content",
 			contentType: ContentTypeAnimation,
-			expectCodec: "libx265",
+			expectCodec: "libvpx-vp9",
 		},
 		{
 			name:        "Gaming content",
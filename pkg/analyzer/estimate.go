@@ -0,0 +1,155 @@
+package analyzer
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+const (
+	// containerOverhead is the fraction added to the stream sizes for muxing overhead
+	containerOverhead = 0.01
+	// referencePixels is the frame size the encoder speed table was measured at (1080p)
+	referencePixels = 1920 * 1080
+	// referenceCPUs is the core count of the machine the speed table was measured on
+	referenceCPUs = 8
+	// streamCopySpeed is how many times faster than real time a copied video
+	// stream is remuxed while the audio is re-encoded
+	streamCopySpeed = 50
+)
+
+// x264PresetFPS is the approximate libx264 encode speed in frames per second
+// for 1080p content on an 8-core desktop CPU
+var x264PresetFPS = map[string]float64{
+	"ultrafast": 320,
+	"superfast": 230,
+	"veryfast":  170,
+	"faster":    120,
+	"fast":      90,
+	"medium":    65,
+	"slow":      35,
+	"slower":    16,
+	"veryslow":  7,
+	"placebo":   2,
+}
+
+// codecSpeedFactor is the encode speed of each codec relative to libx264
+// at the same preset
+var codecSpeedFactor = map[string]float64{
+	"libx264":    1.0,
+	"libx265":    0.25,
+	"libvpx-vp9": 0.2,
+	"h264_nvenc": 4.0,
+	"hevc_nvenc": 3.5,
+	"h264_qsv":   3.0,
+	"hevc_qsv":   2.5,
+	"h264_amf":   3.0,
+	"hevc_amf":   2.5,
+}
+
+// EstimateOutputSize predicts the size in bytes of the compressed file from
+// the target video bitrate, the audio settings and the video duration. audio
+// holds the streams that reach the output, see compressor.MappedAudio.
+func EstimateOutputSize(analysis *VideoAnalysis, settings map[string]string, audio []ffmpeg.AudioStreamInfo) int64 {
+	if analysis == nil || analysis.VideoFile == nil || analysis.VideoFile.Duration <= 0 {
+		return 0
+	}
+	videoFile := analysis.VideoFile
+
+	videoBitrate := ParseBitrate(settings["bitrate"])
+	if settings["codec"] == "copy" && videoFile.VideoInfo.BitRate > 0 {
+		// A copied stream keeps its size
+		videoBitrate = videoFile.VideoInfo.BitRate
+	}
+	if videoBitrate == 0 {
+		videoBitrate = analysis.OptimalBitrate
+	}
+
+	var audioBitrate int64
+	switch settings["audio_codec"] {
+	case "":
+		// No audio settings means no audio stream
+	case "copy":
+		for _, stream := range audio {
+			audioBitrate += stream.BitRate
+		}
+	default:
+		audioBitrate = ParseBitrate(settings["audio_bitrate"]) * int64(len(audio))
+	}
+
+	bytes := float64(videoBitrate+audioBitrate) / 8 * videoFile.Duration
+	return int64(bytes * (1 + containerOverhead))
+}
+
+// ParseBitrate converts an FFmpeg bitrate string such as "2500k" or "4M" to bits per second
+func ParseBitrate(value string) int64 {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	multiplier := 1.0
+	switch strings.ToLower(value[len(value)-1:]) {
+	case "k":
+		multiplier = 1000
+		value = value[:len(value)-1]
+	case "m":
+		multiplier = 1000000
+		value = value[:len(value)-1]
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0
+	}
+	return int64(number * multiplier)
+}
+
+// EstimateEncodeTime predicts how long a single FFmpeg process needs to encode
+// the video with the given settings, based on preset speed benchmarks scaled
+// by resolution and the number of CPU cores
+func EstimateEncodeTime(analysis *VideoAnalysis, settings map[string]string) time.Duration {
+	if analysis == nil || analysis.VideoFile == nil || analysis.VideoFile.Duration <= 0 {
+		return 0
+	}
+	videoInfo := analysis.VideoFile.VideoInfo
+
+	if settings["codec"] == "copy" {
+		return time.Duration(analysis.VideoFile.Duration / streamCopySpeed * float64(time.Second))
+	}
+
+	fps := videoInfo.FPS
+	if fps <= 0 {
+		fps = 30
+	}
+	totalFrames := analysis.VideoFile.Duration * fps
+
+	encodeFPS, ok := x264PresetFPS[settings["preset"]]
+	if !ok {
+		encodeFPS = x264PresetFPS["medium"]
+	}
+	if factor, ok := codecSpeedFactor[settings["codec"]]; ok {
+		encodeFPS *= factor
+	}
+
+	// Encode speed is roughly inversely proportional to the pixel count
+	pixels := float64(videoInfo.Width * videoInfo.Height)
+	if pixels > 0 {
+		encodeFPS *= referencePixels / pixels
+	}
+
+	// Software encoders scale with the available cores, but not perfectly
+	cpuFactor := float64(runtime.NumCPU()) / referenceCPUs
+	if cpuFactor < 0.25 {
+		cpuFactor = 0.25
+	} else if cpuFactor > 2 {
+		cpuFactor = 2
+	}
+	encodeFPS *= cpuFactor
+
+	seconds := totalFrames / encodeFPS
+	return time.Duration(seconds * float64(time.Second))
+}
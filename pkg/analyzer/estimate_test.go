@@ -0,0 +1,92 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBitrate(t *testing.T) {
+	assert.Equal(t, int64(2500000), ParseBitrate("2500k"))
+	assert.Equal(t, int64(4000000), ParseBitrate("4M"))
+	assert.Equal(t, int64(1500000), ParseBitrate("1.5m"))
+	assert.Equal(t, int64(128000), ParseBitrate("128000"))
+	assert.Equal(t, int64(0), ParseBitrate(""))
+	assert.Equal(t, int64(0), ParseBitrate("abc"))
+}
+
+func TestEstimateOutputSize(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile: &ffmpeg.VideoFile{
+			Duration: 100,
+			AudioInfo: []ffmpeg.AudioStreamInfo{
+				{Codec: "aac", BitRate: 160000},
+			},
+		},
+		OptimalBitrate: 1000000,
+	}
+
+	// Video bitrate from settings, audio copied from the source
+	settings := map[string]string{"bitrate": "2000k", "audio_codec": "copy"}
+	expected := int64(float64(2000000+160000) / 8 * 100 * 1.01)
+	assert.Equal(t, expected, EstimateOutputSize(analysis, settings, analysis.VideoFile.AudioInfo))
+
+	// Falls back to the analysis bitrate and uses the re-encoded audio bitrate
+	settings = map[string]string{"audio_codec": "aac", "audio_bitrate": "128k"}
+	expected = int64(float64(1000000+128000) / 8 * 100 * 1.01)
+	assert.Equal(t, expected, EstimateOutputSize(analysis, settings, analysis.VideoFile.AudioInfo))
+
+	// A copied video stream keeps the source bitrate
+	analysis.VideoFile.VideoInfo.BitRate = 5000000
+	settings = map[string]string{"codec": "copy", "audio_codec": "aac", "audio_bitrate": "128k"}
+	expected = int64(float64(5000000+128000) / 8 * 100 * 1.01)
+	assert.Equal(t, expected, EstimateOutputSize(analysis, settings, analysis.VideoFile.AudioInfo))
+
+	// Only the audio streams that reach the output count
+	commentary := ffmpeg.AudioStreamInfo{Codec: "aac", BitRate: 96000}
+	analysis.VideoFile.AudioInfo = append(analysis.VideoFile.AudioInfo, commentary)
+	expected = int64(float64(5000000+2*128000) / 8 * 100 * 1.01)
+	assert.Equal(t, expected, EstimateOutputSize(analysis, settings, analysis.VideoFile.AudioInfo))
+	settings["audio_codec"] = "copy"
+	expected = int64(float64(5000000+96000) / 8 * 100 * 1.01)
+	assert.Equal(t, expected, EstimateOutputSize(analysis, settings, []ffmpeg.AudioStreamInfo{commentary}))
+
+	// Unknown duration cannot be estimated
+	analysis.VideoFile.Duration = 0
+	assert.Equal(t, int64(0), EstimateOutputSize(analysis, settings, analysis.VideoFile.AudioInfo))
+}
+
+func TestEstimateEncodeTime(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile: &ffmpeg.VideoFile{
+			Duration: 600,
+			VideoInfo: ffmpeg.VideoStreamInfo{
+				Width:  1920,
+				Height: 1080,
+				FPS:    30,
+			},
+		},
+	}
+
+	fast := EstimateEncodeTime(analysis, map[string]string{"codec": "libx264", "preset": "veryfast"})
+	slow := EstimateEncodeTime(analysis, map[string]string{"codec": "libx264", "preset": "veryslow"})
+	hevc := EstimateEncodeTime(analysis, map[string]string{"codec": "libx265", "preset": "veryfast"})
+
+	assert.True(t, fast > 0)
+	assert.True(t, slow > fast*10)
+	assert.InDelta(t, float64(fast*4), float64(hevc), float64(time.Millisecond))
+
+	// Four times the pixels takes four times as long
+	analysis.VideoFile.VideoInfo.Width = 3840
+	analysis.VideoFile.VideoInfo.Height = 2160
+	uhd := EstimateEncodeTime(analysis, map[string]string{"codec": "libx264", "preset": "veryfast"})
+	assert.InDelta(t, float64(fast*4), float64(uhd), float64(time.Millisecond))
+
+	// Copying the video stream runs far faster than real time
+	assert.Equal(t, 12*time.Second, EstimateEncodeTime(analysis, map[string]string{"codec": "copy"}))
+
+	analysis.VideoFile.Duration = 0
+	assert.Equal(t, time.Duration(0), EstimateEncodeTime(analysis, nil))
+}
@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Decision records a choice the analyzer made and why, so it can be
+// understood and overridden
+type Decision struct {
+	Name     string `json:"name"`               // What was decided: a setting such as "codec" or "crf", or "content_type"
+	Value    string `json:"value"`              // The value chosen
+	Reason   string `json:"reason"`             // Why, e.g. "height 1080 >= 1080 and content=Gaming"
+	Override string `json:"override,omitempty"` // Option that decides instead, e.g. "--codec"
+	Final    string `json:"final,omitempty"`    // Value of the setting after the encoder options changed it
+}
+
+// String describes the decision, e.g. "codec=hevc because content=Screencast"
+func (d Decision) String() string {
+	s := fmt.Sprintf("%s=%s because %s", d.Name, d.Value, d.Reason)
+	if d.Final != "" {
+		s += fmt.Sprintf("; changed to %s by the preset or encoder options", d.Final)
+	}
+	if d.Override != "" {
+		s += fmt.Sprintf(" (override with %s)", d.Override)
+	}
+	return s
+}
+
+// explain records a decision of the analyzer
+func (ca *ContentAnalyzer) explain(name, value, override, format string, args ...interface{}) {
+	if !ca.Explain {
+		return
+	}
+	ca.Record(Decision{Name: name, Value: value, Reason: fmt.Sprintf(format, args...), Override: override})
+}
+
+// amend changes the value of a recorded decision and adds to its reason,
+// for settings adjusted after they were first chosen
+func (ca *ContentAnalyzer) amend(name, value, format string, args ...interface{}) {
+	if !ca.Explain {
+		return
+	}
+	ca.decisionsMu.Lock()
+	defer ca.decisionsMu.Unlock()
+	for i := range ca.decisions {
+		if ca.decisions[i].Name == name {
+			ca.decisions[i].Value = value
+			ca.decisions[i].Reason += "; " + fmt.Sprintf(format, args...)
+			return
+		}
+	}
+}
+
+// Record adds a decision, such as one made outside the analyzer, to those
+// returned by Decisions. Nothing is recorded unless Explain is set. A
+// decision of the same name is replaced, so the record matches the settings
+// finally chosen.
+func (ca *ContentAnalyzer) Record(decision Decision) {
+	if ca == nil || !ca.Explain {
+		return
+	}
+	ca.decisionsMu.Lock()
+	defer ca.decisionsMu.Unlock()
+	for i := range ca.decisions {
+		if ca.decisions[i].Name == decision.Name {
+			ca.decisions[i] = decision
+			return
+		}
+	}
+	ca.decisions = append(ca.decisions, decision)
+}
+
+// Decisions returns the decisions recorded with Explain set, in the order
+// they were first made
+func (ca *ContentAnalyzer) Decisions() []Decision {
+	ca.decisionsMu.Lock()
+	defer ca.decisionsMu.Unlock()
+	return append([]Decision(nil), ca.decisions...)
+}
+
+// FinalDecisions returns decisions with the final value of each setting
+// that was changed after the analysis, such as a CRF lowered by the
+// thorough preset or a codec replaced by a hardware encoder
+func FinalDecisions(decisions []Decision, settings map[string]string) []Decision {
+	final := make([]Decision, len(decisions))
+	for i, decision := range decisions {
+		if value, ok := settings[decision.Name]; ok && value != decision.Value {
+			decision.Final = value
+		}
+		final[i] = decision
+	}
+	return final
+}
+
+// matchingKeyword returns the first of keywords that s contains, or an
+// empty string
+func matchingKeyword(s string, keywords []string) string {
+	for _, keyword := range keywords {
+		if strings.Contains(s, keyword) {
+			return keyword
+		}
+	}
+	return ""
+}
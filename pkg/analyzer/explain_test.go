@@ -0,0 +1,100 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+// decision returns the recorded decision called name
+func decision(ca *ContentAnalyzer, name string) Decision {
+	for _, d := range ca.Decisions() {
+		if d.Name == name {
+			return d
+		}
+	}
+	return Decision{}
+}
+
+// TestExplainCompressionSettings tests that the reasons for the codec and
+// CRF are recorded with Explain set
+func TestExplainCompressionSettings(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile:        &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 60}},
+		ContentType:      ContentTypeGaming,
+		MotionComplexity: MotionComplexityHigh,
+	}
+	ca := NewContentAnalyzer(nil, nil)
+
+	// Nothing is recorded unless asked
+	_, err := ca.GetCompressionSettings(analysis, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, ca.Decisions())
+
+	ca.Explain = true
+	settings, err := ca.GetCompressionSettings(analysis, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "25", settings["crf"])
+	assert.Equal(t, Decision{Name: "crf", Value: "25", Reason: "base 23 (content=Gaming) + motion High (-2) + quality 1 (+4)", Override: "-q or --target-vmaf"},
+		decision(ca, "crf"))
+	assert.Equal(t, "content=Gaming", decision(ca, "codec").Reason)
+	assert.Equal(t, "veryslow", decision(ca, "preset").Value)
+
+	// A chosen codec is explained by the flag, and replaces the decision
+	ca.Codec = "hevc"
+	_, err = ca.GetCompressionSettings(analysis, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "libx265", decision(ca, "codec").Value)
+	assert.Equal(t, "--codec hevc was given", decision(ca, "codec").Reason)
+	assert.Equal(t, "codec", ca.Decisions()[0].Name)
+
+	// VP9 moves the CRF to its own scale
+	analysis.ContentType = ContentTypeAnimation
+	analysis.MotionComplexity = MotionComplexityMedium
+	ca.Codec = ""
+	_, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "36", decision(ca, "crf").Value)
+	assert.Equal(t, "base 26 (content=Animation) + quality 3 (+0); +10 for the VP9 scale, clamped to 15-50", decision(ca, "crf").Reason)
+	assert.Equal(t, "0", decision(ca, "bitrate").Value)
+}
+
+// TestExplainAnalysis tests the reasons for the content type, motion and
+// recommended codec
+func TestExplainAnalysis(t *testing.T) {
+	ca := NewContentAnalyzer(nil, nil)
+	ca.Explain = true
+	video := &ffmpeg.VideoFile{Path: "/videos/My Gameplay.mp4", Duration: 120,
+		VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 60}}
+
+	assert.Equal(t, ContentTypeGaming, ca.detectContentType(video))
+	assert.Equal(t, `the file name contains "game"`, decision(ca, "content_type").Reason)
+
+	video.Path = "/videos/clip.mp4"
+	video.VideoInfo.FPS = 24
+	assert.Equal(t, ContentTypeScreencast, ca.detectContentType(video))
+	assert.Equal(t, "24 fps <= 30 at the screen width 1920", decision(ca, "content_type").Reason)
+
+	assert.Equal(t, MotionComplexityMedium, ca.determineMotionComplexity(video, 6, 300))
+	assert.Equal(t, Decision{Name: "motion", Value: "Medium", Override: "--motion-cutoffs",
+		Reason: "3.0 scene changes/min < 5 and frame complexity 300 < 500"}, decision(ca, "motion"))
+
+	assert.Equal(t, "hevc", ca.determineOptimalCodec(video, ContentTypeGaming))
+	assert.Equal(t, "height 1080 >= 1080 and content=Gaming", decision(ca, "recommended_codec").Reason)
+}
+
+func TestFinalDecisions(t *testing.T) {
+	decisions := []Decision{
+		{Name: "crf", Value: "25", Reason: "base 23 (content=Gaming) + quality 2 (+2)", Override: "-q or --target-vmaf"},
+		{Name: "content_type", Value: "Gaming", Reason: `the file name contains "game"`},
+	}
+	final := FinalDecisions(decisions, map[string]string{"crf": "23", "codec": "libx264"})
+	assert.Equal(t, "23", final[0].Final)
+	assert.Equal(t, "", final[1].Final)
+	assert.Equal(t, "", decisions[0].Final) // The recorded decisions are left alone
+
+	assert.Equal(t, "crf=25 because base 23 (content=Gaming) + quality 2 (+2); changed to 23 by the preset or encoder options (override with -q or --target-vmaf)",
+		final[0].String())
+	assert.Equal(t, `content_type=Gaming because the file name contains "game"`, final[1].String())
+}
@@ -0,0 +1,49 @@
+package analyzer
+
+import (
+	"math"
+	"strconv"
+)
+
+// defaultGOPSeconds is the keyframe interval of most content: two seconds
+// lets players seek and streams switch quality often enough while costing
+// little compression
+const defaultGOPSeconds = 2
+
+// contentGOPSeconds holds the keyframe interval of content types that need
+// another one. Screencasts are scrubbed through to find a step, and their
+// static frames make keyframes cheap.
+var contentGOPSeconds = map[ContentType]float64{
+	ContentTypeScreencast: 1,
+}
+
+// GOPSeconds returns the keyframe interval, in seconds, the analyzer uses
+// for a content type
+func GOPSeconds(contentType ContentType) float64 {
+	if seconds, ok := contentGOPSeconds[contentType]; ok {
+		return seconds
+	}
+	return defaultGOPSeconds
+}
+
+// KeyframeInterval returns the number of frames in seconds of video at fps,
+// at least 1
+func KeyframeInterval(seconds, fps float64) int {
+	frames := int(math.Round(seconds * fps))
+	if frames < 1 {
+		return 1
+	}
+	return frames
+}
+
+// setKeyframeInterval sets the gop of settings from the content type and
+// frame rate. Without a frame rate the encoder's default is kept.
+func (ca *ContentAnalyzer) setKeyframeInterval(settings map[string]string, analysis *VideoAnalysis) {
+	if analysis.VideoFile == nil || analysis.VideoFile.VideoInfo.FPS <= 0 {
+		return
+	}
+	fps := analysis.VideoFile.VideoInfo.FPS
+	seconds := GOPSeconds(analysis.ContentType)
+	settings["gop"] = strconv.Itoa(KeyframeInterval(seconds, fps))
+	ca.explain("gop", settings["gop"], "--keyint or --gop-seconds", "%gs at %g fps (content=%s)", seconds, fps, analysis.ContentType)
+}
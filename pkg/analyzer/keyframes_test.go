@@ -0,0 +1,42 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyframeInterval(t *testing.T) {
+	assert.Equal(t, 48, KeyframeInterval(2, 23.976))
+	assert.Equal(t, 60, KeyframeInterval(2, 29.97))
+	assert.Equal(t, 1, KeyframeInterval(0.01, 24))
+}
+
+// TestGetCompressionSettingsKeyframes tests that screencasts get shorter
+// GOPs than other content
+func TestGetCompressionSettingsKeyframes(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile:        &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 30}},
+		ContentType:      ContentTypeLiveAction,
+		MotionComplexity: MotionComplexityMedium,
+	}
+	ca := NewContentAnalyzer(nil, nil)
+	ca.Explain = true
+
+	settings, err := ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "60", settings["gop"])
+
+	analysis.ContentType = ContentTypeScreencast
+	settings, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "30", settings["gop"])
+	assert.Contains(t, ca.Decisions(), Decision{Name: "gop", Value: "30", Reason: "1s at 30 fps (content=Screencast)", Override: "--keyint or --gop-seconds"})
+
+	// Without a frame rate the encoder's default is kept
+	analysis.VideoFile.VideoInfo.FPS = 0
+	settings, err = ca.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.NotContains(t, settings, "gop")
+}
@@ -0,0 +1,140 @@
+package analyzer
+
+import (
+	"math"
+	"strings"
+)
+
+// codecLevel holds the limits of an H.264 or HEVC level
+type codecLevel struct {
+	name       string
+	maxFrame   int64 // Largest frame: macroblocks for H.264, luma samples for HEVC
+	maxRate    int64 // Most of the same units decoded per second
+	maxBitrate int64 // Highest bitrate in kbit/s for the Main profile and tier
+}
+
+// h264Levels holds the H.264 levels in increasing order, from table A-1 of
+// the standard. Level 1b is left out, nothing the tool makes needs it.
+var h264Levels = []codecLevel{
+	{"1", 99, 1485, 64},
+	{"1.1", 396, 3000, 192},
+	{"1.2", 396, 6000, 384},
+	{"1.3", 396, 11880, 768},
+	{"2", 396, 11880, 2000},
+	{"2.1", 792, 19800, 4000},
+	{"2.2", 1620, 20250, 4000},
+	{"3", 1620, 40500, 10000},
+	{"3.1", 3600, 108000, 14000},
+	{"3.2", 5120, 216000, 20000},
+	{"4", 8192, 245760, 20000},
+	{"4.1", 8192, 245760, 50000},
+	{"4.2", 8704, 522240, 50000},
+	{"5", 22080, 589824, 135000},
+	{"5.1", 36864, 983040, 240000},
+	{"5.2", 36864, 2073600, 240000},
+	{"6", 139264, 4177920, 240000},
+	{"6.1", 139264, 8355840, 480000},
+	{"6.2", 139264, 16711680, 800000},
+}
+
+// hevcLevels holds the HEVC levels in increasing order, from table A.8 of
+// the standard, with the bitrates of the Main tier
+var hevcLevels = []codecLevel{
+	{"1", 36864, 552960, 128},
+	{"2", 122880, 3686400, 1500},
+	{"2.1", 245760, 7372800, 3000},
+	{"3", 552960, 16588800, 6000},
+	{"3.1", 983040, 33177600, 10000},
+	{"4", 2228224, 66846720, 12000},
+	{"4.1", 2228224, 133693440, 20000},
+	{"5", 8912896, 267386880, 25000},
+	{"5.1", 8912896, 534773760, 40000},
+	{"5.2", 8912896, 1069547520, 60000},
+	{"6", 35651584, 1069547520, 60000},
+	{"6.1", 35651584, 2139095040, 120000},
+	{"6.2", 35651584, 4278190080, 240000},
+}
+
+// h264BitrateFactors scales the bitrate limits of the H.264 levels for the
+// profiles above Main
+var h264BitrateFactors = map[string]float64{
+	"high":    1.25,
+	"high10":  3,
+	"high422": 4,
+	"high444": 4,
+}
+
+// levelsOf returns the levels of the codec an encoder such as libx264 or
+// hevc_nvenc produces, nil for codecs without levels of this kind, and
+// whether the codec is H.264
+func levelsOf(encoder string) ([]codecLevel, bool) {
+	switch {
+	case encoder == "libx264" || strings.HasPrefix(encoder, "h264_"):
+		return h264Levels, true
+	case encoder == "libx265" || strings.HasPrefix(encoder, "hevc_"):
+		return hevcLevels, false
+	}
+	return nil, false
+}
+
+// holds reports whether level l holds video of width x height at fps
+// frames per second and bitrate bits per second, with the H.264 limits
+// when h264 is set and the HEVC ones otherwise
+func (l codecLevel) holds(h264 bool, profile string, width, height int, fps float64, bitrate int64) bool {
+	frame, long := int64(width)*int64(height), math.Max(float64(width), float64(height))
+	maxBitrate := float64(l.maxBitrate) * 1000
+	if h264 {
+		// H.264 limits count 16x16 macroblocks
+		columns, rows := (int64(width)+15)/16, (int64(height)+15)/16
+		frame, long = columns*rows, math.Max(float64(columns), float64(rows))
+		if factor, ok := h264BitrateFactors[profile]; ok {
+			maxBitrate *= factor
+		}
+	}
+	// No side may be longer than that of a square frame 8 times the size
+	return frame <= l.maxFrame &&
+		long <= math.Sqrt(float64(l.maxFrame)*8) &&
+		float64(frame)*fps <= float64(l.maxRate) &&
+		float64(bitrate) <= maxBitrate
+}
+
+// CodecLevel returns the lowest H.264 or HEVC level that holds video of
+// width x height at fps frames per second, encoded with encoder, such as
+// libx264 or hevc_nvenc, and profile. A bitrate in bits per second is held
+// too, 0 leaves it out. It returns "" for codecs without such levels, and
+// the highest level with false when the video is beyond every level.
+func CodecLevel(encoder, profile string, width, height int, fps float64, bitrate int64) (string, bool) {
+	levels, h264 := levelsOf(encoder)
+	if len(levels) == 0 {
+		return "", true
+	}
+	for _, level := range levels {
+		if level.holds(h264, profile, width, height, fps, bitrate) {
+			return level.name, true
+		}
+	}
+	return levels[len(levels)-1].name, false
+}
+
+// LevelHolds reports whether level, such as "4.1", holds video of width x
+// height at fps frames per second and bitrate bits per second, encoded with
+// encoder and profile. Levels it does not know hold anything.
+func LevelHolds(encoder, profile, level string, width, height int, fps float64, bitrate int64) bool {
+	levels, h264 := levelsOf(encoder)
+	for _, l := range levels {
+		if l.name == normalizeLevel(level) {
+			return l.holds(h264, profile, width, height, fps, bitrate)
+		}
+	}
+	return true
+}
+
+// normalizeLevel writes a level the way the tables do, so "4.0" and "40"
+// are found as "4"
+func normalizeLevel(level string) string {
+	level = strings.TrimSpace(level)
+	if len(level) == 2 && !strings.Contains(level, ".") {
+		level = level[:1] + "." + level[1:]
+	}
+	return strings.TrimSuffix(level, ".0")
+}
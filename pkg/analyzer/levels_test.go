@@ -0,0 +1,53 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodecLevel(t *testing.T) {
+	level := func(encoder, profile string, width, height int, fps float64, bitrate int64) string {
+		l, ok := CodecLevel(encoder, profile, width, height, fps, bitrate)
+		assert.True(t, ok)
+		return l
+	}
+
+	assert.Equal(t, "3", level("libx264", "main", 720, 480, 30, 0))
+	assert.Equal(t, "3.1", level("libx264", "main", 1280, 720, 30, 0))
+	assert.Equal(t, "3.2", level("libx264", "high", 1280, 720, 60, 0))
+	assert.Equal(t, "4", level("libx264", "high", 1920, 1080, 30, 0))
+	assert.Equal(t, "4.2", level("libx264", "high", 1920, 1080, 60, 0))
+	assert.Equal(t, "5.1", level("libx264", "high", 3840, 2160, 30, 0))
+	assert.Equal(t, "5.2", level("h264_nvenc", "high", 3840, 2160, 60, 0))
+
+	// High allows 25% more bitrate than Main
+	assert.Equal(t, "4.1", level("libx264", "main", 1920, 1080, 30, 40000000))
+	assert.Equal(t, "4", level("libx264", "high", 1920, 1080, 30, 25000000))
+	assert.Equal(t, "4.1", level("libx264", "main", 1920, 1080, 30, 25000000))
+
+	// Portrait frames take the same level; a long strip takes the level
+	// whose longest side reaches it, beyond its frame size
+	assert.Equal(t, "4", level("libx264", "high", 1080, 1920, 30, 0))
+	assert.Equal(t, "4", level("libx264", "high", 4096, 256, 30, 0))
+
+	assert.Equal(t, "4", level("libx265", "main", 1920, 1080, 30, 0))
+	assert.Equal(t, "4.1", level("libx265", "main", 1920, 1080, 60, 0))
+	assert.Equal(t, "5.1", level("hevc_nvenc", "main", 3840, 2160, 60, 0))
+	assert.Equal(t, "6", level("libx265", "main10", 7680, 4320, 30, 0))
+
+	assert.Equal(t, "", level("libvpx-vp9", "", 1920, 1080, 30, 0))
+
+	l, ok := CodecLevel("libx264", "high", 7680, 4320, 240, 0)
+	assert.False(t, ok)
+	assert.Equal(t, "6.2", l)
+}
+
+func TestLevelHolds(t *testing.T) {
+	assert.True(t, LevelHolds("libx264", "high", "4.2", 1920, 1080, 60, 0))
+	assert.False(t, LevelHolds("libx264", "high", "4.2", 3840, 2160, 60, 0))
+	assert.False(t, LevelHolds("libx264", "high", "4.0", 1920, 1080, 60, 0))
+	assert.True(t, LevelHolds("libx264", "high", "41", 1920, 1080, 30, 0))
+	assert.True(t, LevelHolds("libx265", "main", "5.1", 3840, 2160, 60, 0))
+	assert.True(t, LevelHolds("libx264", "high", "9", 7680, 4320, 240, 0))
+}
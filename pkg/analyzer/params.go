@@ -0,0 +1,98 @@
+package analyzer
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// MotionCutoff is the upper bound of a motion complexity level: a video is
+// at that level or below when it stays under both values
+type MotionCutoff struct {
+	ScenesPerMinute float64 `json:"scenes_per_minute"`
+	FrameComplexity float64 `json:"frame_complexity"`
+}
+
+// AnalysisParams are the tunables of the content analysis. They are stored
+// in each VideoAnalysis, so an analysis can be reproduced and a cached one
+// made with other values is not reused.
+type AnalysisParams struct {
+	SceneThreshold     float64         `json:"scene_threshold"`     // Scene score (0-1) above which a frame starts a new scene
+	ComplexityInterval float64         `json:"complexity_interval"` // Seconds between the frames sampled for complexity, 0 for keyframes
+	MotionCutoffs      [3]MotionCutoff `json:"motion_cutoffs"`      // Upper bounds of low, medium and high motion
+}
+
+// DefaultAnalysisParams returns the tunables the analysis uses unless told
+// otherwise
+func DefaultAnalysisParams() AnalysisParams {
+	return AnalysisParams{
+		SceneThreshold:     0.3,
+		ComplexityInterval: 0,
+		MotionCutoffs: [3]MotionCutoff{
+			{ScenesPerMinute: 2, FrameComplexity: 200},
+			{ScenesPerMinute: 5, FrameComplexity: 500},
+			{ScenesPerMinute: 10, FrameComplexity: 1000},
+		},
+	}
+}
+
+// Validate checks that the threshold is a scene score, the interval is not
+// negative and the motion cutoffs rise from low to high
+func (p AnalysisParams) Validate() error {
+	if p.SceneThreshold <= 0 || p.SceneThreshold >= 1 {
+		return i18n.Errorf("scene threshold must be between 0 and 1 (got %g)", p.SceneThreshold)
+	}
+	if p.ComplexityInterval < 0 {
+		return i18n.Errorf("complexity interval must be 0 or more (got %g)", p.ComplexityInterval)
+	}
+	for i := 1; i < len(p.MotionCutoffs); i++ {
+		previous, cutoff := p.MotionCutoffs[i-1], p.MotionCutoffs[i]
+		if cutoff.ScenesPerMinute < previous.ScenesPerMinute || cutoff.FrameComplexity < previous.FrameComplexity {
+			return i18n.Errorf("motion cutoffs must rise from low to high (got %s)", FormatMotionCutoffs(p.MotionCutoffs))
+		}
+	}
+	return nil
+}
+
+// ParseMotionCutoffs reads the upper bounds of low, medium and high motion
+// as three scenes-per-minute:frame-complexity pairs, e.g. "2:200,5:500,10:1000"
+func ParseMotionCutoffs(value string) ([3]MotionCutoff, error) {
+	var cutoffs [3]MotionCutoff
+	parts := strings.Split(value, ",")
+	if len(parts) != len(cutoffs) {
+		return cutoffs, i18n.Errorf("invalid motion cutoffs %q: expected three scenes-per-minute:complexity pairs, e.g. 2:200,5:500,10:1000", value)
+	}
+	for i, part := range parts {
+		scenes, complexity, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return cutoffs, i18n.Errorf("invalid motion cutoff %q: expected scenes-per-minute:complexity", part)
+		}
+		var err error
+		if cutoffs[i].ScenesPerMinute, err = strconv.ParseFloat(scenes, 64); err != nil || cutoffs[i].ScenesPerMinute < 0 {
+			return cutoffs, i18n.Errorf("invalid scenes per minute %q in motion cutoff %q", scenes, part)
+		}
+		if cutoffs[i].FrameComplexity, err = strconv.ParseFloat(complexity, 64); err != nil || cutoffs[i].FrameComplexity < 0 {
+			return cutoffs, i18n.Errorf("invalid frame complexity %q in motion cutoff %q", complexity, part)
+		}
+	}
+	return cutoffs, nil
+}
+
+// FormatMotionCutoffs writes cutoffs in the form ParseMotionCutoffs reads
+func FormatMotionCutoffs(cutoffs [3]MotionCutoff) string {
+	parts := make([]string, len(cutoffs))
+	for i, cutoff := range cutoffs {
+		parts[i] = strconv.FormatFloat(cutoff.ScenesPerMinute, 'g', -1, 64) + ":" + strconv.FormatFloat(cutoff.FrameComplexity, 'g', -1, 64)
+	}
+	return strings.Join(parts, ",")
+}
+
+// AnalyzedWith returns the tunables the analysis was made with. Analyses
+// cached before the tunables were recorded used the defaults.
+func (a *VideoAnalysis) AnalyzedWith() AnalysisParams {
+	if a.Params == (AnalysisParams{}) {
+		return DefaultAnalysisParams()
+	}
+	return a.Params
+}
@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMotionCutoffs(t *testing.T) {
+	cutoffs, err := ParseMotionCutoffs("1.5:150, 4:400, 8:900")
+	assert.NoError(t, err)
+	assert.Equal(t, [3]MotionCutoff{{1.5, 150}, {4, 400}, {8, 900}}, cutoffs)
+	assert.Equal(t, "1.5:150,4:400,8:900", FormatMotionCutoffs(cutoffs))
+
+	// The defaults survive a round trip
+	defaults := DefaultAnalysisParams().MotionCutoffs
+	cutoffs, err = ParseMotionCutoffs(FormatMotionCutoffs(defaults))
+	assert.NoError(t, err)
+	assert.Equal(t, defaults, cutoffs)
+
+	for _, value := range []string{"", "2:200,5:500", "2:200,5:500,10:1000,20:2000", "2-200,5:500,10:1000", "2:x,5:500,10:1000", "-2:200,5:500,10:1000"} {
+		_, err := ParseMotionCutoffs(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestAnalysisParamsValidate(t *testing.T) {
+	assert.NoError(t, DefaultAnalysisParams().Validate())
+
+	params := DefaultAnalysisParams()
+	params.SceneThreshold = 1
+	assert.Error(t, params.Validate())
+
+	params = DefaultAnalysisParams()
+	params.ComplexityInterval = -1
+	assert.Error(t, params.Validate())
+
+	params = DefaultAnalysisParams()
+	params.MotionCutoffs[1].FrameComplexity = 100
+	assert.EqualError(t, params.Validate(), "motion cutoffs must rise from low to high (got 2:200,5:100,10:1000)")
+}
+
+// TestMotionCutoffs tests that the motion complexity levels follow the
+// analyzer's cutoffs
+func TestMotionCutoffs(t *testing.T) {
+	video := &ffmpeg.VideoFile{Duration: 120}
+	ca := NewContentAnalyzer(nil, nil)
+	// 3 scene changes per minute
+	assert.Equal(t, MotionComplexityMedium, ca.determineMotionComplexity(video, 6, 300))
+	assert.Equal(t, MotionComplexityVeryHigh, ca.determineMotionComplexity(video, 6, 1500))
+
+	ca.Params.MotionCutoffs = [3]MotionCutoff{{4, 400}, {8, 800}, {16, 1600}}
+	assert.Equal(t, MotionComplexityLow, ca.determineMotionComplexity(video, 6, 300))
+	assert.Equal(t, MotionComplexityHigh, ca.determineMotionComplexity(video, 6, 1500))
+
+	// An analyzer built without NewContentAnalyzer uses the defaults
+	assert.Equal(t, MotionComplexityMedium, (&ContentAnalyzer{}).determineMotionComplexity(video, 6, 300))
+}
+
+func TestAnalyzedWith(t *testing.T) {
+	// Analyses cached before the tunables were recorded used the defaults
+	assert.Equal(t, DefaultAnalysisParams(), (&VideoAnalysis{}).AnalyzedWith())
+
+	params := DefaultAnalysisParams()
+	params.SceneThreshold = 0.2
+	assert.Equal(t, params, (&VideoAnalysis{Params: params}).AnalyzedWith())
+}
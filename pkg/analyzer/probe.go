@@ -0,0 +1,41 @@
+package analyzer
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// defaultFrameComplexity is the frame complexity assumed for contentType
+// when the frames are not measured
+func defaultFrameComplexity(contentType ContentType) float64 {
+	switch contentType {
+	case ContentTypeScreencast:
+		return 100
+	case ContentTypeAnimation:
+		return 200
+	default:
+		return 500
+	}
+}
+
+// EstimateFromProbe analyzes videoFile from what ffprobe reports alone,
+// without decoding a frame, for surveys of whole libraries: the content
+// type comes from the file name and stream properties, the motion is taken
+// as medium and the frame complexity as typical of the content. The
+// optimal bitrate and compression potential follow from those as in
+// AnalyzeVideo, so they are rougher than its own.
+func (ca *ContentAnalyzer) EstimateFromProbe(videoFile *ffmpeg.VideoFile) *VideoAnalysis {
+	analysis := &VideoAnalysis{
+		VideoFile:        videoFile,
+		Params:           ca.params(),
+		ContentType:      ca.detectContentType(videoFile),
+		MotionComplexity: MotionComplexityMedium,
+		IsHDContent:      videoFile.VideoInfo.Height >= 720,
+		IsUHDContent:     videoFile.VideoInfo.Height >= 2160 || videoFile.VideoInfo.Width >= 3840,
+	}
+	analysis.FrameComplexity = defaultFrameComplexity(analysis.ContentType)
+	analysis.SpatialComplexity = ca.calculateSpatialComplexity(videoFile, analysis.FrameComplexity)
+	analysis.RecommendedCodec = ca.determineOptimalCodec(videoFile, analysis.ContentType)
+	analysis.OptimalBitrate = ca.calculateOptimalBitrate(videoFile, analysis)
+	analysis.CompressionPotential = ca.estimateCompressionPotential(videoFile, analysis)
+	return analysis
+}
@@ -0,0 +1,35 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateFromProbe(t *testing.T) {
+	ca := NewContentAnalyzer(nil, util.NewLogger(false))
+
+	// An MKV whose video stream has no bitrate of its own is judged on the
+	// container's, less the audio: already at the optimal bitrate
+	videoFile := &ffmpeg.VideoFile{
+		Path:      "/videos/holiday.mkv",
+		Duration:  600,
+		BitRate:   628000,
+		VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264", Width: 3840, Height: 2160, FPS: 25},
+		AudioInfo: []ffmpeg.AudioStreamInfo{{Codec: "aac", BitRate: 128000}},
+	}
+	analysis := ca.EstimateFromProbe(videoFile)
+	assert.Equal(t, ContentTypeLiveAction, analysis.ContentType)
+	assert.Equal(t, MotionComplexityMedium, analysis.MotionComplexity)
+	assert.Equal(t, 500.0, analysis.FrameComplexity)
+	assert.True(t, analysis.IsUHDContent)
+	assert.Equal(t, "hevc", analysis.RecommendedCodec)
+	assert.Equal(t, int64(500000), analysis.OptimalBitrate)
+	assert.Equal(t, 0, analysis.CompressionPotential)
+
+	// Screencasts are expected to compress further
+	videoFile.Path = "/videos/screen recording.mkv"
+	assert.Equal(t, ContentTypeScreencast, ca.EstimateFromProbe(videoFile).ContentType)
+}
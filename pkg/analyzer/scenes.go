@@ -0,0 +1,37 @@
+package analyzer
+
+import (
+	"math"
+	"sort"
+)
+
+// BusiestScene returns the start of the window of length seconds with the
+// most scene changes, the part of the video hardest to compress. Without
+// scene times, as in analyses shared by a series or made before they were
+// kept, it returns the start of the window in the middle of the video.
+func (a *VideoAnalysis) BusiestScene(length float64) float64 {
+	var duration float64
+	if a.VideoFile != nil {
+		duration = a.VideoFile.Duration
+	}
+	latest := math.Max(duration-length, 0)
+	if len(a.SceneTimes) == 0 {
+		return latest / 2
+	}
+
+	times := append([]float64(nil), a.SceneTimes...)
+	sort.Float64s(times)
+
+	// Windows start at a scene change; ties go to the earlier window
+	best, bestCount := latest/2, 0
+	end := 0
+	for start := range times {
+		for end < len(times) && times[end] < times[start]+length {
+			end++
+		}
+		if count := end - start; count > bestCount {
+			best, bestCount = times[start], count
+		}
+	}
+	return math.Min(best, latest)
+}
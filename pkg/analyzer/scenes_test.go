@@ -0,0 +1,28 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusiestScene(t *testing.T) {
+	analysis := &VideoAnalysis{
+		VideoFile:  &ffmpeg.VideoFile{Duration: 120},
+		SceneTimes: []float64{70, 5, 62, 64, 30, 68, 90},
+	}
+	assert.Equal(t, 62.0, analysis.BusiestScene(10))
+
+	// Ties go to the earlier window, which stays within the video
+	analysis.SceneTimes = []float64{20, 118}
+	assert.Equal(t, 20.0, analysis.BusiestScene(10))
+	analysis.SceneTimes = []float64{118}
+	assert.Equal(t, 110.0, analysis.BusiestScene(10))
+
+	// Without scene times the middle of the video is used
+	analysis.SceneTimes = nil
+	assert.Equal(t, 55.0, analysis.BusiestScene(10))
+	analysis.VideoFile.Duration = 4
+	assert.Equal(t, 0.0, analysis.BusiestScene(10))
+}
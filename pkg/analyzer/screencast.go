@@ -0,0 +1,185 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+const (
+	// overlayMotionRatio is how much more a corner must move than the rest of
+	// the screen to be treated as a webcam overlay
+	overlayMotionRatio = 4.0
+	// minOverlayMotion is the minimum motion of a webcam overlay, so noise in
+	// a completely static recording is not mistaken for one
+	minOverlayMotion = 1.0
+	// screencastGOPSeconds is the keyframe interval used for static screens
+	screencastGOPSeconds = 10
+	// screencastMaxFPS is the frame rate static screens are reduced to
+	screencastMaxFPS = 15
+	// overlayQOffset gives the webcam overlay more bits than the screen
+	overlayQOffset = "-1/5"
+)
+
+// ScreencastLayout describes the static screen and webcam overlay of a screencast
+type ScreencastLayout struct {
+	Overlay       *ffmpeg.Region // Webcam overlay, nil when there is none
+	ScreenMotion  float64        // Motion of the screen area
+	OverlayMotion float64        // Motion of the overlay area
+}
+
+// screencastCorners returns the four corner regions where a webcam overlay is
+// usually placed, each a quarter of the frame wide and high
+func screencastCorners(width, height int) []ffmpeg.Region {
+	w := width / 4 &^ 1
+	h := height / 4 &^ 1
+	return []ffmpeg.Region{
+		{X: 0, Y: 0, Width: w, Height: h},
+		{X: width - w, Y: 0, Width: w, Height: h},
+		{X: 0, Y: height - h, Width: w, Height: h},
+		{X: width - w, Y: height - h, Width: w, Height: h},
+	}
+}
+
+// findOverlay returns the index of the corner whose motion stands out from
+// the others, or -1, together with the motion of the rest of the screen
+func findOverlay(motions []float64) (int, float64) {
+	if len(motions) == 0 {
+		return -1, 0
+	}
+
+	best := 0
+	for i, m := range motions {
+		if m > motions[best] {
+			best = i
+		}
+	}
+
+	var others []float64
+	for i, m := range motions {
+		if i != best {
+			others = append(others, m)
+		}
+	}
+	sort.Float64s(others)
+	screen := 0.0
+	if len(others) > 0 {
+		screen = others[len(others)/2]
+	}
+
+	if motions[best] >= minOverlayMotion && motions[best] >= screen*overlayMotionRatio {
+		return best, screen
+	}
+	return -1, screen
+}
+
+// DetectScreencastLayout measures the motion in each corner of a screencast to
+// find a webcam overlay on an otherwise static screen
+func (ca *ContentAnalyzer) DetectScreencastLayout(videoFile *ffmpeg.VideoFile) (*ScreencastLayout, error) {
+	width, height := videoFile.VideoInfo.Width, videoFile.VideoInfo.Height
+	if width < 64 || height < 64 {
+		return nil, fmt.Errorf("video too small for region analysis: %dx%d", width, height)
+	}
+
+	corners := screencastCorners(width, height)
+	motions := make([]float64, len(corners))
+	for i, corner := range corners {
+		motion, err := ca.FFmpeg.RegionMotion(videoFile.Path, corner)
+		if err != nil {
+			return nil, err
+		}
+		ca.Logger.Debug("Motion in region %s: %.2f", corner, motion)
+		motions[i] = motion
+	}
+
+	layout := &ScreencastLayout{}
+	idx, screen := findOverlay(motions)
+	layout.ScreenMotion = screen
+	if idx >= 0 {
+		overlay := corners[idx]
+		layout.Overlay = &overlay
+		layout.OverlayMotion = motions[idx]
+	}
+	return layout, nil
+}
+
+// ApplyScreencastROI tunes the settings for a static screen: a long keyframe
+// interval, still-image tuning and a lower frame rate when there is no
+// overlay, or more bits for the webcam overlay when there is one
+func ApplyScreencastROI(settings map[string]string, layout *ScreencastLayout, videoFile *ffmpeg.VideoFile) {
+	if layout == nil || videoFile == nil {
+		return
+	}
+
+	fps := ffmpeg.OutputFPS(settings, videoFile.VideoInfo.FPS)
+	// A frame rate already chosen, e.g. with --fps, is kept
+	if layout.Overlay == nil && fps > screencastMaxFPS && ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS) == "" {
+		ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, fmt.Sprintf("fps=%d", screencastMaxFPS))
+		fps = screencastMaxFPS
+	}
+	if fps > 0 {
+		settings["gop"] = strconv.Itoa(int(fps * screencastGOPSeconds))
+	}
+
+	switch settings["codec"] {
+	case "libx264":
+		// Still-image tuning suits the screen but smears a moving webcam
+		if layout.Overlay == nil {
+			settings["tune"] = "stillimage"
+		} else {
+			delete(settings, "tune")
+		}
+	case "libx265":
+		// Zero-latency tuning disables the lookahead that static content benefits from
+		delete(settings, "tune")
+		delete(settings, "x265-params")
+	}
+
+	if layout.Overlay != nil {
+		if roi := overlayROI(settings, *layout.Overlay, videoFile.VideoInfo.Width, videoFile.VideoInfo.Height); roi != "" {
+			ffmpeg.SetVideoFilter(settings, ffmpeg.FilterROI, roi)
+		}
+	}
+}
+
+// overlayROI returns the addroi filter that gives the overlay, found in the
+// width x height source frame, more bits. The filter runs after the crop
+// and scale stages, so the overlay is moved into the cropped frame and
+// written relative to the size of the frame it annotates. It returns an
+// empty string when the crop leaves the overlay out or cannot be read.
+func overlayROI(settings map[string]string, overlay ffmpeg.Region, width, height int) string {
+	if crop := ffmpeg.VideoFilter(settings, ffmpeg.FilterCrop); crop != "" {
+		var cropWidth, cropHeight, cropX, cropY int
+		if _, err := fmt.Sscanf(crop, "crop=%d:%d:%d:%d", &cropWidth, &cropHeight, &cropX, &cropY); err != nil {
+			return ""
+		}
+		left, top := max(overlay.X, cropX), max(overlay.Y, cropY)
+		right := min(overlay.X+overlay.Width, cropX+cropWidth)
+		bottom := min(overlay.Y+overlay.Height, cropY+cropHeight)
+		if right <= left || bottom <= top {
+			return ""
+		}
+		overlay = ffmpeg.Region{X: left - cropX, Y: top - cropY, Width: right - left, Height: bottom - top}
+		width, height = cropWidth, cropHeight
+	}
+	if width <= 0 || height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("addroi=x=%s:y=%s:w=%s:h=%s:qoffset=%s",
+		relativeSize("iw", overlay.X, width), relativeSize("ih", overlay.Y, height),
+		relativeSize("iw", overlay.Width, width), relativeSize("ih", overlay.Height, height), overlayQOffset)
+}
+
+// relativeSize returns n of a total-pixel dimension as an expression of the
+// size the filter sees, dim (iw or ih)
+func relativeSize(dim string, n, total int) string {
+	switch n {
+	case 0:
+		return "0"
+	case total:
+		return dim
+	}
+	return fmt.Sprintf("%s*%d/%d", dim, n, total)
+}
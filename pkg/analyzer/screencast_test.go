@@ -0,0 +1,74 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScreencastCorners(t *testing.T) {
+	corners := screencastCorners(1920, 1080)
+	assert.Len(t, corners, 4)
+	assert.Equal(t, ffmpeg.Region{X: 1440, Y: 810, Width: 480, Height: 270}, corners[3])
+}
+
+func TestFindOverlay(t *testing.T) {
+	idx, screen := findOverlay([]float64{0.2, 0.3, 0.25, 6.0})
+	assert.Equal(t, 3, idx)
+	assert.Equal(t, 0.25, screen)
+
+	// Uniform motion is not an overlay
+	idx, _ = findOverlay([]float64{3.0, 3.5, 2.8, 4.0})
+	assert.Equal(t, -1, idx)
+
+	// A fully static recording has no overlay either
+	idx, _ = findOverlay([]float64{0.0, 0.0, 0.1, 0.3})
+	assert.Equal(t, -1, idx)
+}
+
+func TestApplyScreencastROI(t *testing.T) {
+	videoFile := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 30}}
+
+	settings := map[string]string{"codec": "libx264", "crf": "28"}
+	ApplyScreencastROI(settings, &ScreencastLayout{}, videoFile)
+	assert.Equal(t, "stillimage", settings["tune"])
+	assert.Equal(t, "fps=15", settings["video_filter"])
+	assert.Equal(t, "150", settings["gop"])
+
+	overlay := ffmpeg.Region{X: 1440, Y: 810, Width: 480, Height: 270}
+	settings = map[string]string{"codec": "libx265", "tune": "zerolatency", "x265-params": "bframes=0"}
+	ApplyScreencastROI(settings, &ScreencastLayout{Overlay: &overlay}, videoFile)
+	assert.NotContains(t, settings, "tune")
+	assert.NotContains(t, settings, "x265-params")
+	assert.Equal(t, "300", settings["gop"])
+	assert.Equal(t, "addroi=x=iw*1440/1920:y=ih*810/1080:w=iw*480/1920:h=ih*270/1080:qoffset=-1/5", settings["video_filter"])
+}
+
+// TestApplyScreencastROICropScale tests that the overlay lands on the same
+// picture after the frame is cropped and scaled
+func TestApplyScreencastROICropScale(t *testing.T) {
+	videoFile := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 30}}
+	overlay := ffmpeg.Region{X: 1440, Y: 810, Width: 480, Height: 270}
+
+	settings := map[string]string{"codec": "libx264"}
+	ffmpeg.SetVideoFilter(settings, ffmpeg.FilterCrop, "crop=1600:900:160:90")
+	ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:720")
+	ApplyScreencastROI(settings, &ScreencastLayout{Overlay: &overlay}, videoFile)
+	// The crop cuts the overlay to 320x180 at 1280,720 of the 1600x900 frame
+	assert.Equal(t, "crop=1600:900:160:90,scale=-2:720,addroi=x=iw*1280/1600:y=ih*720/900:w=iw*320/1600:h=ih*180/900:qoffset=-1/5",
+		settings["video_filter"])
+
+	// An overlay the crop cuts away gets no region
+	settings = map[string]string{"codec": "libx264"}
+	ffmpeg.SetVideoFilter(settings, ffmpeg.FilterCrop, "crop=960:540:0:0")
+	ApplyScreencastROI(settings, &ScreencastLayout{Overlay: &overlay}, videoFile)
+	assert.Equal(t, "", ffmpeg.VideoFilter(settings, ffmpeg.FilterROI))
+
+	// Covering the whole cropped frame needs no fractions
+	overlay = ffmpeg.Region{X: 0, Y: 0, Width: 1920, Height: 1080}
+	settings = map[string]string{"codec": "libx264"}
+	ffmpeg.SetVideoFilter(settings, ffmpeg.FilterCrop, "crop=960:540:0:0")
+	ApplyScreencastROI(settings, &ScreencastLayout{Overlay: &overlay}, videoFile)
+	assert.Equal(t, "addroi=x=0:y=0:w=iw:h=ih:qoffset=-1/5", ffmpeg.VideoFilter(settings, ffmpeg.FilterROI))
+}
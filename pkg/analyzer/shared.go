@@ -0,0 +1,73 @@
+package analyzer
+
+import (
+	"math"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// SharedAnalysis derives one analysis from the analyses of similar videos,
+// such as episodes of a series. The most common content type and codec
+// win, and the measures are taken from the most demanding video, so no
+// video of the group gets fewer bits than its own analysis would give it.
+// Scene changes are kept as a rate, see ForVideo, without their times, and
+// the bitrate statistics of each source are left out. It returns nil
+// without analyses.
+func SharedAnalysis(analyses []*VideoAnalysis) *VideoAnalysis {
+	if len(analyses) == 0 {
+		return nil
+	}
+	shared := *analyses[0]
+	types := make(map[ContentType]int)
+	codecs := make(map[string]int)
+	var scenes, duration float64
+	for _, analysis := range analyses {
+		types[analysis.ContentType]++
+		codecs[analysis.RecommendedCodec]++
+		if analysis.MotionComplexity > shared.MotionComplexity {
+			shared.MotionComplexity = analysis.MotionComplexity
+		}
+		shared.FrameComplexity = math.Max(shared.FrameComplexity, analysis.FrameComplexity)
+		shared.SpatialComplexity = math.Max(shared.SpatialComplexity, analysis.SpatialComplexity)
+		if analysis.OptimalBitrate > shared.OptimalBitrate {
+			shared.OptimalBitrate = analysis.OptimalBitrate
+		}
+		if analysis.CompressionPotential < shared.CompressionPotential {
+			shared.CompressionPotential = analysis.CompressionPotential
+		}
+		if analysis.VideoFile != nil {
+			scenes += float64(analysis.SceneChanges)
+			duration += analysis.VideoFile.Duration
+		}
+	}
+
+	// Ties go to the choice of the earlier video
+	for _, analysis := range analyses {
+		if types[analysis.ContentType] > types[shared.ContentType] {
+			shared.ContentType = analysis.ContentType
+		}
+		if codecs[analysis.RecommendedCodec] > codecs[shared.RecommendedCodec] {
+			shared.RecommendedCodec = analysis.RecommendedCodec
+		}
+	}
+
+	// Keep the scene changes per second of the group for ForVideo
+	shared.VideoFile = &ffmpeg.VideoFile{Duration: duration}
+	shared.SceneChanges = int(math.Round(scenes))
+	shared.SceneTimes = nil
+	shared.Bitrate = nil
+	return &shared
+}
+
+// ForVideo returns a copy of the shared analysis for videoFile, with the
+// scene changes scaled to its duration and its own resolution classes
+func (a *VideoAnalysis) ForVideo(videoFile *ffmpeg.VideoFile) *VideoAnalysis {
+	analysis := *a
+	if a.VideoFile != nil && a.VideoFile.Duration > 0 {
+		analysis.SceneChanges = int(math.Round(float64(a.SceneChanges) * videoFile.Duration / a.VideoFile.Duration))
+	}
+	analysis.VideoFile = videoFile
+	analysis.IsHDContent = videoFile.VideoInfo.Height >= 720
+	analysis.IsUHDContent = videoFile.VideoInfo.Height >= 2160 || videoFile.VideoInfo.Width >= 3840
+	return &analysis
+}
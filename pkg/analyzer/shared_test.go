@@ -0,0 +1,55 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedAnalysis(t *testing.T) {
+	assert.Nil(t, SharedAnalysis(nil))
+
+	episode := func(contentType ContentType, motion MotionComplexity, scenes int, bitrate int64, potential int) *VideoAnalysis {
+		return &VideoAnalysis{
+			VideoFile:            &ffmpeg.VideoFile{Duration: 600},
+			ContentType:          contentType,
+			MotionComplexity:     motion,
+			SceneChanges:         scenes,
+			FrameComplexity:      float64(scenes) * 10,
+			CompressionPotential: potential,
+			RecommendedCodec:     "libx265",
+			OptimalBitrate:       bitrate,
+		}
+	}
+	shared := SharedAnalysis([]*VideoAnalysis{
+		episode(ContentTypeLiveAction, MotionComplexityMedium, 30, 2000000, 60),
+		episode(ContentTypeAnimation, MotionComplexityHigh, 60, 1500000, 50),
+		episode(ContentTypeAnimation, MotionComplexityLow, 30, 1000000, 70),
+	})
+
+	// The most common content type, and the most demanding measures
+	assert.Equal(t, ContentTypeAnimation, shared.ContentType)
+	assert.Equal(t, "libx265", shared.RecommendedCodec)
+	assert.Equal(t, MotionComplexityHigh, shared.MotionComplexity)
+	assert.Equal(t, 600.0, shared.FrameComplexity)
+	assert.Equal(t, int64(2000000), shared.OptimalBitrate)
+	assert.Equal(t, 50, shared.CompressionPotential)
+
+	// Scene changes follow the duration of each episode
+	videoFile := &ffmpeg.VideoFile{Duration: 900, VideoInfo: ffmpeg.VideoStreamInfo{Width: 3840, Height: 1600}}
+	analysis := shared.ForVideo(videoFile)
+	assert.Equal(t, 60, analysis.SceneChanges)
+	assert.Equal(t, videoFile, analysis.VideoFile)
+	assert.True(t, analysis.IsHDContent)
+	assert.True(t, analysis.IsUHDContent)
+	assert.Equal(t, 1800.0, shared.VideoFile.Duration)
+	assert.Nil(t, shared.SceneTimes)
+
+	// Ties go to the first episode
+	tie := SharedAnalysis([]*VideoAnalysis{
+		episode(ContentTypeGaming, MotionComplexityLow, 10, 1000000, 60),
+		episode(ContentTypeScreencast, MotionComplexityLow, 10, 1000000, 60),
+	})
+	assert.Equal(t, ContentTypeGaming, tie.ContentType)
+}
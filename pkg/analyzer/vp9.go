@@ -0,0 +1,45 @@
+package analyzer
+
+import (
+	"strconv"
+)
+
+// VP9CRFOffset converts the H.264-scale CRF from calculateCRF to the 0-63
+// VP9 scale, where the same visual quality sits about 10 points higher
+const VP9CRFOffset = 10
+
+// vp9CRF converts an H.264-scale CRF to VP9, within the range VP9 uses in practice
+func vp9CRF(crf string) string {
+	value, err := strconv.Atoi(crf)
+	if err != nil {
+		return crf
+	}
+	value += VP9CRFOffset
+	if value < 15 {
+		value = 15
+	} else if value > 50 {
+		value = 50
+	}
+	return strconv.Itoa(value)
+}
+
+// vp9TileColumns returns the log2 number of tile columns for a frame width.
+// Tiles must be at least 256 pixels wide, and more of them lets row-mt use
+// more threads.
+func vp9TileColumns(width int) int {
+	columns := 0
+	for width >= 512 && columns < 6 {
+		width /= 2
+		columns++
+	}
+	return columns
+}
+
+// addVP9Settings sets up constant-quality VP9 encoding
+func addVP9Settings(settings map[string]string, analysis *VideoAnalysis) {
+	settings["crf"] = vp9CRF(settings["crf"])
+	// -b:v 0 with -crf is VP9's constant quality mode
+	settings["bitrate"] = "0"
+	settings["row_mt"] = "1"
+	settings["tile_columns"] = strconv.Itoa(vp9TileColumns(analysis.VideoFile.VideoInfo.Width))
+}
@@ -0,0 +1,43 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVP9CRF(t *testing.T) {
+	assert.Equal(t, "33", vp9CRF("23"))
+	assert.Equal(t, "15", vp9CRF("2"))
+	assert.Equal(t, "50", vp9CRF("45"))
+}
+
+func TestVP9TileColumns(t *testing.T) {
+	assert.Equal(t, 0, vp9TileColumns(480))
+	assert.Equal(t, 2, vp9TileColumns(1280))
+	assert.Equal(t, 2, vp9TileColumns(1920))
+	assert.Equal(t, 3, vp9TileColumns(3840))
+}
+
+func TestAnimationGetsVP9(t *testing.T) {
+	analyzer := NewContentAnalyzer(&ffmpeg.FFmpeg{}, nil)
+	analysis := &VideoAnalysis{
+		VideoFile: &ffmpeg.VideoFile{
+			Path:      "cartoon.mp4",
+			VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 24},
+			Duration:  600,
+		},
+		ContentType:      ContentTypeAnimation,
+		MotionComplexity: MotionComplexityMedium,
+	}
+
+	settings, err := analyzer.GetCompressionSettings(analysis, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "libvpx-vp9", settings["codec"])
+	assert.Equal(t, "36", settings["crf"])
+	assert.Equal(t, "0", settings["bitrate"])
+	assert.Equal(t, "1", settings["row_mt"])
+	assert.Equal(t, "2", settings["tile_columns"])
+	assert.NotContains(t, settings, "profile")
+}
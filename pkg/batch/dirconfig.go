@@ -0,0 +1,208 @@
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// DirConfigName is the file that overrides the settings of a directory and
+// its subdirectories in a directory run
+const DirConfigName = ".compressvideo.yaml"
+
+// DirConfig is a .compressvideo.yaml file. Its settings apply to the files
+// of its directory and every subdirectory, and a file deeper in the tree
+// overrides the ones above it setting by setting.
+type DirConfig struct {
+	Settings         config.Profile `yaml:",inline"`            // Quality, preset, codec, screencast ROI and routes
+	Profile          string         `yaml:"profile"`            // Config file profile the settings start from
+	SkipCodecs       string         `yaml:"skip_codecs"`        // As --skip-codecs
+	SkipBelowBitrate string         `yaml:"skip_below_bitrate"` // As --skip-below-bitrate
+	Skip             bool           `yaml:"skip"`               // Leave the directory and its subdirectories alone
+
+	// Exclude lists files and subdirectories that are never compressed. A
+	// name such as raw or *.mov matches at any depth; a path with a slash,
+	// such as /masters or clips/raw, is relative to the directory.
+	Exclude []string `yaml:"exclude"`
+}
+
+// LoadDirConfig reads the .compressvideo.yaml of dir, or returns nil when
+// there is none. Unknown keys are errors, so a misspelled setting is not
+// silently ignored.
+func LoadDirConfig(dir string) (*DirConfig, error) {
+	file := filepath.Join(dir, DirConfigName)
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &DirConfig{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, i18n.Errorf("failed to parse %s: %v", file, err)
+	}
+	for _, pattern := range cfg.Exclude {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, i18n.Errorf("invalid exclude pattern %q in %s", pattern, file)
+		}
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings, as the matching flags are checked
+func (c DirConfig) Validate() error {
+	if c.Settings.Quality != 0 && (c.Settings.Quality < 1 || c.Settings.Quality > 5) {
+		return i18n.Errorf("quality must be between 1-5 (got %d)", c.Settings.Quality)
+	}
+	switch c.Settings.Preset {
+	case "", "fast", "balanced", "thorough":
+	default:
+		return i18n.Errorf("preset must be one of: fast, balanced, thorough (got %s)", c.Settings.Preset)
+	}
+	if !analyzer.ValidCodec(c.Settings.Codec) {
+		return i18n.Errorf("codec must be one of: auto, h264, hevc, vp9 (got %s)", c.Settings.Codec)
+	}
+	if _, err := ParseMinBitrate(c.SkipBelowBitrate); err != nil {
+		return err
+	}
+	return ValidateRoutes(c.Settings.Routes)
+}
+
+// Override returns c with the settings that o sets replaced
+func (c DirConfig) Override(o DirConfig) DirConfig {
+	if o.Profile != "" {
+		c.Profile = o.Profile
+	}
+	if o.Settings.Quality != 0 {
+		c.Settings.Quality = o.Settings.Quality
+	}
+	if o.Settings.Preset != "" {
+		c.Settings.Preset = o.Settings.Preset
+	}
+	if o.Settings.Codec != "" {
+		c.Settings.Codec = o.Settings.Codec
+	}
+	if o.Settings.ScreencastROI {
+		c.Settings.ScreencastROI = true
+	}
+	if len(o.Settings.Routes) > 0 {
+		c.Settings.Routes = o.Settings.Routes
+	}
+	if o.SkipCodecs != "" {
+		c.SkipCodecs = o.SkipCodecs
+	}
+	if o.SkipBelowBitrate != "" {
+		c.SkipBelowBitrate = o.SkipBelowBitrate
+	}
+	return c
+}
+
+// resolve fills in the settings of the profile c names, which the settings
+// of c itself override
+func (c DirConfig) resolve(profiles map[string]config.Profile) (DirConfig, error) {
+	if c.Profile == "" {
+		return c, nil
+	}
+	profile, ok := profiles[c.Profile]
+	if !ok {
+		return c, i18n.Errorf("profile %s not found in the config file", c.Profile)
+	}
+	return DirConfig{Settings: profile}.Override(c), nil
+}
+
+// excludeRule holds the exclude patterns of a directory file
+type excludeRule struct {
+	dir      string
+	patterns []string
+}
+
+// matches reports whether file, inside the rule's directory, is excluded
+func (r excludeRule) matches(file string) bool {
+	rel, err := filepath.Rel(r.dir, file)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range r.patterns {
+		name := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			pattern, name = strings.Trim(pattern, "/"), rel
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dirState is what the directory files above and in a directory decide
+// for its files
+type dirState struct {
+	config   DirConfig
+	files    []string
+	excludes []excludeRule
+}
+
+// excluded reports whether a rule of the state excludes file
+func (s *dirState) excluded(file string) bool {
+	for _, rule := range s.excludes {
+		if rule.matches(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// enter returns the state of dir, a subdirectory of s, with its own file
+// read. It returns nil when the file tells the run to skip the directory.
+func (s *dirState) enter(dir string, profiles map[string]config.Profile) (*dirState, error) {
+	cfg, err := LoadDirConfig(dir)
+	if err != nil || cfg == nil {
+		return s, err
+	}
+	if cfg.Skip {
+		return nil, nil
+	}
+
+	// Routes of the file to relative directories are relative to it
+	routes := make([]config.Route, len(cfg.Settings.Routes))
+	for i, route := range cfg.Settings.Routes {
+		if route.Output != "" && !filepath.IsAbs(route.Output) && !strings.HasPrefix(route.Output, "~") {
+			route.Output = filepath.Join(dir, route.Output)
+		}
+		routes[i] = route
+	}
+	cfg.Settings.Routes = routes
+
+	file := filepath.Join(dir, DirConfigName)
+	resolved, err := cfg.resolve(profiles)
+	if err == nil {
+		err = resolved.Validate()
+	}
+	if err != nil {
+		return nil, i18n.Errorf("invalid %s: %v", file, err)
+	}
+
+	state := &dirState{
+		config:   s.config.Override(resolved),
+		files:    append(append([]string(nil), s.files...), file),
+		excludes: s.excludes,
+	}
+	if len(cfg.Exclude) > 0 {
+		state.excludes = append(append([]excludeRule(nil), s.excludes...), excludeRule{dir: dir, patterns: cfg.Exclude})
+	}
+	return state, nil
+}
@@ -0,0 +1,114 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeDirConfig writes a .compressvideo.yaml into dir under root
+func writeDirConfig(t *testing.T, root, dir, content string) {
+	path := filepath.Join(root, filepath.FromSlash(dir), DirConfigName)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// jobsByInput maps the slash-separated input paths, relative to root, to their jobs
+func jobsByInput(t *testing.T, root string, jobs []Job) map[string]Job {
+	byInput := make(map[string]Job)
+	for _, job := range jobs {
+		rel, err := filepath.Rel(root, job.Input)
+		assert.NoError(t, err)
+		byInput[filepath.ToSlash(rel)] = job
+	}
+	return byInput
+}
+
+func TestPlanDirConfigs(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in")
+	createTree(t, input, "a.mp4", "archive/b.mp4", "archive/2019/c.mp4", "archive/masters/d.mp4",
+		"archive/e.mov.mp4", "phone/f.mp4", "done/g.mp4")
+	writeDirConfig(t, input, "", "quality: 3\nskip_codecs: hevc\n")
+	writeDirConfig(t, input, "archive", "profile: archive\nquality: 2\nexclude:\n  - /masters\n  - \"*.mov.mp4\"\n")
+	writeDirConfig(t, input, "archive/2019", "preset: fast\n")
+	writeDirConfig(t, input, "done", "skip: true\n")
+
+	profiles := map[string]config.Profile{"archive": {Quality: 1, Preset: "thorough", Codec: "hevc"}}
+	jobs, err := Plan(input, filepath.Join(dir, "out"), Options{Recursive: true, Match: isMP4, DirConfigs: true, Profiles: profiles})
+	assert.NoError(t, err)
+
+	byInput := jobsByInput(t, input, jobs)
+	assert.Len(t, byInput, 4)
+	assert.Equal(t, 3, byInput["a.mp4"].Config.Settings.Quality)
+	assert.Equal(t, "hevc", byInput["a.mp4"].Config.SkipCodecs)
+	assert.Equal(t, []string{filepath.Join(input, DirConfigName)}, byInput["a.mp4"].ConfigFiles)
+
+	// The file's own settings win over its profile, deeper files over higher ones
+	archived := byInput["archive/b.mp4"].Config
+	assert.Equal(t, 2, archived.Settings.Quality)
+	assert.Equal(t, "thorough", archived.Settings.Preset)
+	assert.Equal(t, "hevc", archived.Settings.Codec)
+	assert.Equal(t, "hevc", archived.SkipCodecs)
+	assert.Equal(t, "fast", byInput["archive/2019/c.mp4"].Config.Settings.Preset)
+	assert.Equal(t, 2, byInput["archive/2019/c.mp4"].Config.Settings.Quality)
+	assert.Len(t, byInput["archive/2019/c.mp4"].ConfigFiles, 3)
+	assert.Equal(t, 3, byInput["phone/f.mp4"].Config.Settings.Quality)
+
+	// Without DirConfigs the files are ignored
+	jobs, err = Plan(input, filepath.Join(dir, "out"), Options{Recursive: true, Match: isMP4})
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 7)
+	assert.Empty(t, jobs[0].ConfigFiles)
+}
+
+func TestPlanDirConfigFlags(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "clip.mp4")
+	writeDirConfig(t, dir, "", "quality: 2\npreset: fast\n")
+
+	// Flags given on the command line win, and the names follow the settings
+	jobs, err := Plan(dir, filepath.Join(dir, "out"), Options{
+		Match:      isMP4,
+		Template:   "{name}-q{quality}-{preset}{ext}",
+		Name:       NameFields{Quality: 3, Preset: "balanced"},
+		DirConfigs: true,
+		Flags:      DirConfig{Settings: config.Profile{Quality: 5}},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, 5, jobs[0].Config.Settings.Quality)
+	assert.Equal(t, "fast", jobs[0].Config.Settings.Preset)
+	assert.Equal(t, "clip-q5-fast.mp4", filepath.Base(jobs[0].Output))
+}
+
+func TestPlanDirConfigErrors(t *testing.T) {
+	for content, message := range map[string]string{
+		"quality: 9\n":          "quality must be between 1-5",
+		"qualty: 2\n":           "field qualty not found",
+		"profile: missing\n":    "profile missing not found",
+		"exclude: [\"[a\"]\n":   "invalid exclude pattern",
+		"skip_below_bitrate: x": "invalid bitrate",
+	} {
+		dir := t.TempDir()
+		createTree(t, dir, "sub/clip.mp4")
+		writeDirConfig(t, dir, "sub", content)
+
+		_, err := Plan(dir, filepath.Join(dir, "out"), Options{Recursive: true, Match: isMP4, DirConfigs: true})
+		if assert.Error(t, err, content) {
+			assert.Contains(t, err.Error(), message)
+			assert.Contains(t, err.Error(), filepath.Join(dir, "sub", DirConfigName))
+		}
+	}
+
+	// An empty file changes nothing
+	dir := t.TempDir()
+	createTree(t, dir, "clip.mp4")
+	writeDirConfig(t, dir, "", "")
+	jobs, err := Plan(dir, filepath.Join(dir, "out"), Options{Match: isMP4, DirConfigs: true})
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
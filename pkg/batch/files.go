@@ -0,0 +1,113 @@
+package batch
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// sizeUnits maps size suffixes to their multiplier in bytes (binary units)
+var sizeUnits = []struct {
+	suffix     string
+	multiplier float64
+}{
+	{"tb", 1 << 40}, {"t", 1 << 40},
+	{"gb", 1 << 30}, {"g", 1 << 30},
+	{"mb", 1 << 20}, {"m", 1 << 20},
+	{"kb", 1 << 10}, {"k", 1 << 10},
+	{"b", 1},
+}
+
+// ParseSize parses a file size such as "500M", "1.5G" or "2048"; an empty
+// value returns 0
+func ParseSize(value string) (int64, error) {
+	original := value
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.multiplier
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, i18n.Errorf("invalid size: %s", original)
+	}
+	return int64(number * multiplier), nil
+}
+
+// ParseAge parses an age such as "30d", "2w" or "12h", or a date in
+// YYYY-MM-DD form, into the modification time it refers to relative to now.
+// An empty value returns the zero time.
+func ParseAge(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if date, err := time.ParseInLocation("2006-01-02", value, now.Location()); err == nil {
+		return date, nil
+	}
+
+	// time.ParseDuration has no units above hours
+	day := 24 * time.Hour
+	for suffix, unit := range map[string]time.Duration{"d": day, "w": 7 * day} {
+		if strings.HasSuffix(value, suffix) {
+			number, err := strconv.ParseFloat(strings.TrimSuffix(value, suffix), 64)
+			if err != nil || number < 0 {
+				return time.Time{}, i18n.Errorf("invalid age: %s", value)
+			}
+			return now.Add(-time.Duration(number * float64(unit))), nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return time.Time{}, i18n.Errorf("invalid age: %s", value)
+	}
+	return now.Add(-age), nil
+}
+
+// FileFilter selects files by size and modification time. Zero values
+// disable the corresponding check.
+type FileFilter struct {
+	MinSize   int64     // Skip files smaller than this many bytes
+	MaxSize   int64     // Skip files larger than this many bytes
+	OlderThan time.Time // Only keep files modified before this time
+	NewerThan time.Time // Only keep files modified after this time
+}
+
+// Active reports whether any check is enabled
+func (f FileFilter) Active() bool {
+	return f.MinSize > 0 || f.MaxSize > 0 || !f.OlderThan.IsZero() || !f.NewerThan.IsZero()
+}
+
+// Match reports whether a file passes every enabled check
+func (f FileFilter) Match(info os.FileInfo) bool {
+	size := info.Size()
+	if f.MinSize > 0 && size < f.MinSize {
+		return false
+	}
+	if f.MaxSize > 0 && size > f.MaxSize {
+		return false
+	}
+
+	modified := info.ModTime()
+	if !f.OlderThan.IsZero() && !modified.Before(f.OlderThan) {
+		return false
+	}
+	if !f.NewerThan.IsZero() && !modified.After(f.NewerThan) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,97 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSize(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"":     0,
+		"2048": 2048,
+		"500M": 500 << 20,
+		"1.5G": 3 << 29,
+		"10kb": 10 << 10,
+		"2 GB": 2 << 30,
+		"100b": 100,
+	} {
+		size, err := ParseSize(value)
+		assert.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	_, err := ParseSize("big")
+	assert.Error(t, err)
+	_, err = ParseSize("-1M")
+	assert.Error(t, err)
+}
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	cutoff, err := ParseAge("30d", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -30), cutoff)
+
+	cutoff, err = ParseAge("2w", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -14), cutoff)
+
+	cutoff, err = ParseAge("12h", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(-12*time.Hour), cutoff)
+
+	cutoff, err = ParseAge("2024-01-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), cutoff)
+
+	cutoff, err = ParseAge("", now)
+	assert.NoError(t, err)
+	assert.True(t, cutoff.IsZero())
+
+	_, err = ParseAge("soon", now)
+	assert.Error(t, err)
+}
+
+func TestPlanFileFilter(t *testing.T) {
+	dir, err := os.MkdirTemp("", "batch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Now()
+	files := map[string]struct {
+		size int
+		age  time.Duration
+	}{
+		"old-large.mp4": {4096, 90 * 24 * time.Hour},
+		"old-small.mp4": {10, 90 * 24 * time.Hour},
+		"new-large.mp4": {4096, time.Hour},
+	}
+	for name, file := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, make([]byte, file.size), 0644))
+		modified := now.Add(-file.age)
+		assert.NoError(t, os.Chtimes(path, modified, modified))
+	}
+
+	output := filepath.Join(dir, "out")
+	filter := FileFilter{MinSize: 1024, OlderThan: now.AddDate(0, 0, -30)}
+	assert.True(t, filter.Active())
+	jobs, err := Plan(dir, output, Options{Match: isMP4, Files: filter})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old-large-compressed.mp4"}, outputs(t, output, jobs))
+
+	filter = FileFilter{MaxSize: 1024}
+	jobs, err = Plan(dir, output, Options{Match: isMP4, Files: filter})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old-small-compressed.mp4"}, outputs(t, output, jobs))
+
+	filter = FileFilter{NewerThan: now.AddDate(0, 0, -1)}
+	jobs, err = Plan(dir, output, Options{Match: isMP4, Files: filter})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new-large-compressed.mp4"}, outputs(t, output, jobs))
+}
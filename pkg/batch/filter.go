@@ -0,0 +1,89 @@
+package batch
+
+import (
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// codecAliases maps common names of codecs to the names ffprobe reports
+var codecAliases = map[string]string{
+	"h265": "hevc",
+	"x265": "hevc",
+	"h264": "h264",
+	"x264": "h264",
+	"avc":  "h264",
+	"av01": "av1",
+	"vp09": "vp9",
+}
+
+// ParseCodecList splits a comma-separated list of codec names, lower-cased
+// and mapped to the names ffprobe reports
+func ParseCodecList(list string) []string {
+	var codecs []string
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if alias, ok := codecAliases[name]; ok {
+			name = alias
+		}
+		codecs = append(codecs, name)
+	}
+	return codecs
+}
+
+// ParseMinBitrate parses a --skip-below-bitrate value such as "2M" or
+// "800k"; an empty value disables the filter
+func ParseMinBitrate(value string) (int64, error) {
+	if strings.TrimSpace(value) == "" {
+		return 0, nil
+	}
+	bitrate := analyzer.ParseBitrate(value)
+	if bitrate <= 0 {
+		return 0, i18n.Errorf("invalid bitrate: %s", value)
+	}
+	return bitrate, nil
+}
+
+// ProbeFilter skips files whose probed streams make re-encoding pointless
+type ProbeFilter struct {
+	SkipCodecs []string // Video codecs (ffprobe names) that are already efficient
+	MinBitrate int64    // Files below this video bitrate in bits/s are skipped
+}
+
+// Active reports whether the filter needs probe data at all
+func (f ProbeFilter) Active() bool {
+	return len(f.SkipCodecs) > 0 || f.MinBitrate > 0
+}
+
+// SkipReason returns why video should not be compressed, or "" to compress
+// it. The stream bitrate is used when ffprobe reports one, otherwise the
+// overall bitrate of the file.
+func (f ProbeFilter) SkipReason(video *ffmpeg.VideoFile) string {
+	if video == nil {
+		return ""
+	}
+
+	codec := strings.ToLower(video.VideoInfo.Codec)
+	for _, skip := range f.SkipCodecs {
+		if codec == skip {
+			return i18n.T("already encoded with %s", codec)
+		}
+	}
+
+	if f.MinBitrate > 0 {
+		bitrate := video.VideoInfo.BitRate
+		if bitrate <= 0 {
+			bitrate = video.BitRate
+		}
+		if bitrate > 0 && bitrate < f.MinBitrate {
+			return i18n.T("bitrate %.2f Mbps is below %.2f Mbps", float64(bitrate)/1e6, float64(f.MinBitrate)/1e6)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,50 @@
+package batch
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCodecList(t *testing.T) {
+	assert.Equal(t, []string{"hevc", "av1", "vp9"}, ParseCodecList("HEVC, av1,,vp9"))
+	assert.Equal(t, []string{"hevc", "h264"}, ParseCodecList("h265,x264"))
+	assert.Nil(t, ParseCodecList(""))
+}
+
+func TestParseMinBitrate(t *testing.T) {
+	bitrate, err := ParseMinBitrate("2M")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2000000), bitrate)
+
+	bitrate, err = ParseMinBitrate("")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), bitrate)
+
+	_, err = ParseMinBitrate("fast")
+	assert.Error(t, err)
+}
+
+func TestProbeFilterSkipReason(t *testing.T) {
+	filter := ProbeFilter{SkipCodecs: ParseCodecList("hevc,av1"), MinBitrate: 2000000}
+	assert.True(t, filter.Active())
+	assert.False(t, ProbeFilter{}.Active())
+
+	hevc := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Codec: "hevc", BitRate: 8000000}}
+	assert.NotEmpty(t, filter.SkipReason(hevc))
+
+	lowBitrate := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264", BitRate: 1500000}}
+	assert.NotEmpty(t, filter.SkipReason(lowBitrate))
+
+	// Falls back to the file bitrate when the stream has none
+	container := &ffmpeg.VideoFile{BitRate: 1000000, VideoInfo: ffmpeg.VideoStreamInfo{Codec: "mpeg4"}}
+	assert.NotEmpty(t, filter.SkipReason(container))
+
+	large := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264", BitRate: 12000000}}
+	assert.Empty(t, filter.SkipReason(large))
+
+	// Unknown bitrate is not a reason to skip
+	unknown := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264"}}
+	assert.Empty(t, filter.SkipReason(unknown))
+}
@@ -0,0 +1,152 @@
+package batch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"gopkg.in/yaml.v3"
+)
+
+// Job result statuses written to the results manifest
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// JobSpec is one entry of a batch manifest. Zero values are taken from the
+// manifest defaults.
+type JobSpec struct {
+	Input      string  `yaml:"input" json:"input"`
+	Output     string  `yaml:"output,omitempty" json:"output,omitempty"`         // Output file
+	OutputDir  string  `yaml:"output_dir,omitempty" json:"output_dir,omitempty"` // Directory for the output when Output is empty
+	Quality    int     `yaml:"quality,omitempty" json:"quality,omitempty"`       // 1-5
+	Preset     string  `yaml:"preset,omitempty" json:"preset,omitempty"`         // fast, balanced, thorough
+	TargetVMAF float64 `yaml:"target_vmaf,omitempty" json:"target_vmaf,omitempty"`
+}
+
+// Manifest lists the jobs of a batch run
+type Manifest struct {
+	Defaults JobSpec   `yaml:"defaults" json:"defaults"`
+	Jobs     []JobSpec `yaml:"jobs" json:"jobs"`
+}
+
+// JobResult records the outcome of one manifest job
+type JobResult struct {
+	Input            string  `yaml:"input" json:"input"`
+	Output           string  `yaml:"output" json:"output"`
+	Status           string  `yaml:"status" json:"status"`
+	Error            string  `yaml:"error,omitempty" json:"error,omitempty"`
+	ErrorClass       string  `yaml:"error_class,omitempty" json:"error_class,omitempty"` // Kind of error of a failed job
+	Attempts         int     `yaml:"attempts,omitempty" json:"attempts,omitempty"`       // Attempts made at a job that was retried
+	Quarantined      string  `yaml:"quarantined,omitempty" json:"quarantined,omitempty"` // Where a failed source was moved with --quarantine-dir
+	Quality          int     `yaml:"quality" json:"quality"`
+	Preset           string  `yaml:"preset" json:"preset"`
+	OriginalSize     int64   `yaml:"original_size,omitempty" json:"original_size,omitempty"`
+	CompressedSize   int64   `yaml:"compressed_size,omitempty" json:"compressed_size,omitempty"`
+	SavedPercent     float64 `yaml:"saved_percent,omitempty" json:"saved_percent,omitempty"`
+	VMAFScore        float64 `yaml:"vmaf_score,omitempty" json:"vmaf_score,omitempty"`
+	HardwareFallback string  `yaml:"hardware_fallback,omitempty" json:"hardware_fallback,omitempty"` // Failed hardware encode redone in software
+	Recovered        string  `yaml:"recovered,omitempty" json:"recovered,omitempty"`                 // Share of a damaged input salvaged with --ignore-errors
+	Duration         float64 `yaml:"duration_seconds" json:"duration_seconds"`
+	CPUSeconds       float64 `yaml:"cpu_seconds,omitempty" json:"cpu_seconds,omitempty"` // CPU time of the job's FFmpeg processes
+	EnergyWh         float64 `yaml:"energy_wh,omitempty" json:"energy_wh,omitempty"`     // Approximate energy of that CPU time
+}
+
+// LoadManifest reads a YAML or JSON manifest and returns its jobs with the
+// defaults applied and relative paths resolved against the manifest's
+// directory. Jobs without an output are named with nameTemplate.
+func LoadManifest(path, nameTemplate string) ([]JobSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, i18n.Errorf("failed to read manifest: %w", err)
+	}
+
+	// JSON is valid YAML, so one decoder reads both
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, i18n.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if len(manifest.Jobs) == 0 {
+		return nil, i18n.Errorf("manifest %s has no jobs", path)
+	}
+
+	base := filepath.Dir(path)
+	defaults := manifest.Defaults
+	jobs := make([]JobSpec, 0, len(manifest.Jobs))
+	for i, job := range manifest.Jobs {
+		if job.Input == "" {
+			return nil, i18n.Errorf("manifest job %d has no input", i+1)
+		}
+		if job.Quality == 0 {
+			job.Quality = defaults.Quality
+		}
+		if job.Quality == 0 {
+			job.Quality = 3
+		}
+		if job.Preset == "" {
+			job.Preset = defaults.Preset
+		}
+		if job.Preset == "" {
+			job.Preset = "balanced"
+		}
+		if job.TargetVMAF == 0 {
+			job.TargetVMAF = defaults.TargetVMAF
+		}
+		if job.OutputDir == "" {
+			job.OutputDir = defaults.OutputDir
+		}
+
+		job.Input = resolvePath(base, job.Input)
+		if job.Output == "" {
+			dir := filepath.Dir(job.Input)
+			if job.OutputDir != "" {
+				dir = resolvePath(base, job.OutputDir)
+			}
+			name := OutputName(nameTemplate, filepath.Base(job.Input), NameFields{Quality: job.Quality, Preset: job.Preset})
+			job.Output = filepath.Join(dir, name)
+		} else {
+			job.Output = resolvePath(base, job.Output)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// resolvePath makes path relative to base unless it is absolute
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+// ResultsPath returns the default results manifest path for a manifest,
+// e.g. jobs.yaml -> jobs.results.yaml
+func ResultsPath(manifestPath string) string {
+	ext := filepath.Ext(manifestPath)
+	return strings.TrimSuffix(manifestPath, ext) + ".results" + ext
+}
+
+// WriteResults writes the results manifest, as JSON when path ends in .json
+// and as YAML otherwise
+func WriteResults(path string, results []JobResult) error {
+	document := struct {
+		Results []JobResult `yaml:"results" json:"results"`
+	}{results}
+
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err = json.MarshalIndent(document, "", "  ")
+	} else {
+		data, err = yaml.Marshal(document)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
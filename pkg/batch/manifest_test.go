@@ -0,0 +1,100 @@
+package batch
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLoadManifestYAML(t *testing.T) {
+	dir, err := os.MkdirTemp("", "manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jobs.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(`
+defaults:
+  quality: 3
+  preset: balanced
+  output_dir: out
+jobs:
+  - input: talks/keynote.mp4
+    quality: 4
+  - input: /media/raw.mkv
+    output: /archive/raw.mkv
+    preset: thorough
+    target_vmaf: 93
+`), 0644))
+
+	jobs, err := LoadManifest(path, DefaultNameTemplate)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 2)
+
+	assert.Equal(t, filepath.Join(dir, "talks", "keynote.mp4"), jobs[0].Input)
+	assert.Equal(t, filepath.Join(dir, "out", "keynote-compressed.mp4"), jobs[0].Output)
+	assert.Equal(t, 4, jobs[0].Quality)
+	assert.Equal(t, "balanced", jobs[0].Preset)
+
+	assert.Equal(t, "/media/raw.mkv", jobs[1].Input)
+	assert.Equal(t, "/archive/raw.mkv", jobs[1].Output)
+	assert.Equal(t, 3, jobs[1].Quality)
+	assert.Equal(t, "thorough", jobs[1].Preset)
+	assert.Equal(t, 93.0, jobs[1].TargetVMAF)
+}
+
+func TestLoadManifestJSON(t *testing.T) {
+	dir, err := os.MkdirTemp("", "manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "jobs.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"jobs": [{"input": "a.mp4"}]}`), 0644))
+
+	jobs, err := LoadManifest(path, DefaultNameTemplate)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "a-compressed.mp4"), jobs[0].Output)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"jobs": [{"output": "a.mp4"}]}`), 0644))
+	_, err = LoadManifest(path, DefaultNameTemplate)
+	assert.Error(t, err)
+
+	assert.NoError(t, os.WriteFile(path, []byte(`{"jobs": []}`), 0644))
+	_, err = LoadManifest(path, DefaultNameTemplate)
+	assert.Error(t, err)
+}
+
+func TestWriteResults(t *testing.T) {
+	dir, err := os.MkdirTemp("", "manifest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, filepath.Join(dir, "jobs.results.yaml"), ResultsPath(filepath.Join(dir, "jobs.yaml")))
+
+	results := []JobResult{
+		{Input: "a.mp4", Output: "a-compressed.mp4", Status: StatusSuccess, Quality: 3, Preset: "balanced", SavedPercent: 42.5},
+		{Input: "b.mp4", Output: "b-compressed.mp4", Status: StatusFailed, Error: "boom", Quality: 3, Preset: "balanced"},
+	}
+
+	var document struct {
+		Results []JobResult `yaml:"results" json:"results"`
+	}
+
+	jsonPath := filepath.Join(dir, "jobs.results.json")
+	assert.NoError(t, WriteResults(jsonPath, results))
+	data, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &document))
+	assert.Equal(t, results, document.Results)
+
+	yamlPath := filepath.Join(dir, "jobs.results.yaml")
+	assert.NoError(t, WriteResults(yamlPath, results))
+	data, err = os.ReadFile(yamlPath)
+	assert.NoError(t, err)
+	document.Results = nil
+	assert.NoError(t, yaml.Unmarshal(data, &document))
+	assert.Equal(t, results, document.Results)
+}
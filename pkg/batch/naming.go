@@ -0,0 +1,165 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// DefaultNameTemplate names each output after its input: clip.mp4 becomes
+// clip-compressed.mp4
+const DefaultNameTemplate = "{name}-compressed{ext}"
+
+// CodecPlaceholder stands for the output's video codec. The codec is chosen
+// by the content analysis, so the placeholder stays in planned names until
+// ExpandCodec fills it in.
+const CodecPlaceholder = "{codec}"
+
+// namePlaceholders maps each placeholder to a pattern matching its values
+var namePlaceholders = map[string]string{
+	"{name}":         `.+`,
+	"{ext}":          `\.[^.]+`,
+	CodecPlaceholder: `[a-z0-9]+`,
+	"{quality}":      `[1-5]`,
+	"{preset}":       `[a-z]+`,
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{[^{}]*\}`)
+	extensionRegex   = regexp.MustCompile(`^\.[A-Za-z0-9]+$`)
+)
+
+// NameFields are the values of the placeholders other than {name} and {ext}
+type NameFields struct {
+	Quality int    // Quality level (1-5)
+	Preset  string // Compression preset
+	Codec   string // Encoder from the compression settings, empty while unknown
+	Ext     string // Extension of the output, e.g. .mp4 for --remux-only mp4, empty for the input's
+}
+
+// ValidateNameTemplate checks that template uses {name}, only known
+// placeholders and no directories
+func ValidateNameTemplate(template string) error {
+	if !strings.Contains(template, "{name}") {
+		return i18n.Errorf("name template must contain {name}: %s", template)
+	}
+	if strings.ContainsAny(template, `/\`) {
+		return i18n.Errorf("name template must not contain directories: %s", template)
+	}
+	for _, placeholder := range placeholderRegex.FindAllString(template, -1) {
+		if _, ok := namePlaceholders[placeholder]; !ok {
+			return i18n.Errorf("unknown placeholder %s in name template (use {name}, {ext}, {codec}, {quality} or {preset})", placeholder)
+		}
+	}
+	return nil
+}
+
+// OutputName expands template for the file name input. {codec} is kept when
+// fields.Codec is empty.
+func OutputName(template, input string, fields NameFields) string {
+	if template == "" {
+		template = DefaultNameTemplate
+	}
+	ext := filepath.Ext(input)
+	outputExt := ext
+	if fields.Ext != "" {
+		outputExt = fields.Ext
+	}
+	codec := CodecPlaceholder
+	if fields.Codec != "" {
+		codec = CodecName(fields.Codec)
+	}
+	return strings.NewReplacer(
+		"{name}", strings.TrimSuffix(input, ext),
+		"{ext}", outputExt,
+		CodecPlaceholder, codec,
+		"{quality}", strconv.Itoa(fields.Quality),
+		"{preset}", fields.Preset,
+	).Replace(template)
+}
+
+// ExpandCodec fills the {codec} placeholder left in the file name of path
+func ExpandCodec(path, encoder string) string {
+	dir, name := filepath.Split(path)
+	return dir + strings.ReplaceAll(name, CodecPlaceholder, CodecName(encoder))
+}
+
+// ExistingOutput returns the existing file that path names. A {codec} in
+// the file name matches any codec, so a rerun finds outputs whose codec was
+// chosen by an earlier analysis.
+func ExistingOutput(path string) (string, bool) {
+	dir, name := filepath.Split(path)
+	if !strings.Contains(name, CodecPlaceholder) {
+		_, err := os.Stat(path)
+		return path, err == nil
+	}
+
+	parts := strings.Split(name, CodecPlaceholder)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	re := regexp.MustCompile("^" + strings.Join(parts, namePlaceholders[CodecPlaceholder]) + "$")
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+	for _, entry := range entries {
+		if re.MatchString(entry.Name()) {
+			return filepath.Join(dir, entry.Name()), true
+		}
+	}
+	return "", false
+}
+
+// CodecName returns the short name of the codec an encoder produces, the
+// same for software and hardware encoders: libx265 and hevc_nvenc are hevc
+func CodecName(encoder string) string {
+	switch {
+	case encoder == "copy":
+		return "copy"
+	case strings.Contains(encoder, "264"):
+		return "h264"
+	case strings.Contains(encoder, "265"), strings.HasPrefix(encoder, "hevc"):
+		return "hevc"
+	case strings.Contains(encoder, "vp9"):
+		return "vp9"
+	case strings.Contains(encoder, "av1"):
+		return "av1"
+	}
+	return strings.TrimPrefix(strings.ToLower(encoder), "lib")
+}
+
+// IsOutputName reports whether the file name looks like an output of
+// template, so directory runs leave earlier outputs alone. Templates that
+// add nothing to the input name but an extension would match every input,
+// so they never match.
+func IsOutputName(template, name string) bool {
+	if template == "" {
+		template = DefaultNameTemplate
+	}
+	rest := strings.NewReplacer("{name}", "", "{ext}", "").Replace(template)
+	if rest == "" || extensionRegex.MatchString(rest) {
+		return false
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	last := 0
+	for _, loc := range placeholderRegex.FindAllStringIndex(template, -1) {
+		pattern.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		pattern.WriteString(namePlaceholders[template[loc[0]:loc[1]]])
+		last = loc[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(template[last:]))
+	pattern.WriteString("$")
+
+	re, err := regexp.Compile(pattern.String())
+	return err == nil && re.MatchString(name)
+}
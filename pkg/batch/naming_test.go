@@ -0,0 +1,70 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputName(t *testing.T) {
+	fields := NameFields{Quality: 4, Preset: "thorough"}
+	assert.Equal(t, "clip-compressed.mp4", OutputName("", "clip.mp4", fields))
+	assert.Equal(t, "clip_compressed.mkv", OutputName("{name}_compressed{ext}", "clip.mkv", fields))
+	assert.Equal(t, "clip-thorough-q4.mp4", OutputName("{name}-{preset}-q{quality}{ext}", "clip.mp4", fields))
+
+	// The codec is filled in once the analysis has chosen it
+	name := OutputName("{name}-{codec}-q{quality}{ext}", "clip.mp4", fields)
+	assert.Equal(t, "clip-{codec}-q4.mp4", name)
+	path := filepath.Join("out", "{codec}", name)
+	assert.Equal(t, filepath.Join("out", "{codec}", "clip-hevc-q4.mp4"), ExpandCodec(path, "hevc_nvenc"))
+	fields.Codec = "libvpx-vp9"
+	assert.Equal(t, "clip-vp9-q4.mp4", OutputName("{name}-{codec}-q{quality}{ext}", "clip.mp4", fields))
+
+	// --remux-only names the outputs after their new container
+	fields.Ext = ".mp4"
+	assert.Equal(t, "clip-compressed.mp4", OutputName("", "clip.mkv", fields))
+	fields.Ext = ""
+
+	// Placeholders in the input name are not expanded again
+	assert.Equal(t, "{ext}-compressed.mp4", OutputName("", "{ext}.mp4", fields))
+}
+
+func TestValidateNameTemplate(t *testing.T) {
+	assert.NoError(t, ValidateNameTemplate(DefaultNameTemplate))
+	assert.NoError(t, ValidateNameTemplate("{name}-{codec}-q{quality}{ext}"))
+	assert.Error(t, ValidateNameTemplate("compressed{ext}"))
+	assert.Error(t, ValidateNameTemplate("out/{name}{ext}"))
+	assert.Error(t, ValidateNameTemplate("{name}-{resolution}{ext}"))
+}
+
+func TestIsOutputName(t *testing.T) {
+	assert.True(t, IsOutputName("", "clip-compressed.mp4"))
+	assert.False(t, IsOutputName("", "clip.mp4"))
+	assert.True(t, IsOutputName("{name}_compressed{ext}", "clip_compressed.mp4"))
+	assert.False(t, IsOutputName("{name}_compressed{ext}", "clip-compressed.mp4"))
+	assert.True(t, IsOutputName("{name}-{codec}-q{quality}{ext}", "clip-h264-q3.mp4"))
+	assert.False(t, IsOutputName("{name}-{codec}-q{quality}{ext}", "clip-final.mp4"))
+
+	// Templates that keep the input name cannot tell outputs from inputs
+	assert.False(t, IsOutputName("{name}{ext}", "clip.mp4"))
+	assert.False(t, IsOutputName("{name}.mkv", "clip.mkv"))
+}
+
+func TestExistingOutput(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "clip-hevc-q3.mp4", "talk-compressed.mp4")
+
+	path, ok := ExistingOutput(filepath.Join(dir, "clip-{codec}-q3.mp4"))
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "clip-hevc-q3.mp4"), path)
+	_, ok = ExistingOutput(filepath.Join(dir, "clip-{codec}-q4.mp4"))
+	assert.False(t, ok)
+
+	_, ok = ExistingOutput(filepath.Join(dir, "talk-compressed.mp4"))
+	assert.True(t, ok)
+	assert.NoError(t, os.Remove(filepath.Join(dir, "talk-compressed.mp4")))
+	_, ok = ExistingOutput(filepath.Join(dir, "talk-compressed.mp4"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,224 @@
+package batch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Statuses of the files of a directory run in its progress file
+const (
+	ProgressPending   = "pending"
+	ProgressCompleted = "completed"
+	ProgressFailed    = "failed"
+)
+
+// ProgressEntry records how far a directory run got with one input
+type ProgressEntry struct {
+	Status     string    `json:"status"`
+	Output     string    `json:"output"`
+	Error      string    `json:"error,omitempty"`
+	ErrorClass string    `json:"error_class,omitempty"` // Kind of error of a failure
+	Attempts   int       `json:"attempts,omitempty"`    // Attempts the last run made at a failure
+	Size       int64     `json:"size"`                  // Size of the input when it was completed
+	ModTime    time.Time `json:"mod_time"`              // Modification time of the input when it was completed
+	Updated    time.Time `json:"updated"`
+}
+
+// Progress is the progress file of a directory run: the status of each of
+// its inputs, keyed by their absolute path, saved after every file so the
+// same command can resume where it stopped, from any working directory
+type Progress struct {
+	Input   string                    `json:"input"`
+	Output  string                    `json:"output"`
+	Params  string                    `json:"params"` // Hash of the options the outputs are encoded with
+	Started time.Time                 `json:"started"`
+	Updated time.Time                 `json:"updated"`
+	Files   map[string]*ProgressEntry `json:"files"`
+
+	path string
+}
+
+// ProgressDir returns the directory progress files are written to
+func ProgressDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".compressvideo", "progress")
+}
+
+// ProgressPath returns the progress file in dir of the run that compresses
+// inputDir into outputDir with the options hashed as params. Runs of the
+// same command share it; any other directory or option starts another.
+func ProgressPath(dir, inputDir, outputDir, params string) string {
+	if abs, err := filepath.Abs(inputDir); err == nil {
+		inputDir = abs
+	}
+	if abs, err := filepath.Abs(outputDir); err == nil {
+		outputDir = abs
+	}
+	sum := sha256.Sum256([]byte(inputDir + "\x00" + outputDir + "\x00" + params))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// NewProgress returns the progress of a new run of jobs, all pending, to be
+// saved at path
+func NewProgress(path, inputDir, outputDir, params string, jobs []Job) *Progress {
+	now := time.Now()
+	p := &Progress{
+		Input:   inputDir,
+		Output:  outputDir,
+		Params:  params,
+		Started: now,
+		Updated: now,
+		Files:   make(map[string]*ProgressEntry, len(jobs)),
+		path:    path,
+	}
+	p.Plan(jobs)
+	return p
+}
+
+// LoadProgress reads the progress file at path. A missing file is reported
+// by os.IsNotExist.
+func LoadProgress(path string) (*Progress, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &Progress{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	// Files of older runs may be keyed by the paths as they were typed
+	files := make(map[string]*ProgressEntry, len(p.Files))
+	for input, entry := range p.Files {
+		files[progressKey(input)] = entry
+	}
+	p.Files = files
+	p.path = path
+	return p, nil
+}
+
+// progressKey returns the key of input in Progress.Files, its absolute path
+func progressKey(input string) string {
+	if abs, err := filepath.Abs(input); err == nil {
+		return abs
+	}
+	return input
+}
+
+// Plan adds the inputs of jobs the progress does not know yet as pending,
+// with their planned outputs. Inputs it knows keep their status.
+func (p *Progress) Plan(jobs []Job) {
+	for _, job := range jobs {
+		key := progressKey(job.Input)
+		if entry, ok := p.Files[key]; ok {
+			if entry.Status != ProgressCompleted {
+				entry.Output = job.Output
+			}
+			continue
+		}
+		p.Files[key] = &ProgressEntry{Status: ProgressPending, Output: job.Output}
+	}
+}
+
+// Completed reports whether input was completed by a run, and has not
+// changed since. Its output may have been moved or deleted since.
+func (p *Progress) Completed(input string) bool {
+	entry, ok := p.Files[progressKey(input)]
+	if !ok || entry.Status != ProgressCompleted {
+		return false
+	}
+	stat, err := os.Stat(input)
+	if err != nil {
+		return false
+	}
+	return stat.Size() == entry.Size && stat.ModTime().Equal(entry.ModTime)
+}
+
+// Failed reports whether the last attempt at input failed
+func (p *Progress) Failed(input string) bool {
+	entry, ok := p.Files[progressKey(input)]
+	return ok && entry.Status == ProgressFailed
+}
+
+// Set records the status of input and saves the progress. Failures are
+// recorded with Fail.
+func (p *Progress) Set(input, status string) error {
+	key := progressKey(input)
+	entry, ok := p.Files[key]
+	if !ok {
+		entry = &ProgressEntry{}
+		p.Files[key] = entry
+	}
+	entry.Status = status
+	entry.Error, entry.ErrorClass, entry.Attempts = "", "", 0
+	if status == ProgressCompleted {
+		if stat, err := os.Stat(input); err == nil {
+			entry.Size, entry.ModTime = stat.Size(), stat.ModTime()
+		}
+	}
+	entry.Updated = time.Now()
+	p.Updated = entry.Updated
+	return p.Save()
+}
+
+// Fail records failure as the status of its input and saves the progress
+func (p *Progress) Fail(failure Failure) error {
+	key := progressKey(failure.Input)
+	entry, ok := p.Files[key]
+	if !ok {
+		entry = &ProgressEntry{}
+		p.Files[key] = entry
+	}
+	entry.Status = ProgressFailed
+	entry.Error, entry.ErrorClass, entry.Attempts = failure.Error, failure.Class, failure.Attempts
+	entry.Updated = failure.Time
+	p.Updated = entry.Updated
+	return p.Save()
+}
+
+// Counts returns how many inputs are completed, failed and pending
+func (p *Progress) Counts() (completed, failed, pending int) {
+	for _, entry := range p.Files {
+		switch entry.Status {
+		case ProgressCompleted:
+			completed++
+		case ProgressFailed:
+			failed++
+		default:
+			pending++
+		}
+	}
+	return completed, failed, pending
+}
+
+// Path returns the file the progress is saved to
+func (p *Progress) Path() string {
+	return p.path
+}
+
+// Save writes the progress file. It is written to a temporary file first
+// and renamed over the old one, so an interrupted run leaves a whole file.
+func (p *Progress) Save() error {
+	if err := os.MkdirAll(filepath.Dir(p.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp := p.path + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(temp, p.path); err != nil {
+		os.Remove(temp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressPath(t *testing.T) {
+	dir := t.TempDir()
+	path := ProgressPath(dir, "videos", "videos_compressed", "abc")
+	assert.Equal(t, dir, filepath.Dir(path))
+	assert.Equal(t, ".json", filepath.Ext(path))
+
+	assert.Equal(t, path, ProgressPath(dir, "videos", "videos_compressed", "abc"))
+	assert.NotEqual(t, path, ProgressPath(dir, "videos", "videos_compressed", "def"))
+	assert.NotEqual(t, path, ProgressPath(dir, "videos", "elsewhere", "abc"))
+	assert.NotEqual(t, path, ProgressPath(dir, "movies", "videos_compressed", "abc"))
+}
+
+func TestProgressResume(t *testing.T) {
+	dir := t.TempDir()
+	inputs := make([]string, 3)
+	jobs := make([]Job, 3)
+	for i, name := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		inputs[i] = filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(inputs[i], []byte(name), 0644))
+		jobs[i] = Job{Input: inputs[i], Output: filepath.Join(dir, "out", name)}
+	}
+
+	path := filepath.Join(dir, "progress", "run.json")
+	progress := NewProgress(path, dir, filepath.Join(dir, "out"), "abc", jobs)
+	completed, failed, pending := progress.Counts()
+	assert.Equal(t, []int{0, 0, 3}, []int{completed, failed, pending})
+
+	assert.NoError(t, progress.Set(inputs[0], ProgressCompleted))
+	assert.NoError(t, progress.Fail(Failure{Input: inputs[1], Class: "encoder", Error: "encoder crashed", Attempts: 3, Time: time.Now()}))
+
+	// The next run reads what this one saved
+	resumed, err := LoadProgress(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", resumed.Params)
+	assert.True(t, resumed.Completed(inputs[0]))
+	assert.False(t, resumed.Completed(inputs[1]))
+	assert.True(t, resumed.Failed(inputs[1]))
+	assert.Equal(t, "encoder crashed", resumed.Files[inputs[1]].Error)
+	assert.Equal(t, "encoder", resumed.Files[inputs[1]].ErrorClass)
+	assert.Equal(t, 3, resumed.Files[inputs[1]].Attempts)
+	assert.False(t, resumed.Completed(inputs[2]))
+	assert.False(t, resumed.Failed(inputs[2]))
+
+	// Files added since are pending, the others keep their status
+	added := Job{Input: filepath.Join(dir, "d.mp4"), Output: filepath.Join(dir, "out", "d.mp4")}
+	resumed.Plan(append(jobs, added))
+	completed, failed, pending = resumed.Counts()
+	assert.Equal(t, []int{1, 1, 2}, []int{completed, failed, pending})
+
+	// A retried failure clears its error
+	assert.NoError(t, resumed.Set(inputs[1], ProgressCompleted))
+	assert.True(t, resumed.Completed(inputs[1]))
+	assert.Empty(t, resumed.Files[inputs[1]].Error)
+	assert.Empty(t, resumed.Files[inputs[1]].ErrorClass)
+
+	// A completed input that changed since is done again
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(inputs[0], later, later))
+	assert.False(t, resumed.Completed(inputs[0]))
+
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadProgressMissing(t *testing.T) {
+	_, err := LoadProgress(filepath.Join(t.TempDir(), "none.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestProgressRelativePaths(t *testing.T) {
+	// The working directory has symbolic links resolved, e.g. on macOS
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "videos"), 0755))
+	input := filepath.Join(dir, "videos", "a.mp4")
+	assert.NoError(t, os.WriteFile(input, []byte("a"), 0644))
+
+	// A run started in dir with ./videos/a.mp4...
+	t.Chdir(dir)
+	path := filepath.Join(dir, "progress.json")
+	progress := NewProgress(path, "videos", "out", "abc", []Job{{Input: "./videos/a.mp4", Output: "out/a.mp4"}})
+	assert.NoError(t, progress.Set("./videos/a.mp4", ProgressCompleted))
+
+	// ...is resumed from inside videos with a.mp4
+	t.Chdir(filepath.Join(dir, "videos"))
+	resumed, err := LoadProgress(path)
+	assert.NoError(t, err)
+	assert.True(t, resumed.Completed("a.mp4"))
+	assert.True(t, resumed.Completed(input))
+	resumed.Plan([]Job{{Input: "a.mp4", Output: "../out/a.mp4"}})
+	completed, _, pending := resumed.Counts()
+	assert.Equal(t, []int{1, 0}, []int{completed, pending})
+}
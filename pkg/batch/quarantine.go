@@ -0,0 +1,121 @@
+package batch
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Failure describes a source that could not be compressed
+type Failure struct {
+	Input    string
+	Class    string // Kind of error, e.g. truncated or encoder
+	Error    string
+	Attempts int
+	Time     time.Time
+}
+
+// sourceFailureClasses are the error classes of failures that point at a
+// bad source. Others, such as a full disk or a missing encoder, say nothing
+// about the source and would move every file of a batch.
+var sourceFailureClasses = []string{"truncated", "invalid_data", "pixel_format", "verification"}
+
+// QuarantineNoteSuffix is appended to the name of a quarantined source for
+// the note that says why it failed
+const QuarantineNoteSuffix = ".error.txt"
+
+// Quarantine moves the source of failure into dir, under its path relative
+// to root (or its name alone when it is not under root), and writes a note
+// next to it with the error. It returns the new path of the source. Sources
+// are never overwritten: a name already taken gets a number. Sources whose
+// failure is not their own fault, see Quarantinable, are left in place and
+// the returned path is empty.
+func Quarantine(dir, root string, failure Failure) (string, error) {
+	if !Quarantinable(failure.Class) {
+		return "", nil
+	}
+	name, err := filepath.Rel(root, failure.Input)
+	if err != nil || name == "." || strings.HasPrefix(name, "..") {
+		name = filepath.Base(failure.Input)
+	}
+	target := FreeName(filepath.Join(dir, name))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return "", err
+	}
+	if err := moveFile(failure.Input, target); err != nil {
+		return "", err
+	}
+
+	note := fmt.Sprintf("source: %s\nerror class: %s\nattempts: %d\nfailed at: %s\n\n%s\n",
+		failure.Input, failure.Class, failure.Attempts, failure.Time.Format(time.RFC3339), failure.Error)
+	return target, os.WriteFile(target+QuarantineNoteSuffix, []byte(note), 0644)
+}
+
+// Quarantinable reports whether a failure of class points at a bad source,
+// which belongs in quarantine
+func Quarantinable(class string) bool {
+	for _, source := range sourceFailureClasses {
+		if class == source {
+			return true
+		}
+	}
+	return false
+}
+
+// FreeName returns path, or path with a number before its extension when
+// a file of that name exists
+func FreeName(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// moveFile renames src to dst, copying it when they are on different
+// file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile copies src to dst, keeping its permissions and times
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, info.ModTime(), info.ModTime())
+}
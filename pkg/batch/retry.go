@@ -0,0 +1,76 @@
+package batch
+
+import (
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Settings a failed file can be retried with
+const (
+	RetrySoftware     = "software"      // Decode and encode on the CPU instead of the GPU
+	RetryIgnoreErrors = "ignore-errors" // Decode past damaged data, as --ignore-errors
+)
+
+// RetryAlternatives lists the settings a failed file can be retried with
+var RetryAlternatives = []string{RetrySoftware, RetryIgnoreErrors}
+
+// maxRetryDelay caps the wait before a retry
+const maxRetryDelay = time.Hour
+
+// RetryPolicy decides how often and how files that fail are tried again
+type RetryPolicy struct {
+	Retries int           // Attempts after the first one
+	Backoff time.Duration // Wait before the first retry, doubled before each next one
+	With    []string      // Alternatives, see RetryAlternatives; each retry adds the next one
+}
+
+// ParseRetryWith parses a comma-separated list of RetryAlternatives
+func ParseRetryWith(value string) ([]string, error) {
+	var with []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		valid := false
+		for _, alternative := range RetryAlternatives {
+			valid = valid || name == alternative
+		}
+		if !valid {
+			return nil, i18n.Errorf("retry-with must be a list of: %s (got %s)", strings.Join(RetryAlternatives, ", "), name)
+		}
+		with = append(with, name)
+	}
+	return with, nil
+}
+
+// Delay returns how long to wait before retry, counted from 1: Backoff
+// before the first and twice the last wait before each next one, at most
+// an hour
+func (p RetryPolicy) Delay(retry int) time.Duration {
+	if p.Backoff <= 0 || retry < 1 {
+		return 0
+	}
+	delay := p.Backoff
+	for i := 1; i < retry; i++ {
+		delay *= 2
+		if delay >= maxRetryDelay {
+			return maxRetryDelay
+		}
+	}
+	return delay
+}
+
+// Alternatives returns the settings of retry, counted from 1: the first
+// retry-th alternatives of With, or all of them once each has been added
+func (p RetryPolicy) Alternatives(retry int) []string {
+	if retry < 1 {
+		return nil
+	}
+	if retry > len(p.With) {
+		retry = len(p.With)
+	}
+	return p.With[:retry]
+}
@@ -0,0 +1,101 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRetryWith(t *testing.T) {
+	with, err := ParseRetryWith("")
+	assert.NoError(t, err)
+	assert.Empty(t, with)
+
+	with, err = ParseRetryWith("Software, ignore-errors")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{RetrySoftware, RetryIgnoreErrors}, with)
+
+	_, err = ParseRetryWith("software,slower")
+	assert.Error(t, err)
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := RetryPolicy{Retries: 4, Backoff: 30 * time.Second, With: []string{RetrySoftware, RetryIgnoreErrors}}
+
+	assert.Equal(t, 30*time.Second, policy.Delay(1))
+	assert.Equal(t, time.Minute, policy.Delay(2))
+	assert.Equal(t, 2*time.Minute, policy.Delay(3))
+	assert.Equal(t, time.Hour, policy.Delay(20))
+	assert.Equal(t, time.Duration(0), RetryPolicy{Retries: 2}.Delay(1))
+
+	assert.Empty(t, policy.Alternatives(0))
+	assert.Equal(t, []string{RetrySoftware}, policy.Alternatives(1))
+	assert.Equal(t, []string{RetrySoftware, RetryIgnoreErrors}, policy.Alternatives(2))
+	assert.Equal(t, []string{RetrySoftware, RetryIgnoreErrors}, policy.Alternatives(4))
+	assert.Empty(t, RetryPolicy{Retries: 2}.Alternatives(1))
+}
+
+func TestQuarantine(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "quarantine")
+	input := filepath.Join(root, "trips", "beach.mp4")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(input), 0755))
+	assert.NoError(t, os.WriteFile(input, []byte("broken"), 0644))
+
+	failure := Failure{Input: input, Class: "truncated", Error: "input file is truncated", Attempts: 3, Time: time.Now()}
+	target, err := Quarantine(dir, root, failure)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "trips", "beach.mp4"), target)
+
+	_, err = os.Stat(input)
+	assert.True(t, os.IsNotExist(err))
+	data, err := os.ReadFile(target)
+	assert.NoError(t, err)
+	assert.Equal(t, "broken", string(data))
+
+	note, err := os.ReadFile(target + QuarantineNoteSuffix)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(note), "error class: truncated"))
+	assert.True(t, strings.Contains(string(note), "attempts: 3"))
+	assert.True(t, strings.Contains(string(note), "input file is truncated"))
+
+	// A source of the same name does not replace the first
+	assert.NoError(t, os.WriteFile(input, []byte("broken again"), 0644))
+	target, err = Quarantine(dir, root, failure)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "trips", "beach-2.mp4"), target)
+
+	// Sources outside root keep their name alone
+	outside := filepath.Join(t.TempDir(), "other.mov")
+	assert.NoError(t, os.WriteFile(outside, []byte("x"), 0644))
+	failure.Input = outside
+	target, err = Quarantine(dir, root, failure)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "other.mov"), target)
+}
+
+func TestQuarantineLeavesSourcesOfOtherFailures(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(t.TempDir(), "quarantine")
+	input := filepath.Join(root, "beach.mp4")
+	assert.NoError(t, os.WriteFile(input, []byte("fine"), 0644))
+
+	// A full disk, a missing encoder or an unknown error is not the source's fault
+	for _, class := range []string{"disk_full", "encoder", "permission", "other"} {
+		target, err := Quarantine(dir, root, Failure{Input: input, Class: class, Time: time.Now()})
+		assert.NoError(t, err)
+		assert.Equal(t, "", target, class)
+		_, err = os.Stat(input)
+		assert.NoError(t, err, class)
+	}
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	assert.True(t, Quarantinable("invalid_data"))
+	assert.True(t, Quarantinable("pixel_format"))
+	assert.False(t, Quarantinable("timeout"))
+}
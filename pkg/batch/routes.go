@@ -0,0 +1,78 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// ValidateRoutes checks that each route has an output directory and
+// conditions that can match
+func ValidateRoutes(routes []config.Route) error {
+	for i, route := range routes {
+		if strings.TrimSpace(route.Output) == "" {
+			return i18n.Errorf("route %d has no output directory", i+1)
+		}
+		if route.Content != "" {
+			if _, ok := analyzer.ParseContentType(route.Content); !ok {
+				return i18n.Errorf("route %d: content must be one of: animation, screencast, gaming, live_action, sports_action, documentary, unknown (got %s)", i+1, route.Content)
+			}
+		}
+		if route.MinHeight < 0 || route.MaxHeight < 0 || (route.MaxHeight > 0 && route.MinHeight > route.MaxHeight) {
+			return i18n.Errorf("route %d: min_height and max_height must be a range of lines, e.g. 2160 (got %d-%d)", i+1, route.MinHeight, route.MaxHeight)
+		}
+	}
+	return nil
+}
+
+// RouteOutput returns where the first route matching a video of contentType
+// and height sends output, or output when none matches. The path of output
+// under root is kept below the route's directory, so the outputs of a tree
+// stay apart; outputs outside root keep only their name.
+func RouteOutput(routes []config.Route, output, root string, contentType analyzer.ContentType, height int) string {
+	for _, route := range routes {
+		if !routeMatches(route, contentType, height) {
+			continue
+		}
+		rel := filepath.Base(output)
+		if root != "" {
+			if r, err := filepath.Rel(root, output); err == nil && r != ".." && !strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+				rel = r
+			}
+		}
+		return filepath.Join(expandHome(route.Output), rel)
+	}
+	return output
+}
+
+// routeMatches reports whether each condition of route holds for a video
+func routeMatches(route config.Route, contentType analyzer.ContentType, height int) bool {
+	if route.Content != "" {
+		if want, _ := analyzer.ParseContentType(route.Content); want != contentType {
+			return false
+		}
+	}
+	if route.MinHeight > 0 && height < route.MinHeight {
+		return false
+	}
+	if route.MaxHeight > 0 && height > route.MaxHeight {
+		return false
+	}
+	return true
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, `~\`) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
@@ -0,0 +1,65 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteOutput(t *testing.T) {
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+
+	routes := []config.Route{
+		{Content: "Screencast", Output: "~/Videos/screencasts"},
+		{MinHeight: 2160, Output: "/mnt/big/4k"},
+		{Content: "live-action", MaxHeight: 720, Output: "/library/small"},
+	}
+	root := filepath.FromSlash("/out")
+	output := filepath.FromSlash("/out/trip/clip.mp4")
+
+	// The first matching route wins and the path under the root is kept
+	assert.Equal(t, filepath.Join(home, "Videos", "screencasts", "trip", "clip.mp4"),
+		RouteOutput(routes, output, root, analyzer.ContentTypeScreencast, 2160))
+	assert.Equal(t, filepath.FromSlash("/mnt/big/4k/trip/clip.mp4"), RouteOutput(routes, output, root, analyzer.ContentTypeGaming, 2160))
+	assert.Equal(t, filepath.FromSlash("/library/small/trip/clip.mp4"), RouteOutput(routes, output, root, analyzer.ContentTypeLiveAction, 720))
+
+	// Outputs no route matches stay where they were planned
+	assert.Equal(t, output, RouteOutput(routes, output, root, analyzer.ContentTypeLiveAction, 1080))
+	assert.Equal(t, output, RouteOutput(nil, output, root, analyzer.ContentTypeScreencast, 1080))
+
+	// Without a root, or outside it, only the name is kept
+	assert.Equal(t, filepath.FromSlash("/mnt/big/4k/clip.mp4"), RouteOutput(routes, output, "", analyzer.ContentTypeGaming, 2160))
+	assert.Equal(t, filepath.FromSlash("/mnt/big/4k/clip.mp4"), RouteOutput(routes, output, filepath.FromSlash("/elsewhere"), analyzer.ContentTypeGaming, 2160))
+}
+
+func TestValidateRoutes(t *testing.T) {
+	assert.NoError(t, ValidateRoutes(nil))
+	assert.NoError(t, ValidateRoutes([]config.Route{{Content: "sports_action", MinHeight: 1080, MaxHeight: 2160, Output: "/sports"}}))
+
+	for _, route := range []config.Route{
+		{Content: "screencast"},
+		{Content: "vlog", Output: "/vlogs"},
+		{MinHeight: -1, Output: "/out"},
+		{MinHeight: 2160, MaxHeight: 1080, Output: "/out"},
+	} {
+		assert.Error(t, ValidateRoutes([]config.Route{route}), "%+v", route)
+	}
+}
+
+func TestPlanDirConfigRoutes(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "lessons/a.mp4")
+	writeDirConfig(t, dir, "lessons", "routes:\n  - content: screencast\n    output: sorted\n")
+
+	jobs, err := Plan(dir, filepath.Join(dir, "out"), Options{Recursive: true, Match: isMP4, DirConfigs: true})
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		// Relative directories are relative to the file
+		assert.Equal(t, []config.Route{{Content: "screencast", Output: filepath.Join(dir, "lessons", "sorted")}}, jobs[0].Config.Settings.Routes)
+	}
+}
@@ -0,0 +1,215 @@
+package batch
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Estimates of a scanned file
+const (
+	EstimateProbe    = "probe"    // From the stream properties ffprobe reports
+	EstimateAnalysis = "analysis" // From a full analysis cached by an earlier run
+)
+
+// ScanEntry is one video of a library scan
+type ScanEntry struct {
+	Path             string  `json:"path"`
+	Size             int64   `json:"size"`
+	Duration         float64 `json:"duration_seconds"`
+	Codec            string  `json:"codec"`
+	Width            int     `json:"width"`
+	Height           int     `json:"height"`
+	Bitrate          int64   `json:"bitrate"`
+	ContentType      string  `json:"content_type"`
+	RecommendedCodec string  `json:"recommended_codec"`
+	Potential        int     `json:"compression_potential"` // Share of the size compression is estimated to save (%)
+	EstimatedSavings int64   `json:"estimated_savings"`     // Bytes compression is estimated to save
+	Estimate         string  `json:"estimate,omitempty"`    // What the estimate comes from, EstimateProbe or EstimateAnalysis
+	Error            string  `json:"error,omitempty"`       // Why the file could not be probed
+}
+
+// Resolution returns the size of the frame, e.g. 1920x1080
+func (e ScanEntry) Resolution() string {
+	if e.Width <= 0 || e.Height <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%dx%d", e.Width, e.Height)
+}
+
+// ResolutionClass returns the usual name of the resolution of the entry,
+// e.g. 1080p. Frames cropped to a wider shape, such as 1920x800, are named
+// after their width.
+func (e ScanEntry) ResolutionClass() string {
+	height := e.Height
+	if fromWidth := e.Width * 9 / 16; fromWidth > height {
+		height = fromWidth
+	}
+	switch {
+	case height <= 0:
+		return "unknown"
+	case height >= 2160:
+		return "2160p"
+	case height >= 1440:
+		return "1440p"
+	case height >= 1080:
+		return "1080p"
+	case height >= 720:
+		return "720p"
+	case height >= 480:
+		return "480p"
+	}
+	return "SD"
+}
+
+// ScanSortKeys lists the columns a scan can be sorted by. Sizes, savings
+// and rates sort from the largest, text from A to Z.
+var ScanSortKeys = []string{"savings", "size", "potential", "bitrate", "duration", "resolution", "codec", "path"}
+
+// ValidScanSort reports whether a scan can be sorted by key
+func ValidScanSort(key string) bool {
+	for _, k := range ScanSortKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SortScan sorts entries by the column key, see ScanSortKeys. Ties keep the
+// order of the paths, and files that could not be probed come last.
+func SortScan(entries []ScanEntry, key string) error {
+	if !ValidScanSort(key) {
+		return i18n.Errorf("sort must be one of: %s (got %s)", strings.Join(ScanSortKeys, ", "), key)
+	}
+	less := func(a, b ScanEntry) bool {
+		switch key {
+		case "savings":
+			return a.EstimatedSavings > b.EstimatedSavings
+		case "size":
+			return a.Size > b.Size
+		case "potential":
+			return a.Potential > b.Potential
+		case "bitrate":
+			return a.Bitrate > b.Bitrate
+		case "duration":
+			return a.Duration > b.Duration
+		case "resolution":
+			return a.Width*a.Height > b.Width*b.Height
+		case "codec":
+			return a.Codec < b.Codec
+		}
+		return false
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if (a.Error == "") != (b.Error == "") {
+			return a.Error == ""
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Path < b.Path
+	})
+	return nil
+}
+
+// ScanGroup sums the scanned files that share a codec or resolution
+type ScanGroup struct {
+	Name             string `json:"name"`
+	Files            int    `json:"files"`
+	Size             int64  `json:"size"`
+	EstimatedSavings int64  `json:"estimated_savings"`
+}
+
+// ScanSummary sums a whole scan
+type ScanSummary struct {
+	Files            int         `json:"files"`
+	Failed           int         `json:"failed"` // Files that could not be probed
+	Size             int64       `json:"size"`
+	Duration         float64     `json:"duration_seconds"`
+	EstimatedSavings int64       `json:"estimated_savings"`
+	Codecs           []ScanGroup `json:"codecs"`      // Largest first
+	Resolutions      []ScanGroup `json:"resolutions"` // Largest first
+}
+
+// SummarizeScan totals entries, by codec and by resolution class
+func SummarizeScan(entries []ScanEntry) ScanSummary {
+	summary := ScanSummary{Files: len(entries)}
+	for _, entry := range entries {
+		summary.Size += entry.Size
+		if entry.Error != "" {
+			summary.Failed++
+			continue
+		}
+		summary.Duration += entry.Duration
+		summary.EstimatedSavings += entry.EstimatedSavings
+	}
+	summary.Codecs = groupScan(entries, func(e ScanEntry) string { return e.Codec })
+	summary.Resolutions = groupScan(entries, ScanEntry.ResolutionClass)
+	return summary
+}
+
+// groupScan sums the probed entries by the name key gives them, largest
+// group first
+func groupScan(entries []ScanEntry, key func(ScanEntry) string) []ScanGroup {
+	index := make(map[string]int)
+	var groups []ScanGroup
+	for _, entry := range entries {
+		if entry.Error != "" {
+			continue
+		}
+		name := key(entry)
+		if name == "" {
+			name = "unknown"
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, ScanGroup{Name: name})
+		}
+		groups[i].Files++
+		groups[i].Size += entry.Size
+		groups[i].EstimatedSavings += entry.EstimatedSavings
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Size != groups[j].Size {
+			return groups[i].Size > groups[j].Size
+		}
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+// scanCSVHeader names the columns of WriteScanCSV
+var scanCSVHeader = []string{"path", "size_bytes", "duration_seconds", "codec", "width", "height", "bitrate",
+	"content_type", "recommended_codec", "compression_potential", "estimated_savings_bytes", "estimate", "error"}
+
+// WriteScanCSV writes entries as CSV, one row per file under a header, with
+// sizes in bytes and bitrates in bits per second so spreadsheets can sort
+// them
+func WriteScanCSV(w io.Writer, entries []ScanEntry) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(scanCSVHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		row := []string{e.Path, strconv.FormatInt(e.Size, 10), strconv.FormatFloat(e.Duration, 'f', 2, 64), e.Codec,
+			strconv.Itoa(e.Width), strconv.Itoa(e.Height), strconv.FormatInt(e.Bitrate, 10), e.ContentType,
+			e.RecommendedCodec, strconv.Itoa(e.Potential), strconv.FormatInt(e.EstimatedSavings, 10), e.Estimate, e.Error}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleScan() []ScanEntry {
+	return []ScanEntry{
+		{Path: "b.mkv", Size: 4000, Codec: "h264", Width: 1920, Height: 800, Potential: 50, EstimatedSavings: 2000},
+		{Path: "a.mp4", Size: 9000, Codec: "h264", Width: 3840, Height: 2160, Potential: 20, EstimatedSavings: 1800},
+		{Path: "broken.avi", Size: 100, Error: "invalid data"},
+		{Path: "c.mp4", Size: 1000, Codec: "hevc", Width: 1280, Height: 720, Potential: 10, EstimatedSavings: 100},
+	}
+}
+
+func TestSortScan(t *testing.T) {
+	entries := sampleScan()
+	assert.NoError(t, SortScan(entries, "savings"))
+	assert.Equal(t, []string{"b.mkv", "a.mp4", "c.mp4", "broken.avi"}, scanPaths(entries))
+
+	assert.NoError(t, SortScan(entries, "size"))
+	assert.Equal(t, []string{"a.mp4", "b.mkv", "c.mp4", "broken.avi"}, scanPaths(entries))
+
+	// Text sorts from A to Z, ties by path
+	assert.NoError(t, SortScan(entries, "codec"))
+	assert.Equal(t, []string{"a.mp4", "b.mkv", "c.mp4", "broken.avi"}, scanPaths(entries))
+
+	assert.Error(t, SortScan(entries, "color"))
+}
+
+func scanPaths(entries []ScanEntry) []string {
+	var paths []string
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+	return paths
+}
+
+func TestSummarizeScan(t *testing.T) {
+	summary := SummarizeScan(sampleScan())
+	assert.Equal(t, 4, summary.Files)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, int64(14100), summary.Size)
+	assert.Equal(t, int64(3900), summary.EstimatedSavings)
+	assert.Equal(t, []ScanGroup{{Name: "h264", Files: 2, Size: 13000, EstimatedSavings: 3800}, {Name: "hevc", Files: 1, Size: 1000, EstimatedSavings: 100}}, summary.Codecs)
+	assert.Equal(t, []ScanGroup{{Name: "2160p", Files: 1, Size: 9000, EstimatedSavings: 1800},
+		{Name: "1080p", Files: 1, Size: 4000, EstimatedSavings: 2000}, {Name: "720p", Files: 1, Size: 1000, EstimatedSavings: 100}}, summary.Resolutions)
+}
+
+func TestResolutionClass(t *testing.T) {
+	assert.Equal(t, "1080p", ScanEntry{Width: 1920, Height: 1080}.ResolutionClass())
+	assert.Equal(t, "1080p", ScanEntry{Width: 1920, Height: 800}.ResolutionClass())
+	assert.Equal(t, "480p", ScanEntry{Width: 720, Height: 480}.ResolutionClass())
+	assert.Equal(t, "SD", ScanEntry{Width: 320, Height: 240}.ResolutionClass())
+	assert.Equal(t, "unknown", ScanEntry{}.ResolutionClass())
+	assert.Equal(t, "", ScanEntry{}.Resolution())
+}
+
+func TestWriteScanCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteScanCSV(&buf, sampleScan()[:3]))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "path,size_bytes,"))
+	assert.Equal(t, "b.mkv,4000,0.00,h264,1920,800,0,,,50,2000,,", lines[1])
+	assert.Equal(t, "broken.avi,100,0.00,,0,0,0,,,0,0,,invalid data", lines[3])
+}
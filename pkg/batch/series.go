@@ -0,0 +1,114 @@
+package batch
+
+import (
+	"math"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// Series limits. A folder needs more episodes than samples for sharing an
+// analysis to save anything.
+const (
+	SeriesSamples     = 3 // Episodes of a series analyzed in full
+	MinSeriesEpisodes = 5 // Episodes a group of files needs to be treated as a series
+
+	// seriesDurationTolerance is the fraction by which the duration of an
+	// episode may differ from the series' first sample
+	seriesDurationTolerance = 0.25
+)
+
+// episodeRegexes match the episode numbers of file names, most specific
+// first: S01E02, 1x02, "Episode 12", "Ep 3", then a number ending the name,
+// and the first group is the part replaced. Underscores separate words.
+var episodeRegexes = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(s\d{1,2}[ ._-]?e\d{1,4})`),
+	regexp.MustCompile(`(?i)(?:^|[^a-z0-9])(\d{1,2}x\d{2,4})(?:$|[^a-z0-9])`),
+	regexp.MustCompile(`(?i)(?:^|[^a-z0-9])((?:episode|ep|e)[ ._-]?\d{1,4})(?:$|[^a-z0-9])`),
+	regexp.MustCompile(`([ ._-]\d{1,4})$`),
+}
+
+// Series is a group of episodes in one directory
+type Series struct {
+	Name string // File name with the episode number replaced by #
+	Jobs []int  // Positions of the episodes in the planned jobs, in order
+}
+
+// SeriesKey returns the name of a video file with its episode number
+// replaced by #, so the episodes of a series share a key. It reports false
+// when the name has no episode number.
+func SeriesKey(name string) (string, bool) {
+	stem := strings.TrimSuffix(filepath.Base(name), filepath.Ext(name))
+	for _, re := range episodeRegexes {
+		if loc := re.FindStringSubmatchIndex(stem); loc != nil {
+			return strings.ToLower(stem[:loc[2]] + "#" + stem[loc[3]:]), true
+		}
+	}
+	return "", false
+}
+
+// GroupSeries finds the series among jobs: files in one directory whose
+// names differ only by the episode number, at least MinSeriesEpisodes of
+// them. Series are in the order of their first episode.
+func GroupSeries(jobs []Job) []Series {
+	var order []string
+	groups := make(map[string]*Series)
+	for i, job := range jobs {
+		key, ok := SeriesKey(job.Input)
+		if !ok {
+			continue
+		}
+		id := filepath.Join(filepath.Dir(job.Input), key)
+		series, found := groups[id]
+		if !found {
+			series = &Series{Name: key}
+			groups[id] = series
+			order = append(order, id)
+		}
+		series.Jobs = append(series.Jobs, i)
+	}
+
+	var result []Series
+	for _, id := range order {
+		if len(groups[id].Jobs) >= MinSeriesEpisodes {
+			result = append(result, *groups[id])
+		}
+	}
+	return result
+}
+
+// Samples returns the episodes of the series to analyze in full, spread
+// over the series so a change of style along a season shows
+func (s Series) Samples() []int {
+	n := len(s.Jobs)
+	if n <= SeriesSamples {
+		return s.Jobs
+	}
+	samples := make([]int, SeriesSamples)
+	for i := range samples {
+		samples[i] = s.Jobs[i*(n-1)/(SeriesSamples-1)]
+	}
+	return samples
+}
+
+// SameSeries reports why an episode does not look like the reference
+// episode of its series: another resolution, codec, HDR, a frame rate or
+// a duration too far off. It returns "" when the episode matches.
+func SameSeries(reference, episode *ffmpeg.VideoFile) string {
+	ref, info := reference.VideoInfo, episode.VideoInfo
+	switch {
+	case ref.Width != info.Width || ref.Height != info.Height:
+		return "resolution"
+	case ref.Codec != info.Codec:
+		return "codec"
+	case ref.IsHDR != info.IsHDR:
+		return "HDR"
+	case math.Abs(ref.FPS-info.FPS) > 0.5:
+		return "frame rate"
+	case reference.Duration > 0 && math.Abs(episode.Duration-reference.Duration) > reference.Duration*seriesDurationTolerance:
+		return "duration"
+	}
+	return ""
+}
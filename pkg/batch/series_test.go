@@ -0,0 +1,71 @@
+package batch
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSeriesKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"Show.S01E02.1080p.mkv":           "show.#.1080p",
+		"show.s01e10.1080p.mkv":           "show.#.1080p",
+		"Show 1x02.mp4":                   "show #",
+		"Cartoon - Episode 12.mp4":        "cartoon - #",
+		"lecture_ep3.mp4":                 "lecture_#",
+		filepath.Join("a", "vlog-07.mov"): "vlog#",
+	} {
+		key, ok := SeriesKey(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, want, key, name)
+	}
+
+	for _, name := range []string{"holiday.mp4", "Movie 1080p.mkv", "E.T.mkv"} {
+		_, ok := SeriesKey(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestGroupSeries(t *testing.T) {
+	var jobs []Job
+	for _, name := range []string{"Show.S01E01.mkv", "Show.S01E02.mkv", "intro.mp4", "Show.S01E03.mkv",
+		"Show.S01E04.mkv", "Show.S01E05.mkv", "Other.S01E01.mkv", "Other.S01E02.mkv"} {
+		jobs = append(jobs, Job{Input: filepath.Join("in", name)})
+	}
+	// Episodes of the same name in another directory are another series
+	for _, name := range []string{"Show.S02E01.mkv", "Show.S02E02.mkv"} {
+		jobs = append(jobs, Job{Input: filepath.Join("in", "s2", name)})
+	}
+
+	series := GroupSeries(jobs)
+	assert.Equal(t, []Series{{Name: "show.#", Jobs: []int{0, 1, 3, 4, 5}}}, series)
+	assert.Nil(t, GroupSeries(jobs[:5]))
+}
+
+func TestSeriesSamples(t *testing.T) {
+	assert.Equal(t, []int{0, 1}, Series{Jobs: []int{0, 1}}.Samples())
+	assert.Equal(t, []int{0, 2, 4}, Series{Jobs: []int{0, 1, 2, 3, 4}}.Samples())
+	assert.Equal(t, []int{2, 10, 20}, Series{Jobs: []int{2, 4, 6, 8, 10, 12, 14, 16, 20}}.Samples())
+}
+
+func TestSameSeries(t *testing.T) {
+	episode := func(width, height int, codec string, fps, duration float64) *ffmpeg.VideoFile {
+		return &ffmpeg.VideoFile{
+			Duration:  duration,
+			VideoInfo: ffmpeg.VideoStreamInfo{Width: width, Height: height, Codec: codec, FPS: fps},
+		}
+	}
+	reference := episode(1920, 1080, "h264", 23.976, 1300)
+
+	assert.Equal(t, "", SameSeries(reference, episode(1920, 1080, "h264", 24, 1500)))
+	assert.Equal(t, "resolution", SameSeries(reference, episode(1280, 720, "h264", 23.976, 1300)))
+	assert.Equal(t, "codec", SameSeries(reference, episode(1920, 1080, "hevc", 23.976, 1300)))
+	assert.Equal(t, "frame rate", SameSeries(reference, episode(1920, 1080, "h264", 29.97, 1300)))
+	assert.Equal(t, "duration", SameSeries(reference, episode(1920, 1080, "h264", 23.976, 5400)))
+
+	hdr := episode(1920, 1080, "h264", 23.976, 1300)
+	hdr.VideoInfo.IsHDR = true
+	assert.Equal(t, "HDR", SameSeries(reference, hdr))
+}
@@ -0,0 +1,156 @@
+// Package batch plans the files processed by a directory run
+package batch
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/config"
+)
+
+// Job is one input file and the output path it is compressed to
+type Job struct {
+	Input  string
+	Output string
+
+	// Config holds the settings the .compressvideo.yaml files above the
+	// input chose, with the command line flags of Options.Flags on top, and
+	// ConfigFiles the files, outermost first
+	Config      DirConfig
+	ConfigFiles []string
+}
+
+// Options controls how a directory is walked
+type Options struct {
+	Recursive bool                   // Descend into subdirectories
+	Flatten   bool                   // Write every output directly under the output root
+	Template  string                 // Output name template (DefaultNameTemplate when empty)
+	Name      NameFields             // Values of the template's placeholders
+	Match     func(name string) bool // Reports whether a file should be processed
+	Files     FileFilter             // Size and age limits
+
+	// DirConfigs reads the .compressvideo.yaml of each directory, whose
+	// profile may name one of Profiles. Flags holds the settings given on
+	// the command line, which the files do not override.
+	DirConfigs bool
+	Profiles   map[string]config.Profile
+	Flags      DirConfig
+}
+
+// Plan walks inputDir and returns a job for every matching file. Outputs
+// mirror the input tree under outputRoot unless Flatten is set. Names that
+// would collide, including ones differing only in case, get a numeric
+// suffix so no output overwrites another. An output root inside the input
+// tree is not walked, so earlier outputs are never compressed again. With
+// DirConfigs, directories and files their .compressvideo.yaml skips or
+// excludes are left out.
+func Plan(inputDir, outputRoot string, opts Options) ([]Job, error) {
+	inputAbs, err := filepath.Abs(inputDir)
+	if err != nil {
+		return nil, err
+	}
+	outputAbs, err := filepath.Abs(outputRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []Job
+	used := make(map[string]bool)
+	states := map[string]*dirState{filepath.Dir(inputDir): {}}
+
+	err = filepath.WalkDir(inputDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		parent := states[filepath.Dir(path)]
+
+		if entry.IsDir() {
+			if path != inputDir {
+				abs, err := filepath.Abs(path)
+				if err != nil {
+					return err
+				}
+				if !opts.Recursive || (abs == outputAbs && outputAbs != inputAbs) || parent.excluded(path) {
+					return filepath.SkipDir
+				}
+			}
+			state := parent
+			if opts.DirConfigs {
+				if state, err = parent.enter(path, opts.Profiles); err != nil {
+					return err
+				}
+				if state == nil {
+					return filepath.SkipDir
+				}
+			}
+			states[path] = state
+			return nil
+		}
+
+		if !entry.Type().IsRegular() || (opts.Match != nil && !opts.Match(entry.Name())) || parent.excluded(path) {
+			return nil
+		}
+		if opts.Files.Active() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			if !opts.Files.Match(info) {
+				return nil
+			}
+		}
+
+		outputDir := outputRoot
+		if !opts.Flatten {
+			rel, err := filepath.Rel(inputDir, filepath.Dir(path))
+			if err != nil {
+				return err
+			}
+			outputDir = filepath.Join(outputRoot, rel)
+		}
+
+		settings := parent.config.Override(opts.Flags)
+		fields := opts.Name
+		if settings.Settings.Quality != 0 {
+			fields.Quality = settings.Settings.Quality
+		}
+		if settings.Settings.Preset != "" {
+			fields.Preset = settings.Settings.Preset
+		}
+
+		name := OutputName(opts.Template, entry.Name(), fields)
+		ext := filepath.Ext(name)
+		output := uniquePath(outputDir, strings.TrimSuffix(name, ext), ext, used)
+		jobs = append(jobs, Job{Input: path, Output: output, Config: settings, ConfigFiles: parent.files})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// uniquePath returns outputDir/base+ext, numbering the name when it was
+// already handed out in this run
+func uniquePath(outputDir, base, ext string, used map[string]bool) string {
+	path := filepath.Join(outputDir, base+ext)
+	for n := 2; used[strings.ToLower(path)]; n++ {
+		path = filepath.Join(outputDir, fmt.Sprintf("%s-%d%s", base, n, ext))
+	}
+	used[strings.ToLower(path)] = true
+	return path
+}
+
+// CreateOutputDirs creates the directories the jobs write to
+func CreateOutputDirs(jobs []Job) error {
+	for _, job := range jobs {
+		if err := os.MkdirAll(filepath.Dir(job.Output), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
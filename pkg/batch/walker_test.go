@@ -0,0 +1,100 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createTree creates empty files at the given slash-separated paths under root
+func createTree(t *testing.T, root string, paths ...string) {
+	for _, path := range paths {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		assert.NoError(t, os.WriteFile(full, nil, 0644))
+	}
+}
+
+func isMP4(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mp4")
+}
+
+// outputs returns the job outputs relative to root, slash-separated
+func outputs(t *testing.T, root string, jobs []Job) []string {
+	var rels []string
+	for _, job := range jobs {
+		rel, err := filepath.Rel(root, job.Output)
+		assert.NoError(t, err)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	return rels
+}
+
+func TestPlanMirrorsTree(t *testing.T) {
+	dir, err := os.MkdirTemp("", "batch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "in")
+	output := filepath.Join(dir, "out")
+	createTree(t, input, "a.mp4", "notes.txt", "trip/b.mp4", "trip/day2/c.mp4")
+
+	jobs, err := Plan(input, output, Options{Recursive: true, Match: isMP4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a-compressed.mp4", "trip/b-compressed.mp4", "trip/day2/c-compressed.mp4"}, outputs(t, output, jobs))
+	assert.Equal(t, filepath.Join(input, "trip", "b.mp4"), jobs[1].Input)
+
+	assert.NoError(t, CreateOutputDirs(jobs))
+	_, err = os.Stat(filepath.Join(output, "trip", "day2"))
+	assert.NoError(t, err)
+
+	// Without -r only the top level is processed
+	jobs, err = Plan(input, output, Options{Match: isMP4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a-compressed.mp4"}, outputs(t, output, jobs))
+}
+
+func TestPlanFlattenCollisions(t *testing.T) {
+	dir, err := os.MkdirTemp("", "batch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	createTree(t, dir, "a/clip.mp4", "b/clip.mp4", "c/CLIP.mp4")
+
+	output := filepath.Join(dir, "flat")
+	jobs, err := Plan(dir, output, Options{Recursive: true, Flatten: true, Match: isMP4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"clip-compressed.mp4", "clip-compressed-2.mp4", "CLIP-compressed-3.mp4"}, outputs(t, output, jobs))
+}
+
+func TestPlanNameTemplate(t *testing.T) {
+	dir := t.TempDir()
+	createTree(t, dir, "a/clip.mp4", "b/clip.mp4")
+
+	output := filepath.Join(dir, "flat")
+	jobs, err := Plan(dir, output, Options{
+		Recursive: true,
+		Flatten:   true,
+		Match:     isMP4,
+		Template:  "{name}_q{quality}{ext}",
+		Name:      NameFields{Quality: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"clip_q2.mp4", "clip_q2-2.mp4"}, outputs(t, output, jobs))
+}
+
+func TestPlanSkipsOutputRootInsideInput(t *testing.T) {
+	dir, err := os.MkdirTemp("", "batch")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	createTree(t, dir, "a.mp4", "compressed/a-compressed.mp4")
+
+	output := filepath.Join(dir, "compressed")
+	jobs, err := Plan(dir, output, Options{Recursive: true, Match: isMP4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a-compressed.mp4"}, outputs(t, output, jobs))
+}
@@ -0,0 +1,98 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Window is a time of day encoding is allowed in, such as 01:00-07:00.
+// Windows that end before they start run past midnight.
+type Window struct {
+	Start time.Duration // Since midnight
+	End   time.Duration // Since midnight
+}
+
+// ParseWindow parses a window written as HH:MM-HH:MM. An empty value is
+// the zero window, which allows every time.
+func ParseWindow(value string) (Window, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Window{}, nil
+	}
+	invalid := i18n.Errorf("only-between must be a time window such as 01:00-07:00 (got %s)", value)
+	from, to, found := strings.Cut(value, "-")
+	if !found {
+		return Window{}, invalid
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return Window{}, invalid
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return Window{}, invalid
+	}
+	if start == end {
+		return Window{}, i18n.Errorf("only-between must end at another time than it starts (got %s)", value)
+	}
+	return Window{Start: start, End: end}, nil
+}
+
+// parseClock parses a time of day such as 7:30 or 23:00
+func parseClock(value string) (time.Duration, error) {
+	clock, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute, nil
+}
+
+// IsZero reports whether the window allows every time
+func (w Window) IsZero() bool {
+	return w.Start == 0 && w.End == 0
+}
+
+// Contains reports whether t, in its own location, is within the window.
+// The window includes its start and excludes its end.
+func (w Window) Contains(t time.Time) bool {
+	if w.IsZero() {
+		return true
+	}
+	now := sinceMidnight(t)
+	if w.Start < w.End {
+		return now >= w.Start && now < w.End
+	}
+	return now >= w.Start || now < w.End
+}
+
+// Next returns the next time at or after t the window opens, t itself when
+// it is open
+func (w Window) Next(t time.Time) time.Time {
+	if w.Contains(t) {
+		return t
+	}
+	wait := w.Start - sinceMidnight(t)
+	if wait < 0 {
+		wait += 24 * time.Hour
+	}
+	return t.Add(wait)
+}
+
+// String formats the window as HH:MM-HH:MM
+func (w Window) String() string {
+	return fmt.Sprintf("%s-%s", formatClock(w.Start), formatClock(w.End))
+}
+
+// sinceMidnight returns the time of day of t
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// formatClock formats a time of day as HH:MM
+func formatClock(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
@@ -0,0 +1,64 @@
+package batch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWindow(t *testing.T) {
+	window, err := ParseWindow("01:00-07:30")
+	assert.NoError(t, err)
+	assert.Equal(t, Window{Start: time.Hour, End: 7*time.Hour + 30*time.Minute}, window)
+	assert.Equal(t, "01:00-07:30", window.String())
+
+	window, err = ParseWindow(" 22:00 - 6:00 ")
+	assert.NoError(t, err)
+	assert.Equal(t, "22:00-06:00", window.String())
+
+	window, err = ParseWindow("")
+	assert.NoError(t, err)
+	assert.True(t, window.IsZero())
+
+	for _, value := range []string{"night", "01:00", "25:00-07:00", "01:00-07:61", "03:00-03:00"} {
+		_, err := ParseWindow(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestWindowContains(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, 6, 15, hour, minute, 0, 0, time.Local)
+	}
+
+	overnight, _ := ParseWindow("01:00-07:00")
+	assert.True(t, overnight.Contains(at(1, 0)))
+	assert.True(t, overnight.Contains(at(6, 59)))
+	assert.False(t, overnight.Contains(at(7, 0)))
+	assert.False(t, overnight.Contains(at(0, 59)))
+	assert.False(t, overnight.Contains(at(13, 0)))
+
+	pastMidnight, _ := ParseWindow("22:00-06:00")
+	assert.True(t, pastMidnight.Contains(at(23, 30)))
+	assert.True(t, pastMidnight.Contains(at(0, 0)))
+	assert.True(t, pastMidnight.Contains(at(5, 59)))
+	assert.False(t, pastMidnight.Contains(at(6, 0)))
+	assert.False(t, pastMidnight.Contains(at(21, 59)))
+
+	assert.True(t, Window{}.Contains(at(13, 0)))
+}
+
+func TestWindowNext(t *testing.T) {
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2024, 6, day, hour, minute, 0, 0, time.Local)
+	}
+
+	overnight, _ := ParseWindow("01:00-07:00")
+	assert.Equal(t, at(15, 3, 0), overnight.Next(at(15, 3, 0)))
+	assert.Equal(t, at(16, 1, 0), overnight.Next(at(15, 13, 0)))
+	assert.Equal(t, at(15, 1, 0), overnight.Next(at(15, 0, 30)))
+
+	pastMidnight, _ := ParseWindow("22:00-06:00")
+	assert.Equal(t, at(15, 22, 0), pastMidnight.Next(at(15, 6, 0)))
+}
@@ -0,0 +1,94 @@
+// Package bot takes videos sent to a Telegram or Discord bot and sends the
+// compressed files back
+package bot
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// Platform is a chat service the bot receives videos on and replies through
+type Platform interface {
+	// Name is the name of the service, e.g. Telegram
+	Name() string
+	// UploadLimit is the largest file in bytes the bot may send
+	UploadLimit() int64
+	// DownloadLimit is the largest file in bytes the bot may fetch, 0 for no limit
+	DownloadLimit() int64
+	// Receive waits for the videos sent since the last call. It returns
+	// none when nothing arrived for a while, so the caller can stop.
+	Receive(ctx context.Context) ([]Video, error)
+	// Download saves the video to path
+	Download(ctx context.Context, video Video, path string) error
+	// Reply answers the message of the video with text, and the file at
+	// path when path is not empty
+	Reply(ctx context.Context, video Video, text, path string) error
+}
+
+// Video is a video file sent to the bot
+type Video struct {
+	Chat      string // Chat or channel the video was sent in
+	MessageID string // Message that carried the video
+	From      string // User who sent it
+	FileName  string // Name of the file, made up when the service has none
+	Size      int64  // Bytes, 0 when unknown
+
+	source string // Telegram file_id or Discord attachment URL
+}
+
+// Ext returns the extension of the video's file name, .mp4 when it has none
+func (v Video) Ext() string {
+	ext := strings.ToLower(filepath.Ext(v.FileName))
+	if ext == "" || len(ext) > 6 {
+		return ".mp4"
+	}
+	return ext
+}
+
+// Sizes of the upload and download limits
+const (
+	TelegramUploadLimit   = 50 << 20 // Bot API sendVideo
+	TelegramDownloadLimit = 20 << 20 // Bot API getFile
+	DiscordUploadLimit    = 10 << 20 // Servers without boosts
+)
+
+// sizeMargin is the share of a size limit a compressed file is planned to
+// fill, leaving room for the container and the encoder's bitrate swings
+const sizeMargin = 0.92
+
+// MinVideoBitrate is the lowest video bitrate in bits/s worth sending;
+// below it a video is a blur of blocks
+const MinVideoBitrate = 150000
+
+// ErrTooLong means a video cannot fit a size limit at a watchable bitrate
+var ErrTooLong = errors.New("video too long for the size limit")
+
+// VideoBitrate returns the video bitrate in bits/s that keeps a video of
+// duration seconds, with audio at audioBitrate bits/s, under limit bytes.
+// It returns ErrTooLong when that is below MinVideoBitrate.
+func VideoBitrate(limit int64, duration float64, audioBitrate int64) (int64, error) {
+	if duration <= 0 {
+		return 0, errors.New("unknown video duration")
+	}
+	bitrate := int64(float64(limit)*sizeMargin*8/duration) - audioBitrate
+	if bitrate < MinVideoBitrate {
+		return 0, ErrTooLong
+	}
+	return bitrate, nil
+}
+
+// Allowed reports whether the chat or the sender of video is among the IDs
+// in allow; an empty list allows everyone
+func Allowed(allow []string, video Video) bool {
+	if len(allow) == 0 {
+		return true
+	}
+	for _, id := range allow {
+		if id == video.Chat || id == video.From {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideoBitrate(t *testing.T) {
+	// 10 MB over 100 s, 96 kb/s of it audio
+	bitrate, err := VideoBitrate(10000000, 100, 96000)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(640000), bitrate)
+
+	_, err = VideoBitrate(10000000, 3600, 96000)
+	assert.Equal(t, ErrTooLong, err)
+	_, err = VideoBitrate(10000000, 0, 96000)
+	assert.Error(t, err)
+}
+
+func TestAllowed(t *testing.T) {
+	video := Video{Chat: "-100", From: "42"}
+	assert.True(t, Allowed(nil, video))
+	assert.True(t, Allowed([]string{"42"}, video))
+	assert.True(t, Allowed([]string{"7", "-100"}, video))
+	assert.False(t, Allowed([]string{"7"}, video))
+
+	assert.Equal(t, ".mov", Video{FileName: "Clip.MOV"}.Ext())
+	assert.Equal(t, ".mp4", Video{FileName: "clip"}.Ext())
+}
+
+func TestTelegram(t *testing.T) {
+	var sent map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTOKEN/getUpdates":
+			assert.Equal(t, "0", r.URL.Query().Get("offset"))
+			fmt.Fprint(w, `{"ok":true,"result":[
+				{"update_id":10,"message":{"message_id":1,"from":{"id":42},"chat":{"id":42},"text":"hi"}},
+				{"update_id":11,"message":{"message_id":2,"from":{"id":42},"chat":{"id":42},"video":{"file_id":"F1","file_size":1000}}},
+				{"update_id":12,"message":{"message_id":3,"from":{"id":42},"chat":{"id":42},"document":{"file_id":"F2","file_name":"a.pdf","mime_type":"application/pdf"}}},
+				{"update_id":13,"message":{"message_id":4,"from":{"id":42},"chat":{"id":42},"document":{"file_id":"F3","file_name":"b.mkv","mime_type":"video/x-matroska"}}}]}`)
+		case "/botTOKEN/getFile":
+			assert.Equal(t, "F1", r.URL.Query().Get("file_id"))
+			fmt.Fprint(w, `{"ok":true,"result":{"file_path":"videos/file_1.mp4"}}`)
+		case "/file/botTOKEN/videos/file_1.mp4":
+			fmt.Fprint(w, "video data")
+		case "/botTOKEN/sendVideo":
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			sent = map[string]string{"chat_id": r.FormValue("chat_id"), "reply_to_message_id": r.FormValue("reply_to_message_id"), "caption": r.FormValue("caption")}
+			file, header, err := r.FormFile("video")
+			if assert.NoError(t, err) {
+				data, _ := io.ReadAll(file)
+				sent["file"] = header.Filename + ":" + string(data)
+			}
+			fmt.Fprint(w, `{"ok":true,"result":{}}`)
+		case "/botTOKEN/sendMessage":
+			fmt.Fprint(w, `{"ok":false,"description":"Bad Request: chat not found"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	telegram := NewTelegram("TOKEN")
+	telegram.APIURL = server.URL
+	assert.Equal(t, int64(0), telegram.DownloadLimit())
+
+	videos, err := telegram.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(14), telegram.offset)
+	if !assert.Equal(t, 2, len(videos)) {
+		return
+	}
+	assert.Equal(t, "video-2.mp4", videos[0].FileName)
+	assert.Equal(t, int64(1000), videos[0].Size)
+	assert.Equal(t, "b.mkv", videos[1].FileName)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.mp4")
+	assert.NoError(t, telegram.Download(context.Background(), videos[0], input))
+	data, _ := os.ReadFile(input)
+	assert.Equal(t, "video data", string(data))
+
+	assert.NoError(t, telegram.Reply(context.Background(), videos[0], "Done", input))
+	assert.Equal(t, map[string]string{"chat_id": "42", "reply_to_message_id": "2", "caption": "Done", "file": "in.mp4:video data"}, sent)
+
+	err = telegram.Reply(context.Background(), videos[0], "Failed", "")
+	assert.EqualError(t, err, "telegram: Bad Request: chat not found")
+
+	// The token never shows in errors
+	telegram.APIURL = "http://127.0.0.1:1"
+	_, err = telegram.Receive(context.Background())
+	if assert.Error(t, err) {
+		assert.False(t, strings.Contains(err.Error(), "TOKEN"), err.Error())
+	}
+}
+
+func TestDiscord(t *testing.T) {
+	interval := discordPollInterval
+	discordPollInterval = time.Millisecond
+	defer func() { discordPollInterval = interval }()
+
+	var reply map[string]interface{}
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Attachments come from the CDN, which never gets the token
+		if strings.HasPrefix(r.URL.Path, "/a/") {
+			assert.Equal(t, "", r.Header.Get("Authorization"))
+		} else {
+			assert.Equal(t, "Bot TOKEN", r.Header.Get("Authorization"))
+		}
+		switch {
+		case r.URL.Path == "/channels/7/messages" && r.Method == http.MethodGet:
+			if r.URL.Query().Get("after") == "" {
+				assert.Equal(t, "1", r.URL.Query().Get("limit"))
+				fmt.Fprint(w, `[{"id":"99","author":{"id":"1"}}]`)
+				return
+			}
+			assert.Equal(t, "99", r.URL.Query().Get("after"))
+			fmt.Fprintf(w, `[
+				{"id":"102","author":{"id":"5","bot":true},"attachments":[{"filename":"out.mp4","size":10,"url":"%[1]s/a/3","content_type":"video/mp4"}]},
+				{"id":"101","author":{"id":"5"},"attachments":[{"filename":"clip.mp4","size":10,"url":"%[1]s/a/1","content_type":"video/mp4"},{"filename":"notes.txt","url":"%[1]s/a/2","content_type":"text/plain"}]},
+				{"id":"100","author":{"id":"5"},"content":"hello"}]`, server.URL)
+		case r.URL.Path == "/channels/7/messages" && r.Method == http.MethodPost:
+			assert.NoError(t, r.ParseMultipartForm(1<<20))
+			assert.NoError(t, json.Unmarshal([]byte(r.FormValue("payload_json")), &reply))
+			_, header, err := r.FormFile("files[0]")
+			if assert.NoError(t, err) {
+				reply["file"] = header.Filename
+			}
+			fmt.Fprint(w, `{}`)
+		case r.URL.Path == "/a/1":
+			fmt.Fprint(w, "clip")
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"Missing Access","code":50001}`)
+		}
+	}))
+	defer server.Close()
+
+	discord := NewDiscord("TOKEN", []string{"7"})
+	discord.APIURL = server.URL
+	assert.Equal(t, int64(DiscordUploadLimit), discord.UploadLimit())
+
+	// Messages from before the bot started are left alone
+	videos, err := discord.Receive(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(videos))
+
+	videos, err = discord.Receive(context.Background())
+	assert.NoError(t, err)
+	if !assert.Equal(t, 1, len(videos)) {
+		return
+	}
+	assert.Equal(t, Video{Chat: "7", MessageID: "101", From: "5", FileName: "clip.mp4", Size: 10, source: server.URL + "/a/1"}, videos[0])
+	assert.Equal(t, "102", discord.last["7"])
+
+	path := filepath.Join(t.TempDir(), "clip.mp4")
+	assert.NoError(t, discord.Download(context.Background(), videos[0], path))
+	assert.NoError(t, discord.Reply(context.Background(), videos[0], "Done", path))
+	assert.Equal(t, "Done", reply["content"])
+	assert.Equal(t, "clip.mp4", reply["file"])
+	assert.Equal(t, "101", reply["message_reference"].(map[string]interface{})["message_id"])
+
+	discord.Channels = []string{"8"}
+	_, err = discord.Receive(context.Background())
+	assert.EqualError(t, err, "discord returned 403 Forbidden: Missing Access")
+}
@@ -0,0 +1,205 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiscordAPI is the address of the Discord REST API
+const DiscordAPI = "https://discord.com/api/v10"
+
+// discordPollInterval is how often the channels are checked for messages
+var discordPollInterval = 5 * time.Second
+
+// Discord receives videos posted in Discord channels, polling them through
+// the REST API. The bot needs the Message Content intent to see the
+// attachments of messages that do not mention it.
+type Discord struct {
+	Token    string
+	Channels []string // IDs of the channels to watch
+	APIURL   string
+	Client   *http.Client
+	Limit    int64 // Upload limit, 0 for DiscordUploadLimit
+
+	last map[string]string // ID of the last message seen in each channel
+}
+
+// NewDiscord creates a Discord bot for token watching channels
+func NewDiscord(token string, channels []string) *Discord {
+	return &Discord{Token: token, Channels: channels, APIURL: DiscordAPI, Client: &http.Client{}}
+}
+
+// Name returns Discord
+func (d *Discord) Name() string {
+	return "Discord"
+}
+
+// UploadLimit returns the largest file the bot may send
+func (d *Discord) UploadLimit() int64 {
+	if d.Limit > 0 {
+		return d.Limit
+	}
+	return DiscordUploadLimit
+}
+
+// DownloadLimit returns 0, attachments are served without a limit
+func (d *Discord) DownloadLimit() int64 {
+	return 0
+}
+
+// discordMessage is the part of a message the bot reads
+type discordMessage struct {
+	ID     string `json:"id"`
+	Author struct {
+		ID  string `json:"id"`
+		Bot bool   `json:"bot"`
+	} `json:"author"`
+	Attachments []struct {
+		Filename    string `json:"filename"`
+		Size        int64  `json:"size"`
+		URL         string `json:"url"`
+		ContentType string `json:"content_type"`
+	} `json:"attachments"`
+}
+
+// Receive waits discordPollInterval and returns the videos attached to
+// the messages posted in the channels since the last call. Messages posted
+// before the first call are left alone.
+func (d *Discord) Receive(ctx context.Context) ([]Video, error) {
+	if d.last == nil {
+		d.last = make(map[string]string)
+	} else {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(discordPollInterval):
+		}
+	}
+
+	var videos []Video
+	for _, channel := range d.Channels {
+		after, started := d.last[channel]
+		query := url.Values{"limit": {"50"}}
+		if !started {
+			query.Set("limit", "1")
+		} else if after != "" {
+			query.Set("after", after)
+		}
+
+		var messages []discordMessage
+		if err := d.call(ctx, http.MethodGet, "/channels/"+channel+"/messages?"+query.Encode(), nil, &messages); err != nil {
+			return videos, err
+		}
+		// Snowflakes grow with time; the longer one is the newer
+		sort.Slice(messages, func(i, j int) bool {
+			a, b := messages[i].ID, messages[j].ID
+			return len(a) < len(b) || len(a) == len(b) && a < b
+		})
+		if len(messages) > 0 {
+			d.last[channel] = messages[len(messages)-1].ID
+		} else if !started {
+			d.last[channel] = ""
+		}
+		if !started {
+			continue
+		}
+
+		for _, message := range messages {
+			if message.Author.Bot {
+				continue
+			}
+			for _, attachment := range message.Attachments {
+				if !strings.HasPrefix(attachment.ContentType, "video/") {
+					continue
+				}
+				videos = append(videos, Video{
+					Chat:      channel,
+					MessageID: message.ID,
+					From:      message.Author.ID,
+					FileName:  attachment.Filename,
+					Size:      attachment.Size,
+					source:    attachment.URL,
+				})
+			}
+		}
+	}
+	return videos, nil
+}
+
+// Download saves the attachment of the video to path
+func (d *Discord) Download(ctx context.Context, video Video, path string) error {
+	return download(ctx, d.Client, video.source, path)
+}
+
+// Reply answers the message of the video with text, and the file at path
+// attached when path is not empty
+func (d *Discord) Reply(ctx context.Context, video Video, text, path string) error {
+	payload, err := json.Marshal(map[string]interface{}{
+		"content":           text,
+		"message_reference": map[string]interface{}{"message_id": video.MessageID, "fail_if_not_exists": false},
+		"allowed_mentions":  map[string]interface{}{"parse": []string{}},
+	})
+	if err != nil {
+		return err
+	}
+	endpoint := "/channels/" + video.Chat + "/messages"
+	if path == "" {
+		return d.call(ctx, http.MethodPost, endpoint, payload, nil)
+	}
+
+	req, err := multipartRequest(ctx, d.APIURL+endpoint, map[string]string{"payload_json": string(payload)}, "files[0]", path)
+	if err != nil {
+		return err
+	}
+	return d.do(req, nil)
+}
+
+// call sends a JSON request to the REST API and decodes the response into
+// result
+func (d *Discord) call(ctx context.Context, method, endpoint string, body []byte, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, d.APIURL+endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return d.do(req, result)
+}
+
+// do authenticates and sends a REST API request, decoding the response
+// into result
+func (d *Discord) do(req *http.Request, result interface{}) error {
+	req.Header.Set("Authorization", "Bot "+d.Token)
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("discord returned %s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("discord returned %s", resp.Status)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(result)
+}
@@ -0,0 +1,108 @@
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// download saves the body of a GET of rawURL to path
+func download(ctx context.Context, client *http.Client, rawURL, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("download returned %s", resp.Status)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	return file.Close()
+}
+
+// uploadBody is the body of a multipart upload, which closes the file it
+// streams
+type uploadBody struct {
+	io.Reader
+	file *os.File
+}
+
+// Close closes the uploaded file
+func (b uploadBody) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	return b.file.Close()
+}
+
+// multipartRequest returns a POST of fields to rawURL as a multipart form,
+// streaming the file at path as field when path is not empty. The length
+// of the body is set, as some servers refuse chunked uploads.
+func multipartRequest(ctx context.Context, rawURL string, fields map[string]string, field, path string) (*http.Request, error) {
+	var head bytes.Buffer
+	form := multipart.NewWriter(&head)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+
+	body := uploadBody{}
+	var fileSize int64
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		info, err := file.Stat()
+		if err == nil {
+			_, err = form.CreateFormFile(field, filepath.Base(path))
+		}
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		body.file, fileSize = file, info.Size()
+	}
+
+	// The closing boundary goes after the file
+	headSize := head.Len()
+	if err := form.Close(); err != nil {
+		body.Close()
+		return nil, err
+	}
+	tail := bytes.NewReader(append([]byte(nil), head.Bytes()[headSize:]...))
+	head.Truncate(headSize)
+	if body.file != nil {
+		body.Reader = io.MultiReader(&head, body.file, tail)
+	} else {
+		body.Reader = io.MultiReader(&head, tail)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rawURL, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.ContentLength = int64(headSize+tail.Len()) + fileSize
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	return req, nil
+}
@@ -0,0 +1,219 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TelegramAPI is the address of the Telegram Bot API
+const TelegramAPI = "https://api.telegram.org"
+
+// telegramPollTimeout is how long a getUpdates call waits for a message
+const telegramPollTimeout = 50 * time.Second
+
+// Telegram receives videos through the Telegram Bot API, polling getUpdates
+type Telegram struct {
+	Token  string
+	APIURL string // Bot API address, a local Bot API server lifts the size limits
+	Client *http.Client
+	Limit  int64 // Upload limit, 0 for TelegramUploadLimit
+
+	offset int64 // First update not received yet
+}
+
+// NewTelegram creates a Telegram bot for token
+func NewTelegram(token string) *Telegram {
+	return &Telegram{Token: token, APIURL: TelegramAPI, Client: &http.Client{}}
+}
+
+// Name returns Telegram
+func (t *Telegram) Name() string {
+	return "Telegram"
+}
+
+// UploadLimit returns the largest file the bot may send
+func (t *Telegram) UploadLimit() int64 {
+	if t.Limit > 0 {
+		return t.Limit
+	}
+	return TelegramUploadLimit
+}
+
+// DownloadLimit returns the largest file getFile serves. A local Bot API
+// server has none.
+func (t *Telegram) DownloadLimit() int64 {
+	if t.APIURL != TelegramAPI {
+		return 0
+	}
+	return TelegramDownloadLimit
+}
+
+// telegramFile is a video, animation or document of a message
+type telegramFile struct {
+	FileID   string `json:"file_id"`
+	FileName string `json:"file_name"`
+	FileSize int64  `json:"file_size"`
+	MimeType string `json:"mime_type"`
+}
+
+// telegramUpdate is the part of an update the bot reads
+type telegramUpdate struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		MessageID int64 `json:"message_id"`
+		From      struct {
+			ID int64 `json:"id"`
+		} `json:"from"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+		Video     *telegramFile `json:"video"`
+		Animation *telegramFile `json:"animation"`
+		Document  *telegramFile `json:"document"`
+	} `json:"message"`
+}
+
+// Receive waits up to telegramPollTimeout for messages and returns the
+// videos among them, sent as videos or as files
+func (t *Telegram) Receive(ctx context.Context) ([]Video, error) {
+	query := url.Values{
+		"offset":          {strconv.FormatInt(t.offset, 10)},
+		"timeout":         {strconv.Itoa(int(telegramPollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	ctx, cancel := context.WithTimeout(ctx, telegramPollTimeout+30*time.Second)
+	defer cancel()
+
+	var updates []telegramUpdate
+	if err := t.call(ctx, "getUpdates", query, &updates); err != nil {
+		return nil, err
+	}
+
+	var videos []Video
+	for _, update := range updates {
+		t.offset = update.UpdateID + 1
+		message := update.Message
+		if message == nil {
+			continue
+		}
+		file := message.Video
+		if file == nil {
+			file = message.Animation
+		}
+		if file == nil && message.Document != nil && strings.HasPrefix(message.Document.MimeType, "video/") {
+			file = message.Document
+		}
+		if file == nil {
+			continue
+		}
+		video := Video{
+			Chat:      strconv.FormatInt(message.Chat.ID, 10),
+			MessageID: strconv.FormatInt(message.MessageID, 10),
+			From:      strconv.FormatInt(message.From.ID, 10),
+			FileName:  file.FileName,
+			Size:      file.FileSize,
+			source:    file.FileID,
+		}
+		if video.FileName == "" {
+			video.FileName = "video-" + video.MessageID + ".mp4"
+		}
+		videos = append(videos, video)
+	}
+	return videos, nil
+}
+
+// Download saves the video to path through getFile
+func (t *Telegram) Download(ctx context.Context, video Video, path string) error {
+	var file struct {
+		FilePath string `json:"file_path"`
+	}
+	if err := t.call(ctx, "getFile", url.Values{"file_id": {video.source}}, &file); err != nil {
+		return err
+	}
+	return t.redact(download(ctx, t.Client, t.APIURL+"/file/bot"+t.Token+"/"+file.FilePath, path))
+}
+
+// Reply answers the message of the video with text, and the file at path
+// as a streamable video when path is not empty
+func (t *Telegram) Reply(ctx context.Context, video Video, text, path string) error {
+	fields := map[string]string{
+		"chat_id":                     video.Chat,
+		"reply_to_message_id":         video.MessageID,
+		"allow_sending_without_reply": "true",
+	}
+	if path == "" {
+		fields["text"] = text
+		return t.post(ctx, "sendMessage", fields, "", "")
+	}
+	fields["caption"] = text
+	fields["supports_streaming"] = "true"
+	return t.post(ctx, "sendVideo", fields, "video", path)
+}
+
+// telegramResponse wraps every Bot API result
+type telegramResponse struct {
+	OK          bool            `json:"ok"`
+	Description string          `json:"description"`
+	Result      json.RawMessage `json:"result"`
+}
+
+// call runs a Bot API method with query and decodes its result into result
+func (t *Telegram) call(ctx context.Context, method string, query url.Values, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.methodURL(method)+"?"+query.Encode(), nil)
+	if err != nil {
+		return t.redact(err)
+	}
+	return t.do(req, result)
+}
+
+// post runs a Bot API method with fields as a multipart form, uploading
+// the file at path as field when path is not empty
+func (t *Telegram) post(ctx context.Context, method string, fields map[string]string, field, path string) error {
+	req, err := multipartRequest(ctx, t.methodURL(method), fields, field, path)
+	if err != nil {
+		return t.redact(err)
+	}
+	return t.do(req, nil)
+}
+
+// do sends a Bot API request and decodes its result into result
+func (t *Telegram) do(req *http.Request, result interface{}) error {
+	resp, err := t.Client.Do(req)
+	if err != nil {
+		return t.redact(err)
+	}
+	defer resp.Body.Close()
+
+	var response telegramResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 16<<20)).Decode(&response); err != nil {
+		return fmt.Errorf("telegram returned %s", resp.Status)
+	}
+	if !response.OK {
+		return fmt.Errorf("telegram: %s", response.Description)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+// methodURL returns the address of a Bot API method
+func (t *Telegram) methodURL(method string) string {
+	return t.APIURL + "/bot" + t.Token + "/" + method
+}
+
+// redact removes the token from err, as the errors of net/http quote the
+// address it is part of
+func (t *Telegram) redact(err error) error {
+	if err == nil || t.Token == "" || !strings.Contains(err.Error(), t.Token) {
+		return err
+	}
+	return fmt.Errorf("%s", strings.ReplaceAll(err.Error(), t.Token, "<token>"))
+}
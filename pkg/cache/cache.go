@@ -15,7 +15,6 @@ import (
 	"github.com/cccarv82/compressvideo/pkg/analyzer"
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
 	"github.com/cccarv82/compressvideo/pkg/util"
-	_ "github.com/mattn/go-sqlite3" // Import SQLite driver
 )
 
 // VideoAnalysisCache manages caching of video analysis results
@@ -25,6 +24,10 @@ type VideoAnalysisCache struct {
 	Logger     *util.Logger
 	Enabled    bool
 	MaxAgeHours int
+	FingerprintMode  FingerprintMode // How files are identified (path or content)
+	ContentHashBytes int64           // Bytes hashed at each end of the file in content mode
+	MaxEntries       int             // Maximum number of cached analyses (0 = unlimited)
+	MaxSizeBytes     int64           // Maximum size of the cached data in bytes (0 = unlimited)
 }
 
 // CacheEntry represents a cached video analysis entry
@@ -57,6 +60,8 @@ func NewVideoAnalysisCache(logger *util.Logger) (*VideoAnalysisCache, error) {
 		Logger:       logger,
 		Enabled:      true,
 		MaxAgeHours:  720, // Default: 30 days
+		FingerprintMode:  FingerprintPath,
+		ContentHashBytes: DefaultContentHashBytes,
 	}
 
 	// Open/create the database
@@ -68,20 +73,27 @@ func NewVideoAnalysisCache(logger *util.Logger) (*VideoAnalysisCache, error) {
 	return cache, nil
 }
 
-// getCacheDir returns the directory for storing cache data
-func getCacheDir() string {
+// getCacheDir returns the directory for storing cache data. It is a variable
+// so tests can point the cache at a temporary directory.
+var getCacheDir = func() string {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		// Fallback to temporary directory if home directory can't be determined
-		return filepath.Join(os.TempDir(), ".compressvideo", "cache")
+		return util.LongPath(filepath.Join(os.TempDir(), ".compressvideo", "cache"))
 	}
-	return filepath.Join(homeDir, ".compressvideo", "cache")
+	// Roaming profiles may keep the home directory on a network share
+	return util.LongPath(filepath.Join(homeDir, ".compressvideo", "cache"))
+}
+
+// Dir returns the directory that holds the cache database
+func Dir() string {
+	return getCacheDir()
 }
 
 // initDB initializes the SQLite database for the cache
 func (vc *VideoAnalysisCache) initDB() error {
 	dbPath := filepath.Join(vc.CacheDir, "analysis_cache.db")
-	db, err := sql.Open("sqlite3", dbPath)
+	db, err := sql.Open(sqliteDriver, dbPath)
 	if err != nil {
 		return err
 	}
@@ -111,6 +123,24 @@ func (vc *VideoAnalysisCache) initDB() error {
 		return err
 	}
 
+	// Access times for LRU eviction
+	if err := ensureLastAccessed(db); err != nil {
+		db.Close()
+		return err
+	}
+
+	// Results of previous compressions, keyed by fingerprint and parameters
+	if err := createOutcomesTable(db); err != nil {
+		db.Close()
+		return err
+	}
+
+	// Sources and outputs of successful compressions, kept without expiry
+	if err := createLedgerTable(db); err != nil {
+		db.Close()
+		return err
+	}
+
 	return nil
 }
 
@@ -122,8 +152,17 @@ func (vc *VideoAnalysisCache) Close() error {
 	return nil
 }
 
-// GetVideoFingerprint generates a unique identifier for a video file based on its path, size, and modification time
+// GetVideoFingerprint generates a unique identifier for a video file based on its path, size, and modification time,
+// or on its content when the cache is in content fingerprint mode
 func (vc *VideoAnalysisCache) GetVideoFingerprint(videoPath string) (string, error) {
+	if vc.FingerprintMode == FingerprintContent {
+		chunkSize := vc.ContentHashBytes
+		if chunkSize <= 0 {
+			chunkSize = DefaultContentHashBytes
+		}
+		return contentFingerprint(videoPath, chunkSize)
+	}
+
 	fileInfo, err := os.Stat(videoPath)
 	if err != nil {
 		return "", err
@@ -192,6 +231,8 @@ func (vc *VideoAnalysisCache) Get(videoPath string) (*analyzer.VideoAnalysis, *f
 		return nil, nil, false, err
 	}
 
+	vc.touch(fingerprint)
+
 	vc.Logger.Debug("Found cached analysis for %s (cached: %s)", videoPath, dateCached)
 	return &analysis, &videoInfo, true, nil
 }
@@ -231,8 +272,8 @@ func (vc *VideoAnalysisCache) Put(videoPath string, analysis *analyzer.VideoAnal
 	// Store in database
 	_, err = vc.DB.Exec(`
 		INSERT OR REPLACE INTO video_analysis 
-		(id, video_path, size_bytes, mod_time, date_cached, analysis_data, video_info, duration, resolution, codec, valid) 
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		(id, video_path, size_bytes, mod_time, date_cached, analysis_data, video_info, duration, resolution, codec, valid, last_accessed) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`, 
 		fingerprint, 
 		videoPath, 
@@ -245,6 +286,7 @@ func (vc *VideoAnalysisCache) Put(videoPath string, analysis *analyzer.VideoAnal
 		resolution,
 		videoInfo.VideoInfo.Codec,
 		true,
+		time.Now(),
 	)
 
 	if err != nil {
@@ -252,6 +294,11 @@ func (vc *VideoAnalysisCache) Put(videoPath string, analysis *analyzer.VideoAnal
 	}
 
 	vc.Logger.Debug("Cached analysis for %s", videoPath)
+
+	// Keep the cache within its configured size
+	if _, err := vc.Prune(vc.MaxEntries, vc.MaxSizeBytes); err != nil {
+		vc.Logger.Warning("Failed to evict old cache entries: %v", err)
+	}
 	return nil
 }
 
@@ -289,6 +336,10 @@ func (vc *VideoAnalysisCache) CleanExpiredEntries() (int64, error) {
 		return 0, err
 	}
 
+	if _, err := vc.DB.Exec("DELETE FROM compression_outcomes WHERE date_cached < ?", cutoffTime); err != nil {
+		return 0, err
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return 0, err
@@ -0,0 +1,11 @@
+//go:build cgo
+
+package cache
+
+import (
+	_ "github.com/mattn/go-sqlite3" // Import SQLite driver
+)
+
+// sqliteDriver is the database/sql driver used for the cache. cgo builds use
+// mattn/go-sqlite3.
+const sqliteDriver = "sqlite3"
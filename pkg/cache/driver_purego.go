@@ -0,0 +1,11 @@
+//go:build !cgo
+
+package cache
+
+import (
+	_ "modernc.org/sqlite" // Import pure-Go SQLite driver
+)
+
+// sqliteDriver is the database/sql driver used for the cache. Builds with
+// CGO_ENABLED=0 use the pure-Go modernc.org/sqlite.
+const sqliteDriver = "sqlite"
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// FingerprintMode selects how video files are identified in the cache
+type FingerprintMode string
+
+const (
+	// FingerprintPath identifies files by path, size and modification time
+	FingerprintPath FingerprintMode = "path"
+	// FingerprintContent identifies files by size and a hash of their first
+	// and last bytes, so entries survive renames and copies
+	FingerprintContent FingerprintMode = "content"
+)
+
+// DefaultContentHashBytes is how much of each end of the file is hashed in
+// content mode
+const DefaultContentHashBytes = 4 * 1024 * 1024
+
+// ParseFingerprintMode converts a flag value into a FingerprintMode
+func ParseFingerprintMode(value string) (FingerprintMode, error) {
+	switch mode := FingerprintMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "", FingerprintPath:
+		return FingerprintPath, nil
+	case FingerprintContent:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid cache fingerprint mode: %s (use path or content)", value)
+	}
+}
+
+// SetFingerprintMode sets how video files are identified in the cache
+func (vc *VideoAnalysisCache) SetFingerprintMode(mode FingerprintMode) {
+	vc.FingerprintMode = mode
+}
+
+// contentFingerprint hashes the file size together with the first and last
+// chunkSize bytes of the file
+func contentFingerprint(videoPath string, chunkSize int64) (string, error) {
+	f, err := os.Open(videoPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	h := sha256.New()
+	io.WriteString(h, fmt.Sprintf("%d\n", size))
+
+	// Small files are hashed whole
+	if size <= 2*chunkSize {
+		if _, err := io.Copy(h, f); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(h.Sum(nil)), nil
+	}
+
+	if _, err := io.CopyN(h, f, chunkSize); err != nil {
+		return "", err
+	}
+	if _, err := f.Seek(size-chunkSize, io.SeekStart); err != nil {
+		return "", err
+	}
+	if _, err := io.CopyN(h, f, chunkSize); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFingerprintMode(t *testing.T) {
+	mode, err := ParseFingerprintMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, FingerprintPath, mode)
+
+	mode, err = ParseFingerprintMode("Content")
+	assert.NoError(t, err)
+	assert.Equal(t, FingerprintContent, mode)
+
+	_, err = ParseFingerprintMode("inode")
+	assert.Error(t, err)
+}
+
+func TestContentFingerprintSurvivesRename(t *testing.T) {
+	cache, _ := createTestCache(t)
+	cache.SetFingerprintMode(FingerprintContent)
+	cache.ContentHashBytes = 8
+
+	dir := t.TempDir()
+	content := []byte("header-bytes....middle that is not hashed....trailer-bytes")
+	original := filepath.Join(dir, "original.mp4")
+	copied := filepath.Join(dir, "sub", "renamed.mp4")
+	assert.NoError(t, os.WriteFile(original, content, 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Dir(copied), 0755))
+	assert.NoError(t, os.WriteFile(copied, content, 0644))
+
+	a, err := cache.GetVideoFingerprint(original)
+	assert.NoError(t, err)
+	b, err := cache.GetVideoFingerprint(copied)
+	assert.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	// Changing the hashed head changes the fingerprint
+	changed := append([]byte("HEADER"), content[6:]...)
+	assert.NoError(t, os.WriteFile(copied, changed, 0644))
+	b, err = cache.GetVideoFingerprint(copied)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	// Path mode still tells the two files apart
+	cache.SetFingerprintMode(FingerprintPath)
+	assert.NoError(t, os.WriteFile(copied, content, 0644))
+	a, err = cache.GetVideoFingerprint(original)
+	assert.NoError(t, err)
+	b, err = cache.GetVideoFingerprint(copied)
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, b)
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"database/sql"
+	"os"
+	"time"
+)
+
+// LedgerEntry records a successful compression. Sources and outputs are
+// identified by content fingerprint, so entries survive renames and moves of
+// either file.
+type LedgerEntry struct {
+	SourcePath     string    // Source path when the compression was recorded
+	OutputPath     string    // Output path when the compression was recorded
+	DateCompressed time.Time // When the compression finished
+}
+
+// createLedgerTable creates the table of the compression ledger. The sizes
+// are stored so files are only hashed when a recorded file has their size.
+func createLedgerTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS compression_ledger (
+			source_fingerprint TEXT,
+			source_size INTEGER,
+			source_path TEXT,
+			output_fingerprint TEXT,
+			output_size INTEGER,
+			output_path TEXT,
+			date_compressed TIMESTAMP,
+			PRIMARY KEY (source_fingerprint, output_path)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_ledger_source_size ON compression_ledger(source_size);
+		CREATE INDEX IF NOT EXISTS idx_ledger_output_size ON compression_ledger(output_size);
+	`)
+	return err
+}
+
+// RecordCompression adds the compression of sourcePath into outputPath to
+// the ledger. Unlike analyses and outcomes, ledger entries do not expire and
+// are recorded even when the cache is disabled.
+func (vc *VideoAnalysisCache) RecordCompression(sourcePath, outputPath string) error {
+	sourceFingerprint, sourceSize, err := ledgerFingerprint(sourcePath)
+	if err != nil {
+		return err
+	}
+	outputFingerprint, outputSize, err := ledgerFingerprint(outputPath)
+	if err != nil {
+		return err
+	}
+
+	_, err = vc.DB.Exec(`
+		INSERT OR REPLACE INTO compression_ledger
+		(source_fingerprint, source_size, source_path, output_fingerprint, output_size, output_path, date_compressed)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, sourceFingerprint, sourceSize, sourcePath, outputFingerprint, outputSize, outputPath, time.Now())
+	if err != nil {
+		return err
+	}
+
+	vc.Logger.Debug("Recorded compression of %s in the ledger", sourcePath)
+	return nil
+}
+
+// CompressedTo returns the recorded compressions of the file at path, most
+// recent first
+func (vc *VideoAnalysisCache) CompressedTo(path string) ([]LedgerEntry, error) {
+	return vc.findInLedger("source", path)
+}
+
+// CompressedFrom returns the recorded compression that produced the file at
+// path, if it is the output of an earlier run
+func (vc *VideoAnalysisCache) CompressedFrom(path string) (*LedgerEntry, bool, error) {
+	entries, err := vc.findInLedger("output", path)
+	if err != nil || len(entries) == 0 {
+		return nil, false, err
+	}
+	return &entries[0], true, nil
+}
+
+// findInLedger returns the entries whose source or output (role) has the
+// content of the file at path
+func (vc *VideoAnalysisCache) findInLedger(role, path string) ([]LedgerEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Files of a size nobody recorded are not hashed
+	var count int
+	err = vc.DB.QueryRow("SELECT COUNT(*) FROM compression_ledger WHERE "+role+"_size = ?", info.Size()).Scan(&count)
+	if err != nil || count == 0 {
+		return nil, err
+	}
+
+	fingerprint, _, err := ledgerFingerprint(path)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := vc.DB.Query(`
+		SELECT source_path, output_path, date_compressed
+		FROM compression_ledger
+		WHERE `+role+`_fingerprint = ?
+		ORDER BY date_compressed DESC
+	`, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []LedgerEntry
+	for rows.Next() {
+		var entry LedgerEntry
+		if err := rows.Scan(&entry.SourcePath, &entry.OutputPath, &entry.DateCompressed); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// ledgerFingerprint identifies the file at path by content, whatever the
+// fingerprint mode of the cache, so renamed and moved files are recognized
+func ledgerFingerprint(path string) (string, int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", 0, err
+	}
+	fingerprint, err := contentFingerprint(path, DefaultContentHashBytes)
+	return fingerprint, info.Size(), err
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLedger(t *testing.T) {
+	cache, _ := createTestCache(t)
+	cache.SetEnabled(false) // The ledger does not depend on the analysis cache
+	sourcePath := createTempVideoFile(t)
+	dir := filepath.Dir(sourcePath)
+	outputPath := filepath.Join(dir, "test-video-compressed.mp4")
+	assert.NoError(t, os.WriteFile(outputPath, []byte("small"), 0644))
+
+	entries, err := cache.CompressedTo(sourcePath)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	assert.NoError(t, cache.RecordCompression(sourcePath, outputPath))
+
+	entries, err = cache.CompressedTo(sourcePath)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, outputPath, entries[0].OutputPath)
+	}
+
+	// A renamed output is still recognized as an output
+	renamed := filepath.Join(dir, "holiday.mp4")
+	assert.NoError(t, os.Rename(outputPath, renamed))
+	entry, found, err := cache.CompressedFrom(renamed)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, sourcePath, entry.SourcePath)
+
+	// A moved source is still known to be compressed
+	moved := filepath.Join(dir, "archive.mp4")
+	assert.NoError(t, os.Rename(sourcePath, moved))
+	entries, err = cache.CompressedTo(moved)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Files that only share a name are not
+	assert.NoError(t, os.WriteFile(sourcePath, []byte("another video of the same name"), 0644))
+	entries, err = cache.CompressedTo(sourcePath)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+	_, found, err = cache.CompressedFrom(sourcePath)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
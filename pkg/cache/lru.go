@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ensureLastAccessed adds the last_accessed column used for LRU eviction to
+// databases created before it existed
+func ensureLastAccessed(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(video_analysis)")
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			rows.Close()
+			return err
+		}
+		if name == "last_accessed" {
+			found = true
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		if _, err := db.Exec("ALTER TABLE video_analysis ADD COLUMN last_accessed TIMESTAMP"); err != nil {
+			return err
+		}
+		if _, err := db.Exec("UPDATE video_analysis SET last_accessed = date_cached WHERE last_accessed IS NULL"); err != nil {
+			return err
+		}
+	}
+
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_last_accessed ON video_analysis(last_accessed)")
+	return err
+}
+
+// SetSizeLimit sets the maximum number of entries and the maximum size in
+// bytes of the cached data. Zero means no limit. Least recently used entries
+// are evicted when a new entry exceeds the limits.
+func (vc *VideoAnalysisCache) SetSizeLimit(maxEntries int, maxBytes int64) {
+	vc.MaxEntries = maxEntries
+	vc.MaxSizeBytes = maxBytes
+}
+
+// touch records that a cache entry was just used
+func (vc *VideoAnalysisCache) touch(fingerprint string) {
+	_, err := vc.DB.Exec("UPDATE video_analysis SET last_accessed = ? WHERE id = ?", time.Now(), fingerprint)
+	if err != nil {
+		vc.Logger.Debug("Failed to update cache access time: %v", err)
+	}
+}
+
+// Usage returns the number of cached analyses and the size in bytes of the
+// cached data
+func (vc *VideoAnalysisCache) Usage() (int64, int64, error) {
+	var entries int64
+	var size sql.NullInt64
+	err := vc.DB.QueryRow(`
+		SELECT COUNT(*), SUM(LENGTH(analysis_data) + LENGTH(video_info))
+		FROM video_analysis
+	`).Scan(&entries, &size)
+	if err != nil {
+		return 0, 0, err
+	}
+	return entries, size.Int64, nil
+}
+
+// Prune evicts the least recently used entries until the cache holds at most
+// maxEntries entries and maxBytes bytes of data. Zero means no limit. It
+// returns the number of evicted entries.
+func (vc *VideoAnalysisCache) Prune(maxEntries int, maxBytes int64) (int64, error) {
+	if !vc.Enabled || (maxEntries <= 0 && maxBytes <= 0) {
+		return 0, nil
+	}
+
+	entries, size, err := vc.Usage()
+	if err != nil {
+		return 0, err
+	}
+
+	overLimit := func() bool {
+		return (maxEntries > 0 && entries > int64(maxEntries)) || (maxBytes > 0 && size > maxBytes)
+	}
+	if !overLimit() {
+		return 0, nil
+	}
+
+	rows, err := vc.DB.Query(`
+		SELECT id, LENGTH(analysis_data) + LENGTH(video_info)
+		FROM video_analysis
+		ORDER BY COALESCE(last_accessed, date_cached) ASC
+	`)
+	if err != nil {
+		return 0, err
+	}
+
+	var evict []string
+	for rows.Next() && overLimit() {
+		var id string
+		var entrySize int64
+		if err := rows.Scan(&id, &entrySize); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		evict = append(evict, id)
+		entries--
+		size -= entrySize
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := vc.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	for _, id := range evict {
+		if _, err := tx.Exec("DELETE FROM video_analysis WHERE id = ?", id); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if _, err := tx.Exec("DELETE FROM compression_outcomes WHERE fingerprint = ?", id); err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if len(evict) > 0 {
+		vc.Logger.Debug("Evicted %d least recently used cache entries", len(evict))
+	}
+	return int64(len(evict)), nil
+}
@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPruneEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, _ := createTestCache(t)
+	analysis, videoFile := createTestVideoAnalysis()
+
+	first := createTempVideoFile(t)
+	second := createTempVideoFile(t)
+	third := createTempVideoFile(t)
+	for _, path := range []string{first, second, third} {
+		assert.NoError(t, cache.Put(path, analysis, videoFile))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Using the first entry makes the second one the least recently used
+	_, _, found, err := cache.Get(first)
+	assert.NoError(t, err)
+	assert.True(t, found)
+
+	entries, size, err := cache.Usage()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), entries)
+	assert.True(t, size > 0)
+
+	evicted, err := cache.Prune(2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), evicted)
+
+	_, _, found, _ = cache.Get(second)
+	assert.False(t, found)
+	_, _, found, _ = cache.Get(first)
+	assert.True(t, found)
+	_, _, found, _ = cache.Get(third)
+	assert.True(t, found)
+
+	// A size limit smaller than one entry empties the cache
+	evicted, err = cache.Prune(0, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), evicted)
+}
+
+func TestPutEnforcesSizeLimit(t *testing.T) {
+	cache, _ := createTestCache(t)
+	cache.SetSizeLimit(1, 0)
+	analysis, videoFile := createTestVideoAnalysis()
+
+	assert.NoError(t, cache.Put(createTempVideoFile(t), analysis, videoFile))
+	time.Sleep(10 * time.Millisecond)
+	latest := createTempVideoFile(t)
+	assert.NoError(t, cache.Put(latest, analysis, videoFile))
+
+	entries, _, err := cache.Usage()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), entries)
+
+	_, _, found, _ := cache.Get(latest)
+	assert.True(t, found)
+}
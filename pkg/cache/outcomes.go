@@ -0,0 +1,172 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+)
+
+// CompressionOutcome is the cached result of compressing a file with a given
+// set of parameters
+type CompressionOutcome struct {
+	Fingerprint      string            // Fingerprint of the input file
+	ParamsHash       string            // Hash of the parameters used (see HashParams)
+	InputPath        string            // Input path when the outcome was recorded
+	OutputPath       string            // Output file that was produced
+	OriginalSize     int64             // Size of the input in bytes
+	CompressedSize   int64             // Size of the output in bytes
+	CompressionRatio float64           // Original size / compressed size
+	SavedPercent     float64           // Space saved in percent
+	VMAFScore        float64           // Predicted VMAF, when a target VMAF was used
+	ProcessingTime   time.Duration     // Time the encode took
+	Settings         map[string]string // Final encoder settings
+	DateCached       time.Time         // When the outcome was recorded
+}
+
+// createOutcomesTable creates the table holding compression outcomes
+func createOutcomesTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS compression_outcomes (
+			fingerprint TEXT,
+			params_hash TEXT,
+			input_path TEXT,
+			output_path TEXT,
+			original_size INTEGER,
+			compressed_size INTEGER,
+			compression_ratio REAL,
+			saved_percent REAL,
+			vmaf REAL,
+			processing_seconds REAL,
+			settings BLOB,
+			date_cached TIMESTAMP,
+			PRIMARY KEY (fingerprint, params_hash)
+		);
+	`)
+	return err
+}
+
+// HashParams returns a stable hash of the parameters that determine the
+// output of a compression run (quality, preset, tool version, ...)
+func HashParams(params map[string]string) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		io.WriteString(h, fmt.Sprintf("%s=%s\n", key, params[key]))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// GetOutcome returns the recorded outcome of compressing videoPath with the
+// given parameters, if any
+func (vc *VideoAnalysisCache) GetOutcome(videoPath, paramsHash string) (*CompressionOutcome, bool, error) {
+	if !vc.Enabled {
+		return nil, false, nil
+	}
+
+	fingerprint, err := vc.GetVideoFingerprint(videoPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	outcome := &CompressionOutcome{Fingerprint: fingerprint, ParamsHash: paramsHash}
+	var seconds float64
+	var settingsData []byte
+
+	err = vc.DB.QueryRow(`
+		SELECT input_path, output_path, original_size, compressed_size, compression_ratio,
+			saved_percent, vmaf, processing_seconds, settings, date_cached
+		FROM compression_outcomes
+		WHERE fingerprint = ? AND params_hash = ?
+	`, fingerprint, paramsHash).Scan(
+		&outcome.InputPath,
+		&outcome.OutputPath,
+		&outcome.OriginalSize,
+		&outcome.CompressedSize,
+		&outcome.CompressionRatio,
+		&outcome.SavedPercent,
+		&outcome.VMAFScore,
+		&seconds,
+		&settingsData,
+		&outcome.DateCached,
+	)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if time.Since(outcome.DateCached).Hours() > float64(vc.MaxAgeHours) {
+		return nil, false, nil
+	}
+
+	outcome.ProcessingTime = time.Duration(seconds * float64(time.Second))
+	if err := json.Unmarshal(settingsData, &outcome.Settings); err != nil {
+		return nil, false, err
+	}
+
+	return outcome, true, nil
+}
+
+// PutOutcome records the result of compressing videoPath with the given parameters
+func (vc *VideoAnalysisCache) PutOutcome(videoPath, paramsHash string, result *compressor.CompressionResult) error {
+	if !vc.Enabled || result == nil || result.Error != nil {
+		return nil
+	}
+
+	fingerprint, err := vc.GetVideoFingerprint(videoPath)
+	if err != nil {
+		return err
+	}
+
+	settingsData, err := json.Marshal(result.Settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = vc.DB.Exec(`
+		INSERT OR REPLACE INTO compression_outcomes
+		(fingerprint, params_hash, input_path, output_path, original_size, compressed_size,
+			compression_ratio, saved_percent, vmaf, processing_seconds, settings, date_cached)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		fingerprint,
+		paramsHash,
+		videoPath,
+		result.OutputFile,
+		result.OriginalSize,
+		result.CompressedSize,
+		result.CompressionRatio,
+		result.SavedSpacePercent,
+		result.VMAFScore,
+		result.ProcessingTime.Seconds(),
+		settingsData,
+		time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	vc.Logger.Debug("Cached compression outcome for %s", videoPath)
+	return nil
+}
+
+// OutputIntact reports whether the recorded output file still exists with the
+// recorded size
+func (o *CompressionOutcome) OutputIntact() bool {
+	info, err := os.Stat(o.OutputPath)
+	return err == nil && info.Size() == o.CompressedSize
+}
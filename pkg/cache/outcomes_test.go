@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashParams(t *testing.T) {
+	a := HashParams(map[string]string{"quality": "3", "preset": "balanced"})
+	b := HashParams(map[string]string{"preset": "balanced", "quality": "3"})
+	c := HashParams(map[string]string{"quality": "4", "preset": "balanced"})
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
+
+func TestOutcomeRoundTrip(t *testing.T) {
+	cache, _ := createTestCache(t)
+	videoPath := createTempVideoFile(t)
+	outputPath := filepath.Join(filepath.Dir(videoPath), "out.mp4")
+	assert.NoError(t, os.WriteFile(outputPath, []byte("small"), 0644))
+
+	hash := HashParams(map[string]string{"quality": "3"})
+
+	_, found, err := cache.GetOutcome(videoPath, hash)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	result := &compressor.CompressionResult{
+		OutputFile:        outputPath,
+		OriginalSize:      33,
+		CompressedSize:    5,
+		CompressionRatio:  6.6,
+		SavedSpacePercent: 84.8,
+		VMAFScore:         94.5,
+		ProcessingTime:    90 * time.Second,
+		Settings:          map[string]string{"codec": "libx265", "crf": "24"},
+	}
+	assert.NoError(t, cache.PutOutcome(videoPath, hash, result))
+
+	outcome, found, err := cache.GetOutcome(videoPath, hash)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, outputPath, outcome.OutputPath)
+	assert.Equal(t, int64(5), outcome.CompressedSize)
+	assert.Equal(t, 94.5, outcome.VMAFScore)
+	assert.Equal(t, 90*time.Second, outcome.ProcessingTime)
+	assert.Equal(t, "libx265", outcome.Settings["codec"])
+	assert.True(t, outcome.OutputIntact())
+
+	// Different parameters must not match
+	_, found, err = cache.GetOutcome(videoPath, HashParams(map[string]string{"quality": "5"}))
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// A modified output is no longer intact
+	assert.NoError(t, os.WriteFile(outputPath, []byte("changed output"), 0644))
+	assert.False(t, outcome.OutputIntact())
+}
@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// DefaultSimilarityTolerance is the duration band, as a fraction, within which
+// cached videos are considered similar
+const DefaultSimilarityTolerance = 0.2
+
+// SimilarAnalysis looks for a cached video with the same resolution and codec
+// and a duration within tolerance of videoFile, and returns its analysis
+// adapted to videoFile as a prior. The path of the video the analysis came
+// from is returned alongside it.
+func (vc *VideoAnalysisCache) SimilarAnalysis(videoFile *ffmpeg.VideoFile, tolerance float64) (*analyzer.VideoAnalysis, string, bool, error) {
+	if !vc.Enabled || videoFile == nil {
+		return nil, "", false, nil
+	}
+
+	resolution := fmt.Sprintf("%dx%d", videoFile.VideoInfo.Width, videoFile.VideoInfo.Height)
+	entries, err := vc.FindSimilarVideos(resolution, videoFile.Duration, videoFile.VideoInfo.Codec, tolerance)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	// Prefer the entry with the closest duration
+	var best *CacheEntry
+	for _, entry := range entries {
+		if entry.VideoPath == videoFile.Path {
+			continue
+		}
+		if best == nil || math.Abs(entry.Duration-videoFile.Duration) < math.Abs(best.Duration-videoFile.Duration) {
+			best = entry
+		}
+	}
+	if best == nil {
+		return nil, "", false, nil
+	}
+
+	var analysis analyzer.VideoAnalysis
+	if err := json.Unmarshal(best.AnalysisData, &analysis); err != nil {
+		return nil, "", false, err
+	}
+
+	// Scene changes scale with the length of the video
+	if best.Duration > 0 {
+		analysis.SceneChanges = int(math.Round(float64(analysis.SceneChanges) * videoFile.Duration / best.Duration))
+	}
+	analysis.VideoFile = videoFile
+
+	vc.Logger.Debug("Using analysis of %s as a prior for %s", best.VideoPath, videoFile.Path)
+	return &analysis, best.VideoPath, true, nil
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarAnalysis(t *testing.T) {
+	cache, _ := createTestCache(t)
+	analysis, videoFile := createTestVideoAnalysis()
+	cachedPath := createTempVideoFile(t)
+	assert.NoError(t, cache.Put(cachedPath, analysis, videoFile))
+
+	// Same resolution and codec, 10% longer
+	_, newFile := createTestVideoAnalysis()
+	newFile.Path = "/test/path/other.mp4"
+	newFile.Duration = 66.0
+
+	prior, source, found, err := cache.SimilarAnalysis(newFile, DefaultSimilarityTolerance)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, cachedPath, source)
+	assert.Equal(t, analysis.ContentType, prior.ContentType)
+	assert.Equal(t, analysis.FrameComplexity, prior.FrameComplexity)
+	assert.Equal(t, 6, prior.SceneChanges)
+	assert.Equal(t, newFile, prior.VideoFile)
+
+	// A different resolution has no similar videos
+	newFile.VideoInfo.Width = 1280
+	newFile.VideoInfo.Height = 720
+	_, _, found, err = cache.SimilarAnalysis(newFile, DefaultSimilarityTolerance)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,203 @@
+package compressor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Animated image formats produced by ConvertAnimated
+const (
+	AnimatedGIF  = "gif"
+	AnimatedWebP = "webp"
+	AnimatedAVIF = "avif"
+)
+
+// AnimatedOptions controls the conversion of a clip to an animated image
+type AnimatedOptions struct {
+	Format   string  // gif, webp or avif
+	Width    int     // Output width in pixels, 0 keeps the source width
+	FPS      int     // Frame rate, 0 keeps the source frame rate
+	Start    float64 // Seconds skipped at the start of the clip
+	Duration float64 // Seconds converted, 0 for the rest of the clip
+}
+
+// gifColors maps the 1-5 quality scale to the size of the GIF palette
+var gifColors = map[int]int{1: 64, 2: 128, 3: 256, 4: 256, 5: 256}
+
+// gifDither maps the 1-5 quality scale to the paletteuse dithering. Bayer
+// dithering compresses better than error diffusion; a lower scale gives a
+// finer pattern.
+var gifDither = map[int]string{
+	1: "bayer:bayer_scale=5",
+	2: "bayer:bayer_scale=4",
+	3: "bayer:bayer_scale=3",
+	4: "bayer:bayer_scale=2",
+	5: "sierra2_4a",
+}
+
+// webpQuality maps the 1-5 quality scale to lossy WebP quality
+var webpQuality = map[int]string{1: "50", 2: "60", 3: "70", 4: "80", 5: "90"}
+
+// avifCRF maps the 1-5 quality scale to libaom-av1 CRF values
+var avifCRF = map[int]string{1: "45", 2: "40", 3: "35", 4: "30", 5: "25"}
+
+// ValidAnimatedFormat reports whether format is gif, webp or avif
+func ValidAnimatedFormat(format string) bool {
+	return format == AnimatedGIF || format == AnimatedWebP || format == AnimatedAVIF
+}
+
+// animatedFilter returns the frame rate and scaling filters, or an empty
+// string when the clip keeps its size and frame rate
+func animatedFilter(opts AnimatedOptions) string {
+	var filters []string
+	if opts.FPS > 0 {
+		filters = append(filters, fmt.Sprintf("fps=%d", opts.FPS))
+	}
+	if opts.Width > 0 {
+		filters = append(filters, fmt.Sprintf("scale=%d:-2:flags=lanczos", opts.Width))
+	}
+	return strings.Join(filters, ",")
+}
+
+// animatedInputArgs returns the arguments that select the part of the clip
+// to convert. Seeking before -i is fast and frame accurate when re-encoding.
+func animatedInputArgs(inputFile string, opts AnimatedOptions) []string {
+	args := []string{"-y"}
+	if opts.Start > 0 {
+		args = append(args, "-ss", strconv.FormatFloat(opts.Start, 'f', 3, 64))
+	}
+	if opts.Duration > 0 {
+		args = append(args, "-t", strconv.FormatFloat(opts.Duration, 'f', 3, 64))
+	}
+	args = append(args, ffmpeg.RemoteInputArgs(inputFile)...)
+	return append(args, "-i", ffmpeg.FileArg(inputFile))
+}
+
+// paletteArgs returns the first GIF pass, which picks the palette that fits
+// the colors of the clip best. stats_mode=diff favors the moving parts.
+func paletteArgs(inputFile, paletteFile string, opts AnimatedOptions, quality int) []string {
+	palettegen := fmt.Sprintf("palettegen=max_colors=%d:stats_mode=diff", gifColors[quality])
+	if filter := animatedFilter(opts); filter != "" {
+		palettegen = filter + "," + palettegen
+	}
+	args := animatedInputArgs(inputFile, opts)
+	return append(args, "-vf", palettegen, "-frames:v", "1", "-update", "1", ffmpeg.FileArg(paletteFile))
+}
+
+// gifArgs returns the second GIF pass, which maps the clip onto the palette.
+// diff_mode=rectangle only redraws the changed part of each frame.
+func gifArgs(inputFile, paletteFile, outputFile string, opts AnimatedOptions, quality int) []string {
+	source := "[0:v]"
+	graph := ""
+	if filter := animatedFilter(opts); filter != "" {
+		graph = "[0:v]" + filter + "[x];"
+		source = "[x]"
+	}
+	graph += fmt.Sprintf("%s[1:v]paletteuse=dither=%s:diff_mode=rectangle", source, gifDither[quality])
+
+	args := animatedInputArgs(inputFile, opts)
+	return append(args, "-i", ffmpeg.FileArg(paletteFile), "-lavfi", graph, "-loop", "0", ffmpeg.FileArg(outputFile))
+}
+
+// animatedArgs returns the single pass that encodes an animated WebP or AVIF
+func animatedArgs(inputFile, outputFile string, opts AnimatedOptions, quality int) []string {
+	args := animatedInputArgs(inputFile, opts)
+	if filter := animatedFilter(opts); filter != "" {
+		args = append(args, "-vf", filter)
+	}
+	if opts.Format == AnimatedWebP {
+		args = append(args, "-c:v", "libwebp_anim", "-lossless", "0", "-q:v", webpQuality[quality],
+			"-compression_level", "6", "-loop", "0")
+	} else {
+		args = append(args, "-c:v", "libaom-av1", "-crf", avifCRF[quality], "-b:v", "0", "-cpu-used", "6",
+			"-pix_fmt", "yuv420p")
+	}
+	return append(args, "-an", ffmpeg.FileArg(outputFile))
+}
+
+// ConvertAnimated converts a clip, or the part of it selected by opts, to an
+// animated GIF, WebP or AVIF image. GIFs are made in two passes: the first
+// computes a palette for the clip and the second maps every frame onto it.
+func (vc *VideoCompressor) ConvertAnimated(inputFile, outputFile string, opts AnimatedOptions, quality int,
+	progress *util.ProgressTracker) (*CompressionResult, error) {
+	if !ValidAnimatedFormat(opts.Format) {
+		return nil, i18n.Errorf("animated format must be gif, webp or avif (got %s)", opts.Format)
+	}
+	if quality < 1 || quality > 5 {
+		quality = 3
+	}
+	startTime := time.Now()
+
+	originalSize, err := inputSize(inputFile, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Progress follows the converted part of the clip
+	duration := opts.Duration
+	if duration <= 0 {
+		if videoFile, err := vc.FFmpeg.GetVideoInfo(inputFile); err == nil {
+			duration = videoFile.Duration - opts.Start
+		}
+	}
+
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return nil, i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+
+	var args []string
+	switch opts.Format {
+	case AnimatedGIF:
+		tempDir := vc.TempDir
+		if tempDir == "" {
+			if tempDir, err = util.NewJobTempDir(); err != nil {
+				return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+			}
+			defer os.RemoveAll(tempDir)
+		}
+		paletteFile := filepath.Join(tempDir, "palette.png")
+
+		paletteCmd := paletteArgs(inputFile, paletteFile, opts, quality)
+		vc.Logger.Debug("Running FFmpeg command: %s %s", ffmpegPath, strings.Join(paletteCmd, " "))
+		if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, paletteCmd); err != nil {
+			return nil, fmt.Errorf("FFmpeg error: %w", err)
+		}
+		args = gifArgs(inputFile, paletteFile, outputFile, opts, quality)
+	default:
+		args = animatedArgs(inputFile, outputFile, opts, quality)
+	}
+
+	vc.Logger.Debug("Running FFmpeg command: %s %s", ffmpegPath, strings.Join(args, " "))
+	if err := vc.runWithProgress(ffmpegPath, args, duration, progress); err != nil {
+		return nil, err
+	}
+
+	result := &CompressionResult{
+		InputFile:      inputFile,
+		OutputFile:     outputFile,
+		OriginalSize:   originalSize,
+		ProcessingTime: time.Since(startTime),
+		Settings:       map[string]string{"codec": opts.Format},
+	}
+	result.CompressedSize, err = fileSize(outputFile)
+	if err != nil || result.CompressedSize == 0 {
+		result.Error = fmt.Errorf("%w: no image was written to %s", ErrVerificationFailed, outputFile)
+		return result, result.Error
+	}
+	if originalSize > 0 {
+		result.SavedSpaceBytes = originalSize - result.CompressedSize
+		result.CompressionRatio = float64(originalSize) / float64(result.CompressedSize)
+		result.SavedSpacePercent = float64(result.SavedSpaceBytes) / float64(originalSize) * 100
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package compressor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnimatedArgs(t *testing.T) {
+	opts := AnimatedOptions{Format: AnimatedGIF, Width: 480, FPS: 15, Start: 2.5, Duration: 4}
+	assert.Equal(t, "fps=15,scale=480:-2:flags=lanczos", animatedFilter(opts))
+	assert.Empty(t, animatedFilter(AnimatedOptions{}))
+
+	// The palette pass and the encode pass see the same frames
+	args := paletteArgs("clip.mp4", "palette.png", opts, 1)
+	assert.Equal(t, []string{"-y", "-ss", "2.500", "-t", "4.000", "-i", "clip.mp4",
+		"-vf", "fps=15,scale=480:-2:flags=lanczos,palettegen=max_colors=64:stats_mode=diff",
+		"-frames:v", "1", "-update", "1", "palette.png"}, args)
+
+	args = gifArgs("clip.mp4", "palette.png", "clip.gif", opts, 3)
+	assert.Equal(t, []string{"-y", "-ss", "2.500", "-t", "4.000", "-i", "clip.mp4", "-i", "palette.png",
+		"-lavfi", "[0:v]fps=15,scale=480:-2:flags=lanczos[x];[x][1:v]paletteuse=dither=bayer:bayer_scale=3:diff_mode=rectangle",
+		"-loop", "0", "clip.gif"}, args)
+
+	// Without filters the palette is applied to the decoded frames directly
+	args = gifArgs("clip.mp4", "palette.png", "clip.gif", AnimatedOptions{Format: AnimatedGIF}, 5)
+	assert.Contains(t, args, "[0:v][1:v]paletteuse=dither=sierra2_4a:diff_mode=rectangle")
+
+	args = animatedArgs("clip.mp4", "clip.webp", AnimatedOptions{Format: AnimatedWebP, Width: 320}, 4)
+	assert.Equal(t, []string{"-y", "-i", "clip.mp4", "-vf", "scale=320:-2:flags=lanczos",
+		"-c:v", "libwebp_anim", "-lossless", "0", "-q:v", "80", "-compression_level", "6", "-loop", "0",
+		"-an", "clip.webp"}, args)
+
+	args = animatedArgs("clip.mp4", "clip.avif", AnimatedOptions{Format: AnimatedAVIF}, 3)
+	assert.Contains(t, args, "libaom-av1")
+	assert.Contains(t, args, "35")
+	assert.Equal(t, "clip.avif", args[len(args)-1])
+}
+
+func TestConvertAnimated(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+	runner := &fakeRunner{}
+	ff := ffmpeg.NewFFmpeg("clip.mp4", "", nil, logger)
+	ff.Runner = runner
+	vc := NewVideoCompressor(ff, nil, logger)
+	vc.TempDir = t.TempDir()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "clip.mp4")
+	output := filepath.Join(dir, "clip.gif")
+	assert.NoError(t, os.WriteFile(input, make([]byte, 4000), 0644))
+	assert.NoError(t, os.WriteFile(output, make([]byte, 1000), 0644))
+
+	opts := AnimatedOptions{Format: AnimatedGIF, Width: 480, FPS: 15}
+	result, err := vc.ConvertAnimated(input, output, opts, 3, util.NewProgressTracker(100, "Converting", logger))
+	assert.NoError(t, err)
+	assert.Equal(t, 75.0, result.SavedSpacePercent)
+
+	// ffprobe for the duration, then the palette and GIF passes
+	if assert.Len(t, runner.calls, 3) {
+		assert.Equal(t, "ffprobe", runner.calls[0][0])
+		palette := filepath.Join(vc.TempDir, "palette.png")
+		assert.Equal(t, paletteArgs(input, palette, opts, 3), runner.calls[1][1:])
+		assert.Equal(t, gifArgs(input, palette, output, opts, 3), runner.calls[2][1:])
+	}
+
+	_, err = vc.ConvertAnimated(input, output, AnimatedOptions{Format: "png"}, 3, nil)
+	assert.Error(t, err)
+}
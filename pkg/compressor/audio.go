@@ -0,0 +1,101 @@
+package compressor
+
+import (
+	"strconv"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Audio channel modes accepted by --audio-channels
+const (
+	AudioChannelsKeep   = "keep"
+	AudioChannelsStereo = "stereo"
+	AudioChannelsMono   = "mono"
+)
+
+// monoAudioBitrate is the AAC bitrate used for a track downmixed to mono
+const monoAudioBitrate = "96k"
+
+// stereoDownmix holds pan filters that fold surround layouts into stereo.
+// Channel indexes are used instead of names so 5.1 and 5.1(side) share one
+// filter. Front channels stay at unity and center and surrounds are mixed in
+// at -3 dB (ITU-R BS.775), which keeps dialog at its original loudness
+// instead of the quieter normalized mix FFmpeg's -ac produces; LFE is dropped.
+var stereoDownmix = map[int]string{
+	6: "pan=stereo|FL=c0+0.707*c2+0.707*c4|FR=c1+0.707*c2+0.707*c5",
+	8: "pan=stereo|FL=c0+0.707*c2+0.707*c4+0.707*c6|FR=c1+0.707*c2+0.707*c5+0.707*c7",
+}
+
+// monoDownmix folds a stereo pair into one channel at equal gain
+const monoDownmix = "pan=mono|c0=0.5*c0+0.5*c1"
+
+// ValidAudioChannels reports whether mode is a supported --audio-channels value
+func ValidAudioChannels(mode string) bool {
+	switch mode {
+	case "", AudioChannelsKeep, AudioChannelsStereo, AudioChannelsMono:
+		return true
+	}
+	return false
+}
+
+// downmixFilter returns the audio filter that turns channels into the
+// target count, or "" when -ac alone has to do
+func downmixFilter(channels, target int) string {
+	stereo := stereoDownmix[channels]
+	if target == 2 {
+		return stereo
+	}
+	if channels == 2 {
+		return monoDownmix
+	}
+	if stereo != "" {
+		return stereo + "," + monoDownmix
+	}
+	return ""
+}
+
+// ApplyAudioChannels downmixes the audio to the channel layout selected by
+// mode. audio holds the streams that reach the output, see MappedAudio, and
+// the one with the most channels decides the filter. Streams of different
+// layouts get FFmpeg's own downmix, as one filter cannot fit them all.
+// Audio that would be copied is re-encoded to AAC.
+func ApplyAudioChannels(settings map[string]string, audio []ffmpeg.AudioStreamInfo, mode string) error {
+	if !ValidAudioChannels(mode) {
+		return i18n.Errorf("audio channels must be one of: stereo, mono, keep (got %s)", mode)
+	}
+	if mode == "" || mode == AudioChannelsKeep || settings["audio_codec"] == "" {
+		return nil
+	}
+
+	target := 2
+	if mode == AudioChannelsMono {
+		target = 1
+	}
+
+	channels, mixed := 0, false
+	for _, stream := range audio {
+		if channels != 0 && stream.Channels != channels {
+			mixed = true
+		}
+		if stream.Channels > channels {
+			channels = stream.Channels
+		}
+	}
+	if channels <= target {
+		return nil
+	}
+
+	if settings["audio_codec"] == "copy" {
+		settings["audio_codec"] = "aac"
+		settings["audio_bitrate"] = defaultAudioBitrate
+		if target == 1 {
+			settings["audio_bitrate"] = monoAudioBitrate
+		}
+	}
+	if filter := downmixFilter(channels, target); filter != "" && !mixed {
+		settings["audio_filter"] = filter
+	}
+	settings["audio_channels"] = strconv.Itoa(target)
+	return nil
+}
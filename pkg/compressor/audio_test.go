@@ -0,0 +1,58 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyAudioChannelsStereo(t *testing.T) {
+	surround := []ffmpeg.AudioStreamInfo{{Codec: "ac3", Channels: 6}}
+	settings := map[string]string{"codec": "libx265", "audio_codec": "copy"}
+	assert.NoError(t, ApplyAudioChannels(settings, surround, AudioChannelsStereo))
+	assert.Equal(t, "aac", settings["audio_codec"])
+	assert.Equal(t, "192k", settings["audio_bitrate"])
+	assert.Equal(t, stereoDownmix[6], settings["audio_filter"])
+	assert.Equal(t, "2", settings["audio_channels"])
+
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mkv", "out.mkv", settings)
+	assert.Contains(t, args, "-af")
+	assert.Equal(t, "2", args[len(args)-2])
+
+	// An already re-encoded track keeps its bitrate
+	settings = map[string]string{"audio_codec": "aac", "audio_bitrate": "128k"}
+	assert.NoError(t, ApplyAudioChannels(settings, []ffmpeg.AudioStreamInfo{{Channels: 8}}, AudioChannelsStereo))
+	assert.Equal(t, "128k", settings["audio_bitrate"])
+	assert.Equal(t, stereoDownmix[8], settings["audio_filter"])
+}
+
+func TestApplyAudioChannelsMono(t *testing.T) {
+	settings := map[string]string{"audio_codec": "copy"}
+	assert.NoError(t, ApplyAudioChannels(settings, []ffmpeg.AudioStreamInfo{{Channels: 6}}, AudioChannelsMono))
+	assert.Equal(t, stereoDownmix[6]+","+monoDownmix, settings["audio_filter"])
+	assert.Equal(t, "96k", settings["audio_bitrate"])
+	assert.Equal(t, "1", settings["audio_channels"])
+
+	// Layouts without a known downmix rely on -ac
+	settings = map[string]string{"audio_codec": "copy"}
+	assert.NoError(t, ApplyAudioChannels(settings, []ffmpeg.AudioStreamInfo{{Channels: 4}}, AudioChannelsStereo))
+	assert.NotContains(t, settings, "audio_filter")
+	assert.Equal(t, "2", settings["audio_channels"])
+}
+
+func TestApplyAudioChannelsNoChange(t *testing.T) {
+	stereo := []ffmpeg.AudioStreamInfo{{Channels: 2}}
+	settings := map[string]string{"audio_codec": "copy"}
+	assert.NoError(t, ApplyAudioChannels(settings, stereo, AudioChannelsStereo))
+	assert.NoError(t, ApplyAudioChannels(settings, stereo, AudioChannelsKeep))
+	assert.Equal(t, map[string]string{"audio_codec": "copy"}, settings)
+
+	// No audio stream, nothing to downmix
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyAudioChannels(settings, nil, AudioChannelsMono))
+	assert.NotContains(t, settings, "audio_channels")
+
+	assert.Error(t, ApplyAudioChannels(settings, stereo, "quad"))
+}
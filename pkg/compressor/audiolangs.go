@@ -0,0 +1,162 @@
+package compressor
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// languageCodeRegex matches ISO 639 language codes such as en or por,
+// optionally with a region as in pt-BR
+var languageCodeRegex = regexp.MustCompile(`^[a-z]{2,3}([-_][a-z]{2,4})?$`)
+
+// languageAliases maps the three-letter codes containers tag streams with,
+// both bibliographic and terminologic, to the two-letter code of the
+// language, so eng, en and en-US name the same tracks
+var languageAliases = map[string]string{
+	"eng": "en", "por": "pt", "spa": "es", "fra": "fr", "fre": "fr",
+	"deu": "de", "ger": "de", "ita": "it", "jpn": "ja", "zho": "zh",
+	"chi": "zh", "kor": "ko", "rus": "ru", "nld": "nl", "dut": "nl",
+	"pol": "pl", "swe": "sv", "nor": "no", "dan": "da", "fin": "fi",
+	"tur": "tr", "ara": "ar", "heb": "he", "hin": "hi", "ces": "cs",
+	"cze": "cs", "ell": "el", "gre": "el", "hun": "hu", "ron": "ro",
+	"rum": "ro", "ukr": "uk", "tha": "th", "vie": "vi", "ind": "id",
+	"cat": "ca", "hrv": "hr", "srp": "sr", "bul": "bg", "slk": "sk",
+	"slo": "sk",
+}
+
+// ParseLanguages splits a --keep-audio-langs list such as "eng,por" into
+// language codes, in order of preference
+func ParseLanguages(list string) ([]string, error) {
+	var languages []string
+	for _, code := range strings.Split(list, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		if !languageCodeRegex.MatchString(code) {
+			return nil, i18n.Errorf("invalid language code %s (use codes such as en, eng or pt-BR)", code)
+		}
+		languages = append(languages, code)
+	}
+	if len(languages) == 0 && strings.TrimSpace(list) != "" {
+		return nil, i18n.Errorf("no language codes in %s", list)
+	}
+	return languages, nil
+}
+
+// languageKey returns the two-letter code of a language tag, or the tag
+// itself for languages without an alias
+func languageKey(code string) string {
+	code = strings.ToLower(code)
+	code, _, _ = strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	if alias, ok := languageAliases[code]; ok {
+		return alias
+	}
+	return code
+}
+
+// SelectAudioStreams returns the positions of the audio streams in the
+// given languages, ordered by the list. Within a language the stream the
+// source marks as default comes first, then the others in source order.
+// Untagged streams match no language.
+func SelectAudioStreams(audio []ffmpeg.AudioStreamInfo, languages []string) []int {
+	var selected []int
+	seen := make(map[string]bool)
+	for _, language := range languages {
+		key := languageKey(language)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		var streams []int
+		for i, stream := range audio {
+			if stream.Language == "" || languageKey(stream.Language) != key {
+				continue
+			}
+			if stream.Default {
+				streams = append([]int{i}, streams...)
+			} else {
+				streams = append(streams, i)
+			}
+		}
+		selected = append(selected, streams...)
+	}
+	return selected
+}
+
+// ApplyAudioLanguages keeps only the audio streams in the given languages.
+// The output gets them in the order of the list, and the first is its only
+// default track. It reports false, leaving the settings as they are, when
+// no stream matches.
+func ApplyAudioLanguages(settings map[string]string, audio []ffmpeg.AudioStreamInfo, languages []string) bool {
+	if len(languages) == 0 || settings["audio_codec"] == "" || settings["no_audio"] == "1" {
+		return true
+	}
+	selected := SelectAudioStreams(audio, languages)
+	if len(selected) == 0 {
+		return false
+	}
+	positions := make([]string, len(selected))
+	for i, stream := range selected {
+		positions[i] = strconv.Itoa(stream)
+	}
+	settings["audio_streams"] = strings.Join(positions, ",")
+	return true
+}
+
+// keptAudioStreams returns the positions of the audio streams
+// ApplyAudioLanguages kept, or nil when FFmpeg picks the audio
+func keptAudioStreams(settings map[string]string) []int {
+	if settings["audio_streams"] == "" || settings["no_audio"] == "1" || settings["audio_codec"] == "" {
+		return nil
+	}
+	var streams []int
+	for _, position := range strings.Split(settings["audio_streams"], ",") {
+		if stream, err := strconv.Atoi(position); err == nil {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// MappedAudio returns the audio streams of the source that reach the
+// output: those ApplyAudioLanguages kept, or the one FFmpeg maps itself
+func MappedAudio(settings map[string]string, audio []ffmpeg.AudioStreamInfo) []ffmpeg.AudioStreamInfo {
+	if len(audio) == 0 {
+		return nil
+	}
+	kept := keptAudioStreams(settings)
+	if kept == nil {
+		return []ffmpeg.AudioStreamInfo{audio[defaultAudioStream(audio)]}
+	}
+	var mapped []ffmpeg.AudioStreamInfo
+	for _, stream := range kept {
+		if stream < len(audio) {
+			mapped = append(mapped, audio[stream])
+		}
+	}
+	return mapped
+}
+
+// audioMapArgs returns the arguments that map the kept audio streams of
+// input, in order, with the first flagged as the default track and the
+// others cleared of the flag the source may have given them
+func audioMapArgs(input int, kept []int) []string {
+	var args []string
+	for _, stream := range kept {
+		args = append(args, "-map", strconv.Itoa(input)+":a:"+strconv.Itoa(stream))
+	}
+	for i := range kept {
+		disposition := "0"
+		if i == 0 {
+			disposition = "default"
+		}
+		args = append(args, "-disposition:a:"+strconv.Itoa(i), disposition)
+	}
+	return args
+}
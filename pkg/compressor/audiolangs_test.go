@@ -0,0 +1,77 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLanguages(t *testing.T) {
+	languages, err := ParseLanguages(" ENG, por ,pt-BR")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"eng", "por", "pt-br"}, languages)
+
+	languages, err = ParseLanguages("")
+	assert.NoError(t, err)
+	assert.Empty(t, languages)
+
+	for _, list := range []string{"english", "e1", ",", "pt-"} {
+		_, err := ParseLanguages(list)
+		assert.Error(t, err, list)
+	}
+}
+
+func TestSelectAudioStreams(t *testing.T) {
+	audio := []ffmpeg.AudioStreamInfo{
+		{Language: "eng", Channels: 6, Default: true},
+		{Language: "fre"},
+		{Language: "por"},
+		{},
+		{Language: "eng", Channels: 2},
+		{Language: "pt-BR", Default: true},
+	}
+
+	// The list orders the tracks; two- and three-letter codes match alike
+	assert.Equal(t, []int{5, 2, 0, 4}, SelectAudioStreams(audio, []string{"pt", "en"}))
+	assert.Equal(t, []int{1}, SelectAudioStreams(audio, []string{"fra", "fr"}))
+	assert.Empty(t, SelectAudioStreams(audio, []string{"jpn"}))
+}
+
+func TestApplyAudioLanguages(t *testing.T) {
+	audio := []ffmpeg.AudioStreamInfo{{Language: "eng", Channels: 6}, {Language: "spa", Channels: 6}, {Language: "por", Channels: 2}}
+	settings := map[string]string{"codec": "libx264", "audio_codec": "aac", "audio_bitrate": "128k"}
+	assert.True(t, ApplyAudioLanguages(settings, audio, []string{"por", "eng"}))
+	assert.Equal(t, "2,0", settings["audio_streams"])
+	assert.Equal(t, []ffmpeg.AudioStreamInfo{audio[2], audio[0]}, MappedAudio(settings, audio))
+
+	// The output maps the kept tracks and flags the first as default
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mkv", "out.mkv", settings)
+	assert.Equal(t, []string{"-y", "-i", "in.mkv", "-map", "0:v:0", "-map", "0:a:2", "-map", "0:a:0",
+		"-disposition:a:0", "default", "-disposition:a:1", "0"}, args[:13])
+	args = vc.mergeArgs("segments.txt", "in.mkv", "out.mkv", settings, audio)
+	assert.Equal(t, []string{"-f", "concat", "-safe", "0", "-i", "segments.txt", "-i", "in.mkv",
+		"-map", "0:v", "-map", "1:a:2", "-map", "1:a:0", "-disposition:a:0", "default", "-disposition:a:1", "0",
+		"-c:v", "copy", "-c:a", "aac", "-b:a", "128k", "-y", "out.mkv"}, args)
+
+	// Segments carry no audio
+	settings["no_audio"] = "1"
+	assert.NotContains(t, vc.BuildFFmpegArgs("segment.mp4", "out.mp4", settings), "-map")
+
+	// Without a match the audio is left to FFmpeg
+	settings = map[string]string{"audio_codec": "aac"}
+	assert.False(t, ApplyAudioLanguages(settings, audio, []string{"jpn"}))
+	assert.NotContains(t, settings, "audio_streams")
+	assert.Equal(t, []ffmpeg.AudioStreamInfo{audio[0]}, MappedAudio(settings, audio))
+}
+
+// TestDownmixMixedLayouts tests that kept tracks of different layouts are
+// downmixed by -ac alone
+func TestDownmixMixedLayouts(t *testing.T) {
+	settings := map[string]string{"audio_codec": "aac"}
+	audio := []ffmpeg.AudioStreamInfo{{Channels: 6}, {Channels: 2}}
+	assert.NoError(t, ApplyAudioChannels(settings, audio, AudioChannelsStereo))
+	assert.NotContains(t, settings, "audio_filter")
+	assert.Equal(t, "2", settings["audio_channels"])
+}
@@ -0,0 +1,282 @@
+package compressor
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// CompatTarget is what a family of devices reliably plays. An encode for
+// the target keeps within it whatever the analyzer would have chosen.
+type CompatTarget struct {
+	Name   string
+	Codecs []string // Codecs the devices decode, as --codec names, the preferred first
+
+	Profiles map[string]string // Profile of each codec
+	Levels   map[string]string // Highest level of each codec, which MaxSize and MaxFPS stay within
+	Tags     map[string]string // Codec tag of each codec, e.g. hvc1 that Apple players need for HEVC
+
+	MaxSize     [2]int  // Largest frame, long side by short side, so portrait video fits too
+	MaxFPS      float64 // Highest frame rate
+	PixFmt      string
+	AudioCodecs []string // Audio codecs the devices decode, others are encoded to AAC
+	MaxChannels int      // Most audio channels; more are downmixed to stereo
+	Containers  []string // Output extensions the devices open
+}
+
+// compatTargets holds the --compat targets by name
+var compatTargets = map[string]CompatTarget{
+	// Browsers without HEVC, such as Firefox and Chrome on older systems
+	"web": {
+		Codecs:      []string{"h264"},
+		Profiles:    map[string]string{"h264": "high"},
+		Levels:      map[string]string{"h264": "4.2"},
+		MaxSize:     [2]int{1920, 1080},
+		MaxFPS:      60,
+		PixFmt:      "yuv420p",
+		AudioCodecs: []string{"aac", "mp3"},
+		MaxChannels: 2,
+		Containers:  []string{".mp4", ".m4v"},
+	},
+	// Smart TVs and media players from around 2015, whose HEVC support varies
+	"tv2015": {
+		Codecs:      []string{"h264"},
+		Profiles:    map[string]string{"h264": "high"},
+		Levels:      map[string]string{"h264": "4.1"},
+		MaxSize:     [2]int{1920, 1080},
+		MaxFPS:      30,
+		PixFmt:      "yuv420p",
+		AudioCodecs: []string{"aac", "ac3", "mp3"},
+		MaxChannels: 6,
+		Containers:  []string{".mp4", ".m4v", ".mkv"},
+	},
+	// iPhones and iPads; HEVC needs iOS 11
+	"ios": {
+		Codecs:      []string{"h264", "hevc"},
+		Profiles:    map[string]string{"h264": "high", "hevc": "main"},
+		Levels:      map[string]string{"h264": "5.2", "hevc": "5.1"},
+		Tags:        map[string]string{"hevc": "hvc1"},
+		MaxSize:     [2]int{3840, 2160},
+		MaxFPS:      60,
+		PixFmt:      "yuv420p",
+		AudioCodecs: []string{"aac", "ac3", "eac3"},
+		MaxChannels: 6,
+		Containers:  []string{".mp4", ".m4v", ".mov"},
+	},
+	// Telegram's in-app player, which streams H.264 and AAC in MP4
+	"telegram": {
+		Codecs:      []string{"h264"},
+		Profiles:    map[string]string{"h264": "high"},
+		Levels:      map[string]string{"h264": "4.2"},
+		MaxSize:     [2]int{1920, 1080},
+		MaxFPS:      60,
+		PixFmt:      "yuv420p",
+		AudioCodecs: []string{"aac"},
+		MaxChannels: 2,
+		Containers:  []string{".mp4"},
+	},
+}
+
+// CompatTargetNames returns the names of the --compat targets in
+// alphabetical order
+func CompatTargetNames() []string {
+	names := make([]string, 0, len(compatTargets))
+	for name := range compatTargets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupCompat returns the --compat target called name
+func LookupCompat(name string) (CompatTarget, error) {
+	target, ok := compatTargets[strings.ToLower(name)]
+	if !ok {
+		return CompatTarget{}, i18n.Errorf("unknown compatibility target %q (use one of: %s)", name, strings.Join(CompatTargetNames(), ", "))
+	}
+	target.Name = strings.ToLower(name)
+	return target, nil
+}
+
+// Plays reports whether the target decodes codec, a --codec name
+func (t CompatTarget) Plays(codec string) bool {
+	return contains(t.Codecs, codec)
+}
+
+// OpensContainer reports whether the target opens files named like path
+func (t CompatTarget) OpensContainer(path string) bool {
+	return contains(t.Containers, strings.ToLower(filepath.Ext(path)))
+}
+
+// codecFamily returns the --codec name of an encoder, e.g. h264 for
+// libx264 and h264_nvenc
+func codecFamily(encoder string) string {
+	switch {
+	case encoder == "libx264" || strings.HasPrefix(encoder, "h264_"):
+		return "h264"
+	case encoder == "libx265" || strings.HasPrefix(encoder, "hevc_"):
+		return "hevc"
+	case encoder == "libvpx-vp9" || strings.HasPrefix(encoder, "vp9_"):
+		return "vp9"
+	case encoder == "libsvtav1" || encoder == "libaom-av1" || strings.HasPrefix(encoder, "av1_"):
+		return "av1"
+	}
+	return encoder
+}
+
+// audioCodecName returns the codec an audio encoder produces, as ffprobe
+// names it
+func audioCodecName(encoder string) string {
+	switch encoder {
+	case "libfdk_aac":
+		return "aac"
+	case "libmp3lame":
+		return "mp3"
+	case "libopus":
+		return "opus"
+	case "libvorbis":
+		return "vorbis"
+	}
+	return encoder
+}
+
+// ApplyCompat keeps settings within what target plays: its profile and
+// pixel format, a frame no larger and no faster than it decodes, and audio
+// it decodes. The level is left to ApplyLevel, see CheckLevel. audio holds
+// the streams that reach the output, see MappedAudio. Sizes and frame rates
+// set on the command line are kept. It returns the names of the settings it
+// changed, and fails when the video is encoded with a codec the target does
+// not play.
+func ApplyCompat(settings map[string]string, target CompatTarget, videoFile *ffmpeg.VideoFile, audio []ffmpeg.AudioStreamInfo) ([]string, error) {
+	if settings["codec"] == "copy" {
+		return nil, i18n.Errorf("--compat cannot be used with --copy-video")
+	}
+	codec := codecFamily(settings["codec"])
+	if !target.Plays(codec) {
+		return nil, i18n.Errorf("--compat %s devices do not play %s (use --codec %s)", target.Name, codec, target.Codecs[0])
+	}
+
+	var changed []string
+	set := func(name, value string) {
+		if value != "" && settings[name] != value {
+			settings[name] = value
+			changed = append(changed, name)
+		}
+	}
+	set("profile", target.Profiles[codec])
+	set("video_tag", target.Tags[codec])
+	set("pix_fmt", target.PixFmt)
+
+	// Shrink and slow down video beyond the level
+	var filters VideoFilters
+	if videoFile != nil && ffmpeg.VideoFilter(settings, ffmpeg.FilterScale) == "" {
+		filters.Scale = compatSize(settings, videoFile, target.MaxSize)
+	}
+	if videoFile != nil && ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS) == "" {
+		filters.FPS = compatFPS(videoFile.VideoInfo.FPS, target.MaxFPS)
+	}
+	if err := ApplyVideoFilters(settings, filters, videoFile); err != nil {
+		return nil, err
+	}
+	if filters.Scale != "" {
+		changed = append(changed, "scale")
+	}
+	if filters.FPS > 0 {
+		changed = append(changed, "fps")
+	}
+
+	// Audio the devices cannot decode is encoded to AAC
+	if audioCodec := settings["audio_codec"]; audioCodec != "" && settings["no_audio"] != "1" {
+		playable := audioCodec != "copy" && contains(target.AudioCodecs, audioCodecName(audioCodec))
+		if audioCodec == "copy" {
+			playable = true
+			for _, stream := range audio {
+				playable = playable && contains(target.AudioCodecs, stream.Codec)
+			}
+		}
+		if !playable {
+			set("audio_codec", "aac")
+			if settings["audio_bitrate"] == "" {
+				settings["audio_bitrate"] = defaultAudioBitrate
+			}
+		}
+
+		channels := 0
+		for _, stream := range audio {
+			if stream.Channels > channels {
+				channels = stream.Channels
+			}
+		}
+		if target.MaxChannels > 0 && channels > target.MaxChannels && settings["audio_channels"] == "" {
+			if err := ApplyAudioChannels(settings, audio, AudioChannelsStereo); err != nil {
+				return nil, err
+			}
+			changed = append(changed, "audio_channels")
+		}
+	}
+	return changed, nil
+}
+
+// CheckLevel fails when the video encoded with settings needs a higher
+// level than the target decodes, such as 4K at 60 fps, which needs H.264
+// level 5.2, for devices that stop at 4.2
+func (t CompatTarget) CheckLevel(settings map[string]string, videoFile *ffmpeg.VideoFile) error {
+	codec := settings["codec"]
+	maxLevel := t.Levels[codecFamily(codec)]
+	if maxLevel == "" {
+		return nil
+	}
+	width, height, fps, bitrate := levelInputs(settings, videoFile)
+	if analyzer.LevelHolds(codec, settings["profile"], maxLevel, width, height, fps, bitrate) {
+		return nil
+	}
+	level, _ := analyzer.CodecLevel(codec, settings["profile"], width, height, fps, bitrate)
+	return i18n.Errorf("--compat %s devices decode %s up to level %s, but %dx%d at %.3g fps needs level %s", t.Name, codecFamily(codec), maxLevel, width, height, fps, level)
+}
+
+// compatSize returns the --scale value that fits the output frame in
+// maxSize, long side by short side, or "" when it already fits. A crop
+// in settings is taken into account.
+func compatSize(settings map[string]string, videoFile *ffmpeg.VideoFile, maxSize [2]int) string {
+	width, height := videoFile.VideoInfo.Width, videoFile.VideoInfo.Height
+	var cropWidth, cropHeight int
+	if _, err := fmt.Sscanf(ffmpeg.VideoFilter(settings, ffmpeg.FilterCrop), "crop=%d:%d", &cropWidth, &cropHeight); err == nil {
+		width, height = cropWidth, cropHeight
+	}
+	if width <= 0 || height <= 0 || maxSize[0] <= 0 {
+		return ""
+	}
+
+	long, short := maxSize[0], maxSize[1]
+	boxWidth, boxHeight := long, short
+	if height > width {
+		boxWidth, boxHeight = short, long
+	}
+	if width <= boxWidth && height <= boxHeight {
+		return ""
+	}
+
+	// Keep the aspect ratio with even sides, as 4:2:0 video needs
+	ratio := math.Min(float64(boxWidth)/float64(width), float64(boxHeight)/float64(height))
+	even := func(n float64) int { return int(math.Floor(n/2)) * 2 }
+	return fmt.Sprintf("%dx%d", even(float64(width)*ratio), even(float64(height)*ratio))
+}
+
+// compatFPS returns the frame rate that brings sourceFPS within maxFPS, or
+// 0 when it already is. Every other frame is dropped when that is enough,
+// so 59.94 fps becomes 29.97 rather than 30.
+func compatFPS(sourceFPS, maxFPS float64) float64 {
+	if maxFPS <= 0 || sourceFPS <= maxFPS+0.01 {
+		return 0
+	}
+	if half := sourceFPS / 2; half <= maxFPS+0.01 {
+		return math.Round(half*1000) / 1000
+	}
+	return maxFPS
+}
@@ -0,0 +1,117 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookupCompat(t *testing.T) {
+	assert.Equal(t, []string{"ios", "telegram", "tv2015", "web"}, CompatTargetNames())
+
+	target, err := LookupCompat("iOS")
+	assert.NoError(t, err)
+	assert.Equal(t, "ios", target.Name)
+	assert.True(t, target.Plays("hevc"))
+	assert.False(t, target.Plays("vp9"))
+	assert.True(t, target.OpensContainer("clip.MOV"))
+	assert.False(t, target.OpensContainer("clip.mkv"))
+
+	_, err = LookupCompat("playstation")
+	assert.Error(t, err)
+}
+
+func TestApplyCompat(t *testing.T) {
+	web, _ := LookupCompat("web")
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 3840, Height: 2160, FPS: 50}}
+	audio := []ffmpeg.AudioStreamInfo{{Codec: "ac3", Channels: 6}}
+	settings := map[string]string{"codec": "libx264", "profile": "main", "level": "3.1", "pix_fmt": "yuv420p", "audio_codec": "copy"}
+
+	changed, err := ApplyCompat(settings, web, video, audio)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"profile", "scale", "audio_codec", "audio_channels"}, changed)
+	assert.Equal(t, "high", settings["profile"])
+	assert.Equal(t, "scale=1920:1080", ffmpeg.VideoFilter(settings, ffmpeg.FilterScale))
+	assert.Equal(t, "", ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS))
+	assert.Equal(t, "aac", settings["audio_codec"])
+	assert.Equal(t, "2", settings["audio_channels"])
+
+	// The level follows the scaled frame, within the ceiling of the devices
+	_, err = ApplyLevel(settings, video)
+	assert.NoError(t, err)
+	assert.Equal(t, "4.2", settings["level"])
+	assert.NoError(t, web.CheckLevel(settings, video))
+
+	// Codecs the devices do not play are refused
+	_, err = ApplyCompat(map[string]string{"codec": "libvpx-vp9"}, web, video, nil)
+	assert.Error(t, err)
+	_, err = ApplyCompat(map[string]string{"codec": "copy"}, web, video, nil)
+	assert.Error(t, err)
+}
+
+func TestApplyCompatIOS(t *testing.T) {
+	ios, _ := LookupCompat("ios")
+	portrait := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 2160, Height: 3840, FPS: 30}}
+	settings := map[string]string{"codec": "hevc_nvenc", "cq": "28", "audio_codec": "copy"}
+
+	changed, err := ApplyCompat(settings, ios, portrait, []ffmpeg.AudioStreamInfo{{Codec: "aac", Channels: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"profile", "video_tag", "pix_fmt"}, changed)
+	assert.Equal(t, "hvc1", settings["video_tag"])
+	assert.Equal(t, "copy", settings["audio_codec"])
+
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mov", "out.mov", settings)
+	assert.Contains(t, args, "hvc1")
+}
+
+func TestApplyCompatKeepsChosenSize(t *testing.T) {
+	tv, _ := LookupCompat("tv2015")
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 2560, Height: 1440, FPS: 59.94}}
+	settings := map[string]string{"codec": "libx264", "gop": "120"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:720"))
+
+	_, err := ApplyCompat(settings, tv, video, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "scale=-2:720", ffmpeg.VideoFilter(settings, ffmpeg.FilterScale))
+	assert.Equal(t, "fps=29.97", ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS))
+	assert.Equal(t, "60", settings["gop"])
+}
+
+func TestCompatCheckLevel(t *testing.T) {
+	web, _ := LookupCompat("web")
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 3840, Height: 2160, FPS: 60}}
+	settings := map[string]string{"codec": "libx264", "profile": "high", "crf": "23"}
+
+	// 4K at 60 fps needs level 5.2, beyond the 4.2 of browsers
+	err := web.CheckLevel(settings, video)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "5.2")
+	}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:1080"))
+	assert.NoError(t, web.CheckLevel(settings, video))
+}
+
+func TestCompatSize(t *testing.T) {
+	box := [2]int{1920, 1080}
+	video := func(width, height int) *ffmpeg.VideoFile {
+		return &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: width, Height: height}}
+	}
+	assert.Equal(t, "", compatSize(map[string]string{}, video(1920, 1080), box))
+	assert.Equal(t, "", compatSize(map[string]string{}, video(1080, 1920), box))
+	assert.Equal(t, "1920x800", compatSize(map[string]string{}, video(3840, 1600), box))
+	assert.Equal(t, "1080x1920", compatSize(map[string]string{}, video(2160, 3840), box))
+
+	// The crop is what gets scaled
+	settings := map[string]string{}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterCrop, "crop=1920:1080:960:540"))
+	assert.Equal(t, "", compatSize(settings, video(3840, 2160), box))
+}
+
+func TestCompatFPS(t *testing.T) {
+	assert.Equal(t, 0.0, compatFPS(30, 30))
+	assert.Equal(t, 0.0, compatFPS(29.97, 30))
+	assert.Equal(t, 29.97, compatFPS(59.94, 30))
+	assert.Equal(t, 30.0, compatFPS(120, 30))
+}
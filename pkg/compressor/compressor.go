@@ -1,9 +1,9 @@
 package compressor
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -13,6 +13,7 @@ import (
 
 	"github.com/cccarv82/compressvideo/pkg/analyzer"
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 	"github.com/cccarv82/compressvideo/pkg/util"
 )
 
@@ -26,9 +27,15 @@ type CompressionResult struct {
 	SavedSpaceBytes     int64
 	SavedSpacePercent   float64
 	ProcessingTime      time.Duration
+	CPUTime             time.Duration // CPU time of the FFmpeg processes of the file, when measured
+	EnergyWh            float64       // Approximate energy the CPU time took, in watt-hours
 	AverageFrameQuality float64
+	VMAFScore           float64 // Predicted VMAF when a target VMAF was requested
+	HardwareFallback    *HardwareFallback // Set when a failed hardware encode was redone in software
+	Recovery            *Recovery         // How much of the input was salvaged, set with IgnoreErrors
 	FFmpegCommand       string
 	Settings            map[string]string
+	Overridden          []string // Settings given on the command line instead of chosen by the analyzer
 	Error               error
 }
 
@@ -38,7 +45,17 @@ type VideoCompressor struct {
 	Logger           *util.Logger
 	Analyzer         *analyzer.ContentAnalyzer
 	ConcurrentWorkers int
-	TempDir          string
+	TempDir          string  // Directory for temporary files; empty gives each compression its own directory under util.TempRoot
+	TargetVMAF       float64 // When > 0, pick the CRF by probing VMAF instead of using the analyzer's value
+	HardwareEncoder  string  // Hardware encoder family (nvenc, qsv, amf or auto), empty for software encoding
+	Threads          int     // Maximum encoder threads across all concurrent FFmpeg processes (0 = no limit)
+	IgnoreErrors     bool    // Decode past damaged data, accepting an output shorter than the source
+	Overrides        Overrides // Settings from the command line that replace the analyzer's
+	Parallel         string  // Parallel mode (auto, on or off), empty for auto
+	Segments         int     // Number of parallel segments, 0 to pick it from the machine
+	Memory           uint64  // Bytes of RAM of the machine, 0 when unknown
+	HDR10Plus        bool    // Copy the HDR10+ metadata of HDR10+ sources, extracted with hdr10plus_tool
+	Throttle         *Throttle // Lowers the segments encoded at once while the machine runs hot, nil for no limit
 }
 
 // NewVideoCompressor creates a new video compressor
@@ -46,19 +63,26 @@ func NewVideoCompressor(ffmpeg *ffmpeg.FFmpeg, analyzer *analyzer.ContentAnalyze
 	// Set the number of concurrent workers to CPU cores
 	concurrentWorkers := runtime.NumCPU()
 	
-	// Create temp directory for processing
-	tempDir := filepath.Join(os.TempDir(), "compressvideo")
-	os.MkdirAll(tempDir, 0755)
+	// Unknown RAM leaves the segment count to the CPU cores
+	memory, _ := util.TotalMemory()
 	
 	return &VideoCompressor{
 		FFmpeg:           ffmpeg,
 		Logger:           logger,
 		Analyzer:         analyzer,
 		ConcurrentWorkers: concurrentWorkers,
-		TempDir:          tempDir,
+		Memory:           memory,
 	}
 }
 
+// runner returns the Runner that runs FFmpeg for this compressor
+func (vc *VideoCompressor) runner() ffmpeg.Runner {
+	if vc.FFmpeg != nil && vc.FFmpeg.Runner != nil {
+		return vc.FFmpeg.Runner
+	}
+	return ffmpeg.DefaultRunner
+}
+
 // CompressVideo compresses a video with the given settings
 func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis *analyzer.VideoAnalysis, 
 	settings map[string]string, quality int, preset string, progress *util.ProgressTracker) (*CompressionResult, error) {
@@ -66,11 +90,18 @@ func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis
 	startTime := time.Now()
 	
 	// Get original file size
-	inputInfo, err := os.Stat(inputFile)
+	originalSize, err := inputSize(inputFile, analysis)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get input file info: %w", err)
+		return nil, err
 	}
-	originalSize := inputInfo.Size()
+	
+	// Keep this job's temporary files in one directory, so a crash leaves a
+	// single directory behind for 'compressvideo cleanup'
+	removeTempDir, err := vc.useJobTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer removeTempDir()
 	
 	// Calculate optimal compression settings if not provided
 	if settings == nil {
@@ -80,8 +111,16 @@ func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis
 		}
 	}
 	
-	// Adjust settings based on preset
-	vc.adjustSettingsForPreset(settings, preset)
+	// Kept to redo the encode in software if the hardware encoder fails
+	original := copySettings(settings)
+	
+	// Adjust settings for the preset, thread limit and encoder
+	if err := vc.prepareSettings(settings, quality, preset); err != nil {
+		return nil, err
+	}
+	
+	// Keep the HDR of the source, and report the metadata it loses
+	vc.prepareHDR(inputFile, analysis.VideoFile, settings)
 	
 	// Prepare result
 	result := &CompressionResult{
@@ -89,17 +128,45 @@ func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis
 		OutputFile:   outputFile,
 		OriginalSize: originalSize,
 		Settings:     settings,
+		Overridden:   vc.Overrides.Names(),
 	}
 	
-	// Determine compression approach based on content type and video length
-	useParallelCompression := analysis.VideoFile.Duration > 60 && 
-		analysis.ContentType != analyzer.ContentTypeScreencast
+	// Replace the analyzer's CRF with one measured against the VMAF target
+	if vc.TargetVMAF > 0 && settings["codec"] == "copy" {
+		vc.Logger.Warning("Target VMAF is ignored when the video stream is copied")
+	} else if vc.TargetVMAF > 0 && settings["cq"] != "" {
+		vc.Logger.Warning("Target VMAF is not supported with %s, using constant quality %s", settings["codec"], settings["cq"])
+	} else if vc.TargetVMAF > 0 {
+		vc.Logger.Info("Probing CRF values for target VMAF %.1f...", vc.TargetVMAF)
+		crf, predicted, _, err := vc.FindCRFForTargetVMAF(inputFile, analysis.VideoFile.Duration, settings, vc.TargetVMAF)
+		if err != nil {
+			vc.Logger.Warning("Target VMAF search failed, keeping CRF %s: %v", settings["crf"], err)
+		} else {
+			vc.Logger.Info("Selected CRF %d (predicted VMAF %.2f)", crf, predicted)
+			settings["crf"] = strconv.Itoa(crf)
+			result.VMAFScore = predicted
+			vc.Analyzer.Record(analyzer.Decision{
+				Name:     "crf",
+				Value:    settings["crf"],
+				Reason:   fmt.Sprintf("the highest CRF predicted to reach VMAF %.1f (%.2f)", vc.TargetVMAF, predicted),
+				Override: "--target-vmaf",
+			})
+		}
+	}
 	
 	// Execute compression
-	if useParallelCompression {
-		err = vc.compressVideoParallel(inputFile, outputFile, settings, progress)
-	} else {
-		err = vc.compressVideoSingle(inputFile, outputFile, settings, progress)
+	err = vc.encode(inputFile, outputFile, analysis, settings, progress)
+	
+	// Driver problems and session limits only affect the GPU: redo the file
+	// with the software encoder instead of failing it
+	if err != nil && isHardwareEncoder(settings["codec"]) {
+		if software, fallback := vc.retryInSoftware(inputFile, outputFile, original, settings["codec"], err, quality, preset); fallback != nil {
+			settings = software
+			result.Settings = settings
+			result.HardwareFallback = fallback
+			progress.Restart()
+			err = vc.encode(inputFile, outputFile, analysis, settings, progress)
+		}
 	}
 	
 	if err != nil {
@@ -114,12 +181,28 @@ func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis
 		return result, result.Error
 	}
 	result.CompressedSize = outputInfo.Size()
-	
+
+	// A zero exit status does not guarantee a complete, readable output
+	output, err := vc.verifyCompressedFile(outputFile, analysis.VideoFile)
+	if err != nil {
+		result.Error = err
+		return result, err
+	}
+	if vc.IgnoreErrors {
+		result.Recovery = newRecovery(analysis.VideoFile, output)
+		if !result.Recovery.Complete() {
+			vc.Logger.Warning("Only part of the damaged input could be recovered: %s", result.Recovery)
+		}
+	}
+
 	// Calculate compression metrics
 	result.ProcessingTime = time.Since(startTime)
-	result.SavedSpaceBytes = originalSize - result.CompressedSize
-	result.CompressionRatio = float64(originalSize) / float64(result.CompressedSize)
-	result.SavedSpacePercent = float64(result.SavedSpaceBytes) / float64(originalSize) * 100
+	// Remote inputs of unknown size leave the savings unknown
+	if originalSize > 0 {
+		result.SavedSpaceBytes = originalSize - result.CompressedSize
+		result.CompressionRatio = float64(originalSize) / float64(result.CompressedSize)
+		result.SavedSpacePercent = float64(result.SavedSpaceBytes) / float64(originalSize) * 100
+	}
 	
 	// Calculate average frame quality (can be done through VMAF or SSIM if needed)
 	// For now, we'll use a placeholder that estimates based on settings
@@ -128,8 +211,121 @@ func (vc *VideoCompressor) CompressVideo(inputFile, outputFile string, analysis
 	return result, nil
 }
 
+// useJobTempDir gives the job its own temporary directory when TempDir is
+// not set, and returns the function that removes it
+func (vc *VideoCompressor) useJobTempDir() (func(), error) {
+	if vc.TempDir != "" {
+		return func() {}, nil
+	}
+	jobDir, err := util.NewJobTempDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	vc.TempDir = jobDir
+	return func() {
+		os.RemoveAll(jobDir)
+		vc.TempDir = ""
+	}, nil
+}
+
+// encode runs the compression, in parallel segments when parallelPlan
+// finds the encoder and machine gain from it
+func (vc *VideoCompressor) encode(inputFile, outputFile string, analysis *analyzer.VideoAnalysis,
+	settings map[string]string, progress *util.ProgressTracker) error {
+	segments, reason := vc.parallelPlan(analysis, settings)
+	if segments > 0 {
+		return vc.compressVideoParallel(inputFile, outputFile, settings, segments, progress)
+	}
+	vc.Logger.Debug("Encoding in one pass: %s", reason)
+	return vc.compressVideoSingle(inputFile, outputFile, settings, progress)
+}
+
+// prepareSettings completes the analyzer's settings for the preset, the
+// thread limit and the encoder that will run them
+func (vc *VideoCompressor) prepareSettings(settings map[string]string, quality int, preset string) error {
+	return vc.prepareEncoderSettings(settings, quality, preset, vc.HardwareEncoder)
+}
+
+// prepareEncoderSettings is prepareSettings with the hardware encoder family
+// to use, empty for software encoding
+func (vc *VideoCompressor) prepareEncoderSettings(settings map[string]string, quality int, preset, hardware string) error {
+	// Adjust settings based on preset
+	vc.adjustSettingsForPreset(settings, preset)
+	
+	// Settings chosen on the command line win over the preset's
+	if err := ApplyOverrides(settings, vc.Overrides); err != nil {
+		return err
+	}
+	
+	// Cap the encoder threads when a limit is set
+	if vc.Threads > 0 {
+		settings["threads"] = strconv.Itoa(vc.Threads)
+	}
+	
+	// Switch to the hardware encoder the user asked for
+	vc.useHardwareEncoder(settings, hardware)
+	
+	// Fall back to an available encoder before starting, rather than failing midway
+	if err := vc.ensureEncoderAvailable(settings); err != nil {
+		return err
+	}
+	
+	// Hardware encoders get quality-driven settings of their own
+	applyHardwareEncoderSettings(settings, quality, preset)
+	return nil
+}
+
+// parallelSegments returns how many segments are encoded concurrently in parallel mode
+func (vc *VideoCompressor) parallelSegments() int {
+	// 1 per CPU core, capped at 8 segments to avoid overhead, unless the
+	// count was chosen with --segments
+	numSegments := vc.Segments
+	if numSegments <= 0 {
+		numSegments = vc.ConcurrentWorkers
+		if numSegments > 8 {
+			numSegments = 8
+		}
+	}
+	// Never run more segments at once than the thread limit allows
+	if vc.Threads > 0 && numSegments > vc.Threads {
+		numSegments = vc.Threads
+	}
+	return numSegments
+}
+
+// segmentThreads returns the encoder threads of each of numSegments
+// concurrent segments under a total limit of threads
+func segmentThreads(threads, numSegments int) int {
+	if numSegments <= 0 || threads/numSegments < 1 {
+		return 1
+	}
+	return threads / numSegments
+}
+
+// inputSize returns the size of the input file. Remote inputs use the size
+// ffprobe reported, which is 0 when the server does not send one.
+func inputSize(inputFile string, analysis *analyzer.VideoAnalysis) (int64, error) {
+	if ffmpeg.IsRemote(inputFile) {
+		if analysis != nil && analysis.VideoFile != nil {
+			return analysis.VideoFile.Size, nil
+		}
+		return 0, nil
+	}
+
+	inputInfo, err := os.Stat(inputFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get input file info: %w", err)
+	}
+	return inputInfo.Size(), nil
+}
+
 // adjustSettingsForPreset adjusts the compression settings based on the chosen preset
 func (vc *VideoCompressor) adjustSettingsForPreset(settings map[string]string, preset string) {
+	// A copied video stream has no encoder to tune
+	if settings["codec"] == "copy" {
+		return
+	}
+
 	// Get current preset speed from settings
 	currentPreset := settings["preset"]
 	
@@ -174,11 +370,11 @@ func (vc *VideoCompressor) adjustSettingsForPreset(settings map[string]string, p
 // compressVideoSingle compresses a single video file
 func (vc *VideoCompressor) compressVideoSingle(inputFile, outputFile string, settings map[string]string, progress *util.ProgressTracker) error {
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
 	// Build FFmpeg command arguments
 	args := vc.BuildFFmpegArgs(inputFile, outputFile, settings)
@@ -187,99 +383,41 @@ func (vc *VideoCompressor) compressVideoSingle(inputFile, outputFile string, set
 	cmdStr := fmt.Sprintf("%s %s", ffmpegPath, strings.Join(args, " "))
 	vc.Logger.Debug("Running FFmpeg command: %s", cmdStr)
 	
-	// Create command
-	cmd := exec.Command(ffmpegPath, args...)
-	
 	// Get video duration for progress calculation
 	videoFile, err := vc.FFmpeg.GetVideoInfo(inputFile)
 	if err != nil {
 		return fmt.Errorf("error getting video duration: %w", err)
 	}
-	totalDuration := videoFile.Duration
-	
+	return vc.runWithProgress(ffmpegPath, args, videoFile.Duration, progress)
+}
+
+// runWithProgress runs FFmpeg with args, updating progress from the status
+// lines it prints while encoding totalDuration seconds of video
+func (vc *VideoCompressor) runWithProgress(ffmpegPath string, args []string, totalDuration float64, progress *util.ProgressTracker) error {
 	// Variável para capturar a saída completa de stderr para análise de erros
 	var stderrOutput strings.Builder
+	var lastProgressReported int64
 	
-	// Pipe stderr to capture progress info
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stderr pipe: %w", err)
-	}
-	
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start FFmpeg: %w", err)
-	}
-	
-	// Monitor progress
-	go func() {
-		// Read FFmpeg output and update progress
-		buf := make([]byte, 2048)
-		var lastProgressReported int64
+	// Read FFmpeg's stderr as it is written and update progress
+	stderr := ffmpeg.OutputFunc(func(output string) {
+		stderrOutput.WriteString(output) // Capturar a saída completa
 		
-		for {
-			n, err := stderr.Read(buf)
-			if n == 0 || err != nil {
-				break
-			}
-			
-			output := string(buf[:n])
-			stderrOutput.WriteString(output) // Capturar a saída completa
+		// Parse time, speed and fps from FFmpeg's status line
+		if status, ok := parseFFmpegProgress(output); ok && totalDuration > 0 {
+			percentComplete := status.percent(totalDuration)
 			
-			// Parse time information from FFmpeg output
-			timeMatch := strings.Index(output, "time=")
-			if timeMatch != -1 {
-				// Encontrar o fim da string de tempo (até o espaço)
-				endIdx := timeMatch + 5
-				for endIdx < len(output) && output[endIdx] != ' ' {
-					endIdx++
-				}
-				
-				if endIdx > timeMatch+5 {
-					timeStr := output[timeMatch+5:endIdx]
-					timeStr = strings.TrimSpace(timeStr)
-					
-					// Parse time in HH:MM:SS format
-					if len(timeStr) >= 8 { // Garantir que tem pelo menos "HH:MM:SS"
-						parts := strings.Split(timeStr, ":")
-						if len(parts) >= 3 {
-							hours, _ := strconv.Atoi(parts[0])
-							minutes, _ := strconv.Atoi(parts[1])
-							
-							// Remover sufixos que possam existir na parte dos segundos
-							secondsStr := parts[2]
-							if dotIndex := strings.Index(secondsStr, "."); dotIndex >= 0 {
-								secondsStr = secondsStr[:dotIndex+3] // Manter até 2 casas decimais
-							}
-							
-							seconds, _ := strconv.ParseFloat(secondsStr, 64)
-							currentTime := float64(hours*3600) + float64(minutes*60) + seconds
-							
-							// Update progress
-							if totalDuration > 0 {
-								percentComplete := int64((currentTime / totalDuration) * 100)
-								if percentComplete > 100 {
-									percentComplete = 100
-								}
-								
-								// Update progress only if it's different from last reported
-								if percentComplete != lastProgressReported {
-									progress.Update(percentComplete)
-									lastProgressReported = percentComplete
-								}
-							}
-						}
-					}
-				}
+			// Update progress only if it's different from last reported
+			if percentComplete != lastProgressReported || status.Speed > 0 {
+				progress.UpdateWithStats(percentComplete, status.stats(totalDuration))
+				lastProgressReported = percentComplete
 			}
 		}
-	}()
+	})
 	
-	// Wait for command to finish
-	err = cmd.Wait()
+	// Run the command and wait for it to finish
+	err := vc.runner().Run(context.Background(), ffmpegPath, args, nil, stderr)
 	if err != nil {
-		errorOutput := stderrOutput.String()
-		return fmt.Errorf("FFmpeg error: %w\nDetails: %s", err, errorOutput)
+		return fmt.Errorf("FFmpeg error: %w", ffmpeg.ClassifyError(err, stderrOutput.String()))
 	}
 	
 	// Set progress to 100%
@@ -289,8 +427,7 @@ func (vc *VideoCompressor) compressVideoSingle(inputFile, outputFile string, set
 }
 
 // compressVideoParallel compresses a video by splitting it into segments and processing in parallel
-func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, settings map[string]string, progress *util.ProgressTracker) error {
-	vc.Logger.Info("Using parallel compression for faster processing")
+func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, settings map[string]string, workers int, progress *util.ProgressTracker) error {
 	
 	// Get video duration to split into segments
 	videoFile, err := vc.FFmpeg.GetVideoInfo(inputFile)
@@ -299,46 +436,42 @@ func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, s
 	}
 	
 	// Create temporary directory for segments
-	segmentDir := filepath.Join(vc.TempDir, fmt.Sprintf("segments_%d", time.Now().UnixNano()))
+	segmentDir := filepath.Join(vc.TempDir, "segments")
 	if err := os.MkdirAll(segmentDir, 0755); err != nil {
 		return fmt.Errorf("failed to create segment directory: %w", err)
 	}
 	defer os.RemoveAll(segmentDir) // Clean up when done
 	
-	// Calculate how many segments to create (1 per CPU core)
-	numSegments := vc.ConcurrentWorkers
-	if numSegments > 8 {
-		numSegments = 8 // Cap at 8 segments to avoid overhead
-	}
-	
-	// Split the video into segments
+	// Large sources are cut into more segments than are encoded at once, so
+	// only a few of them are on disk at a time instead of a copy of the
+	// whole source
+	numSegments := segmentCount(videoFile.Size, workers)
 	segmentDuration := videoFile.Duration / float64(numSegments)
-	segments, err := vc.splitVideo(inputFile, segmentDir, segmentDuration, numSegments)
-	if err != nil {
-		return fmt.Errorf("failed to split video: %w", err)
-	}
-	
-	// Create list file for concat
-	listPath := filepath.Join(segmentDir, "segments.txt")
-	listFile, err := os.Create(listPath)
-	if err != nil {
-		return fmt.Errorf("failed to create segment list: %w", err)
-	}
+	vc.Logger.Info("Using parallel compression for faster processing (%d segments, %d at a time)", numSegments, workers)
 	
 	// Compress segments in parallel
 	var wg sync.WaitGroup
-	compressedSegments := make([]string, len(segments))
-	errorChan := make(chan error, len(segments))
-	progressChan := make(chan int, 100) // For progress updates
+	compressedSegments := make([]string, numSegments)
+	errorChan := make(chan error, numSegments+1)
+	progressChan := make(chan segmentUpdate, 100) // For progress updates
+	aggregatorDone := make(chan struct{})
+	
+	// Show one bar per segment under the overall progress
+	labels := make([]string, numSegments)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("Segment %d/%d", i+1, numSegments)
+	}
+	segmentBars := progress.AddSubBars(labels...)
 	
 	// Start a goroutine to aggregate progress updates
 	go func() {
-		segmentProgress := make([]int, len(segments))
-		for progressUpdate := range progressChan {
-			segmentID := progressUpdate >> 16    // Primeiros 16 bits contêm o ID do segmento
-			progressValue := progressUpdate & 0xFFFF  // Últimos 16 bits contêm o valor do progresso
-			
-			segmentProgress[segmentID] = progressValue
+		defer close(aggregatorDone)
+		segmentProgress := make([]int, numSegments)
+		segmentStats := make([]util.EncodeStats, numSegments)
+		for update := range progressChan {
+			segmentProgress[update.segmentID] = update.progress
+			segmentStats[update.segmentID] = update.stats
+			progress.UpdateSubBar(segmentBars[update.segmentID], int64(update.progress), update.stats)
 			
 			// Calcular o progresso médio de todos os segmentos
 			totalProgress := 0
@@ -346,59 +479,143 @@ func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, s
 				totalProgress += p
 			}
 			
+			// Segments run concurrently: throughput adds up, the slowest one
+			// sets the ETA and bitrate and quantizer are averaged
+			var aggregate util.EncodeStats
+			reporting := 0
+			for i, stats := range segmentStats {
+				if segmentProgress[i] >= 100 {
+					continue
+				}
+				aggregate.FPS += stats.FPS
+				aggregate.Speed += stats.Speed
+				if stats.Remaining > aggregate.Remaining {
+					aggregate.Remaining = stats.Remaining
+				}
+				if stats.Bitrate > 0 {
+					aggregate.Bitrate += stats.Bitrate
+					aggregate.Q += stats.Q
+					reporting++
+				}
+			}
+			if reporting > 0 {
+				aggregate.Bitrate /= float64(reporting)
+				aggregate.Q /= float64(reporting)
+			}
+			
 			// 90% para compressão, 10% reservado para a fusão final
-			avgProgress := int64(float64(totalProgress) / float64(len(segments) * 100) * 90)
+			avgProgress := int64(float64(totalProgress) / float64(numSegments * 100) * 90)
 			if avgProgress < 90 {
-				progress.Update(avgProgress)
+				progress.UpdateWithStats(avgProgress, aggregate)
 			}
 		}
 	}()
 	
-	// Start workers for each segment
-	for i, segment := range segments {
-		wg.Add(1)
-		go func(i int, segment string) {
-			defer wg.Done()
-			
-			// Create output path for compressed segment
-			outSegment := filepath.Join(segmentDir, fmt.Sprintf("out_%04d.mp4", i))
-			compressedSegments[i] = outSegment
-			
-			// Clone settings map to avoid race conditions
-			segmentSettings := make(map[string]string)
-			for k, v := range settings {
-				segmentSettings[k] = v
+	// Split the segments one after another while the workers encode them.
+	// A split segment holds a slot until it is encoded and removed, which
+	// caps the split data on disk: the splitter runs one segment ahead of
+	// each worker at most.
+	split := make(chan int)
+	slots := make(chan struct{}, storedSegments(workers))
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	fail := func(err error) {
+		errorChan <- err
+		failOnce.Do(func() { close(failed) })
+	}
+	segmentPath := func(i int) string {
+		return filepath.Join(segmentDir, fmt.Sprintf("segment_%04d.mp4", i))
+	}
+	
+	go func() {
+		defer close(split)
+		for i := 0; i < numSegments; i++ {
+			select {
+			case slots <- struct{}{}:
+			case <-failed:
+				return
 			}
-			
-			// Force key frames at segment boundaries
-			segmentSettings["force_key_frames"] = "expr:eq(n,0)"
-			
-			// Create segment progress tracker that reports to the channel
-			segmentProgress := &segmentProgressTracker{
-				segmentID: i,
-				progressChan: progressChan,
+			if err := vc.splitSegment(inputFile, segmentPath(i), float64(i)*segmentDuration, segmentDuration); err != nil {
+				fail(fmt.Errorf("failed to split segment %d: %w", i, err))
+				return
 			}
-			
-			// Compress this segment
-			err := vc.compressSegment(segment, outSegment, segmentSettings, segmentProgress)
-			if err != nil {
-				errorChan <- fmt.Errorf("segment %d error: %w", i, err)
+			select {
+			case split <- i:
+			case <-failed:
 				return
 			}
-			
-			// Write entry to list file (thread-safe by using a synchronized file)
-			path, _ := filepath.Rel(segmentDir, outSegment)
-			line := fmt.Sprintf("file '%s'\n", path)
-			if _, err := listFile.WriteString(line); err != nil {
-				errorChan <- fmt.Errorf("failed to write to list file: %w", err)
+		}
+	}()
+	
+	// Start the workers, which encode the segments as they are split
+	vc.Throttle.plan(workers)
+	defer vc.Throttle.plan(0)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range split {
+				segment := segmentPath(i)
+				
+				// Create output path for compressed segment
+				outSegment := filepath.Join(segmentDir, fmt.Sprintf("out_%04d.mp4", i))
+				compressedSegments[i] = outSegment
+				
+				// Clone settings map to avoid race conditions
+				segmentSettings := make(map[string]string)
+				for k, v := range settings {
+					segmentSettings[k] = v
+				}
+				
+				// Force key frames at segment boundaries
+				segmentSettings["force_key_frames"] = "expr:eq(n,0)"
+				
+				// Segments hold video only, the audio is taken whole from the
+				// source when they are merged
+				segmentSettings["no_audio"] = "1"
+				
+				// The merged file is laid out for playback, not its segments
+				withoutMuxer(segmentSettings)
+				
+				// Share the thread limit between the concurrent segments
+				if vc.Threads > 0 {
+					segmentSettings["threads"] = strconv.Itoa(segmentThreads(vc.Threads, workers))
+				}
+				
+				// Create segment progress tracker that reports to the channel
+				segmentProgress := &segmentProgressTracker{
+					segmentID: i,
+					progressChan: progressChan,
+				}
+				
+				// Compress this segment, unless another one already failed,
+				// once the throttle allows another encode at once
+				var err error
+				select {
+				case <-failed:
+				default:
+					if vc.Throttle.acquire(workers, failed) {
+						err = vc.compressSegment(segment, outSegment, segmentSettings, segmentProgress)
+						vc.Throttle.release()
+					}
+				}
+				
+				// The split segment is no longer needed, make room for the next
+				os.Remove(segment)
+				<-slots
+				
+				if err != nil {
+					fail(fmt.Errorf("segment %d error: %w", i, err))
+				}
 			}
-		}(i, segment)
+		}()
 	}
 	
 	// Wait for all segments to be compressed
 	wg.Wait()
 	close(progressChan)
-	listFile.Close()
+	<-aggregatorDone
+	progress.ClearSubBars()
 	
 	// Check for errors
 	select {
@@ -408,12 +625,19 @@ func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, s
 		// No errors
 	}
 	
+	// List the segments only once all are done, in playback order
+	listPath := filepath.Join(segmentDir, "segments.txt")
+	if err := writeSegmentList(listPath, compressedSegments); err != nil {
+		return err
+	}
+	
 	// Update progress
 	progress.Update(90)
 	
-	// Merge the segments
+	// Merge the segments. CompressVideo then checks the merged duration
+	// against the source, which catches segments dropped or cut short.
 	vc.Logger.Info("Merging compressed segments...")
-	err = vc.mergeSegments(listPath, outputFile, settings["codec"])
+	err = vc.mergeSegments(listPath, inputFile, outputFile, settings, videoFile.AudioInfo)
 	if err != nil {
 		return fmt.Errorf("failed to merge segments: %w", err)
 	}
@@ -424,57 +648,67 @@ func (vc *VideoCompressor) compressVideoParallel(inputFile, outputFile string, s
 	return nil
 }
 
-// splitVideo splits a video into multiple segments of equal duration
-func (vc *VideoCompressor) splitVideo(inputFile, segmentDir string, segmentDuration float64, numSegments int) ([]string, error) {
-	segments := make([]string, numSegments)
-	
+// writeSegmentList checks that every compressed segment was written and
+// lists them, in order, for the concat demuxer. Entries are relative to the
+// list file.
+func writeSegmentList(listPath string, segments []string) error {
+	names := make([]string, len(segments))
+	for i, segment := range segments {
+		info, err := os.Stat(segment)
+		if err != nil || info.Size() == 0 {
+			return fmt.Errorf("compressed segment %d is missing or empty: %s", i, segment)
+		}
+		names[i], err = filepath.Rel(filepath.Dir(listPath), segment)
+		if err != nil {
+			names[i] = segment
+		}
+	}
+	if err := ffmpeg.WriteConcatList(listPath, names); err != nil {
+		return fmt.Errorf("failed to write segment list: %w", err)
+	}
+	return nil
+}
+
+// splitSegment copies duration seconds of the video stream of inputFile,
+// from start, into outPath. Audio is left out: cut at the same points it
+// would gain gaps and clicks at every boundary, so mergeSegments takes it
+// from the source.
+func (vc *VideoCompressor) splitSegment(inputFile, outPath string, start, duration float64) error {
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
-	for i := 0; i < numSegments; i++ {
-		startTime := float64(i) * segmentDuration
-		outPath := filepath.Join(segmentDir, fmt.Sprintf("segment_%04d.mp4", i))
-		segments[i] = outPath
-		
-		args := []string{
-			"-ss", fmt.Sprintf("%.3f", startTime),
-			"-i", inputFile,
-			"-t", fmt.Sprintf("%.3f", segmentDuration),
-			"-c", "copy", // Use copy to make splitting fast
-			"-avoid_negative_ts", "1",
-			"-y", outPath,
-		}
-		
-		cmd := exec.Command(ffmpegPath, args...)
-		vc.Logger.Debug("Splitting segment %d: %s", i, strings.Join(cmd.Args, " "))
-		
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return nil, fmt.Errorf("failed to split segment %d: %w\nOutput: %s", i, err, string(output))
-		}
-	}
+	args := append(ffmpeg.RemoteInputArgs(inputFile),
+		"-ss", fmt.Sprintf("%.3f", start),
+		"-i", ffmpeg.FileArg(inputFile),
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-map", "0:v:0",
+		"-c", "copy", // Use copy to make splitting fast
+		"-avoid_negative_ts", "1",
+		"-y", outPath,
+	)
+	
+	vc.Logger.Debug("Splitting segment: %s %s", ffmpegPath, strings.Join(args, " "))
 	
-	return segments, nil
+	_, err = ffmpeg.CombinedOutput(runner, ffmpegPath, args)
+	return err
 }
 
 // compressSegment compresses a single video segment
 func (vc *VideoCompressor) compressSegment(inputFile, outputFile string, settings map[string]string, progress progressReporter) error {
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
 	// Build FFmpeg command
 	args := vc.BuildFFmpegArgs(inputFile, outputFile, settings)
 	
-	// Run FFmpeg
-	cmd := exec.Command(ffmpegPath, args...)
-	
 	// Get video duration for progress calculation
 	videoFile, err := vc.FFmpeg.GetVideoInfo(inputFile)
 	if err != nil {
@@ -482,140 +716,167 @@ func (vc *VideoCompressor) compressSegment(inputFile, outputFile string, setting
 	}
 	totalDuration := videoFile.Duration
 	
-	// Pipe stderr to capture progress info
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("failed to get stderr pipe: %w", err)
-	}
-	
-	// Start the command
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("failed to start FFmpeg: %w", err)
-	}
-	
-	// Monitor progress
-	go func() {
-		// Read FFmpeg output and update progress
-		buf := make([]byte, 2048)
-		var lastProgressReported int64
+	// Read FFmpeg's stderr as it is written and update progress
+	var stderrOutput strings.Builder
+	var lastProgressReported int64
+	stderr := ffmpeg.OutputFunc(func(output string) {
+		stderrOutput.WriteString(output) // Kept to explain a failure
 		
-		for {
-			n, err := stderr.Read(buf)
-			if n == 0 || err != nil {
-				break
-			}
-			
-			output := string(buf[:n])
+		// Parse time, speed and fps from FFmpeg's status line
+		if status, ok := parseFFmpegProgress(output); ok && totalDuration > 0 {
+			percentComplete := status.percent(totalDuration)
 			
-			// Parse time information from FFmpeg output
-			timeMatch := strings.Index(output, "time=")
-			if timeMatch != -1 {
-				// Encontrar o fim da string de tempo (até o espaço)
-				endIdx := timeMatch + 5
-				for endIdx < len(output) && output[endIdx] != ' ' {
-					endIdx++
-				}
-				
-				if endIdx > timeMatch+5 {
-					timeStr := output[timeMatch+5:endIdx]
-					timeStr = strings.TrimSpace(timeStr)
-					
-					// Parse time in HH:MM:SS format
-					if len(timeStr) >= 8 { // Garantir que tem pelo menos "HH:MM:SS"
-						parts := strings.Split(timeStr, ":")
-						if len(parts) >= 3 {
-							hours, _ := strconv.Atoi(parts[0])
-							minutes, _ := strconv.Atoi(parts[1])
-							
-							// Remover sufixos que possam existir na parte dos segundos
-							secondsStr := parts[2]
-							if dotIndex := strings.Index(secondsStr, "."); dotIndex >= 0 {
-								secondsStr = secondsStr[:dotIndex+3] // Manter até 2 casas decimais
-							}
-							
-							seconds, _ := strconv.ParseFloat(secondsStr, 64)
-							currentTime := float64(hours*3600) + float64(minutes*60) + seconds
-							
-							// Update progress
-							if totalDuration > 0 {
-								percentComplete := int64((currentTime / totalDuration) * 100)
-								if percentComplete > 100 {
-									percentComplete = 100
-								}
-								
-								// Só atualizar se houver mudança significativa ou for o final
-								if percentComplete > lastProgressReported || percentComplete >= 100 {
-									progress.reportProgress(int(percentComplete))
-									lastProgressReported = percentComplete
-								}
-							}
-						}
-					}
-				}
-			}
-			
-			// Log errors in verbose mode
-			if strings.Contains(strings.ToLower(output), "error") {
-				vc.Logger.Debug("FFmpeg output: %s", output)
+			// Só atualizar se houver mudança significativa ou for o final
+			if percentComplete > lastProgressReported || percentComplete >= 100 || status.Speed > 0 {
+				progress.reportProgress(int(percentComplete), status.stats(totalDuration))
+				lastProgressReported = percentComplete
 			}
 		}
-	}()
+		
+		// Log errors in verbose mode
+		if strings.Contains(strings.ToLower(output), "error") {
+			vc.Logger.Debug("FFmpeg output: %s", output)
+		}
+	})
 	
-	// Wait for the command to finish
-	if err := cmd.Wait(); err != nil {
-		return fmt.Errorf("FFmpeg error: %w", err)
+	// Run FFmpeg and wait for it to finish
+	if err := runner.Run(context.Background(), ffmpegPath, args, nil, stderr); err != nil {
+		return fmt.Errorf("FFmpeg error: %w", ffmpeg.ClassifyError(err, stderrOutput.String()))
 	}
 	
 	// Set progress to 100%
-	progress.reportProgress(100)
+	progress.reportProgress(100, util.EncodeStats{})
 	
 	return nil
 }
 
-// mergeSegments merges multiple video segments into one output file
-func (vc *VideoCompressor) mergeSegments(listFile, outputFile, codec string) error {
+// mergeSegments joins the compressed video segments into one output file
+// and muxes the audio of the source back in
+func (vc *VideoCompressor) mergeSegments(listFile, inputFile, outputFile string, settings map[string]string, audio []ffmpeg.AudioStreamInfo) error {
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+	
+	args := vc.mergeArgs(listFile, inputFile, outputFile, settings, audio)
+	vc.Logger.Debug("Merging segments: %s %s", ffmpegPath, strings.Join(args, " "))
+	
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return fmt.Errorf("failed to merge segments: %w", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
-	// Use FFmpeg's concat demuxer to merge segments
+	return nil
+}
+
+// mergeArgs returns the arguments that join the segments of listFile with
+// FFmpeg's concat demuxer, copying their video, and add the audio streams
+// kept by --keep-audio-langs, or the one FFmpeg would have picked, from
+// inputFile, encoded as settings ask. The
+// audio is read in one piece, so it has no seams at the segment boundaries.
+func (vc *VideoCompressor) mergeArgs(listFile, inputFile, outputFile string, settings map[string]string, audio []ffmpeg.AudioStreamInfo) []string {
 	args := []string{
 		"-f", "concat",
 		"-safe", "0",
 		"-i", listFile,
-		"-c", "copy", // Just copy the streams without re-encoding
-		"-y", outputFile,
 	}
 	
-	cmd := exec.Command(ffmpegPath, args...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to merge segments: %w\nOutput: %s", err, string(output))
+	withAudio := settings["no_audio"] != "1" && settings["audio_codec"] != ""
+	if withAudio {
+		args = append(args, ffmpeg.RemoteInputArgs(inputFile)...)
+		args = append(args, "-i", ffmpeg.FileArg(inputFile))
 	}
 	
-	return nil
+	args = append(args, "-map", "0:v")
+	if kept := keptAudioStreams(settings); withAudio && kept != nil {
+		args = append(args, audioMapArgs(1, kept)...)
+	} else if withAudio {
+		// The ? leaves the audio out should the source turn out to have none
+		args = append(args, "-map", fmt.Sprintf("1:a:%d?", defaultAudioStream(audio)))
+	}
+	args = append(args, "-c:v", "copy") // The video is already encoded
+	if withAudio {
+		args = append(args, audioArgs(settings)...)
+	} else {
+		args = append(args, "-an")
+	}
+	args = append(args, muxerArgs(settings)...)
+	
+	return append(args, "-y", ffmpeg.FileArg(outputFile))
+}
+
+// defaultAudioStream returns the position, among the audio streams, of the
+// one FFmpeg maps when no -map is given: the first with the most channels
+func defaultAudioStream(audio []ffmpeg.AudioStreamInfo) int {
+	best := 0
+	for i, stream := range audio {
+		if stream.Channels > audio[best].Channels {
+			best = i
+		}
+	}
+	return best
 }
 
 // BuildFFmpegArgs constrói os argumentos para o comando FFmpeg
 func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, settings map[string]string) []string {
+	args, keepOnGPU := vc.inputArgs(inputFile, settings)
+	args = append(args, vc.outputArgs(inputFile, settings, keepOnGPU)...)
+	
+	// Add output file
+	args = append(args, ffmpeg.FileArg(outputFile))
+	
+	return args
+}
+
+// inputArgs returns the arguments up to and including the input file, and
+// whether decoded frames stay in GPU memory for the encoder
+func (vc *VideoCompressor) inputArgs(inputFile string, settings map[string]string) ([]string, bool) {
 	// Base arguments
 	args := []string{"-y"}
 	
+	// Decode on the GPU when a hardware decoder is configured. Frames stay in
+	// GPU memory when the encoder runs on the same device.
+	codec := settings["codec"]
+	keepOnGPU := false
+	if vc.FFmpeg != nil && vc.FFmpeg.Options != nil && vc.FFmpeg.Options.HWAccel != "" && codec != "copy" {
+		hwaccel := vc.FFmpeg.Options.HWAccel
+		keepOnGPU = ffmpeg.HWAccelMatchesEncoder(hwaccel, codec) && ffmpeg.VideoFilterChain(settings) == ""
+		args = append(args, ffmpeg.HWAccelArgs(hwaccel, keepOnGPU)...)
+	}
+	
+	// Read past damaged data of partial recordings and downloads
+	if vc.IgnoreErrors {
+		args = append(args, ignoreErrorsArgs...)
+	}
+	
 	// Add input file
-	args = append(args, "-i", inputFile)
+	args = append(args, ffmpeg.RemoteInputArgs(inputFile)...)
+	args = append(args, "-i", ffmpeg.FileArg(inputFile))
 	
-	// Add codec settings
+	return args, keepOnGPU
+}
+
+// outputArgs returns the encoding arguments of one output file
+func (vc *VideoCompressor) outputArgs(inputFile string, settings map[string]string, keepOnGPU bool) []string {
+	var args []string
 	codec := settings["codec"]
+	
+	// Map the audio tracks --keep-audio-langs kept, FFmpeg picks otherwise
+	if kept := keptAudioStreams(settings); kept != nil {
+		args = append(args, "-map", "0:v:0")
+		args = append(args, audioMapArgs(0, kept)...)
+	}
+	
+	// Add codec settings
 	if codec != "" {
 		args = append(args, "-c:v", codec)
 	}
 	
 	// Add preset
+	// VP9 has no presets, its speed is set with -cpu-used below
 	preset := settings["preset"]
-	if preset != "" {
+	if preset != "" && codec != "libvpx-vp9" {
 		args = append(args, "-preset", preset)
 	}
 	
@@ -631,12 +892,17 @@ func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, setting
 		args = append(args, "-profile:v", profile)
 	}
 	
-	// Add level
+	// Add level; x265 takes it with its own parameters
 	level := settings["level"]
-	if level != "" {
+	if level != "" && codec != "libx265" {
 		args = append(args, "-level", level)
 	}
 	
+	// Tag the stream the way the players of --compat expect, e.g. hvc1
+	if tag := settings["video_tag"]; tag != "" {
+		args = append(args, "-tag:v", tag)
+	}
+	
 	// Add tuning parameter
 	tune := settings["tune"]
 	if tune != "" {
@@ -644,11 +910,18 @@ func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, setting
 	}
 	
 	// Add codec-specific parameters
-	if codec == "libx265" && settings["x265-params"] != "" {
-		args = append(args, "-x265-params", settings["x265-params"])
-	} else if strings.Contains(codec, "nvenc") {
-		// Adicionar parâmetros específicos para NVENC para melhorar a compatibilidade
-		if codec == "h264_nvenc" || codec == "hevc_nvenc" {
+	if codec == "libx265" {
+		if params := x265Params(settings); params != "" {
+			args = append(args, "-x265-params", params)
+		}
+	} else if codec == "h264_nvenc" || codec == "hevc_nvenc" {
+		if cq := settings["cq"]; cq != "" {
+			// Constant quality: -b:v 0 lifts the bitrate cap so -cq alone drives quality
+			args = append(args, "-rc", "vbr", "-cq", cq, "-b:v", "0")
+			if multipass := settings["multipass"]; multipass != "" {
+				args = append(args, "-multipass", multipass)
+			}
+		} else {
 			args = append(args, "-rc", "vbr")
 			
 			// Garantir que temos um valor de bitrate para usar
@@ -667,28 +940,42 @@ func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, setting
 					}
 				}
 				args = append(args, "-b:v", defaultBitrate)
-				vc.Logger.Debug("Usando bitrate padrão para NVENC: %s", defaultBitrate)
-			}
-			
-			// Usar diferentes parâmetros dependendo da plataforma
-			if runtime.GOOS == "windows" {
-				// Parâmetros mais simples para Windows para evitar bugs
-				vc.Logger.Debug("Usando configuração NVENC simplificada para Windows")
-			} else {
-				// Configuração completa para outras plataformas
-				args = append(args, "-rc-lookahead", "20")
-				args = append(args, "-spatial-aq", "1")
-				args = append(args, "-temporal-aq", "1")
+				vc.Logger.Debug("Using default NVENC bitrate: %s", defaultBitrate)
 			}
 		}
+		
+		// Usar diferentes parâmetros dependendo da plataforma
+		if runtime.GOOS == "windows" {
+			// Parâmetros mais simples para Windows para evitar bugs
+			vc.Logger.Debug("Using simplified NVENC settings for Windows")
+		} else {
+			// Configuração completa para outras plataformas
+			args = append(args, "-rc-lookahead", "20")
+			args = append(args, "-spatial-aq", "1")
+			args = append(args, "-temporal-aq", "1")
+		}
+	} else if codec == "libvpx-vp9" {
+		args = append(args, vp9Args(settings)...)
+	} else if isQSV(codec) {
+		args = append(args, qsvArgs(codec, settings)...)
+	} else if isAMF(codec) {
+		args = append(args, amfArgs(codec, settings)...)
 	}
 	
-	// Add pixel format if specified
+	// GPU frames cannot be converted by -pix_fmt, the encoder picks the format
 	pixFmt := settings["pix_fmt"]
-	if pixFmt != "" {
+	if pixFmt != "" && !keepOnGPU {
 		args = append(args, "-pix_fmt", pixFmt)
 	}
 	
+	// Add the video filters of every stage, in order
+	if filter := ffmpeg.VideoFilterChain(settings); filter != "" {
+		args = append(args, "-vf", filter)
+	}
+	
+	// Add keyframe interval if specified
+	args = append(args, keyframeArgs(codec, settings["gop"])...)
+	
 	// Add force key frames if specified
 	forceKeyFrames := settings["force_key_frames"]
 	if forceKeyFrames != "" {
@@ -696,20 +983,7 @@ func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, setting
 	}
 	
 	// Add audio codec settings
-	audioCodec := settings["audio_codec"]
-	if audioCodec != "" {
-		if audioCodec == "copy" {
-			args = append(args, "-c:a", "copy")
-		} else {
-			args = append(args, "-c:a", audioCodec)
-			
-			// Add audio bitrate if specified
-			audioBitrate := settings["audio_bitrate"]
-			if audioBitrate != "" {
-				args = append(args, "-b:a", audioBitrate)
-			}
-		}
-	}
+	args = append(args, audioArgs(settings)...)
 	
 	// Add thread count
 	threads := settings["threads"]
@@ -723,9 +997,42 @@ func (vc *VideoCompressor) BuildFFmpegArgs(inputFile, outputFile string, setting
 		args = append(args, "-b:v", bitrate)
 	}
 	
-	// Add output file
-	args = append(args, outputFile)
+	// Lay the file out for playback, e.g. with its index at the front
+	args = append(args, muxerArgs(settings)...)
 	
+	// Options from --extra-ffmpeg-args come last, so they win over the tool's
+	args = append(args, vc.Overrides.ExtraArgs...)
+	
+	return args
+}
+
+// audioArgs returns the arguments that encode, copy or drop the audio
+func audioArgs(settings map[string]string) []string {
+	var args []string
+	audioCodec := settings["audio_codec"]
+	if settings["no_audio"] == "1" {
+		args = append(args, "-an")
+	} else if audioCodec != "" {
+		if audioCodec == "copy" {
+			args = append(args, "-c:a", "copy")
+		} else {
+			args = append(args, "-c:a", audioCodec)
+			
+			// Add audio bitrate if specified
+			audioBitrate := settings["audio_bitrate"]
+			if audioBitrate != "" {
+				args = append(args, "-b:a", audioBitrate)
+			}
+			
+			// Add downmix settings if specified
+			if filter := settings["audio_filter"]; filter != "" {
+				args = append(args, "-af", filter)
+			}
+			if channels := settings["audio_channels"]; channels != "" {
+				args = append(args, "-ac", channels)
+			}
+		}
+	}
 	return args
 }
 
@@ -771,55 +1078,61 @@ func (vc *VideoCompressor) EstimateFrameQuality(settings map[string]string) floa
 
 // Simple progress reporter interface for segment compression
 type progressReporter interface {
-	reportProgress(progress int)
+	reportProgress(progress int, stats util.EncodeStats)
+}
+
+// segmentUpdate is a progress report from one segment worker
+type segmentUpdate struct {
+	segmentID int
+	progress  int
+	stats     util.EncodeStats
 }
 
 // Implementation of progress reporter for segments
 type segmentProgressTracker struct {
 	segmentID    int
-	progressChan chan<- int
+	progressChan chan<- segmentUpdate
 }
 
-func (spt *segmentProgressTracker) reportProgress(progress int) {
-	// Combinar segmentID e progress em um único inteiro
-	// Os primeiros 16 bits representam o segmentID, os últimos 16 bits representam o progresso
-	combinedProgress := (spt.segmentID << 16) | progress
-	spt.progressChan <- combinedProgress
+func (spt *segmentProgressTracker) reportProgress(progress int, stats util.EncodeStats) {
+	spt.progressChan <- segmentUpdate{
+		segmentID: spt.segmentID,
+		progress:  progress,
+		stats:     stats,
+	}
 }
 
 func (vc *VideoCompressor) compressVideoWithTwoPass(inputFile, outputFile string, settings map[string]string, progress *util.ProgressTracker) error {
 	vc.Logger.Debug("Starting two-pass compression")
 	
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
 	// Build FFmpeg arguments for both passes
-	firstPassArgs := append(vc.BuildFFmpegArgs(inputFile, "NUL", settings), "-pass", "1", "-f", "null", "-")
+	// The pass logs go to the temporary directory, not the working directory
+	passLog := filepath.Join(vc.TempDir, "ffmpeg2pass")
+	firstPassArgs := append(vc.BuildFFmpegArgs(inputFile, "NUL", settings), "-pass", "1", "-passlogfile", passLog, "-f", "null", "-")
 	
 	// Modify for macOS/Linux
 	if runtime.GOOS != "windows" {
-		firstPassArgs = append(vc.BuildFFmpegArgs(inputFile, "/dev/null", settings), "-pass", "1", "-f", "null", "/dev/null")
+		firstPassArgs = append(vc.BuildFFmpegArgs(inputFile, "/dev/null", settings), "-pass", "1", "-passlogfile", passLog, "-f", "null", "/dev/null")
 	}
 	
 	// Run first pass
 	vc.Logger.Debug("Running first pass compression")
-	cmd := exec.Command(ffmpegPath, firstPassArgs...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("FFmpeg first pass error: %w\nOutput: %s", err, string(output))
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, firstPassArgs); err != nil {
+		return fmt.Errorf("FFmpeg first pass error: %w", err)
 	}
 	
 	// Run second pass
-	secondPassArgs := append(vc.BuildFFmpegArgs(inputFile, outputFile, settings), "-pass", "2")
+	secondPassArgs := append(vc.BuildFFmpegArgs(inputFile, outputFile, settings), "-pass", "2", "-passlogfile", passLog)
 	vc.Logger.Debug("Running second pass compression")
-	cmd = exec.Command(ffmpegPath, secondPassArgs...)
-	output, err = cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("FFmpeg second pass error: %w\nOutput: %s", err, string(output))
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, secondPassArgs); err != nil {
+		return fmt.Errorf("FFmpeg second pass error: %w", err)
 	}
 	
 	return nil
@@ -829,11 +1142,11 @@ func (vc *VideoCompressor) compressVideoSegment(inputFile, outputFile string, st
 	vc.Logger.Debug("Compressing segment: %s from %.2fs for %.2fs", inputFile, startTime, duration)
 	
 	// Obter o caminho para o FFmpeg
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	ffmpegPath := ffmpegInfo.Path
 	
 	// Add segment-specific arguments
 	segmentArgs := []string{
@@ -846,11 +1159,8 @@ func (vc *VideoCompressor) compressVideoSegment(inputFile, outputFile string, st
 	
 	// Execute command
 	vc.Logger.Debug("Running FFmpeg segment command: %s %s", ffmpegPath, strings.Join(args, " "))
-	cmd := exec.Command(ffmpegPath, args...)
-	
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("failed to compress segment: %w\nOutput: %s", err, string(output))
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return fmt.Errorf("failed to compress segment: %w", err)
 	}
 	
 	return nil
@@ -1,10 +1,18 @@
 package compressor
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/cccarv82/compressvideo/pkg/analyzer"
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -53,10 +61,70 @@ func (m *MockProgressTracker) Finish() {
 	m.Called()
 }
 
+// fakeRunner stands in for FFmpeg: ffprobe reports a ten-second 720p video
+// and ffmpeg writes the given stderr and fails with err
+type fakeRunner struct {
+	calls  [][]string
+	stderr string
+	err    error
+}
+
+func (r *fakeRunner) EncodePath() (string, error) { return "ffmpeg", nil }
+func (r *fakeRunner) ProbePath() (string, error)  { return "ffprobe", nil }
+
+func (r *fakeRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	r.calls = append(r.calls, append([]string{path}, args...))
+	if path == "ffprobe" {
+		_, err := io.WriteString(stdout, `{"format":{"duration":"10.0"},"streams":[{"codec_type":"video","codec_name":"h264","width":1280,"height":720}]}`)
+		return err
+	}
+	if stderr != nil {
+		io.WriteString(stderr, r.stderr)
+	}
+	return r.err
+}
+
 // TestNewVideoCompressor tests the NewVideoCompressor function
 func TestNewVideoCompressor(t *testing.T) {
-	// Skip this test for now as we have issues with mock types
-	t.Skip("Skipping test due to mock type issues")
+	logger := util.NewLogger(false)
+	runner := &fakeRunner{}
+	ff := ffmpeg.NewFFmpeg("in.mp4", "out.mp4", nil, logger)
+	ff.Runner = runner
+
+	vc := NewVideoCompressor(ff, nil, logger)
+	assert.Equal(t, ff, vc.FFmpeg)
+	assert.Equal(t, runtime.NumCPU(), vc.ConcurrentWorkers)
+	assert.Empty(t, vc.TempDir)
+	assert.Equal(t, runner, vc.runner())
+
+	// Without an FFmpeg instance the default runner is used
+	assert.Equal(t, ffmpeg.DefaultRunner, (&VideoCompressor{}).runner())
+}
+
+// TestCompressVideoSingleRunner tests that the encode goes through the injected runner
+func TestCompressVideoSingleRunner(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+	runner := &fakeRunner{stderr: "frame=  120 fps= 60 q=28.0 size=    1024kB time=00:00:05.00 bitrate=1677.7kbits/s speed=2.0x\r"}
+	ff := ffmpeg.NewFFmpeg("in.mp4", "out.mp4", nil, logger)
+	ff.Runner = runner
+	vc := NewVideoCompressor(ff, nil, logger)
+
+	settings := map[string]string{"codec": "libx264", "crf": "23"}
+	progress := util.NewProgressTracker(100, "Compressing", logger)
+	assert.NoError(t, vc.compressVideoSingle("in.mp4", "out.mp4", settings, progress))
+	assert.Len(t, runner.calls, 2)
+	assert.Equal(t, "ffprobe", runner.calls[0][0])
+	assert.Equal(t, "ffmpeg", runner.calls[1][0])
+	assert.Equal(t, vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings), runner.calls[1][1:])
+
+	// A failed encode reports what FFmpeg printed
+	runner.stderr = "Unknown encoder 'libx264'"
+	runner.err = fmt.Errorf("exit status 1")
+	err := vc.compressVideoSingle("in.mp4", "out.mp4", settings, util.NewProgressTracker(100, "Compressing", logger))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown encoder 'libx264'")
 }
 
 // TestEstimateFrameQuality tests the EstimateFrameQuality function
@@ -75,4 +143,121 @@ func TestAdjustSettingsForPreset(t *testing.T) {
 func TestBuildFFmpegArgs(t *testing.T) {
 	// Skip this test for now due to API changes
 	t.Skip("Skipping test due to API changes in BuildFFmpegArgs")
-} 
\ No newline at end of file
+}
+
+// TestBuildFFmpegArgsHWAccel tests the hardware decode options added by BuildFFmpegArgs
+func TestBuildFFmpegArgsHWAccel(t *testing.T) {
+	vc := &VideoCompressor{
+		FFmpeg: ffmpeg.NewFFmpeg("in.mp4", "out.mp4", &ffmpeg.Options{HWAccel: "cuda"}, nil),
+		Logger: util.NewLogger(false),
+	}
+
+	// A software encoder gets the decoded frames copied back to the CPU
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "libx265", "pix_fmt": "yuv420p"})
+	assert.Equal(t, []string{"-y", "-hwaccel", "cuda", "-i", "in.mp4"}, args[:5])
+	assert.Contains(t, args, "-pix_fmt")
+	assert.NotContains(t, args, "-hwaccel_output_format")
+
+	// NVENC keeps the frames on the GPU
+	args = vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "hevc_nvenc", "bitrate": "4M", "pix_fmt": "yuv420p"})
+	assert.Equal(t, []string{"-y", "-hwaccel", "cuda", "-hwaccel_output_format", "cuda", "-i", "in.mp4"}, args[:7])
+	assert.NotContains(t, args, "-pix_fmt")
+}
+
+// TestBuildFFmpegArgsRemoteInput tests that URLs are read with reconnects
+func TestBuildFFmpegArgsRemoteInput(t *testing.T) {
+	vc := &VideoCompressor{}
+	url := "https://cdn.example.com/talk.mp4"
+	args := vc.BuildFFmpegArgs(url, "talk-compressed.mp4", map[string]string{"codec": "libx264", "crf": "23"})
+	assert.Equal(t, []string{"-y", "-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "5", "-i", url}, args[:9])
+
+	// Remote inputs without a known size leave the savings unknown
+	size, err := inputSize(url, &analyzer.VideoAnalysis{VideoFile: &ffmpeg.VideoFile{Size: 1024}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), size)
+	size, err = inputSize(url, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), size)
+}
+
+func TestBuildFFmpegArgsSpecialFileNames(t *testing.T) {
+	vc := &VideoCompressor{}
+	settings := map[string]string{"codec": "libx264", "crf": "23"}
+
+	// Quotes, spaces and emoji are passed through as they are
+	args := vc.BuildFFmpegArgs("It's my 🎬.mp4", "It's my 🎬-compressed.mp4", settings)
+	assert.Equal(t, []string{"-y", "-i", "It's my 🎬.mp4"}, args[:3])
+	assert.Equal(t, "It's my 🎬-compressed.mp4", args[len(args)-1])
+
+	// Leading dashes and protocol-like prefixes are opened as files
+	args = vc.BuildFFmpegArgs("-take2.mp4", "Take2:final.mp4", settings)
+	assert.Equal(t, []string{"-y", "-i", "file:-take2.mp4"}, args[:3])
+	assert.Equal(t, "file:Take2:final.mp4", args[len(args)-1])
+}
+
+// TestThreadLimit tests how the thread limit caps parallel segments
+func TestThreadLimit(t *testing.T) {
+	vc := &VideoCompressor{ConcurrentWorkers: 16}
+	assert.Equal(t, 8, vc.parallelSegments())
+
+	vc.Threads = 4
+	assert.Equal(t, 4, vc.parallelSegments())
+
+	assert.Equal(t, 3, segmentThreads(12, 4))
+	assert.Equal(t, 1, segmentThreads(2, 4))
+	assert.Equal(t, 1, segmentThreads(4, 0))
+}
+
+func TestWriteSegmentList(t *testing.T) {
+	dir := t.TempDir()
+	segments := make([]string, 12)
+	for i := range segments {
+		segments[i] = filepath.Join(dir, fmt.Sprintf("out_%04d.mp4", i))
+		assert.NoError(t, os.WriteFile(segments[i], []byte("segment"), 0644))
+	}
+
+	listPath := filepath.Join(dir, "segments.txt")
+	assert.NoError(t, writeSegmentList(listPath, segments))
+	data, err := os.ReadFile(listPath)
+	assert.NoError(t, err)
+	expected := ""
+	for i := range segments {
+		expected += fmt.Sprintf("file 'out_%04d.mp4'\n", i)
+	}
+	assert.Equal(t, expected, string(data))
+
+	// A segment that was never written fails the merge before it starts
+	assert.NoError(t, os.WriteFile(segments[5], nil, 0644))
+	assert.Error(t, writeSegmentList(listPath, segments))
+	assert.NoError(t, os.Remove(segments[5]))
+	assert.Error(t, writeSegmentList(listPath, segments))
+}
+
+func TestMergeArgs(t *testing.T) {
+	vc := &VideoCompressor{}
+	audio := []ffmpeg.AudioStreamInfo{{Index: 1, Channels: 2}, {Index: 2, Channels: 6}, {Index: 3, Channels: 6}}
+
+	// The video of the segments is copied and the audio FFmpeg would have
+	// picked, the first 5.1 track, is encoded from the source
+	settings := map[string]string{"codec": "libx265", "audio_codec": "aac", "audio_bitrate": "128k", "audio_channels": "2"}
+	args := vc.mergeArgs("segments.txt", "in.mkv", "out.mkv", settings, audio)
+	assert.Equal(t, []string{"-f", "concat", "-safe", "0", "-i", "segments.txt", "-i", "in.mkv",
+		"-map", "0:v", "-map", "1:a:1?", "-c:v", "copy", "-c:a", "aac", "-b:a", "128k", "-ac", "2", "-y", "out.mkv"}, args)
+
+	// Copied audio
+	settings = map[string]string{"codec": "libx264", "audio_codec": "copy"}
+	args = vc.mergeArgs("segments.txt", "in.mp4", "out.mp4", settings, nil)
+	assert.Equal(t, []string{"-f", "concat", "-safe", "0", "-i", "segments.txt", "-i", "in.mp4",
+		"-map", "0:v", "-map", "1:a:0?", "-c:v", "copy", "-c:a", "copy", "-y", "out.mp4"}, args)
+
+	// Without audio the source is not read again
+	settings = map[string]string{"codec": "libx264", "no_audio": "1"}
+	args = vc.mergeArgs("segments.txt", "in.mp4", "out.mp4", settings, audio)
+	assert.Equal(t, []string{"-f", "concat", "-safe", "0", "-i", "segments.txt",
+		"-map", "0:v", "-c:v", "copy", "-an", "-y", "out.mp4"}, args)
+
+	// Segments are encoded without audio
+	args = vc.BuildFFmpegArgs("segment_0000.mp4", "out_0000.mp4", map[string]string{"codec": "libx264", "audio_codec": "aac", "no_audio": "1"})
+	assert.Contains(t, args, "-an")
+	assert.NotContains(t, args, "-c:a")
+}
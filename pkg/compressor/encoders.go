@@ -0,0 +1,113 @@
+package compressor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+)
+
+// encoderFallbacks lists, for each encoder the analyzer may recommend, the
+// encoders to try in order when the FFmpeg build does not include it
+var encoderFallbacks = map[string][]string{
+	"libx265":    {"libx264", "libopenh264"},
+	"libvpx-vp9": {"libx265", "libx264", "libopenh264"},
+	"libsvtav1":  {"libaom-av1", "libx265", "libx264", "libopenh264"},
+	"libaom-av1": {"libsvtav1", "libx265", "libx264", "libopenh264"},
+	"h264_nvenc": {"libx264", "libopenh264"},
+	"hevc_nvenc": {"libx265", "libx264", "libopenh264"},
+	"h264_qsv":   {"libx264", "libopenh264"},
+	"hevc_qsv":   {"libx265", "libx264", "libopenh264"},
+	"h264_amf":   {"libx264", "libopenh264"},
+	"hevc_amf":   {"libx265", "libx264", "libopenh264"},
+	"libx264":    {"libopenh264"},
+}
+
+// defaultCRF is the default quality value of each CRF-based encoder
+var defaultCRF = map[string]string{
+	"libx264":    "23",
+	"libx265":    "28",
+	"libvpx-vp9": "31",
+	"libsvtav1":  "35",
+	"libaom-av1": "30",
+}
+
+// selectEncoder returns the encoder to use for codec given the encoders
+// available in the FFmpeg build, and whether one was found
+func selectEncoder(codec string, available map[string]bool) (string, bool) {
+	if available[codec] {
+		return codec, true
+	}
+	for _, fallback := range encoderFallbacks[codec] {
+		if available[fallback] {
+			return fallback, true
+		}
+	}
+	return "", false
+}
+
+// ensureEncoderAvailable replaces the recommended encoder with an available
+// one when the FFmpeg build lacks it, so the encode does not fail midway
+func (vc *VideoCompressor) ensureEncoderAvailable(settings map[string]string) error {
+	codec := settings["codec"]
+	if codec == "" || codec == "copy" || vc.FFmpeg == nil {
+		return nil
+	}
+
+	available, err := vc.FFmpeg.Encoders()
+	if err != nil {
+		// Without the list, let FFmpeg report any problem itself
+		vc.Logger.Debug("Could not probe FFmpeg encoders: %v", err)
+		return nil
+	}
+
+	encoder, ok := selectEncoder(codec, available)
+	if !ok {
+		return fmt.Errorf("FFmpeg build has no encoder for %s (and none of its fallbacks: %s)",
+			codec, strings.Join(encoderFallbacks[codec], ", "))
+	}
+	if encoder != codec {
+		vc.Logger.Warning("Encoder %s is not available in this FFmpeg build, using %s instead", codec, encoder)
+		switchEncoder(settings, codec, encoder)
+	}
+	return nil
+}
+
+// switchEncoder updates the settings so they are valid for the new encoder
+func switchEncoder(settings map[string]string, from, to string) {
+	settings["codec"] = to
+
+	// Tune, profile and level values were chosen for the previous encoder
+	// and are not valid for every other one
+	for _, key := range []string{"tune", "profile", "level", "x265-params"} {
+		delete(settings, key)
+	}
+
+	// VP9 runs on its own CRF scale with -b:v 0; go back to the H.264 scale
+	if from == "libvpx-vp9" {
+		if crf, err := strconv.Atoi(settings["crf"]); err == nil {
+			settings["crf"] = strconv.Itoa(crf - analyzer.VP9CRFOffset)
+		}
+		if settings["bitrate"] == "0" {
+			delete(settings, "bitrate")
+		}
+		delete(settings, "row_mt")
+		delete(settings, "tile_columns")
+	}
+
+	if to == "libopenh264" {
+		// OpenH264 is bitrate-driven and has no CRF or presets
+		delete(settings, "crf")
+		delete(settings, "preset")
+		return
+	}
+
+	// Encoders without CRF (NVENC) leave no usable value: use the new
+	// encoder's default
+	if crf, ok := defaultCRF[to]; ok {
+		if _, hadCRF := defaultCRF[from]; !hadCRF || settings["crf"] == "" {
+			settings["crf"] = crf
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectEncoder(t *testing.T) {
+	available := map[string]bool{"libx264": true, "aac": true}
+
+	encoder, ok := selectEncoder("libx264", available)
+	assert.True(t, ok)
+	assert.Equal(t, "libx264", encoder)
+
+	encoder, ok = selectEncoder("libx265", available)
+	assert.True(t, ok)
+	assert.Equal(t, "libx264", encoder)
+
+	encoder, ok = selectEncoder("hevc_nvenc", available)
+	assert.True(t, ok)
+	assert.Equal(t, "libx264", encoder)
+
+	_, ok = selectEncoder("libx265", map[string]bool{"aac": true})
+	assert.False(t, ok)
+}
+
+func TestSwitchEncoder(t *testing.T) {
+	settings := map[string]string{
+		"codec":       "libx265",
+		"crf":         "26",
+		"preset":      "medium",
+		"profile":     "main",
+		"tune":        "zerolatency",
+		"x265-params": "bframes=0",
+	}
+	switchEncoder(settings, "libx265", "libx264")
+
+	assert.Equal(t, "libx264", settings["codec"])
+	assert.Equal(t, "26", settings["crf"])
+	assert.Equal(t, "medium", settings["preset"])
+	assert.NotContains(t, settings, "x265-params")
+	assert.NotContains(t, settings, "tune")
+
+	// NVENC has no CRF, so the new encoder's default is used
+	settings = map[string]string{"codec": "hevc_nvenc", "bitrate": "4M"}
+	switchEncoder(settings, "hevc_nvenc", "libx265")
+	assert.Equal(t, "28", settings["crf"])
+
+	settings = map[string]string{"codec": "libx264", "crf": "23", "preset": "fast", "bitrate": "2M"}
+	switchEncoder(settings, "libx264", "libopenh264")
+	assert.NotContains(t, settings, "crf")
+	assert.NotContains(t, settings, "preset")
+	assert.Equal(t, "2M", settings["bitrate"])
+}
@@ -0,0 +1,13 @@
+package compressor
+
+import "time"
+
+// DefaultWattsPerCore is the power one busy CPU core is taken to draw: a
+// middle ground between laptop and desktop processors
+const DefaultWattsPerCore = 10.0
+
+// EstimateEnergy returns the approximate energy, in watt-hours, of cpuTime
+// of work on cores drawing wattsPerCore each
+func EstimateEnergy(cpuTime time.Duration, wattsPerCore float64) float64 {
+	return cpuTime.Hours() * wattsPerCore
+}
@@ -0,0 +1,15 @@
+package compressor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateEnergy(t *testing.T) {
+	assert.InDelta(t, 10.0, EstimateEnergy(time.Hour, DefaultWattsPerCore), 1e-9)
+	// CPU time adds up across cores: two busy for half an hour make an hour
+	assert.InDelta(t, 5.0, EstimateEnergy(2*30*time.Minute, 5), 1e-9)
+	assert.Equal(t, 0.0, EstimateEnergy(0, DefaultWattsPerCore))
+}
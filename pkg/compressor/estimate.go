@@ -0,0 +1,44 @@
+package compressor
+
+import (
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+const (
+	// parallelEfficiency is the extra throughput each additional segment worker
+	// adds, since the encoder itself is already multi-threaded
+	parallelEfficiency = 0.25
+	// maxParallelSpeedup caps the gain from encoding segments concurrently
+	maxParallelSpeedup = 2.0
+)
+
+// EstimateCompression predicts the output size and encode time for a video
+// using the settings CompressVideo would apply for the given preset
+func (vc *VideoCompressor) EstimateCompression(analysis *analyzer.VideoAnalysis, settings map[string]string, preset string) (int64, time.Duration) {
+	// Work on a copy so the caller's settings are adjusted only once, by CompressVideo
+	adjusted := make(map[string]string, len(settings))
+	for k, v := range settings {
+		adjusted[k] = v
+	}
+	vc.adjustSettingsForPreset(adjusted, preset)
+
+	var audio []ffmpeg.AudioStreamInfo
+	if analysis != nil && analysis.VideoFile != nil {
+		audio = MappedAudio(adjusted, analysis.VideoFile.AudioInfo)
+	}
+	size := analyzer.EstimateOutputSize(analysis, adjusted, audio)
+	encodeTime := analyzer.EstimateEncodeTime(analysis, adjusted)
+
+	if segments, _ := vc.parallelPlan(analysis, adjusted); segments > 0 {
+		speedup := 1 + parallelEfficiency*float64(segments-1)
+		if speedup > maxParallelSpeedup {
+			speedup = maxParallelSpeedup
+		}
+		encodeTime = time.Duration(float64(encodeTime) / speedup)
+	}
+
+	return size, encodeTime
+}
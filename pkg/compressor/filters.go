@@ -0,0 +1,242 @@
+package compressor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// VideoFilters are changes to the picture chosen on the command line. Each
+// becomes one stage of the video filter chain.
+type VideoFilters struct {
+	Deinterlace bool    // Turn interlaced fields into frames
+	FPS         float64 // Output frame rate, 0 for the source's
+	Crop        string  // Region to keep as width:height:x:y
+	Denoise     string  // Strength of the noise removal: light, medium or strong
+	Tonemap     bool    // Map HDR video to SDR
+	Scale       string  // Output size as WIDTHxHEIGHT, or a height that keeps the aspect ratio
+
+	// SquarePixels resizes anamorphic video, such as DV and DVD rips, to
+	// its display aspect ratio with square pixels, for players that ignore
+	// the sample aspect ratio and would show it stretched
+	SquarePixels bool
+
+	// Subtitles are drawn into the picture, for players without subtitle
+	// support, from a subtitle file or from the SubtitleStream-th subtitle
+	// stream of a video
+	Subtitles      string
+	SubtitleStream int
+}
+
+// denoiseFilters holds the hqdn3d filter of each denoise strength. Medium
+// uses the filter's defaults.
+var denoiseFilters = map[string]string{
+	"light":  "hqdn3d=2:1.5:3:2.25",
+	"medium": "hqdn3d=4:3:6:4.5",
+	"strong": "hqdn3d=8:6:12:9",
+}
+
+// DenoiseStrengths lists the valid --denoise values
+var DenoiseStrengths = []string{"light", "medium", "strong"}
+
+// tonemapFilter converts PQ or HLG video to BT.709 SDR: the picture is
+// made linear, tone mapped with the Hable curve in floating point and
+// brought back to 8-bit 4:2:0
+const tonemapFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+
+// deinterlaceFilter makes one frame of each pair of fields and leaves
+// frames that are not flagged interlaced alone
+const deinterlaceFilter = "bwdif=mode=send_frame:deint=interlaced"
+
+// Stages returns the filter stages the options fill, in chain order
+func (f VideoFilters) Stages() []ffmpeg.FilterStage {
+	var stages []ffmpeg.FilterStage
+	if f.Deinterlace {
+		stages = append(stages, ffmpeg.FilterDeinterlace)
+	}
+	if f.FPS > 0 {
+		stages = append(stages, ffmpeg.FilterFPS)
+	}
+	if f.Crop != "" {
+		stages = append(stages, ffmpeg.FilterCrop)
+	}
+	if f.Denoise != "" {
+		stages = append(stages, ffmpeg.FilterDenoise)
+	}
+	if f.Tonemap {
+		stages = append(stages, ffmpeg.FilterTonemap)
+	}
+	if f.Scale != "" || f.SquarePixels {
+		stages = append(stages, ffmpeg.FilterScale)
+	}
+	if f.Subtitles != "" {
+		stages = append(stages, ffmpeg.FilterSubtitles)
+	}
+	return stages
+}
+
+// Validate checks the values that do not depend on the video
+func (f VideoFilters) Validate() error {
+	if f.FPS < 0 {
+		return i18n.Errorf("frame rate must be more than 0 (got %g)", f.FPS)
+	}
+	if f.Crop != "" {
+		if _, err := parseCrop(f.Crop); err != nil {
+			return err
+		}
+	}
+	if f.Denoise != "" && denoiseFilters[f.Denoise] == "" {
+		return i18n.Errorf("denoise must be one of: %s (got %s)", strings.Join(DenoiseStrengths, ", "), f.Denoise)
+	}
+	if f.Scale != "" {
+		if _, err := scaleFilter(f.Scale); err != nil {
+			return err
+		}
+	}
+	if f.SubtitleStream < 0 {
+		return i18n.Errorf("subtitle stream must be 0 or more (got %d)", f.SubtitleStream)
+	}
+	return nil
+}
+
+// ApplyVideoFilters adds the filters to the video filter chain of settings.
+// A crop must fit in the source frame, and tone mapping is left out for
+// video that is not HDR. It fails when a stage is already set to another
+// filter, such as a frame rate chosen for a screencast, or when the video
+// is copied.
+func ApplyVideoFilters(settings map[string]string, f VideoFilters, videoFile *ffmpeg.VideoFile) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	if len(f.Stages()) == 0 {
+		return nil
+	}
+	if settings["codec"] == "copy" {
+		return i18n.Errorf("video filters cannot be used with --copy-video")
+	}
+
+	graphs := make(map[ffmpeg.FilterStage]string)
+	if f.Deinterlace {
+		graphs[ffmpeg.FilterDeinterlace] = deinterlaceFilter
+	}
+	if f.FPS > 0 {
+		graphs[ffmpeg.FilterFPS] = "fps=" + strconv.FormatFloat(f.FPS, 'f', -1, 64)
+		// Keep the keyframe interval the same length in seconds
+		if gop, err := strconv.Atoi(settings["gop"]); err == nil && videoFile != nil && videoFile.VideoInfo.FPS > 0 {
+			seconds := float64(gop) / ffmpeg.OutputFPS(settings, videoFile.VideoInfo.FPS)
+			settings["gop"] = strconv.Itoa(analyzer.KeyframeInterval(seconds, f.FPS))
+		}
+	}
+	if f.Crop != "" {
+		crop, _ := parseCrop(f.Crop)
+		if videoFile != nil {
+			width, height := videoFile.VideoInfo.Width, videoFile.VideoInfo.Height
+			if width > 0 && height > 0 && (crop[0]+crop[2] > width || crop[1]+crop[3] > height) {
+				return i18n.Errorf("crop %s does not fit in the %dx%d frame", f.Crop, width, height)
+			}
+		}
+		graphs[ffmpeg.FilterCrop] = fmt.Sprintf("crop=%d:%d:%d:%d", crop[0], crop[1], crop[2], crop[3])
+	}
+	if f.Denoise != "" {
+		graphs[ffmpeg.FilterDenoise] = denoiseFilters[f.Denoise]
+	}
+	if f.Tonemap && videoFile != nil && videoFile.VideoInfo.IsHDR {
+		graphs[ffmpeg.FilterTonemap] = tonemapFilter
+		// The tone-mapped picture is 8-bit, so a 10-bit profile no longer fits
+		if settings["pix_fmt"] != "yuv420p" {
+			delete(settings, "profile")
+		}
+		settings["pix_fmt"] = "yuv420p"
+	}
+	if f.SquarePixels && videoFile != nil && videoFile.VideoInfo.IsAnamorphic() {
+		graphs[ffmpeg.FilterScale] = squarePixelsFilter(f.Scale)
+	} else if f.Scale != "" {
+		scale, _ := scaleFilter(f.Scale)
+		graphs[ffmpeg.FilterScale] = scale
+	}
+	if f.Subtitles != "" {
+		graphs[ffmpeg.FilterSubtitles] = subtitlesFilter(f.Subtitles, f.SubtitleStream)
+	}
+
+	for _, stage := range f.Stages() {
+		// Tone mapping is skipped for SDR video, square pixels for video
+		// that already has them
+		if graphs[stage] == "" {
+			continue
+		}
+		if err := ffmpeg.SetVideoFilter(settings, stage, graphs[stage]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCrop reads a crop region as width:height:x:y
+func parseCrop(value string) ([4]int, error) {
+	var crop [4]int
+	parts := strings.Split(value, ":")
+	if len(parts) != len(crop) {
+		return crop, i18n.Errorf("invalid crop %q: expected width:height:x:y, e.g. 1920:800:0:140", value)
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		// Width and height must be positive, the offsets may be 0
+		if err != nil || n < 0 || (i < 2 && n == 0) {
+			return crop, i18n.Errorf("invalid crop %q: expected width:height:x:y, e.g. 1920:800:0:140", value)
+		}
+		crop[i] = n
+	}
+	return crop, nil
+}
+
+// scaleFilter returns the scale filter of an output size given as
+// WIDTHxHEIGHT or as a height, whose width keeps the aspect ratio
+func scaleFilter(value string) (string, error) {
+	widthText, heightText, sized := strings.Cut(strings.ToLower(value), "x")
+	if !sized {
+		widthText, heightText = "", widthText
+	}
+	height, err := strconv.Atoi(strings.TrimSuffix(heightText, "p"))
+	if err != nil || height <= 0 {
+		return "", i18n.Errorf("invalid scale %q: expected WIDTHxHEIGHT or a height, e.g. 1280x720 or 720", value)
+	}
+	if !sized {
+		// -2 keeps the width even, as 4:2:0 video needs
+		return fmt.Sprintf("scale=-2:%d", height), nil
+	}
+	width, err := strconv.Atoi(widthText)
+	if err != nil || width <= 0 {
+		return "", i18n.Errorf("invalid scale %q: expected WIDTHxHEIGHT or a height, e.g. 1280x720 or 720", value)
+	}
+	return fmt.Sprintf("scale=%d:%d", width, height), nil
+}
+
+// squarePixelsFilter returns the scale filter that turns anamorphic video
+// into square pixels at its display aspect ratio. The width is worked out
+// by the filter from the frame it receives, so a crop before it is taken
+// into account. A size given with --scale is kept; a height alone gets the
+// width of the display aspect ratio.
+func squarePixelsFilter(size string) string {
+	if size == "" {
+		return "scale=trunc(iw*sar/2)*2:ih,setsar=1"
+	}
+	scale, _ := scaleFilter(size)
+	if height, ok := strings.CutPrefix(scale, "scale=-2:"); ok {
+		return fmt.Sprintf("scale=trunc(%s*dar/2)*2:%s,setsar=1", height, height)
+	}
+	return scale + ",setsar=1"
+}
+
+// subtitlesFilter returns the filter that draws the subtitles of file, a
+// subtitle file or the stream-th subtitle stream of a video, with libass
+func subtitlesFilter(file string, stream int) string {
+	graph := "subtitles=filename=" + ffmpeg.FilterPath(file)
+	if stream > 0 {
+		graph += ":si=" + strconv.Itoa(stream)
+	}
+	return graph
+}
@@ -0,0 +1,105 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVideoFiltersValidate(t *testing.T) {
+	assert.NoError(t, VideoFilters{}.Validate())
+	assert.NoError(t, VideoFilters{Crop: "1920:800:0:140", Denoise: "light", Scale: "1280x720", FPS: 23.976}.Validate())
+	assert.NoError(t, VideoFilters{Scale: "720p"}.Validate())
+	for _, f := range []VideoFilters{
+		{FPS: -1}, {Crop: "1920:800"}, {Crop: "0:800:0:0"}, {Crop: "a:b:c:d"},
+		{Denoise: "extreme"}, {Scale: "big"}, {Scale: "1280x"}, {Scale: "0"},
+	} {
+		assert.Error(t, f.Validate(), "%+v", f)
+	}
+}
+
+func TestApplyVideoFilters(t *testing.T) {
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, IsHDR: true}}
+	settings := map[string]string{"codec": "libx265", "pix_fmt": "yuv420p10le", "profile": "main10"}
+	f := VideoFilters{Deinterlace: true, FPS: 25, Crop: "1920:800:0:140", Denoise: "medium", Tonemap: true, Scale: "720"}
+	assert.NoError(t, ApplyVideoFilters(settings, f, video))
+	assert.Equal(t, deinterlaceFilter+",fps=25,crop=1920:800:0:140,hqdn3d=4:3:6:4.5,"+tonemapFilter+",scale=-2:720", settings["video_filter"])
+	assert.Equal(t, "yuv420p", settings["pix_fmt"])
+	assert.NotContains(t, settings, "profile")
+
+	// SDR video is not tone mapped
+	video.VideoInfo.IsHDR = false
+	settings = map[string]string{"codec": "libx264", "pix_fmt": "yuv420p"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{Tonemap: true}, video))
+	assert.NotContains(t, settings, "video_filter")
+
+	assert.EqualError(t, ApplyVideoFilters(map[string]string{"codec": "libx264"}, VideoFilters{Crop: "1920:800:0:400"}, video),
+		"crop 1920:800:0:400 does not fit in the 1920x1080 frame")
+	assert.Error(t, ApplyVideoFilters(map[string]string{"codec": "copy"}, VideoFilters{Scale: "720"}, video))
+
+	// A stage another part of the tool already set collides
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, "fps=15"))
+	assert.Error(t, ApplyVideoFilters(settings, VideoFilters{FPS: 30}, video))
+}
+
+// TestVideoFilterArgs tests that the filters of every stage reach -vf
+func TestVideoFilterArgs(t *testing.T) {
+	vc := &VideoCompressor{}
+	settings := map[string]string{"codec": "libx264", "crf": "23"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:720"))
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterDenoise, "hqdn3d"))
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings)
+	assert.Contains(t, args, "hqdn3d,scale=-2:720")
+
+	// Copying the video drops the filters
+	settings["audio_codec"] = "aac"
+	assert.NoError(t, ApplyStreamModes(settings, StreamModes{CopyVideo: true}))
+	assert.Empty(t, ffmpeg.VideoFilterChain(settings))
+}
+
+// TestFPSKeepsKeyframeSeconds tests that a new frame rate keeps the
+// keyframe interval the same length in seconds
+func TestFPSKeepsKeyframeSeconds(t *testing.T) {
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, FPS: 60}}
+	settings := map[string]string{"codec": "libx264", "gop": "120"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{FPS: 30}, video))
+	assert.Equal(t, "60", settings["gop"])
+}
+
+func TestSquarePixels(t *testing.T) {
+	dvd := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 720, Height: 480, SAR: "32:27", DAR: "16:9"}}
+	settings := map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{SquarePixels: true, Crop: "704:480:8:0"}, dvd))
+	assert.Equal(t, "crop=704:480:8:0,scale=trunc(iw*sar/2)*2:ih,setsar=1", settings["video_filter"])
+
+	// A height alone gets the width of the display aspect ratio
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{SquarePixels: true, Scale: "360"}, dvd))
+	assert.Equal(t, "scale=trunc(360*dar/2)*2:360,setsar=1", settings["video_filter"])
+
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{SquarePixels: true, Scale: "640x360"}, dvd))
+	assert.Equal(t, "scale=640:360,setsar=1", settings["video_filter"])
+
+	// Square pixels are left alone
+	hd := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080, SAR: "1:1"}}
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{SquarePixels: true}, hd))
+	assert.NotContains(t, settings, "video_filter")
+}
+
+func TestBurnSubtitles(t *testing.T) {
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080}}
+	settings := map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{Scale: "720", Subtitles: "clip.en.srt"}, video))
+	assert.Equal(t, "scale=-2:720,subtitles=filename=clip.en.srt", settings["video_filter"])
+
+	// A stream of the video is picked by its position among the subtitles
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyVideoFilters(settings, VideoFilters{Subtitles: `C:\Videos\it's.mkv`, SubtitleStream: 2}, video))
+	assert.Equal(t, `subtitles=filename=C\\:\\\\Videos\\\\it\\\'s.mkv:si=2`, settings["video_filter"])
+
+	assert.Error(t, VideoFilters{Subtitles: "clip.mkv", SubtitleStream: -1}.Validate())
+}
@@ -0,0 +1,124 @@
+package compressor
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// HDR transfer characteristics, as FFmpeg and x265 name them
+const (
+	transferPQ  = "smpte2084"    // HDR10, HDR10+ and Dolby Vision
+	transferHLG = "arib-std-b67" // Hybrid log-gamma broadcasts
+)
+
+// dolbyVisionProfileNoBase is the Dolby Vision profile without an HDR10
+// base layer: decoded without its metadata, the picture has wrong colors
+const dolbyVisionProfileNoBase = 5
+
+// ApplyHDR10 keeps an HDR source HDR when it is encoded with x265: the
+// output is 10-bit main10 with the source's BT.2020 colors and transfer,
+// and HDR10 video keeps its mastering display and light level metadata.
+// pixFmt is the pixel format chosen on the command line, which wins. It
+// reports whether the settings were changed.
+func ApplyHDR10(settings map[string]string, info ffmpeg.VideoStreamInfo, metadata *ffmpeg.HDRMetadata, pixFmt string) bool {
+	transfer := info.ColorTransfer
+	if !info.IsHDR || transfer != transferPQ && transfer != transferHLG || settings["codec"] != "libx265" || ffmpeg.VideoFilter(settings, ffmpeg.FilterTonemap) != "" {
+		return false
+	}
+
+	if pixFmt == "" {
+		settings["pix_fmt"] = "yuv420p10le"
+	}
+	settings["profile"] = "main10"
+
+	primaries, matrix := info.ColorPrimaries, info.ColorSpace
+	if primaries == "" {
+		primaries = "bt2020"
+	}
+	if matrix == "" {
+		matrix = "bt2020nc"
+	}
+	params := []string{"colorprim=" + primaries, "transfer=" + transfer, "colormatrix=" + matrix}
+
+	// HLG needs no metadata, HDR10 signals its display in SEI messages
+	if transfer == transferPQ {
+		params = append(params, "hdr10=1", "hdr10-opt=1")
+		if metadata != nil && metadata.MasteringDisplay != "" {
+			params = append(params, "master-display="+metadata.MasteringDisplay)
+		}
+		if metadata != nil && metadata.MaxCLL != "" {
+			params = append(params, "max-cll="+metadata.MaxCLL)
+		}
+	}
+	addX265Params(settings, params...)
+	return true
+}
+
+// addX265Params appends params to the -x265-params of settings
+func addX265Params(settings map[string]string, params ...string) {
+	if existing := settings["x265-params"]; existing != "" {
+		params = append([]string{existing}, params...)
+	}
+	settings["x265-params"] = strings.Join(params, ":")
+}
+
+// x265ParamValue escapes a value of -x265-params, which FFmpeg splits at
+// colons, such as the drive of a Windows path
+func x265ParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`).Replace(value)
+}
+
+// copiesHDR10Plus reports whether settings copy HDR10+ metadata, which is
+// numbered from the first frame of the video
+func copiesHDR10Plus(settings map[string]string) bool {
+	return strings.Contains(settings["x265-params"], "dhdr10-info=")
+}
+
+// prepareHDR keeps the HDR of the source in settings and reports the HDR
+// metadata the output loses. With HDR10Plus the HDR10+ metadata of an HEVC
+// source is extracted and given to x265.
+func (vc *VideoCompressor) prepareHDR(inputFile string, videoFile *ffmpeg.VideoFile, settings map[string]string) {
+	if videoFile == nil || !videoFile.VideoInfo.IsHDR || settings["codec"] == "copy" ||
+		ffmpeg.VideoFilter(settings, ffmpeg.FilterTonemap) != "" {
+		return
+	}
+	info := videoFile.VideoInfo
+
+	metadata, err := vc.FFmpeg.ProbeHDRMetadata(inputFile)
+	if err != nil {
+		vc.Logger.Debug("Could not read the HDR metadata of the frames: %v", err)
+		metadata = &ffmpeg.HDRMetadata{}
+	}
+	if !ApplyHDR10(settings, info, metadata, vc.Overrides.PixFmt) {
+		vc.Logger.Warning("The output of %s is not HDR; encode with libx265 to keep HDR, or use --tonemap for SDR", settings["codec"])
+		return
+	}
+
+	if info.DolbyVision == dolbyVisionProfileNoBase {
+		vc.Logger.Warning("Dolby Vision profile 5 has no HDR10 base layer, so the output will have wrong colors; keep the original instead")
+	} else if info.DolbyVision != 0 || metadata.DolbyVision {
+		vc.Logger.Warning("The Dolby Vision metadata of the source is not kept; players will show its HDR10 base layer")
+	}
+
+	if !metadata.HDR10Plus {
+		return
+	}
+	switch {
+	case !vc.HDR10Plus:
+		vc.Logger.Warning("The HDR10+ dynamic metadata of the source is lost (use --keep-hdr10plus to copy it, which needs %s)", ffmpeg.HDR10PlusTool)
+	case info.Codec != "hevc":
+		vc.Logger.Warning("The HDR10+ metadata of %s video cannot be extracted, so it is lost", info.Codec)
+	case ffmpeg.VideoFilter(settings, ffmpeg.FilterFPS) != "":
+		vc.Logger.Warning("The HDR10+ metadata is lost: it follows the frames of the source, and --fps changes them")
+	default:
+		jsonPath := filepath.Join(vc.TempDir, "hdr10plus.json")
+		if err := vc.FFmpeg.ExtractHDR10Plus(inputFile, jsonPath); err != nil {
+			vc.Logger.Warning("Could not extract the HDR10+ metadata, so it is lost: %v", err)
+			return
+		}
+		addX265Params(settings, "dhdr10-info="+x265ParamValue(jsonPath))
+		vc.Logger.Info("Copying the HDR10+ metadata of the source")
+	}
+}
@@ -0,0 +1,50 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyHDR10(t *testing.T) {
+	hdr10 := ffmpeg.VideoStreamInfo{IsHDR: true, ColorTransfer: "smpte2084", ColorPrimaries: "bt2020", ColorSpace: "bt2020nc"}
+	metadata := &ffmpeg.HDRMetadata{MasteringDisplay: "G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50)", MaxCLL: "1000,400"}
+
+	settings := map[string]string{"codec": "libx265", "pix_fmt": "yuv420p", "profile": "main", "x265-params": "bframes=0"}
+	assert.True(t, ApplyHDR10(settings, hdr10, metadata, ""))
+	assert.Equal(t, "yuv420p10le", settings["pix_fmt"])
+	assert.Equal(t, "main10", settings["profile"])
+	assert.Equal(t, "bframes=0:colorprim=bt2020:transfer=smpte2084:colormatrix=bt2020nc:hdr10=1:hdr10-opt=1:"+
+		"master-display=G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50):max-cll=1000,400", settings["x265-params"])
+
+	// HLG has no display metadata, and --pix-fmt wins
+	settings = map[string]string{"codec": "libx265", "pix_fmt": "yuv422p10le"}
+	hlg := ffmpeg.VideoStreamInfo{IsHDR: true, ColorTransfer: "arib-std-b67"}
+	assert.True(t, ApplyHDR10(settings, hlg, nil, "yuv422p10le"))
+	assert.Equal(t, "yuv422p10le", settings["pix_fmt"])
+	assert.Equal(t, "colorprim=bt2020:transfer=arib-std-b67:colormatrix=bt2020nc", settings["x265-params"])
+
+	// SDR sources, other encoders and tone mapped video are left alone
+	tonemapped := map[string]string{"codec": "libx265", "pix_fmt": "yuv420p"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(tonemapped, ffmpeg.FilterTonemap, tonemapFilter))
+	for name, settings := range map[string]map[string]string{
+		"x264":    {"codec": "libx264", "pix_fmt": "yuv420p"},
+		"tonemap": tonemapped,
+	} {
+		assert.False(t, ApplyHDR10(settings, hdr10, metadata, ""), name)
+		assert.Equal(t, "yuv420p", settings["pix_fmt"], name)
+	}
+	settings = map[string]string{"codec": "libx265"}
+	assert.False(t, ApplyHDR10(settings, ffmpeg.VideoStreamInfo{}, nil, ""))
+	assert.NotContains(t, settings, "x265-params")
+}
+
+func TestX265ParamValue(t *testing.T) {
+	assert.Equal(t, `/tmp/job/hdr10plus.json`, x265ParamValue("/tmp/job/hdr10plus.json"))
+	assert.Equal(t, `C\:\\Temp\\hdr10plus.json`, x265ParamValue(`C:\Temp\hdr10plus.json`))
+
+	settings := map[string]string{"x265-params": "dhdr10-info=" + x265ParamValue("/tmp/job/hdr10plus.json")}
+	assert.True(t, copiesHDR10Plus(settings))
+	assert.False(t, copiesHDR10Plus(map[string]string{}))
+}
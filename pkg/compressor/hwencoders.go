@@ -0,0 +1,264 @@
+package compressor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// nvencCQ maps the 1-5 quality scale to NVENC constant quality values. They
+// sit a little below the x264 CRF for the same level, which is roughly where
+// NVENC output matches x264 visually.
+var nvencCQ = map[int]string{
+	1: "32",
+	2: "29",
+	3: "26",
+	4: "23",
+	5: "20",
+}
+
+// nvencPresets maps compression presets to NVENC presets (p1 fastest, p7 best)
+var nvencPresets = map[string]string{
+	"fast":     "p2",
+	"balanced": "p5",
+	"thorough": "p7",
+}
+
+// nvencMultipass maps compression presets to the NVENC -multipass mode
+var nvencMultipass = map[string]string{
+	"fast":     "disabled",
+	"balanced": "qres",
+	"thorough": "fullres",
+}
+
+// qsvGlobalQuality maps the 1-5 quality scale to QSV ICQ global_quality values
+var qsvGlobalQuality = map[int]string{
+	1: "32",
+	2: "29",
+	3: "26",
+	4: "23",
+	5: "20",
+}
+
+// qsvPresets maps compression presets to QSV presets
+var qsvPresets = map[string]string{
+	"fast":     "veryfast",
+	"balanced": "medium",
+	"thorough": "veryslow",
+}
+
+// amfQP maps the 1-5 quality scale to AMF constant QP values
+var amfQP = map[int]int{
+	1: 32,
+	2: 29,
+	3: 26,
+	4: 23,
+	5: 20,
+}
+
+// amfQuality maps compression presets to the AMF -quality option
+var amfQuality = map[string]string{
+	"fast":     "speed",
+	"balanced": "balanced",
+	"thorough": "quality",
+}
+
+// isNVENC reports whether codec is an NVIDIA hardware encoder
+func isNVENC(codec string) bool {
+	return strings.HasSuffix(codec, "_nvenc")
+}
+
+// isQSV reports whether codec is an Intel Quick Sync hardware encoder
+func isQSV(codec string) bool {
+	return strings.HasSuffix(codec, "_qsv")
+}
+
+// isAMF reports whether codec is an AMD hardware encoder
+func isAMF(codec string) bool {
+	return strings.HasSuffix(codec, "_amf")
+}
+
+// isHardwareEncoder reports whether codec runs on an NVIDIA, Intel or AMD GPU
+func isHardwareEncoder(codec string) bool {
+	return isNVENC(codec) || isQSV(codec) || isAMF(codec)
+}
+
+// hardwareCodec returns the encoder of family that produces the same format
+// as codec. HEVC is used for everything that is not H.264.
+func hardwareCodec(codec, family string) string {
+	switch codec {
+	case "libx264", "libopenh264", "h264_nvenc", "h264_qsv", "h264_amf":
+		return "h264_" + family
+	}
+	return "hevc_" + family
+}
+
+// selectHardwareEncoder returns the hardware encoder to use in place of codec.
+// family is a hardware encoder family or "auto" for the first one available.
+func selectHardwareEncoder(codec, family string, available map[string]bool) (string, bool) {
+	families := []string{family}
+	if family == "auto" {
+		families = ffmpeg.DetectAvailableHWAccelerators(available)
+	}
+	for _, f := range families {
+		if encoder := hardwareCodec(codec, f); available[encoder] {
+			return encoder, true
+		}
+	}
+	return "", false
+}
+
+// useHardwareEncoder switches the settings to the hardware encoder family
+// when the FFmpeg build has one for the recommended format
+func (vc *VideoCompressor) useHardwareEncoder(settings map[string]string, family string) {
+	codec := settings["codec"]
+	if family == "" || codec == "" || codec == "copy" || vc.FFmpeg == nil {
+		return
+	}
+
+	available, err := vc.FFmpeg.Encoders()
+	if err != nil {
+		vc.Logger.Warning("Could not list FFmpeg encoders, keeping %s: %v", codec, err)
+		return
+	}
+
+	encoder, ok := selectHardwareEncoder(codec, family, available)
+	if !ok {
+		vc.Logger.Warning("No %s hardware encoder for %s in this FFmpeg build, keeping %s", family, codec, codec)
+		return
+	}
+	if encoder != codec {
+		vc.Logger.Info("Using hardware encoder %s instead of %s", encoder, codec)
+		settings["codec"] = encoder
+	}
+}
+
+// applyHardwareEncoderSettings replaces the software encoder settings from the
+// analyzer with quality-driven settings for hardware encoders, so a hardware
+// encode at a given quality level looks close to a software one
+func applyHardwareEncoderSettings(settings map[string]string, quality int, preset string) {
+	codec := settings["codec"]
+	switch {
+	case isNVENC(codec):
+		applyNVENCSettings(settings, quality, preset)
+	case isQSV(codec):
+		applyQSVSettings(settings, quality, preset)
+	case isAMF(codec):
+		applyAMFSettings(settings, quality, preset)
+	}
+}
+
+// applyNVENCSettings sets up constant-quality NVENC encoding
+func applyNVENCSettings(settings map[string]string, quality int, preset string) {
+	cq, ok := nvencCQ[quality]
+	if !ok {
+		cq = nvencCQ[3]
+	}
+	nvPreset, ok := nvencPresets[preset]
+	if !ok {
+		nvPreset = nvencPresets["balanced"]
+	}
+	multipass, ok := nvencMultipass[preset]
+	if !ok {
+		multipass = nvencMultipass["balanced"]
+	}
+
+	settings["cq"] = cq
+	settings["preset"] = nvPreset
+	settings["tune"] = "hq"
+	settings["multipass"] = multipass
+
+	// CRF, x264/x265 levels and a target bitrate do not apply in CQ mode
+	for _, key := range []string{"crf", "level", "x265-params", "bitrate"} {
+		delete(settings, key)
+	}
+}
+
+// applyQSVSettings sets up ICQ (intelligent constant quality) QSV encoding
+func applyQSVSettings(settings map[string]string, quality int, preset string) {
+	globalQuality, ok := qsvGlobalQuality[quality]
+	if !ok {
+		globalQuality = qsvGlobalQuality[3]
+	}
+	qsvPreset, ok := qsvPresets[preset]
+	if !ok {
+		qsvPreset = qsvPresets["balanced"]
+	}
+
+	settings["global_quality"] = globalQuality
+	settings["preset"] = qsvPreset
+	// Look-ahead improves rate control at the cost of speed
+	if preset != "fast" {
+		settings["look_ahead"] = "1"
+	}
+	// QSV encoders take NV12 rather than planar YUV
+	if settings["pix_fmt"] != "" {
+		settings["pix_fmt"] = "nv12"
+	}
+
+	for _, key := range []string{"crf", "tune", "profile", "level", "x265-params", "bitrate"} {
+		delete(settings, key)
+	}
+}
+
+// applyAMFSettings sets up constant-QP AMF encoding
+func applyAMFSettings(settings map[string]string, quality int, preset string) {
+	qp, ok := amfQP[quality]
+	if !ok {
+		qp = amfQP[3]
+	}
+	amfPreset, ok := amfQuality[preset]
+	if !ok {
+		amfPreset = amfQuality["balanced"]
+	}
+
+	settings["rc"] = "cqp"
+	settings["qp"] = fmt.Sprintf("%d", qp)
+	settings["amf_quality"] = amfPreset
+
+	// AMF has no -preset; its speed/quality tradeoff is the -quality option
+	for _, key := range []string{"crf", "preset", "tune", "profile", "level", "x265-params", "bitrate"} {
+		delete(settings, key)
+	}
+}
+
+// qsvArgs returns the rate control arguments for a QSV encoder
+func qsvArgs(codec string, settings map[string]string) []string {
+	var args []string
+	if globalQuality := settings["global_quality"]; globalQuality != "" {
+		args = append(args, "-global_quality", globalQuality)
+	}
+	if settings["look_ahead"] == "1" {
+		if codec == "h264_qsv" {
+			args = append(args, "-look_ahead", "1", "-look_ahead_depth", "40")
+		} else {
+			// HEVC uses the extended bitrate control for look-ahead
+			args = append(args, "-extbrc", "1", "-look_ahead_depth", "40")
+		}
+	}
+	return args
+}
+
+// amfArgs returns the rate control arguments for an AMF encoder
+func amfArgs(codec string, settings map[string]string) []string {
+	var args []string
+	if quality := settings["amf_quality"]; quality != "" {
+		args = append(args, "-quality", quality)
+	}
+	if settings["rc"] == "cqp" && settings["qp"] != "" {
+		qp := settings["qp"]
+		args = append(args, "-rc", "cqp", "-qp_i", qp, "-qp_p", qp)
+		if codec == "h264_amf" {
+			// B-frames get a slightly higher QP, as with x264
+			bqp := qp
+			if v, err := parseInt(qp); err == nil {
+				bqp = formatInt(v + 2)
+			}
+			args = append(args, "-qp_b", bqp)
+		}
+	} else if rc := settings["rc"]; rc != "" {
+		args = append(args, "-rc", rc)
+	}
+	return args
+}
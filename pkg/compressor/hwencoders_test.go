@@ -0,0 +1,92 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyHardwareEncoderSettingsNVENC(t *testing.T) {
+	settings := map[string]string{
+		"codec":   "hevc_nvenc",
+		"crf":     "28",
+		"preset":  "slow",
+		"bitrate": "4M",
+		"level":   "4.1",
+		"pix_fmt": "yuv420p",
+	}
+	applyHardwareEncoderSettings(settings, 4, "thorough")
+
+	assert.Equal(t, "23", settings["cq"])
+	assert.Equal(t, "p7", settings["preset"])
+	assert.Equal(t, "hq", settings["tune"])
+	assert.Equal(t, "fullres", settings["multipass"])
+	assert.NotContains(t, settings, "crf")
+	assert.NotContains(t, settings, "bitrate")
+	assert.NotContains(t, settings, "level")
+	assert.Equal(t, "yuv420p", settings["pix_fmt"])
+
+	vc := &VideoCompressor{Logger: util.NewLogger(false)}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings)
+	assert.Contains(t, args, "-cq")
+	assert.Contains(t, args, "-multipass")
+	assert.NotContains(t, args, "-crf")
+}
+
+func TestApplyHardwareEncoderSettingsSoftware(t *testing.T) {
+	settings := map[string]string{"codec": "libx264", "crf": "23", "preset": "medium"}
+	applyHardwareEncoderSettings(settings, 3, "balanced")
+
+	assert.Equal(t, map[string]string{"codec": "libx264", "crf": "23", "preset": "medium"}, settings)
+}
+
+func TestSelectHardwareEncoder(t *testing.T) {
+	available := map[string]bool{"libx264": true, "h264_qsv": true, "hevc_qsv": true, "h264_amf": true}
+
+	encoder, ok := selectHardwareEncoder("libx265", "auto", available)
+	assert.True(t, ok)
+	assert.Equal(t, "hevc_qsv", encoder)
+
+	encoder, ok = selectHardwareEncoder("libx264", "amf", available)
+	assert.True(t, ok)
+	assert.Equal(t, "h264_amf", encoder)
+
+	_, ok = selectHardwareEncoder("libx265", "amf", available)
+	assert.False(t, ok)
+
+	_, ok = selectHardwareEncoder("libx264", "nvenc", available)
+	assert.False(t, ok)
+}
+
+func TestApplyHardwareEncoderSettingsQSV(t *testing.T) {
+	settings := map[string]string{"codec": "h264_qsv", "crf": "23", "tune": "film", "pix_fmt": "yuv420p"}
+	applyHardwareEncoderSettings(settings, 3, "balanced")
+
+	assert.Equal(t, "26", settings["global_quality"])
+	assert.Equal(t, "medium", settings["preset"])
+	assert.Equal(t, "nv12", settings["pix_fmt"])
+	assert.NotContains(t, settings, "crf")
+	assert.NotContains(t, settings, "tune")
+
+	assert.Equal(t, []string{"-global_quality", "26", "-look_ahead", "1", "-look_ahead_depth", "40"}, qsvArgs("h264_qsv", settings))
+	assert.Equal(t, []string{"-global_quality", "26", "-extbrc", "1", "-look_ahead_depth", "40"}, qsvArgs("hevc_qsv", settings))
+}
+
+func TestApplyHardwareEncoderSettingsAMF(t *testing.T) {
+	settings := map[string]string{"codec": "h264_amf", "crf": "23", "preset": "slow", "profile": "high"}
+	applyHardwareEncoderSettings(settings, 5, "thorough")
+
+	assert.Equal(t, "cqp", settings["rc"])
+	assert.Equal(t, "20", settings["qp"])
+	assert.NotContains(t, settings, "preset")
+	assert.NotContains(t, settings, "profile")
+
+	assert.Equal(t, []string{"-quality", "quality", "-rc", "cqp", "-qp_i", "20", "-qp_p", "20", "-qp_b", "22"}, amfArgs("h264_amf", settings))
+	assert.Equal(t, []string{"-quality", "quality", "-rc", "cqp", "-qp_i", "20", "-qp_p", "20"}, amfArgs("hevc_amf", settings))
+
+	vc := &VideoCompressor{Logger: util.NewLogger(false)}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings)
+	assert.Contains(t, args, "-qp_i")
+	assert.NotContains(t, args, "-preset")
+}
@@ -0,0 +1,141 @@
+package compressor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Classes of hardware encoder failures, told apart by what FFmpeg printed
+const (
+	HWFailureDriver       = "driver or device unavailable"
+	HWFailureSessionLimit = "encoder session limit reached"
+	HWFailureUnsupported  = "input or setting not supported by the hardware encoder"
+	HWFailureEncoder      = "hardware encoder error"
+)
+
+// hwFailurePatterns maps lowercase FFmpeg messages to the failure class they
+// indicate. NVENC reports its session limit as "out of memory (10)", so the
+// session limit is matched first.
+var hwFailurePatterns = []struct {
+	class    string
+	patterns []string
+}{
+	{HWFailureSessionLimit, []string{
+		"out of memory (10)",
+		"incompatible client key",
+		"concurrent session",
+		"session limit",
+	}},
+	{HWFailureDriver, []string{
+		"cannot load libcuda",
+		"cannot load nvcuda",
+		"cannot load libnvidia-encode",
+		"cannot load nvencodeapi",
+		"driver does not support the required nvenc api version",
+		"minimum required nvidia driver",
+		"no nvenc capable devices found",
+		"unsupported device",
+		"cuinit",
+		"error creating a mfx session",
+		"error initializing an mfx session",
+		"error initializing an internal mfx session",
+		"unsupported hardware",
+		"failed to initialize amf",
+		"dll amfrt",
+		"load library failed",
+		"device creation failed",
+		"no device available",
+	}},
+	{HWFailureUnsupported, []string{
+		"invalid param",
+		"no capable devices found",
+		"doesn't support required nvenc features",
+		"10 bit encode not supported",
+		"not supported by the device",
+		"unsupported pixel format",
+		"invalid level",
+		"width not supported",
+		"height not supported",
+		"exceeds the maximum",
+	}},
+}
+
+// HardwareFallback records a hardware encode that failed and was redone
+// with a software encoder
+type HardwareFallback struct {
+	Encoder  string // Hardware encoder that failed
+	Reason   string // Failure class (one of the HWFailure constants)
+	Software string // Encoder that redid the file
+}
+
+// String describes the fallback for reports
+func (f *HardwareFallback) String() string {
+	return fmt.Sprintf("%s failed (%s), re-encoded with %s", f.Encoder, f.Reason, f.Software)
+}
+
+// classifyHardwareFailure returns the class of a failed encode with the
+// hardware encoder codec, judged from the FFmpeg output. It returns false
+// when nothing points at the encoder, e.g. for an unreadable input that a
+// software encode would fail on as well.
+func classifyHardwareFailure(codec, output string) (string, bool) {
+	lower := strings.ToLower(output)
+	for _, group := range hwFailurePatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(lower, pattern) {
+				return group.class, true
+			}
+		}
+	}
+	// Messages of the encoder itself, or FFmpeg failing to open it
+	if strings.Contains(lower, "["+codec+" @") || strings.Contains(lower, "error while opening encoder") ||
+		strings.Contains(lower, "error initializing output stream") {
+		return HWFailureEncoder, true
+	}
+	return "", false
+}
+
+// retryInSoftware prepares to redo a failed hardware encode with a software
+// encoder and returns its settings; original holds the settings before the
+// switch to the hardware encoder. It returns nil when the failure does not
+// look like a hardware problem or no software encoder is available.
+func (vc *VideoCompressor) retryInSoftware(inputFile, outputFile string, original map[string]string, encoder string,
+	encodeErr error, quality int, preset string) (map[string]string, *HardwareFallback) {
+	reason, ok := classifyHardwareFailure(encoder, ffmpeg.ErrorOutput(encodeErr))
+	if !ok {
+		return nil, nil
+	}
+
+	settings := copySettings(original)
+	// Settings that name a hardware encoder themselves get its software
+	// counterpart
+	if codec := settings["codec"]; isHardwareEncoder(codec) && len(encoderFallbacks[codec]) > 0 {
+		switchEncoder(settings, codec, encoderFallbacks[codec][0])
+	}
+	if err := vc.prepareEncoderSettings(settings, quality, preset, ""); err != nil {
+		vc.Logger.Warning("Hardware encoder %s failed (%s) and no software encoder is available: %v",
+			encoder, i18n.Tr(reason), err)
+		return nil, nil
+	}
+
+	vc.Logger.Warning("Hardware encoder %s failed (%s), retrying %s with %s",
+		encoder, i18n.Tr(reason), filepath.Base(inputFile), settings["codec"])
+	vc.Logger.Debug("Hardware encode error: %v\n%s", encodeErr, ffmpeg.ErrorOutput(encodeErr))
+
+	// Start over from a clean output
+	os.Remove(outputFile)
+	return settings, &HardwareFallback{Encoder: encoder, Reason: reason, Software: settings["codec"]}
+}
+
+// copySettings returns a copy of settings
+func copySettings(settings map[string]string) map[string]string {
+	result := make(map[string]string, len(settings))
+	for key, value := range settings {
+		result[key] = value
+	}
+	return result
+}
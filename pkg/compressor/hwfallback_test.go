@@ -0,0 +1,63 @@
+package compressor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyHardwareFailure(t *testing.T) {
+	tests := []struct {
+		output string
+		class  string
+	}{
+		{"[h264_nvenc @ 0x55] OpenEncodeSessionEx failed: out of memory (10): (no details)", HWFailureSessionLimit},
+		{"[hevc_nvenc @ 0x55] Driver does not support the required nvenc API version. Required: 12.1 Found: 11.1", HWFailureDriver},
+		{"[AVHWDeviceContext @ 0x1] Cannot load libcuda.so.1", HWFailureDriver},
+		{"[h264_qsv @ 0x1] Error creating a MFX session: -9.", HWFailureDriver},
+		{"[hevc_nvenc @ 0x55] 10 bit encode not supported", HWFailureUnsupported},
+		{"[h264_amf @ 0x1] encoder failed\nError while opening encoder for output stream #0:0", HWFailureEncoder},
+	}
+	for _, tt := range tests {
+		class, ok := classifyHardwareFailure("h264_nvenc", tt.output)
+		assert.True(t, ok, tt.output)
+		assert.Equal(t, tt.class, class, tt.output)
+	}
+
+	// Input problems would fail a software encode as well
+	_, ok := classifyHardwareFailure("h264_nvenc", "in.mp4: Invalid data found when processing input")
+	assert.False(t, ok)
+}
+
+func TestRetryInSoftware(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	vc := &VideoCompressor{Logger: logger, ConcurrentWorkers: 4, HardwareEncoder: "nvenc"}
+	output := t.TempDir() + "/out.mp4"
+
+	// The settings from before the switch to NVENC are encoded in software
+	original := map[string]string{"codec": "libx265", "crf": "28", "preset": "medium"}
+	encodeErr := fmt.Errorf("FFmpeg error: exit status 1\nDetails: [hevc_nvenc @ 0x1] OpenEncodeSessionEx failed: out of memory (10)")
+	settings, fallback := vc.retryInSoftware("in.mp4", output, original, "hevc_nvenc", encodeErr, 3, "balanced")
+	assert.Equal(t, "libx265", settings["codec"])
+	assert.Equal(t, "28", settings["crf"])
+	assert.Equal(t, &HardwareFallback{Encoder: "hevc_nvenc", Reason: HWFailureSessionLimit, Software: "libx265"}, fallback)
+	assert.Equal(t, "hevc_nvenc failed (encoder session limit reached), re-encoded with libx265", fallback.String())
+	// The hardware encoder stays configured for the next file
+	assert.Equal(t, "nvenc", vc.HardwareEncoder)
+	// The original settings are left alone
+	assert.Equal(t, "libx265", original["codec"])
+
+	// Settings that name the hardware encoder get its software counterpart
+	settings, fallback = vc.retryInSoftware("in.mp4", output, map[string]string{"codec": "h264_nvenc"}, "h264_nvenc", encodeErr, 3, "balanced")
+	assert.NotNil(t, fallback)
+	assert.Equal(t, "libx264", settings["codec"])
+	assert.Equal(t, "23", settings["crf"])
+
+	// Failures that are not about the encoder are not retried
+	settings, fallback = vc.retryInSoftware("in.mp4", output, original, "hevc_nvenc", fmt.Errorf("in.mp4: No such file or directory"), 3, "balanced")
+	assert.Nil(t, settings)
+	assert.Nil(t, fallback)
+}
@@ -0,0 +1,104 @@
+package compressor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Keyframes is a keyframe interval chosen on the command line, in frames or
+// in seconds of the encoded video. At most one of the two is set.
+type Keyframes struct {
+	Frames  int     // Frames between keyframes
+	Seconds float64 // Seconds between keyframes
+}
+
+// IsSet reports whether an interval was chosen
+func (k Keyframes) IsSet() bool {
+	return k.Frames > 0 || k.Seconds > 0
+}
+
+// Validate checks that the interval is positive and given only one way
+func (k Keyframes) Validate() error {
+	if k.Frames < 0 {
+		return i18n.Errorf("keyint must be 1 frame or more (got %d)", k.Frames)
+	}
+	if k.Seconds < 0 {
+		return i18n.Errorf("GOP seconds must be more than 0 (got %g)", k.Seconds)
+	}
+	if k.Frames > 0 && k.Seconds > 0 {
+		return i18n.Errorf("--keyint and --gop-seconds cannot be used together")
+	}
+	return nil
+}
+
+// ApplyKeyframes replaces the keyframe interval of settings with k. An
+// interval in seconds is turned into frames at the output frame rate, which
+// the fps filter stage may have changed from sourceFPS.
+func ApplyKeyframes(settings map[string]string, k Keyframes, sourceFPS float64) error {
+	if err := k.Validate(); err != nil {
+		return err
+	}
+	if !k.IsSet() {
+		return nil
+	}
+	if settings["codec"] == "copy" {
+		return i18n.Errorf("the keyframe interval cannot be changed with --copy-video")
+	}
+	if k.Frames > 0 {
+		settings["gop"] = strconv.Itoa(k.Frames)
+		return nil
+	}
+	fps := ffmpeg.OutputFPS(settings, sourceFPS)
+	if fps <= 0 {
+		return i18n.Errorf("--gop-seconds needs the frame rate of the video, which is unknown; use --keyint")
+	}
+	settings["gop"] = strconv.Itoa(analyzer.KeyframeInterval(k.Seconds, fps))
+	return nil
+}
+
+// keyframeArgs returns the arguments that set the keyframe interval of the
+// encoder. Most take -g as the longest distance between keyframes; x265
+// takes it in its parameters instead, see x265Params.
+func keyframeArgs(codec, gop string) []string {
+	if gop == "" || codec == "libx265" {
+		return nil
+	}
+	args := []string{"-g", gop}
+	// Make every keyframe, including those forced for HLS segments, an
+	// IDR frame a player can start decoding from
+	if codec == "h264_nvenc" || codec == "hevc_nvenc" {
+		args = append(args, "-forced-idr", "1")
+	}
+	return args
+}
+
+// x265Params returns the -x265-params of settings, with the level, which
+// x265 only takes there. With a keyframe interval the GOPs are closed, as
+// x265 leaves them open by default, so each starts with a frame a player
+// can seek or switch streams to.
+func x265Params(settings map[string]string) string {
+	params := settings["x265-params"]
+	var added []string
+	if gop := settings["gop"]; gop != "" {
+		added = append(added, "keyint="+gop, "open-gop=0")
+	}
+	if level := settings["level"]; level != "" {
+		added = append(added, "level-idc="+level)
+	}
+	if len(added) == 0 {
+		return params
+	}
+	var kept []string
+	for _, param := range strings.Split(params, ":") {
+		name, _, _ := strings.Cut(param, "=")
+		if param == "" || name == "keyint" || name == "open-gop" || name == "no-open-gop" || name == "level-idc" {
+			continue
+		}
+		kept = append(kept, param)
+	}
+	return strings.Join(append(kept, added...), ":")
+}
@@ -0,0 +1,67 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyframesValidate(t *testing.T) {
+	assert.NoError(t, Keyframes{}.Validate())
+	assert.NoError(t, Keyframes{Frames: 48}.Validate())
+	assert.NoError(t, Keyframes{Seconds: 2}.Validate())
+	assert.Error(t, Keyframes{Frames: -1}.Validate())
+	assert.Error(t, Keyframes{Seconds: -2}.Validate())
+	assert.Error(t, Keyframes{Frames: 48, Seconds: 2}.Validate())
+}
+
+func TestApplyKeyframes(t *testing.T) {
+	settings := map[string]string{"codec": "libx264", "gop": "60"}
+	assert.NoError(t, ApplyKeyframes(settings, Keyframes{}, 30))
+	assert.Equal(t, "60", settings["gop"])
+
+	assert.NoError(t, ApplyKeyframes(settings, Keyframes{Frames: 250}, 30))
+	assert.Equal(t, "250", settings["gop"])
+
+	assert.NoError(t, ApplyKeyframes(settings, Keyframes{Seconds: 4}, 29.97))
+	assert.Equal(t, "120", settings["gop"])
+
+	// Seconds are counted at the output frame rate
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, "fps=15"))
+	assert.NoError(t, ApplyKeyframes(settings, Keyframes{Seconds: 4}, 29.97))
+	assert.Equal(t, "60", settings["gop"])
+
+	assert.Error(t, ApplyKeyframes(map[string]string{"codec": "libx264"}, Keyframes{Seconds: 2}, 0))
+	assert.Error(t, ApplyKeyframes(map[string]string{"codec": "copy"}, Keyframes{Frames: 48}, 24))
+}
+
+func TestKeyframeArgs(t *testing.T) {
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "libx264", "gop": "48"})
+	assert.Equal(t, []string{"-g", "48"}, argsAfter(args, "-g", 1))
+
+	args = vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "hevc_nvenc", "cq": "28", "gop": "60"})
+	assert.Equal(t, []string{"-g", "60", "-forced-idr", "1"}, argsAfter(args, "-g", 3))
+
+	args = vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "libvpx-vp9", "crf": "31", "gop": "50"})
+	assert.Equal(t, []string{"-g", "50"}, argsAfter(args, "-g", 1))
+
+	// x265 takes the interval in its parameters, with closed GOPs
+	args = vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "libx265", "gop": "30", "x265-params": "bframes=0:keyint=600"})
+	assert.NotContains(t, args, "-g")
+	assert.Equal(t, []string{"-x265-params", "bframes=0:keyint=30:open-gop=0"}, argsAfter(args, "-x265-params", 1))
+	assert.Equal(t, "keyint=30:open-gop=0", x265Params(map[string]string{"gop": "30"}))
+	assert.Equal(t, "bframes=0", x265Params(map[string]string{"x265-params": "bframes=0"}))
+	assert.Equal(t, "bframes=0:keyint=30:open-gop=0:level-idc=5.1", x265Params(map[string]string{"x265-params": "bframes=0:level-idc=4", "gop": "30", "level": "5.1"}))
+}
+
+// argsAfter returns the argument named name and the n that follow it
+func argsAfter(args []string, name string, n int) []string {
+	for i, arg := range args {
+		if arg == name && i+n < len(args) {
+			return args[i : i+n+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package compressor
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// OutputSize returns the width and height of the encoded frame: the source
+// frame after the crop and scale stages of settings. Scales whose size is
+// worked out by FFmpeg from expressions other than those the tool writes
+// leave the size before them.
+func OutputSize(settings map[string]string, videoFile *ffmpeg.VideoFile) (int, int) {
+	width, height := videoFile.VideoInfo.Width, videoFile.VideoInfo.Height
+	var cropWidth, cropHeight int
+	if _, err := fmt.Sscanf(ffmpeg.VideoFilter(settings, ffmpeg.FilterCrop), "crop=%d:%d", &cropWidth, &cropHeight); err == nil {
+		width, height = cropWidth, cropHeight
+	}
+	scale, ok := strings.CutPrefix(ffmpeg.VideoFilter(settings, ffmpeg.FilterScale), "scale=")
+	if !ok || width <= 0 || height <= 0 {
+		return width, height
+	}
+
+	// Square pixels follow the sample aspect ratio, see squarePixelsFilter
+	scale, _, _ = strings.Cut(scale, ",")
+	widthExpr, heightExpr, _ := strings.Cut(scale, ":")
+	sar := videoFile.VideoInfo.SampleAspectRatio()
+	even := func(n float64) int { return int(math.Floor(n/2)) * 2 }
+	scaledWidth, widthErr := strconv.Atoi(widthExpr)
+	scaledHeight, heightErr := strconv.Atoi(heightExpr)
+	switch {
+	case widthErr == nil && heightErr == nil && scaledWidth > 0 && scaledHeight > 0:
+		return scaledWidth, scaledHeight
+	case heightErr == nil && scaledHeight > 0 && scaledWidth < 0:
+		return even(float64(width*scaledHeight)/float64(height) + 1), scaledHeight
+	case widthErr == nil && scaledWidth > 0 && scaledHeight < 0:
+		return scaledWidth, even(float64(height*scaledWidth)/float64(width) + 1)
+	case widthExpr == "trunc(iw*sar/2)*2" && heightExpr == "ih":
+		return even(float64(width) * sar), height
+	case heightErr == nil && scaledHeight > 0 && strings.Contains(widthExpr, "*dar/2"):
+		return even(float64(scaledHeight) * float64(width) * sar / float64(height)), scaledHeight
+	}
+	return width, height
+}
+
+// ApplyLevel sets the level of settings to the lowest that holds the
+// encoded video: its frame after the filters, its frame rate and, without
+// CRF, its bitrate. Only a level already chosen by the analyzer is changed,
+// encoders that pick their own are left alone. It reports whether the level
+// changed, and fails when the video is beyond every level of the codec,
+// which is then set to the highest.
+func ApplyLevel(settings map[string]string, videoFile *ffmpeg.VideoFile) (bool, error) {
+	if settings["level"] == "" {
+		return false, nil
+	}
+	width, height, fps, bitrate := levelInputs(settings, videoFile)
+	level, ok := analyzer.CodecLevel(settings["codec"], settings["profile"], width, height, fps, bitrate)
+	if level == "" {
+		return false, nil
+	}
+	changed := level != settings["level"]
+	settings["level"] = level
+	if !ok {
+		return changed, i18n.Errorf("%dx%d at %.3g fps is beyond every %s level, signalling level %s", width, height, fps, codecFamily(settings["codec"]), level)
+	}
+	return changed, nil
+}
+
+// levelInputs returns what the level of the encoded video depends on: its
+// frame size, frame rate and the bitrate that drives the encoder, 0 with CRF
+func levelInputs(settings map[string]string, videoFile *ffmpeg.VideoFile) (int, int, float64, int64) {
+	width, height := OutputSize(settings, videoFile)
+	fps := ffmpeg.OutputFPS(settings, videoFile.VideoInfo.FPS)
+	var bitrate int64
+	if settings["crf"] == "" {
+		bitrate = analyzer.ParseBitrate(settings["bitrate"])
+	}
+	return width, height, fps, bitrate
+}
@@ -0,0 +1,74 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputSize(t *testing.T) {
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080}}
+	size := func(stages map[ffmpeg.FilterStage]string) [2]int {
+		settings := map[string]string{}
+		for stage, graph := range stages {
+			assert.NoError(t, ffmpeg.SetVideoFilter(settings, stage, graph))
+		}
+		width, height := OutputSize(settings, video)
+		return [2]int{width, height}
+	}
+
+	assert.Equal(t, [2]int{1920, 1080}, size(nil))
+	assert.Equal(t, [2]int{1280, 720}, size(map[ffmpeg.FilterStage]string{ffmpeg.FilterScale: "scale=-2:720"}))
+	assert.Equal(t, [2]int{640, 480}, size(map[ffmpeg.FilterStage]string{ffmpeg.FilterScale: "scale=640:480"}))
+	assert.Equal(t, [2]int{1440, 1080}, size(map[ffmpeg.FilterStage]string{ffmpeg.FilterCrop: "crop=1440:1080:240:0"}))
+	assert.Equal(t, [2]int{960, 720}, size(map[ffmpeg.FilterStage]string{
+		ffmpeg.FilterCrop:  "crop=1440:1080:240:0",
+		ffmpeg.FilterScale: "scale=-2:720",
+	}))
+
+	// Anamorphic DVD video becomes square pixels at 16:9
+	dvd := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 720, Height: 480, SAR: "32:27"}}
+	settings := map[string]string{}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, squarePixelsFilter("")))
+	width, height := OutputSize(settings, dvd)
+	assert.Equal(t, [2]int{852, 480}, [2]int{width, height})
+}
+
+func TestApplyLevel(t *testing.T) {
+	video := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 3840, Height: 2160, FPS: 60}}
+	settings := map[string]string{"codec": "libx264", "profile": "high", "level": "4.1", "crf": "23"}
+
+	changed, err := ApplyLevel(settings, video)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "5.2", settings["level"])
+
+	// The level follows the frame after the filters
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:720"))
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, "fps=30"))
+	_, err = ApplyLevel(settings, video)
+	assert.NoError(t, err)
+	assert.Equal(t, "3.1", settings["level"])
+
+	// Without CRF the bitrate counts too
+	delete(settings, "crf")
+	settings["bitrate"] = "30M"
+	_, err = ApplyLevel(settings, video)
+	assert.NoError(t, err)
+	assert.Equal(t, "4.1", settings["level"])
+
+	// Encoders that pick their own level are left alone
+	nvenc := map[string]string{"codec": "h264_nvenc", "cq": "23"}
+	changed, err = ApplyLevel(nvenc, video)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.NotContains(t, nvenc, "level")
+
+	// Beyond the highest level
+	huge := &ffmpeg.VideoFile{VideoInfo: ffmpeg.VideoStreamInfo{Width: 7680, Height: 4320, FPS: 240}}
+	settings = map[string]string{"codec": "libx264", "profile": "high", "level": "4.1", "crf": "23"}
+	_, err = ApplyLevel(settings, huge)
+	assert.Error(t, err)
+	assert.Equal(t, "6.2", settings["level"])
+}
@@ -0,0 +1,88 @@
+package compressor
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// MuxerOptions choose how the output file is laid out for playback
+type MuxerOptions struct {
+	NoFaststart bool // Leave the MP4 and MOV index at the end, where it is first written
+	Fragmented  bool // Write MP4 and MOV as fragments that players and servers can stream as they arrive
+}
+
+const (
+	// faststartFlags move the index of MP4 and MOV files to the front once
+	// they are written, so players start before the whole file has arrived
+	faststartFlags = "+faststart"
+
+	// fragmentedFlags start a fragment at each keyframe behind an empty
+	// index, the layout of streaming servers and Media Source Extensions
+	fragmentedFlags = "+frag_keyframe+empty_moov+default_base_moof"
+
+	// cueBytesPerSecond is the room kept at the front of Matroska files for
+	// their index of keyframes, the cues, per second of video. A cue takes
+	// less than 40 bytes and keyframes rarely come more than once a second.
+	cueBytesPerSecond = 64
+
+	// minCueSpace is the least room kept for the cues of Matroska files
+	minCueSpace = 4096
+)
+
+// muxerSettings are the settings ApplyMuxer sets
+var muxerSettings = []string{"movflags", "reserve_index_space"}
+
+// mp4Extensions and matroskaExtensions list the outputs of the MP4 and
+// Matroska muxers
+var (
+	mp4Extensions      = []string{".mp4", ".m4v", ".m4a", ".mov"}
+	matroskaExtensions = []string{".mkv", ".mka", ".webm"}
+)
+
+// ApplyMuxer sets how outputFile is written for playback: MP4 and MOV get
+// their index moved to the front, unless options.NoFaststart is set, or are
+// fragmented with options.Fragmented; Matroska and WebM keep room at the
+// front for their cues, sized for duration seconds of video, so players
+// seek without reading to the end. Fragmenting other containers fails.
+func ApplyMuxer(settings map[string]string, outputFile string, duration float64, options MuxerOptions) error {
+	withoutMuxer(settings)
+	ext := strings.ToLower(filepath.Ext(outputFile))
+	switch {
+	case contains(mp4Extensions, ext) && options.Fragmented:
+		settings["movflags"] = fragmentedFlags
+	case options.Fragmented:
+		return i18n.Errorf("--fragmented needs an MP4 or MOV output (got %s)", filepath.Base(outputFile))
+	case contains(mp4Extensions, ext) && !options.NoFaststart:
+		settings["movflags"] = faststartFlags
+	case contains(matroskaExtensions, ext):
+		space := int(duration) * cueBytesPerSecond
+		if space < minCueSpace {
+			space = minCueSpace
+		}
+		settings["reserve_index_space"] = strconv.Itoa(space)
+	}
+	return nil
+}
+
+// muxerArgs returns the muxer arguments of settings, see ApplyMuxer
+func muxerArgs(settings map[string]string) []string {
+	var args []string
+	if flags := settings["movflags"]; flags != "" {
+		args = append(args, "-movflags", flags)
+	}
+	if space := settings["reserve_index_space"]; space != "" {
+		args = append(args, "-reserve_index_space", space)
+	}
+	return args
+}
+
+// withoutMuxer removes the muxer settings from settings, for temporary
+// files such as segments and probes, which are never played
+func withoutMuxer(settings map[string]string) {
+	for _, key := range muxerSettings {
+		delete(settings, key)
+	}
+}
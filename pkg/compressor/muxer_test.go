@@ -0,0 +1,52 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMuxer(t *testing.T) {
+	settings := map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.MP4", 60, MuxerOptions{}))
+	assert.Equal(t, "+faststart", settings["movflags"])
+
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.mov", 60, MuxerOptions{NoFaststart: true}))
+	assert.NotContains(t, settings, "movflags")
+
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.mp4", 60, MuxerOptions{Fragmented: true}))
+	assert.Equal(t, "+frag_keyframe+empty_moov+default_base_moof", settings["movflags"])
+
+	// Matroska keeps room for its cues, at least 4 KB
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.mkv", 7200, MuxerOptions{}))
+	assert.NotContains(t, settings, "movflags")
+	assert.Equal(t, "460800", settings["reserve_index_space"])
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.webm", 10, MuxerOptions{}))
+	assert.Equal(t, "4096", settings["reserve_index_space"])
+
+	assert.NoError(t, ApplyMuxer(settings, "/out/clip.avi", 60, MuxerOptions{}))
+	assert.NotContains(t, settings, "reserve_index_space")
+
+	assert.Error(t, ApplyMuxer(settings, "/out/clip.mkv", 60, MuxerOptions{Fragmented: true}))
+}
+
+func TestMuxerArgs(t *testing.T) {
+	vc := &VideoCompressor{}
+	settings := map[string]string{"codec": "libx264", "crf": "23"}
+	assert.NoError(t, ApplyMuxer(settings, "out.mp4", 60, MuxerOptions{}))
+	args := vc.BuildFFmpegArgs("in.mkv", "out.mp4", settings)
+	assert.Equal(t, []string{"-movflags", "+faststart"}, argsAfter(args, "-movflags", 1))
+
+	// The merged file of a parallel encode gets them too
+	args = vc.mergeArgs("list.txt", "in.mkv", "out.mp4", settings, nil)
+	assert.Equal(t, []string{"-movflags", "+faststart"}, argsAfter(args, "-movflags", 1))
+
+	assert.NoError(t, ApplyMuxer(settings, "out.mkv", 60, MuxerOptions{}))
+	args = vc.BuildFFmpegArgs("in.mkv", "out.mkv", settings)
+	assert.Equal(t, []string{"-reserve_index_space", "4096"}, argsAfter(args, "-reserve_index_space", 1))
+
+	// Temporary files are left as FFmpeg writes them
+	withoutMuxer(settings)
+	args = vc.BuildFFmpegArgs("in.mkv", "segment.mkv", settings)
+	assert.NotContains(t, args, "-reserve_index_space")
+}
@@ -0,0 +1,243 @@
+package compressor
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Overrides are encoder settings chosen on the command line. They take
+// precedence over the analyzer's settings and the preset's adjustments.
+type Overrides struct {
+	CRF          string // Constant rate factor on the scale of the codec used
+	VideoBitrate string // Video bitrate, e.g. 2500k or 4M
+	AudioBitrate string // Audio bitrate, e.g. 128k
+	PixFmt       string // Pixel format, e.g. yuv420p10le
+	Tune         string // Encoder tuning, e.g. film or animation
+
+	// ExtraArgs are FFmpeg options added after those of the tool, e.g.
+	// -movflags +faststart. Later options win in FFmpeg, so they can also
+	// replace the tool's, such as its -vf.
+	ExtraArgs []string
+}
+
+// overrideFlags names the command-line option of each overridden setting
+var overrideFlags = map[string]string{
+	"crf":           "--crf",
+	"bitrate":       "--video-bitrate",
+	"audio_bitrate": "--audio-bitrate",
+	"pix_fmt":       "--pix-fmt",
+	"tune":          "--tune",
+
+	"extra_ffmpeg_args": "--extra-ffmpeg-args",
+}
+
+// crfRanges holds the valid CRF values of each CRF-based encoder
+var crfRanges = map[string][2]int{
+	"libx264":    {0, 51},
+	"libx265":    {0, 51},
+	"libvpx-vp9": {0, 63},
+	"libsvtav1":  {0, 63},
+	"libaom-av1": {0, 63},
+}
+
+// encoderTunes lists the -tune values of each encoder that has them
+var encoderTunes = map[string][]string{
+	"libx264":    {"film", "animation", "grain", "stillimage", "fastdecode", "zerolatency", "psnr", "ssim"},
+	"libx265":    {"animation", "grain", "fastdecode", "zerolatency", "psnr", "ssim"},
+	"libvpx-vp9": {"psnr", "ssim"},
+}
+
+// encoderPixFmts lists the usual pixel formats of the software encoders.
+// Formats of other encoders are left to FFmpeg to check.
+var encoderPixFmts = map[string][]string{
+	"libx264":    {"yuv420p", "yuvj420p", "yuv422p", "yuvj422p", "yuv444p", "yuvj444p", "nv12", "nv16", "nv21", "yuv420p10le", "yuv422p10le", "yuv444p10le", "gray", "gray10le"},
+	"libx265":    {"yuv420p", "yuvj420p", "yuv422p", "yuvj422p", "yuv444p", "yuvj444p", "gbrp", "yuv420p10le", "yuv422p10le", "yuv444p10le", "gbrp10le", "yuv420p12le", "yuv422p12le", "yuv444p12le", "gbrp12le", "gray", "gray10le", "gray12le"},
+	"libvpx-vp9": {"yuv420p", "yuva420p", "yuv422p", "yuv440p", "yuv444p", "gbrp", "yuv420p10le", "yuv422p10le", "yuv440p10le", "yuv444p10le", "gbrp10le", "yuv420p12le", "yuv422p12le", "yuv440p12le", "yuv444p12le", "gbrp12le"},
+}
+
+// settings returns the overridden settings by name
+func (o Overrides) settings() map[string]string {
+	settings := make(map[string]string)
+	for key, value := range map[string]string{
+		"crf":           o.CRF,
+		"bitrate":       o.VideoBitrate,
+		"audio_bitrate": o.AudioBitrate,
+		"pix_fmt":       o.PixFmt,
+		"tune":          o.Tune,
+	} {
+		if value != "" {
+			settings[key] = value
+		}
+	}
+	return settings
+}
+
+// Names returns the names of the overridden settings in alphabetical order,
+// e.g. "crf" and "tune"
+func (o Overrides) Names() []string {
+	names := make([]string, 0, len(overrideFlags))
+	for key := range o.settings() {
+		names = append(names, key)
+	}
+	if len(o.ExtraArgs) > 0 {
+		names = append(names, "extra_ffmpeg_args")
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OverrideFlag returns the command-line option that overrides setting, or
+// an empty string
+func OverrideFlag(setting string) string {
+	return overrideFlags[setting]
+}
+
+// Validate checks the values that do not depend on the codec
+func (o Overrides) Validate() error {
+	if o.CRF != "" {
+		if crf, err := strconv.Atoi(o.CRF); err != nil || crf < 0 {
+			return i18n.Errorf("crf must be a whole number of 0 or more (got %s)", o.CRF)
+		}
+	}
+	if o.VideoBitrate != "" && analyzer.ParseBitrate(o.VideoBitrate) <= 0 {
+		return i18n.Errorf("video bitrate must be a bitrate such as 4M or 2500k (got %s)", o.VideoBitrate)
+	}
+	if o.AudioBitrate != "" && analyzer.ParseBitrate(o.AudioBitrate) <= 0 {
+		return i18n.Errorf("audio bitrate must be a bitrate such as 128k (got %s)", o.AudioBitrate)
+	}
+	return ValidateExtraArgs(o.ExtraArgs)
+}
+
+// forbiddenExtraArgs are the FFmpeg options the tool sets itself and relies
+// on: the input, overwriting the output, the standard input and the log
+// and progress output it reads, and the two-pass log
+var forbiddenExtraArgs = []string{
+	"-i", "-y", "-n", "-stdin", "-nostdin",
+	"-progress", "-stats", "-nostats", "-stats_period",
+	"-loglevel", "-v", "-hide_banner", "-report",
+	"-pass", "-passlogfile",
+}
+
+// booleanExtraArgs are FFmpeg options that take no value. Every other
+// option is followed by one.
+var booleanExtraArgs = []string{
+	"-an", "-vn", "-sn", "-dn", "-shortest", "-copyts", "-start_at_zero",
+	"-accurate_seek", "-noaccurate_seek", "-autorotate", "-noautorotate",
+	"-autoscale", "-noautoscale", "-ignore_unknown", "-fix_sub_duration",
+	"-bitexact", "-xerror", "-vstats", "-benchmark", "-benchmark_all",
+}
+
+// ValidateExtraArgs checks arguments meant to be added to the FFmpeg
+// command. They must be options, each followed by its value, so no input
+// or output file can be slipped in, and none may be an option the tool
+// sets itself, such as -i, -y or -progress.
+func ValidateExtraArgs(args []string) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			return i18n.Errorf("extra FFmpeg argument %q is not an option; input and output files cannot be added", arg)
+		}
+		// Stream specifiers select streams, -pass:v is still -pass
+		name, _, _ := strings.Cut(arg, ":")
+		if contains(forbiddenExtraArgs, name) {
+			return i18n.Errorf("extra FFmpeg option %s is set by the tool and cannot be passed through", name)
+		}
+		if contains(booleanExtraArgs, name) {
+			continue
+		}
+		if i+1 == len(args) {
+			return i18n.Errorf("extra FFmpeg option %s needs a value", arg)
+		}
+		// The value is taken as is, so negative numbers such as -g -1 pass
+		i++
+	}
+	return nil
+}
+
+// ApplyOverrides replaces the analyzer's settings with the overrides. It
+// fails when an override does not suit the codec of the settings, such as a
+// CRF out of the encoder's range or a tuning it does not have.
+func ApplyOverrides(settings map[string]string, o Overrides) error {
+	if err := o.Validate(); err != nil {
+		return err
+	}
+	codec := settings["codec"]
+
+	// A copied video stream is not encoded
+	if codec == "copy" {
+		overridden := o.settings()
+		for _, key := range []string{"crf", "bitrate", "pix_fmt", "tune"} {
+			if overridden[key] != "" {
+				return i18n.Errorf("%s cannot be used with --copy-video", overrideFlags[key])
+			}
+		}
+	}
+
+	if o.CRF != "" {
+		limits, ok := crfRanges[codec]
+		if !ok {
+			return i18n.Errorf("--crf is not supported by %s", codec)
+		}
+		if crf, _ := strconv.Atoi(o.CRF); crf < limits[0] || crf > limits[1] {
+			return i18n.Errorf("crf for %s must be between %d-%d (got %s)", codec, limits[0], limits[1], o.CRF)
+		}
+		settings["crf"] = o.CRF
+	}
+
+	if o.VideoBitrate != "" {
+		settings["bitrate"] = o.VideoBitrate
+	}
+
+	if o.PixFmt != "" {
+		if formats, ok := encoderPixFmts[codec]; ok && !contains(formats, o.PixFmt) {
+			return i18n.Errorf("pixel format %s is not supported by %s (use one of: %s)", o.PixFmt, codec, strings.Join(formats, ", "))
+		}
+		// The analyzer's profile was chosen for its own format; the encoder
+		// picks one that fits the new format, e.g. main10 for 10-bit HEVC
+		if settings["pix_fmt"] != o.PixFmt {
+			delete(settings, "profile")
+		}
+		settings["pix_fmt"] = o.PixFmt
+	}
+
+	if o.Tune != "" {
+		tunes, ok := encoderTunes[codec]
+		if !ok {
+			return i18n.Errorf("--tune is not supported by %s", codec)
+		}
+		if !contains(tunes, o.Tune) {
+			return i18n.Errorf("tune %s is not supported by %s (use one of: %s)", o.Tune, codec, strings.Join(tunes, ", "))
+		}
+		settings["tune"] = o.Tune
+	}
+
+	if o.AudioBitrate != "" {
+		if settings["no_audio"] == "1" {
+			return i18n.Errorf("--audio-bitrate cannot be used with --no-audio")
+		}
+		// Copied audio keeps its bitrate, so it is re-encoded to AAC
+		if settings["audio_codec"] == "copy" {
+			settings["audio_codec"] = "aac"
+		}
+		// Files without audio have nothing to set
+		if settings["audio_codec"] != "" {
+			settings["audio_bitrate"] = o.AudioBitrate
+		}
+	}
+
+	return nil
+}
+
+// contains reports whether values holds value
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
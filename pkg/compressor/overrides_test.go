@@ -0,0 +1,86 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverridesValidate(t *testing.T) {
+	assert.NoError(t, Overrides{}.Validate())
+	assert.NoError(t, Overrides{CRF: "20", VideoBitrate: "4M", AudioBitrate: "96k"}.Validate())
+	for _, o := range []Overrides{{CRF: "-1"}, {CRF: "20.5"}, {VideoBitrate: "fast"}, {VideoBitrate: "0"}, {AudioBitrate: "k"}} {
+		assert.Error(t, o.Validate(), "%+v", o)
+	}
+}
+
+func TestApplyOverrides(t *testing.T) {
+	settings := map[string]string{"codec": "libx265", "crf": "28", "profile": "main", "pix_fmt": "yuv420p",
+		"tune": "zerolatency", "bitrate": "3000k", "audio_codec": "copy"}
+	o := Overrides{CRF: "22", VideoBitrate: "2500k", AudioBitrate: "128k", PixFmt: "yuv420p10le", Tune: "grain"}
+	assert.NoError(t, ApplyOverrides(settings, o))
+	assert.Equal(t, map[string]string{"codec": "libx265", "crf": "22", "pix_fmt": "yuv420p10le", "tune": "grain",
+		"bitrate": "2500k", "audio_codec": "aac", "audio_bitrate": "128k"}, settings)
+	assert.Equal(t, []string{"audio_bitrate", "bitrate", "crf", "pix_fmt", "tune"}, o.Names())
+
+	// Values are checked against the codec
+	assert.EqualError(t, ApplyOverrides(map[string]string{"codec": "libx264"}, Overrides{CRF: "60"}),
+		"crf for libx264 must be between 0-51 (got 60)")
+	assert.NoError(t, ApplyOverrides(map[string]string{"codec": "libvpx-vp9"}, Overrides{CRF: "60"}))
+	assert.Error(t, ApplyOverrides(map[string]string{"codec": "libvpx-vp9"}, Overrides{Tune: "film"}))
+	assert.Error(t, ApplyOverrides(map[string]string{"codec": "libx264"}, Overrides{PixFmt: "yuv420p12le"}))
+	assert.Error(t, ApplyOverrides(map[string]string{"codec": "h264_nvenc"}, Overrides{CRF: "20"}))
+	assert.EqualError(t, ApplyOverrides(map[string]string{"codec": "copy", "audio_codec": "aac"}, Overrides{Tune: "film"}),
+		"--tune cannot be used with --copy-video")
+
+	// Audio bitrates need audio
+	assert.Error(t, ApplyOverrides(map[string]string{"codec": "libx264", "no_audio": "1"}, Overrides{AudioBitrate: "128k"}))
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ApplyOverrides(settings, Overrides{AudioBitrate: "128k"}))
+	assert.NotContains(t, settings, "audio_bitrate")
+}
+
+// TestOverridesWinOverPreset tests that the preset does not change a CRF
+// given on the command line
+func TestOverridesWinOverPreset(t *testing.T) {
+	vc := &VideoCompressor{ConcurrentWorkers: 4}
+	settings := map[string]string{"codec": "libx264", "crf": "26", "preset": "medium"}
+	assert.NoError(t, vc.prepareSettings(settings, 3, "thorough"))
+	assert.Equal(t, "24", settings["crf"])
+
+	vc.Overrides = Overrides{CRF: "26"}
+	settings = map[string]string{"codec": "libx264", "crf": "26", "preset": "medium"}
+	assert.NoError(t, vc.prepareSettings(settings, 3, "thorough"))
+	assert.Equal(t, "26", settings["crf"])
+	assert.Equal(t, "slow", settings["preset"])
+}
+
+func TestValidateExtraArgs(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"-movflags", "+faststart"},
+		{"-vf", "hqdn3d", "-an", "-g", "-1"},
+		{"-metadata:s:a:0", "language=por", "-shortest"},
+	} {
+		assert.NoError(t, ValidateExtraArgs(args), "%q", args)
+	}
+
+	assert.EqualError(t, ValidateExtraArgs([]string{"-y"}), "extra FFmpeg option -y is set by the tool and cannot be passed through")
+	assert.Error(t, ValidateExtraArgs([]string{"-i", "other.mp4"}))
+	assert.Error(t, ValidateExtraArgs([]string{"-pass:v", "1"}))
+	assert.Error(t, ValidateExtraArgs([]string{"-progress", "pipe:1"}))
+	// A value left over would be read as another output file
+	assert.EqualError(t, ValidateExtraArgs([]string{"-an", "copy.mp4"}), `extra FFmpeg argument "copy.mp4" is not an option; input and output files cannot be added`)
+	assert.Error(t, ValidateExtraArgs([]string{"-bf"}))
+	assert.Error(t, Overrides{ExtraArgs: []string{"-n"}}.Validate())
+}
+
+// TestExtraArgsFollowSettings tests that extra arguments come after the
+// tool's and before the output file, so they take precedence
+func TestExtraArgsFollowSettings(t *testing.T) {
+	vc := &VideoCompressor{Overrides: Overrides{ExtraArgs: []string{"-vf", "hqdn3d", "-movflags", "+faststart"}}}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", map[string]string{"codec": "libx264", "crf": "23", "video_filter": "scale=1280:-2"})
+	assert.Equal(t, []string{"-vf", "hqdn3d", "-movflags", "+faststart", "out.mp4"}, args[len(args)-5:])
+	assert.Contains(t, args, "scale=1280:-2")
+	assert.Equal(t, []string{"extra_ffmpeg_args"}, vc.Overrides.Names())
+}
@@ -0,0 +1,140 @@
+package compressor
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Parallel modes accepted by --parallel
+const (
+	ParallelAuto = "auto" // Split when the encoder and machine gain from it
+	ParallelOn   = "on"   // Always split
+	ParallelOff  = "off"  // Always encode in one pass
+)
+
+const (
+	// minParallelDuration is the shortest video worth splitting, in seconds:
+	// below it the split and merge cost more than the segments save
+	minParallelDuration = 60
+	// minParallelCPUs is the fewest cores parallel segments gain from. One
+	// x264 or x265 process already keeps a few cores busy.
+	minParallelCPUs = 4
+	// maxSplitBitrate is the source bitrate above which splitting is left
+	// out. Such sources are camera or editing intermediates, and copying
+	// them into segments costs about as much disk time as it saves.
+	maxSplitBitrate = 100_000_000
+	// segmentMemoryShare is the part of the RAM the concurrent segments may
+	// use, leaving the rest to the system and other programs
+	segmentMemoryShare = 0.5
+	// segmentBaseMemory is the memory of an FFmpeg process and its decoder
+	// before the encoder holds any frames
+	segmentBaseMemory = 256 << 20
+	// maxSegmentBytes is the most source data one segment should hold, so
+	// the split segments waiting on disk stay small next to the source
+	maxSegmentBytes = 1 << 30
+	// maxSegmentCount caps the segments of very large sources: each one
+	// adds a progress bar and a keyframe at its start
+	maxSegmentCount = 32
+)
+
+// ValidParallel reports whether mode is a supported --parallel value
+func ValidParallel(mode string) bool {
+	switch mode {
+	case "", ParallelAuto, ParallelOn, ParallelOff:
+		return true
+	}
+	return false
+}
+
+// parallelPlan decides whether a video is encoded in parallel segments and
+// returns how many, or 0 and the reason to encode it in one pass. With
+// --parallel auto the video is split only when the encoder and the machine
+// gain from it; --parallel on and --segments split it unless it cannot be.
+func (vc *VideoCompressor) parallelPlan(analysis *analyzer.VideoAnalysis, settings map[string]string) (int, string) {
+	// A copied video stream gains nothing from segmenting, and segments past
+	// the readable end of a damaged input would come out empty
+	if settings["codec"] == "copy" {
+		return 0, i18n.T("the video stream is copied")
+	}
+	if vc.IgnoreErrors {
+		return 0, i18n.T("damaged inputs are encoded in one pass")
+	}
+	// Subtitles are timed to the whole video, and segments start at zero
+	if ffmpeg.VideoFilter(settings, ffmpeg.FilterSubtitles) != "" {
+		return 0, i18n.T("burned-in subtitles are timed to the whole video")
+	}
+	if copiesHDR10Plus(settings) {
+		return 0, i18n.T("HDR10+ metadata is numbered from the first frame")
+	}
+	if vc.Parallel == ParallelOff {
+		return 0, i18n.T("--parallel off")
+	}
+	if analysis == nil || analysis.VideoFile == nil || analysis.VideoFile.Duration <= 0 {
+		return 0, i18n.T("the duration of the video is unknown")
+	}
+	if vc.Parallel == ParallelOn || vc.Segments > 0 {
+		return vc.parallelSegments(), ""
+	}
+
+	video := analysis.VideoFile
+	switch {
+	case video.Duration <= minParallelDuration:
+		return 0, i18n.T("the video is shorter than %d seconds", minParallelDuration)
+	case analysis.ContentType == analyzer.ContentTypeScreencast:
+		return 0, i18n.T("screencasts encode quickly in one pass")
+	case isHardwareEncoder(settings["codec"]):
+		// GPUs run few encode sessions and each is already fast, so
+		// concurrent segments mostly wait on one another
+		return 0, i18n.T("%s is a hardware encoder", settings["codec"])
+	case vc.ConcurrentWorkers < minParallelCPUs:
+		return 0, i18n.T("%d CPU cores are kept busy by one encoder", vc.ConcurrentWorkers)
+	case video.BitRate > maxSplitBitrate:
+		return 0, i18n.T("the %.0f Mbps source would cost more to split than it saves", float64(video.BitRate)/1e6)
+	}
+
+	segments := vc.parallelSegments()
+	if vc.Memory > 0 {
+		perSegment := segmentMemory(settings["codec"], video.VideoInfo.Width, video.VideoInfo.Height)
+		if fit := int(float64(vc.Memory) * segmentMemoryShare / float64(perSegment)); fit < segments {
+			segments = fit
+		}
+	}
+	if segments < 2 {
+		return 0, i18n.T("there is not enough memory for two segments at once")
+	}
+	return segments, ""
+}
+
+// segmentMemory estimates the memory of one segment's encoder: the 4:2:0
+// frames it holds for lookahead and as references, more for the slower
+// encoders, plus the FFmpeg process itself
+func segmentMemory(codec string, width, height int) uint64 {
+	frames := uint64(60)
+	if codec == "libx265" || codec == "libvpx-vp9" {
+		frames = 100
+	}
+	frameSize := uint64(width) * uint64(height) * 3 / 2
+	return frameSize*frames + segmentBaseMemory
+}
+
+// segmentCount returns how many segments a source of size bytes is split
+// into when workers of them are encoded at once: one per worker, or more
+// for large sources so each holds at most maxSegmentBytes
+func segmentCount(size int64, workers int) int {
+	count := int((size + maxSegmentBytes - 1) / maxSegmentBytes)
+	if count > maxSegmentCount {
+		count = maxSegmentCount
+	}
+	if count < workers {
+		count = workers
+	}
+	return count
+}
+
+// storedSegments returns how many split segments may be on disk at once
+// with workers encoding them: one per worker and the next one, split while
+// they encode
+func storedSegments(workers int) int {
+	return workers + 1
+}
@@ -0,0 +1,177 @@
+package compressor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidParallel(t *testing.T) {
+	for _, mode := range []string{"", ParallelAuto, ParallelOn, ParallelOff} {
+		assert.True(t, ValidParallel(mode), mode)
+	}
+	assert.False(t, ValidParallel("yes"))
+}
+
+func TestParallelPlan(t *testing.T) {
+	analysis := func(duration float64, bitrate int64) *analyzer.VideoAnalysis {
+		return &analyzer.VideoAnalysis{
+			ContentType: analyzer.ContentTypeLiveAction,
+			VideoFile: &ffmpeg.VideoFile{Duration: duration, BitRate: bitrate,
+				VideoInfo: ffmpeg.VideoStreamInfo{Width: 1920, Height: 1080}},
+		}
+	}
+	x264 := map[string]string{"codec": "libx264"}
+
+	// A long software encode on a large machine is split, at most 8 ways
+	vc := &VideoCompressor{ConcurrentWorkers: 16, Memory: 32 << 30}
+	segments, _ := vc.parallelPlan(analysis(600, 8_000_000), x264)
+	assert.Equal(t, 8, segments)
+
+	// Short videos, screencasts, GPU encoders, few cores and huge bitrates
+	// are encoded in one pass
+	for name, plan := range map[string]func() (int, string){
+		"short": func() (int, string) { return vc.parallelPlan(analysis(45, 8_000_000), x264) },
+		"nvenc": func() (int, string) {
+			return vc.parallelPlan(analysis(600, 8_000_000), map[string]string{"codec": "hevc_nvenc"})
+		},
+		"copy": func() (int, string) {
+			return vc.parallelPlan(analysis(600, 8_000_000), map[string]string{"codec": "copy"})
+		},
+		"bitrate":  func() (int, string) { return vc.parallelPlan(analysis(600, 400_000_000), x264) },
+		"no video": func() (int, string) { return vc.parallelPlan(&analyzer.VideoAnalysis{}, x264) },
+		"screencast": func() (int, string) {
+			screencast := analysis(600, 8_000_000)
+			screencast.ContentType = analyzer.ContentTypeScreencast
+			return vc.parallelPlan(screencast, x264)
+		},
+		"two cores": func() (int, string) {
+			return (&VideoCompressor{ConcurrentWorkers: 2}).parallelPlan(analysis(600, 8_000_000), x264)
+		},
+	} {
+		segments, reason := plan()
+		assert.Equal(t, 0, segments, name)
+		assert.NotEmpty(t, reason, name)
+	}
+
+	// RAM caps the segments: a 4K x265 segment needs about 1.5 GB
+	uhd := analysis(600, 40_000_000)
+	uhd.VideoFile.VideoInfo = ffmpeg.VideoStreamInfo{Width: 3840, Height: 2160}
+	vc = &VideoCompressor{ConcurrentWorkers: 16, Memory: 8 << 30}
+	segments, _ = vc.parallelPlan(uhd, map[string]string{"codec": "libx265"})
+	assert.Equal(t, 2, segments)
+	vc.Memory = 2 << 30
+	segments, _ = vc.parallelPlan(uhd, map[string]string{"codec": "libx265"})
+	assert.Equal(t, 0, segments)
+
+	// --parallel on and --segments split whatever auto would decide
+	vc = &VideoCompressor{ConcurrentWorkers: 2, Parallel: ParallelOn}
+	segments, _ = vc.parallelPlan(analysis(45, 8_000_000), map[string]string{"codec": "h264_nvenc"})
+	assert.Equal(t, 2, segments)
+	vc = &VideoCompressor{ConcurrentWorkers: 2, Segments: 6}
+	segments, _ = vc.parallelPlan(analysis(45, 8_000_000), x264)
+	assert.Equal(t, 6, segments)
+
+	// but not copied video or --parallel off
+	segments, _ = vc.parallelPlan(analysis(600, 8_000_000), map[string]string{"codec": "copy"})
+	assert.Equal(t, 0, segments)
+	vc = &VideoCompressor{ConcurrentWorkers: 16, Parallel: ParallelOff}
+	segments, _ = vc.parallelPlan(analysis(600, 8_000_000), x264)
+	assert.Equal(t, 0, segments)
+
+	// Burned-in subtitles are timed to the whole video
+	subtitles := map[string]string{"codec": "libx264"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(subtitles, ffmpeg.FilterSubtitles, "subtitles=filename=clip.srt"))
+	vc = &VideoCompressor{ConcurrentWorkers: 16, Segments: 6}
+	segments, reason := vc.parallelPlan(analysis(600, 8_000_000), subtitles)
+	assert.Equal(t, 0, segments)
+	assert.Equal(t, "burned-in subtitles are timed to the whole video", reason)
+
+	// and so is HDR10+ metadata
+	segments, _ = vc.parallelPlan(analysis(600, 8_000_000), map[string]string{"codec": "libx265", "x265-params": "dhdr10-info=/tmp/hdr10plus.json"})
+	assert.Equal(t, 0, segments)
+}
+
+func TestSegmentCount(t *testing.T) {
+	assert.Equal(t, 4, segmentCount(0, 4))
+	assert.Equal(t, 4, segmentCount(2<<30, 4))
+	assert.Equal(t, 10, segmentCount(10<<30-1, 4))
+	assert.Equal(t, maxSegmentCount, segmentCount(500<<30, 4))
+	assert.Equal(t, 3, storedSegments(2))
+}
+
+// pipelineRunner stands in for FFmpeg in parallel mode: ffprobe reports a
+// two-minute 5 GB video, and ffmpeg writes its output file, unless it is
+// failOutput, and records the most split segments on disk at once
+type pipelineRunner struct {
+	mu         sync.Mutex
+	dir        string
+	failOutput string
+	maxStored  int
+	merges     [][]string
+}
+
+func (r *pipelineRunner) EncodePath() (string, error) { return "ffmpeg", nil }
+func (r *pipelineRunner) ProbePath() (string, error)  { return "ffprobe", nil }
+
+func (r *pipelineRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	if path == "ffprobe" {
+		_, err := io.WriteString(stdout, `{"format":{"duration":"120.0","size":"5368709120"},"streams":[{"codec_type":"video","codec_name":"h264","width":1920,"height":1080}]}`)
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if args[0] == "-f" {
+		r.merges = append(r.merges, args)
+	}
+	if r.failOutput != "" && filepath.Base(args[len(args)-1]) == r.failOutput {
+		return fmt.Errorf("exit status 1")
+	}
+	if err := os.WriteFile(args[len(args)-1], []byte("video"), 0644); err != nil {
+		return err
+	}
+	stored, _ := filepath.Glob(filepath.Join(r.dir, "segments", "segment_*.mp4"))
+	if len(stored) > r.maxStored {
+		r.maxStored = len(stored)
+	}
+	return nil
+}
+
+func TestCompressVideoParallelPipeline(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+	dir := t.TempDir()
+	runner := &pipelineRunner{dir: dir}
+	ff := ffmpeg.NewFFmpeg("in.mp4", "out.mp4", nil, logger)
+	ff.Runner = runner
+	vc := NewVideoCompressor(ff, nil, logger)
+	vc.TempDir = dir
+
+	// Five 1 GB segments are encoded two at a time, and no more than one
+	// split segment waits beside those being encoded
+	output := filepath.Join(dir, "out.mp4")
+	settings := map[string]string{"codec": "libx264", "crf": "23", "audio_codec": "copy"}
+	progress := util.NewProgressTracker(100, "Compressing", logger)
+	assert.NoError(t, vc.compressVideoParallel("in.mp4", output, settings, 2, progress))
+	assert.True(t, runner.maxStored > 0 && runner.maxStored <= storedSegments(2), "%d split segments on disk", runner.maxStored)
+	assert.Len(t, runner.merges, 1)
+	assert.FileExists(t, output)
+
+	// A failed segment stops the pipeline and nothing is merged
+	runner = &pipelineRunner{dir: dir, failOutput: "out_0001.mp4"}
+	ff.Runner = runner
+	err := vc.compressVideoParallel("in.mp4", output, settings, 2, util.NewProgressTracker(100, "Compressing", logger))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "segment 1")
+	assert.Empty(t, runner.merges)
+}
@@ -0,0 +1,90 @@
+package compressor
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+var (
+	progressTimeRegex    = regexp.MustCompile(`time=\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+	progressSpeedRegex   = regexp.MustCompile(`speed=\s*(\d+(?:\.\d+)?)x`)
+	progressFPSRegex     = regexp.MustCompile(`fps=\s*(\d+(?:\.\d+)?)`)
+	progressQRegex       = regexp.MustCompile(`(?:^|\s)q=\s*(-?\d+(?:\.\d+)?)`)
+	progressBitrateRegex = regexp.MustCompile(`bitrate=\s*(\d+(?:\.\d+)?)kbits/s`)
+)
+
+// ffmpegProgress holds the fields of an FFmpeg status line
+type ffmpegProgress struct {
+	OutTime float64 // Position of the encoded output in seconds
+	Speed   float64 // Encode speed relative to real time (0 when unknown)
+	FPS     float64 // Frames encoded per second (0 when unknown)
+	Q       float64 // Quantizer of the latest frame (0 when unknown)
+	Bitrate float64 // Average bitrate of the output so far in kbit/s (0 when unknown)
+}
+
+// parseFFmpegProgress extracts the latest time=, speed=, fps=, q= and bitrate=
+// values from a chunk of FFmpeg stderr output. It returns false when no time=
+// is present.
+func parseFFmpegProgress(output string) (ffmpegProgress, bool) {
+	var p ffmpegProgress
+
+	times := progressTimeRegex.FindAllStringSubmatch(output, -1)
+	if len(times) == 0 {
+		return p, false
+	}
+	last := times[len(times)-1]
+	hours, _ := strconv.Atoi(last[1])
+	minutes, _ := strconv.Atoi(last[2])
+	seconds, _ := strconv.ParseFloat(last[3], 64)
+	p.OutTime = float64(hours*3600) + float64(minutes*60) + seconds
+
+	if speeds := progressSpeedRegex.FindAllStringSubmatch(output, -1); len(speeds) > 0 {
+		p.Speed, _ = strconv.ParseFloat(speeds[len(speeds)-1][1], 64)
+	}
+	if fps := progressFPSRegex.FindAllStringSubmatch(output, -1); len(fps) > 0 {
+		p.FPS, _ = strconv.ParseFloat(fps[len(fps)-1][1], 64)
+	}
+	if q := progressQRegex.FindAllStringSubmatch(output, -1); len(q) > 0 {
+		p.Q, _ = strconv.ParseFloat(q[len(q)-1][1], 64)
+	}
+	if bitrates := progressBitrateRegex.FindAllStringSubmatch(output, -1); len(bitrates) > 0 {
+		p.Bitrate, _ = strconv.ParseFloat(bitrates[len(bitrates)-1][1], 64)
+	}
+
+	return p, true
+}
+
+// percent returns how much of totalDuration has been encoded, capped at 100
+func (p ffmpegProgress) percent(totalDuration float64) int64 {
+	if totalDuration <= 0 {
+		return 0
+	}
+	percent := int64(p.OutTime / totalDuration * 100)
+	if percent > 100 {
+		percent = 100
+	}
+	return percent
+}
+
+// remaining returns the time left to encode totalDuration at the current speed
+func (p ffmpegProgress) remaining(totalDuration float64) time.Duration {
+	if p.Speed <= 0 || p.OutTime >= totalDuration {
+		return 0
+	}
+	seconds := (totalDuration - p.OutTime) / p.Speed
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// stats converts the status line into the figures shown by the progress bar
+func (p ffmpegProgress) stats(totalDuration float64) util.EncodeStats {
+	return util.EncodeStats{
+		FPS:       p.FPS,
+		Speed:     p.Speed,
+		Bitrate:   p.Bitrate,
+		Q:         p.Q,
+		Remaining: p.remaining(totalDuration),
+	}
+}
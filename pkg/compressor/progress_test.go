@@ -0,0 +1,43 @@
+package compressor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseFFmpegProgress tests reading FFmpeg status lines
+func TestParseFFmpegProgress(t *testing.T) {
+	output := "frame=  120 fps= 24 q=28.0 size=     512kB time=00:00:04.00 bitrate=1048.6kbits/s speed=0.8x    \r" +
+		"frame=  240 fps= 25 q=28.0 size=    1024kB time=00:01:30.50 bitrate=1048.6kbits/s speed=1.7x    \r"
+
+	p, ok := parseFFmpegProgress(output)
+	assert.True(t, ok)
+	assert.Equal(t, 90.5, p.OutTime)
+	assert.Equal(t, 1.7, p.Speed)
+	assert.Equal(t, 25.0, p.FPS)
+	assert.Equal(t, 28.0, p.Q)
+	assert.Equal(t, 1048.6, p.Bitrate)
+
+	// speed=N/A at the very start leaves the speed unknown
+	p, ok = parseFFmpegProgress("frame=    0 fps=0.0 q=0.0 size=       0kB time=00:00:00.00 bitrate=N/A speed=N/A")
+	assert.True(t, ok)
+	assert.Equal(t, 0.0, p.Speed)
+	assert.Equal(t, 0.0, p.Bitrate)
+	assert.Equal(t, time.Duration(0), p.remaining(60))
+
+	_, ok = parseFFmpegProgress("Stream mapping:")
+	assert.False(t, ok)
+}
+
+// TestFFmpegProgressRemaining tests the speed-based ETA
+func TestFFmpegProgressRemaining(t *testing.T) {
+	p := ffmpegProgress{OutTime: 30, Speed: 2}
+	assert.Equal(t, 45*time.Second, p.remaining(120))
+	assert.Equal(t, int64(25), p.percent(120))
+
+	p.OutTime = 150
+	assert.Equal(t, time.Duration(0), p.remaining(120))
+	assert.Equal(t, int64(100), p.percent(120))
+}
@@ -0,0 +1,186 @@
+package compressor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// RemuxContainers lists the containers streams can be copied into with
+// --remux-only
+var RemuxContainers = []string{"mp4", "mov", "mkv", "webm"}
+
+// remuxCodecs holds the video and audio codecs each container holds, as
+// ffprobe names them. Matroska holds any.
+var remuxCodecs = map[string][]string{
+	"mp4":  {"h264", "hevc", "av1", "vp9", "mpeg4", "mpeg2video", "aac", "mp3", "mp2", "ac3", "eac3", "opus", "flac", "alac"},
+	"mov":  {"h264", "hevc", "av1", "prores", "dnxhd", "mpeg4", "mpeg2video", "mjpeg", "aac", "mp3", "ac3", "eac3", "alac"},
+	"webm": {"vp8", "vp9", "av1", "opus", "vorbis"},
+}
+
+// remuxSubtitleCodecs holds the codec text subtitles are converted to in
+// each container that does not take them as they are. Image subtitles
+// only fit in Matroska, which takes every text format but that of MP4.
+var remuxSubtitleCodecs = map[string]string{
+	"mp4":  "mov_text",
+	"mov":  "mov_text",
+	"webm": "webvtt",
+}
+
+// staleTags are the metadata tags that describe the file the streams come
+// from, such as the program that wrote it and the statistics of its
+// streams, rather than their content. They would be wrong in the new file.
+var (
+	staleTags       = []string{"encoder", "major_brand", "minor_version", "compatible_brands"}
+	staleStreamTags = []string{"BPS", "DURATION", "NUMBER_OF_FRAMES", "NUMBER_OF_BYTES",
+		"_STATISTICS_WRITING_APP", "_STATISTICS_WRITING_DATE_UTC", "_STATISTICS_TAGS"}
+)
+
+// ValidRemuxContainer reports whether streams can be copied into container
+// with --remux-only
+func ValidRemuxContainer(container string) bool {
+	return contains(RemuxContainers, container)
+}
+
+// CheckRemux fails when the video or audio of videoFile cannot be copied
+// into container unchanged
+func CheckRemux(videoFile *ffmpeg.VideoFile, container string) error {
+	codecs, limited := remuxCodecs[container]
+	if !limited {
+		return nil
+	}
+	var unfit []string
+	if codec := videoFile.VideoInfo.Codec; codec != "" && !contains(codecs, codec) {
+		unfit = append(unfit, codec)
+	}
+	for _, stream := range videoFile.AudioInfo {
+		if stream.Codec != "" && !contains(codecs, stream.Codec) && !contains(unfit, stream.Codec) {
+			unfit = append(unfit, stream.Codec)
+		}
+	}
+	if len(unfit) > 0 {
+		return i18n.Errorf("%s files cannot hold %s without re-encoding (remux to mkv, or compress instead)", container, strings.Join(unfit, ", "))
+	}
+	return nil
+}
+
+// Remux copies the streams of inputFile, probed as videoFile, unchanged
+// into outputFile, whose extension names the container: video, audio,
+// subtitles, chapters, fonts and metadata, leaving out the tags that only
+// described the old file. Text subtitles are converted to the format of
+// containers that need one, and image subtitles are left out of those
+// that cannot hold them. The output is laid out for playback as options
+// ask, see ApplyMuxer.
+func (vc *VideoCompressor) Remux(inputFile, outputFile string, videoFile *ffmpeg.VideoFile, options MuxerOptions) (*CompressionResult, error) {
+	container := remuxContainer(outputFile)
+	if err := CheckRemux(videoFile, container); err != nil {
+		return nil, err
+	}
+	settings := map[string]string{"codec": "copy"}
+	if len(videoFile.AudioInfo) > 0 {
+		settings["audio_codec"] = "copy"
+	}
+	if err := ApplyMuxer(settings, outputFile, videoFile.Duration, options); err != nil {
+		return nil, err
+	}
+
+	args, dropped := remuxArgs(inputFile, outputFile, videoFile, settings)
+	if dropped > 0 {
+		vc.Logger.Warning("Leaving out %d image subtitle streams, which %s files cannot hold", dropped, container)
+	}
+
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return nil, i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+	command := fmt.Sprintf("%s %s", ffmpegPath, strings.Join(args, " "))
+	vc.Logger.Debug("Remuxing: %s", command)
+
+	start := time.Now()
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args); err != nil {
+		os.Remove(outputFile)
+		return nil, fmt.Errorf("failed to remux: %w", err)
+	}
+
+	stat, err := os.Stat(outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output file info: %w", err)
+	}
+	result := &CompressionResult{
+		InputFile:      inputFile,
+		OutputFile:     outputFile,
+		OriginalSize:   videoFile.Size,
+		CompressedSize: stat.Size(),
+		ProcessingTime: time.Since(start),
+		FFmpegCommand:  command,
+		Settings:       settings,
+	}
+	if result.OriginalSize > 0 {
+		result.CompressionRatio = float64(result.OriginalSize) / float64(result.CompressedSize)
+		result.SavedSpaceBytes = result.OriginalSize - result.CompressedSize
+		result.SavedSpacePercent = float64(result.SavedSpaceBytes) / float64(result.OriginalSize) * 100
+	}
+	return result, nil
+}
+
+// remuxContainer returns the container of outputFile, e.g. mp4
+func remuxContainer(outputFile string) string {
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(outputFile), "."))
+	if ext == "m4v" {
+		return "mp4"
+	}
+	return ext
+}
+
+// remuxArgs returns the arguments that copy the streams of inputFile into
+// outputFile, and how many image subtitle streams are left out
+func remuxArgs(inputFile, outputFile string, videoFile *ffmpeg.VideoFile, settings map[string]string) ([]string, int) {
+	container := remuxContainer(outputFile)
+	subtitleCodec, converted := remuxSubtitleCodecs[container]
+
+	// Files without timestamps, such as AVI, get them made up
+	args := []string{"-y", "-fflags", "+genpts"}
+	args = append(args, ffmpeg.RemoteInputArgs(inputFile)...)
+	args = append(args, "-i", ffmpeg.FileArg(inputFile), "-map", "0:v?", "-map", "0:a?")
+
+	dropped := 0
+	var subtitleArgs []string
+	for i, stream := range videoFile.SubtitleInfo {
+		if converted && !stream.IsText() {
+			dropped++
+			continue
+		}
+		args = append(args, "-map", fmt.Sprintf("0:s:%d", i))
+		kept := i - dropped
+		switch {
+		case converted:
+			subtitleArgs = append(subtitleArgs, fmt.Sprintf("-c:s:%d", kept), subtitleCodec)
+		case stream.Codec == "mov_text":
+			// Matroska has no MP4 text subtitles, SRT holds the same text
+			subtitleArgs = append(subtitleArgs, fmt.Sprintf("-c:s:%d", kept), "srt")
+		}
+	}
+	// Fonts of styled subtitles
+	if container == "mkv" {
+		args = append(args, "-map", "0:t?")
+	}
+	args = append(args, "-c", "copy")
+	args = append(args, subtitleArgs...)
+
+	args = append(args, "-map_metadata", "0", "-map_chapters", "0")
+	for _, tag := range staleTags {
+		args = append(args, "-metadata", tag+"=")
+	}
+	for _, tag := range staleStreamTags {
+		args = append(args, "-metadata:s", tag+"=")
+	}
+
+	args = append(args, muxerArgs(settings)...)
+	return append(args, ffmpeg.FileArg(outputFile)), dropped
+}
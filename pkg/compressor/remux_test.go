@@ -0,0 +1,57 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRemux(t *testing.T) {
+	videoFile := &ffmpeg.VideoFile{
+		VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264"},
+		AudioInfo: []ffmpeg.AudioStreamInfo{{Codec: "aac"}, {Codec: "pcm_s16le"}},
+	}
+	assert.NoError(t, CheckRemux(videoFile, "mkv"))
+	assert.EqualError(t, CheckRemux(videoFile, "mp4"), "mp4 files cannot hold pcm_s16le without re-encoding (remux to mkv, or compress instead)")
+	assert.EqualError(t, CheckRemux(videoFile, "webm"), "webm files cannot hold h264, aac, pcm_s16le without re-encoding (remux to mkv, or compress instead)")
+
+	videoFile.AudioInfo = videoFile.AudioInfo[:1]
+	assert.NoError(t, CheckRemux(videoFile, "mp4"))
+	assert.True(t, ValidRemuxContainer("mov"))
+	assert.False(t, ValidRemuxContainer("avi"))
+}
+
+func TestRemuxArgs(t *testing.T) {
+	videoFile := &ffmpeg.VideoFile{
+		Duration:     60,
+		VideoInfo:    ffmpeg.VideoStreamInfo{Codec: "h264"},
+		AudioInfo:    []ffmpeg.AudioStreamInfo{{Codec: "aac"}},
+		SubtitleInfo: []ffmpeg.SubtitleStreamInfo{{Codec: "hdmv_pgs_subtitle"}, {Codec: "subrip"}},
+	}
+	settings := map[string]string{"codec": "copy"}
+	assert.NoError(t, ApplyMuxer(settings, "out.mp4", videoFile.Duration, MuxerOptions{}))
+
+	// MP4 takes the text subtitles as mov_text and leaves the images out
+	args, dropped := remuxArgs("in.mkv", "out.mp4", videoFile, settings)
+	assert.Equal(t, 1, dropped)
+	assert.Contains(t, args, "0:s:1")
+	assert.NotContains(t, args, "0:s:0")
+	assert.NotContains(t, args, "0:t?")
+	assert.Equal(t, []string{"-c:s:0", "mov_text"}, argsAfter(args, "-c:s:0", 1))
+	assert.Equal(t, []string{"-movflags", "+faststart"}, argsAfter(args, "-movflags", 1))
+	assert.Contains(t, args, "encoder=")
+	assert.Equal(t, "out.mp4", args[len(args)-1])
+
+	// Matroska keeps every stream and the fonts, with MP4 text as SRT
+	videoFile.SubtitleInfo = []ffmpeg.SubtitleStreamInfo{{Codec: "hdmv_pgs_subtitle"}, {Codec: "mov_text"}}
+	assert.NoError(t, ApplyMuxer(settings, "out.mkv", videoFile.Duration, MuxerOptions{}))
+	args, dropped = remuxArgs("in.mp4", "out.mkv", videoFile, settings)
+	assert.Equal(t, 0, dropped)
+	assert.Contains(t, args, "0:s:0")
+	assert.Contains(t, args, "0:t?")
+	assert.NotContains(t, args, "-c:s:0")
+	assert.Equal(t, []string{"-c:s:1", "srt"}, argsAfter(args, "-c:s:1", 1))
+	assert.NotContains(t, args, "-movflags")
+	assert.Equal(t, []string{"-reserve_index_space", "4096"}, argsAfter(args, "-reserve_index_space", 1))
+}
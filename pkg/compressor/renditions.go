@@ -0,0 +1,339 @@
+package compressor
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Rendition is one rung of a quality ladder: the video scaled to a height,
+// with the width following the aspect ratio
+type Rendition struct {
+	Name   string // Label such as 720p, used in output names
+	Height int    // Output height in pixels
+}
+
+// RenditionOutput is a rendition and the file it is written to
+type RenditionOutput struct {
+	Rendition
+	OutputFile string // Video file, or HLS media playlist for a ladder with a master playlist
+}
+
+// Ladder lists the renditions encoded from one input
+type Ladder struct {
+	Outputs        []RenditionOutput
+	MasterPlaylist string // HLS master playlist listing the renditions, empty for separate files
+}
+
+// hlsSegmentSeconds is the target segment length of HLS renditions. Every
+// rendition gets keyframes at the same times, so players can switch between
+// them at segment boundaries.
+const hlsSegmentSeconds = 6
+
+// Heights outside this range are almost certainly typos
+const (
+	minRenditionHeight = 144
+	maxRenditionHeight = 4320
+)
+
+// ParseRenditions parses a list of heights such as "1080p,720p,480p". The
+// renditions are returned from the tallest down, without duplicates.
+func ParseRenditions(value string) ([]Rendition, error) {
+	seen := make(map[int]bool)
+	var renditions []Rendition
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+		height, err := strconv.Atoi(strings.TrimSuffix(item, "p"))
+		if err != nil || height < minRenditionHeight || height > maxRenditionHeight || height%2 != 0 {
+			return nil, i18n.Errorf("invalid rendition %s (use even heights such as 1080p,720p,480p)", item)
+		}
+		if seen[height] {
+			continue
+		}
+		seen[height] = true
+		renditions = append(renditions, Rendition{Name: fmt.Sprintf("%dp", height), Height: height})
+	}
+	if len(renditions) == 0 {
+		return nil, i18n.Errorf("no renditions given (use heights such as 1080p,720p,480p)")
+	}
+
+	sort.Slice(renditions, func(i, j int) bool { return renditions[i].Height > renditions[j].Height })
+	return renditions, nil
+}
+
+// LadderFor splits renditions into those the source can fill and those that
+// would be upscaled from a shorter source. Sources of unknown height keep
+// every rendition.
+func LadderFor(renditions []Rendition, sourceHeight int) (kept, dropped []Rendition) {
+	for _, r := range renditions {
+		if sourceHeight > 0 && r.Height > sourceHeight {
+			dropped = append(dropped, r)
+		} else {
+			kept = append(kept, r)
+		}
+	}
+	return kept, dropped
+}
+
+// RenditionSettings returns a copy of settings that scales the video to r.
+// The bitrate shrinks with the pixel count; CRF needs no change because it
+// already adapts to the resolution. It fails when the settings already
+// scale the video.
+func RenditionSettings(settings map[string]string, r Rendition, sourceHeight int, hls bool) (map[string]string, error) {
+	result := make(map[string]string, len(settings)+1)
+	for key, value := range settings {
+		result[key] = value
+	}
+
+	if err := ffmpeg.SetVideoFilter(result, ffmpeg.FilterScale, fmt.Sprintf("scale=-2:%d", r.Height)); err != nil {
+		return nil, err
+	}
+
+	if bitrate := analyzer.ParseBitrate(result["bitrate"]); bitrate > 0 && sourceHeight > r.Height {
+		ratio := float64(r.Height) / float64(sourceHeight)
+		result["bitrate"] = fmt.Sprintf("%dk", int64(float64(bitrate)*ratio*ratio)/1000)
+	}
+
+	if hls {
+		// The HLS muxer writes its own fragmented MP4 segments
+		withoutMuxer(result)
+		result["force_key_frames"] = fmt.Sprintf("expr:gte(t,n_forced*%d)", hlsSegmentSeconds)
+	}
+	return result, nil
+}
+
+// hlsArgs returns the muxer arguments of an HLS rendition. Segments are
+// fragmented MP4, which holds every codec the analyzer picks.
+func hlsArgs(playlist, codec string) []string {
+	dir := filepath.Dir(playlist)
+	args := []string{
+		"-f", "hls",
+		"-hls_time", strconv.Itoa(hlsSegmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_type", "fmp4",
+		"-hls_fmp4_init_filename", "init.mp4",
+		"-hls_segment_filename", filepath.Join(dir, "segment_%05d.m4s"),
+	}
+	// Apple players only accept HEVC tagged as hvc1
+	if codec == "libx265" || strings.HasPrefix(codec, "hevc_") {
+		args = append(args, "-tag:v", "hvc1")
+	}
+	return args
+}
+
+// CompressRenditions encodes every rendition of the ladder in one FFmpeg
+// run: the input is decoded once and each output gets its own scaling, with
+// the settings of the analysis shared by all of them. The results are in
+// the order of ladder.Outputs.
+func (vc *VideoCompressor) CompressRenditions(inputFile string, ladder Ladder, analysis *analyzer.VideoAnalysis,
+	settings map[string]string, quality int, preset string, progress *util.ProgressTracker) ([]*CompressionResult, error) {
+	if len(ladder.Outputs) == 0 {
+		return nil, i18n.Errorf("no renditions to encode")
+	}
+	startTime := time.Now()
+	source := analysis.VideoFile
+
+	originalSize, err := inputSize(inputFile, analysis)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := vc.prepareSettings(settings, quality, preset); err != nil {
+		return nil, err
+	}
+	removeTempDir, err := vc.useJobTempDir()
+	if err != nil {
+		return nil, err
+	}
+	defer removeTempDir()
+	vc.prepareHDR(inputFile, source, settings)
+	if vc.TargetVMAF > 0 {
+		vc.Logger.Warning("Target VMAF is ignored when encoding renditions")
+	}
+
+	hls := ladder.MasterPlaylist != ""
+	var args []string
+	outputSettings := make([]map[string]string, len(ladder.Outputs))
+	for i, output := range ladder.Outputs {
+		renditionSettings, err := RenditionSettings(settings, output.Rendition, source.VideoInfo.Height, hls)
+		if err != nil {
+			return nil, err
+		}
+		outputSettings[i] = renditionSettings
+		// The scale filter runs on the CPU, so frames never stay on the GPU
+		if i == 0 {
+			args, _ = vc.inputArgs(inputFile, renditionSettings)
+		}
+		args = append(args, vc.outputArgs(inputFile, renditionSettings, false)...)
+		if hls {
+			if err := os.MkdirAll(filepath.Dir(output.OutputFile), 0755); err != nil {
+				return nil, fmt.Errorf("failed to create rendition directory: %w", err)
+			}
+			args = append(args, hlsArgs(output.OutputFile, settings["codec"])...)
+		}
+		args = append(args, ffmpeg.FileArg(output.OutputFile))
+	}
+
+	ffmpegPath, err := vc.runner().EncodePath()
+	if err != nil {
+		return nil, i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+	vc.Logger.Debug("Running FFmpeg command: %s %s", ffmpegPath, strings.Join(args, " "))
+
+	if err := vc.runWithProgress(ffmpegPath, args, source.Duration, progress); err != nil {
+		return nil, err
+	}
+
+	results := make([]*CompressionResult, len(ladder.Outputs))
+	probed := make([]*ffmpeg.VideoFile, len(ladder.Outputs))
+	for i, output := range ladder.Outputs {
+		result := &CompressionResult{
+			InputFile:    inputFile,
+			OutputFile:   output.OutputFile,
+			OriginalSize: originalSize,
+			Settings:     outputSettings[i],
+		}
+		results[i] = result
+
+		// An HLS rendition is its playlist and the segments next to it
+		if hls {
+			result.CompressedSize, err = dirSize(filepath.Dir(output.OutputFile))
+		} else {
+			result.CompressedSize, err = fileSize(output.OutputFile)
+		}
+		if err != nil {
+			result.Error = fmt.Errorf("failed to get output file info: %w", err)
+			return results, result.Error
+		}
+
+		probed[i], err = vc.FFmpeg.GetVideoInfo(output.OutputFile)
+		if err == nil {
+			err = verifyOutput(source, probed[i])
+		} else {
+			err = fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+		}
+		if err != nil {
+			result.Error = err
+			return results, err
+		}
+
+		result.ProcessingTime = time.Since(startTime)
+		if originalSize > 0 && result.CompressedSize > 0 {
+			result.SavedSpaceBytes = originalSize - result.CompressedSize
+			result.CompressionRatio = float64(originalSize) / float64(result.CompressedSize)
+			result.SavedSpacePercent = float64(result.SavedSpaceBytes) / float64(originalSize) * 100
+		}
+		result.AverageFrameQuality = vc.EstimateFrameQuality(result.Settings)
+	}
+
+	if hls {
+		if err := writeMasterPlaylist(ladder.MasterPlaylist, ladder.Outputs, probed); err != nil {
+			return results, fmt.Errorf("failed to write master playlist: %w", err)
+		}
+	}
+	return results, nil
+}
+
+// writeMasterPlaylist writes the HLS master playlist that lists the media
+// playlists of the renditions, with their peak and average bitrates
+func writeMasterPlaylist(path string, outputs []RenditionOutput, probed []*ffmpeg.VideoFile) error {
+	var b strings.Builder
+	b.WriteString("#EXTM3U\n#EXT-X-VERSION:7\n#EXT-X-INDEPENDENT-SEGMENTS\n")
+	for i, output := range outputs {
+		peak, average, err := playlistBandwidth(output.OutputFile)
+		if err != nil {
+			return err
+		}
+		uri, err := filepath.Rel(filepath.Dir(path), output.OutputFile)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "#EXT-X-STREAM-INF:BANDWIDTH=%d,AVERAGE-BANDWIDTH=%d,RESOLUTION=%dx%d\n%s\n",
+			peak, average, probed[i].VideoInfo.Width, probed[i].VideoInfo.Height, filepath.ToSlash(uri))
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// playlistBandwidth returns the peak and average bitrate in bits per second
+// of the segments listed in an HLS media playlist
+func playlistBandwidth(playlist string) (peak, average int64, err error) {
+	f, err := os.Open(playlist)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(playlist)
+	var totalBits, totalSeconds, segmentSeconds float64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#EXTINF:") {
+			value := strings.SplitN(strings.TrimPrefix(line, "#EXTINF:"), ",", 2)[0]
+			segmentSeconds, _ = strconv.ParseFloat(value, 64)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") || segmentSeconds <= 0 {
+			continue
+		}
+		size, err := fileSize(filepath.Join(dir, line))
+		if err != nil {
+			return 0, 0, err
+		}
+		bits := float64(size) * 8
+		peak = int64(math.Max(float64(peak), bits/segmentSeconds))
+		totalBits += bits
+		totalSeconds += segmentSeconds
+		segmentSeconds = 0
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, 0, err
+	}
+	if totalSeconds > 0 {
+		average = int64(totalBits / totalSeconds)
+	}
+	return peak, average, nil
+}
+
+// fileSize returns the size of the file at path
+func fileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// dirSize returns the total size of the files in dir
+func dirSize(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return 0, err
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
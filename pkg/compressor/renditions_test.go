@@ -0,0 +1,140 @@
+package compressor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRenditions(t *testing.T) {
+	renditions, err := ParseRenditions("480p, 1080p,720P,720p")
+	assert.NoError(t, err)
+	assert.Equal(t, []Rendition{{"1080p", 1080}, {"720p", 720}, {"480p", 480}}, renditions)
+
+	renditions, err = ParseRenditions("360")
+	assert.NoError(t, err)
+	assert.Equal(t, []Rendition{{"360p", 360}}, renditions)
+
+	for _, value := range []string{"", "hd", "721p", "100p", "8640p"} {
+		_, err := ParseRenditions(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLadderFor(t *testing.T) {
+	renditions, _ := ParseRenditions("1080p,720p,480p")
+	kept, dropped := LadderFor(renditions, 720)
+	assert.Equal(t, []Rendition{{"720p", 720}, {"480p", 480}}, kept)
+	assert.Equal(t, []Rendition{{"1080p", 1080}}, dropped)
+
+	kept, dropped = LadderFor(renditions, 0)
+	assert.Equal(t, renditions, kept)
+	assert.Empty(t, dropped)
+}
+
+func TestRenditionSettings(t *testing.T) {
+	settings := map[string]string{"codec": "libx264", "crf": "23", "bitrate": "4M"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, "fps=30"))
+	result, err := RenditionSettings(settings, Rendition{"540p", 540}, 1080, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "fps=30,scale=-2:540", result["video_filter"])
+	assert.Equal(t, "1000k", result["bitrate"])
+	assert.Equal(t, "23", result["crf"])
+	assert.Empty(t, result["force_key_frames"])
+
+	// The shared settings are left alone
+	assert.Equal(t, "fps=30", settings["video_filter"])
+	assert.Equal(t, "4M", settings["bitrate"])
+
+	// HLS renditions share keyframe times
+	result, err = RenditionSettings(map[string]string{"codec": "libx265"}, Rendition{"1080p", 1080}, 1080, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "scale=-2:1080", result["video_filter"])
+	assert.Equal(t, "expr:gte(t,n_forced*6)", result["force_key_frames"])
+	assert.Contains(t, hlsArgs(filepath.Join("out", "1080p", "index.m3u8"), "libx265"), "hvc1")
+
+	// Settings that already scale the video collide with the rendition's
+	settings = map[string]string{"codec": "libx264"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=1280:720"))
+	_, err = RenditionSettings(settings, Rendition{"540p", 540}, 1080, false)
+	assert.Error(t, err)
+}
+
+func TestCompressRenditions(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+	runner := &fakeRunner{}
+	ff := ffmpeg.NewFFmpeg("in.mp4", "", nil, logger)
+	ff.Runner = runner
+	vc := NewVideoCompressor(ff, nil, logger)
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "in.mp4")
+	assert.NoError(t, os.WriteFile(input, make([]byte, 4000), 0644))
+	ladder := Ladder{Outputs: []RenditionOutput{
+		{Rendition{"720p", 720}, filepath.Join(dir, "in-720p.mp4")},
+		{Rendition{"480p", 480}, filepath.Join(dir, "in-480p.mp4")},
+	}}
+	for _, output := range ladder.Outputs {
+		assert.NoError(t, os.WriteFile(output.OutputFile, make([]byte, 1000), 0644))
+	}
+	analysis := &analyzer.VideoAnalysis{VideoFile: &ffmpeg.VideoFile{Duration: 10, VideoInfo: ffmpeg.VideoStreamInfo{Height: 720}}}
+
+	results, err := vc.CompressRenditions(input, ladder, analysis, map[string]string{"codec": "libx264", "crf": "23"},
+		3, "balanced", util.NewProgressTracker(100, "Compressing", logger))
+	assert.NoError(t, err)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, ladder.Outputs[1].OutputFile, results[1].OutputFile)
+		assert.Equal(t, 75.0, results[1].SavedSpacePercent)
+		assert.Equal(t, "scale=-2:480", results[1].Settings["video_filter"])
+	}
+
+	// One FFmpeg run decodes the input once for every output
+	var encodes [][]string
+	for _, call := range runner.calls {
+		if call[0] == "ffmpeg" && call[len(call)-1] != "-encoders" {
+			encodes = append(encodes, call)
+		}
+	}
+	if assert.Len(t, encodes, 1) {
+		args := encodes[0]
+		assert.Equal(t, ffmpeg.FileArg(ladder.Outputs[1].OutputFile), args[len(args)-1])
+		assert.Contains(t, args, "scale=-2:720")
+		assert.Contains(t, args, ffmpeg.FileArg(ladder.Outputs[0].OutputFile))
+	}
+}
+
+func TestPlaylistBandwidth(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "segment_00000.m4s"), make([]byte, 6000), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "segment_00001.m4s"), make([]byte, 1000), 0644))
+	playlist := filepath.Join(dir, "index.m3u8")
+	assert.NoError(t, os.WriteFile(playlist, []byte(`#EXTM3U
+#EXT-X-VERSION:7
+#EXT-X-MAP:URI="init.mp4"
+#EXTINF:6.000000,
+segment_00000.m4s
+#EXTINF:2.000000,
+segment_00001.m4s
+#EXT-X-ENDLIST
+`), 0644))
+
+	peak, average, err := playlistBandwidth(playlist)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8000), peak)
+	assert.Equal(t, int64(7000), average)
+
+	master := filepath.Join(filepath.Dir(dir), "master.m3u8")
+	outputs := []RenditionOutput{{Rendition{"720p", 720}, playlist}}
+	probed := []*ffmpeg.VideoFile{{VideoInfo: ffmpeg.VideoStreamInfo{Width: 1280, Height: 720}}}
+	assert.NoError(t, writeMasterPlaylist(master, outputs, probed))
+	data, err := os.ReadFile(master)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "#EXT-X-STREAM-INF:BANDWIDTH=8000,AVERAGE-BANDWIDTH=7000,RESOLUTION=1280x720\n"+filepath.Base(dir)+"/index.m3u8\n")
+}
@@ -0,0 +1,57 @@
+package compressor
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// ignoreErrorsArgs make FFmpeg read past damaged data instead of failing:
+// decoders ignore bitstream errors, corrupt packets are dropped and missing
+// timestamps are generated
+var ignoreErrorsArgs = []string{"-err_detect", "ignore_err", "-fflags", "+genpts+discardcorrupt"}
+
+// Recovery records how much of a damaged input was salvaged with IgnoreErrors
+type Recovery struct {
+	SourceDuration float64 // Seconds the source was expected to last, 0 when unknown
+	OutputDuration float64 // Seconds of video written to the output
+}
+
+// newRecovery compares the salvaged output with the source
+func newRecovery(source, output *ffmpeg.VideoFile) *Recovery {
+	recovery := &Recovery{OutputDuration: output.Duration}
+	if source != nil {
+		recovery.SourceDuration = source.Duration
+	}
+	return recovery
+}
+
+// Percent returns the share of the source duration that was recovered, or 0
+// when the duration of the source is unknown
+func (r *Recovery) Percent() float64 {
+	if r.SourceDuration <= 0 {
+		return 0
+	}
+	return math.Min(r.OutputDuration/r.SourceDuration*100, 100)
+}
+
+// Complete reports whether the output lasts as long as the source, within the
+// tolerance of the output verification
+func (r *Recovery) Complete() bool {
+	if r.SourceDuration <= 0 {
+		return false
+	}
+	tolerance := math.Max(durationToleranceSeconds, r.SourceDuration*durationToleranceFraction)
+	return r.SourceDuration-r.OutputDuration <= tolerance
+}
+
+// String describes the recovered duration for reports
+func (r *Recovery) String() string {
+	recovered := util.FormatDuration(int(math.Round(r.OutputDuration)))
+	if r.SourceDuration <= 0 {
+		return fmt.Sprintf("%s (source duration unknown)", recovered)
+	}
+	return fmt.Sprintf("%s of %s (%.1f%%)", recovered, util.FormatDuration(int(math.Round(r.SourceDuration))), r.Percent())
+}
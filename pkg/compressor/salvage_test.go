@@ -0,0 +1,60 @@
+package compressor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildFFmpegArgsIgnoreErrors tests that damaged data is skipped on input
+func TestBuildFFmpegArgsIgnoreErrors(t *testing.T) {
+	vc := &VideoCompressor{IgnoreErrors: true}
+	args := vc.BuildFFmpegArgs("capture.mkv", "out.mp4", map[string]string{"codec": "libx264", "crf": "23"})
+	assert.Equal(t, []string{"-y", "-err_detect", "ignore_err", "-fflags", "+genpts+discardcorrupt", "-i", "capture.mkv"}, args[:7])
+
+	vc.IgnoreErrors = false
+	assert.NotContains(t, vc.BuildFFmpegArgs("capture.mkv", "out.mp4", map[string]string{"codec": "libx264"}), "-err_detect")
+}
+
+// TestRecovery tests the share of a damaged input reported as recovered
+func TestRecovery(t *testing.T) {
+	recovery := newRecovery(&ffmpeg.VideoFile{Duration: 900}, &ffmpeg.VideoFile{Duration: 750})
+	assert.InDelta(t, 83.33, recovery.Percent(), 0.01)
+	assert.False(t, recovery.Complete())
+	assert.Equal(t, "12m 30s of 15m 0s (83.3%)", recovery.String())
+
+	// Within the verification tolerance the whole input was recovered
+	recovery.OutputDuration = 895
+	assert.True(t, recovery.Complete())
+
+	// Estimated source durations can be shorter than what was recovered
+	recovery.OutputDuration = 905
+	assert.Equal(t, 100.0, recovery.Percent())
+
+	recovery = newRecovery(nil, &ffmpeg.VideoFile{Duration: 42})
+	assert.Equal(t, 0.0, recovery.Percent())
+	assert.False(t, recovery.Complete())
+	assert.Equal(t, "42s (source duration unknown)", recovery.String())
+}
+
+// TestVerifyCompressedFileIgnoreErrors tests that salvaged outputs may be
+// shorter than the source
+func TestVerifyCompressedFileIgnoreErrors(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	ff := ffmpeg.NewFFmpeg("", "", nil, logger)
+	ff.Runner = &fakeRunner{} // Probes a ten-second output
+	vc := NewVideoCompressor(ff, nil, logger)
+	source := &ffmpeg.VideoFile{Duration: 60}
+
+	_, err := vc.verifyCompressedFile("out.mp4", source)
+	assert.True(t, errors.Is(err, ErrVerificationFailed))
+
+	vc.IgnoreErrors = true
+	output, err := vc.verifyCompressedFile("out.mp4", source)
+	assert.NoError(t, err)
+	assert.InDelta(t, 10.0, output.Duration, 0.001)
+}
@@ -0,0 +1,69 @@
+package compressor
+
+import (
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// StreamModes selects streams that are copied or dropped instead of re-encoded
+type StreamModes struct {
+	CopyVideo bool // Copy the video stream, re-encode audio only
+	CopyAudio bool // Copy the audio streams unchanged
+	NoAudio   bool // Drop the audio streams
+}
+
+// defaultAudioBitrate is used when audio is re-encoded without a bitrate from the analyzer
+const defaultAudioBitrate = "192k"
+
+// Validate reports combinations of modes that contradict each other
+func (m StreamModes) Validate() error {
+	if m.CopyVideo && m.CopyAudio {
+		return i18n.Errorf("--copy-video and --copy-audio together leave nothing to transform")
+	}
+	if m.CopyAudio && m.NoAudio {
+		return i18n.Errorf("--copy-audio and --no-audio cannot be used together")
+	}
+	return nil
+}
+
+// ApplyStreamModes adjusts the analyzer's settings for the stream modes. It
+// fails when the result would copy every stream unchanged.
+func ApplyStreamModes(settings map[string]string, modes StreamModes) error {
+	if err := modes.Validate(); err != nil {
+		return err
+	}
+	hasAudio := settings["audio_codec"] != ""
+
+	if modes.CopyVideo {
+		if !hasAudio && !modes.NoAudio {
+			return i18n.Errorf("--copy-video on a file without audio leaves nothing to transform")
+		}
+		// Only the codec matters when the stream is copied
+		for _, key := range []string{"crf", "preset", "profile", "level", "tune", "x265-params", "pix_fmt",
+			"bitrate", "gop", "force_key_frames", "row_mt", "tile_columns"} {
+			delete(settings, key)
+		}
+		ffmpeg.ClearVideoFilters(settings)
+		settings["codec"] = "copy"
+
+		// Re-encode the audio even when the analyzer would have copied it,
+		// e.g. to turn large PCM tracks into AAC
+		if hasAudio && settings["audio_codec"] == "copy" {
+			settings["audio_codec"] = "aac"
+			settings["audio_bitrate"] = defaultAudioBitrate
+		}
+	}
+
+	if modes.CopyAudio && hasAudio {
+		settings["audio_codec"] = "copy"
+		delete(settings, "audio_bitrate")
+	}
+
+	if modes.NoAudio {
+		delete(settings, "audio_codec")
+		delete(settings, "audio_bitrate")
+		settings["no_audio"] = "1"
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamModesValidate(t *testing.T) {
+	assert.NoError(t, StreamModes{}.Validate())
+	assert.NoError(t, StreamModes{CopyVideo: true, NoAudio: true}.Validate())
+	assert.Error(t, StreamModes{CopyVideo: true, CopyAudio: true}.Validate())
+	assert.Error(t, StreamModes{CopyAudio: true, NoAudio: true}.Validate())
+}
+
+func TestApplyStreamModesCopyVideo(t *testing.T) {
+	settings := map[string]string{"codec": "libx265", "crf": "28", "preset": "medium", "pix_fmt": "yuv420p", "audio_codec": "copy"}
+	assert.NoError(t, ApplyStreamModes(settings, StreamModes{CopyVideo: true}))
+	assert.Equal(t, map[string]string{"codec": "copy", "audio_codec": "aac", "audio_bitrate": "192k"}, settings)
+
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mov", "out.mov", settings)
+	assert.Equal(t, []string{"-y", "-i", "in.mov", "-c:v", "copy", "-c:a", "aac", "-b:a", "192k", "out.mov"}, args)
+
+	// Without audio there is nothing left to do
+	settings = map[string]string{"codec": "libx264", "crf": "23"}
+	assert.Error(t, ApplyStreamModes(settings, StreamModes{CopyVideo: true}))
+}
+
+func TestApplyStreamModesAudio(t *testing.T) {
+	settings := map[string]string{"codec": "libx264", "audio_codec": "aac", "audio_bitrate": "128k"}
+	assert.NoError(t, ApplyStreamModes(settings, StreamModes{CopyAudio: true}))
+	assert.Equal(t, "copy", settings["audio_codec"])
+	assert.NotContains(t, settings, "audio_bitrate")
+
+	settings = map[string]string{"codec": "libx264", "audio_codec": "aac", "audio_bitrate": "128k"}
+	assert.NoError(t, ApplyStreamModes(settings, StreamModes{NoAudio: true}))
+	assert.NotContains(t, settings, "audio_codec")
+
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings)
+	assert.Contains(t, args, "-an")
+}
@@ -0,0 +1,91 @@
+package compressor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/sidecar"
+)
+
+// subtitleCodecs holds the subtitle encoder of each output container that
+// takes text subtitles. Matroska keeps SRT, ASS and WebVTT as they are.
+var subtitleCodecs = map[string]string{
+	".mp4":  "mov_text",
+	".m4v":  "mov_text",
+	".mov":  "mov_text",
+	".mkv":  "copy",
+	".webm": "webvtt",
+}
+
+// CanMuxSubtitles reports whether text subtitles can be added to outputFile
+func CanMuxSubtitles(outputFile string) bool {
+	_, ok := subtitleCodecs[strings.ToLower(filepath.Ext(outputFile))]
+	return ok
+}
+
+// MuxSubtitles adds the text subtitles subs to outputFile as subtitle
+// streams, tagged with the language and forced flag of their names. The
+// other streams are copied, so it costs a pass over the file but no
+// encode. The file keeps the layout of the settings it was encoded with,
+// see ApplyMuxer.
+func (vc *VideoCompressor) MuxSubtitles(outputFile string, subs []sidecar.File, settings map[string]string) error {
+	ext := filepath.Ext(outputFile)
+	codec, ok := subtitleCodecs[strings.ToLower(ext)]
+	if !ok {
+		return i18n.Errorf("subtitles cannot be muxed into %s files", ext)
+	}
+
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+
+	tempFile := strings.TrimSuffix(outputFile, ext) + ".subs" + ext
+	args := subtitleMuxArgs(outputFile, tempFile, subs, codec, settings)
+	vc.Logger.Debug("Muxing subtitles: %s %s", ffmpegPath, strings.Join(args, " "))
+
+	if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to mux subtitles: %w", err)
+	}
+	if err := os.Rename(tempFile, outputFile); err != nil {
+		os.Remove(tempFile)
+		return fmt.Errorf("failed to mux subtitles: %w", err)
+	}
+	return nil
+}
+
+// subtitleMuxArgs returns the arguments that copy outputFile to tempFile
+// with subs added. The new subtitles come before those the output already
+// has, so their stream positions are known.
+func subtitleMuxArgs(outputFile, tempFile string, subs []sidecar.File, codec string, settings map[string]string) []string {
+	args := []string{"-y", "-i", ffmpeg.FileArg(outputFile)}
+	for _, sub := range subs {
+		args = append(args, "-i", ffmpeg.FileArg(sub.Path))
+	}
+
+	args = append(args, "-map", "0:v?", "-map", "0:a?")
+	for i := range subs {
+		args = append(args, "-map", fmt.Sprintf("%d:s", i+1))
+	}
+	args = append(args, "-map", "0:s?", "-c", "copy", "-c:s", codec)
+
+	for i, sub := range subs {
+		if language := sub.Language(); language != "" {
+			// Containers take the language without its region
+			language, _, _ = strings.Cut(strings.ReplaceAll(language, "_", "-"), "-")
+			args = append(args, fmt.Sprintf("-metadata:s:s:%d", i), "language="+strings.ToLower(language))
+		}
+		if sub.Forced() {
+			args = append(args, fmt.Sprintf("-disposition:s:%d", i), "forced")
+		}
+	}
+
+	args = append(args, muxerArgs(settings)...)
+	return append(args, ffmpeg.FileArg(tempFile))
+}
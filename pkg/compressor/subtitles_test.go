@@ -0,0 +1,30 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/sidecar"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubtitleMuxArgs(t *testing.T) {
+	subs := []sidecar.File{
+		{Path: "/in/clip.pt-BR.srt", Suffix: ".pt-BR.srt", Kind: sidecar.Subtitle},
+		{Path: "/in/clip.en.forced.ass", Suffix: ".en.forced.ass", Kind: sidecar.Subtitle},
+		{Path: "/in/clip.srt", Suffix: ".srt", Kind: sidecar.Subtitle},
+	}
+
+	args := subtitleMuxArgs("/out/clip.mp4", "/out/clip.subs.mp4", subs, "mov_text", map[string]string{"movflags": "+faststart"})
+	assert.Equal(t, []string{
+		"-y", "-i", "/out/clip.mp4", "-i", "/in/clip.pt-BR.srt", "-i", "/in/clip.en.forced.ass", "-i", "/in/clip.srt",
+		"-map", "0:v?", "-map", "0:a?", "-map", "1:s", "-map", "2:s", "-map", "3:s", "-map", "0:s?",
+		"-c", "copy", "-c:s", "mov_text",
+		"-metadata:s:s:0", "language=pt",
+		"-metadata:s:s:1", "language=en", "-disposition:s:1", "forced",
+		"-movflags", "+faststart",
+		"/out/clip.subs.mp4",
+	}, args)
+
+	assert.True(t, CanMuxSubtitles("/out/clip.MKV"))
+	assert.False(t, CanMuxSubtitles("/out/clip.avi"))
+}
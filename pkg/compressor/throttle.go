@@ -0,0 +1,171 @@
+package compressor
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Steps a Throttle takes after a reading
+const (
+	ThrottleKeep   = ""       // Nothing changes
+	ThrottleFewer  = "fewer"  // Fewer segments are encoded at once
+	ThrottleMore   = "more"   // More segments are encoded at once again
+	ThrottlePause  = "pause"  // Encoding pauses
+	ThrottleResume = "resume" // Encoding resumes
+)
+
+// coolLoadShare is the share of the maximum load the load must fall to
+// before work is added back, so the throttle does not flap around the limit
+const coolLoadShare = 0.8
+
+// coolTemperatureMargin is how many °C below the maximum the temperature
+// must fall to before work is added back
+const coolTemperatureMargin = 5
+
+// Throttle cuts the encoding work while the machine runs hot: each reading
+// above a limit halves the segments a parallel encode runs at once, and
+// pauses encoding once a single one is left. Work comes back a step at a
+// time once the readings are clearly below the limits.
+type Throttle struct {
+	MaxLoad        float64 // Load average above which work is cut, 0 for no limit
+	MaxTemperature float64 // CPU temperature in °C above which work is cut, 0 for no limit
+
+	mu      sync.Mutex
+	planned int  // Segments the running parallel encode would run at once, 0 when none runs
+	limit   int  // Segments allowed at once, 0 for as many as planned
+	cap     int  // Segments allowed at once whatever the readings, 0 for no cap
+	active  int  // Segments being encoded
+	paused  bool // Whether encoding is paused
+}
+
+// Observe takes a reading of the load average and the CPU temperature, NaN
+// for one that cannot be read, and returns the step the throttle took
+func (t *Throttle) Observe(load, temperature float64) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	hot := (t.MaxLoad > 0 && load > t.MaxLoad) || (t.MaxTemperature > 0 && temperature > t.MaxTemperature)
+	cool := (t.MaxLoad <= 0 || math.IsNaN(load) || load <= t.MaxLoad*coolLoadShare) &&
+		(t.MaxTemperature <= 0 || math.IsNaN(temperature) || temperature <= t.MaxTemperature-coolTemperatureMargin)
+
+	switch {
+	case hot && !t.paused:
+		if workers := t.workersLocked(); workers > 1 {
+			t.limit = workers / 2
+			return ThrottleFewer
+		}
+		t.paused = true
+		return ThrottlePause
+	case cool && t.paused:
+		t.paused = false
+		return ThrottleResume
+	case cool && t.limit > 0:
+		t.limit *= 2
+		if t.limit >= t.planned {
+			t.limit = 0
+		}
+		return ThrottleMore
+	}
+	return ThrottleKeep
+}
+
+// Paused reports whether the throttle paused encoding
+func (t *Throttle) Paused() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.paused
+}
+
+// SetCap allows at most workers segments at once whatever the readings,
+// e.g. on battery power; 0 removes the cap
+func (t *Throttle) SetCap(workers int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cap = workers
+}
+
+// Workers returns how many of planned segments may be encoded at once
+func (t *Throttle) Workers(planned int) int {
+	if t == nil {
+		return planned
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.allowedLocked(planned)
+}
+
+// allowedLocked returns how many of planned segments may be encoded at
+// once; t.mu must be held
+func (t *Throttle) allowedLocked(planned int) int {
+	allowed := planned
+	if t.limit > 0 && t.limit < allowed {
+		allowed = t.limit
+	}
+	if t.cap > 0 && t.cap < allowed {
+		allowed = t.cap
+	}
+	return allowed
+}
+
+// workersLocked returns the segments allowed at once in the running encode,
+// 1 when none runs in parallel; t.mu must be held
+func (t *Throttle) workersLocked() int {
+	if t.planned <= 1 {
+		return 1
+	}
+	return t.allowedLocked(t.planned)
+}
+
+// plan records the segments a parallel encode runs at once, 0 once it ends
+func (t *Throttle) plan(workers int) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.planned = workers
+	if workers > 0 && t.limit >= workers {
+		t.limit = 0
+	}
+}
+
+// throttlePollInterval is how often a waiting segment checks the throttle
+var throttlePollInterval = time.Second
+
+// acquire blocks until fewer segments are being encoded than the throttle
+// allows of planned, and counts one more. It returns false, counting
+// nothing, when stop is closed first.
+func (t *Throttle) acquire(planned int, stop <-chan struct{}) bool {
+	if t == nil {
+		return true
+	}
+	for {
+		t.mu.Lock()
+		if t.active < t.allowedLocked(planned) {
+			t.active++
+			t.mu.Unlock()
+			return true
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-stop:
+			return false
+		case <-time.After(throttlePollInterval):
+		}
+	}
+}
+
+// release counts a segment encode acquired with acquire as done
+func (t *Throttle) release() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.active--
+}
@@ -0,0 +1,91 @@
+package compressor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleSteps(t *testing.T) {
+	throttle := &Throttle{MaxLoad: 8, MaxTemperature: 90}
+	unknown := math.NaN()
+	throttle.plan(8)
+
+	// Each hot reading halves the segments, then pauses
+	assert.Equal(t, ThrottleKeep, throttle.Observe(7, unknown))
+	assert.Equal(t, ThrottleFewer, throttle.Observe(9, unknown))
+	assert.Equal(t, 4, throttle.Workers(8))
+	assert.Equal(t, ThrottleFewer, throttle.Observe(5, 95))
+	assert.Equal(t, ThrottleFewer, throttle.Observe(9.5, 80))
+	assert.Equal(t, 1, throttle.Workers(8))
+	assert.Equal(t, ThrottlePause, throttle.Observe(10, 80))
+	assert.True(t, throttle.Paused())
+	assert.Equal(t, ThrottleKeep, throttle.Observe(10, 80))
+
+	// Readings just under the limits change nothing, clearly lower ones add
+	// the work back a step at a time
+	assert.Equal(t, ThrottleKeep, throttle.Observe(7, 88))
+	assert.Equal(t, ThrottleResume, throttle.Observe(6, 80))
+	assert.False(t, throttle.Paused())
+	assert.Equal(t, ThrottleMore, throttle.Observe(6, unknown))
+	assert.Equal(t, 2, throttle.Workers(8))
+	assert.Equal(t, ThrottleMore, throttle.Observe(6, unknown))
+	assert.Equal(t, ThrottleMore, throttle.Observe(6, unknown))
+	assert.Equal(t, 8, throttle.Workers(8))
+	assert.Equal(t, ThrottleKeep, throttle.Observe(6, unknown))
+}
+
+func TestThrottleSingleEncode(t *testing.T) {
+	// Without parallel segments the first hot reading pauses
+	throttle := &Throttle{MaxTemperature: 85}
+	assert.Equal(t, ThrottlePause, throttle.Observe(math.NaN(), 86))
+	assert.Equal(t, ThrottleResume, throttle.Observe(math.NaN(), 79))
+
+	// A cap holds whatever the readings
+	throttle = &Throttle{}
+	throttle.plan(8)
+	throttle.SetCap(1)
+	assert.Equal(t, 1, throttle.Workers(8))
+	assert.Equal(t, ThrottleKeep, throttle.Observe(1, 40))
+	assert.Equal(t, 1, throttle.Workers(8))
+	throttle.SetCap(0)
+	assert.Equal(t, 8, throttle.Workers(8))
+
+	var none *Throttle
+	assert.Equal(t, 6, none.Workers(6))
+	assert.False(t, none.Paused())
+	assert.True(t, none.acquire(6, nil))
+}
+
+func TestThrottleAcquire(t *testing.T) {
+	interval := throttlePollInterval
+	throttlePollInterval = time.Millisecond
+	defer func() { throttlePollInterval = interval }()
+
+	throttle := &Throttle{MaxLoad: 4}
+	throttle.plan(4)
+	throttle.Observe(5, math.NaN())
+	assert.Equal(t, 2, throttle.Workers(4))
+
+	stop := make(chan struct{})
+	assert.True(t, throttle.acquire(4, stop))
+	assert.True(t, throttle.acquire(4, stop))
+
+	// A third segment waits until one of the others is done
+	acquired := make(chan bool)
+	go func() { acquired <- throttle.acquire(4, stop) }()
+	select {
+	case <-acquired:
+		t.Fatal("acquired beyond the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	throttle.release()
+	assert.True(t, <-acquired)
+
+	// and gives up when the encode stops
+	go func() { acquired <- throttle.acquire(4, stop) }()
+	close(stop)
+	assert.False(t, <-acquired)
+}
@@ -0,0 +1,51 @@
+package compressor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// ErrVerificationFailed is returned when FFmpeg finished but the output does
+// not hold the encoded video, e.g. it is truncated or unreadable
+var ErrVerificationFailed = errors.New("output verification failed")
+
+// Outputs may differ from the source by up to this many seconds, or this
+// fraction of the duration for long videos, before they are considered truncated
+const (
+	durationToleranceSeconds  = 1.0
+	durationToleranceFraction = 0.02
+)
+
+// verifyOutput checks the probed output against the source video
+func verifyOutput(source, output *ffmpeg.VideoFile) error {
+	if output.VideoInfo.Codec == "" {
+		return fmt.Errorf("%w: no video stream in %s", ErrVerificationFailed, output.Path)
+	}
+
+	// Sources of unknown duration cannot be compared
+	if source == nil || source.Duration <= 0 {
+		return nil
+	}
+	tolerance := math.Max(durationToleranceSeconds, source.Duration*durationToleranceFraction)
+	if math.Abs(source.Duration-output.Duration) > tolerance {
+		return fmt.Errorf("%w: output lasts %.1fs but the source lasts %.1fs",
+			ErrVerificationFailed, output.Duration, source.Duration)
+	}
+	return nil
+}
+
+// verifyCompressedFile probes the output and checks it against the source.
+// Outputs salvaged with IgnoreErrors may be shorter than the source.
+func (vc *VideoCompressor) verifyCompressedFile(outputFile string, source *ffmpeg.VideoFile) (*ffmpeg.VideoFile, error) {
+	output, err := vc.FFmpeg.GetVideoInfo(outputFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrVerificationFailed, err)
+	}
+	if vc.IgnoreErrors {
+		source = nil
+	}
+	return output, verifyOutput(source, output)
+}
@@ -0,0 +1,37 @@
+package compressor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyOutput(t *testing.T) {
+	source := &ffmpeg.VideoFile{Duration: 600, VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264"}}
+
+	output := &ffmpeg.VideoFile{Path: "out.mp4", Duration: 599.5, VideoInfo: ffmpeg.VideoStreamInfo{Codec: "hevc"}}
+	assert.NoError(t, verifyOutput(source, output))
+
+	// 2% of ten minutes is still within tolerance
+	output.Duration = 590
+	assert.NoError(t, verifyOutput(source, output))
+
+	output.Duration = 300
+	err := verifyOutput(source, output)
+	assert.True(t, errors.Is(err, ErrVerificationFailed))
+
+	// Short clips are held to one second
+	short := &ffmpeg.VideoFile{Duration: 10, VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264"}}
+	output.Duration = 8
+	assert.Error(t, verifyOutput(short, output))
+
+	output.VideoInfo.Codec = ""
+	err = verifyOutput(&ffmpeg.VideoFile{}, output)
+	assert.True(t, errors.Is(err, ErrVerificationFailed))
+
+	// Unknown source durations are not compared
+	output.VideoInfo.Codec = "hevc"
+	assert.NoError(t, verifyOutput(&ffmpeg.VideoFile{}, output))
+}
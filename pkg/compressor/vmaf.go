@@ -0,0 +1,349 @@
+package compressor
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+const (
+	// vmafProbeSegments is the number of short clips sampled from the source
+	vmafProbeSegments = 3
+	// vmafProbeDuration is the length in seconds of each probe clip
+	vmafProbeDuration = 4.0
+	// vmafCRFStep is the distance between the CRF values tried on each probe
+	vmafCRFStep = 4
+)
+
+// vmafScoreRegex matches the pooled score printed by the libvmaf filter
+var vmafScoreRegex = regexp.MustCompile(`VMAF score[:=]\s*([0-9]+(?:\.[0-9]+)?)`)
+
+// VMAFProbe holds the VMAF measured for one CRF value across all probe clips
+type VMAFProbe struct {
+	CRF  int
+	VMAF float64
+}
+
+// FindCRFForTargetVMAF encodes a few short probe clips at different CRF values,
+// measures their VMAF against the source and returns the highest CRF whose
+// fitted VMAF still meets the target
+func (vc *VideoCompressor) FindCRFForTargetVMAF(inputFile string, duration float64, settings map[string]string, target float64) (int, float64, []VMAFProbe, error) {
+	runner := vc.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return 0, 0, nil, i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+
+	capabilities, err := ffmpeg.ProbeCapabilities(runner, ffmpegPath)
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	if err := capabilities.Require(ffmpeg.FeatureVMAF); err != nil {
+		return 0, 0, nil, err
+	}
+
+	if duration <= 0 {
+		return 0, 0, nil, fmt.Errorf("video duration is unknown")
+	}
+
+	baseCRF := 23
+	if crf, err := strconv.Atoi(settings["crf"]); err == nil {
+		baseCRF = crf
+	}
+	minCRF, maxCRF := crfRange(settings["codec"])
+
+	candidates := []int{}
+	for _, crf := range []int{baseCRF - vmafCRFStep, baseCRF, baseCRF + vmafCRFStep} {
+		if crf >= minCRF && crf <= maxCRF {
+			candidates = append(candidates, crf)
+		}
+	}
+
+	probeDir := filepath.Join(vc.TempDir, "vmaf")
+	if err := os.MkdirAll(probeDir, 0755); err != nil {
+		return 0, 0, nil, fmt.Errorf("failed to create probe directory: %w", err)
+	}
+	defer os.RemoveAll(probeDir)
+
+	offsets, clipDuration := probeOffsets(duration, vmafProbeSegments, vmafProbeDuration)
+
+	probes := []VMAFProbe{}
+	for _, crf := range candidates {
+		// Clone settings so the probe encode mirrors the real one except for CRF
+		probeSettings := make(map[string]string)
+		for k, v := range settings {
+			probeSettings[k] = v
+		}
+		probeSettings["crf"] = strconv.Itoa(crf)
+		delete(probeSettings, "audio_codec")
+		delete(probeSettings, "audio_bitrate")
+		withoutMuxer(probeSettings)
+
+		total := 0.0
+		for i, offset := range offsets {
+			probeFile := filepath.Join(probeDir, fmt.Sprintf("probe_crf%d_%d.mp4", crf, i))
+
+			args := []string{
+				"-ss", fmt.Sprintf("%.3f", offset),
+				"-t", fmt.Sprintf("%.3f", clipDuration),
+			}
+			args = append(args, vc.BuildFFmpegArgs(inputFile, probeFile, probeSettings)...)
+			// Probes only need video
+			args = append(args[:len(args)-1], "-an", probeFile)
+
+			vc.Logger.Debug("Encoding VMAF probe (CRF %d, clip %d): %s %s", crf, i, ffmpegPath, strings.Join(args, " "))
+			if _, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args); err != nil {
+				return 0, 0, nil, fmt.Errorf("failed to encode probe clip: %w", err)
+			}
+
+			score, err := vc.measureVMAF(runner, ffmpegPath, probeFile, inputFile, offset, clipDuration, referenceFilter(probeSettings))
+			if err != nil {
+				return 0, 0, nil, err
+			}
+			total += score
+		}
+
+		probe := VMAFProbe{CRF: crf, VMAF: total / float64(len(offsets))}
+		vc.Logger.Info("VMAF probe: CRF %d → %.2f", probe.CRF, probe.VMAF)
+		probes = append(probes, probe)
+	}
+
+	crf, predicted := pickCRFForTarget(probes, target, minCRF, maxCRF)
+	return crf, predicted, probes, nil
+}
+
+// vmafReferenceStages are the filter stages that change the size, frame
+// rate or colours of the probes. The source clip goes through them too, as
+// libvmaf compares frames of the same size and rate; denoising and the
+// other stages are left out, so their effect on quality is measured.
+var vmafReferenceStages = []ffmpeg.FilterStage{
+	ffmpeg.FilterDeinterlace, ffmpeg.FilterFPS, ffmpeg.FilterCrop, ffmpeg.FilterTonemap, ffmpeg.FilterScale,
+}
+
+// referenceFilter returns the filters of vmafReferenceStages in settings,
+// joined in chain order, or an empty string when there are none
+func referenceFilter(settings map[string]string) string {
+	var filters []string
+	for _, stage := range vmafReferenceStages {
+		if graph := ffmpeg.VideoFilter(settings, stage); graph != "" {
+			filters = append(filters, graph)
+		}
+	}
+	return strings.Join(filters, ",")
+}
+
+// measureVMAF scores an encoded probe against the matching clip of the
+// source, passed through refFilter to match the probe's frames
+func (vc *VideoCompressor) measureVMAF(runner ffmpeg.Runner, ffmpegPath, distorted, reference string, offset, duration float64, refFilter string) (float64, error) {
+	refChain := "setpts=PTS-STARTPTS"
+	if refFilter != "" {
+		refChain += "," + refFilter
+	}
+	args := []string{"-i", ffmpeg.FileArg(distorted)}
+	args = append(args, ffmpeg.RemoteInputArgs(reference)...)
+	args = append(args,
+		"-ss", fmt.Sprintf("%.3f", offset),
+		"-t", fmt.Sprintf("%.3f", duration),
+		"-i", ffmpeg.FileArg(reference),
+		"-lavfi", "[0:v]setpts=PTS-STARTPTS[dist];[1:v]"+refChain+"[ref];[dist][ref]libvmaf",
+		"-an",
+		"-f", "null",
+		"-",
+	)
+
+	vc.Logger.Debug("Measuring VMAF: %s %s", ffmpegPath, strings.Join(args, " "))
+	output, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args)
+	if err != nil {
+		return 0, fmt.Errorf("VMAF measurement failed: %w", err)
+	}
+
+	return parseVMAFScore(string(output))
+}
+
+// parseVMAFScore extracts the pooled VMAF score from FFmpeg's output
+func parseVMAFScore(output string) (float64, error) {
+	matches := vmafScoreRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return 0, fmt.Errorf("VMAF score not found in FFmpeg output")
+	}
+
+	// The pooled score is the last one printed
+	return strconv.ParseFloat(matches[len(matches)-1][1], 64)
+}
+
+// probeOffsets spreads probe clips evenly across the video, shortening them
+// for very short inputs
+func probeOffsets(duration float64, count int, clipDuration float64) ([]float64, float64) {
+	if duration <= clipDuration {
+		return []float64{0}, duration
+	}
+	if duration < clipDuration*float64(count) {
+		count = int(duration / clipDuration)
+	}
+
+	offsets := make([]float64, count)
+	for i := 0; i < count; i++ {
+		// Centre each clip in its slice of the video
+		slice := duration / float64(count)
+		offset := slice*float64(i) + (slice-clipDuration)/2
+		if offset < 0 {
+			offset = 0
+		}
+		offsets[i] = offset
+	}
+	return offsets, clipDuration
+}
+
+// crfRange returns the valid CRF range for a codec
+func crfRange(codec string) (int, int) {
+	if codec == "libvpx-vp9" {
+		return 0, 63
+	}
+	return 0, 51
+}
+
+// fitVMAFCurve fits VMAF = a + b*crf + c*crf² by least squares. A quadratic is
+// used when there are enough points and it stays monotonic over the probed
+// range; otherwise it falls back to a straight line.
+func fitVMAFCurve(probes []VMAFProbe) func(crf float64) float64 {
+	if len(probes) == 1 {
+		v := probes[0].VMAF
+		return func(float64) float64 { return v }
+	}
+
+	lo, hi := float64(probes[0].CRF), float64(probes[0].CRF)
+	for _, p := range probes {
+		lo = math.Min(lo, float64(p.CRF))
+		hi = math.Max(hi, float64(p.CRF))
+	}
+
+	if len(probes) >= 3 {
+		if a, b, c, ok := fitQuadratic(probes); ok {
+			// VMAF must not increase with CRF inside the probed range
+			if b+2*c*lo <= 0 && b+2*c*hi <= 0 {
+				return func(crf float64) float64 { return a + b*crf + c*crf*crf }
+			}
+		}
+	}
+
+	a, b := fitLinear(probes)
+	return func(crf float64) float64 { return a + b*crf }
+}
+
+// fitLinear returns the least-squares line through the probes
+func fitLinear(probes []VMAFProbe) (float64, float64) {
+	n := float64(len(probes))
+	var sx, sy, sxx, sxy float64
+	for _, p := range probes {
+		x := float64(p.CRF)
+		sx += x
+		sy += p.VMAF
+		sxx += x * x
+		sxy += x * p.VMAF
+	}
+
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return sy / n, 0
+	}
+	b := (n*sxy - sx*sy) / den
+	a := (sy - b*sx) / n
+	return a, b
+}
+
+// fitQuadratic solves the 3x3 normal equations for a second-degree fit
+func fitQuadratic(probes []VMAFProbe) (float64, float64, float64, bool) {
+	var s [5]float64 // sums of x^0..x^4
+	var t [3]float64 // sums of y*x^0..y*x^2
+	for _, p := range probes {
+		x := float64(p.CRF)
+		xp := 1.0
+		for i := 0; i < 5; i++ {
+			s[i] += xp
+			if i < 3 {
+				t[i] += p.VMAF * xp
+			}
+			xp *= x
+		}
+	}
+
+	m := [3][4]float64{
+		{s[0], s[1], s[2], t[0]},
+		{s[1], s[2], s[3], t[1]},
+		{s[2], s[3], s[4], t[2]},
+	}
+
+	// Gaussian elimination with partial pivoting
+	for col := 0; col < 3; col++ {
+		pivot := col
+		for row := col + 1; row < 3; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-9 {
+			return 0, 0, 0, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+
+		for row := col + 1; row < 3; row++ {
+			factor := m[row][col] / m[col][col]
+			for k := col; k < 4; k++ {
+				m[row][k] -= factor * m[col][k]
+			}
+		}
+	}
+
+	var coef [3]float64
+	for row := 2; row >= 0; row-- {
+		sum := m[row][3]
+		for k := row + 1; k < 3; k++ {
+			sum -= m[row][k] * coef[k]
+		}
+		coef[row] = sum / m[row][row]
+	}
+
+	return coef[0], coef[1], coef[2], true
+}
+
+// pickCRFForTarget returns the highest CRF whose fitted VMAF meets the target,
+// together with the predicted VMAF at that CRF. Extrapolation is limited to a
+// few steps beyond the probed range.
+func pickCRFForTarget(probes []VMAFProbe, target float64, minCRF, maxCRF int) (int, float64) {
+	curve := fitVMAFCurve(probes)
+
+	lo, hi := probes[0].CRF, probes[0].CRF
+	for _, p := range probes {
+		if p.CRF < lo {
+			lo = p.CRF
+		}
+		if p.CRF > hi {
+			hi = p.CRF
+		}
+	}
+
+	searchLo := lo - 2*vmafCRFStep
+	if searchLo < minCRF {
+		searchLo = minCRF
+	}
+	searchHi := hi + 2*vmafCRFStep
+	if searchHi > maxCRF {
+		searchHi = maxCRF
+	}
+
+	for crf := searchHi; crf >= searchLo; crf-- {
+		if predicted := curve(float64(crf)); predicted >= target {
+			return crf, predicted
+		}
+	}
+
+	// Even the lowest CRF we are willing to try misses the target
+	return searchLo, curve(float64(searchLo))
+}
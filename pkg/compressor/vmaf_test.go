@@ -0,0 +1,92 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseVMAFScore tests extracting the pooled score from FFmpeg output
+func TestParseVMAFScore(t *testing.T) {
+	output := `[Parsed_libvmaf_4 @ 0x55d0c8c0] VMAF score: 93.456789
+frame=  100 fps=25 q=-0.0 Lsize=N/A time=00:00:04.00`
+
+	score, err := parseVMAFScore(output)
+	assert.NoError(t, err)
+	assert.InDelta(t, 93.456789, score, 0.000001)
+
+	_, err = parseVMAFScore("frame=  100 fps=25")
+	assert.Error(t, err)
+}
+
+// TestProbeOffsets tests that probe clips stay inside the video
+func TestProbeOffsets(t *testing.T) {
+	offsets, clip := probeOffsets(120, 3, 4)
+	assert.Equal(t, 3, len(offsets))
+	assert.Equal(t, 4.0, clip)
+	for _, offset := range offsets {
+		assert.True(t, offset >= 0 && offset+clip <= 120)
+	}
+
+	// Shorter than a single clip: encode the whole thing once
+	offsets, clip = probeOffsets(2.5, 3, 4)
+	assert.Equal(t, []float64{0}, offsets)
+	assert.Equal(t, 2.5, clip)
+
+	// Only room for two clips
+	offsets, _ = probeOffsets(9, 3, 4)
+	assert.Equal(t, 2, len(offsets))
+}
+
+// TestPickCRFForTarget tests choosing a CRF from measured probes
+func TestPickCRFForTarget(t *testing.T) {
+	// Perfectly linear response: VMAF drops 1.5 points per CRF step
+	probes := []VMAFProbe{
+		{CRF: 19, VMAF: 97.0},
+		{CRF: 23, VMAF: 91.0},
+		{CRF: 27, VMAF: 85.0},
+	}
+
+	crf, predicted := pickCRFForTarget(probes, 93, 0, 51)
+	assert.Equal(t, 21, crf)
+	assert.True(t, predicted >= 93)
+
+	// A target that cannot be reached returns the lowest CRF searched
+	crf, _ = pickCRFForTarget(probes, 120, 0, 51)
+	assert.Equal(t, 11, crf)
+
+	// An easy target is capped by the extrapolation limit
+	crf, _ = pickCRFForTarget(probes, 10, 0, 51)
+	assert.Equal(t, 35, crf)
+}
+
+// TestMeasureVMAFFilters tests that the source clip is cropped, scaled and
+// resampled like the probe before libvmaf compares them
+func TestMeasureVMAFFilters(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError)
+	runner := &fakeRunner{stderr: "[Parsed_libvmaf_4 @ 0x1] VMAF score: 94.500000\n"}
+	ff := ffmpeg.NewFFmpeg("in.mp4", "out.mp4", nil, logger)
+	ff.Runner = runner
+	vc := NewVideoCompressor(ff, nil, logger)
+
+	settings := map[string]string{"codec": "libx264"}
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterFPS, "fps=30"))
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterCrop, "crop=1920:800:0:140"))
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterDenoise, "hqdn3d"))
+	assert.NoError(t, ffmpeg.SetVideoFilter(settings, ffmpeg.FilterScale, "scale=-2:720"))
+	refFilter := referenceFilter(settings)
+	assert.Equal(t, "fps=30,crop=1920:800:0:140,scale=-2:720", refFilter)
+
+	score, err := vc.measureVMAF(runner, "ffmpeg", "probe.mp4", "in.mp4", 10, 2, refFilter)
+	assert.NoError(t, err)
+	assert.InDelta(t, 94.5, score, 0.0001)
+	assert.Contains(t, runner.calls[0], "[0:v]setpts=PTS-STARTPTS[dist];[1:v]setpts=PTS-STARTPTS,fps=30,crop=1920:800:0:140,scale=-2:720[ref];[dist][ref]libvmaf")
+
+	// Without filters the source is compared as it is
+	_, err = vc.measureVMAF(runner, "ffmpeg", "probe.mp4", "in.mp4", 10, 2, referenceFilter(map[string]string{}))
+	assert.NoError(t, err)
+	assert.Contains(t, runner.calls[1], "[0:v]setpts=PTS-STARTPTS[dist];[1:v]setpts=PTS-STARTPTS[ref];[dist][ref]libvmaf")
+}
@@ -0,0 +1,33 @@
+package compressor
+
+// vp9CPUUsed maps x264-style speed presets to libvpx -cpu-used values
+var vp9CPUUsed = map[string]string{
+	"placebo":   "0",
+	"veryslow":  "0",
+	"slower":    "1",
+	"slow":      "1",
+	"medium":    "2",
+	"fast":      "3",
+	"faster":    "3",
+	"veryfast":  "4",
+	"superfast": "5",
+	"ultrafast": "5",
+}
+
+// vp9Args returns the libvpx-vp9 speed and threading arguments
+func vp9Args(settings map[string]string) []string {
+	cpuUsed, ok := vp9CPUUsed[settings["preset"]]
+	if !ok {
+		cpuUsed = vp9CPUUsed["medium"]
+	}
+	args := []string{"-deadline", "good", "-cpu-used", cpuUsed}
+
+	if settings["row_mt"] != "" {
+		args = append(args, "-row-mt", settings["row_mt"])
+	}
+	if settings["tile_columns"] != "" {
+		args = append(args, "-tile-columns", settings["tile_columns"])
+	}
+	// Alternate reference frames with a long lag give VP9 most of its efficiency
+	return append(args, "-frame-parallel", "0", "-auto-alt-ref", "1", "-lag-in-frames", "25")
+}
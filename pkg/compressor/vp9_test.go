@@ -0,0 +1,24 @@
+package compressor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVP9Args(t *testing.T) {
+	settings := map[string]string{"codec": "libvpx-vp9", "crf": "33", "bitrate": "0", "preset": "slow", "row_mt": "1", "tile_columns": "2"}
+	assert.Equal(t, []string{"-deadline", "good", "-cpu-used", "1", "-row-mt", "1", "-tile-columns", "2",
+		"-frame-parallel", "0", "-auto-alt-ref", "1", "-lag-in-frames", "25"}, vp9Args(settings))
+
+	vc := &VideoCompressor{}
+	args := vc.BuildFFmpegArgs("in.mp4", "out.mp4", settings)
+	assert.NotContains(t, args, "-preset")
+	assert.Equal(t, []string{"-b:v", "0", "out.mp4"}, args[len(args)-3:])
+
+	// Falling back from VP9 restores the H.264 CRF scale
+	switchEncoder(settings, "libvpx-vp9", "libx265")
+	assert.Equal(t, "23", settings["crf"])
+	assert.NotContains(t, settings, "bitrate")
+	assert.NotContains(t, settings, "tile_columns")
+}
@@ -0,0 +1,161 @@
+// Package config loads user defaults from the CompressVideo config file
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings read from ~/.compressvideo/config.yaml
+type Config struct {
+	Notify   NotifyConfig        `yaml:"notify"`
+	FFmpeg   FFmpegConfig        `yaml:"ffmpeg"`
+	Profiles map[string]Profile  `yaml:"profiles"`
+	Analysis AnalysisConfig      `yaml:"analysis"`
+	Hooks    map[string][]string `yaml:"hooks"`  // Commands run at each hook point, e.g. after_encode
+	Routes   []Route             `yaml:"routes"` // Where outputs go by what the analysis found
+}
+
+// NotifyConfig configures completion notifications
+type NotifyConfig struct {
+	URL     string `yaml:"url"`     // Webhook URL (Slack, Discord or generic HTTP)
+	Desktop bool   `yaml:"desktop"` // Show a desktop notification
+}
+
+// FFmpegConfig pins the FFmpeg build that is downloaded when FFmpeg is missing
+type FFmpegConfig struct {
+	URL    string `yaml:"url"`    // Archive to download instead of the default mirrors
+	SHA256 string `yaml:"sha256"` // Expected SHA-256 of the archive
+}
+
+// AnalysisConfig overrides the tunables of the content analysis; zero values
+// keep the defaults
+type AnalysisConfig struct {
+	SceneThreshold     float64 `yaml:"scene_threshold"`     // Scene score (0-1) that counts as a scene change
+	ComplexityInterval float64 `yaml:"complexity_interval"` // Seconds between the frames sampled for complexity
+	MotionCutoffs      string  `yaml:"motion_cutoffs"`      // e.g. 2:200,5:500,10:1000
+}
+
+// Profile is a named set of compression settings, used with --profile and
+// created by 'compressvideo wizard'
+type Profile struct {
+	Quality       int     `yaml:"quality,omitempty"`        // 1-5
+	Preset        string  `yaml:"preset,omitempty"`         // fast, balanced, thorough
+	Codec         string  `yaml:"codec,omitempty"`          // auto, h264, hevc or vp9
+	ScreencastROI bool    `yaml:"screencast_roi,omitempty"` // Tune static screen areas of screencasts
+	Routes        []Route `yaml:"routes,omitempty"`         // Checked before the routes of the config file
+}
+
+// Route sends the outputs of the videos it matches to another directory once
+// the analysis has classified them. Each condition left empty matches every
+// video.
+type Route struct {
+	Content   string `yaml:"content,omitempty"`    // Content type, e.g. screencast or live_action
+	MinHeight int    `yaml:"min_height,omitempty"` // Fewest lines of the picture, e.g. 2160 for 4K
+	MaxHeight int    `yaml:"max_height,omitempty"` // Most lines of the picture
+	Output    string `yaml:"output"`               // Directory the outputs are written to; ~ is the home directory
+}
+
+// DefaultPath returns the location of the user config file
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".compressvideo", "config.yaml")
+}
+
+// Load reads the config file at path. A missing file is not an error and
+// yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveProfile adds the profile name to the config file at path, replacing
+// a profile of the same name. The rest of the file, comments included, is
+// kept; a missing file is created.
+func SaveProfile(path, name string, profile Profile) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to parse config file %s: not a mapping", path)
+	}
+
+	var value yaml.Node
+	if err := value.Encode(profile); err != nil {
+		return err
+	}
+	setMappingValue(mappingValue(root, "profiles"), name, &value)
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// mappingValue returns the mapping under key in mapping, adding an empty one
+// when the key is missing or has no mapping
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			value := mapping.Content[i+1]
+			if value.Kind != yaml.MappingNode {
+				*value = yaml.Node{Kind: yaml.MappingNode}
+			}
+			return value
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode}
+	setMappingValue(mapping, key, value)
+	return value
+}
+
+// setMappingValue sets key in mapping to value
+func setMappingValue(mapping *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content[i+1] = value
+			return
+		}
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
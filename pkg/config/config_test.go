@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := "notify:\n  url: https://example.com/hook\n  desktop: true\n" +
+		"ffmpeg:\n  url: https://example.com/ffmpeg.tar.xz\n  sha256: abc123\n" +
+		"analysis:\n  scene_threshold: 0.25\n  motion_cutoffs: 3:250,6:600,12:1200\n" +
+		"hooks:\n  after_encode:\n    - ./upload.sh\n    - ./tag.sh\n" +
+		"routes:\n  - content: screencast\n    output: ~/Videos/screencasts\n  - min_height: 2160\n    output: /mnt/big/4k\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", cfg.Notify.URL)
+	assert.True(t, cfg.Notify.Desktop)
+	assert.Equal(t, "https://example.com/ffmpeg.tar.xz", cfg.FFmpeg.URL)
+	assert.Equal(t, "abc123", cfg.FFmpeg.SHA256)
+	assert.Equal(t, AnalysisConfig{SceneThreshold: 0.25, MotionCutoffs: "3:250,6:600,12:1200"}, cfg.Analysis)
+	assert.Equal(t, map[string][]string{"after_encode": {"./upload.sh", "./tag.sh"}}, cfg.Hooks)
+	assert.Equal(t, []Route{{Content: "screencast", Output: "~/Videos/screencasts"}, {MinHeight: 2160, Output: "/mnt/big/4k"}}, cfg.Routes)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.NoError(t, err)
+	assert.Equal(t, "", cfg.Notify.URL)
+}
+
+func TestLoadInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("notify: [unclosed"), 0644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
+
+func TestSaveProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".compressvideo", "config.yaml")
+
+	// A missing file is created
+	assert.NoError(t, SaveProfile(path, "phone", Profile{Quality: 2, Preset: "fast", Codec: "hevc"}))
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{Quality: 2, Preset: "fast", Codec: "hevc"}, cfg.Profiles["phone"])
+
+	// Other settings and comments are kept, and profiles of the same name replaced
+	content := "# Team defaults\nnotify:\n  url: https://example.com/hook\nprofiles:\n  old:\n    quality: 5\n"
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	assert.NoError(t, SaveProfile(path, "screen", Profile{Quality: 3, Preset: "balanced", ScreencastROI: true}))
+	assert.NoError(t, SaveProfile(path, "old", Profile{Quality: 4}))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "# Team defaults")
+	cfg, err = Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/hook", cfg.Notify.URL)
+	assert.Equal(t, Profile{Quality: 4}, cfg.Profiles["old"])
+	assert.Equal(t, Profile{Quality: 3, Preset: "balanced", ScreencastROI: true}, cfg.Profiles["screen"])
+}
+
+func TestSaveProfileInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("- a list"), 0644))
+	assert.Error(t, SaveProfile(path, "phone", Profile{Quality: 2}))
+}
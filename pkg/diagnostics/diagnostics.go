@@ -0,0 +1,236 @@
+// Package diagnostics writes the bundles users attach to bug reports when
+// CompressVideo crashes or FFmpeg fails for no known reason
+package diagnostics
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// IssuesURL is where users report problems
+const IssuesURL = "https://github.com/cccarv82/compressvideo/issues"
+
+// MaxOutputLines is how many of the last lines of FFmpeg output a bundle keeps
+const MaxOutputLines = 200
+
+// maxBundles is how many bundles are kept in the diagnostics directory, so
+// a batch of failing files does not fill it up
+const maxBundles = 20
+
+// secretFlags hold values that must not end up in a shared bundle, besides
+// the flags whose name has one of secretWords
+var secretFlags = []string{"--notify-url"}
+
+// secretWords mark flags that hold credentials, such as --telegram-token
+var secretWords = []string{"token", "auth", "password"}
+
+// Bundle describes a crash or an FFmpeg failure
+type Bundle struct {
+	Time          time.Time
+	Reason        string            // Panic value or error message
+	Stack         string            // Stack trace of a panic, empty for FFmpeg failures
+	Args          []string          // Command line, redacted when written
+	Input         string            // File being processed, empty before one is
+	Settings      map[string]string // Encoder settings of the file
+	Probe         string            // ffprobe JSON of the input
+	FFmpegOutput  string            // What FFmpeg printed before failing
+	FFmpegPath    string
+	FFmpegVersion string
+}
+
+// Dir returns the directory bundles are written to
+func Dir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = os.TempDir()
+	}
+	return filepath.Join(homeDir, ".compressvideo", "diagnostics")
+}
+
+// Write saves the bundle as a new text file in dir and returns its path.
+// The oldest bundles are removed beyond the last 20.
+func (b *Bundle) Write(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create diagnostics directory: %w", err)
+	}
+	file, err := os.CreateTemp(dir, "diagnostics-"+b.Time.Format("20060102-150405")+"-*.txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create diagnostic bundle: %w", err)
+	}
+	if _, err := b.WriteTo(file); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write diagnostic bundle: %w", err)
+	}
+
+	prune(dir, maxBundles)
+	return file.Name(), nil
+}
+
+// WriteTo writes the bundle as text, one section per kind of information.
+// Sections without information are left out.
+func (b *Bundle) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s diagnostic bundle\n", util.AppName)
+	fmt.Fprintf(&sb, "Created: %s\n", b.Time.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&sb, "Reason: %s\n", b.Reason)
+
+	ffmpeg := "not found"
+	if b.FFmpegPath != "" {
+		ffmpeg = fmt.Sprintf("%s (%s)", b.FFmpegVersion, b.FFmpegPath)
+	}
+	build := util.GetBuildInfo()
+	section(&sb, "Environment", fmt.Sprintf("Version: %s\nGo: %s\nPlatform: %s\nCPUs: %d\nLanguage: %s\nFFmpeg: %s",
+		build, build.GoVersion, build.Platform, runtime.NumCPU(), i18n.Language(), ffmpeg))
+	section(&sb, "Command Line", commandLine(RedactArgs(b.Args)))
+	section(&sb, "Input", b.Input)
+	section(&sb, "Settings", formatSettings(b.Settings))
+	section(&sb, "ffprobe", strings.TrimSpace(b.Probe))
+	section(&sb, fmt.Sprintf("FFmpeg Output (last %d lines)", MaxOutputLines), LastLines(b.FFmpegOutput, MaxOutputLines))
+	section(&sb, "Stack Trace", strings.TrimSpace(b.Stack))
+
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// section adds a titled section unless content is empty
+func section(sb *strings.Builder, title, content string) {
+	if content == "" {
+		return
+	}
+	fmt.Fprintf(sb, "\n== %s ==\n%s\n", title, content)
+}
+
+// formatSettings lists settings as sorted key=value lines
+func formatSettings(settings map[string]string) string {
+	lines := make([]string, 0, len(settings))
+	for key, value := range settings {
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// commandLine joins args into a command line, quoting the ones with spaces
+func commandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// RedactArgs returns args without secrets: the values of flags such as
+// --notify-url are replaced, and credentials and query strings are removed
+// from URLs
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	secretValue := false
+	for i, arg := range args {
+		switch {
+		case secretValue:
+			arg = "<redacted>"
+			secretValue = false
+		case isSecretFlag(arg):
+			if name, _, ok := strings.Cut(arg, "="); ok {
+				arg = name + "=<redacted>"
+			} else {
+				secretValue = true
+			}
+		default:
+			arg = redactURL(arg)
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
+
+// isSecretFlag reports whether arg is one of secretFlags or a flag whose
+// name has one of secretWords, with or without an =value
+func isSecretFlag(arg string) bool {
+	name, _, _ := strings.Cut(arg, "=")
+	if !strings.HasPrefix(name, "--") {
+		return false
+	}
+	for _, flag := range secretFlags {
+		if name == flag {
+			return true
+		}
+	}
+	name = strings.ToLower(name)
+	for _, word := range secretWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactURL removes the user information and query string of a URL, where
+// signed URLs and streaming services keep credentials
+func redactURL(arg string) string {
+	if !strings.Contains(arg, "://") {
+		return arg
+	}
+	u, err := url.Parse(arg)
+	if err != nil || u.Host == "" {
+		return arg
+	}
+	if u.User != nil {
+		u.User = url.User("redacted")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	return u.String()
+}
+
+// LastLines returns the last n lines of output, without FFmpeg's progress
+// lines, which are rewritten in place with carriage returns
+func LastLines(output string, n int) string {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		// Only the last state of a line rewritten with \r is kept
+		if i := strings.LastIndex(line, "\r"); i >= 0 {
+			line = line[i+1:]
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// prune removes the oldest bundles in dir beyond keep
+func prune(dir string, keep int) {
+	bundles, err := filepath.Glob(filepath.Join(dir, "diagnostics-*.txt"))
+	if err != nil || len(bundles) <= keep {
+		return
+	}
+	// Names start with the creation time, so they sort oldest first
+	sort.Strings(bundles)
+	for _, path := range bundles[:len(bundles)-keep] {
+		os.Remove(path)
+	}
+}
@@ -0,0 +1,70 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a command-line fragment into arguments the way a POSIX
+// shell would, without expanding anything: whitespace separates arguments,
+// single quotes keep their contents literally, double quotes keep spaces
+// and a backslash escapes the character after it. It fails on an
+// unterminated quote or a trailing backslash.
+func SplitArgs(s string) ([]string, error) {
+	var args []string
+	var arg strings.Builder
+	inArg := false // Whether an argument has started, so '' gives an empty one
+	var quote rune // Open quote character, 0 outside quotes
+	escaped := false
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			// Inside double quotes only the characters a shell escapes there
+			// lose their backslash
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				arg.WriteRune('\\')
+			}
+			arg.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash in %q", s)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, s)
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,32 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitArgs(t *testing.T) {
+	for input, expected := range map[string][]string{
+		"":                                   nil,
+		"   ":                                nil,
+		"-movflags +faststart":               {"-movflags", "+faststart"},
+		"  -g  48\t-bf 3\n":                  {"-g", "48", "-bf", "3"},
+		`-vf "unsharp=5:5:0.8, hqdn3d"`:      {"-vf", "unsharp=5:5:0.8, hqdn3d"},
+		`-metadata 'title=It "works"'`:       {"-metadata", `title=It "works"`},
+		`-metadata title=It\'s\ here`:        {"-metadata", "title=It's here"},
+		`-metadata "comment=a \"b\" \\ c\d"`: {"-metadata", `comment=a "b" \ c\d`},
+		`-metadata comment=''`:               {"-metadata", "comment="},
+		`-metadata ''`:                       {"-metadata", ""},
+		`-x265-params 'a=1:b=2'"c"`:          {"-x265-params", "a=1:b=2c"},
+	} {
+		args, err := SplitArgs(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, args, input)
+	}
+
+	for _, input := range []string{`-vf "scale=1280:-2`, `-metadata 'title`, `-g 48 \`} {
+		_, err := SplitArgs(input)
+		assert.Error(t, err, input)
+	}
+}
@@ -0,0 +1,61 @@
+package ffmpeg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseRatio reads a ratio such as 64:45 or 16/9. Unknown ratios, which
+// FFprobe prints as 0:1 or N/A, are 0.
+func parseRatio(ratio string) float64 {
+	num, den, ok := strings.Cut(ratio, ":")
+	if !ok {
+		num, den, ok = strings.Cut(ratio, "/")
+	}
+	if !ok {
+		return 0
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return n / d
+}
+
+// knownRatio returns ratio, or an empty string when it is unknown
+func knownRatio(ratio string) string {
+	if parseRatio(ratio) == 0 {
+		return ""
+	}
+	return ratio
+}
+
+// SampleAspectRatio returns the width of a pixel relative to its height:
+// 1 for square pixels or when the ratio is unknown
+func (v VideoStreamInfo) SampleAspectRatio() float64 {
+	if sar := parseRatio(v.SAR); sar > 0 {
+		return sar
+	}
+	return 1
+}
+
+// IsAnamorphic reports whether the video is stored with non-square pixels,
+// as DV and DVD video are, and only looks right when players stretch it to
+// its display aspect ratio
+func (v VideoStreamInfo) IsAnamorphic() bool {
+	return math.Abs(v.SampleAspectRatio()-1) > 0.001
+}
+
+// DisplayWidth returns the width the video is shown at, rounded to an even
+// number of square pixels, e.g. 854 for 720x480 video at 16:9
+func (v VideoStreamInfo) DisplayWidth() int {
+	if !v.IsAnamorphic() {
+		return v.Width
+	}
+	return int(math.Round(float64(v.Width)*v.SampleAspectRatio()/2)) * 2
+}
@@ -0,0 +1,37 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRatio(t *testing.T) {
+	assert.InDelta(t, 64.0/45, parseRatio("64:45"), 1e-9)
+	assert.InDelta(t, 16.0/9, parseRatio("16/9"), 1e-9)
+	for _, ratio := range []string{"", "0:1", "N/A", "1:0", "16"} {
+		assert.Zero(t, parseRatio(ratio), ratio)
+		assert.Empty(t, knownRatio(ratio), ratio)
+	}
+}
+
+func TestAnamorphicVideo(t *testing.T) {
+	// NTSC DVD at 16:9
+	dvd := VideoStreamInfo{Width: 720, Height: 480, SAR: "32:27", DAR: "16:9"}
+	assert.True(t, dvd.IsAnamorphic())
+	assert.Equal(t, 854, dvd.DisplayWidth())
+
+	// PAL DV at 4:3
+	dv := VideoStreamInfo{Width: 720, Height: 576, SAR: "16:15", DAR: "4:3"}
+	assert.True(t, dv.IsAnamorphic())
+	assert.Equal(t, 768, dv.DisplayWidth())
+
+	for _, square := range []VideoStreamInfo{
+		{Width: 1920, Height: 1080, SAR: "1:1", DAR: "16:9"},
+		{Width: 1920, Height: 1080},
+	} {
+		assert.False(t, square.IsAnamorphic())
+		assert.Equal(t, 1920, square.DisplayWidth())
+		assert.Equal(t, 1.0, square.SampleAspectRatio())
+	}
+}
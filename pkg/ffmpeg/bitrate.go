@@ -0,0 +1,89 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// VideoBitrates reads the size of every packet of the first video stream
+// of filePath, without decoding, and returns the bitrate of each second of
+// video in bits per second. Remote inputs are only sampled, see
+// RemoteSampleSeconds.
+func (f *FFmpeg) VideoBitrates(filePath string) ([]int64, error) {
+	f.Logger.Debug("Measuring the bitrate of each second of: %s", filePath)
+	runner := f.runner()
+	ffprobePath, err := runner.ProbePath()
+	if err != nil {
+		return nil, err
+	}
+	if ffprobePath == "" {
+		return nil, fmt.Errorf("ffprobe not found")
+	}
+
+	args := []string{
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "packet=pts_time,dts_time,size",
+		"-of", "csv=p=0",
+	}
+	if IsRemote(filePath) {
+		args = append(args, "-read_intervals", "%+"+strconv.Itoa(RemoteSampleSeconds))
+	}
+	args = append(args, RemoteInputArgs(filePath)...)
+	output, err := Output(runner, ffprobePath, append(args, FileArg(filePath)))
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	bitrates := parsePacketBitrates(output)
+	if len(bitrates) == 0 {
+		return nil, fmt.Errorf("no video packets found")
+	}
+	return bitrates, nil
+}
+
+// parsePacketBitrates sums the packets of the ffprobe CSV lines
+// pts_time,dts_time,size into the bits of each second from the first
+// packet. Packets without a presentation time are placed at their decoding
+// time. The last second is left out when it is not the only one, since it
+// is rarely whole and would show as a dip.
+func parsePacketBitrates(output []byte) []int64 {
+	type packet struct {
+		time float64
+		size int64
+	}
+	var packets []packet
+	start := math.Inf(1)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ",")
+		if len(fields) < 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			continue
+		}
+		time, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			if time, err = strconv.ParseFloat(fields[1], 64); err != nil {
+				continue
+			}
+		}
+		packets = append(packets, packet{time, size})
+		start = math.Min(start, time)
+	}
+
+	var bitrates []int64
+	for _, p := range packets {
+		second := int(p.time - start)
+		for len(bitrates) <= second {
+			bitrates = append(bitrates, 0)
+		}
+		bitrates[second] += p.size * 8
+	}
+	if len(bitrates) > 1 {
+		bitrates = bitrates[:len(bitrates)-1]
+	}
+	return bitrates
+}
@@ -0,0 +1,25 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePacketBitrates(t *testing.T) {
+	// Seconds count from the first packet; B-frames come out of order and
+	// packets without a presentation time use their decoding time
+	output := []byte(`10.000000,9.960000,50000
+10.080000,10.000000,1000
+10.040000,10.040000,1000
+N/A,10.500000,500
+11.200000,11.100000,20000
+12.100000,12.000000,30000
+12.500000,12.500000,garbled
+`)
+	assert.Equal(t, []int64{(50000 + 1000 + 1000 + 500) * 8, 20000 * 8}, parsePacketBitrates(output))
+
+	// A video shorter than a second keeps its only second
+	assert.Equal(t, []int64{8000}, parsePacketBitrates([]byte("0.000000,0.000000,1000\n")))
+	assert.Empty(t, parsePacketBitrates([]byte("")))
+}
@@ -0,0 +1,164 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+var (
+	capabilitiesMu    sync.Mutex
+	capabilitiesCache = map[string]*Capabilities{} // Capabilities per FFmpeg binary path
+)
+
+// Capabilities describes what an FFmpeg build can do, from its -version,
+// -encoders and -filters output
+type Capabilities struct {
+	Version       string
+	Configuration []string // Options FFmpeg was built with, such as --enable-libvmaf
+	Encoders      map[string]bool
+	Filters       map[string]bool
+}
+
+// Feature is an optional feature that needs an encoder or filter compiled
+// into FFmpeg
+type Feature struct {
+	Name    string // Shown to the user, e.g. "VMAF scoring"
+	Encoder string // Encoder the feature needs, if any
+	Filter  string // Filter the feature needs, if any
+	Hint    string // What to do instead, e.g. "use --codec hevc"
+}
+
+// Optional features gated on the FFmpeg build
+var (
+	FeatureVMAF     = Feature{Name: "VMAF scoring", Filter: "libvmaf", Hint: "run without --target-vmaf"}
+	FeatureSVTAV1   = Feature{Name: "SVT-AV1 encoding", Encoder: "libsvtav1", Hint: "use --codec hevc"}
+	FeatureLoudnorm = Feature{Name: "two-pass loudness normalization", Filter: "loudnorm", Hint: "leave the audio loudness unchanged"}
+	FeatureTonemap  = Feature{Name: "HDR tone mapping", Filter: "zscale", Hint: "run without --tonemap"}
+)
+
+// Features lists the optional features, as checked by the doctor command
+var Features = []Feature{FeatureVMAF, FeatureSVTAV1, FeatureLoudnorm, FeatureTonemap}
+
+// requirement returns the encoder or filter the feature needs
+func (feature Feature) requirement() string {
+	if feature.Encoder != "" {
+		return feature.Encoder
+	}
+	return feature.Filter
+}
+
+// MissingFeatureError reports an FFmpeg build that lacks what a feature needs
+type MissingFeatureError struct {
+	Version string
+	Feature Feature
+}
+
+func (e *MissingFeatureError) Error() string {
+	return i18n.T("your FFmpeg %s lacks %s; run repair-ffmpeg or %s", e.Version, e.Feature.requirement(), i18n.Tr(e.Feature.Hint))
+}
+
+// Has reports whether the build includes what feature needs
+func (c *Capabilities) Has(feature Feature) bool {
+	if feature.Encoder != "" && !c.Encoders[feature.Encoder] {
+		return false
+	}
+	if feature.Filter != "" && !c.Filters[feature.Filter] {
+		return false
+	}
+	return true
+}
+
+// Require returns a MissingFeatureError when the build lacks what feature
+// needs
+func (c *Capabilities) Require(feature Feature) error {
+	if c.Has(feature) {
+		return nil
+	}
+	return &MissingFeatureError{Version: c.Version, Feature: feature}
+}
+
+// Capabilities returns what the FFmpeg build can do, probed once per binary
+// and cached for the process
+func (f *FFmpeg) Capabilities() (*Capabilities, error) {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return nil, err
+	}
+	return ProbeCapabilities(runner, ffmpegPath)
+}
+
+// ProbeCapabilities runs "ffmpeg -version", "-encoders" and "-filters"
+// unless the result is already cached
+func ProbeCapabilities(runner Runner, ffmpegPath string) (*Capabilities, error) {
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+
+	if capabilities, ok := capabilitiesCache[ffmpegPath]; ok {
+		return capabilities, nil
+	}
+
+	output, err := Output(runner, ffmpegPath, []string{"-hide_banner", "-version"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the FFmpeg version: %w", err)
+	}
+	capabilities := parseVersionOutput(string(output))
+
+	if capabilities.Encoders, err = probeEncoders(runner, ffmpegPath); err != nil {
+		return nil, err
+	}
+
+	output, err = Output(runner, ffmpegPath, []string{"-hide_banner", "-filters"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FFmpeg filters: %w", err)
+	}
+	capabilities.Filters = parseFilterList(string(output))
+
+	capabilitiesCache[ffmpegPath] = capabilities
+	return capabilities, nil
+}
+
+// parseVersionOutput reads the version and build configuration from
+// "ffmpeg -version" output:
+//
+//	ffmpeg version 6.1.1 Copyright (c) 2000-2023 the FFmpeg developers
+//	built with gcc 13 (GCC)
+//	configuration: --prefix=/usr --enable-gpl --enable-libx264
+func parseVersionOutput(output string) *Capabilities {
+	capabilities := &Capabilities{Version: "Unknown"}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "ffmpeg version "); ok {
+			if fields := strings.Fields(rest); len(fields) > 0 {
+				capabilities.Version = fields[0]
+			}
+		}
+		if rest, ok := strings.CutPrefix(line, "configuration:"); ok {
+			capabilities.Configuration = strings.Fields(rest)
+		}
+	}
+	return capabilities
+}
+
+// parseFilterList reads the filter names from "ffmpeg -filters" output:
+//
+//	Filters:
+//	  T.. = Timeline support
+//	  ...
+//	 ... libvmaf           VV->V      Calculate the VMAF between two video streams.
+func parseFilterList(output string) map[string]bool {
+	filters := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && strings.Contains(fields[2], "->") {
+			filters[fields[1]] = true
+		}
+	}
+	return filters
+}
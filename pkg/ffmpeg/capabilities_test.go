@@ -0,0 +1,55 @@
+package ffmpeg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVersionOutput(t *testing.T) {
+	output := `ffmpeg version 4.2.7-0ubuntu0.1 Copyright (c) 2000-2022 the FFmpeg developers
+built with gcc 9 (Ubuntu 9.4.0-1ubuntu1~20.04.1)
+configuration: --prefix=/usr --enable-gpl --enable-libx264 --enable-libx265
+libavutil      56. 31.100 / 56. 31.100
+`
+	capabilities := parseVersionOutput(output)
+	assert.Equal(t, "4.2.7-0ubuntu0.1", capabilities.Version)
+	assert.Equal(t, []string{"--prefix=/usr", "--enable-gpl", "--enable-libx264", "--enable-libx265"}, capabilities.Configuration)
+
+	assert.Equal(t, "Unknown", parseVersionOutput("").Version)
+}
+
+func TestParseFilterList(t *testing.T) {
+	output := `Filters:
+  T.. = Timeline support
+  .S. = Slice threading
+  ..C = Command support
+  A = Audio input/output
+  | = Source or sink filter
+ ... abench            A->A       Benchmark part of an audio graph.
+ T.C loudnorm          A->A       EBU R128 loudness normalization
+ TS. libvmaf           VV->V      Calculate the VMAF between two video streams.
+`
+	filters := parseFilterList(output)
+	assert.Len(t, filters, 3)
+	assert.True(t, filters["libvmaf"])
+	assert.True(t, filters["loudnorm"])
+	assert.False(t, filters["="])
+}
+
+func TestCapabilitiesRequire(t *testing.T) {
+	capabilities := &Capabilities{
+		Version:  "4.2.7",
+		Encoders: map[string]bool{"libx264": true, "libx265": true},
+		Filters:  map[string]bool{"loudnorm": true},
+	}
+	assert.True(t, capabilities.Has(FeatureLoudnorm))
+	assert.NoError(t, capabilities.Require(FeatureLoudnorm))
+	assert.False(t, capabilities.Has(FeatureVMAF))
+
+	err := capabilities.Require(FeatureSVTAV1)
+	assert.EqualError(t, err, "your FFmpeg 4.2.7 lacks libsvtav1; run repair-ffmpeg or use --codec hevc")
+	var missing *MissingFeatureError
+	assert.True(t, errors.As(err, &missing))
+}
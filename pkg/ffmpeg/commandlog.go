@@ -0,0 +1,198 @@
+package ffmpeg
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLoggedStderr is how much of the end of each command's standard error
+// the command log keeps
+const maxLoggedStderr = 4096
+
+// maxCommandLogs is how many command logs are kept in the log directory
+const maxCommandLogs = 20
+
+// commandLog is the log every command run by ExecRunner is recorded to;
+// its file is nil when commands are not logged
+var commandLog struct {
+	mu    sync.Mutex
+	file  *os.File
+	count int // Commands recorded so far
+}
+
+// CommandLogDir returns the directory command logs are written to
+func CommandLogDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		homeDir = "."
+	}
+	return filepath.Join(homeDir, ".compressvideo", "logs")
+}
+
+// StartCommandLog records every FFmpeg and FFprobe command run from now on,
+// with its duration, exit code and the end of its standard error, to a new
+// file in dir, and returns its path. The oldest logs are removed beyond the
+// last 20.
+func StartCommandLog(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+	name := fmt.Sprintf("commands-%s-%d.log", time.Now().Format("20060102-150405"), os.Getpid())
+	// Command lines can hold URLs with credentials
+	file, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return "", fmt.Errorf("failed to create command log: %w", err)
+	}
+	fmt.Fprintf(file, "# %s\n", strings.Join(quoteArgs(os.Args), " "))
+
+	commandLog.mu.Lock()
+	if commandLog.file != nil {
+		commandLog.file.Close()
+	}
+	commandLog.file = file
+	commandLog.count = 0
+	commandLog.mu.Unlock()
+
+	pruneCommandLogs(dir, maxCommandLogs)
+	return file.Name(), nil
+}
+
+// StopCommandLog stops recording commands and closes the log
+func StopCommandLog() error {
+	commandLog.mu.Lock()
+	defer commandLog.mu.Unlock()
+	if commandLog.file == nil {
+		return nil
+	}
+	err := commandLog.file.Close()
+	commandLog.file = nil
+	return err
+}
+
+// commandLogging reports whether commands are being recorded
+func commandLogging() bool {
+	commandLog.mu.Lock()
+	defer commandLog.mu.Unlock()
+	return commandLog.file != nil
+}
+
+// logCommand records a command that ran for duration and ended with err
+func logCommand(path string, args []string, start time.Time, duration time.Duration, err error, stderr string) {
+	commandLog.mu.Lock()
+	defer commandLog.mu.Unlock()
+	if commandLog.file == nil {
+		return
+	}
+	commandLog.count++
+
+	var entry strings.Builder
+	fmt.Fprintf(&entry, "\n[%s] #%d %s after %s\n", start.Format(time.RFC3339), commandLog.count,
+		exitStatus(err), duration.Round(time.Millisecond))
+	fmt.Fprintf(&entry, "$ %s\n", strings.Join(quoteArgs(append([]string{path}, args...)), " "))
+	if stderr = strings.TrimSpace(progressLinesRemoved(stderr)); stderr != "" {
+		entry.WriteString(stderr)
+		entry.WriteString("\n")
+	}
+	io.WriteString(commandLog.file, entry.String())
+}
+
+// progressLinesRemoved keeps only the last state of each line FFmpeg
+// rewrites in place with carriage returns, such as its progress line
+func progressLinesRemoved(output string) string {
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.LastIndex(line, "\r"); j >= 0 {
+			lines[i] = line[j+1:]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exitStatus describes how a command ended: its exit code, or why it did
+// not run to completion
+func exitStatus(err error) string {
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return "exit 0"
+	case errors.As(err, &exitErr) && exitErr.ExitCode() >= 0:
+		return fmt.Sprintf("exit %d", exitErr.ExitCode())
+	default:
+		return fmt.Sprintf("failed (%v)", err)
+	}
+}
+
+// quoteArgs quotes the arguments a POSIX shell would split or expand, so
+// a logged command line can be pasted into a terminal
+func quoteArgs(args []string) []string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\$`;&|<>()*?[]{}!#~") {
+			arg = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+		quoted[i] = arg
+	}
+	return quoted
+}
+
+// tailWriter keeps the last max bytes written to it
+type tailWriter struct {
+	mu   sync.Mutex
+	buf  []byte
+	max  int
+	lost bool // Whether earlier output was dropped
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) > w.max {
+		w.buf = append(w.buf[:0], w.buf[len(w.buf)-w.max:]...)
+		w.lost = true
+	}
+	return len(p), nil
+}
+
+// String returns the kept output, marked when the start was dropped
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lost {
+		return "[...]" + string(w.buf)
+	}
+	return string(w.buf)
+}
+
+// sameWriter reports whether a and b are the same writer, as for output
+// combined from stdout and stderr. Writers of types that cannot be compared,
+// such as OutputFunc, are never the same.
+func sameWriter(a, b io.Writer) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a != nil && a == b
+}
+
+// pruneCommandLogs removes the oldest command logs in dir beyond keep
+func pruneCommandLogs(dir string, keep int) {
+	logs, err := filepath.Glob(filepath.Join(dir, "commands-*.log"))
+	if err != nil || len(logs) <= keep {
+		return
+	}
+	// Names start with the creation time, so they sort oldest first
+	sort.Strings(logs)
+	for _, path := range logs[:len(logs)-keep] {
+		os.Remove(path)
+	}
+}
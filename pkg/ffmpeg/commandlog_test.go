@@ -0,0 +1,54 @@
+package ffmpeg
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCommandLog tests that commands run by ExecRunner are recorded with
+// their exit code and standard error
+func TestCommandLog(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs /bin/sh")
+	}
+	path, err := StartCommandLog(t.TempDir())
+	assert.NoError(t, err)
+	defer StopCommandLog()
+
+	runner := NewRunner("/bin/sh", "")
+	output, err := CombinedOutput(runner, "/bin/sh", []string{"-c", "echo out; echo 'frame=1\rframe=2' >&2; echo oops >&2; exit 3"})
+	assert.Error(t, err)
+	assert.Equal(t, "out\nframe=1\rframe=2\noops\n", string(output))
+	_, err = Output(runner, "/bin/sh", []string{"-c", "true"})
+	assert.NoError(t, err)
+	assert.NoError(t, StopCommandLog())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	log := string(data)
+	assert.Contains(t, log, "] #1 exit 3 after ")
+	assert.Contains(t, log, "$ /bin/sh -c 'echo out; echo '\\''frame=1\rframe=2'\\'' >&2; echo oops >&2; exit 3'\n")
+	assert.Contains(t, log, "frame=2\noops\n")
+	assert.NotContains(t, log, "frame=1\rframe=2\n")
+	assert.Contains(t, log, "] #2 exit 0 after ")
+}
+
+func TestQuoteArgs(t *testing.T) {
+	assert.Equal(t, []string{"ffmpeg", "-i", "'my video.mp4'", "-vf", "scale=1280:-2,format=yuv420p", "''", `'it'\''s'`},
+		quoteArgs([]string{"ffmpeg", "-i", "my video.mp4", "-vf", "scale=1280:-2,format=yuv420p", "", "it's"}))
+	// Plain arguments stay unquoted
+	assert.Equal(t, []string{"-crf", "23", "out.mp4"}, quoteArgs([]string{"-crf", "23", "out.mp4"}))
+}
+
+func TestTailWriter(t *testing.T) {
+	tail := &tailWriter{max: 10}
+	tail.Write([]byte("short"))
+	assert.Equal(t, "short", tail.String())
+
+	tail.Write([]byte(strings.Repeat("x", 20) + "0123456789"))
+	assert.Equal(t, "[...]0123456789", tail.String())
+}
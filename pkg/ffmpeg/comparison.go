@@ -0,0 +1,65 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Layouts of comparison clips
+const (
+	ComparisonSideBySide = "side-by-side" // Source on the left, output on the right
+	ComparisonInterleave = "interleave"   // Source and output take turns every second
+)
+
+// ComparisonSeconds is the length of comparison clips
+const ComparisonSeconds = 10
+
+// comparisonCRF keeps the comparison clip close to lossless, so the
+// artifacts seen are the output's and not the clip's
+const comparisonCRF = "10"
+
+// ComparisonFilter returns the filter graph that lays out the source (input
+// 0) and the output (input 1) in mode, both at the output's width and height
+func ComparisonFilter(mode string, width, height int) string {
+	size := strconv.Itoa(width) + ":" + strconv.Itoa(height)
+	if mode == ComparisonSideBySide {
+		size = "-2:" + strconv.Itoa(height)
+	}
+	inputs := fmt.Sprintf("[0:v]scale=%[1]s,setsar=1,format=yuv420p[source];[1:v]scale=%[1]s,setsar=1,format=yuv420p[output];", size)
+	if mode == ComparisonInterleave {
+		// The source shows during even seconds, the output during odd ones
+		return inputs + "[source][output]overlay=enable='gte(mod(t,2),1)'"
+	}
+	return inputs + "[source][output]hstack=inputs=2"
+}
+
+// MakeComparison writes a clip of length seconds from start that shows the
+// source and the compressed output of a video together, laid out by mode,
+// so their quality can be compared by eye. output is width by height.
+func (f *FFmpeg) MakeComparison(source, output, clipPath string, start, length float64, mode string, width, height int) error {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return err
+	}
+
+	seek := []string{"-ss", fmt.Sprintf("%.3f", start), "-t", fmt.Sprintf("%.3f", length)}
+	args := append([]string{"-y"}, RemoteInputArgs(source)...)
+	args = append(args, seek...)
+	args = append(args, "-i", FileArg(source))
+	args = append(args, seek...)
+	args = append(args,
+		"-i", FileArg(output),
+		"-filter_complex", ComparisonFilter(mode, width, height),
+		"-c:v", "libx264",
+		"-crf", comparisonCRF,
+		"-preset", "veryfast",
+		"-an",
+		"-movflags", "+faststart",
+		FileArg(clipPath),
+	)
+	if _, err := CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return fmt.Errorf("failed to make comparison clip: %w", err)
+	}
+	return nil
+}
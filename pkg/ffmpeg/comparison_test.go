@@ -0,0 +1,16 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComparisonFilter(t *testing.T) {
+	assert.Equal(t,
+		"[0:v]scale=-2:720,setsar=1,format=yuv420p[source];[1:v]scale=-2:720,setsar=1,format=yuv420p[output];[source][output]hstack=inputs=2",
+		ComparisonFilter(ComparisonSideBySide, 1280, 720))
+	assert.Equal(t,
+		"[0:v]scale=1280:720,setsar=1,format=yuv420p[source];[1:v]scale=1280:720,setsar=1,format=yuv420p[output];[source][output]overlay=enable='gte(mod(t,2),1)'",
+		ComparisonFilter(ComparisonInterleave, 1280, 720))
+}
@@ -0,0 +1,63 @@
+package ffmpeg
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// CPUMeter adds up the CPU time, user and system, of the processes run
+// through a Runner returned by WithCPUMeter
+type CPUMeter struct {
+	mu    sync.Mutex
+	total time.Duration
+}
+
+// Add adds d to the meter
+func (m *CPUMeter) Add(d time.Duration) {
+	m.mu.Lock()
+	m.total += d
+	m.mu.Unlock()
+}
+
+// Total returns the CPU time measured so far
+func (m *CPUMeter) Total() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.total
+}
+
+// cpuMeterKey is the context key of the meter an ExecRunner adds to
+type cpuMeterKey struct{}
+
+// meteredRunner measures the CPU time of the commands of a Runner
+type meteredRunner struct {
+	Runner
+	meter *CPUMeter
+}
+
+// WithCPUMeter returns a Runner that runs commands through r and adds the
+// CPU time of each process to meter, so the cost of a whole job is known
+// whichever part of the tool starts its processes. Only processes started
+// by an ExecRunner are measured.
+func WithCPUMeter(r Runner, meter *CPUMeter) Runner {
+	return &meteredRunner{Runner: r, meter: meter}
+}
+
+// Run runs the binary at path with the meter in its context
+func (r *meteredRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	return r.Runner.Run(context.WithValue(ctx, cpuMeterKey{}, r.meter), path, args, stdout, stderr)
+}
+
+// addCPUTime adds the CPU time of an exited process to the meter of ctx.
+// The operating system counts the time of the descendants the process
+// waited for, such as the ffmpeg run by a wrapper script.
+func addCPUTime(ctx context.Context, state *os.ProcessState) {
+	meter, ok := ctx.Value(cpuMeterKey{}).(*CPUMeter)
+	if !ok || state == nil {
+		return
+	}
+	meter.Add(state.UserTime() + state.SystemTime())
+}
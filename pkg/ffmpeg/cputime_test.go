@@ -0,0 +1,33 @@
+package ffmpeg
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCPUMeter tests that the CPU time of the processes of a metered runner
+// is added up, and that other runners are left alone
+func TestCPUMeter(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("runs /bin/sh")
+	}
+	busy := []string{"-c", "i=0; while [ $i -lt 100000 ]; do i=$((i+1)); done"}
+	meter := &CPUMeter{}
+	runner := WithCPUMeter(WithContext(NewRunner("/bin/sh", ""), context.Background()), meter)
+
+	assert.NoError(t, runner.Run(context.Background(), "/bin/sh", busy, nil, nil))
+	first := meter.Total()
+	assert.True(t, first > 0)
+
+	// Failed commands count too
+	assert.Error(t, runner.Run(context.Background(), "/bin/sh", append(busy[:1:1], busy[1]+"; exit 1"), nil, nil))
+	assert.True(t, meter.Total() > first)
+
+	// Commands run without the meter are not counted
+	total := meter.Total()
+	assert.NoError(t, NewRunner("/bin/sh", "").Run(context.Background(), "/bin/sh", busy, nil, nil))
+	assert.Equal(t, total, meter.Total())
+}
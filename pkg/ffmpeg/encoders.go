@@ -0,0 +1,143 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	encoderMu    sync.Mutex
+	encoderCache = map[string]map[string]bool{} // Encoders per FFmpeg binary path
+)
+
+// Encoders returns the encoders compiled into the FFmpeg binary. The list is
+// probed with "ffmpeg -encoders" once per binary and cached for the process.
+func (f *FFmpeg) Encoders() (map[string]bool, error) {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return nil, err
+	}
+	return probeEncoders(runner, ffmpegPath)
+}
+
+// probeEncoders runs "ffmpeg -encoders" unless the result is already cached
+func probeEncoders(runner Runner, ffmpegPath string) (map[string]bool, error) {
+	encoderMu.Lock()
+	defer encoderMu.Unlock()
+
+	if encoders, ok := encoderCache[ffmpegPath]; ok {
+		return encoders, nil
+	}
+
+	output, err := Output(runner, ffmpegPath, []string{"-hide_banner", "-encoders"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FFmpeg encoders: %w", err)
+	}
+
+	encoders := parseEncoderList(string(output))
+	if len(encoders) == 0 {
+		return nil, fmt.Errorf("failed to list FFmpeg encoders: empty output")
+	}
+	encoderCache[ffmpegPath] = encoders
+	return encoders, nil
+}
+
+// parseEncoderList reads the encoder names from "ffmpeg -encoders" output:
+//
+//	Encoders:
+//	 V..... = Video
+//	 ------
+//	 V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC (codec h264)
+func parseEncoderList(output string) map[string]bool {
+	encoders := map[string]bool{}
+	inList := false
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "------") {
+			inList = true
+			continue
+		}
+		if !inList {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields[0]) != 6 {
+			continue
+		}
+		encoders[fields[1]] = true
+	}
+	return encoders
+}
+
+// Hardware encoder families, named after the FFmpeg encoder suffix
+const (
+	HWEncoderNVENC = "nvenc" // NVIDIA
+	HWEncoderQSV   = "qsv"   // Intel Quick Sync
+	HWEncoderAMF   = "amf"   // AMD
+)
+
+// hwEncoderFamilies lists the hardware encoder families in order of preference
+var hwEncoderFamilies = []string{HWEncoderNVENC, HWEncoderQSV, HWEncoderAMF}
+
+// DetectAvailableHWAccelerators returns the hardware encoder families with an
+// H.264 or HEVC encoder in the given encoder list, in order of preference.
+// The encoders being compiled in does not guarantee that a matching GPU and
+// driver are present.
+func DetectAvailableHWAccelerators(encoders map[string]bool) []string {
+	var families []string
+	for _, family := range hwEncoderFamilies {
+		if encoders["h264_"+family] || encoders["hevc_"+family] {
+			families = append(families, family)
+		}
+	}
+	return families
+}
+
+// encoderTestFrames is the number of frames TryEncoder encodes
+const encoderTestFrames = 5
+
+// encoderTestArgs returns the arguments that encode a few frames of a test
+// pattern with encoder and discard them. VAAPI encoders only take frames
+// uploaded to the GPU; the other encoders take them from system memory.
+func encoderTestArgs(encoder string) []string {
+	args := []string{"-hide_banner", "-v", "error"}
+	if strings.HasSuffix(encoder, "_vaapi") {
+		args = append(args, "-vaapi_device", defaultVAAPIDevice)
+	}
+	args = append(args, "-f", "lavfi", "-i", "testsrc2=size=320x240:rate=25")
+	switch {
+	case strings.HasSuffix(encoder, "_vaapi"):
+		args = append(args, "-vf", "format=nv12,hwupload")
+	case strings.HasSuffix(encoder, "_qsv"):
+		args = append(args, "-pix_fmt", "nv12")
+	default:
+		args = append(args, "-pix_fmt", "yuv420p")
+	}
+	return append(args, "-frames:v", strconv.Itoa(encoderTestFrames), "-c:v", encoder, "-f", "null", "-")
+}
+
+// TryEncoder encodes a few frames of a test pattern with encoder. Hardware
+// encoders compiled into FFmpeg still need a matching GPU and driver, which
+// only a test encode shows.
+func (f *FFmpeg) TryEncoder(encoder string) error {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return err
+	}
+	if output, err := CombinedOutput(runner, ffmpegPath, encoderTestArgs(encoder)); err != nil {
+		if details := strings.TrimSpace(string(output)); details != "" {
+			// The first line names the cause; the rest repeats it
+			return fmt.Errorf("%s", strings.SplitN(details, "\n", 2)[0])
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEncoderList(t *testing.T) {
+	output := `Encoders:
+ V..... = Video
+ A..... = Audio
+ S..... = Subtitle
+ .F.... = Frame-level multithreading
+ ------
+ V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10 (codec h264)
+ V....D h264_nvenc           NVIDIA NVENC H.264 encoder (codec h264)
+ A....D aac                  AAC (Advanced Audio Coding)
+`
+	encoders := parseEncoderList(output)
+
+	assert.Len(t, encoders, 3)
+	assert.True(t, encoders["libx264"])
+	assert.True(t, encoders["h264_nvenc"])
+	assert.True(t, encoders["aac"])
+	// The legend above the separator is not an encoder
+	assert.False(t, encoders["="])
+	assert.False(t, encoders["libx265"])
+}
+
+func TestDetectAvailableHWAccelerators(t *testing.T) {
+	encoders := map[string]bool{"libx264": true, "hevc_amf": true, "h264_nvenc": true}
+	assert.Equal(t, []string{"nvenc", "amf"}, DetectAvailableHWAccelerators(encoders))
+	assert.Empty(t, DetectAvailableHWAccelerators(map[string]bool{"libx264": true}))
+}
+
+func TestEncoderTestArgs(t *testing.T) {
+	args := encoderTestArgs("libx264")
+	assert.Equal(t, []string{"-c:v", "libx264", "-f", "null", "-"}, args[len(args)-5:])
+	assert.Contains(t, args, "yuv420p")
+
+	// VAAPI needs a device and frames uploaded to it
+	args = encoderTestArgs("hevc_vaapi")
+	assert.Equal(t, []string{"-hide_banner", "-v", "error", "-vaapi_device", "/dev/dri/renderD128"}, args[:5])
+	assert.Contains(t, args, "format=nv12,hwupload")
+
+	assert.Contains(t, encoderTestArgs("h264_qsv"), "nv12")
+}
+
+func TestTryEncoder(t *testing.T) {
+	runner := &fakeRunner{output: map[string]string{
+		"/opt/ffmpeg/ffmpeg": "[h264_nvenc @ 0x1] Cannot load libcuda.so.1\nError initializing output stream\n",
+	}}
+	f := NewFFmpeg("", "", nil, nil)
+	f.Runner = runner
+
+	// Only the first line of the FFmpeg output is kept
+	err := f.TryEncoder("h264_nvenc")
+	assert.EqualError(t, err, "[h264_nvenc @ 0x1] Cannot load libcuda.so.1")
+	assert.Equal(t, "h264_nvenc", runner.calls[0][len(runner.calls[0])-4])
+}
@@ -0,0 +1,187 @@
+package ffmpeg
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// Failures recognized in FFmpeg and FFprobe output. Use errors.Is to test
+// for them; the *Error returned by ClassifyError wraps one of them.
+var (
+	ErrTruncatedInput   = errors.New("input file is truncated or incomplete")
+	ErrInvalidData      = errors.New("input is damaged or not a readable video")
+	ErrFileNotFound     = errors.New("file not found")
+	ErrPermissionDenied = errors.New("permission denied")
+	ErrDiskFull         = errors.New("no space left on device")
+	ErrPixelFormat      = errors.New("pixel format not supported by the encoder")
+	ErrUnknownEncoder   = errors.New("encoder not available in this FFmpeg build")
+	ErrNetwork          = errors.New("network error while reading the input")
+)
+
+// errorPatterns maps lowercase FFmpeg messages to the failure they indicate.
+// A file cut short also prints "Invalid data found", so truncation is
+// matched first.
+var errorPatterns = []struct {
+	kind     error
+	patterns []string
+}{
+	{ErrTruncatedInput, []string{"moov atom not found", "partial file", "stream ends prematurely", "truncated"}},
+	{ErrFileNotFound, []string{"no such file or directory"}},
+	{ErrPermissionDenied, []string{"permission denied", "operation not permitted"}},
+	{ErrDiskFull, []string{"no space left on device"}},
+	{ErrPixelFormat, []string{"incompatible pixel format", "specified pixel format", "unsupported pixel format", "no pixel format specified"}},
+	{ErrUnknownEncoder, []string{"unknown encoder", "encoder not found"}},
+	{ErrNetwork, []string{"connection refused", "connection timed out", "connection reset", "server returned",
+		"failed to resolve hostname", "network is unreachable", "http error"}},
+	{ErrInvalidData, []string{"invalid data found when processing input", "could not find codec parameters",
+		"error while decoding", "invalid nal unit", "no video stream"}},
+}
+
+// errorHints tells the user what to do about each failure
+var errorHints = map[error]string{
+	ErrTruncatedInput:   "the file appears truncated, e.g. a cut-off recording or an interrupted download; try --ignore-errors to salvage the readable part",
+	ErrInvalidData:      "the file may be damaged or not a video; check that it plays in a media player, or try --ignore-errors to skip damaged data",
+	ErrFileNotFound:     "check the path and that the drive or share is still connected",
+	ErrPermissionDenied: "check that you can read the input and write to the output directory",
+	ErrDiskFull:         "free up disk space or write the output to another drive",
+	ErrPixelFormat:      "the encoder cannot take the source's pixel format; try a software encoder or another quality level",
+	ErrUnknownEncoder:   "run 'compressvideo doctor' to see the available encoders, or 'compressvideo repair-ffmpeg' for a full FFmpeg build",
+	ErrNetwork:          "check the URL and your network connection",
+}
+
+// detailHints replace the hint of a failure whose detail has the pattern.
+// An MP4 without its index cannot even be probed, so --ignore-errors, which
+// only reaches the encode, does not help.
+var detailHints = []struct {
+	pattern string
+	hint    string
+}{
+	{"moov atom not found", "the MP4 index (moov atom) is missing, usually because the recording was cut off before it was finalized; it cannot be read until a tool such as untrunc rebuilds the index"},
+}
+
+// errorLineKeywords pick the lines that explain an unrecognized failure
+var errorLineKeywords = []string{"error", "invalid", "failed", "unable", "cannot", "could not", "not supported", "unsupported"}
+
+// maxErrorLines caps the lines of FFmpeg output kept in an error message
+const maxErrorLines = 3
+
+// progressLineRegex matches FFmpeg's status lines (frame=..., size=...)
+var progressLineRegex = regexp.MustCompile(`^\s*(frame|size)=`)
+
+// Error is a failed FFmpeg or FFprobe run, classified from what it printed.
+// The message holds only the lines that explain the failure and a hint;
+// the full output is kept in Output for the log.
+type Error struct {
+	Err    error  // Exit error of the process
+	Kind   error  // Recognized failure (one of the Err variables), nil when unrecognized
+	Detail string // Lines of output that explain the failure
+	Output string // Everything the process printed
+}
+
+// Error returns the failure, the lines that explain it and a hint
+func (e *Error) Error() string {
+	message := e.Err.Error()
+	if e.Kind != nil {
+		message = i18n.Tr(e.Kind.Error())
+	}
+	if e.Detail != "" {
+		message += ": " + e.Detail
+	}
+	if hint := e.Hint(); hint != "" {
+		message += "\n" + i18n.T("Hint: %s", i18n.Tr(hint))
+	}
+	return message
+}
+
+// Unwrap returns the exit error and the recognized failure
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Err, e.Kind}
+}
+
+// Hint returns what the user can do about the failure, empty when the
+// failure was not recognized
+func (e *Error) Hint() string {
+	detail := strings.ToLower(e.Detail)
+	for _, entry := range detailHints {
+		if strings.Contains(detail, entry.pattern) {
+			return entry.hint
+		}
+	}
+	return errorHints[e.Kind]
+}
+
+// ClassifyError turns err from running FFmpeg or FFprobe into an *Error,
+// using output (what the process printed) to recognize the failure. It
+// returns nil for a nil err.
+func ClassifyError(err error, output string) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+
+	lines := outputLines(output)
+	for _, group := range errorPatterns {
+		for _, pattern := range group.patterns {
+			for _, line := range lines {
+				if strings.Contains(strings.ToLower(line), pattern) {
+					return &Error{Err: err, Kind: group.kind, Detail: line, Output: output}
+				}
+			}
+		}
+	}
+	return &Error{Err: err, Detail: errorDetail(lines), Output: output}
+}
+
+// ErrorOutput returns everything FFmpeg printed before failing with err, or
+// the message of err when it does not come from ClassifyError
+func ErrorOutput(err error) string {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.Output
+	}
+	return err.Error()
+}
+
+// outputLines returns the non-empty lines of output without status lines.
+// Status lines end in carriage returns, so those split lines too.
+func outputLines(output string) []string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(output, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		if line != "" && !progressLineRegex.MatchString(line) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// errorDetail returns the last lines that look like errors, or the last
+// line when none does
+func errorDetail(lines []string) string {
+	var matched []string
+	for _, line := range lines {
+		lower := strings.ToLower(line)
+		for _, keyword := range errorLineKeywords {
+			if strings.Contains(lower, keyword) {
+				matched = append(matched, line)
+				break
+			}
+		}
+	}
+	if len(matched) == 0 && len(lines) > 0 {
+		matched = lines[len(lines)-1:]
+	}
+	if len(matched) > maxErrorLines {
+		matched = matched[len(matched)-maxErrorLines:]
+	}
+	return strings.Join(matched, "; ")
+}
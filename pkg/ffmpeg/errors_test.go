@@ -0,0 +1,132 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestClassifyError tests that common FFmpeg failures are recognized
+func TestClassifyError(t *testing.T) {
+	exitErr := fmt.Errorf("exit status 1")
+	tests := []struct {
+		output string
+		kind   error
+		detail string
+	}{
+		{"[mov,mp4,m4a,3gp,3g2,mj2 @ 0x55d0] moov atom not found\nin.mp4: Invalid data found when processing input",
+			ErrTruncatedInput, "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x55d0] moov atom not found"},
+		{"in.avi: Invalid data found when processing input", ErrInvalidData, "in.avi: Invalid data found when processing input"},
+		{"missing.mp4: No such file or directory", ErrFileNotFound, "missing.mp4: No such file or directory"},
+		{"/out/video.mp4: Permission denied", ErrPermissionDenied, "/out/video.mp4: Permission denied"},
+		{"av_interleaved_write_frame(): No space left on device", ErrDiskFull, "av_interleaved_write_frame(): No space left on device"},
+		{"[h264_nvenc @ 0x1] Unsupported pixel format: yuv422p10le", ErrPixelFormat, "[h264_nvenc @ 0x1] Unsupported pixel format: yuv422p10le"},
+		{"Unknown encoder 'libsvtav1'", ErrUnknownEncoder, "Unknown encoder 'libsvtav1'"},
+		{"[tcp @ 0x1] Connection to tcp://host:80 failed: Connection refused", ErrNetwork, "[tcp @ 0x1] Connection to tcp://host:80 failed: Connection refused"},
+	}
+
+	for _, test := range tests {
+		err := ClassifyError(exitErr, test.output)
+		assert.True(t, errors.Is(err, test.kind), test.output)
+		assert.True(t, errors.Is(err, exitErr), "exit error must stay reachable")
+
+		var ffmpegErr *Error
+		if assert.True(t, errors.As(err, &ffmpegErr)) {
+			assert.Equal(t, test.detail, ffmpegErr.Detail)
+			assert.Equal(t, test.output, ffmpegErr.Output)
+			assert.NotEmpty(t, ffmpegErr.Hint())
+		}
+		assert.Contains(t, err.Error(), test.kind.Error())
+		assert.Contains(t, err.Error(), "Hint: ")
+	}
+}
+
+// TestClassifyErrorUnrecognized tests that unrecognized failures keep only
+// the last error lines instead of the whole output
+func TestClassifyErrorUnrecognized(t *testing.T) {
+	output := "ffmpeg version 6.1\n  built with gcc\n" + strings.Repeat("frame=  10 fps=0.0 q=28.0 size=0kB time=00:00:00.40\r", 50) +
+		"\n[libx264 @ 0x1] first error\n[libx264 @ 0x1] second error\n[libx264 @ 0x1] third error\n" +
+		"Error initializing output stream 0:0 -- Error while opening encoder\nConversion failed!\n"
+	err := ClassifyError(fmt.Errorf("exit status 1"), output)
+
+	var ffmpegErr *Error
+	assert.True(t, errors.As(err, &ffmpegErr))
+	assert.Nil(t, ffmpegErr.Kind)
+	assert.Empty(t, ffmpegErr.Hint())
+	assert.Equal(t, "[libx264 @ 0x1] third error; Error initializing output stream 0:0 -- Error while opening encoder; Conversion failed!",
+		ffmpegErr.Detail)
+	assert.Equal(t, "exit status 1: "+ffmpegErr.Detail, err.Error())
+	assert.Equal(t, output, ErrorOutput(err))
+
+	// Without error lines the last line explains the failure
+	err = ClassifyError(fmt.Errorf("exit status 1"), "ffmpeg version 6.1\nlast words\n")
+	assert.Equal(t, "exit status 1: last words", err.Error())
+
+	assert.Nil(t, ClassifyError(nil, "anything"))
+}
+
+// TestClassifyErrorWrapped tests that classifying twice keeps the first
+// classification and that exec errors stay reachable
+func TestClassifyErrorWrapped(t *testing.T) {
+	exitErr := &exec.ExitError{}
+	err := ClassifyError(exitErr, "in.mp4: Permission denied")
+	wrapped := fmt.Errorf("FFmpeg error: %w", err)
+	assert.Equal(t, wrapped, ClassifyError(wrapped, "other output"))
+
+	var target *exec.ExitError
+	assert.True(t, errors.As(wrapped, &target))
+	assert.Equal(t, "in.mp4: Permission denied", ErrorOutput(wrapped))
+	assert.Equal(t, "plain", ErrorOutput(fmt.Errorf("plain")))
+}
+
+// TestCombinedOutputClassifies tests that failed runs return classified errors
+func TestCombinedOutputClassifies(t *testing.T) {
+	runner := &fakeRunner{output: map[string]string{"/opt/ffmpeg/ffmpeg": "in.mp4: moov atom not found\n"}}
+	output, err := CombinedOutput(runner, "/opt/ffmpeg/ffmpeg", []string{"-i", "in.mp4"})
+	assert.Equal(t, "in.mp4: moov atom not found\n", string(output))
+	assert.True(t, errors.Is(err, ErrTruncatedInput))
+
+	// Output only returns standard output but still classifies from stderr
+	output, err = Output(runner, "/opt/ffmpeg/ffmpeg", []string{"-i", "in.mp4"})
+	assert.Empty(t, output)
+	assert.True(t, errors.Is(err, ErrTruncatedInput))
+}
+
+// probeFailRunner fails every ffprobe run with output
+type probeFailRunner struct {
+	fakeRunner
+	output string
+}
+
+func (r *probeFailRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	io.WriteString(stderr, r.output)
+	return fmt.Errorf("exit status 1")
+}
+
+// TestHintMissingMoovAtom tests that the hint of an MP4 without its index
+// does not send the user to --ignore-errors, which cannot get past probing
+func TestHintMissingMoovAtom(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError)
+	f := NewFFmpeg("", "", nil, logger)
+	f.Runner = &probeFailRunner{
+		fakeRunner: fakeRunner{probePath: "/opt/ffmpeg/ffprobe"},
+		output:     "[mov,mp4,m4a,3gp,3g2,mj2 @ 0x1] moov atom not found\ncut.mp4: Invalid data found when processing input\n",
+	}
+
+	_, err := f.GetVideoInfo("cut.mp4")
+	assert.True(t, errors.Is(err, ErrTruncatedInput))
+	assert.Contains(t, err.Error(), "untrunc")
+	assert.NotContains(t, err.Error(), "--ignore-errors")
+
+	// Files cut short elsewhere can be salvaged by the encode
+	err = ClassifyError(fmt.Errorf("exit status 1"), "[mov,mp4 @ 0x1] stream 0, offset 0x2f1: partial file")
+	assert.Contains(t, err.Error(), "--ignore-errors")
+}
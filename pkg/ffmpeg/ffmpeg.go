@@ -2,13 +2,14 @@ package ffmpeg
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 	"github.com/cccarv82/compressvideo/pkg/util"
 )
 
@@ -38,6 +39,7 @@ type EncodingSettings struct {
 type Options struct {
 	Quality int    // Quality level (1-5, 1=max compression, 5=max quality)
 	Preset  string // Preset (fast, balanced, thorough)
+	HWAccel string // Hardware decoder (cuda, qsv, vaapi), empty for CPU decoding
 }
 
 // FFmpeg represents an FFmpeg instance
@@ -46,6 +48,9 @@ type FFmpeg struct {
 	OutputFile  string    // Output file path
 	Options     *Options  // Compression options
 	Logger      *util.Logger // Logger
+	Runner      Runner    // Runs ffmpeg and ffprobe, DefaultRunner when nil
+
+	probeWarning sync.Once // Shows the missing-ffprobe warning once, from any segment worker
 }
 
 // NewFFmpeg cria uma nova instância do FFmpeg
@@ -59,34 +64,62 @@ func NewFFmpeg(inputFile, outputFile string, options *Options, logger *util.Logg
 		OutputFile: outputFile,
 		Options:    options,
 		Logger:     logger,
+		Runner:     DefaultRunner,
 	}
 }
 
+// ProbeJSON returns the ffprobe JSON description of the format and streams
+// of a video file, as GetVideoInfo reads it
+func (f *FFmpeg) ProbeJSON(filePath string) ([]byte, error) {
+	runner := f.runner()
+	ffprobePath, err := runner.ProbePath()
+	if err != nil {
+		return nil, err
+	}
+	if ffprobePath == "" {
+		return nil, fmt.Errorf("ffprobe not found")
+	}
+	return probeJSON(runner, ffprobePath, filePath)
+}
+
+// probeJSON runs ffprobe to get JSON output with all stream info. Errors are
+// still printed, so a failure can be classified.
+func probeJSON(runner Runner, ffprobePath, filePath string) ([]byte, error) {
+	args := append([]string{
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+	}, RemoteInputArgs(filePath)...)
+	output, err := Output(runner, ffprobePath, append(args, FileArg(filePath)))
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	return output, nil
+}
+
 // GetVideoInfo retrieves detailed information about a video file using ffprobe
 func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 	f.Logger.Debug("Getting video info for: %s", filePath)
 
 	// Obter o caminho para o FFprobe
-	info, err := util.FindFFmpeg()
+	runner := f.runner()
+	ffprobePath, err := runner.ProbePath()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return nil, i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
-	
-	ffprobePath := info.FFprobePath
 
-	// Run ffprobe to get JSON output with all stream info
-	cmd := exec.Command(
-		ffprobePath,
-		"-v", "quiet",
-		"-print_format", "json",
-		"-show_format",
-		"-show_streams",
-		filePath,
-	)
+	// Sem FFprobe, extrai apenas as informações básicas da saída do ffmpeg
+	if ffprobePath == "" {
+		f.probeWarning.Do(func() {
+			f.Logger.Warning("FFprobe not found, reading basic stream info from ffmpeg output; analysis will be limited")
+		})
+		return f.getVideoInfoFromFFmpeg(filePath)
+	}
 
-	output, err := cmd.Output()
+	output, err := probeJSON(runner, ffprobePath, filePath)
 	if err != nil {
-		return nil, fmt.Errorf("ffprobe failed: %w", err)
+		return nil, err
 	}
 
 	// Parse the JSON output
@@ -103,11 +136,16 @@ func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 	// Create a new VideoFile
 	videoFile := &VideoFile{
 		Path:     filePath,
-		Format:   filepath.Ext(filePath)[1:], // Remove the dot
+		Format:   pathExtension(filePath),
 		Metadata: make(map[string]string),
 		AudioInfo: []AudioStreamInfo{},
 	}
 
+	// URLs without an extension are named by the container ffprobe detected
+	if formatName, ok := ffprobeOutput.Format["format_name"].(string); ok && videoFile.Format == "" {
+		videoFile.Format = strings.Split(formatName, ",")[0]
+	}
+
 	// Extract duration and size from format section
 	if durationStr, ok := ffprobeOutput.Format["duration"].(string); ok {
 		videoFile.Duration, _ = strconv.ParseFloat(durationStr, 64)
@@ -162,6 +200,14 @@ func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 				videoInfo.PixelFormat = pixFmt
 			}
 			
+			// Extract sample and display aspect ratios, 0:1 when unknown
+			if sar, ok := stream["sample_aspect_ratio"].(string); ok {
+				videoInfo.SAR = knownRatio(sar)
+			}
+			if dar, ok := stream["display_aspect_ratio"].(string); ok {
+				videoInfo.DAR = knownRatio(dar)
+			}
+			
 			// Extract profile level
 			if profile, ok := stream["profile"].(string); ok {
 				videoInfo.ProfileLevel = profile
@@ -181,16 +227,24 @@ func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 				}
 			}
 			
+			// Extract the color properties, which ffprobe reports on the
+			// stream and some muxers also as tags
+			videoInfo.ColorSpace, _ = stream["color_space"].(string)
+			videoInfo.ColorPrimaries, _ = stream["color_primaries"].(string)
+			videoInfo.ColorTransfer, _ = stream["color_transfer"].(string)
+			if tags, ok := stream["tags"].(map[string]interface{}); ok && videoInfo.ColorTransfer == "" {
+				videoInfo.ColorTransfer, _ = tags["color_transfer"].(string)
+			}
+			
 			// Check for HDR
-			if tags, ok := stream["tags"].(map[string]interface{}); ok {
-				if colorTransfer, ok := tags["color_transfer"].(string); ok {
-					if strings.Contains(strings.ToLower(colorTransfer), "smpte2084") || 
-					   strings.Contains(strings.ToLower(colorTransfer), "arib-std-b67") {
-						videoInfo.IsHDR = true
-					}
-				}
+			colorTransfer := strings.ToLower(videoInfo.ColorTransfer)
+			if strings.Contains(colorTransfer, "smpte2084") || strings.Contains(colorTransfer, "arib-std-b67") {
+				videoInfo.IsHDR = true
 			}
 			
+			// Dolby Vision streams carry a configuration record
+			videoInfo.DolbyVision = dolbyVisionProfile(stream)
+			
 			videoFile.VideoInfo = videoInfo
 			
 		} else if streamType == "audio" {
@@ -230,8 +284,28 @@ func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 					audioInfo.Language = language
 				}
 			}
+			if disposition, ok := stream["disposition"].(map[string]interface{}); ok {
+				audioInfo.Default = disposition["default"] == float64(1)
+			}
 			
 			videoFile.AudioInfo = append(videoFile.AudioInfo, audioInfo)
+		} else if streamType == "subtitle" {
+			// Extract subtitle stream info
+			subtitleInfo := SubtitleStreamInfo{}
+			if codec, ok := stream["codec_name"].(string); ok {
+				subtitleInfo.Codec = codec
+			}
+			if tags, ok := stream["tags"].(map[string]interface{}); ok {
+				if language, ok := tags["language"].(string); ok {
+					subtitleInfo.Language = language
+				}
+			}
+			if disposition, ok := stream["disposition"].(map[string]interface{}); ok {
+				subtitleInfo.Default = disposition["default"] == float64(1)
+				subtitleInfo.Forced = disposition["forced"] == float64(1)
+			}
+
+			videoFile.SubtitleInfo = append(videoFile.SubtitleInfo, subtitleInfo)
 		}
 	}
 
@@ -242,17 +316,15 @@ func (f *FFmpeg) GetVideoInfo(filePath string) (*VideoFile, error) {
 // ExecuteCommand runs an FFmpeg command with the given arguments
 func (f *FFmpeg) ExecuteCommand(args []string) ([]byte, error) {
 	// Obter o caminho para o FFmpeg
-	info, err := util.FindFFmpeg()
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		return nil, fmt.Errorf("erro ao encontrar FFmpeg: %v", err)
+		return nil, i18n.Errorf("failed to find FFmpeg: %v", err)
 	}
 	
-	ffmpegPath := info.Path
-	
 	f.Logger.Debug("Executing FFmpeg command: %s %s", ffmpegPath, strings.Join(args, " "))
 	
-	cmd := exec.Command(ffmpegPath, args...)
-	return cmd.CombinedOutput()
+	return CombinedOutput(runner, ffmpegPath, args)
 }
 
 // DetectSceneChanges analyzes a video to detect scene changes
@@ -265,12 +337,11 @@ func (f *FFmpeg) DetectSceneChanges(filePath string, threshold float64) ([]float
 	}
 	
 	// Use FFmpeg's scene detection filter
-	args := []string{
-		"-i", filePath,
+	args := append(f.inputArgs(filePath),
 		"-vf", fmt.Sprintf("select='gt(scene,%f)',metadata=print", threshold),
 		"-f", "null",
 		"-",
-	}
+	)
 	
 	output, err := f.ExecuteCommand(args)
 	if err != nil {
@@ -305,18 +376,22 @@ func (f *FFmpeg) DetectSceneChanges(filePath string, threshold float64) ([]float
 	return sceneChanges, nil
 }
 
-// CalculateFrameComplexity estimates the complexity of video frames
-func (f *FFmpeg) CalculateFrameComplexity(filePath string) (float64, error) {
+// CalculateFrameComplexity estimates the complexity of video frames from
+// one frame every interval seconds, or from the keyframes when interval is 0
+func (f *FFmpeg) CalculateFrameComplexity(filePath string, interval float64) (float64, error) {
 	f.Logger.Debug("Calculating frame complexity for: %s", filePath)
 	
 	// Use FFmpeg to extract frames and calculate complexity
 	// This is a simplified approach using FFmpeg filters
-	args := []string{
-		"-i", filePath,
-		"-vf", "select='eq(pict_type,I)',signalstats=stat=variance",
+	selectExpr := "eq(pict_type,I)"
+	if interval > 0 {
+		selectExpr = fmt.Sprintf("isnan(prev_selected_t)+gte(t-prev_selected_t,%g)", interval)
+	}
+	args := append(f.inputArgs(filePath),
+		"-vf", fmt.Sprintf("select='%s',signalstats=stat=variance", selectExpr),
 		"-f", "null",
 		"-",
-	}
+	)
 	
 	output, err := f.ExecuteCommand(args)
 	if err != nil {
@@ -354,25 +429,23 @@ func (f *FFmpeg) CalculateFrameComplexity(filePath string) (float64, error) {
 // Execute executes the FFmpeg command
 func (ffmpeg *FFmpeg) Execute() error {
 	// Verificar se o FFmpeg está instalado
-	ffmpegInfo, err := util.FindFFmpeg()
+	runner := ffmpeg.runner()
+	ffmpegPath, err := runner.EncodePath()
 	if err != nil {
-		ffmpeg.Logger.Error("Falha ao verificar FFmpeg: %v", err)
-		ffmpeg.Logger.Info("Tente executar 'compressvideo repair-ffmpeg' para corrigir problemas com o FFmpeg")
-		return fmt.Errorf("falha ao inicializar FFmpeg: %v", err)
+		ffmpeg.Logger.Error("Failed to verify FFmpeg: %v", err)
+		ffmpeg.Logger.Info("Try running 'compressvideo repair-ffmpeg' to fix FFmpeg problems")
+		return i18n.Errorf("failed to initialize FFmpeg: %v", err)
 	}
 	
-	// Caminho para o FFmpeg
-	ffmpegPath := ffmpegInfo.Path
-	
 	// Obter informações do vídeo original
 	video, err := ffmpeg.GetVideoInfo(ffmpeg.InputFile)
 	if err != nil {
 		// No Windows, erros com código hexadecimal podem indicar problemas com o FFprobe
 		if strings.Contains(err.Error(), "0x") || strings.Contains(err.Error(), "exit status") {
-			ffmpeg.Logger.Error("Erro ao executar FFprobe. Isso pode indicar um problema com a instalação do FFmpeg.")
-			ffmpeg.Logger.Info("Tente executar 'compressvideo repair-ffmpeg' para corrigir problemas com o FFmpeg")
+			ffmpeg.Logger.Error("Failed to run FFprobe. This may indicate a problem with the FFmpeg installation.")
+			ffmpeg.Logger.Info("Try running 'compressvideo repair-ffmpeg' to fix FFmpeg problems")
 		}
-		return fmt.Errorf("falha ao obter informações do vídeo: %v", err)
+		return i18n.Errorf("failed to get video info: %v", err)
 	}
 
 	// Criar uma estrutura VideoInfo a partir do VideoFile para compatibilidade
@@ -387,10 +460,10 @@ func (ffmpeg *FFmpeg) Execute() error {
 	}
 	
 	// Exibir informações do vídeo
-	ffmpeg.Logger.Info("Informações do vídeo:")
-	ffmpeg.Logger.Info("  Resolução: %dx%d", videoInfo.Width, videoInfo.Height)
+	ffmpeg.Logger.Info("Video information:")
+	ffmpeg.Logger.Info("  Resolution: %dx%d", videoInfo.Width, videoInfo.Height)
 	ffmpeg.Logger.Info("  Codec: %s", videoInfo.CodecName)
-	ffmpeg.Logger.Info("  Duração: %.2f segundos", videoInfo.Duration)
+	ffmpeg.Logger.Info("  Duration: %.2f seconds", videoInfo.Duration)
 	if videoInfo.BitRate > 0 {
 		ffmpeg.Logger.Info("  Bitrate: %.2f Mbps", float64(videoInfo.BitRate)/1024/1024)
 	}
@@ -399,122 +472,94 @@ func (ffmpeg *FFmpeg) Execute() error {
 	settings := ffmpeg.calculateEncodingSettings(videoInfo, ffmpeg.Options.Quality)
 
 	// Exibir configurações de compressão
-	ffmpeg.Logger.Info("Configurações de compressão:")
+	ffmpeg.Logger.Info("Compression settings:")
 	ffmpeg.Logger.Info("  Codec: %s", settings.VideoCodec)
 	ffmpeg.Logger.Info("  CRF: %d", settings.CRF)
 	ffmpeg.Logger.Info("  Preset: %s", settings.Preset)
 	if settings.MaxWidth > 0 || settings.MaxHeight > 0 {
-		ffmpeg.Logger.Info("  Escala: %dx%d", settings.MaxWidth, settings.MaxHeight)
+		ffmpeg.Logger.Info("  Scale: %dx%d", settings.MaxWidth, settings.MaxHeight)
 	}
 	if settings.TargetBitrate > 0 {
 		ffmpeg.Logger.Info("  Bitrate: %.2f Mbps", float64(settings.TargetBitrate)/1024/1024)
 	} else {
-		ffmpeg.Logger.Info("  Bitrate: Automático (controlado pelo CRF)")
+		ffmpeg.Logger.Info("  Bitrate: Automatic (controlled by CRF)")
 	}
 	
 	// Iniciar compressão
-	ffmpeg.Logger.Info("Iniciando compressão do vídeo...")
+	ffmpeg.Logger.Info("Starting video compression...")
 	
 	// Construir comando FFmpeg
 	args := ffmpeg.buildFFmpegCommand(settings)
 
 	// Add input and output files
-	inputArgs := []string{"-i", ffmpeg.InputFile}
+	inputArgs := []string{"-i", FileArg(ffmpeg.InputFile)}
 	args = append(inputArgs, args...)
-	args = append(args, "-y", ffmpeg.OutputFile)
+	args = append(args, "-y", FileArg(ffmpeg.OutputFile))
 
 	// Log the full command for debugging
-	ffmpeg.Logger.Debug("Executando comando FFmpeg: %s %s", ffmpegPath, strings.Join(args, " "))
-
-	// Execute the command with progress monitoring
-	cmd := exec.Command(ffmpegPath, args...)
-	
-	// Configurar buffers para stdout e stderr
-	var stdoutBuf, stderrBuf bytes.Buffer
-	cmd.Stdout = &stdoutBuf
-	
-	// Configurar captura de stderr para progresso
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		return fmt.Errorf("falha ao capturar saída de erro: %v", err)
-	}
-
-	// Iniciar comando
-	if err := cmd.Start(); err != nil {
-		return fmt.Errorf("falha ao iniciar FFmpeg: %v", err)
-	}
+	ffmpeg.Logger.Debug("Running FFmpeg command: %s %s", ffmpegPath, strings.Join(args, " "))
 
 	// Mostrar progresso
 	progressTracker := util.NewProgressTrackerWithOptions(util.ProgressTrackerOptions{
 		Total:          100, // Total de 100% em vez da duração
-		Description:    "Comprimindo vídeo",
+		Description:    i18n.Tr("Compressing video"),
 		Logger:         ffmpeg.Logger,
 		ShowPercentage: true,
 		ShowSpeed:      false,
 	})
 
-	// Ler stderr para mostrar progresso
-	buf := make([]byte, 2048)
+	// Ler stderr para mostrar progresso e armazenar em stderrBuf
+	var stdoutBuf, stderrBuf bytes.Buffer
 	lastProgress := int64(0)
 	
-	// Capturar a saída de stderr para mostrar o progresso e armazenar em stderrBuf
-	go func() {
-		for {
-			n, err := stderr.Read(buf)
-			if n == 0 || err != nil {
-				break
+	stderr := OutputFunc(func(output string) {
+		stderrBuf.WriteString(output)
+		
+		// Procurar por informações de tempo em qualquer parte da saída
+		timeMatch := strings.Index(output, "time=")
+		if timeMatch != -1 {
+			// Encontrar o fim da string de tempo (até o espaço)
+			endIdx := timeMatch + 5
+			for endIdx < len(output) && output[endIdx] != ' ' {
+				endIdx++
 			}
 			
-			output := string(buf[:n])
-			stderrBuf.WriteString(output)
-			
-			// Procurar por informações de tempo em qualquer parte da saída
-			timeMatch := strings.Index(output, "time=")
-			if timeMatch != -1 {
-				// Encontrar o fim da string de tempo (até o espaço)
-				endIdx := timeMatch + 5
-				for endIdx < len(output) && output[endIdx] != ' ' {
-					endIdx++
-				}
+			if endIdx > timeMatch+5 {
+				timeStr := output[timeMatch+5:endIdx]
+				timeStr = strings.TrimSpace(timeStr)
 				
-				if endIdx > timeMatch+5 {
-					timeStr := output[timeMatch+5:endIdx]
-					timeStr = strings.TrimSpace(timeStr)
+				// Parse time in HH:MM:SS format
+				if len(timeStr) >= 8 { // Garantir que tem pelo menos "HH:MM:SS"
+					currentTime := parseFFmpegTime(timeStr)
 					
-					// Parse time in HH:MM:SS format
-					if len(timeStr) >= 8 { // Garantir que tem pelo menos "HH:MM:SS"
-						currentTime := parseFFmpegTime(timeStr)
+					if currentTime > 0 && videoInfo.Duration > 0 {
+						// Calcular percentual em vez de usar o tempo diretamente
+						percentComplete := int64((currentTime / videoInfo.Duration) * 100.0)
+						if percentComplete > 100 {
+							percentComplete = 100
+						}
 						
-						if currentTime > 0 && videoInfo.Duration > 0 {
-							// Calcular percentual em vez de usar o tempo diretamente
-							percentComplete := int64((currentTime / videoInfo.Duration) * 100.0)
-							if percentComplete > 100 {
-								percentComplete = 100
-							}
-							
-							// Só atualizar se houver mudança significativa ou for o final
-							if percentComplete > lastProgress || percentComplete >= 100 {
-								progressTracker.Update(percentComplete)
-								lastProgress = percentComplete
-							}
+						// Só atualizar se houver mudança significativa ou for o final
+						if percentComplete > lastProgress || percentComplete >= 100 {
+							progressTracker.Update(percentComplete)
+							lastProgress = percentComplete
 						}
 					}
 				}
 			}
-			
-			// Mostrar erro detalhado em modo verbose
-			if ffmpeg.Logger.IsVerbose() && strings.Contains(strings.ToLower(output), "error") {
-				ffmpeg.Logger.Debug("FFmpeg: %s", output)
-			}
 		}
-	}()
+		
+		// Mostrar erro detalhado em modo verbose
+		if ffmpeg.Logger.IsVerbose() && strings.Contains(strings.ToLower(output), "error") {
+			ffmpeg.Logger.Debug("FFmpeg: %s", output)
+		}
+	})
 	
-	// Aguardar comando finalizar
-	err = cmd.Wait()
+	// Executar e aguardar o comando finalizar
+	err = runner.Run(context.Background(), ffmpegPath, args, &stdoutBuf, stderr)
 	if err != nil {
-		// Extrair a mensagem de erro da saída de stderr
-		errorMsg := stderrBuf.String()
-		return fmt.Errorf("FFmpeg falhou: %v\nDetalhes: %s", err, errorMsg)
+		// Classificar o erro a partir da saída de stderr
+		return fmt.Errorf("FFmpeg failed: %w", ClassifyError(err, stderrBuf.String()))
 	}
 	
 	progressTracker.Finish()
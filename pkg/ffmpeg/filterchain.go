@@ -0,0 +1,150 @@
+package ffmpeg
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// FilterStage is the job of a video filter, which decides where it runs in
+// the chain given to -vf
+type FilterStage string
+
+// Video filter stages
+const (
+	FilterDeinterlace FilterStage = "deinterlace" // Turns fields into frames
+	FilterFPS         FilterStage = "fps"         // Changes the frame rate
+	FilterCrop        FilterStage = "crop"        // Cuts the frame to a region
+	FilterDenoise     FilterStage = "denoise"     // Removes grain and noise
+	FilterTonemap     FilterStage = "tonemap"     // Maps HDR to SDR
+	FilterScale       FilterStage = "scale"       // Resizes the frame
+	FilterSubtitles   FilterStage = "subtitles"   // Draws subtitles into the picture
+	FilterROI         FilterStage = "roi"         // Marks regions for the encoder
+	FilterCustom      FilterStage = "custom"      // Chain set directly as video_filter
+)
+
+// filterStages lists the stages in the order their filters run:
+//   - deinterlacing needs the fields as captured, so it comes first
+//   - the frame rate drops frames before the costly filters see them
+//   - cropping works in source pixels, before the frame is resized
+//   - denoising and tone mapping work best at full resolution
+//   - scaling comes last of the filters that change the picture
+//   - subtitles are drawn on the final picture, so they are sharp at the
+//     output size and never cropped
+//   - regions of interest annotate the frames the encoder receives, so they
+//     are written relative to the size those frames have
+var filterStages = []FilterStage{
+	FilterDeinterlace, FilterFPS, FilterCrop, FilterDenoise, FilterTonemap, FilterScale, FilterSubtitles, FilterROI, FilterCustom,
+}
+
+// filterKey returns the setting that holds the filter of stage
+func filterKey(stage FilterStage) string {
+	return "filter_" + string(stage)
+}
+
+// VideoFilter returns the filter of stage in settings, or an empty string
+func VideoFilter(settings map[string]string, stage FilterStage) string {
+	return settings[filterKey(stage)]
+}
+
+// SetVideoFilter puts graph at its stage of the video filter chain of
+// settings and updates settings["video_filter"] to the whole chain. Each
+// stage holds one filter, so a stage already set to another graph is a
+// collision: two parts of the tool scaling the video, say, would otherwise
+// fight over its size.
+func SetVideoFilter(settings map[string]string, stage FilterStage, graph string) error {
+	adoptVideoFilter(settings)
+	key := filterKey(stage)
+	if existing := settings[key]; existing != "" && existing != graph {
+		return i18n.Errorf("%s filter %s collides with %s, already in the video filter chain", stage, graph, existing)
+	}
+	settings[key] = graph
+	settings["video_filter"] = stageChain(settings)
+	return nil
+}
+
+// RemoveVideoFilter takes the filter of stage out of the chain of settings
+func RemoveVideoFilter(settings map[string]string, stage FilterStage) {
+	adoptVideoFilter(settings)
+	delete(settings, filterKey(stage))
+	if chain := stageChain(settings); chain != "" {
+		settings["video_filter"] = chain
+	} else {
+		delete(settings, "video_filter")
+	}
+}
+
+// ClearVideoFilters removes every video filter from settings
+func ClearVideoFilters(settings map[string]string) {
+	for _, stage := range filterStages {
+		delete(settings, filterKey(stage))
+	}
+	delete(settings, "video_filter")
+}
+
+// VideoFilterChain returns the -vf argument of settings: the filters of
+// each stage in order, joined with commas. Settings without stages, such
+// as those of older callers, use their video_filter as is.
+func VideoFilterChain(settings map[string]string) string {
+	if chain := stageChain(settings); chain != "" {
+		return chain
+	}
+	return settings["video_filter"]
+}
+
+// stageChain joins the filters of the stages of settings in order
+func stageChain(settings map[string]string) string {
+	var filters []string
+	for _, stage := range filterStages {
+		if graph := settings[filterKey(stage)]; graph != "" {
+			filters = append(filters, graph)
+		}
+	}
+	return strings.Join(filters, ",")
+}
+
+// OutputFPS returns the frame rate of the encoded video: the rate of the
+// fps stage, such as fps=15 or fps=30000/1001, or else sourceFPS
+func OutputFPS(settings map[string]string, sourceFPS float64) float64 {
+	rate, ok := strings.CutPrefix(VideoFilter(settings, FilterFPS), "fps=")
+	if !ok {
+		return sourceFPS
+	}
+	// Options such as fps=30:round=near follow the rate
+	rate, _, _ = strings.Cut(rate, ":")
+	num, den, fraction := strings.Cut(rate, "/")
+	fps, err := strconv.ParseFloat(num, 64)
+	if err != nil || fps <= 0 {
+		return sourceFPS
+	}
+	if fraction {
+		d, err := strconv.ParseFloat(den, 64)
+		if err != nil || d <= 0 {
+			return sourceFPS
+		}
+		fps /= d
+	}
+	return fps
+}
+
+// adoptVideoFilter keeps a video_filter set directly, without stages, as
+// the custom stage so it is not lost when stages are added
+func adoptVideoFilter(settings map[string]string) {
+	for _, stage := range filterStages {
+		if settings[filterKey(stage)] != "" {
+			return
+		}
+	}
+	if graph := settings["video_filter"]; graph != "" {
+		settings[filterKey(FilterCustom)] = graph
+	}
+}
+
+// FilterPath escapes a file path for use as a filter option value in a
+// filter chain. The value is escaped once for the option and again for the
+// chain, so colons of Windows drive letters, quotes and commas survive.
+func FilterPath(path string) string {
+	option := strings.NewReplacer(`\`, `\\`, `:`, `\:`, `'`, `\'`).Replace(path)
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`).Replace(option)
+}
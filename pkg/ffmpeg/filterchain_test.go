@@ -0,0 +1,71 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetVideoFilter(t *testing.T) {
+	settings := map[string]string{}
+	assert.NoError(t, SetVideoFilter(settings, FilterScale, "scale=-2:720"))
+	assert.NoError(t, SetVideoFilter(settings, FilterROI, "addroi=x=0:y=0:w=10:h=10:qoffset=-1/5"))
+	assert.NoError(t, SetVideoFilter(settings, FilterCrop, "crop=1920:800:0:140"))
+	assert.NoError(t, SetVideoFilter(settings, FilterDeinterlace, "bwdif"))
+
+	// Stages run in their order, whatever order they were set in
+	assert.Equal(t, "bwdif,crop=1920:800:0:140,scale=-2:720,addroi=x=0:y=0:w=10:h=10:qoffset=-1/5", settings["video_filter"])
+	assert.Equal(t, settings["video_filter"], VideoFilterChain(settings))
+	assert.Equal(t, "scale=-2:720", VideoFilter(settings, FilterScale))
+
+	// Setting a stage again to the same filter is fine, another collides
+	assert.NoError(t, SetVideoFilter(settings, FilterScale, "scale=-2:720"))
+	assert.EqualError(t, SetVideoFilter(settings, FilterScale, "scale=-2:480"),
+		"scale filter scale=-2:480 collides with scale=-2:720, already in the video filter chain")
+
+	RemoveVideoFilter(settings, FilterROI)
+	assert.Equal(t, "bwdif,crop=1920:800:0:140,scale=-2:720", settings["video_filter"])
+
+	ClearVideoFilters(settings)
+	assert.Empty(t, settings)
+	assert.Empty(t, VideoFilterChain(settings))
+}
+
+// TestSetVideoFilterKeepsDirectFilter tests that a video_filter set without
+// stages stays in the chain, after the stages
+func TestSetVideoFilterKeepsDirectFilter(t *testing.T) {
+	settings := map[string]string{"video_filter": "hflip"}
+	assert.Equal(t, "hflip", VideoFilterChain(settings))
+
+	assert.NoError(t, SetVideoFilter(settings, FilterFPS, "fps=30"))
+	assert.Equal(t, "fps=30,hflip", settings["video_filter"])
+
+	RemoveVideoFilter(settings, FilterCustom)
+	RemoveVideoFilter(settings, FilterFPS)
+	assert.NotContains(t, settings, "video_filter")
+}
+
+func TestOutputFPS(t *testing.T) {
+	settings := map[string]string{}
+	assert.Equal(t, 59.94, OutputFPS(settings, 59.94))
+
+	for graph, expected := range map[string]float64{
+		"fps=15":            15,
+		"fps=23.976":        23.976,
+		"fps=30000/1001":    30000.0 / 1001,
+		"fps=25:round=near": 25,
+		"fps=source_fps":    59.94,
+		"fps=30/0":          59.94,
+	} {
+		settings = map[string]string{}
+		assert.NoError(t, SetVideoFilter(settings, FilterFPS, graph))
+		assert.Equal(t, expected, OutputFPS(settings, 59.94), graph)
+	}
+}
+
+// TestFilterPath tests that paths survive both levels of filter escaping
+func TestFilterPath(t *testing.T) {
+	assert.Equal(t, "/videos/clip.srt", FilterPath("/videos/clip.srt"))
+	assert.Equal(t, `C\\:\\\\subs\\\\a\,b.srt`, FilterPath(`C:\subs\a,b.srt`))
+	assert.Equal(t, `Bob\\\'s \[1\].ass`, FilterPath("Bob's [1].ass"))
+}
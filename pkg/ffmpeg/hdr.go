@@ -0,0 +1,183 @@
+package ffmpeg
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// HDRMetadata is the HDR metadata carried by the first frames of a video
+type HDRMetadata struct {
+	MasteringDisplay string // x265 master-display value, e.g. G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50)
+	MaxCLL           string // x265 max-cll value: maximum content and frame-average light levels, e.g. 1000,400
+	HDR10Plus        bool   // Whether frames carry HDR10+ (SMPTE 2094-40) dynamic metadata
+	DolbyVision      bool   // Whether frames carry Dolby Vision RPUs
+}
+
+// Dynamic reports whether the video has per-scene HDR metadata, which an
+// encode drops unless it is copied explicitly
+func (m HDRMetadata) Dynamic() bool {
+	return m.HDR10Plus || m.DolbyVision
+}
+
+// hdrProbeFrames is how many frames are read for HDR side data. Dynamic
+// metadata is on every frame, but some encoders leave it off the first.
+const hdrProbeFrames = 3
+
+// HDR10PlusTool is the program that extracts HDR10+ metadata from HEVC
+const HDR10PlusTool = "hdr10plus_tool"
+
+// ProbeHDRMetadata reads the HDR side data of the first frames of filePath
+func (f *FFmpeg) ProbeHDRMetadata(filePath string) (*HDRMetadata, error) {
+	runner := f.runner()
+	ffprobePath, err := runner.ProbePath()
+	if err != nil {
+		return nil, err
+	}
+	if ffprobePath == "" {
+		return nil, fmt.Errorf("ffprobe not found")
+	}
+
+	args := append([]string{
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-read_intervals", "%+#" + strconv.Itoa(hdrProbeFrames),
+		"-show_frames",
+		"-show_entries", "frame=side_data_list",
+		"-print_format", "json",
+	}, RemoteInputArgs(filePath)...)
+	output, err := Output(runner, ffprobePath, append(args, FileArg(filePath)))
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+	return parseHDRMetadata(output)
+}
+
+// parseHDRMetadata reads HDRMetadata from the ffprobe JSON of frames
+func parseHDRMetadata(output []byte) (*HDRMetadata, error) {
+	var probe struct {
+		Frames []struct {
+			SideData []map[string]interface{} `json:"side_data_list"`
+		} `json:"frames"`
+	}
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	metadata := &HDRMetadata{}
+	for _, frame := range probe.Frames {
+		for _, data := range frame.SideData {
+			kind, _ := data["side_data_type"].(string)
+			switch {
+			case kind == "Mastering display metadata" && metadata.MasteringDisplay == "":
+				metadata.MasteringDisplay = masteringDisplay(data)
+			case kind == "Content light level metadata" && metadata.MaxCLL == "":
+				maxContent, _ := data["max_content"].(float64)
+				maxAverage, _ := data["max_average"].(float64)
+				if maxContent > 0 {
+					metadata.MaxCLL = fmt.Sprintf("%d,%d", int(maxContent), int(maxAverage))
+				}
+			case strings.Contains(kind, "HDR10+") || strings.Contains(kind, "SMPTE2094-40"):
+				metadata.HDR10Plus = true
+			case strings.HasPrefix(kind, "Dolby Vision"):
+				metadata.DolbyVision = true
+			}
+		}
+	}
+	return metadata, nil
+}
+
+// masteringDisplay returns the x265 master-display value of the mastering
+// display side data: chromaticities in units of 0.00002 and luminance in
+// units of 0.0001 cd/m². It returns "" when a value is missing.
+func masteringDisplay(data map[string]interface{}) string {
+	var values []int
+	for _, key := range []string{"green_x", "green_y", "blue_x", "blue_y", "red_x", "red_y",
+		"white_point_x", "white_point_y", "max_luminance", "min_luminance"} {
+		value, ok := parseRational(data[key])
+		if !ok {
+			return ""
+		}
+		scale := 50000.0
+		if strings.HasSuffix(key, "luminance") {
+			scale = 10000
+		}
+		values = append(values, int(math.Round(value*scale)))
+	}
+	return fmt.Sprintf("G(%d,%d)B(%d,%d)R(%d,%d)WP(%d,%d)L(%d,%d)",
+		values[0], values[1], values[2], values[3], values[4], values[5], values[6], values[7], values[8], values[9])
+}
+
+// parseRational reads a side data value such as "34000/50000"
+func parseRational(value interface{}) (float64, bool) {
+	text, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	num, den, found := strings.Cut(text, "/")
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, false
+	}
+	if !found {
+		return n, true
+	}
+	d, err := strconv.ParseFloat(den, 64)
+	if err != nil || d == 0 {
+		return 0, false
+	}
+	return n / d, true
+}
+
+// dolbyVisionProfile returns the profile of the Dolby Vision configuration
+// record of an ffprobe stream, or 0 when it has none
+func dolbyVisionProfile(stream map[string]interface{}) int {
+	sideData, _ := stream["side_data_list"].([]interface{})
+	for _, entry := range sideData {
+		data, ok := entry.(map[string]interface{})
+		if !ok || data["side_data_type"] != "DOVI configuration record" {
+			continue
+		}
+		if profile, ok := data["dv_profile"].(float64); ok && profile > 0 {
+			return int(profile)
+		}
+		return -1 // Dolby Vision of an unknown profile
+	}
+	return 0
+}
+
+// ExtractHDR10Plus writes the HDR10+ metadata of the HEVC video of filePath
+// to jsonPath with hdr10plus_tool, in the form x265 reads with
+// --dhdr10-info. The video stream is first copied out as a raw HEVC
+// bitstream next to jsonPath.
+func (f *FFmpeg) ExtractHDR10Plus(filePath, jsonPath string) error {
+	toolPath, err := exec.LookPath(HDR10PlusTool)
+	if err != nil {
+		return i18n.Errorf("%s was not found on the PATH", HDR10PlusTool)
+	}
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return i18n.Errorf("failed to find FFmpeg: %v", err)
+	}
+
+	hevcPath := strings.TrimSuffix(jsonPath, ".json") + ".hevc"
+	defer os.Remove(hevcPath)
+	args := append([]string{"-y", "-v", "error"}, RemoteInputArgs(filePath)...)
+	args = append(args, "-i", FileArg(filePath), "-map", "0:v:0", "-c:v", "copy",
+		"-bsf:v", "hevc_mp4toannexb", "-f", "hevc", FileArg(hevcPath))
+	if _, err := CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return fmt.Errorf("failed to copy out the HEVC stream: %w", err)
+	}
+
+	if _, err := CombinedOutput(runner, toolPath, []string{"extract", "-i", hevcPath, "-o", jsonPath}); err != nil {
+		return fmt.Errorf("%s failed: %w", HDR10PlusTool, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const hdrFramesJSON = `{"frames":[{"side_data_list":[
+	{"side_data_type":"Mastering display metadata","red_x":"34000/50000","red_y":"16000/50000","green_x":"13250/50000","green_y":"34500/50000","blue_x":"7500/50000","blue_y":"3000/50000","white_point_x":"15635/50000","white_point_y":"16450/50000","min_luminance":"50/10000","max_luminance":"10000000/10000"},
+	{"side_data_type":"Content light level metadata","max_content":1000,"max_average":400},
+	{"side_data_type":"HDR Dynamic Metadata SMPTE2094-40 (HDR10+)","application_version":1}
+]},{"side_data_list":[{"side_data_type":"HDR Dynamic Metadata SMPTE2094-40 (HDR10+)"}]}]}`
+
+func TestProbeHDRMetadata(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError)
+	runner := &fakeRunner{probePath: "/opt/ffmpeg/ffprobe", output: map[string]string{"/opt/ffmpeg/ffprobe": hdrFramesJSON}}
+	f := NewFFmpeg("", "", nil, logger)
+	f.Runner = runner
+
+	metadata, err := f.ProbeHDRMetadata("movie.mkv")
+	assert.NoError(t, err)
+	assert.Equal(t, &HDRMetadata{
+		MasteringDisplay: "G(13250,34500)B(7500,3000)R(34000,16000)WP(15635,16450)L(10000000,50)",
+		MaxCLL:           "1000,400",
+		HDR10Plus:        true,
+	}, metadata)
+	assert.True(t, metadata.Dynamic())
+	assert.Contains(t, runner.calls[0], "%+#3")
+
+	// SDR frames have no side data
+	metadata, err = parseHDRMetadata([]byte(`{"frames":[{}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, &HDRMetadata{}, metadata)
+
+	// Without ffprobe the frames cannot be read
+	runner.probePath = ""
+	_, err = f.ProbeHDRMetadata("movie.mkv")
+	assert.Error(t, err)
+}
+
+// TestGetVideoInfoHDR tests that the color properties and Dolby Vision
+// configuration of a stream are read
+func TestGetVideoInfoHDR(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError)
+	runner := &fakeRunner{probePath: "/opt/ffmpeg/ffprobe", output: map[string]string{
+		"/opt/ffmpeg/ffprobe": `{"format":{"duration":"60"},"streams":[{"codec_type":"video","codec_name":"hevc","width":3840,"height":2160,
+			"color_space":"bt2020nc","color_transfer":"smpte2084","color_primaries":"bt2020",
+			"side_data_list":[{"side_data_type":"DOVI configuration record","dv_profile":8,"dv_level":6}]}]}`,
+	}}
+	f := NewFFmpeg("", "", nil, logger)
+	f.Runner = runner
+
+	videoFile, err := f.GetVideoInfo("movie.mkv")
+	assert.NoError(t, err)
+	info := videoFile.VideoInfo
+	assert.True(t, info.IsHDR)
+	assert.Equal(t, "smpte2084", info.ColorTransfer)
+	assert.Equal(t, "bt2020", info.ColorPrimaries)
+	assert.Equal(t, "bt2020nc", info.ColorSpace)
+	assert.Equal(t, 8, info.DolbyVision)
+
+	assert.Equal(t, 0, dolbyVisionProfile(map[string]interface{}{}))
+}
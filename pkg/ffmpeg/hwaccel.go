@@ -0,0 +1,69 @@
+package ffmpeg
+
+import (
+	"strings"
+)
+
+// Supported hardware decoders for the -hwaccel option
+const (
+	HWAccelNone  = ""
+	HWAccelCUDA  = "cuda"
+	HWAccelQSV   = "qsv"
+	HWAccelVAAPI = "vaapi"
+)
+
+// defaultVAAPIDevice is the render node used for VAAPI decoding
+const defaultVAAPIDevice = "/dev/dri/renderD128"
+
+// ValidHWAccel reports whether name is a supported hardware decoder
+func ValidHWAccel(name string) bool {
+	switch name {
+	case HWAccelNone, HWAccelCUDA, HWAccelQSV, HWAccelVAAPI:
+		return true
+	}
+	return false
+}
+
+// HWAccelMatchesEncoder reports whether frames decoded with hwaccel can be
+// passed to codec without leaving the GPU
+func HWAccelMatchesEncoder(hwaccel, codec string) bool {
+	switch hwaccel {
+	case HWAccelCUDA:
+		return strings.HasSuffix(codec, "_nvenc")
+	case HWAccelQSV:
+		return strings.HasSuffix(codec, "_qsv")
+	case HWAccelVAAPI:
+		return strings.HasSuffix(codec, "_vaapi")
+	}
+	return false
+}
+
+// HWAccelArgs returns the input options that decode with hwaccel. When
+// keepOnGPU is set the decoded frames stay in GPU memory for a matching
+// hardware encoder; otherwise they are copied back for CPU filters.
+func HWAccelArgs(hwaccel string, keepOnGPU bool) []string {
+	if hwaccel == HWAccelNone {
+		return nil
+	}
+
+	args := []string{"-hwaccel", hwaccel}
+	if hwaccel == HWAccelVAAPI {
+		args = append(args, "-hwaccel_device", defaultVAAPIDevice)
+	}
+	if keepOnGPU {
+		args = append(args, "-hwaccel_output_format", hwaccel)
+	}
+	return args
+}
+
+// inputArgs returns the arguments that open filePath for analysis, decoding
+// on the GPU when a hardware decoder is configured. Remote inputs are only
+// sampled.
+func (f *FFmpeg) inputArgs(filePath string) []string {
+	var args []string
+	if f.Options != nil {
+		args = HWAccelArgs(f.Options.HWAccel, false)
+	}
+	args = append(args, analysisInputArgs(filePath)...)
+	return append(args, "-i", FileArg(filePath))
+}
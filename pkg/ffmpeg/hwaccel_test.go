@@ -0,0 +1,33 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidHWAccel(t *testing.T) {
+	assert.True(t, ValidHWAccel(""))
+	assert.True(t, ValidHWAccel("cuda"))
+	assert.True(t, ValidHWAccel("qsv"))
+	assert.True(t, ValidHWAccel("vaapi"))
+	assert.False(t, ValidHWAccel("opencl"))
+}
+
+func TestHWAccelMatchesEncoder(t *testing.T) {
+	assert.True(t, HWAccelMatchesEncoder("cuda", "hevc_nvenc"))
+	assert.True(t, HWAccelMatchesEncoder("qsv", "h264_qsv"))
+	assert.True(t, HWAccelMatchesEncoder("vaapi", "hevc_vaapi"))
+	assert.False(t, HWAccelMatchesEncoder("cuda", "libx265"))
+	assert.False(t, HWAccelMatchesEncoder("", "h264_nvenc"))
+}
+
+func TestHWAccelArgs(t *testing.T) {
+	assert.Nil(t, HWAccelArgs("", true))
+	assert.Equal(t, []string{"-hwaccel", "cuda"}, HWAccelArgs("cuda", false))
+	assert.Equal(t, []string{"-hwaccel", "cuda", "-hwaccel_output_format", "cuda"}, HWAccelArgs("cuda", true))
+	assert.Equal(t, []string{"-hwaccel", "vaapi", "-hwaccel_device", "/dev/dri/renderD128"}, HWAccelArgs("vaapi", false))
+
+	f := NewFFmpeg("in.mp4", "", &Options{HWAccel: "qsv"}, nil)
+	assert.Equal(t, []string{"-hwaccel", "qsv", "-i", "in.mp4"}, f.inputArgs("in.mp4"))
+}
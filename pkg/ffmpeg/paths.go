@@ -0,0 +1,55 @@
+package ffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// urlSchemeChars are the characters FFmpeg accepts in a protocol name
+const urlSchemeChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789+-."
+
+// FileArg returns path as an FFmpeg or FFprobe input or output argument.
+// Local names that FFmpeg would read as an option ("-intro.mp4") or as a
+// protocol ("Part1:Intro.mp4") are given the file: protocol. Spaces, quotes,
+// emoji and non-UTF-8 bytes need no escaping on the command line, so other
+// paths and URLs are returned unchanged.
+func FileArg(path string) string {
+	if path == "" || IsRemote(path) {
+		return path
+	}
+	if strings.HasPrefix(path, "-") || hasProtocolPrefix(path) {
+		return "file:" + path
+	}
+	return path
+}
+
+// hasProtocolPrefix reports whether FFmpeg would take the start of path, up
+// to its first colon, for a protocol name
+func hasProtocolPrefix(path string) bool {
+	// Windows drive letters (C:\Videos) are paths to FFmpeg
+	if filepath.VolumeName(path) != "" {
+		return false
+	}
+	n := 0
+	for n < len(path) && strings.IndexByte(urlSchemeChars, path[n]) >= 0 {
+		n++
+	}
+	return n > 0 && n < len(path) && path[n] == ':'
+}
+
+// ConcatEntry returns the concat demuxer line that lists path. The path is
+// single-quoted, and each quote inside it is written as a closing quote, an
+// escaped quote and an opening quote, as the demuxer's own parser expects.
+func ConcatEntry(path string) string {
+	return "file '" + strings.ReplaceAll(path, "'", `'\''`) + "'\n"
+}
+
+// WriteConcatList writes a concat demuxer list of files, in order, to listPath
+func WriteConcatList(listPath string, files []string) error {
+	var list strings.Builder
+	for _, file := range files {
+		list.WriteString(ConcatEntry(file))
+	}
+	return os.WriteFile(listPath, []byte(list.String()), 0644)
+}
@@ -0,0 +1,46 @@
+package ffmpeg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileArg(t *testing.T) {
+	// Names FFmpeg reads correctly are passed unchanged
+	for _, path := range []string{
+		"movie.mp4",
+		"/videos/My Holiday (2023).mp4",
+		"/videos/It's \"fine\".mkv",
+		"/videos/🎬 festa.mp4",
+		"/videos/caf\xe9.mp4", // Latin-1 name, not valid UTF-8
+		"Part 1: Intro.mp4",   // The space ends the protocol candidate
+		"/videos/a:b.mp4",
+		"https://cdn.example.com/talk.mp4",
+		"",
+	} {
+		assert.Equal(t, path, FileArg(path), path)
+	}
+
+	// Names FFmpeg would take for an option or a protocol
+	assert.Equal(t, "file:-intro.mp4", FileArg("-intro.mp4"))
+	assert.Equal(t, "file:Part1:Intro.mp4", FileArg("Part1:Intro.mp4"))
+	assert.Equal(t, "file:clip.v2:final.mkv", FileArg("clip.v2:final.mkv"))
+}
+
+func TestConcatEntry(t *testing.T) {
+	assert.Equal(t, "file 'out_0001.mp4'\n", ConcatEntry("out_0001.mp4"))
+	assert.Equal(t, "file '/tmp/It'\\''s here/out 1.mp4'\n", ConcatEntry("/tmp/It's here/out 1.mp4"))
+	assert.Equal(t, "file '/tmp/🎬/caf\xe9.mp4'\n", ConcatEntry("/tmp/🎬/caf\xe9.mp4"))
+}
+
+func TestWriteConcatList(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "segments.txt")
+	assert.NoError(t, WriteConcatList(listPath, []string{"out_0000.mp4", "It's 1.mp4"}))
+
+	data, err := os.ReadFile(listPath)
+	assert.NoError(t, err)
+	assert.Equal(t, "file 'out_0000.mp4'\nfile 'It'\\''s 1.mp4'\n", string(data))
+}
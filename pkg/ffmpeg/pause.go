@@ -0,0 +1,189 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// PauseUser is the reason of the pauses Pause and Resume make for the user
+const PauseUser = "user"
+
+// pause tracks the processes started by ExecRunner so the user can suspend
+// every running encode to free the CPU, then resume it where it stopped.
+// Pauses are held for reasons, such as the user or a schedule, and the
+// processes only run while no reason holds them.
+var pause = struct {
+	mu        sync.Mutex
+	holds     map[string]bool // Reasons the processes are paused for
+	since     time.Time       // Start of the current pause
+	total     time.Duration   // Time spent in earlier pauses
+	resumed   chan struct{}   // Closed on resuming, nil while running
+	processes map[int]bool    // IDs of the running processes
+	suspend   func(pid int) error
+	resume    func(pid int) error
+}{
+	holds:     map[string]bool{},
+	processes: map[int]bool{},
+	suspend:   util.SuspendProcess,
+	resume:    util.ResumeProcess,
+}
+
+// Pause suspends the running FFmpeg processes for the user. Processes
+// started while paused wait for Resume before they start. Pausing twice has
+// no effect.
+func Pause() error {
+	return Hold(PauseUser)
+}
+
+// Resume releases the pause of the user. The processes continue unless
+// another reason holds them.
+func Resume() error {
+	return Release(PauseUser)
+}
+
+// Hold pauses the FFmpeg processes for reason until Release is called with
+// it. Holding a reason twice has no effect.
+func Hold(reason string) error {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	if pause.holds[reason] {
+		return nil
+	}
+	pause.holds[reason] = true
+	if len(pause.holds) > 1 {
+		return nil
+	}
+	pause.since = time.Now()
+	pause.resumed = make(chan struct{})
+
+	var errs []error
+	for pid := range pause.processes {
+		if err := pause.suspend(pid); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Release drops the pause held for reason, and continues the suspended
+// processes when no other reason holds them
+func Release(reason string) error {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	if !pause.holds[reason] {
+		return nil
+	}
+	delete(pause.holds, reason)
+	if len(pause.holds) > 0 {
+		return nil
+	}
+	return resumeLocked()
+}
+
+// ReleaseAll drops every pause and continues the suspended processes
+func ReleaseAll() error {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	if len(pause.holds) == 0 {
+		return nil
+	}
+	pause.holds = map[string]bool{}
+	return resumeLocked()
+}
+
+// resumeLocked continues the suspended processes; pause.mu must be held
+func resumeLocked() error {
+	pause.total += time.Since(pause.since)
+	close(pause.resumed)
+	pause.resumed = nil
+
+	var errs []error
+	for pid := range pause.processes {
+		if err := pause.resume(pid); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Paused reports whether the FFmpeg processes are paused, for any reason
+func Paused() bool {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+	return len(pause.holds) > 0
+}
+
+// Held reports whether the FFmpeg processes are paused for reason
+func Held(reason string) bool {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+	return pause.holds[reason]
+}
+
+// PauseReasons returns the reasons the FFmpeg processes are paused for,
+// in alphabetical order
+func PauseReasons() []string {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	reasons := make([]string, 0, len(pause.holds))
+	for reason := range pause.holds {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// PausedTime returns the total time spent paused, including the current
+// pause, so time limits can leave it out
+func PausedTime() time.Duration {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	if len(pause.holds) > 0 {
+		return pause.total + time.Since(pause.since)
+	}
+	return pause.total
+}
+
+// waitWhilePaused blocks until the processes are resumed or ctx is done
+func waitWhilePaused(ctx context.Context) error {
+	pause.mu.Lock()
+	resumed := pause.resumed
+	pause.mu.Unlock()
+
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-resumed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// trackProcess records a started process until the returned function is
+// called. A process started as a pause begins is suspended right away.
+func trackProcess(pid int) (release func()) {
+	pause.mu.Lock()
+	defer pause.mu.Unlock()
+
+	pause.processes[pid] = true
+	if len(pause.holds) > 0 {
+		pause.suspend(pid)
+	}
+	return func() {
+		pause.mu.Lock()
+		defer pause.mu.Unlock()
+		delete(pause.processes, pid)
+	}
+}
@@ -0,0 +1,92 @@
+package ffmpeg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPauseResume tests that running and newly started processes are
+// suspended while paused and resumed afterwards
+func TestPauseResume(t *testing.T) {
+	var suspended, resumed []int
+	suspend, resume := pause.suspend, pause.resume
+	pause.suspend = func(pid int) error { suspended = append(suspended, pid); return nil }
+	pause.resume = func(pid int) error { resumed = append(resumed, pid); return nil }
+	defer func() {
+		pause.suspend, pause.resume = suspend, resume
+	}()
+
+	release := trackProcess(100)
+	assert.NoError(t, Pause())
+	assert.NoError(t, Pause()) // Already paused
+	assert.True(t, Paused())
+	assert.Equal(t, []int{100}, suspended)
+
+	// A process started as the pause begins is suspended right away
+	releaseLate := trackProcess(200)
+	assert.Equal(t, []int{100, 200}, suspended)
+	releaseLate()
+
+	// New processes wait for the resume
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, waitWhilePaused(ctx))
+
+	waited := make(chan error)
+	go func() { waited <- waitWhilePaused(context.Background()) }()
+	time.Sleep(10 * time.Millisecond)
+	pausedTime := PausedTime()
+	assert.True(t, pausedTime >= 20*time.Millisecond, pausedTime.String())
+
+	assert.NoError(t, Resume())
+	assert.NoError(t, <-waited)
+	assert.False(t, Paused())
+	assert.Equal(t, []int{100}, resumed)
+	assert.True(t, PausedTime() >= pausedTime)
+
+	release()
+	assert.Empty(t, pause.processes)
+	assert.NoError(t, Resume()) // Not paused
+	assert.NoError(t, waitWhilePaused(context.Background()))
+}
+
+// TestPauseHolds tests that processes only resume once every reason that
+// paused them is released
+func TestPauseHolds(t *testing.T) {
+	var suspended, resumed []int
+	suspend, resume := pause.suspend, pause.resume
+	pause.suspend = func(pid int) error { suspended = append(suspended, pid); return nil }
+	pause.resume = func(pid int) error { resumed = append(resumed, pid); return nil }
+	defer func() {
+		pause.suspend, pause.resume = suspend, resume
+	}()
+
+	release := trackProcess(300)
+	defer release()
+	assert.NoError(t, Hold("schedule"))
+	assert.NoError(t, Pause())
+	assert.Equal(t, []int{300}, suspended)
+	assert.True(t, Held("schedule"))
+	assert.True(t, Held(PauseUser))
+	assert.Equal(t, []string{"schedule", PauseUser}, PauseReasons())
+
+	// The user resuming leaves the schedule's pause in place
+	assert.NoError(t, Resume())
+	assert.True(t, Paused())
+	assert.False(t, Held(PauseUser))
+	assert.Empty(t, resumed)
+
+	assert.NoError(t, Release("schedule"))
+	assert.False(t, Paused())
+	assert.Equal(t, []int{300}, resumed)
+
+	assert.NoError(t, Hold("schedule"))
+	assert.NoError(t, Hold("battery"))
+	assert.NoError(t, ReleaseAll())
+	assert.False(t, Paused())
+	assert.False(t, Held("battery"))
+	assert.Equal(t, []int{300, 300}, resumed)
+}
@@ -0,0 +1,262 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	durationLineRegex = regexp.MustCompile(`Duration:\s*(\d+):(\d+):(\d+(?:\.\d+)?)`)
+	bitrateLineRegex  = regexp.MustCompile(`bitrate:\s*(\d+)\s*kb/s`)
+	streamLineRegex   = regexp.MustCompile(`Stream #\d+:(\d+)(?:\[0x[0-9a-fA-F]+\])?(?:\(([^)]*)\))?:\s*(Video|Audio):\s*(.*)$`)
+	resolutionRegex   = regexp.MustCompile(`^(\d{2,5})x(\d{2,5})`)
+	kbpsRegex         = regexp.MustCompile(`^(\d+)\s*kb/s`)
+	fpsRegex          = regexp.MustCompile(`^(\d+(?:\.\d+)?)(k?)\s*(fps|tbr)$`)
+	sampleRateRegex   = regexp.MustCompile(`^(\d+)\s*Hz$`)
+	channelsRegex     = regexp.MustCompile(`^(\d+)\s*channels`)
+	aspectRegex       = regexp.MustCompile(`\[SAR (\d+:\d+) DAR (\d+:\d+)\]`)
+)
+
+// getVideoInfoFromFFmpeg extracts basic stream information by running
+// `ffmpeg -i` and parsing its stderr. It is used when ffprobe is not available.
+func (f *FFmpeg) getVideoInfoFromFFmpeg(filePath string) (*VideoFile, error) {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return nil, err
+	}
+
+	// ffmpeg exits with an error because no output is given, so the exit
+	// status is ignored and only the printed stream information is used
+	output, _ := CombinedOutput(runner, ffmpegPath, []string{"-hide_banner", "-i", FileArg(filePath)})
+
+	videoFile, err := parseFFmpegInputInfo(string(output))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ffmpeg output: %w", err)
+	}
+
+	videoFile.Path = filePath
+	videoFile.Format = pathExtension(filePath)
+	if stat, err := os.Stat(filePath); err == nil {
+		videoFile.Size = stat.Size()
+	}
+
+	return videoFile, nil
+}
+
+// parseFFmpegInputInfo parses the "Input #0" block printed by `ffmpeg -i`
+func parseFFmpegInputInfo(output string) (*VideoFile, error) {
+	videoFile := &VideoFile{
+		Metadata:  make(map[string]string),
+		AudioInfo: []AudioStreamInfo{},
+	}
+
+	foundVideo := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+
+		if strings.HasPrefix(line, "Duration:") {
+			if m := durationLineRegex.FindStringSubmatch(line); m != nil {
+				hours, _ := strconv.ParseFloat(m[1], 64)
+				minutes, _ := strconv.ParseFloat(m[2], 64)
+				seconds, _ := strconv.ParseFloat(m[3], 64)
+				videoFile.Duration = hours*3600 + minutes*60 + seconds
+			}
+			if m := bitrateLineRegex.FindStringSubmatch(line); m != nil {
+				kbps, _ := strconv.ParseInt(m[1], 10, 64)
+				videoFile.BitRate = kbps * 1000
+			}
+			continue
+		}
+
+		m := streamLineRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		index, _ := strconv.Atoi(m[1])
+		language := m[2]
+		fields := splitStreamFields(m[4])
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch m[3] {
+		case "Video":
+			// Only the first video stream is used, matching the ffprobe path
+			if foundVideo {
+				continue
+			}
+			foundVideo = true
+			videoFile.VideoInfo = parseVideoStreamFields(fields)
+		case "Audio":
+			audio := parseAudioStreamFields(fields)
+			audio.Index = index
+			if language != "und" {
+				audio.Language = language
+			}
+			audio.Default = strings.HasSuffix(line, "(default)")
+			videoFile.AudioInfo = append(videoFile.AudioInfo, audio)
+		}
+	}
+
+	if !foundVideo {
+		return nil, fmt.Errorf("no video stream found")
+	}
+
+	return videoFile, nil
+}
+
+// parseVideoStreamFields fills a VideoStreamInfo from the comma separated
+// description of an ffmpeg video stream line
+func parseVideoStreamFields(fields []string) VideoStreamInfo {
+	info := VideoStreamInfo{}
+	info.Codec, info.ProfileLevel = splitCodecField(fields[0])
+	if strings.Contains(strings.ToLower(info.ProfileLevel), "high") {
+		info.HasBFrames = true
+	}
+
+	for i, field := range fields[1:] {
+		lower := strings.ToLower(field)
+		if strings.Contains(lower, "smpte2084") {
+			info.IsHDR, info.ColorTransfer = true, "smpte2084"
+		} else if strings.Contains(lower, "arib-std-b67") {
+			info.IsHDR, info.ColorTransfer = true, "arib-std-b67"
+		}
+
+		// The pixel format always follows the codec
+		if i == 0 {
+			pixFmt := field
+			if idx := strings.Index(pixFmt, "("); idx >= 0 {
+				pixFmt = pixFmt[:idx]
+			}
+			info.PixelFormat = strings.TrimSpace(pixFmt)
+			continue
+		}
+
+		if m := resolutionRegex.FindStringSubmatch(field); m != nil {
+			info.Width, _ = strconv.Atoi(m[1])
+			info.Height, _ = strconv.Atoi(m[2])
+			if m := aspectRegex.FindStringSubmatch(field); m != nil {
+				info.SAR, info.DAR = knownRatio(m[1]), knownRatio(m[2])
+			}
+		} else if m := kbpsRegex.FindStringSubmatch(field); m != nil {
+			kbps, _ := strconv.ParseInt(m[1], 10, 64)
+			info.BitRate = kbps * 1000
+		} else if m := fpsRegex.FindStringSubmatch(field); m != nil {
+			// Prefer fps; tbr is only used when fps is missing
+			if m[3] == "fps" || info.FPS == 0 {
+				fps, _ := strconv.ParseFloat(m[1], 64)
+				if m[2] == "k" {
+					fps *= 1000
+				}
+				info.FPS = fps
+			}
+		}
+	}
+
+	return info
+}
+
+// parseAudioStreamFields fills an AudioStreamInfo from the comma separated
+// description of an ffmpeg audio stream line
+func parseAudioStreamFields(fields []string) AudioStreamInfo {
+	info := AudioStreamInfo{}
+	info.Codec, _ = splitCodecField(fields[0])
+
+	for _, field := range fields[1:] {
+		if m := sampleRateRegex.FindStringSubmatch(field); m != nil {
+			info.SampleRate, _ = strconv.Atoi(m[1])
+		} else if m := kbpsRegex.FindStringSubmatch(field); m != nil {
+			kbps, _ := strconv.ParseInt(m[1], 10, 64)
+			info.BitRate = kbps * 1000
+		} else if channels := parseChannelLayout(field); channels > 0 && info.Channels == 0 {
+			info.Channels = channels
+		}
+	}
+
+	return info
+}
+
+// parseChannelLayout converts an ffmpeg channel layout name to a channel count
+func parseChannelLayout(layout string) int {
+	if m := channelsRegex.FindStringSubmatch(layout); m != nil {
+		channels, _ := strconv.Atoi(m[1])
+		return channels
+	}
+
+	if idx := strings.Index(layout, "("); idx >= 0 {
+		layout = layout[:idx]
+	}
+
+	switch layout {
+	case "mono":
+		return 1
+	case "stereo", "downmix":
+		return 2
+	case "2.1", "3.0":
+		return 3
+	case "quad", "4.0", "3.1":
+		return 4
+	case "5.0", "4.1":
+		return 5
+	case "5.1", "6.0":
+		return 6
+	case "6.1", "7.0":
+		return 7
+	case "7.1":
+		return 8
+	}
+	return 0
+}
+
+// splitCodecField separates "h264 (High) (avc1 / 0x31637661)" into the codec
+// name and its profile
+func splitCodecField(field string) (string, string) {
+	parts := strings.SplitN(field, " ", 2)
+	codec := parts[0]
+	profile := ""
+	if len(parts) == 2 {
+		rest := strings.TrimSpace(parts[1])
+		if strings.HasPrefix(rest, "(") {
+			if end := strings.Index(rest, ")"); end > 0 {
+				candidate := rest[1:end]
+				// Skip codec tags like "avc1 / 0x31637661"
+				if !strings.Contains(candidate, "/") {
+					profile = candidate
+				}
+			}
+		}
+	}
+	return codec, profile
+}
+
+// splitStreamFields splits a stream description on commas that are not
+// enclosed in parentheses or brackets
+func splitStreamFields(description string) []string {
+	fields := []string{}
+	depth := 0
+	start := 0
+	for i, r := range description {
+		switch r {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				fields = append(fields, strings.TrimSpace(description[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(description[start:]); last != "" {
+		fields = append(fields, last)
+	}
+	return fields
+}
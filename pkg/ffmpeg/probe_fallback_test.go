@@ -0,0 +1,87 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseFFmpegInputInfo tests extracting stream info from `ffmpeg -i` output
+func TestParseFFmpegInputInfo(t *testing.T) {
+	output := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'sample.mp4':
+  Metadata:
+    major_brand     : isom
+    encoder         : Lavf58.29.100
+  Duration: 00:01:30.53, start: 0.000000, bitrate: 1205 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(tv, bt709, progressive), 1280x720 [SAR 1:1 DAR 16:9], 1072 kb/s, 29.97 fps, 29.97 tbr, 30k tbn (default)
+  Stream #0:1[0x2](eng): Audio: aac (LC) (mp4a / 0x6134706D), 48000 Hz, stereo, fltp, 128 kb/s (default)
+  Stream #0:2[0x3](por): Audio: ac3 (ac-3 / 0x332D6361), 48000 Hz, 5.1(side), fltp, 384 kb/s
+At least one output file must be specified`
+
+	videoFile, err := parseFFmpegInputInfo(output)
+	assert.NoError(t, err)
+
+	assert.InDelta(t, 90.53, videoFile.Duration, 0.001)
+	assert.Equal(t, int64(1205000), videoFile.BitRate)
+
+	assert.Equal(t, "h264", videoFile.VideoInfo.Codec)
+	assert.Equal(t, "High", videoFile.VideoInfo.ProfileLevel)
+	assert.Equal(t, "yuv420p", videoFile.VideoInfo.PixelFormat)
+	assert.Equal(t, 1280, videoFile.VideoInfo.Width)
+	assert.Equal(t, 720, videoFile.VideoInfo.Height)
+	assert.Equal(t, int64(1072000), videoFile.VideoInfo.BitRate)
+	assert.InDelta(t, 29.97, videoFile.VideoInfo.FPS, 0.001)
+	assert.False(t, videoFile.VideoInfo.IsHDR)
+	assert.Equal(t, "1:1", videoFile.VideoInfo.SAR)
+	assert.Equal(t, "16:9", videoFile.VideoInfo.DAR)
+
+	assert.Equal(t, 2, len(videoFile.AudioInfo))
+	assert.Equal(t, "aac", videoFile.AudioInfo[0].Codec)
+	assert.Equal(t, 48000, videoFile.AudioInfo[0].SampleRate)
+	assert.Equal(t, 2, videoFile.AudioInfo[0].Channels)
+	assert.Equal(t, "eng", videoFile.AudioInfo[0].Language)
+	assert.Equal(t, 1, videoFile.AudioInfo[0].Index)
+	assert.True(t, videoFile.AudioInfo[0].Default)
+	assert.False(t, videoFile.AudioInfo[1].Default)
+	assert.Equal(t, 6, videoFile.AudioInfo[1].Channels)
+	assert.Equal(t, int64(384000), videoFile.AudioInfo[1].BitRate)
+}
+
+// TestParseFFmpegInputInfoHDR tests HDR detection and missing fps
+func TestParseFFmpegInputInfoHDR(t *testing.T) {
+	output := `  Duration: 00:00:10.00, start: 0.000000, bitrate: N/A
+    Stream #0:0: Video: hevc (Main 10), yuv420p10le(tv, bt2020nc/bt2020/smpte2084), 3840x2160, 25 tbr, 1k tbn`
+
+	videoFile, err := parseFFmpegInputInfo(output)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, videoFile.Duration)
+	assert.Equal(t, int64(0), videoFile.BitRate)
+	assert.Equal(t, "hevc", videoFile.VideoInfo.Codec)
+	assert.Equal(t, "yuv420p10le", videoFile.VideoInfo.PixelFormat)
+	assert.True(t, videoFile.VideoInfo.IsHDR)
+	assert.Equal(t, 25.0, videoFile.VideoInfo.FPS)
+}
+
+// TestParseFFmpegInputInfoNoVideo tests that audio-only inputs are rejected
+func TestParseFFmpegInputInfoNoVideo(t *testing.T) {
+	output := `  Duration: 00:03:00.00, start: 0.000000, bitrate: 320 kb/s
+  Stream #0:0: Audio: mp3, 44100 Hz, stereo, fltp, 320 kb/s`
+
+	_, err := parseFFmpegInputInfo(output)
+	assert.Error(t, err)
+}
+
+// TestParseFFmpegInputInfoAnamorphic tests reading the aspect ratios of
+// video with non-square pixels
+func TestParseFFmpegInputInfoAnamorphic(t *testing.T) {
+	output := `  Duration: 00:00:30.00, start: 0.000000, bitrate: 28771 kb/s
+    Stream #0:0: Video: dvvideo, yuv411p, 720x480 [SAR 8:9 DAR 4:3], 25000 kb/s, 29.97 fps, 29.97 tbr, 29.97 tbn`
+
+	videoFile, err := parseFFmpegInputInfo(output)
+	assert.NoError(t, err)
+	assert.Equal(t, 720, videoFile.VideoInfo.Width)
+	assert.Equal(t, "8:9", videoFile.VideoInfo.SAR)
+	assert.Equal(t, "4:3", videoFile.VideoInfo.DAR)
+	assert.True(t, videoFile.VideoInfo.IsAnamorphic())
+	assert.Equal(t, 640, videoFile.VideoInfo.DisplayWidth())
+}
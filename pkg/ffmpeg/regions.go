@@ -0,0 +1,73 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Region is a rectangle of the video frame, in pixels
+type Region struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// String formats the region as WxH+X+Y
+func (r Region) String() string {
+	return fmt.Sprintf("%dx%d+%d+%d", r.Width, r.Height, r.X, r.Y)
+}
+
+// RegionMotion measures how much a region of the video changes over time. It
+// samples one frame per second and returns the average luma difference
+// between consecutive samples (0 = static, higher = more motion).
+func (f *FFmpeg) RegionMotion(filePath string, region Region) (float64, error) {
+	filter := fmt.Sprintf("fps=1,crop=%d:%d:%d:%d,tblend=all_mode=difference,signalstats,metadata=print:key=lavfi.signalstats.YAVG",
+		region.Width, region.Height, region.X, region.Y)
+	args := append(f.inputArgs(filePath),
+		"-an",
+		"-vf", filter,
+		"-f", "null",
+		"-",
+	)
+
+	output, err := f.ExecuteCommand(args)
+	if err != nil {
+		return 0, fmt.Errorf("region motion analysis failed: %w", err)
+	}
+
+	motion, ok := parseAverageYAVG(string(output))
+	if !ok {
+		return 0, fmt.Errorf("no frames analyzed for region %s", region)
+	}
+	return motion, nil
+}
+
+// parseAverageYAVG averages the lavfi.signalstats.YAVG values printed by the
+// metadata filter
+func parseAverageYAVG(output string) (float64, bool) {
+	var total float64
+	var count int
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.Index(line, "lavfi.signalstats.YAVG=")
+		if idx < 0 {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(line[idx+len("lavfi.signalstats.YAVG="):]), 64)
+		if err != nil {
+			continue
+		}
+		total += value
+		count++
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
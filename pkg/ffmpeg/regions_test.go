@@ -0,0 +1,26 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAverageYAVG(t *testing.T) {
+	output := `[Parsed_metadata_4 @ 0x55] frame:0    pts:0       pts_time:0
+[Parsed_metadata_4 @ 0x55] lavfi.signalstats.YAVG=1.5
+[Parsed_metadata_4 @ 0x55] frame:1    pts:1       pts_time:1
+[Parsed_metadata_4 @ 0x55] lavfi.signalstats.YAVG=4.5
+frame=    2 fps=0.0 q=-0.0 Lsize=N/A time=00:00:02.00`
+
+	motion, ok := parseAverageYAVG(output)
+	assert.True(t, ok)
+	assert.Equal(t, 3.0, motion)
+
+	_, ok = parseAverageYAVG("no metadata here")
+	assert.False(t, ok)
+}
+
+func TestRegionString(t *testing.T) {
+	assert.Equal(t, "480x270+1440+810", Region{X: 1440, Y: 810, Width: 480, Height: 270}.String())
+}
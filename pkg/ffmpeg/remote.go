@@ -0,0 +1,61 @@
+package ffmpeg
+
+import (
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// RemoteSampleSeconds is how much of a remote input is read for content
+// analysis, so analyzing a URL does not download the whole file
+const RemoteSampleSeconds = 120
+
+// IsRemote reports whether path is an HTTP or HTTPS URL, which FFmpeg reads
+// directly instead of a local file
+func IsRemote(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// RemoteInputArgs returns the input options that let FFmpeg recover from
+// dropped connections while reading path, or nil for local files
+func RemoteInputArgs(path string) []string {
+	if !IsRemote(path) {
+		return nil
+	}
+	return []string{"-reconnect", "1", "-reconnect_streamed", "1", "-reconnect_delay_max", "5"}
+}
+
+// RemoteBaseName returns the file name at the end of a URL's path, without
+// the query string, e.g. https://host/media/talk.mp4?sig=x -> talk.mp4
+func RemoteBaseName(rawURL string) string {
+	name := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		name = path.Base(parsed.Path)
+	}
+	if name == "" || name == "/" || name == "." {
+		return "video"
+	}
+	return name
+}
+
+// pathExtension returns the extension of a local path or URL without the
+// dot, or "" when it has none
+func pathExtension(filePath string) string {
+	if IsRemote(filePath) {
+		filePath = RemoteBaseName(filePath)
+	}
+	return strings.TrimPrefix(filepath.Ext(filePath), ".")
+}
+
+// analysisInputArgs limits analysis of remote inputs to the first
+// RemoteSampleSeconds, so only that part is downloaded
+func analysisInputArgs(filePath string) []string {
+	args := RemoteInputArgs(filePath)
+	if args != nil {
+		args = append(args, "-t", strconv.Itoa(RemoteSampleSeconds))
+	}
+	return args
+}
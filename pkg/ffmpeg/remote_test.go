@@ -0,0 +1,36 @@
+package ffmpeg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRemote(t *testing.T) {
+	assert.True(t, IsRemote("https://cdn.example.com/talk.mp4"))
+	assert.True(t, IsRemote("HTTP://example.com/a.mkv"))
+	assert.False(t, IsRemote("/videos/talk.mp4"))
+	assert.False(t, IsRemote("C:\\videos\\talk.mp4"))
+	assert.False(t, IsRemote("s3://bucket/talk.mp4"))
+}
+
+func TestRemoteBaseName(t *testing.T) {
+	assert.Equal(t, "talk.mp4", RemoteBaseName("https://cdn.example.com/media/talk.mp4?sig=abc&exp=1"))
+	assert.Equal(t, "my talk.mp4", RemoteBaseName("https://example.com/my%20talk.mp4"))
+	assert.Equal(t, "video", RemoteBaseName("https://example.com/"))
+	assert.Equal(t, "mp4", pathExtension("https://example.com/a.mp4?x=y.mkv"))
+	assert.Equal(t, "", pathExtension("https://example.com/stream"))
+	assert.Equal(t, "mov", pathExtension("/videos/clip.mov"))
+}
+
+func TestRemoteInputArgs(t *testing.T) {
+	assert.Nil(t, RemoteInputArgs("/videos/talk.mp4"))
+	assert.Contains(t, RemoteInputArgs("https://example.com/a.mp4"), "-reconnect")
+
+	f := NewFFmpeg("", "", DefaultOptions(), nil)
+	assert.Equal(t, []string{"-i", "/videos/talk.mp4"}, f.inputArgs("/videos/talk.mp4"))
+
+	// Analysis of a URL only reads the first RemoteSampleSeconds
+	args := f.inputArgs("https://example.com/a.mp4")
+	assert.Equal(t, []string{"-t", "120", "-i", "https://example.com/a.mp4"}, args[len(args)-4:])
+}
@@ -0,0 +1,228 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Runner finds and runs the FFmpeg and FFprobe binaries. The analyzer, the
+// compressor and the reporter run every command through the Runner of their
+// FFmpeg instance, so tests can replace it with a fake and callers can point
+// the tool at custom binaries.
+type Runner interface {
+	// EncodePath returns the path of the ffmpeg binary
+	EncodePath() (string, error)
+	// ProbePath returns the path of the ffprobe binary, or an empty path
+	// when only ffmpeg is installed
+	ProbePath() (string, error)
+	// Run runs the binary at path with args until it exits or ctx is done.
+	// Output is written to stdout and stderr as the binary produces it, so
+	// FFmpeg's progress lines can be followed; either writer may be nil.
+	Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error
+}
+
+// waitDelay bounds the wait for the output of a killed process
+const waitDelay = 5 * time.Second
+
+// DefaultRunner runs the FFmpeg installation found by util.FindFFmpeg
+var DefaultRunner Runner = &ExecRunner{}
+
+// lowPriority is set by SetLowPriority
+var lowPriority atomic.Bool
+
+// SetLowPriority makes ExecRunner start every process at a lower CPU and
+// I/O priority. A priority set on the tool itself does not reliably reach
+// its children, as Linux keeps priorities per thread.
+func SetLowPriority(low bool) {
+	lowPriority.Store(low)
+}
+
+// ExecRunner runs FFmpeg and FFprobe as child processes
+type ExecRunner struct {
+	FFmpegPath  string // ffmpeg binary, empty to use the one util.FindFFmpeg finds
+	FFprobePath string // ffprobe binary, empty for ffmpeg-only analysis when FFmpegPath is set
+
+	mu   sync.Mutex
+	info *util.FFmpegInfo // Installation found on first use
+}
+
+// NewRunner returns a Runner for custom ffmpeg and ffprobe binaries
+func NewRunner(ffmpegPath, ffprobePath string) *ExecRunner {
+	return &ExecRunner{FFmpegPath: ffmpegPath, FFprobePath: ffprobePath}
+}
+
+// Info returns the installation the runner uses. Installations found by
+// util.FindFFmpeg are looked up once; a missing FFmpeg is looked up again on
+// the next call, so an install or repair during the run is picked up.
+func (r *ExecRunner) Info() (*util.FFmpegInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.info != nil {
+		return r.info, nil
+	}
+	if r.FFmpegPath != "" {
+		r.info = &util.FFmpegInfo{
+			Available:    true,
+			Path:         r.FFmpegPath,
+			FFprobePath:  r.FFprobePath,
+			ProbeMissing: r.FFprobePath == "",
+		}
+		return r.info, nil
+	}
+
+	info, err := util.FindFFmpeg()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Available {
+		return nil, fmt.Errorf("FFmpeg is not available")
+	}
+	r.info = info
+	return info, nil
+}
+
+// EncodePath returns the path of the ffmpeg binary
+func (r *ExecRunner) EncodePath() (string, error) {
+	info, err := r.Info()
+	if err != nil {
+		return "", err
+	}
+	return info.Path, nil
+}
+
+// ProbePath returns the path of the ffprobe binary, empty when it is missing
+func (r *ExecRunner) ProbePath() (string, error) {
+	info, err := r.Info()
+	if err != nil {
+		return "", err
+	}
+	if info.ProbeMissing {
+		return "", nil
+	}
+	return info.FFprobePath, nil
+}
+
+// Run runs the binary at path as a child process. While encodes are paused
+// the process waits for Resume before it starts.
+func (r *ExecRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	if err := waitWhilePaused(ctx); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	// A killed wrapper script can leave children holding its output open
+	cmd.WaitDelay = waitDelay
+
+	// Keep the end of the standard error for the command log
+	var tail *tailWriter
+	if commandLogging() {
+		tail = &tailWriter{max: maxLoggedStderr}
+		if stderr == nil {
+			cmd.Stderr = tail
+		} else {
+			cmd.Stderr = io.MultiWriter(stderr, tail)
+		}
+		// Combined output must stay a single writer
+		if sameWriter(stdout, stderr) {
+			cmd.Stdout = cmd.Stderr
+		}
+	}
+
+	start := time.Now()
+	var err error
+	if lowPriority.Load() {
+		err = util.StartLowPriority(cmd)
+	} else {
+		err = cmd.Start()
+	}
+	if err == nil {
+		release := trackProcess(cmd.Process.Pid)
+		err = cmd.Wait()
+		release()
+		addCPUTime(ctx, cmd.ProcessState)
+	}
+	if tail != nil {
+		logCommand(path, args, start, time.Since(start), err, tail.String())
+	}
+	return err
+}
+
+// contextRunner stops the commands of a Runner when its context is done
+type contextRunner struct {
+	Runner
+	ctx context.Context
+}
+
+// WithContext returns a Runner that runs commands through r and also stops
+// them when ctx is done, so a deadline covers every FFmpeg process of a job
+// whichever part of the tool starts it. A stopped command fails with an
+// error that wraps the cause of ctx.
+func WithContext(r Runner, ctx context.Context) Runner {
+	if ctx.Done() == nil {
+		return r
+	}
+	return &contextRunner{Runner: r, ctx: ctx}
+}
+
+// Run runs the binary at path until it exits, ctx is done or the runner's
+// context is done
+func (r *contextRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	if r.ctx.Err() != nil {
+		return context.Cause(r.ctx)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(r.ctx, cancel)
+	defer stop()
+
+	err := r.Runner.Run(ctx, path, args, stdout, stderr)
+	if err != nil && r.ctx.Err() != nil {
+		return fmt.Errorf("%w (%v)", context.Cause(r.ctx), err)
+	}
+	return err
+}
+
+// Output runs the binary at path through r and returns its standard output.
+// A failure is classified from the standard error by ClassifyError.
+func Output(r Runner, path string, args []string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	err := r.Run(context.Background(), path, args, &stdout, &stderr)
+	return stdout.Bytes(), ClassifyError(err, stderr.String())
+}
+
+// CombinedOutput runs the binary at path through r and returns its standard
+// output and standard error together. A failure is classified from that
+// output by ClassifyError.
+func CombinedOutput(r Runner, path string, args []string) ([]byte, error) {
+	var output bytes.Buffer
+	err := r.Run(context.Background(), path, args, &output, &output)
+	return output.Bytes(), ClassifyError(err, output.String())
+}
+
+// OutputFunc is an io.Writer that hands each chunk of output to a function
+// as soon as the binary writes it
+type OutputFunc func(chunk string)
+
+// Write passes p to the function
+func (fn OutputFunc) Write(p []byte) (int, error) {
+	fn(string(p))
+	return len(p), nil
+}
+
+// runner returns the Runner of f, falling back to DefaultRunner
+func (f *FFmpeg) runner() Runner {
+	if f == nil || f.Runner == nil {
+		return DefaultRunner
+	}
+	return f.Runner
+}
@@ -0,0 +1,173 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRunner answers every command with canned output instead of running FFmpeg
+type fakeRunner struct {
+	probePath string
+	output    map[string]string // Output per binary path
+	calls     [][]string
+}
+
+func (r *fakeRunner) EncodePath() (string, error) { return "/opt/ffmpeg/ffmpeg", nil }
+func (r *fakeRunner) ProbePath() (string, error)  { return r.probePath, nil }
+
+func (r *fakeRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	r.calls = append(r.calls, append([]string{path}, args...))
+	output, ok := r.output[path]
+	if !ok {
+		return fmt.Errorf("unexpected command %s", path)
+	}
+	if path == r.probePath {
+		io.WriteString(stdout, output)
+		return nil
+	}
+	// ffmpeg prints stream information on stderr and fails without an output
+	io.WriteString(stderr, output)
+	return fmt.Errorf("exit status 1")
+}
+
+// TestGetVideoInfoRunner tests that probing goes through the injected runner
+func TestGetVideoInfoRunner(t *testing.T) {
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+	runner := &fakeRunner{
+		probePath: "/opt/ffmpeg/ffprobe",
+		output: map[string]string{
+			"/opt/ffmpeg/ffprobe": `{"format":{"duration":"90.5","format_name":"mov,mp4"},"streams":[{"codec_type":"video","codec_name":"h264","width":1280,"height":720},{"codec_type":"audio","codec_name":"aac","channels":2,"tags":{"language":"por"},"disposition":{"default":1}},{"codec_type":"subtitle","codec_name":"hdmv_pgs_subtitle","tags":{"language":"eng"}},{"codec_type":"subtitle","codec_name":"subrip","tags":{"language":"por"},"disposition":{"default":1,"forced":0}}]}`,
+			"/opt/ffmpeg/ffmpeg": `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'sample.mp4':
+  Duration: 00:01:30.53, start: 0.000000, bitrate: 1205 kb/s
+  Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(tv, bt709, progressive), 1280x720 [SAR 1:1 DAR 16:9], 1072 kb/s, 29.97 fps, 29.97 tbr, 30k tbn (default)
+At least one output file must be specified`,
+		},
+	}
+	f := NewFFmpeg("", "", nil, logger)
+	f.Runner = runner
+
+	videoFile, err := f.GetVideoInfo("-sample.mp4")
+	assert.NoError(t, err)
+	assert.InDelta(t, 90.5, videoFile.Duration, 0.001)
+	assert.Equal(t, 1280, videoFile.VideoInfo.Width)
+	assert.True(t, videoFile.AudioInfo[0].Default)
+	assert.Equal(t, []SubtitleStreamInfo{
+		{Codec: "hdmv_pgs_subtitle", Language: "eng"},
+		{Codec: "subrip", Language: "por", Default: true},
+	}, videoFile.SubtitleInfo)
+	assert.False(t, videoFile.SubtitleInfo[0].IsText())
+	assert.True(t, videoFile.SubtitleInfo[1].IsText())
+	assert.Equal(t, "/opt/ffmpeg/ffprobe", runner.calls[0][0])
+	assert.Equal(t, "file:-sample.mp4", runner.calls[0][len(runner.calls[0])-1])
+
+	// Without ffprobe the stream information comes from "ffmpeg -i"
+	runner.probePath = ""
+	videoFile, err = f.GetVideoInfo("sample.mp4")
+	assert.NoError(t, err)
+	assert.InDelta(t, 90.53, videoFile.Duration, 0.001)
+	assert.Equal(t, []string{"/opt/ffmpeg/ffmpeg", "-hide_banner", "-i", "sample.mp4"}, runner.calls[1])
+}
+
+// TestNewRunner tests a runner for custom binaries
+func TestNewRunner(t *testing.T) {
+	runner := NewRunner("/opt/ffmpeg/ffmpeg", "")
+	path, err := runner.EncodePath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/ffmpeg/ffmpeg", path)
+	path, err = runner.ProbePath()
+	assert.NoError(t, err)
+	assert.Empty(t, path)
+
+	runner = NewRunner("/opt/ffmpeg/ffmpeg", "/opt/ffmpeg/ffprobe")
+	path, err = runner.ProbePath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/ffmpeg/ffprobe", path)
+
+	// A nil FFmpeg or one without a runner uses the default
+	assert.Equal(t, DefaultRunner, (*FFmpeg)(nil).runner())
+	assert.Equal(t, DefaultRunner, (&FFmpeg{}).runner())
+}
+
+// blockingRunner stands in for an FFmpeg process that hangs until it is killed
+type blockingRunner struct{ fakeRunner }
+
+func (r *blockingRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	<-ctx.Done()
+	return fmt.Errorf("signal: killed")
+}
+
+// TestWithContext tests that a runner context stops hung processes
+func TestWithContext(t *testing.T) {
+	base := &blockingRunner{}
+	assert.Equal(t, Runner(base), WithContext(base, context.Background()))
+
+	errTimeout := errors.New("per-file timeout reached")
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, errTimeout)
+	defer cancel()
+	runner := WithContext(base, ctx)
+
+	_, err := CombinedOutput(runner, "/opt/ffmpeg/ffmpeg", []string{"-i", "hung.mp4"})
+	assert.True(t, errors.Is(err, errTimeout))
+	assert.Contains(t, err.Error(), "signal: killed")
+
+	// Commands started after the deadline do not run at all
+	err = runner.Run(context.Background(), "/opt/ffmpeg/ffmpeg", nil, nil, nil)
+	assert.Equal(t, errTimeout, err)
+
+	path, err := runner.EncodePath()
+	assert.NoError(t, err)
+	assert.Equal(t, "/opt/ffmpeg/ffmpeg", path)
+}
+
+// niceOf returns the nice value of a /proc/<pid>/stat line
+func niceOf(stat string) string {
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+	return fields[16]
+}
+
+// TestSetLowPriority tests that the processes ExecRunner starts run at the
+// lower priority from their start, and their own children too
+func TestSetLowPriority(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("reads the nice value from /proc")
+	}
+	own, err := os.ReadFile("/proc/self/stat")
+	if err != nil || niceOf(string(own)) != "0" {
+		t.Skip("needs to run at the normal priority")
+	}
+
+	SetLowPriority(true)
+	defer SetLowPriority(false)
+
+	// The shell reads its priority as soon as it runs
+	var stdout strings.Builder
+	runner := &ExecRunner{}
+	err = runner.Run(context.Background(), "/bin/sh", []string{"-c", "cat /proc/$$/stat; cat /proc/self/stat"}, &stdout, nil)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Equal(t, "10", niceOf(lines[0]))
+		assert.Equal(t, "10", niceOf(lines[1]))
+	}
+
+	// The tool itself is left alone
+	own, _ = os.ReadFile("/proc/self/stat")
+	assert.Equal(t, "0", niceOf(string(own)))
+
+	// Nor are the processes started after it is turned off
+	SetLowPriority(false)
+	stdout.Reset()
+	assert.NoError(t, runner.Run(context.Background(), "/bin/cat", []string{"/proc/self/stat"}, &stdout, nil))
+	assert.Equal(t, "0", niceOf(stdout.String()))
+}
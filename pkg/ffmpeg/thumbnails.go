@@ -0,0 +1,148 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// SpriteOptions controls the preview sprite sheet: thumbnails taken at a
+// regular interval and tiled into one image that web players show while
+// seeking
+type SpriteOptions struct {
+	Interval      float64 // Seconds between thumbnails
+	Width         int     // Thumbnail width in pixels
+	Columns       int     // Thumbnails per row
+	MaxThumbnails int     // Longer videos get a longer interval instead of more thumbnails
+}
+
+// DefaultSpriteOptions returns the sprite settings common web players expect
+func DefaultSpriteOptions() SpriteOptions {
+	return SpriteOptions{Interval: 10, Width: 160, Columns: 10, MaxThumbnails: 200}
+}
+
+// SpriteLayout is the grid of a sprite sheet
+type SpriteLayout struct {
+	Interval float64 // Seconds between thumbnails
+	Count    int     // Number of thumbnails
+	Columns  int     // Thumbnails per row
+	Rows     int     // Number of rows
+	Width    int     // Thumbnail width in pixels
+	Height   int     // Thumbnail height in pixels
+}
+
+// posterWidth caps the width of poster frames; 0 would keep the video width
+const posterWidth = 1280
+
+// PlanSprite lays out the sprite sheet of a video of duration seconds and
+// the given size
+func PlanSprite(duration float64, videoWidth, videoHeight int, opts SpriteOptions) SpriteLayout {
+	layout := SpriteLayout{Interval: opts.Interval, Columns: opts.Columns, Width: opts.Width}
+	if layout.Interval <= 0 {
+		layout.Interval = DefaultSpriteOptions().Interval
+	}
+	if opts.MaxThumbnails > 0 && duration/layout.Interval > float64(opts.MaxThumbnails) {
+		layout.Interval = math.Ceil(duration / float64(opts.MaxThumbnails))
+	}
+
+	layout.Count = int(math.Ceil(duration / layout.Interval))
+	if layout.Count < 1 {
+		layout.Count = 1
+	}
+	if layout.Columns > layout.Count {
+		layout.Columns = layout.Count
+	}
+	layout.Rows = (layout.Count + layout.Columns - 1) / layout.Columns
+
+	// The height keeps the aspect ratio, rounded to an even number of pixels
+	layout.Height = layout.Width * 9 / 16
+	if videoWidth > 0 && videoHeight > 0 {
+		layout.Height = int(math.Round(float64(layout.Width)*float64(videoHeight)/float64(videoWidth)/2)) * 2
+	}
+	return layout
+}
+
+// WebVTT returns the index that maps each time range of a video of duration
+// seconds to its tile in image, using media fragment coordinates
+func (l SpriteLayout) WebVTT(image string, duration float64) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n")
+	for i := 0; i < l.Count; i++ {
+		start := float64(i) * l.Interval
+		end := math.Min(start+l.Interval, duration)
+		if end <= start {
+			end = start + l.Interval
+		}
+		x := (i % l.Columns) * l.Width
+		y := (i / l.Columns) * l.Height
+		fmt.Fprintf(&b, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			vttTimestamp(start), vttTimestamp(end), image, x, y, l.Width, l.Height)
+	}
+	return b.String()
+}
+
+// vttTimestamp formats seconds as a WebVTT timestamp (HH:MM:SS.mmm)
+func vttTimestamp(seconds float64) string {
+	millis := int64(math.Round(seconds * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+// ExtractPoster writes a representative frame of the video as a JPEG
+// poster image. The frame is picked by FFmpeg's thumbnail filter a little
+// into the video, away from black openings and fades.
+func (f *FFmpeg) ExtractPoster(videoPath, imagePath string, duration float64) error {
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return err
+	}
+
+	args := []string{"-y"}
+	if start := math.Min(duration*0.1, 60); start > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", start))
+	}
+	args = append(args,
+		"-i", FileArg(videoPath),
+		"-vf", fmt.Sprintf("thumbnail,scale='min(%d,iw)':-2", posterWidth),
+		"-frames:v", "1",
+		"-q:v", "2",
+		FileArg(imagePath),
+	)
+	if _, err := CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return fmt.Errorf("failed to extract poster frame: %w", err)
+	}
+	return nil
+}
+
+// GenerateSprite writes the preview sprite sheet of video to imagePath and
+// its WebVTT index to vttPath. The index refers to the image by imageURL,
+// usually its file name next to the index.
+func (f *FFmpeg) GenerateSprite(video *VideoFile, imagePath, imageURL, vttPath string, opts SpriteOptions) (SpriteLayout, error) {
+	layout := PlanSprite(video.Duration, video.VideoInfo.Width, video.VideoInfo.Height, opts)
+
+	runner := f.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		return layout, err
+	}
+
+	filter := fmt.Sprintf("fps=1/%g,scale=%d:%d,tile=%dx%d",
+		layout.Interval, layout.Width, layout.Height, layout.Columns, layout.Rows)
+	args := []string{
+		"-y",
+		"-i", FileArg(video.Path),
+		"-vf", filter,
+		"-frames:v", "1",
+		"-q:v", "4",
+		FileArg(imagePath),
+	}
+	if _, err := CombinedOutput(runner, ffmpegPath, args); err != nil {
+		return layout, fmt.Errorf("failed to generate preview sprite: %w", err)
+	}
+
+	if err := os.WriteFile(vttPath, []byte(layout.WebVTT(imageURL, video.Duration)), 0644); err != nil {
+		return layout, fmt.Errorf("failed to write sprite index: %w", err)
+	}
+	return layout, nil
+}
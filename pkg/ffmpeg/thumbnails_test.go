@@ -0,0 +1,37 @@
+package ffmpeg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanSprite(t *testing.T) {
+	opts := DefaultSpriteOptions()
+
+	layout := PlanSprite(125, 1920, 1080, opts)
+	assert.Equal(t, SpriteLayout{Interval: 10, Count: 13, Columns: 10, Rows: 2, Width: 160, Height: 90}, layout)
+
+	// Short clips get a single row, portrait videos taller tiles
+	layout = PlanSprite(25, 1080, 1920, opts)
+	assert.Equal(t, SpriteLayout{Interval: 10, Count: 3, Columns: 3, Rows: 1, Width: 160, Height: 284}, layout)
+
+	// Long videos get a longer interval instead of a huge image
+	layout = PlanSprite(3*3600, 1280, 720, opts)
+	assert.Equal(t, 54.0, layout.Interval)
+	assert.Equal(t, 200, layout.Count)
+	assert.Equal(t, 20, layout.Rows)
+}
+
+func TestSpriteWebVTT(t *testing.T) {
+	layout := PlanSprite(25, 1280, 720, SpriteOptions{Interval: 10, Width: 160, Columns: 2})
+	vtt := layout.WebVTT("clip-sprite.jpg", 25)
+
+	assert.True(t, strings.HasPrefix(vtt, "WEBVTT\n"))
+	assert.Contains(t, vtt, "\n00:00:00.000 --> 00:00:10.000\nclip-sprite.jpg#xywh=0,0,160,90\n")
+	assert.Contains(t, vtt, "\n00:00:10.000 --> 00:00:20.000\nclip-sprite.jpg#xywh=160,0,160,90\n")
+	assert.Contains(t, vtt, "\n00:00:20.000 --> 00:00:25.000\nclip-sprite.jpg#xywh=0,90,160,90\n")
+
+	assert.Equal(t, "01:02:03.500", vttTimestamp(3723.5))
+}
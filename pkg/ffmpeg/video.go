@@ -7,28 +7,34 @@ import (
 
 // VideoFile represents a video file with its metadata
 type VideoFile struct {
-	Path      string            // Full path to the video file
-	Size      int64             // Size in bytes
-	Format    string            // Container format (mp4, mkv, etc.)
-	Duration  float64           // Duration in seconds
-	BitRate   int64             // Overall bitrate in bits/s
-	VideoInfo VideoStreamInfo   // Information about the video stream
-	AudioInfo []AudioStreamInfo // Information about audio streams
-	Metadata  map[string]string // Additional metadata
+	Path         string               // Full path to the video file
+	Size         int64                // Size in bytes
+	Format       string               // Container format (mp4, mkv, etc.)
+	Duration     float64              // Duration in seconds
+	BitRate      int64                // Overall bitrate in bits/s
+	VideoInfo    VideoStreamInfo      // Information about the video stream
+	AudioInfo    []AudioStreamInfo    // Information about audio streams
+	SubtitleInfo []SubtitleStreamInfo // Information about subtitle streams
+	Metadata     map[string]string    // Additional metadata
 }
 
 // VideoStreamInfo contains information about a video stream
 type VideoStreamInfo struct {
-	Codec         string  // Video codec (h264, h265, etc.)
-	Width         int     // Width in pixels
-	Height        int     // Height in pixels
-	FPS           float64 // Frames per second
-	BitRate       int64   // Video bitrate in bits/s
-	PixelFormat   string  // Pixel format (yuv420p, etc.)
-	ColorSpace    string  // Color space
-	IsHDR         bool    // Whether the video uses HDR
-	HasBFrames    bool    // Whether the video uses B-frames
-	ProfileLevel  string  // Codec profile level
+	Codec          string  // Video codec (h264, h265, etc.)
+	Width          int     // Width in pixels
+	Height         int     // Height in pixels
+	FPS            float64 // Frames per second
+	BitRate        int64   // Video bitrate in bits/s
+	PixelFormat    string  // Pixel format (yuv420p, etc.)
+	ColorSpace     string  // Color space (matrix coefficients), e.g. bt2020nc
+	ColorPrimaries string  // Color primaries, e.g. bt2020
+	ColorTransfer  string  // Transfer characteristics, smpte2084 for HDR10 and arib-std-b67 for HLG
+	IsHDR          bool    // Whether the video uses HDR
+	DolbyVision    int     // Dolby Vision profile, 0 when the video has none
+	HasBFrames     bool    // Whether the video uses B-frames
+	ProfileLevel   string  // Codec profile level
+	SAR            string  // Sample (pixel) aspect ratio, e.g. 8:9 for NTSC DV; empty when unknown
+	DAR            string  // Display aspect ratio, e.g. 4:3; empty when unknown
 }
 
 // AudioStreamInfo contains information about an audio stream
@@ -39,6 +45,30 @@ type AudioStreamInfo struct {
 	SampleRate    int    // Sample rate in Hz
 	BitRate       int64  // Audio bitrate in bits/s
 	Language      string // Language code
+	Default       bool   // Whether players pick it by default
+}
+
+// SubtitleStreamInfo contains information about a subtitle stream
+type SubtitleStreamInfo struct {
+	Codec    string // Subtitle codec (subrip, ass, hdmv_pgs_subtitle, etc.)
+	Language string // Language code
+	Default  bool   // Whether players show it by default
+	Forced   bool   // Whether it only translates foreign dialogue and signs
+}
+
+// bitmapSubtitleCodecs are the subtitle codecs that hold images rather than
+// text
+var bitmapSubtitleCodecs = map[string]bool{
+	"hdmv_pgs_subtitle": true,
+	"dvd_subtitle":      true,
+	"dvb_subtitle":      true,
+	"xsub":              true,
+}
+
+// IsText reports whether the stream holds text, which the subtitles filter
+// can draw, rather than images
+func (s SubtitleStreamInfo) IsText() bool {
+	return !bitmapSubtitleCodecs[s.Codec]
 }
 
 // GetVideoInfo extracts information about a video file
@@ -0,0 +1,143 @@
+// Package hooks runs user commands at fixed points of a compression, such
+// as uploading or tagging each output, with the job as JSON on stdin
+package hooks
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+	"github.com/cccarv82/compressvideo/pkg/notify"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Point is a moment of a run at which hooks are run
+type Point string
+
+// Hook points, in the order they are reached
+const (
+	AfterAnalysis Point = "after_analysis" // The video was analyzed, before its settings are chosen
+	BeforeEncode  Point = "before_encode"  // The settings are final and the encode is about to start
+	AfterEncode   Point = "after_encode"   // The encode finished or failed
+	AfterBatch    Point = "after_batch"    // Every file of a directory run was processed
+)
+
+// Points lists the hook points
+var Points = []Point{AfterAnalysis, BeforeEncode, AfterEncode, AfterBatch}
+
+// Analysis is the part of the analysis a hook receives
+type Analysis struct {
+	ContentType      string  `json:"content_type"`
+	MotionComplexity string  `json:"motion_complexity"`
+	Duration         float64 `json:"duration"`
+	Width            int     `json:"width"`
+	Height           int     `json:"height"`
+	FPS              float64 `json:"fps"`
+	VideoCodec       string  `json:"video_codec"`
+	BitRate          int64   `json:"bitrate"`
+}
+
+// Job is the JSON document a hook reads on stdin. Fields are filled as the
+// run reaches them: the analysis from after_analysis on, the settings from
+// before_encode and the result at after_encode.
+type Job struct {
+	Hook       Point                 `json:"hook"`
+	Tool       string                `json:"tool"`
+	Version    string                `json:"version"`
+	InputFile  string                `json:"input_file,omitempty"`
+	OutputFile string                `json:"output_file,omitempty"`
+	Analysis   *Analysis             `json:"analysis,omitempty"`
+	Settings   map[string]string     `json:"settings,omitempty"`
+	Result     *notify.ResultPayload `json:"result,omitempty"`
+	Batch      *notify.BatchSummary  `json:"batch,omitempty"`
+}
+
+// Hooks holds the commands run at each point
+type Hooks struct {
+	Commands map[Point][]string
+	Logger   *util.Logger
+}
+
+// New creates hooks that run commands, keyed by point
+func New(commands map[Point][]string, logger *util.Logger) *Hooks {
+	return &Hooks{Commands: commands, Logger: logger}
+}
+
+// ValidPoint reports whether name is a hook point
+func ValidPoint(name string) bool {
+	for _, point := range Points {
+		if string(point) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFlag reads a --hook value, point=command
+func ParseFlag(value string) (Point, string, error) {
+	name, command, ok := strings.Cut(value, "=")
+	name, command = strings.TrimSpace(name), strings.TrimSpace(command)
+	if !ok || command == "" {
+		return "", "", i18n.Errorf("invalid hook %q: expected point=command, e.g. after_encode=./upload.sh", value)
+	}
+	if !ValidPoint(name) {
+		return "", "", i18n.Errorf("invalid hook point %q: expected one of after_analysis, before_encode, after_encode, after_batch", name)
+	}
+	return Point(name), command, nil
+}
+
+// Enabled reports whether any command runs at point
+func (h *Hooks) Enabled(point Point) bool {
+	return h != nil && len(h.Commands[point]) > 0
+}
+
+// Run runs the commands of point in order, each with job as JSON on stdin,
+// and stops at the first that fails. What the commands print is logged.
+func (h *Hooks) Run(point Point, job Job) error {
+	if !h.Enabled(point) {
+		return nil
+	}
+	job.Hook = point
+	job.Tool = util.AppName
+	job.Version = util.Version
+	input, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	for _, command := range h.Commands[point] {
+		h.Logger.Debug("Running %s hook: %s", point, command)
+		cmd := shellCommand(command)
+		cmd.Stdin = bytes.NewReader(input)
+		cmd.Env = append(os.Environ(),
+			"COMPRESSVIDEO_HOOK="+string(point),
+			"COMPRESSVIDEO_INPUT="+job.InputFile,
+			"COMPRESSVIDEO_OUTPUT="+job.OutputFile,
+		)
+		output, err := cmd.CombinedOutput()
+		scanner := bufio.NewScanner(bytes.NewReader(output))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				h.Logger.Info("%s hook: %s", point, line)
+			}
+		}
+		if err != nil {
+			return i18n.Errorf("%s hook %q failed: %v", point, command, err)
+		}
+	}
+	return nil
+}
+
+// shellCommand runs command with the system shell, so hooks can use pipes,
+// redirections and quoting as they would in a terminal
+func shellCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,74 @@
+package hooks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/notify"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFlag(t *testing.T) {
+	point, command, err := ParseFlag("after_encode=./upload.sh --bucket=videos")
+	assert.NoError(t, err)
+	assert.Equal(t, AfterEncode, point)
+	assert.Equal(t, "./upload.sh --bucket=videos", command)
+
+	for _, value := range []string{"after_encode", "after_encode=", "=./upload.sh", "after_upload=./upload.sh"} {
+		_, _, err := ParseFlag(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook commands are written for sh")
+	}
+	dir := t.TempDir()
+	logger := util.NewLogger(false)
+	logger.SetLevel(util.LogLevelError) // Keep the test output clean
+
+	// Each command reads the job on stdin and sees it in the environment
+	jobFile := filepath.Join(dir, "job.json")
+	envFile := filepath.Join(dir, "env.txt")
+	h := New(map[Point][]string{
+		AfterEncode: {
+			"cat > " + jobFile,
+			`echo "$COMPRESSVIDEO_HOOK $COMPRESSVIDEO_OUTPUT" > ` + envFile,
+		},
+	}, logger)
+	assert.True(t, h.Enabled(AfterEncode))
+	assert.False(t, h.Enabled(BeforeEncode))
+
+	result := &notify.ResultPayload{InputFile: "in.mp4", OutputFile: "out.mp4", CompressedSize: 1024}
+	assert.NoError(t, h.Run(AfterEncode, Job{InputFile: "in.mp4", OutputFile: "out.mp4", Result: result}))
+
+	data, err := os.ReadFile(jobFile)
+	assert.NoError(t, err)
+	var job Job
+	assert.NoError(t, json.Unmarshal(data, &job))
+	assert.Equal(t, AfterEncode, job.Hook)
+	assert.Equal(t, util.AppName, job.Tool)
+	assert.Equal(t, "out.mp4", job.OutputFile)
+	assert.Equal(t, int64(1024), job.Result.CompressedSize)
+
+	data, err = os.ReadFile(envFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "after_encode out.mp4\n", string(data))
+
+	// A failing command stops the ones after it
+	marker := filepath.Join(dir, "ran")
+	h = New(map[Point][]string{BeforeEncode: {"exit 3", "touch " + marker}}, logger)
+	assert.Error(t, h.Run(BeforeEncode, Job{InputFile: "in.mp4"}))
+	_, err = os.Stat(marker)
+	assert.True(t, os.IsNotExist(err))
+
+	// Points without commands do nothing, nor do missing hooks
+	assert.NoError(t, h.Run(AfterBatch, Job{}))
+	var none *Hooks
+	assert.NoError(t, none.Run(AfterBatch, Job{}))
+}
@@ -0,0 +1,788 @@
+package i18n
+
+// ptBR is the Brazilian Portuguese catalog. Keys are the English message IDs
+// without leading or trailing whitespace.
+var ptBR = map[string]string{
+	// FFmpeg setup and probing
+	"FFmpeg found: %s": "FFmpeg encontrado: %s",
+	"FFprobe not found: video analysis will be limited (use 'repair-ffmpeg' to install it)": "FFprobe não encontrado: a análise de vídeo será limitada (use 'repair-ffmpeg' para instalar)",
+	"FFmpeg not found. Downloading automatically...":                                        "FFmpeg não encontrado. Baixando automaticamente...",
+	"failed to download FFmpeg: %v":                                                         "erro ao baixar FFmpeg: %v",
+	"failed to verify downloaded FFmpeg: %v":                                                "erro ao verificar FFmpeg baixado: %v",
+	"FFmpeg downloaded successfully: %s (version %s)":                                       "FFmpeg baixado com sucesso: %s (versão %s)",
+	"Failed to remove FFmpeg directory: %v":                                                 "Falha ao remover diretório do FFmpeg: %v",
+	"Reinstalling FFmpeg...":                                                                "Reinstalando FFmpeg...",
+	"failed to reinstall FFmpeg: %v":                                                        "falha ao reinstalar FFmpeg: %v",
+	"FFmpeg installed, but it has problems: %v":                                             "FFmpeg instalado, mas com problemas: %v",
+	"FFmpeg reinstalled successfully: %s (version %s)":                                      "FFmpeg reinstalado com sucesso: %s (versão %s)",
+	"unsupported operating system: %s":                                                      "sistema operacional não suportado: %s",
+	"failed to create FFmpeg directory: %v":                                                 "falha ao criar diretório para FFmpeg: %v",
+	"failed to create temporary directory: %v":                                              "falha ao criar diretório temporário: %v",
+	"FFmpeg already installed at: %s":                                                       "FFmpeg já instalado em: %s",
+	"Existing FFmpeg has problems: %v":                                                      "FFmpeg existente com problemas: %v",
+	"Trying to download FFmpeg from: %s":                                                    "Tentando baixar FFmpeg de: %s",
+	"Failed to download from %s: %v":                                                        "Falha ao baixar de %s: %v",
+	"Downloaded file is invalid or too small":                                               "Arquivo baixado inválido ou muito pequeno",
+	"invalid downloaded file":                                                               "arquivo baixado inválido",
+	"Download completed successfully":                                                       "Download concluído com sucesso",
+	"failed to download FFmpeg from every source: %v":                                       "falha ao baixar FFmpeg de todas as fontes: %v",
+	"Extracting FFmpeg...":                                                                  "Extraindo FFmpeg...",
+	"failed to extract FFmpeg: %v":                                                          "falha ao extrair FFmpeg: %v",
+	"ffmpeg not found after extraction":                                                     "ffmpeg não encontrado após extração",
+	"FFprobe not found, trying to download it separately...":                                "FFprobe não encontrado, tentando baixar separadamente...",
+	"failed to download FFprobe for macOS: %v":                                              "falha ao baixar FFprobe para macOS: %v",
+	"ffprobe not found after extraction":                                                    "ffprobe não encontrado após extração",
+	"Extracting zip file to: %s":                                                            "Extraindo arquivo zip em: %s",
+	"failed to open zip file: %v":                                                           "falha ao abrir arquivo zip: %v",
+	"Failed to open %s from zip: %v":                                                        "Falha ao abrir %s do zip: %v",
+	"Failed to create %s: %v":                                                               "Falha ao criar %s: %v",
+	"Failed to copy %s: %v":                                                                 "Falha ao copiar %s: %v",
+	"FFmpeg extracted to: %s":                                                               "FFmpeg extraído para: %s",
+	"FFprobe extracted to: %s":                                                              "FFprobe extraído para: %s",
+	"Looking for executables in: %s":                                                        "Procurando executáveis em: %s",
+	"ffmpeg.exe not found in zip file":                                                      "ffmpeg.exe não encontrado no arquivo zip",
+	"ffprobe.exe not found in zip file":                                                     "ffprobe.exe não encontrado no arquivo zip",
+	"Extracting archive to: %s":                                                             "Extraindo arquivo para: %s",
+	"unsupported archive format: %s":                                                        "formato de arquivo não suportado: %s",
+	"failed to extract with tar: %v (output: %s)":                                           "falha ao extrair com tar: %v (output: %s)",
+	"FFmpeg copied to: %s":                                                                  "FFmpeg copiado para: %s",
+	"FFprobe copied to: %s":                                                                 "FFprobe copiado para: %s",
+	"failed to search for executables: %v":                                                  "erro ao procurar executáveis: %v",
+	"Extracting FFmpeg for macOS to: %s":                                                    "Extraindo FFmpeg para macOS em: %s",
+	"Failed to open file from zip: %v":                                                      "Falha ao abrir arquivo do zip: %v",
+	"Failed to create file: %v":                                                             "Falha ao criar arquivo: %v",
+	"Failed to copy file: %v":                                                               "Falha ao copiar arquivo: %v",
+	"Downloading FFprobe for macOS...":                                                      "Baixando FFprobe para macOS...",
+	"Trying to download FFprobe from: %s":                                                   "Tentando baixar FFprobe de: %s",
+	"Failed to open zip file: %v":                                                           "Falha ao abrir arquivo zip: %v",
+	"failed to download FFprobe: %v":                                                        "falha ao baixar FFprobe: %v",
+	"failed to create directory: %v":                                                        "falha ao criar diretório: %v",
+	"failed to create request: %v":                                                          "erro ao criar request: %v",
+	"download failed: %v":                                                                   "erro ao fazer download: %v",
+	"invalid status code: %d":                                                               "status code inválido: %d",
+	"failed to create file: %v":                                                             "erro ao criar arquivo: %v",
+	"failed to save file: %v":                                                               "erro ao salvar arquivo: %v",
+	"FFmpeg executable not found: %s":                                                       "executável do FFmpeg não encontrado: %s",
+	"FFprobe executable not found: %s":                                                      "executável do FFprobe não encontrado: %s",
+	"failed to run FFmpeg: %v (output: %s)":                                                 "erro ao executar FFmpeg: %v (output: %s)",
+	"failed to run FFprobe: %v (output: %s)":                                                "erro ao executar FFprobe: %v (output: %s)",
+	"failed to find FFmpeg: %v":                                                             "erro ao encontrar FFmpeg: %v",
+	"Failed to verify FFmpeg: %v":                                                           "Falha ao verificar FFmpeg: %v",
+	"Try running 'compressvideo repair-ffmpeg' to fix FFmpeg problems":                      "Tente executar 'compressvideo repair-ffmpeg' para corrigir problemas com o FFmpeg",
+	"failed to initialize FFmpeg: %v":                                                       "falha ao inicializar FFmpeg: %v",
+	"Failed to run FFprobe. This may indicate a problem with the FFmpeg installation.": "Erro ao executar FFprobe. Isso pode indicar um problema com a instalação do FFmpeg.",
+	"failed to get video info: %v":                "falha ao obter informações do vídeo: %v",
+	"Video information:":                          "Informações do vídeo:",
+	"Resolution: %dx%d":                           "Resolução: %dx%d",
+	"Duration: %.2f seconds":                      "Duração: %.2f segundos",
+	"Compression settings:":                       "Configurações de compressão:",
+	"Scale: %dx%d":                                "Escala: %dx%d",
+	"Bitrate: Automatic (controlled by CRF)":      "Bitrate: Automático (controlado pelo CRF)",
+	"Starting video compression...":               "Iniciando compressão do vídeo...",
+	"Running FFmpeg command: %s %s":               "Executando comando FFmpeg: %s %s",
+	"Compressing video":                           "Comprimindo vídeo",
+	"Using default NVENC bitrate: %s":             "Usando bitrate padrão para NVENC: %s",
+	"Using simplified NVENC settings for Windows": "Usando configuração NVENC simplificada para Windows",
+	"failed to analyze video: %v":                 "falha ao analisar vídeo: %v",
+
+	"Downloading FFmpeg": "Baixando FFmpeg",
+	"Pinned FFmpeg build is not installed, downloading %s...":          "O build fixado do FFmpeg não está instalado, baixando %s...",
+	"Integrity check failed for %s: %v":                                "Falha na verificação de integridade de %s: %v",
+	"SHA-256 verified: %s":                                             "SHA-256 verificado: %s",
+	"No published checksum for %s; download not verified (SHA-256 %s)": "Nenhum checksum publicado para %s; download não verificado (SHA-256 %s)",
+	"Failed to record FFmpeg build: %v":                                "Falha ao registrar o build do FFmpeg: %v",
+	"failed to get published checksum: %v":                             "falha ao obter o checksum publicado: %v",
+	"checksum mismatch: expected %s, got %s":                           "checksum não confere: esperado %s, obtido %s",
+	"no checksum for %s in list":                                       "nenhum checksum para %s na lista",
+
+	// Console report
+	"COMPRESSION OPERATION REPORT":                       "RELATÓRIO DA OPERAÇÃO DE COMPRESSÃO",
+	"Bitrate: %.2f Mbps":                                 "Bitrate: %.2f Mbps",
+	"Codec: %s":                                          "Codec: %s",
+	"Compressed Size:  %.2f MB":                          "Tamanho Comprimido: %.2f MB",
+	"Compression Ratio: %.2f:1":                          "Taxa de Compressão: %.2f:1",
+	"Content:    %s, %s motion":                          "Conteúdo:   %s, movimento %s",
+	"Duration:   %.2f seconds":                           "Duração:    %.2f segundos",
+	"Est. Transfer Time Saved: %.1f seconds at 10 Mbps":  "Tempo de Transferência Economizado (est.): %.1f segundos a 10 Mbps",
+	"Est. Transfer Time Saved: %d min %d sec at 10 Mbps": "Tempo de Transferência Economizado (est.): %d min %d s a 10 Mbps",
+	"Input:  %s":                                         "Entrada: %s",
+	"Original Size:    %.2f MB":                          "Tamanho Original:   %.2f MB",
+	"Output: %s":                                         "Saída:   %s",
+	"Overall Score:    %.1f/100":                         "Pontuação Geral:    %.1f/100",
+	"CPU Time:         %s (about %.2f Wh)":               "Tempo de CPU:       %s (cerca de %.2f Wh)",
+	"Predicted VMAF:   %.2f":                             "VMAF Previsto:      %.2f",
+	"Preset: %s":                                         "Preset: %s",
+	"Processing Time:  %s":                               "Tempo de Processamento: %s",
+	"Quality (CRF): %s":                                  "Qualidade (CRF): %s",
+	"Quality Estimate: %s (%.1f/100)":                    "Qualidade Estimada: %s (%.1f/100)",
+	"Space Saved:      %.2f MB (%.1f%%)":                 "Espaço Economizado: %.2f MB (%.1f%%)",
+	"Stream #%d:":                                        "Stream #%d:",
+	"Video Codec: %s":                                    "Codec de Vídeo: %s",
+	"⏱️ PERFORMANCE:":                                    "⏱️ DESEMPENHO:",
+	"⚙️ ENCODING SETTINGS:":                              "⚙️ CONFIGURAÇÕES DE CODIFICAÇÃO:",
+	"🎬 VIDEO DETAILS:":                                   "🎬 DETALHES DO VÍDEO:",
+	"💡 OPTIMIZATION TIPS:":                               "💡 DICAS DE OTIMIZAÇÃO:",
+	"📊 COMPRESSION RESULTS:":                             "📊 RESULTADOS DA COMPRESSÃO:",
+	"📁 FILES:":                                           "📁 ARQUIVOS:",
+	"📈 SOURCE BITRATE (%d s):":                           "📈 BITRATE DA ORIGEM (%d s):",
+	"Mean: %s, median: %s":                               "Média: %s, mediana: %s",
+	"Percentiles: 90th %s, 95th %s, 99th %s":             "Percentis: 90º %s, 95º %s, 99º %s",
+	"Range: %s to %s":                                    "Faixa: %s a %s",
+
+	// Cache
+	"All cache entries cleared successfully":             "Todas as entradas do cache foram removidas",
+	"Analysis time saved by using cache!":                "Tempo de análise economizado usando o cache!",
+	"Cache Statistics":                                   "Estatísticas do Cache",
+	"Cache Usage Tips":                                   "Dicas de Uso do Cache",
+	"Cache directory: %s":                                "Diretório do cache: %s",
+	"Cache status: %d total entries, %d valid entries":   "Status do cache: %d entradas no total, %d válidas",
+	"Checking analysis cache...":                         "Verificando o cache de análise...",
+	"Cleaned %d expired cache entries":                   "%d entradas expiradas removidas do cache",
+	"Cleaning Expired Entries":                           "Limpando Entradas Expiradas",
+	"Clearing Cache":                                     "Limpando o Cache",
+	"Clearing all cache entries...":                      "Removendo todas as entradas do cache...",
+	"Clearing entries older than %d days...":             "Removendo entradas com mais de %d dias...",
+	"Continuing without cache...":                        "Continuando sem cache...",
+	"Error reading from cache: %v":                       "Erro ao ler do cache: %v",
+	"cache fingerprint must be path or content (got %s)": "a impressão digital do cache deve ser path ou content (recebido %s)",
+	"Specify --max-size and/or --max-entries":            "Informe --max-size e/ou --max-entries",
+	"Failed to prune cache: %v":                          "Falha ao reduzir o cache: %v",
+	"Failed to evict old cache entries: %v":              "Falha ao remover entradas antigas do cache: %v",
+	"Evicted %d cache entries":                           "%d entradas removidas do cache",
+	"Cache now holds %d entries (%s)":                    "O cache agora tem %d entradas (%s)",
+	"• Limit the cache size with '--cache-max-size' or 'cache prune --max-size'":           "• Limite o tamanho do cache com '--cache-max-size' ou 'cache prune --max-size'",
+	"--fast-analysis requires --use-cache":                                                 "--fast-analysis requer --use-cache",
+	"Fast analysis: reusing the analysis of similar video %s":                              "Análise rápida: reutilizando a análise do vídeo semelhante %s",
+	"No similar video in the cache, analyzing video...":                                    "Nenhum vídeo semelhante no cache, analisando o vídeo...",
+	"hwaccel must be one of: cuda, qsv, vaapi (got %s)":                                    "hwaccel deve ser um de: cuda, qsv, vaapi (recebido %s)",
+	"Target VMAF is not supported with %s, using constant quality %s":                      "VMAF alvo não é suportado com %s, usando qualidade constante %s",
+	"hw-encoder must be one of: auto, nvenc, qsv, amf (got %s)":                            "hw-encoder deve ser um de: auto, nvenc, qsv, amf (recebido %s)",
+	"Could not list FFmpeg encoders, keeping %s: %v":                                       "Não foi possível listar os codificadores do FFmpeg, mantendo %s: %v",
+	"No %s hardware encoder for %s in this FFmpeg build, keeping %s":                       "Nenhum codificador de hardware %s para %s nesta versão do FFmpeg, mantendo %s",
+	"Using hardware encoder %s instead of %s":                                              "Usando o codificador de hardware %s em vez de %s",
+	"threads must be 0 or more (got %d)":                                                   "threads deve ser 0 ou mais (recebido %d)",
+	"Failed to lower process priority: %v":                                                 "Falha ao reduzir a prioridade do processo: %v",
+	"Screencast region analysis failed: %v":                                                "A análise de regiões do screencast falhou: %v",
+	"Webcam overlay detected at %s":                                                        "Sobreposição de webcam detectada em %s",
+	"No webcam overlay detected, treating the whole screen as static":                      "Nenhuma sobreposição de webcam detectada, tratando a tela inteira como estática",
+	"--copy-video and --copy-audio together leave nothing to transform":                    "--copy-video e --copy-audio juntos não deixam nada para transformar",
+	"--copy-audio and --no-audio cannot be used together":                                  "--copy-audio e --no-audio não podem ser usados juntos",
+	"--copy-video on a file without audio leaves nothing to transform":                     "--copy-video em um arquivo sem áudio não deixa nada para transformar",
+	"Target VMAF is ignored when the video stream is copied":                               "O VMAF alvo é ignorado quando o stream de vídeo é copiado",
+	"audio channels must be one of: stereo, mono, keep (got %s)":                           "canais de áudio devem ser um de: stereo, mono, keep (recebido %s)",
+	"--audio-channels requires re-encoding the audio and cannot be used with --copy-audio": "--audio-channels exige recodificar o áudio e não pode ser usado com --copy-audio",
+	"Failed to preserve file times: %v":                                                    "Falha ao preservar as datas do arquivo: %v",
+	"--flatten requires --recursive":                                                       "--flatten requer --recursive",
+	"skip-below-bitrate must be a bitrate such as 2M or 800k (got %s)":                     "skip-below-bitrate deve ser uma taxa de bits como 2M ou 800k (recebido %s)",
+	"invalid bitrate: %s":                                                                  "taxa de bits inválida: %s",
+	"already encoded with %s":                                                              "já codificado com %s",
+	"bitrate %.2f Mbps is below %.2f Mbps":                                                 "taxa de bits de %.2f Mbps está abaixo de %.2f Mbps",
+	"Could not probe %s, compressing it anyway: %v":                                        "Não foi possível inspecionar %s, comprimindo mesmo assim: %v",
+	"Skipping %s: %s":                                     "Ignorando %s: %s",
+	"Skipped %d files by codec or bitrate":                "%d arquivos ignorados por codec ou taxa de bits",
+	"invalid size: %s":                                    "tamanho inválido: %s",
+	"invalid age: %s":                                     "idade inválida: %s",
+	"min-size must be a size such as 500M or 2G (got %s)": "min-size deve ser um tamanho como 500M ou 2G (recebido %s)",
+	"max-size must be a size such as 500M or 2G (got %s)": "max-size deve ser um tamanho como 500M ou 2G (recebido %s)",
+	"min-size cannot be larger than max-size":             "min-size não pode ser maior que max-size",
+	"older-than must be an age such as 30d, 2w or 12h, or a YYYY-MM-DD date (got %s)": "older-than deve ser uma idade como 30d, 2w ou 12h, ou uma data AAAA-MM-DD (recebido %s)",
+	"newer-than must be an age such as 30d, 2w or 12h, or a YYYY-MM-DD date (got %s)": "newer-than deve ser uma idade como 30d, 2w ou 12h, ou uma data AAAA-MM-DD (recebido %s)",
+	"failed to read manifest: %w":                                  "falha ao ler o manifesto: %w",
+	"failed to parse manifest %s: %w":                              "falha ao interpretar o manifesto %s: %w",
+	"manifest %s has no jobs":                                      "o manifesto %s não tem tarefas",
+	"manifest job %d has no input":                                 "a tarefa %d do manifesto não tem entrada",
+	"%s: quality must be between 1-5 (got %d)":                     "%s: a qualidade deve estar entre 1-5 (recebido %d)",
+	"%s: preset must be one of: fast, balanced, thorough (got %s)": "%s: o preset deve ser um de: fast, balanced, thorough (recebido %s)",
+	"%s: target VMAF must be between 0-100 (got %.1f)":             "%s: o VMAF alvo deve estar entre 0-100 (recebido %.1f)",
+	"Job %s: %s":                              "Tarefa %s: %s",
+	"failed to write results manifest: %w":    "falha ao gravar o manifesto de resultados: %w",
+	"Results saved to: %s":                    "Resultados salvos em: %s",
+	"%d of %d jobs failed":                    "%d de %d tarefas falharam",
+	"Not using the cache for remote input %s": "Cache não usado para a entrada remota %s",
+	"not an s3:// URI: %s":                    "não é uma URI s3://: %s",
+	"S3 URI must name a bucket and an object key (s3://bucket/key): %s": "a URI S3 deve indicar um bucket e uma chave de objeto (s3://bucket/chave): %s",
+	"failed to load AWS configuration: %w":                              "falha ao carregar a configuração da AWS: %w",
+	"failed to download %s: %w":                                         "falha ao baixar %s: %w",
+	"failed to upload %s: %w":                                           "falha ao enviar %s: %w",
+	"failed to create staging directory: %w":                            "falha ao criar o diretório temporário: %w",
+	"failed to check %s: %w":                                            "falha ao verificar %s: %w",
+	"Downloading %s...":                                                 "Baixando %s...",
+	"Downloaded %s (%s)":                                                "%s baixado (%s)",
+	"Uploading %s...":                                                   "Enviando %s...",
+	"Uploaded %s":                                                       "%s enviado",
+	"%d of %d files failed":                                             "%d de %d arquivos falharam",
+	"%s: %d%% (%s remaining)":                                           "%s: %d%% (%s restantes)",
+	"Temporary Directory":                                               "Diretório Temporário",
+	"No leftover temporary files found":                                 "Nenhum arquivo temporário restante encontrado",
+	"Would remove %d temporary directories (%s)":                        "Seriam removidos %d diretórios temporários (%s)",
+	"Removed %d temporary directories (%s)":                             "%d diretórios temporários removidos (%s)",
+	"CompressVideo - FFmpeg Repair Tool":                                "CompressVideo - Ferramenta de Reparo do FFmpeg",
+	"Starting FFmpeg repair process...":                                 "Iniciando o reparo do FFmpeg...",
+	"FFmpeg repair completed successfully!":                             "Reparo do FFmpeg concluído com sucesso!",
+	"FFmpeg Version":                                                    "Versão do FFmpeg",
+	"FFmpeg Path":                                                       "Caminho do FFmpeg",
+	"FFprobe Path":                                                      "Caminho do FFprobe",
+	"You can now use CompressVideo normally.":                           "Agora você pode usar o CompressVideo normalmente.",
+	"name template must contain {name}: %s":                             "o modelo de nome deve conter {name}: %s",
+	"name template must not contain directories: %s":                    "o modelo de nome não deve conter diretórios: %s",
+	"unknown placeholder %s in name template (use {name}, {ext}, {codec}, {quality} or {preset})": "marcador %s desconhecido no modelo de nome (use {name}, {ext}, {codec}, {quality} ou {preset})",
+	"Compression ledger unavailable, recognizing outputs by name: %v":                             "Registro de compressões indisponível, reconhecendo saídas pelo nome: %v",
+	"Could not check the compression ledger: %v":                                                  "Não foi possível consultar o registro de compressões: %v",
+	"Failed to record the compression in the ledger: %v":                                          "Falha ao gravar a compressão no registro: %v",
+	"%s is the output of an earlier run (compressed from %s)":                                     "%s é a saída de uma execução anterior (comprimido a partir de %s)",
+	"it is the output of an earlier run (compressed from %s)":                                     "é a saída de uma execução anterior (comprimido a partir de %s)",
+	"already compressed to %s (use -f to compress it again)":                                      "já comprimido em %s (use -f para comprimir novamente)",
+	"renditions format must be files or hls (got %s)":                                             "o formato das renditions deve ser files ou hls (recebido %s)",
+	"--renditions scales the video and cannot be used with --copy-video":                          "--renditions redimensiona o vídeo e não pode ser usado com --copy-video",
+	"--renditions cannot be used with s3:// outputs":                                              "--renditions não pode ser usado com saídas s3://",
+	"invalid rendition %s (use even heights such as 1080p,720p,480p)":                             "rendition inválida %s (use alturas pares como 1080p,720p,480p)",
+	"no renditions given (use heights such as 1080p,720p,480p)":                                   "nenhuma rendition informada (use alturas como 1080p,720p,480p)",
+	"no renditions to encode":                                                                     "nenhuma rendition para codificar",
+	"Target VMAF is ignored when encoding renditions":                                             "O VMAF alvo é ignorado ao codificar renditions",
+	"Skipping rendition %s: the source is only %dp":                                               "Ignorando a rendition %s: a origem tem apenas %dp",
+	"every rendition is taller than the %dp source":                                               "todas as renditions são maiores que a origem de %dp",
+	"Renditions":                                           "Renditions",
+	"Master Playlist":                                      "Playlist Principal",
+	"Compressing %d renditions":                            "Comprimindo %d renditions",
+	"Encoded %d renditions of %s":                          "%d renditions de %s codificadas",
+	"%s (%.1f%% smaller)":                                  "%s (%.1f%% menor)",
+	"CompressVideo - Animated Image":                       "CompressVideo - Imagem Animada",
+	"an input file is required (compressvideo gif <file>)": "é necessário um arquivo de entrada (compressvideo gif <arquivo>)",
+	"animated format must be gif, webp or avif (got %s)":   "o formato animado deve ser gif, webp ou avif (recebido %s)",
+	"width, fps, start and duration cannot be negative":    "width, fps, start e duration não podem ser negativos",
+	"Animated images of long clips get large; use --start and --duration to convert a part": "Imagens animadas de clipes longos ficam grandes; use --start e --duration para converter uma parte",
+	"Converting to %s":           "Convertendo para %s",
+	"Conversion failed: %v":      "Falha na conversão: %v",
+	"Animated image saved to %s": "Imagem animada salva em %s",
+	"Size Change":                "Variação de Tamanho",
+	"%.1f%% of the source":       "%.1f%% da origem",
+	"sprite interval must be more than 0 seconds (got %g)": "o intervalo do sprite deve ser maior que 0 segundos (recebido %g)",
+	"Failed to write poster frame: %v":                     "Falha ao gravar o quadro de capa: %v",
+	"Poster":                                               "Capa",
+	"Failed to write preview sprite: %v":                   "Falha ao gravar o sprite de pré-visualização: %v",
+	"Preview Sprite":                                       "Sprite de Pré-visualização",
+	"%s (%d thumbnails, one every %gs)":                    "%s (%d miniaturas, uma a cada %gs)",
+	"CompressVideo - Doctor":                               "CompressVideo - Diagnóstico",
+	"Cache directory":                                      "Diretório de cache",
+	"Temporary directory":                                  "Diretório temporário",
+	"Cache disk space":                                     "Espaço em disco do cache",
+	"Temporary disk space":                                 "Espaço em disco temporário",
+	"Working directory disk space":                         "Espaço em disco do diretório de trabalho",
+	"Check":                                                "Verificação",
+	"Status":                                               "Status",
+	"Details":                                              "Detalhes",
+	"FFmpeg was not found; run repair-ffmpeg or install it": "O FFmpeg não foi encontrado; execute repair-ffmpeg ou instale-o",
+	"%d of %d checks failed":                                "%d de %d verificações falharam",
+	"No checks failed (%d warnings)":                        "Nenhuma verificação falhou (%d avisos)",
+	"All checks passed":                                     "Todas as verificações passaram",
+	"not found or not working":                              "não encontrado ou não funciona",
+	"system":                                                "sistema",
+	"downloaded":                                            "baixado",
+	"not found; video information is read from FFmpeg output, with fewer details": "não encontrado; as informações do vídeo são lidas da saída do FFmpeg, com menos detalhes",
+	"Encoders": "Codificadores",
+	"%s compiled in, but no usable GPU or driver: %v":                          "%s compilado, mas sem GPU ou driver utilizável: %v",
+	"%s failed a test encode: %v":                                              "%s falhou em uma codificação de teste: %v",
+	"not in this FFmpeg build":                                                 "ausente neste build do FFmpeg",
+	"not in this FFmpeg build; run repair-ffmpeg for a full build":             "ausente neste build do FFmpeg; execute repair-ffmpeg para um build completo",
+	"not in this FFmpeg build; other codecs are used instead":                  "ausente neste build do FFmpeg; outros codecs são usados no lugar",
+	"%s is not writable: %v":                                                   "%s não permite gravação: %v",
+	"could not be determined: %v":                                              "não foi possível determinar: %v",
+	"%s free":                                                                  "%s livres",
+	"Hardware encoder %s failed (%s), retrying %s with %s":                     "O codificador de hardware %s falhou (%s), repetindo %s com %s",
+	"Hardware encoder %s failed (%s) and no software encoder is available: %v": "O codificador de hardware %s falhou (%s) e nenhum codificador de software está disponível: %v",
+	"driver or device unavailable":                                             "driver ou dispositivo indisponível",
+	"encoder session limit reached":                                            "limite de sessões do codificador atingido",
+	"input or setting not supported by the hardware encoder":                   "entrada ou configuração não suportada pelo codificador de hardware",
+	"hardware encoder error":                                                   "erro do codificador de hardware",
+	"Hardware Fallback: %s failed (%s), re-encoded with %s":                    "Alternativa ao Hardware: %s falhou (%s), recodificado com %s",
+	"input file is truncated or incomplete":                                    "o arquivo de entrada está truncado ou incompleto",
+	"input is damaged or not a readable video":                                 "a entrada está danificada ou não é um vídeo legível",
+	"file not found":                                                           "arquivo não encontrado",
+	"permission denied":                                                        "permissão negada",
+	"no space left on device":                                                  "sem espaço livre no dispositivo",
+	"pixel format not supported by the encoder":                                "formato de pixel não suportado pelo codificador",
+	"encoder not available in this FFmpeg build":                               "codificador não disponível nesta versão do FFmpeg",
+	"network error while reading the input":                                    "erro de rede ao ler a entrada",
+	"the file appears truncated, e.g. a cut-off recording or an interrupted download; try --ignore-errors to salvage the readable part": "o arquivo parece truncado, por exemplo uma gravação cortada ou um download interrompido; tente --ignore-errors para aproveitar a parte legível",
+	"the file may be damaged or not a video; check that it plays in a media player, or try --ignore-errors to skip damaged data":        "o arquivo pode estar danificado ou não ser um vídeo; verifique se ele abre em um player de mídia, ou tente --ignore-errors para pular os dados danificados",
+	"check the path and that the drive or share is still connected":                                                                     "verifique o caminho e se o disco ou compartilhamento ainda está conectado",
+	"check that you can read the input and write to the output directory":                                                               "verifique se você pode ler a entrada e gravar no diretório de saída",
+	"free up disk space or write the output to another drive":                                                                           "libere espaço em disco ou grave a saída em outro disco",
+	"the encoder cannot take the source's pixel format; try a software encoder or another quality level":                                "o codificador não aceita o formato de pixel da origem; tente um codificador por software ou outro nível de qualidade",
+	"run 'compressvideo doctor' to see the available encoders, or 'compressvideo repair-ffmpeg' for a full FFmpeg build":                "execute 'compressvideo doctor' para ver os codificadores disponíveis, ou 'compressvideo repair-ffmpeg' para uma versão completa do FFmpeg",
+	"check the URL and your network connection":                                                                                         "verifique a URL e sua conexão de rede",
+	"Hint: %s": "Dica: %s",
+	"Only part of the damaged input could be recovered: %s":    "Apenas parte da entrada danificada pôde ser recuperada: %s",
+	"  Recovered:        %s of %s (%.1f%%)":                    "  Recuperado:       %s de %s (%.1f%%)",
+	"  Recovered:        %s (source duration unknown)":         "  Recuperado:       %s (duração da origem desconhecida)",
+	"timeout-per-file must be 0 or more (got %s)":              "timeout-per-file deve ser 0 ou mais (recebido %s)",
+	"max-runtime must be 0 or more (got %s)":                   "max-runtime deve ser 0 ou mais (recebido %s)",
+	"Stopped %s: --max-runtime of %s reached":                  "%s interrompido: --max-runtime de %s atingido",
+	"Stopped %s after --timeout-per-file of %s":                "%s interrompido após --timeout-per-file de %s",
+	"--max-runtime of %s reached, %d files were not processed": "--max-runtime de %s atingido, %d arquivos não foram processados",
+	"%d of %d files were not processed before --max-runtime":   "%d de %d arquivos não foram processados antes do --max-runtime",
+	"not started before --max-runtime":                         "não iniciado antes do --max-runtime",
+	"Press p to pause or resume encoding":                      "Pressione p para pausar ou retomar a codificação",
+	"Encoding paused, press p to resume":                       "Codificação pausada, pressione p para retomar",
+	"Encoding paused, send the same signal again to resume":    "Codificação pausada, envie o mesmo sinal novamente para retomar",
+	"Encoding resumed":                                "Codificação retomada",
+	"Could not pause every FFmpeg process: %v":        "Não foi possível pausar todos os processos do FFmpeg: %v",
+	"Could not resume every FFmpeg process: %v":       "Não foi possível retomar todos os processos do FFmpeg: %v",
+	"Keyboard controls unavailable: %v":               "Controles de teclado indisponíveis: %v",
+	"What is in the video?":                           "O que há no vídeo?",
+	"Camera footage (family, events, travel)":         "Gravações de câmera (família, eventos, viagens)",
+	"Screen recording or tutorial":                    "Gravação de tela ou tutorial",
+	"Gameplay or sports with fast motion":             "Jogos ou esportes com movimento rápido",
+	"Animation or cartoons":                           "Animação ou desenhos",
+	"Not sure":                                        "Não tenho certeza",
+	"Where will it be played?":                        "Onde ele será reproduzido?",
+	"Anywhere, including older devices and chat apps": "Em qualquer lugar, incluindo aparelhos antigos e apps de mensagem",
+	"Modern phones, tablets and TVs":                  "Celulares, tablets e TVs recentes",
+	"Web browsers":                                    "Navegadores web",
+	"Nowhere in particular, it is an archive copy":    "Em nenhum lugar específico, é uma cópia de arquivo",
+	"How much quality loss is acceptable?":            "Quanta perda de qualidade é aceitável?",
+	"None that I can see":                             "Nenhuma que eu perceba",
+	"Barely noticeable":                               "Quase imperceptível",
+	"A little, for a much smaller file":               "Um pouco, por um arquivo bem menor",
+	"Noticeable, but fine for sharing":                "Perceptível, mas boa para compartilhar",
+	"Whatever it takes for the smallest file":         "O que for preciso pelo menor arquivo",
+	"How long can the compression take?":              "Quanto tempo a compressão pode levar?",
+	"As short as possible":                            "O menor possível",
+	"A balance of speed and file size":                "Um equilíbrio entre velocidade e tamanho",
+	"As long as needed for the smallest file":         "O quanto for preciso pelo menor arquivo",
+	"Choice":                             "Opção",
+	"Please enter a number from 1 to %d": "Digite um número de 1 a %d",
+	"invalid choice: %s":                 "opção inválida: %s",
+	"Command":                            "Comando",
+	"Screencast Tuning":                  "Ajuste para gravação de tela",
+	"on":                                 "ativado",
+	"Save these settings as a profile? Enter a name, or leave empty to skip:": "Salvar estas configurações como perfil? Digite um nome, ou deixe em branco para pular:",
+	"Saved profile %s to %s":                                              "Perfil %s salvo em %s",
+	"Use it with: compressvideo -i <file> --profile %s":                   "Use com: compressvideo -i <arquivo> --profile %s",
+	"profile %s not found in %s (create one with 'compressvideo wizard')": "perfil %s não encontrado em %s (crie um com 'compressvideo wizard')",
+	"codec must be one of: auto, h264, hevc, vp9 (got %s)":                "codec deve ser um de: auto, h264, hevc, vp9 (recebido %s)",
+	"Using profile %s: %s":                                                "Usando o perfil %s: %s",
+	"Build":                                                               "Build",
+	"Commit":                                                              "Commit",
+	"Build Date":                                                          "Data do build",
+	"Go Version":                                                          "Versão do Go",
+	"Platform":                                                            "Plataforma",
+	"Version":                                                             "Versão",
+	"Path":                                                                "Caminho",
+	"unknown":                                                             "desconhecido",
+	"not found":                                                           "não encontrado",
+	"not found; run repair-ffmpeg or install it":                                        "não encontrado; execute repair-ffmpeg ou instale-o",
+	"CompressVideo crashed: %v":                                                         "O CompressVideo travou: %v",
+	"Could not write a diagnostic bundle: %v":                                           "Não foi possível gravar um pacote de diagnóstico: %v",
+	"A diagnostic bundle was written to %s; attach it when reporting the problem at %s": "Um pacote de diagnóstico foi gravado em %s; anexe-o ao relatar o problema em %s",
+	"download-limit must be a speed such as 2MB/s or 500KB/s (got %s)":                  "download-limit deve ser uma velocidade como 2MB/s ou 500KB/s (recebido %s)",
+	"FFmpeg downloads limited to %s/s":                                                  "Downloads do FFmpeg limitados a %s/s",
+	"Server does not support resuming downloads, starting over":                         "O servidor não permite retomar downloads, recomeçando do início",
+	"Download interrupted after %s, resuming: %v":                                       "Download interrompido após %s, retomando: %v",
+	"invalid Content-Range: %s":                                                         "Content-Range inválido: %s",
+	"System FFmpeg %s at %s is older than %s, downloading a newer build...":             "O FFmpeg %s do sistema em %s é anterior à versão %s, baixando um build mais novo...",
+	"Downloading the FFmpeg build managed by CompressVideo (--prefer-downloaded)...":    "Baixando o build do FFmpeg gerenciado pelo CompressVideo (--prefer-downloaded)...",
+	"Failed to download FFmpeg, using system FFmpeg %s: %v":                             "Falha ao baixar o FFmpeg, usando o FFmpeg %s do sistema: %v",
+	"older than %s, run repair-ffmpeg to install a newer build":                         "anterior à versão %s, execute repair-ffmpeg para instalar um build mais novo",
+	"your FFmpeg %s lacks %s; run repair-ffmpeg or %s":                                  "seu FFmpeg %s não tem %s; execute repair-ffmpeg ou %s",
+	"run without --target-vmaf":                                                         "execute sem --target-vmaf",
+	"leave the audio loudness unchanged":                                                "mantenha o volume do áudio inalterado",
+	"your FFmpeg %s is older than %s, the oldest version CompressVideo supports; run repair-ffmpeg to install a newer build": "seu FFmpeg %s é anterior à versão %s, a mais antiga suportada pelo CompressVideo; execute repair-ffmpeg para instalar um build mais novo",
+	"older than %s, the oldest version CompressVideo supports":                                                               "anterior à versão %s, a mais antiga suportada pelo CompressVideo",
+	"Could not probe FFmpeg capabilities: %v":                                                                                "Não foi possível verificar os recursos do FFmpeg: %v",
+	"VMAF scoring":                                               "Pontuação VMAF",
+	"SVT-AV1 encoding":                                           "Codificação SVT-AV1",
+	"two-pass loudness normalization":                            "Normalização de volume em duas passagens",
+	"Features":                                                   "Recursos",
+	"Could not log FFmpeg commands: %v":                          "Não foi possível registrar os comandos do FFmpeg: %v",
+	"Logging FFmpeg commands to %s":                              "Registrando os comandos do FFmpeg em %s",
+	"watts-per-core must be 0 or more (got %g)":                  "watts-per-core deve ser 0 ou mais (recebido %g)",
+	"FFmpeg used %s of CPU time, about %.2f Wh at %g W per core": "O FFmpeg usou %s de tempo de CPU, cerca de %.2f Wh a %g W por núcleo",
+	"scene threshold must be between 0 and 1 (got %g)":           "o limiar de cena deve estar entre 0 e 1 (recebido %g)",
+	"complexity interval must be 0 or more (got %g)":             "o intervalo de complexidade deve ser 0 ou mais (recebido %g)",
+	"motion cutoffs must rise from low to high (got %s)":         "os limites de movimento devem crescer de baixo para alto (recebido %s)",
+	"invalid motion cutoffs %q: expected three scenes-per-minute:complexity pairs, e.g. 2:200,5:500,10:1000": "limites de movimento inválidos %q: esperados três pares cenas-por-minuto:complexidade, ex. 2:200,5:500,10:1000",
+	"invalid motion cutoff %q: expected scenes-per-minute:complexity":                                        "limite de movimento inválido %q: esperado cenas-por-minuto:complexidade",
+	"invalid scenes per minute %q in motion cutoff %q":                                                       "cenas por minuto inválidas %q no limite de movimento %q",
+	"invalid frame complexity %q in motion cutoff %q":                                                        "complexidade de quadro inválida %q no limite de movimento %q",
+	"Cached analysis of %s used other analysis parameters":                                                   "A análise em cache de %s usou outros parâmetros de análise",
+	"🧠 DECISIONS:": "🧠 DECISÕES:",
+	"Decisions":    "Decisões",
+	"crf must be a whole number of 0 or more (got %s)":                                    "crf deve ser um número inteiro igual ou maior que 0 (recebido %s)",
+	"video bitrate must be a bitrate such as 4M or 2500k (got %s)":                        "a taxa de bits de vídeo deve ser um valor como 4M ou 2500k (recebido %s)",
+	"audio bitrate must be a bitrate such as 128k (got %s)":                               "a taxa de bits de áudio deve ser um valor como 128k (recebido %s)",
+	"%s cannot be used with --copy-video":                                                 "%s não pode ser usado com --copy-video",
+	"--crf is not supported by %s":                                                        "--crf não é suportado por %s",
+	"crf for %s must be between %d-%d (got %s)":                                           "crf para %s deve estar entre %d-%d (recebido %s)",
+	"pixel format %s is not supported by %s (use one of: %s)":                             "o formato de pixel %s não é suportado por %s (use um destes: %s)",
+	"--tune is not supported by %s":                                                       "--tune não é suportado por %s",
+	"tune %s is not supported by %s (use one of: %s)":                                     "o tune %s não é suportado por %s (use um destes: %s)",
+	"--audio-bitrate cannot be used with --no-audio":                                      "--audio-bitrate não pode ser usado com --no-audio",
+	"--crf and --target-vmaf cannot be used together":                                     "--crf e --target-vmaf não podem ser usados juntos",
+	"%s applies to software encoders and cannot be used with --hw-encoder":                "%s se aplica a codificadores de software e não pode ser usado com --hw-encoder",
+	"--video-bitrate cannot be used with --renditions, whose bitrates follow each height": "--video-bitrate não pode ser usado com --renditions, cujas taxas de bits acompanham cada altura",
+	"--audio-bitrate cannot be used with --copy-audio":                                    "--audio-bitrate não pode ser usado com --copy-audio",
+	"Using %s %s from %s instead of the analyzer's":                                       "Usando %s %s de %s em vez do valor do analisador",
+	"Overridden: %s":                  "Substituído: %s",
+	"invalid --extra-ffmpeg-args: %v": "--extra-ffmpeg-args inválido: %v",
+	"extra FFmpeg argument %q is not an option; input and output files cannot be added": "o argumento extra do FFmpeg %q não é uma opção; arquivos de entrada e saída não podem ser adicionados",
+	"extra FFmpeg option %s is set by the tool and cannot be passed through":            "a opção extra do FFmpeg %s é definida pela ferramenta e não pode ser repassada",
+	"extra FFmpeg option %s needs a value":                                              "a opção extra do FFmpeg %s precisa de um valor",
+	"Adding FFmpeg options from --extra-ffmpeg-args: %s":                                "Adicionando opções do FFmpeg de --extra-ffmpeg-args: %s",
+	"%s filter %s collides with %s, already in the video filter chain":                  "o filtro de %s %s conflita com %s, já presente na cadeia de filtros de vídeo",
+	"frame rate must be more than 0 (got %g)":                                           "a taxa de quadros deve ser maior que 0 (recebido %g)",
+	"denoise must be one of: %s (got %s)":                                               "denoise deve ser um de: %s (recebido %s)",
+	"video filters cannot be used with --copy-video":                                    "filtros de vídeo não podem ser usados com --copy-video",
+	"crop %s does not fit in the %dx%d frame":                                           "o recorte %s não cabe no quadro de %dx%d",
+	"invalid crop %q: expected width:height:x:y, e.g. 1920:800:0:140":                   "recorte inválido %q: esperado largura:altura:x:y, ex.: 1920:800:0:140",
+	"invalid scale %q: expected WIDTHxHEIGHT or a height, e.g. 1280x720 or 720":         "escala inválida %q: esperado LARGURAxALTURA ou uma altura, ex.: 1280x720 ou 720",
+	"--scale cannot be used with --renditions, which scale each output":                 "--scale não pode ser usado com --renditions, que redimensiona cada saída",
+	"The video is not HDR, so it is not tone mapped":                                    "O vídeo não é HDR, então o mapeamento de tons não é aplicado",
+	"HDR tone mapping":                                                                "Mapeamento de tons HDR",
+	"run without --tonemap":                                                           "execute sem --tonemap",
+	"keyint must be 1 frame or more (got %d)":                                         "keyint deve ser 1 quadro ou mais (recebido %d)",
+	"GOP seconds must be more than 0 (got %g)":                                        "os segundos de GOP devem ser maiores que 0 (recebido %g)",
+	"--keyint and --gop-seconds cannot be used together":                              "--keyint e --gop-seconds não podem ser usados juntos",
+	"the keyframe interval cannot be changed with --copy-video":                       "o intervalo entre quadros-chave não pode ser alterado com --copy-video",
+	"--gop-seconds needs the frame rate of the video, which is unknown; use --keyint": "--gop-seconds precisa da taxa de quadros do vídeo, que é desconhecida; use --keyint",
+	"Using a keyframe every %s frames from %s":                                        "Usando um quadro-chave a cada %s quadros de %s",
+	"Aspect Ratio":                   "Proporção",
+	"SAR %s, DAR %s, shown at %dx%d": "SAR %s, DAR %s, exibido em %dx%d",
+	"--square-pixels cannot be used with --renditions, which scale each output":                                   "--square-pixels não pode ser usado com --renditions, que redimensiona cada saída",
+	"The video already has square pixels":                                                                         "O vídeo já tem pixels quadrados",
+	"Keeping the pixel aspect ratio %s of the anamorphic video; use --square-pixels if players show it stretched": "Mantendo a proporção de pixel %s do vídeo anamórfico; use --square-pixels se os players o exibirem esticado",
+	"Encoding in one pass: %s":                                                                                    "Codificando em uma única passagem: %s",
+	"the video stream is copied":                                                                                  "o fluxo de vídeo é copiado",
+	"damaged inputs are encoded in one pass":                                                                      "entradas danificadas são codificadas em uma única passagem",
+	"--parallel off":                                                                                              "--parallel off",
+	"the duration of the video is unknown":                                                                        "a duração do vídeo é desconhecida",
+	"the video is shorter than %d seconds":                                                                        "o vídeo tem menos de %d segundos",
+	"screencasts encode quickly in one pass":                                                                      "gravações de tela são codificadas rapidamente em uma única passagem",
+	"%s is a hardware encoder":                                                                                    "%s é um codificador de hardware",
+	"%d CPU cores are kept busy by one encoder":                                                                   "%d núcleos de CPU são ocupados por um único codificador",
+	"the %.0f Mbps source would cost more to split than it saves":                                                 "dividir a origem de %.0f Mbps custaria mais do que economiza",
+	"there is not enough memory for two segments at once":                                                         "não há memória suficiente para dois segmentos ao mesmo tempo",
+	"parallel must be one of: auto, on, off (got %s)":                                                             "parallel deve ser um de: auto, on, off (recebido %s)",
+	"segments must be 2 or more (got %d)":                                                                         "segments deve ser 2 ou mais (recebido %d)",
+	"--segments cannot be used with --parallel off":                                                               "--segments não pode ser usado com --parallel off",
+	"%s hook: %s":           "hook %s: %s",
+	"%s hook %q failed: %v": "o hook %s %q falhou: %v",
+	"invalid hook %q: expected point=command, e.g. after_encode=./upload.sh":                          "hook inválido %q: esperado ponto=comando, por exemplo after_encode=./upload.sh",
+	"invalid hook point %q: expected one of after_analysis, before_encode, after_encode, after_batch": "ponto de hook inválido %q: esperado um de after_analysis, before_encode, after_encode, after_batch",
+	"Ignoring unknown hook point %s in the config file":                                               "Ignorando ponto de hook desconhecido %s no arquivo de configuração",
+	"Running %s hook: %s":                     "Executando o hook %s: %s",
+	"failed to parse %s: %v":                  "falha ao ler %s: %v",
+	"invalid exclude pattern %q in %s":        "padrão de exclusão inválido %q em %s",
+	"profile %s not found in the config file": "perfil %s não encontrado no arquivo de configuração",
+	"invalid %s: %v":                          "%s inválido: %v",
+	"Using %s: %s":                            "Usando %s: %s",
+	"route %d has no output directory":        "a rota %d não tem diretório de saída",
+	"route %d: content must be one of: animation, screencast, gaming, live_action, sports_action, documentary, unknown (got %s)": "rota %d: content deve ser um de: animation, screencast, gaming, live_action, sports_action, documentary, unknown (recebido %s)",
+	"route %d: min_height and max_height must be a range of lines, e.g. 2160 (got %d-%d)":                                        "rota %d: min_height e max_height devem ser um intervalo de linhas, ex.: 2160 (recebido %d-%d)",
+	"invalid routes in %s: %v":                                                                                        "rotas inválidas em %s: %v",
+	"Routing the %s output to %s":                                                                                     "Direcionando a saída %s para %s",
+	"sidecars must be one of: copy, move (got %s)":                                                                    "sidecars deve ser um de: copy, move (recebido %s)",
+	"subtitles cannot be muxed into %s files":                                                                         "legendas não podem ser incorporadas em arquivos %s",
+	"Could not look for sidecar files: %v":                                                                            "Não foi possível procurar arquivos auxiliares: %v",
+	"Subtitles cannot be muxed into %s files, leaving them as sidecar files":                                          "Legendas não podem ser incorporadas em arquivos %s, mantendo-as como arquivos auxiliares",
+	"Muxed %d subtitle files into the output: %s":                                                                     "%d arquivos de legenda incorporados à saída: %s",
+	"Found %d sidecar files next to the input (use --sidecars copy to bring them along): %s":                          "%d arquivos auxiliares encontrados junto à entrada (use --sidecars copy para levá-los junto): %s",
+	"Skipping sidecar file %s: it already exists (use -f to force overwrite)":                                         "Ignorando o arquivo auxiliar %s: ele já existe (use -f para sobrescrever)",
+	"Failed to move sidecar files: %v":                                                                                "Falha ao mover os arquivos auxiliares: %v",
+	"Failed to copy sidecar files: %v":                                                                                "Falha ao copiar os arquivos auxiliares: %v",
+	"Sidecar files next to the output: %s":                                                                            "Arquivos auxiliares junto à saída: %s",
+	"subtitle stream must be 0 or more (got %d)":                                                                      "o stream de legenda deve ser 0 ou mais (recebido %d)",
+	"burned-in subtitles are timed to the whole video":                                                                "legendas embutidas na imagem seguem o tempo do vídeo inteiro",
+	"burn-subs must be auto or an SRT, ASS, SSA or WebVTT file (got %s)":                                              "burn-subs deve ser auto ou um arquivo SRT, ASS, SSA ou WebVTT (recebido %s)",
+	"subtitle file does not exist: %s":                                                                                "o arquivo de legenda não existe: %s",
+	"Burning in the subtitles of %s":                                                                                  "Gravando na imagem as legendas de %s",
+	"Burning in subtitle stream %d (%s)":                                                                              "Gravando na imagem o stream de legenda %d (%s)",
+	"Burning in subtitle stream %d":                                                                                   "Gravando na imagem o stream de legenda %d",
+	"the subtitles of the video are images (%s), which cannot be burned in; give a subtitle file to --burn-subs":      "as legendas do vídeo são imagens (%s), que não podem ser gravadas na imagem; passe um arquivo de legenda para --burn-subs",
+	"The video has no subtitles to burn in":                                                                           "O vídeo não tem legendas para gravar na imagem",
+	"invalid language code %s (use codes such as en, eng or pt-BR)":                                                   "código de idioma inválido %s (use códigos como en, eng ou pt-BR)",
+	"no language codes in %s":                                                                                         "nenhum código de idioma em %s",
+	"--keep-audio-langs cannot be used with --no-audio":                                                               "--keep-audio-langs não pode ser usado com --no-audio",
+	"Keeping %d of %d audio tracks: %s":                                                                               "Mantendo %d de %d faixas de áudio: %s",
+	"No audio track is in %s, keeping the audio as it is":                                                             "Nenhuma faixa de áudio está em %s, mantendo o áudio como está",
+	"HDR10+ metadata is numbered from the first frame":                                                                "os metadados HDR10+ são numerados a partir do primeiro quadro",
+	"%s was not found on the PATH":                                                                                    "%s não foi encontrado no PATH",
+	"Could not read the HDR metadata of the frames: %v":                                                               "Não foi possível ler os metadados HDR dos quadros: %v",
+	"The output of %s is not HDR; encode with libx265 to keep HDR, or use --tonemap for SDR":                          "A saída de %s não é HDR; codifique com libx265 para manter o HDR, ou use --tonemap para SDR",
+	"Dolby Vision profile 5 has no HDR10 base layer, so the output will have wrong colors; keep the original instead": "O perfil 5 de Dolby Vision não tem camada base HDR10, então a saída terá cores erradas; mantenha o original",
+	"The Dolby Vision metadata of the source is not kept; players will show its HDR10 base layer":                     "Os metadados Dolby Vision da origem não são mantidos; os players mostrarão sua camada base HDR10",
+	"The HDR10+ dynamic metadata of the source is lost (use --keep-hdr10plus to copy it, which needs %s)":             "Os metadados dinâmicos HDR10+ da origem são perdidos (use --keep-hdr10plus para copiá-los, o que requer %s)",
+	"The HDR10+ metadata of %s video cannot be extracted, so it is lost":                                              "Os metadados HDR10+ de vídeo %s não podem ser extraídos, então são perdidos",
+	"The HDR10+ metadata is lost: it follows the frames of the source, and --fps changes them":                        "Os metadados HDR10+ são perdidos: eles seguem os quadros da origem, e --fps os altera",
+	"Could not extract the HDR10+ metadata, so it is lost: %v":                                                        "Não foi possível extrair os metadados HDR10+, então são perdidos: %v",
+	"Copying the HDR10+ metadata of the source":                                                                       "Copiando os metadados HDR10+ da origem",
+	"Found series %s with %d episodes":                                                                                "Série %s encontrada com %d episódios",
+	"%s does not match series %s (%s), analyzing it in full":                                                          "%s não corresponde à série %s (%s), analisando-o por completo",
+	"Using the shared analysis of series %s":                                                                          "Usando a análise compartilhada da série %s",
+	"Analyzing %d episodes of series %s to share their analysis with the others":                                      "Analisando %d episódios da série %s para compartilhar sua análise com os demais",
+	"Could not analyze episode %s: %v":                                                                                "Não foi possível analisar o episódio %s: %v",
+	"No episode of series %s could be analyzed, analyzing each in full":                                               "Nenhum episódio da série %s pôde ser analisado, analisando cada um por completo",
+	"Series %s: %s content, %s motion":                                                                                "Série %s: conteúdo %s, movimento %s",
+	"resolution":                                                                                                      "resolução",
+	"codec":                                                                                                           "codec",
+	"frame rate":                                                                                                      "taxa de quadros",
+	"duration":                                                                                                        "duração",
+	"Failed to write comparison clip: %v":                                                                             "Falha ao gravar o clipe de comparação: %v",
+	"Comparison Clip":                                                                                                 "Clipe de Comparação",
+	"unknown comparison mode %q (use %s or %s)":                                                                       "modo de comparação desconhecido %q (use %s ou %s)",
+	"🔍 FRAME COMPARISON (worst first):":                                                                               "🔍 COMPARAÇÃO DE QUADROS (piores primeiro):",
+	"unknown compatibility target %q (use one of: %s)":                                                                "alvo de compatibilidade desconhecido %q (use um de: %s)",
+	"--compat cannot be used with --copy-video":                                                                       "--compat não pode ser usado com --copy-video",
+	"--compat %s devices do not play %s (use --codec %s)":                                                             "dispositivos de --compat %s não reproduzem %s (use --codec %s)",
+	"--compat %s devices need pixel format %s (got --pix-fmt %s)":                                                     "dispositivos de --compat %s precisam do formato de pixel %s (recebido --pix-fmt %s)",
+	"Using %s %s for --compat %s":                                                                                     "Usando %s %s para --compat %s",
+	"%s devices may not open %s files; name the output %s":                                                            "dispositivos %s podem não abrir arquivos %s; nomeie a saída como %s",
+	"Using level %s for the %dx%d output at %.3g fps":                                                                 "Usando o nível %s para a saída %dx%d a %.3g fps",
+	"%dx%d at %.3g fps is beyond every %s level, signalling level %s":                                                 "%dx%d a %.3g fps está além de todos os níveis de %s, sinalizando o nível %s",
+	"--compat %s devices decode %s up to level %s, but %dx%d at %.3g fps needs level %s":                              "os dispositivos de --compat %s decodificam %s até o nível %s, mas %dx%d a %.3g fps precisa do nível %s",
+	"Writing fragmented MP4 for streaming":                                                                            "Gravando MP4 fragmentado para streaming",
+	"--fragmented needs an MP4 or MOV output (got %s)":                                                                "--fragmented requer uma saída MP4 ou MOV (recebido %s)",
+	"Leaving out %d image subtitle streams, which %s files cannot hold":                                               "Deixando de fora %d faixas de legenda em imagem, que arquivos %s não comportam",
+	"%s files cannot hold %s without re-encoding (remux to mkv, or compress instead)":                                 "arquivos %s não comportam %s sem recodificar (remultiplexe para mkv ou comprima)",
+	"remux container must be one of: %s (got %s)":                                                                     "o contêiner da remultiplexação deve ser um de: %s (recebido %s)",
+	"--remux-only copies the streams unchanged and cannot be used with %s":                                            "--remux-only copia as faixas sem alterá-las e não pode ser usado com %s",
+	"--remux-only %s needs a .%s output (got %s)":                                                                     "--remux-only %s precisa de uma saída .%s (recebido %s)",
+	"Remux failed: %v":                                                  "Falha na remultiplexação: %v",
+	"Remux":                                                             "Remultiplexação",
+	"Copying the streams of %s into %s":                                 "Copiando as faixas de %s para %s",
+	"Remuxed %s to %s in %s (%s, was %s)":                               "%s remultiplexado para %s em %s (%s, era %s)",
+	"Failed to measure the bitrate of each second: %v":                  "Falha ao medir o bitrate de cada segundo: %v",
+	"Source bitrate: %s":                                                "Bitrate da origem: %s",
+	"Source Bitrate (median)":                                           "Bitrate da Origem (mediana)",
+	"Source Bitrate (95th pct)":                                         "Bitrate da Origem (percentil 95)",
+	"Source Bitrate (peak)":                                             "Bitrate da Origem (pico)",
+	"CompressVideo - Library Scan":                                      "CompressVideo - Varredura da Biblioteca",
+	"a directory is required (compressvideo scan <dir>)":                "é necessário um diretório (compressvideo scan <dir>)",
+	"input must be a directory: %s":                                     "a entrada deve ser um diretório: %s",
+	"sort must be one of: %s (got %s)":                                  "a ordenação deve ser uma de: %s (recebido %s)",
+	"--top must be 0 or more (got %d)":                                  "--top deve ser 0 ou mais (recebido %d)",
+	"Analysis cache unavailable, estimating from the streams alone: %v": "Cache de análise indisponível, estimando apenas pelas faixas: %v",
+	"Probing %d/%d: %s":                                                 "Examinando %d/%d: %s",
+	"Failed to probe %s: %v":                                            "Falha ao examinar %s: %v",
+	"failed to write %s: %v":                                            "falha ao gravar %s: %v",
+	"Scan written to %s":                                                "Varredura gravada em %s",
+	"No video files found in %s":                                        "Nenhum arquivo de vídeo encontrado em %s",
+	"Videos":                                                            "Vídeos",
+	"Est. Savings":                                                      "Economia Est.",
+	"Potential":                                                         "Potencial",
+	"Content":                                                           "Conteúdo",
+	"File":                                                              "Arquivo",
+	"By Codec":                                                          "Por Codec",
+	"By Resolution":                                                     "Por Resolução",
+	"Files":                                                             "Arquivos",
+	"Totals":                                                            "Totais",
+	"Could Not Probe":                                                   "Não Examinados",
+	"Total Size":                                                        "Tamanho Total",
+	"Total Duration":                                                    "Duração Total",
+	"--resume requires a directory input":                               "--resume requer um diretório como entrada",
+	"Resuming the last run: %d completed, %d failed, %d pending":                   "Retomando a última execução: %d concluídos, %d com falha, %d pendentes",
+	"No earlier run of this command to resume, starting from the beginning":        "Nenhuma execução anterior deste comando para retomar, começando do início",
+	"Failed to read the progress of the last run, starting from the beginning: %v": "Falha ao ler o progresso da última execução, começando do início: %v",
+	"Failed to save the progress of this run, it cannot be resumed: %v":            "Falha ao salvar o progresso desta execução, ela não poderá ser retomada: %v",
+	"Progress file: %s":                                                           "Arquivo de progresso: %s",
+	"Failed to save the progress of this run: %v":                                 "Falha ao salvar o progresso desta execução: %v",
+	"Skipping %s: completed by an earlier run":                                    "Ignorando %s: concluído por uma execução anterior",
+	"Retrying %s, which failed in the last run":                                   "Tentando novamente %s, que falhou na última execução",
+	"Run the same command with --resume to continue with the files left":          "Execute o mesmo comando com --resume para continuar com os arquivos restantes",
+	"retry-with must be a list of: %s (got %s)":                                   "retry-with deve ser uma lista de: %s (recebido %s)",
+	"retries must be 0 or more (got %d)":                                          "retries deve ser 0 ou mais (recebido %d)",
+	"retry-backoff must be 0 or more (got %s)":                                    "retry-backoff deve ser 0 ou mais (recebido %s)",
+	"quarantine-dir is not a directory: %s":                                       "quarantine-dir não é um diretório: %s",
+	"Not retrying %s: %s errors do not go away on another try":                    "Não tentando novamente %s: erros %s não desaparecem em outra tentativa",
+	"Not retrying %s: --max-runtime would be reached":                             "Não tentando novamente %s: o --max-runtime seria atingido",
+	"%s failed (%s error), retrying in %s with %s (retry %d of %d)":               "%s falhou (erro %s), tentando novamente em %s com %s (tentativa %d de %d)",
+	"%s failed (%s error), retrying in %s (retry %d of %d)":                       "%s falhou (erro %s), tentando novamente em %s (tentativa %d de %d)",
+	"Failed to quarantine %s: %v":                                                 "Falha ao colocar %s em quarentena: %v",
+	"Moved %s to %s":                                                              "%s movido para %s",
+	"only-between must be a time window such as 01:00-07:00 (got %s)":             "only-between deve ser uma janela de horário como 01:00-07:00 (recebido %s)",
+	"only-between must end at another time than it starts (got %s)":               "only-between deve terminar em um horário diferente do início (recebido %s)",
+	"Inside --only-between %s, encoding resumed":                                  "Dentro de --only-between %s, codificação retomada",
+	"Outside --only-between %s, encoding paused until %s":                         "Fora de --only-between %s, codificação pausada até %s",
+	"Encoding stays paused for: %s":                                               "A codificação continua pausada por: %s",
+	"max-load must be 0 or more (got %g)":                                         "max-load deve ser 0 ou mais (recebido %g)",
+	"max-temp must be 0 or more (got %g)":                                         "max-temp deve ser 0 ou mais (recebido %g)",
+	"The load average cannot be read on this system, --max-load has no effect":    "A carga média não pode ser lida neste sistema, --max-load não tem efeito",
+	"The CPU temperature cannot be read on this system, --max-temp has no effect": "A temperatura da CPU não pode ser lida neste sistema, --max-temp não tem efeito",
+	"Machine running hot (%s), encoding fewer segments at once":                   "Máquina sobrecarregada (%s), codificando menos segmentos ao mesmo tempo",
+	"Machine running hot (%s), encoding paused until it cools down":               "Máquina sobrecarregada (%s), codificação pausada até ela esfriar",
+	"Machine cooled down (%s), encoding resumed":                                  "Máquina esfriou (%s), codificação retomada",
+	"Machine cooled down (%s), encoding more segments at once":                    "Máquina esfriou (%s), codificando mais segmentos ao mesmo tempo",
+	"load %.1f": "carga %.1f",
+	"The power source cannot be read on this system, --pause-on-battery and --slow-on-battery have no effect":                  "A fonte de energia não pode ser lida neste sistema, --pause-on-battery e --slow-on-battery não têm efeito",
+	"Running on battery, encoding paused until the power is plugged in":                                                        "Funcionando na bateria, codificação pausada até a energia ser conectada",
+	"Running on battery, encoding one segment at a time":                                                                       "Funcionando na bateria, codificando um segmento por vez",
+	"Power plugged in, encoding at full speed again":                                                                           "Energia conectada, codificando em velocidade máxima novamente",
+	"--metrics-token, --metrics-basic-auth and the --metrics-tls options need --metrics-addr":                                  "--metrics-token, --metrics-basic-auth e as opções --metrics-tls precisam de --metrics-addr",
+	"--metrics-basic-auth must be user:password":                                                                               "--metrics-basic-auth deve ser usuário:senha",
+	"--metrics-tls-cert and --metrics-tls-key must be set together":                                                            "--metrics-tls-cert e --metrics-tls-key devem ser definidos juntos",
+	"Metrics available at https://%s/metrics":                                                                                  "Métricas disponíveis em https://%s/metrics",
+	"The metrics endpoint is open to the network without credentials, protect it with --metrics-token or --metrics-basic-auth": "O endpoint de métricas está aberto na rede sem credenciais, proteja-o com --metrics-token ou --metrics-basic-auth",
+	"CompressVideo - Bot": "CompressVideo - Bot",
+	"Anyone who can message the bot can use it, limit it to your chats or users with --allow": "Qualquer pessoa que possa enviar mensagens ao bot pode usá-lo, limite-o aos seus chats ou usuários com --allow",
+	"Waiting for videos on %s (replies up to %s)":                                             "Aguardando vídeos no %s (respostas de até %s)",
+	"Could not check %s for new videos: %v":                                                   "Não foi possível verificar novos vídeos no %s: %v",
+	"Bot stopped":                                                                             "Bot parado",
+	"--discord-token needs the channels to watch in --discord-channel":                        "--discord-token precisa dos canais a observar em --discord-channel",
+	"--discord-channel needs --discord-token":                                                 "--discord-channel precisa de --discord-token",
+	"give a bot token with --telegram-token or --discord-token":                               "informe o token de um bot com --telegram-token ou --discord-token",
+	"Ignoring %s from user %s in chat %s, neither is in --allow":                              "Ignorando %s do usuário %s no chat %s, nenhum dos dois está em --allow",
+	"Could not reply on %s: %v":                                                               "Não foi possível responder no %s: %v",
+	"%s is %s, larger than the %s that %s lets bots download":                                 "%s tem %s, mais que os %s que o %s permite aos bots baixar",
+	"Could not compress %s: %v":                                                               "Não foi possível comprimir %s: %v",
+	"Could not download %s: %v":                                                               "Não foi possível baixar %s: %v",
+	"%s is not a video that can be read: %v":                                                  "%s não é um vídeo que possa ser lido: %v",
+	"%s is too long to fit in %s at a watchable quality":                                      "%s é longo demais para caber em %s com uma qualidade assistível",
+	"Encoding %s with the video bitrate capped at %s to fit in %s":                            "Codificando %s com o bitrate de vídeo limitado a %s para caber em %s",
+	"%s: %s, %.0f%% smaller":                                                                  "%s: %s, %.0f%% menor",
+	"Sending %s (%s) on %s":                                                                   "Enviando %s (%s) no %s",
+	"%s came out at %s, still over the %s limit":                                              "%s ficou com %s, ainda acima do limite de %s",
+	"%s came out at %s, over the %s limit; encoding it again":                                 "%s ficou com %s, acima do limite de %s; codificando novamente",
+	"CompressVideo - Quick":                                                                   "CompressVideo - Rápido",
+	"could not find the path of compressvideo: %v":                                            "não foi possível encontrar o caminho do compressvideo: %v",
+	"Installed %q at %s":                                                                      "%q instalado em %s",
+	"Removed %s":                                                                              "%s removido",
+	"%s is a folder, not a video":                                                             "%s é uma pasta, não um vídeo",
+	"Not quarantining %s: %s errors are not caused by the source":                             "Não colocando %s em quarentena: erros %s não são causados pelo arquivo de origem",
+	"the MP4 index (moov atom) is missing, usually because the recording was cut off before it was finalized; it cannot be read until a tool such as untrunc rebuilds the index": "o índice do MP4 (átomo moov) está ausente, geralmente porque a gravação foi interrompida antes de ser finalizada; o arquivo não pode ser lido até que uma ferramenta como o untrunc reconstrua o índice",
+	"s3:// outputs take a single file, not a folder of outputs: %s":                                   "saídas s3:// recebem um único arquivo, não uma pasta de saídas: %s",
+	"--compat cannot be used with --hw-encoder, which ignores the profile and level the devices need": "--compat não pode ser usado com --hw-encoder, que ignora o perfil e o nível de que os dispositivos precisam",
+	"Failed to cache compression outcome: %v":                                                         "Falha ao salvar o resultado da compressão no cache: %v",
+	"%s was already compressed to %s (%s, %.1f%% smaller) with these settings, skipping":              "%s já foi comprimido para %s (%s, %.1f%% menor) com estas configurações, pulando",
+	"%s was already compressed to %s (%s, %.1f%% smaller) with these settings":                        "%s já foi comprimido para %s (%s, %.1f%% menor) com estas configurações",
+	"Failed to cache analysis: %v":                                                                    "Falha ao salvar a análise no cache: %v",
+	"Failed to clean expired cache entries: %v":                                                       "Falha ao limpar entradas expiradas do cache: %v",
+	"Failed to clean expired entries: %v":                                                             "Falha ao limpar entradas expiradas: %v",
+	"Failed to clear cache: %v":                                                                       "Falha ao limpar o cache: %v",
+	"Failed to get cache statistics: %v":                                                              "Falha ao obter estatísticas do cache: %v",
+	"Failed to get updated cache statistics: %v":                                                      "Falha ao obter estatísticas atualizadas do cache: %v",
+	"Failed to initialize cache: %v":                                                                  "Falha ao inicializar o cache: %v",
+	"Failed to invalidate old cache entry: %v":                                                        "Falha ao invalidar entrada antiga do cache: %v",
+	"Invalid/expired entries: %d":                                                                     "Entradas inválidas/expiradas: %d",
+	"No expired entries found":                                                                        "Nenhuma entrada expirada encontrada",
+	"No valid cache entry found, analyzing video...":                                                  "Nenhuma entrada válida no cache, analisando o vídeo...",
+	"Total entries: %d":                                                                               "Total de entradas: %d",
+	"Updated Cache Statistics":                                                                        "Estatísticas Atualizadas do Cache",
+	"Using cached analysis for %s":                                                                    "Usando análise em cache para %s",
+	"Valid entries: %d":                                                                               "Entradas válidas: %d",
+	"Video analysis cache disabled":                                                                   "Cache de análise de vídeo desativado",
+	"Video analysis cache enabled":                                                                    "Cache de análise de vídeo ativado",
+	"• Cache entries expire automatically after 30 days by default":                                   "• As entradas do cache expiram automaticamente após 30 dias por padrão",
+	"• Cache speeds up analysis of previously processed videos":                                       "• O cache acelera a análise de vídeos já processados",
+	"• Regular cleaning keeps the cache size manageable":                                              "• Limpezas regulares mantêm o tamanho do cache sob controle",
+	"• Set expiration period with '--cache-max-age' or '-A' flag":                                     "• Defina o período de expiração com '--cache-max-age' ou '-A'",
+	"• Use '--use-cache' or '-c' flag with compressvideo to enable caching":                           "• Use '--use-cache' ou '-c' no compressvideo para ativar o cache",
+
+	// Titles and sections
+	"CompressVideo - Cache Manager":           "CompressVideo - Gerenciador de Cache",
+	"CompressVideo - Smart Video Compression": "CompressVideo - Compressão Inteligente de Vídeo",
+	"CompressVideo - Video Analysis":          "CompressVideo - Análise de Vídeo",
+	"Compression Process":                     "Processo de Compressão",
+	"Content Analysis Results":                "Resultados da Análise de Conteúdo",
+	"Estimate":                                "Estimativa",
+	"Processing Directory":                    "Processando Diretório",
+	"Processing Video":                        "Processando Vídeo",
+	"Recommended Settings":                    "Configurações Recomendadas",
+	"Video Information":                       "Informações do Vídeo",
+	"Audio Streams: %d":                       "Streams de Áudio: %d",
+	"Video Stream:":                           "Stream de Vídeo:",
+
+	// Compression flow
+	"Analyzing video content: %s":                               "Analisando o conteúdo do vídeo: %s",
+	"Checksums saved to: %s":                                    "Checksums salvos em: %s",
+	"Compressed %s in %s, saving %s of space":                   "%s comprimido em %s, economizando %s de espaço",
+	"Compression failed: %v":                                    "Falha na compressão: %v",
+	"Compression report saved to: %s":                           "Relatório de compressão salvo em: %s",
+	"Detected content type: %s":                                 "Tipo de conteúdo detectado: %s",
+	"Determined motion complexity: %s":                          "Complexidade de movimento determinada: %s",
+	"Estimated compression potential: %d%%":                     "Potencial de compressão estimado: %d%%",
+	"FFmpeg not available, HTML report will not include frames": "FFmpeg indisponível, o relatório HTML não incluirá quadros",
+	"FFprobe not found, reading basic stream info from ffmpeg output; analysis will be limited": "FFprobe não encontrado, lendo informações básicas dos streams pela saída do ffmpeg; a análise será limitada",
+	"Failed to calculate frame complexity: %v":                                                  "Falha ao calcular a complexidade dos quadros: %v",
+	"Failed to detect scene changes: %v":                                                        "Falha ao detectar mudanças de cena: %v",
+	"Failed to determine compression settings: %v":                                              "Falha ao determinar as configurações de compressão: %v",
+	"Failed to process %s: %v":                                                                  "Falha ao processar %s: %v",
+	"Failed to save report to file: %v":                                                         "Falha ao salvar o relatório: %v",
+	"Failed to send webhook notification: %v":                                                   "Falha ao enviar notificação por webhook: %v",
+	"Failed to start metrics endpoint on %s: %v":                                                "Falha ao iniciar o endpoint de métricas em %s: %v",
+	"Failed to write checksums: %v":                                                             "Falha ao gravar checksums: %v",
+	"Merging compressed segments...":                                                            "Juntando os segmentos comprimidos...",
+	"Metrics available at http://%s/metrics":                                                    "Métricas disponíveis em http://%s/metrics",
+	"No video files found in directory":                                                         "Nenhum arquivo de vídeo encontrado no diretório",
+	"Operation completed in %s (%.1f units/s)":                                                  "Operação concluída em %s (%.1f unidades/s)",
+	"Operation completed in %s":                                                                 "Operação concluída em %s",
+	"Optimal bitrate: %d kbps":                                                                  "Bitrate ideal: %d kbps",
+	"Probing CRF values for target VMAF %.1f...":                                                "Testando valores de CRF para o VMAF alvo %.1f...",
+	"Processed %d video files":                                                                  "%d arquivos de vídeo processados",
+	"Processing video %s...":                                                                    "Processando o vídeo %s...",
+	"Recommended codec: %s":                                                                     "Codec recomendado: %s",
+	"Recommended compression settings:":                                                         "Configurações de compressão recomendadas:",
+	"Selected CRF %d (predicted VMAF %.2f)":                                                     "CRF %d selecionado (VMAF previsto %.2f)",
+	"Skipping %s: output file already exists (use -f to force overwrite)":                       "Ignorando %s: o arquivo de saída já existe (use -f para sobrescrever)",
+	"Starting compression process...":                                                           "Iniciando o processo de compressão...",
+	"Target VMAF search failed, keeping CRF %s: %v":                                             "A busca pelo VMAF alvo falhou, mantendo CRF %s: %v",
+	"Encoder %s is not available in this FFmpeg build, using %s instead":                        "O encoder %s não está disponível neste build do FFmpeg, usando %s",
+	"Using parallel compression for faster processing (%d segments, %d at a time)":              "Usando compressão paralela para acelerar o processamento (%d segmentos, %d por vez)",
+	"VMAF probe: CRF %d → %.2f":                                                                 "Teste de VMAF: CRF %d → %.2f",
+	"Video compression completed successfully!":                                                 "Compressão de vídeo concluída com sucesso!",
+	"Desktop notification failed: %v":                                                           "Falha na notificação da área de trabalho: %v",
+	"Compressing Video":                                                                         "Comprimindo Vídeo",
+	"[%s] Compressing %s":                                                                       "[%s] Comprimindo %s",
+	"Encoding %s is estimated to take %s. Continue?":                                            "A codificação de %s deve levar %s. Continuar?",
+	"[y/N]":              "[s/N]",
+	"less than a minute": "menos de um minuto",
+
+	// Field labels
+	"Bitrate":                    "Bitrate",
+	"Channels":                   "Canais",
+	"Codec":                      "Codec",
+	"Language":                   "Idioma",
+	"Sample Rate":                "Taxa de Amostragem",
+	"Video Bitrate":              "Bitrate de Vídeo",
+	"Frame Rate":                 "Taxa de Quadros",
+	"HDR":                        "HDR",
+	"Pixel Format":               "Formato de Pixel",
+	"Profile":                    "Perfil",
+	"Resolution":                 "Resolução",
+	"Content Type":               "Tipo de Conteúdo",
+	"Duration":                   "Duração",
+	"Est. Compression Potential": "Potencial de Compressão (est.)",
+	"Estimated Encode Time":      "Tempo de Codificação Estimado",
+	"Estimated Output Size":      "Tamanho de Saída Estimado",
+	"Estimated Savings":          "Economia Estimada",
+	"Format":                     "Formato",
+	"Frame Complexity":           "Complexidade dos Quadros",
+	"Input Directory":            "Diretório de Entrada",
+	"Input File":                 "Arquivo de Entrada",
+	"Motion Complexity":          "Complexidade de Movimento",
+	"Optimal Bitrate":            "Bitrate Ideal",
+	"Output Directory":           "Diretório de Saída",
+	"Output File":                "Arquivo de Saída",
+	"Preset":                     "Preset",
+	"Quality Level":              "Nível de Qualidade",
+	"Recommended Codec":          "Codec Recomendado",
+	"Resolution Type":            "Tipo de Resolução",
+	"Scene Changes":              "Mudanças de Cena",
+	"Size":                       "Tamanho",
+	"Spatial Complexity":         "Complexidade Espacial",
+
+	// CLI errors
+	"input file does not exist: %s":                              "o arquivo de entrada não existe: %s",
+	"quality must be between 1-5 (got %d)":                       "a qualidade deve estar entre 1 e 5 (recebido %d)",
+	"preset must be one of: fast, balanced, thorough (got %s)":   "o preset deve ser fast, balanced ou thorough (recebido %s)",
+	"report format must be one of: txt, json, md, html (got %s)": "o formato do relatório deve ser txt, json, md ou html (recebido %s)",
+	"target VMAF must be between 0-100 (got %.1f)":               "o VMAF alvo deve estar entre 0 e 100 (recebido %.1f)",
+	"output file already exists (use -f to force overwrite): %s": "o arquivo de saída já existe (use -f para sobrescrever): %s",
+	"error accessing input file: %w":                             "erro ao acessar o arquivo de entrada: %w",
+	"failed to create report directory: %w":                      "falha ao criar o diretório de relatórios: %w",
+	"failed to create output directory: %w":                      "falha ao criar o diretório de saída: %w",
+	"failed to read input directory: %w":                         "falha ao ler o diretório de entrada: %w",
+	"compression cancelled by user":                              "compressão cancelada pelo usuário",
+	"an input file is required (compressvideo analyze <file>)":   "é necessário informar um arquivo (compressvideo analyze <arquivo>)",
+	"input must be a file, not a directory: %s":                  "a entrada deve ser um arquivo, não um diretório: %s",
+	"failed to determine compression settings: %v":               "falha ao determinar as configurações de compressão: %v",
+}
@@ -0,0 +1,113 @@
+// Package i18n translates user-facing messages.
+//
+// Messages are identified by their English text, usually a format string
+// such as "Compression failed: %v". The English catalog is therefore the
+// message IDs themselves; other catalogs map an ID to its translation. An
+// ID without a translation is shown in English.
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Supported languages
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+)
+
+// catalogs maps a language to its translations, keyed by message ID
+var catalogs = map[string]map[string]string{
+	English:    {},
+	Portuguese: ptBR,
+}
+
+var (
+	mu      sync.RWMutex
+	current = English
+)
+
+// Supported returns the languages with a catalog
+func Supported() []string {
+	return []string{English, Portuguese}
+}
+
+// SetLanguage selects the catalog used by Tr. Unknown languages fall back to English.
+func SetLanguage(lang string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = Normalize(lang)
+}
+
+// Language returns the selected language
+func Language() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Normalize maps a locale such as "pt_BR.UTF-8", "pt" or "en_US" to a
+// supported language
+func Normalize(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "pt" || strings.HasPrefix(lang, "pt_") || strings.HasPrefix(lang, "pt-") {
+		return Portuguese
+	}
+	return English
+}
+
+// Detect picks the language from the --lang flag, falling back to the
+// LC_ALL, LC_MESSAGES and LANG environment variables
+func Detect(flag string) string {
+	if flag != "" {
+		return Normalize(flag)
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(env); value != "" && value != "C" && value != "POSIX" {
+			return Normalize(value)
+		}
+	}
+	return English
+}
+
+// Tr returns the translation of a message ID. Leading and trailing
+// whitespace (indentation, blank lines) is kept as-is around the translation.
+func Tr(id string) string {
+	mu.RLock()
+	catalog := catalogs[current]
+	mu.RUnlock()
+
+	if translated, ok := catalog[id]; ok {
+		return translated
+	}
+
+	trimmed := strings.TrimSpace(id)
+	if trimmed == "" || trimmed == id {
+		return id
+	}
+	translated, ok := catalog[trimmed]
+	if !ok {
+		return id
+	}
+	start := strings.Index(id, trimmed)
+	return id[:start] + translated + id[start+len(trimmed):]
+}
+
+// T translates a message ID and formats it with args
+func T(id string, args ...interface{}) string {
+	if len(args) == 0 {
+		return Tr(id)
+	}
+	return fmt.Sprintf(Tr(id), args...)
+}
+
+// Errorf translates a format string and returns it as an error. %w is supported.
+func Errorf(format string, args ...interface{}) error {
+	return fmt.Errorf(Tr(format), args...)
+}
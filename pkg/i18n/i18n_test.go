@@ -0,0 +1,60 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, Portuguese, Normalize("pt_BR.UTF-8"))
+	assert.Equal(t, Portuguese, Normalize("pt-BR"))
+	assert.Equal(t, Portuguese, Normalize("pt"))
+	assert.Equal(t, English, Normalize("en_US.UTF-8"))
+	assert.Equal(t, English, Normalize("de_DE"))
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LANG", "pt_BR.UTF-8")
+
+	assert.Equal(t, Portuguese, Detect(""))
+	// The flag wins over the environment
+	assert.Equal(t, English, Detect("en"))
+
+	t.Setenv("LANG", "C")
+	assert.Equal(t, English, Detect(""))
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLanguage(English)
+
+	SetLanguage(English)
+	assert.Equal(t, "FFmpeg found: /usr/bin/ffmpeg", T("FFmpeg found: %s", "/usr/bin/ffmpeg"))
+
+	SetLanguage("pt_BR")
+	assert.Equal(t, "FFmpeg encontrado: /usr/bin/ffmpeg", T("FFmpeg found: %s", "/usr/bin/ffmpeg"))
+	// Indentation and leading blank lines are kept around the translation
+	assert.Equal(t, "  Resolução", Tr("  Resolution"))
+	assert.Equal(t, "\nStream de Vídeo:", Tr("\nVideo Stream:"))
+	// Unknown messages are shown as-is
+	assert.Equal(t, "  not in any catalog", Tr("  not in any catalog"))
+
+	cause := errors.New("boom")
+	err := Errorf("failed to find FFmpeg: %w", cause)
+	assert.True(t, errors.Is(err, cause))
+}
+
+// Translations must use the same format verbs as their message IDs
+func TestCatalogVerbs(t *testing.T) {
+	verbs := regexp.MustCompile(`%[-+# 0]*[0-9.]*[a-zA-Z%]`)
+	for lang, catalog := range catalogs {
+		for id, translated := range catalog {
+			assert.Equal(t, verbs.FindAllString(id, -1), verbs.FindAllString(translated, -1),
+				"%s translation of %q", lang, id)
+		}
+	}
+}
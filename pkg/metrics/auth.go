@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// Auth protects an endpoint with a bearer token, HTTP basic auth or both,
+// a request passing with either. The zero value lets every request through.
+type Auth struct {
+	Token    string // Token expected in an "Authorization: Bearer" header
+	User     string // Basic auth user
+	Password string // Basic auth password
+}
+
+// IsZero reports whether no credentials are set
+func (a Auth) IsZero() bool {
+	return a.Token == "" && a.User == "" && a.Password == ""
+}
+
+// Allowed reports whether the request carries the credentials
+func (a Auth) Allowed(r *http.Request) bool {
+	if a.IsZero() {
+		return true
+	}
+	if a.Token != "" {
+		header := r.Header.Get("Authorization")
+		if token, ok := strings.CutPrefix(header, "Bearer "); ok && equal(strings.TrimSpace(token), a.Token) {
+			return true
+		}
+	}
+	if a.User != "" || a.Password != "" {
+		user, password, ok := r.BasicAuth()
+		// Both are compared so a wrong user takes as long as a wrong password
+		userOK := equal(user, a.User)
+		passwordOK := equal(password, a.Password)
+		if ok && userOK && passwordOK {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler wraps next, answering 401 Unauthorized to requests without the
+// credentials
+func (a Auth) Handler(next http.Handler) http.Handler {
+	if a.IsZero() {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Allowed(r) {
+			if a.User != "" || a.Password != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="compressvideo", charset="UTF-8"`)
+			} else {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="compressvideo"`)
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// equal compares two secrets in constant time
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
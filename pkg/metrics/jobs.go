@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// encodeDurationBuckets covers short clips up to multi-hour encodes (seconds)
+var encodeDurationBuckets = []float64{10, 30, 60, 120, 300, 600, 1200, 1800, 3600, 7200, 14400}
+
+// JobMetrics are the metrics recorded for compression jobs
+type JobMetrics struct {
+	Registry       *Registry
+	JobsProcessed  *Counter
+	Failures       *Counter
+	BytesSaved     *Counter
+	BytesIn        *Counter
+	EncodeDuration *Histogram
+	QueueDepth     *Gauge
+	ActiveJobs     *Gauge
+}
+
+// NewJobMetrics registers the job metrics on a new registry
+func NewJobMetrics() *JobMetrics {
+	r := NewRegistry()
+	return &JobMetrics{
+		Registry:       r,
+		JobsProcessed:  r.NewCounter("compressvideo_jobs_processed_total", "Number of compression jobs finished, by status.", "status"),
+		Failures:       r.NewCounter("compressvideo_failures_total", "Number of failed compression jobs.", ""),
+		BytesSaved:     r.NewCounter("compressvideo_bytes_saved_total", "Bytes saved by compression.", ""),
+		BytesIn:        r.NewCounter("compressvideo_input_bytes_total", "Bytes of input video processed.", ""),
+		EncodeDuration: r.NewHistogram("compressvideo_encode_duration_seconds", "Time spent processing each file, analysis included.", encodeDurationBuckets),
+		QueueDepth:     r.NewGauge("compressvideo_queue_depth", "Files waiting to be compressed."),
+		ActiveJobs:     r.NewGauge("compressvideo_active_jobs", "Files currently being compressed."),
+	}
+}
+
+// JobSucceeded records a successful job
+func (m *JobMetrics) JobSucceeded(inputBytes, savedBytes int64, duration time.Duration) {
+	m.JobsProcessed.Inc("success")
+	m.BytesIn.Add("", float64(inputBytes))
+	if savedBytes > 0 {
+		m.BytesSaved.Add("", float64(savedBytes))
+	}
+	m.EncodeDuration.Observe(duration.Seconds())
+}
+
+// JobFailed records a failed job
+func (m *JobMetrics) JobFailed() {
+	m.JobsProcessed.Inc("failed")
+	m.Failures.Inc("")
+}
+
+// ServeOptions protect the metrics endpoint
+type ServeOptions struct {
+	Auth     Auth   // Credentials requests must carry, none by default
+	CertFile string // TLS certificate file, plain HTTP when empty
+	KeyFile  string // TLS private key file
+}
+
+// Serve starts an HTTP server exposing /metrics on addr. It returns the
+// server so the caller can shut it down, and the address actually bound.
+func (m *JobMetrics) Serve(addr string) (*http.Server, string, error) {
+	return m.ServeWith(addr, ServeOptions{})
+}
+
+// ServeWith starts an HTTP server exposing /metrics on addr like Serve,
+// behind the credentials of options and over TLS when a certificate is set
+func (m *JobMetrics) ServeWith(addr string, options ServeOptions) (*http.Server, string, error) {
+	var tlsConfig *tls.Config
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", err
+	}
+	bound := listener.Addr().String()
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", options.Auth.Handler(m.Registry.Handler()))
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second, TLSConfig: tlsConfig}
+
+	go server.Serve(listener)
+	return server, bound, nil
+}
@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryExposition(t *testing.T) {
+	r := NewRegistry()
+	jobs := r.NewCounter("jobs_total", "Jobs.", "status")
+	r.NewCounter("saved_bytes_total", "Saved.", "")
+	queue := r.NewGauge("queue_depth", "Queue.")
+	duration := r.NewHistogram("duration_seconds", "Duration.", []float64{60, 10})
+
+	jobs.Inc("success")
+	jobs.Inc("success")
+	jobs.Inc("failed")
+	queue.Set(3)
+	duration.Observe(5)
+	duration.Observe(30)
+	duration.Observe(90.5)
+
+	var buf bytes.Buffer
+	r.Write(&buf)
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE jobs_total counter\n")
+	assert.Contains(t, out, "jobs_total{status=\"failed\"} 1\njobs_total{status=\"success\"} 2\n")
+	assert.Contains(t, out, "saved_bytes_total 0\n")
+	assert.Contains(t, out, "queue_depth 3\n")
+	assert.Contains(t, out, "duration_seconds_bucket{le=\"10\"} 1\n")
+	assert.Contains(t, out, "duration_seconds_bucket{le=\"60\"} 2\n")
+	assert.Contains(t, out, "duration_seconds_bucket{le=\"+Inf\"} 3\n")
+	assert.Contains(t, out, "duration_seconds_sum 125.5\n")
+	assert.Contains(t, out, "duration_seconds_count 3\n")
+}
+
+func TestJobMetricsServe(t *testing.T) {
+	m := NewJobMetrics()
+	m.JobSucceeded(1000, 600, 45*time.Second)
+	m.JobFailed()
+
+	server, addr, err := m.Serve("127.0.0.1:0")
+	assert.NoError(t, err)
+	defer server.Close()
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Contains(t, string(body), "compressvideo_bytes_saved_total 600\n")
+	assert.Contains(t, string(body), "compressvideo_failures_total 1\n")
+	assert.Contains(t, string(body), "compressvideo_jobs_processed_total{status=\"success\"} 1\n")
+}
+
+func TestJobMetricsServeAuth(t *testing.T) {
+	m := NewJobMetrics()
+	server, addr, err := m.ServeWith("127.0.0.1:0", ServeOptions{Auth: Auth{Token: "s3cret", User: "prometheus", Password: "scrape"}})
+	assert.NoError(t, err)
+	defer server.Close()
+
+	get := func(setup func(*http.Request)) int {
+		req, _ := http.NewRequest(http.MethodGet, "http://"+addr+"/metrics", nil)
+		setup(req)
+		resp, err := http.DefaultClient.Do(req)
+		if !assert.NoError(t, err) {
+			return 0
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get(func(*http.Request) {}))
+	assert.Equal(t, http.StatusUnauthorized, get(func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") }))
+	assert.Equal(t, http.StatusUnauthorized, get(func(r *http.Request) { r.SetBasicAuth("prometheus", "wrong") }))
+	assert.Equal(t, http.StatusOK, get(func(r *http.Request) { r.Header.Set("Authorization", "Bearer s3cret") }))
+	assert.Equal(t, http.StatusOK, get(func(r *http.Request) { r.SetBasicAuth("prometheus", "scrape") }))
+}
+
+func TestJobMetricsServeTLS(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	assert.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	assert.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	m := NewJobMetrics()
+	_, _, err = m.ServeWith("127.0.0.1:0", ServeOptions{CertFile: certFile, KeyFile: filepath.Join(dir, "missing.pem")})
+	assert.Error(t, err)
+
+	server, addr, err := m.ServeWith("127.0.0.1:0", ServeOptions{CertFile: certFile, KeyFile: keyFile})
+	assert.NoError(t, err)
+	defer server.Close()
+
+	cert, _ := x509.ParseCertificate(der)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	resp, err := client.Get("https://" + addr + "/metrics")
+	assert.NoError(t, err)
+	if err == nil {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
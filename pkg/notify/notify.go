@@ -0,0 +1,217 @@
+// Package notify sends completion notifications to webhooks and the desktop
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Event names sent in generic webhook payloads
+const (
+	EventFileCompleted  = "file_completed"
+	EventBatchCompleted = "batch_completed"
+)
+
+// ResultPayload is the JSON form of a CompressionResult
+type ResultPayload struct {
+	InputFile         string            `json:"input_file"`
+	OutputFile        string            `json:"output_file"`
+	OriginalSize      int64             `json:"original_size"`
+	CompressedSize    int64             `json:"compressed_size"`
+	SavedSpaceBytes   int64             `json:"saved_bytes"`
+	SavedSpacePercent float64           `json:"saved_percent"`
+	CompressionRatio  float64           `json:"compression_ratio"`
+	ProcessingTime    float64           `json:"processing_time_seconds"`
+	VMAFScore         float64           `json:"vmaf_score,omitempty"`
+	Settings          map[string]string `json:"settings,omitempty"`
+	Error             string            `json:"error,omitempty"`
+}
+
+// BatchSummary describes a finished directory run
+type BatchSummary struct {
+	Directory  string          `json:"directory"`
+	Processed  int             `json:"processed"`
+	Failed     int             `json:"failed"`
+	SavedBytes int64           `json:"saved_bytes"`
+	Duration   float64         `json:"duration_seconds"`
+	Results    []ResultPayload `json:"results,omitempty"`
+}
+
+// webhookPayload is posted to generic webhooks
+type webhookPayload struct {
+	Event   string         `json:"event"`
+	Message string         `json:"message"`
+	Tool    string         `json:"tool"`
+	Version string         `json:"version"`
+	Result  *ResultPayload `json:"result,omitempty"`
+	Batch   *BatchSummary  `json:"batch,omitempty"`
+}
+
+// Notifier posts results to a webhook and/or shows desktop notifications
+type Notifier struct {
+	URL     string
+	Desktop bool
+	Logger  *util.Logger
+	Client  *http.Client
+}
+
+// NewNotifier creates a notifier; it does nothing when url is empty and desktop is false
+func NewNotifier(url string, desktop bool, logger *util.Logger) *Notifier {
+	return &Notifier{
+		URL:     url,
+		Desktop: desktop,
+		Logger:  logger,
+		Client:  &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Enabled reports whether any notification channel is configured
+func (n *Notifier) Enabled() bool {
+	return n != nil && (n.URL != "" || n.Desktop)
+}
+
+// NewResultPayload converts a compression result for serialization
+func NewResultPayload(result *compressor.CompressionResult) ResultPayload {
+	payload := ResultPayload{
+		InputFile:         result.InputFile,
+		OutputFile:        result.OutputFile,
+		OriginalSize:      result.OriginalSize,
+		CompressedSize:    result.CompressedSize,
+		SavedSpaceBytes:   result.SavedSpaceBytes,
+		SavedSpacePercent: result.SavedSpacePercent,
+		CompressionRatio:  result.CompressionRatio,
+		ProcessingTime:    result.ProcessingTime.Seconds(),
+		VMAFScore:         result.VMAFScore,
+		Settings:          result.Settings,
+	}
+	if result.Error != nil {
+		payload.Error = result.Error.Error()
+	}
+	return payload
+}
+
+// FileCompleted notifies that a single file finished
+func (n *Notifier) FileCompleted(result *compressor.CompressionResult) {
+	if !n.Enabled() || result == nil {
+		return
+	}
+
+	payload := NewResultPayload(result)
+	message := fmt.Sprintf("Compressed %s: %.1f%% smaller (%s)",
+		filepath.Base(result.InputFile), result.SavedSpacePercent, result.ProcessingTime.Round(time.Second))
+	if result.Error != nil {
+		message = fmt.Sprintf("Compression of %s failed: %v", filepath.Base(result.InputFile), result.Error)
+	}
+
+	n.send(webhookPayload{Event: EventFileCompleted, Message: message, Result: &payload})
+}
+
+// BatchCompleted notifies that a directory run finished
+func (n *Notifier) BatchCompleted(summary BatchSummary) {
+	if !n.Enabled() {
+		return
+	}
+
+	message := fmt.Sprintf("Batch %s finished: %d compressed, %d failed, %.1f MB saved",
+		filepath.Base(summary.Directory), summary.Processed, summary.Failed, float64(summary.SavedBytes)/(1024*1024))
+
+	n.send(webhookPayload{Event: EventBatchCompleted, Message: message, Batch: &summary})
+}
+
+// send delivers a payload on every configured channel, logging failures
+func (n *Notifier) send(payload webhookPayload) {
+	payload.Tool = util.AppName
+	payload.Version = util.Version
+
+	if n.URL != "" {
+		if err := n.postWebhook(payload); err != nil {
+			n.Logger.Warning("Failed to send webhook notification: %v", err)
+		}
+	}
+	if n.Desktop {
+		if err := desktopNotify(util.AppName, payload.Message); err != nil {
+			n.Logger.Debug("Desktop notification failed: %v", err)
+		}
+	}
+}
+
+// postWebhook posts the payload, adapting it to Slack and Discord webhooks
+func (n *Notifier) postWebhook(payload webhookPayload) error {
+	body, err := json.Marshal(webhookBody(n.URL, payload))
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.Client.Post(n.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// webhookBody returns the request body for the webhook at rawURL. Slack and
+// Discord only accept their own message shapes; anything else gets the full payload.
+func webhookBody(rawURL string, payload webhookPayload) interface{} {
+	host := ""
+	if parsed, err := url.Parse(rawURL); err == nil {
+		host = strings.ToLower(parsed.Host)
+	}
+
+	switch {
+	case strings.HasSuffix(host, "hooks.slack.com"):
+		return map[string]string{"text": payload.Message}
+	case (strings.HasSuffix(host, "discord.com") || strings.HasSuffix(host, "discordapp.com")) &&
+		strings.Contains(rawURL, "/api/webhooks/"):
+		return map[string]string{"content": payload.Message}
+	default:
+		return payload
+	}
+}
+
+// desktopNotify shows a native desktop notification
+func desktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch util.GetCurrentOS() {
+	case util.Linux:
+		cmd = exec.Command("notify-send", title, message)
+	case util.MacOS:
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case util.Windows:
+		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; `+
+			`$n = New-Object System.Windows.Forms.NotifyIcon; `+
+			`$n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; `+
+			`$n.ShowBalloonTip(10000, %s, %s, 'Info'); Start-Sleep -Seconds 5; $n.Dispose()`,
+			powerShellString(title), powerShellString(message))
+		cmd = exec.Command("powershell", "-NoProfile", "-Command", script)
+	default:
+		return fmt.Errorf("desktop notifications are not supported on this system")
+	}
+	return cmd.Run()
+}
+
+// appleScriptString quotes s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// powerShellString quotes s as a single-quoted PowerShell string literal
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
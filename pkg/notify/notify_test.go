@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileCompletedPostsResult(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(server.URL, false, util.NewLogger(false))
+	n.FileCompleted(&compressor.CompressionResult{
+		InputFile:         "/videos/talk.mp4",
+		SavedSpacePercent: 42.5,
+		ProcessingTime:    90 * time.Second,
+		Settings:          map[string]string{"crf": "24"},
+	})
+
+	assert.Equal(t, EventFileCompleted, received.Event)
+	assert.Equal(t, "/videos/talk.mp4", received.Result.InputFile)
+	assert.Equal(t, 90.0, received.Result.ProcessingTime)
+	assert.Equal(t, "24", received.Result.Settings["crf"])
+	assert.Contains(t, received.Message, "talk.mp4")
+}
+
+func TestWebhookBody(t *testing.T) {
+	payload := webhookPayload{Event: EventFileCompleted, Message: "done"}
+
+	slack := webhookBody("https://hooks.slack.com/services/T/B/X", payload)
+	assert.Equal(t, map[string]string{"text": "done"}, slack)
+
+	discord := webhookBody("https://discord.com/api/webhooks/1/abc", payload)
+	assert.Equal(t, map[string]string{"content": "done"}, discord)
+
+	generic := webhookBody("https://example.com/hook", payload)
+	assert.Equal(t, payload, generic)
+}
+
+func TestResultPayloadError(t *testing.T) {
+	payload := NewResultPayload(&compressor.CompressionResult{Error: errors.New("boom")})
+	assert.Equal(t, "boom", payload.Error)
+}
+
+func TestNotifierDisabled(t *testing.T) {
+	assert.False(t, NewNotifier("", false, nil).Enabled())
+	var n *Notifier
+	assert.False(t, n.Enabled())
+}
@@ -0,0 +1,42 @@
+package reporter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// histogramWidth is the width in characters of the longest histogram bar
+const histogramWidth = 30
+
+// histogramBar returns the bar of a histogram range: its share of the
+// seconds of the busiest range, histogramWidth wide for that one
+func histogramBar(bucket analyzer.BitrateBucket, stats *analyzer.BitrateStats) string {
+	return strings.Repeat("█", barLength(bucket, stats, histogramWidth))
+}
+
+// barLength returns how long the bar of bucket is when the busiest range is
+// width long. Ranges with any seconds get at least 1.
+func barLength(bucket analyzer.BitrateBucket, stats *analyzer.BitrateStats, width int) int {
+	most := 0
+	for _, b := range stats.Histogram {
+		if b.Seconds > most {
+			most = b.Seconds
+		}
+	}
+	if most == 0 || bucket.Seconds == 0 {
+		return 0
+	}
+	length := bucket.Seconds * width / most
+	if length == 0 {
+		length = 1
+	}
+	return length
+}
+
+// bitrateRange formats the range of a histogram bucket, e.g. "2.10 Mbps - 3.40 Mbps"
+func bitrateRange(bucket analyzer.BitrateBucket) string {
+	return fmt.Sprintf("%s - %s", util.FormatBitrate(bucket.Low), util.FormatBitrate(bucket.High))
+}
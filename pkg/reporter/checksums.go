@@ -0,0 +1,113 @@
+package reporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// FileChecksum identifies a file by its SHA-256 hash and size
+type FileChecksum struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// ProvenanceManifest records how an output file was produced so archives can
+// be audited and duplicates detected later
+type ProvenanceManifest struct {
+	Source        FileChecksum      `json:"source"`
+	Output        FileChecksum      `json:"output"`
+	Settings      map[string]string `json:"settings"`
+	Tool          string            `json:"tool"`
+	ToolVersion   string            `json:"tool_version"`
+	FFmpegVersion string            `json:"ffmpeg_version,omitempty"`
+	FFmpegBuild   *util.FFmpegBuild `json:"ffmpeg_build,omitempty"`
+	StartedAt     time.Time         `json:"started_at"`
+	CompletedAt   time.Time         `json:"completed_at"`
+}
+
+// WriteChecksums writes "<output>.sha256" in sha256sum format and a
+// "<output>.provenance.json" manifest next to the output file. It returns the
+// path of the manifest.
+func (rg *ReportGenerator) WriteChecksums(report *Report) (string, error) {
+	source, err := checksumFile(report.InputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash source file: %w", err)
+	}
+	output, err := checksumFile(report.OutputFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash output file: %w", err)
+	}
+
+	manifest := ProvenanceManifest{
+		Source:      source,
+		Output:      output,
+		Tool:        util.AppName,
+		ToolVersion: util.Version,
+		StartedAt:   report.StartTime,
+		CompletedAt: report.CompletionTime,
+	}
+	if report.Result != nil {
+		manifest.Settings = report.Result.Settings
+	}
+	// Only a real installation has a version and build to record
+	if runner, ok := rg.runner().(*ffmpeg.ExecRunner); ok {
+		if info, err := runner.Info(); err == nil {
+			manifest.FFmpegVersion = info.Version
+			manifest.FFmpegBuild = info.Build
+		}
+	}
+
+	// sha256sum-compatible line so the output can be checked with "sha256sum -c"
+	sumPath := report.OutputFile + ".sha256"
+	sumLine := fmt.Sprintf("%s  %s\n", output.SHA256, filepath.Base(report.OutputFile))
+	if err := os.WriteFile(sumPath, []byte(sumLine), 0644); err != nil {
+		return "", fmt.Errorf("failed to write checksum file: %w", err)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode provenance manifest: %w", err)
+	}
+	manifestPath := report.OutputFile + ".provenance.json"
+	if err := os.WriteFile(manifestPath, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write provenance manifest: %w", err)
+	}
+
+	return manifestPath, nil
+}
+
+// checksumFile computes the SHA-256 hash and size of a file
+func checksumFile(path string) (FileChecksum, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileChecksum{}, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return FileChecksum{}, err
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	return FileChecksum{
+		Path:   absPath,
+		SHA256: hex.EncodeToString(hash.Sum(nil)),
+		Size:   size,
+	}, nil
+}
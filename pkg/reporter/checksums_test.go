@@ -0,0 +1,52 @@
+package reporter
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteChecksums(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.mp4")
+	output := filepath.Join(dir, "input-compressed.mp4")
+	assert.NoError(t, os.WriteFile(input, []byte("source video"), 0644))
+	assert.NoError(t, os.WriteFile(output, []byte("hello"), 0644))
+
+	rg := NewReportGenerator(util.NewLogger(false), nil)
+	report := &Report{
+		InputFile:      input,
+		OutputFile:     output,
+		StartTime:      time.Now().Add(-time.Minute),
+		CompletionTime: time.Now(),
+		Result: &compressor.CompressionResult{
+			Settings: map[string]string{"codec": "libx264", "crf": "23"},
+		},
+	}
+
+	manifestPath, err := rg.WriteChecksums(report)
+	assert.NoError(t, err)
+	assert.Equal(t, output+".provenance.json", manifestPath)
+
+	// SHA-256 of "hello"
+	const helloSum = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+	sumLine, err := os.ReadFile(output + ".sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, helloSum+"  input-compressed.mp4\n", string(sumLine))
+
+	data, err := os.ReadFile(manifestPath)
+	assert.NoError(t, err)
+	var manifest ProvenanceManifest
+	assert.NoError(t, json.Unmarshal(data, &manifest))
+	assert.Equal(t, helloSum, manifest.Output.SHA256)
+	assert.Equal(t, int64(5), manifest.Output.Size)
+	assert.Equal(t, int64(len("source video")), manifest.Source.Size)
+	assert.Equal(t, "23", manifest.Settings["crf"])
+	assert.Equal(t, util.Version, manifest.ToolVersion)
+}
@@ -0,0 +1,333 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+// Report file formats
+const (
+	FormatText     = "txt"
+	FormatJSON     = "json"
+	FormatMarkdown = "md"
+	FormatHTML     = "html"
+)
+
+// ValidReportFormat reports whether format is one of the supported report formats
+func ValidReportFormat(format string) bool {
+	switch format {
+	case FormatText, FormatJSON, FormatMarkdown, FormatHTML:
+		return true
+	}
+	return false
+}
+
+// SaveReport writes the report in the given format. When path is empty the
+// report is saved next to the output file as "<name>_report.<format>"; when
+// path is an existing directory the default file name is used inside it.
+func (rg *ReportGenerator) SaveReport(report *Report, format, path string) (string, error) {
+	if format == "" {
+		format = FormatText
+	}
+	if !ValidReportFormat(format) {
+		return "", fmt.Errorf("unsupported report format: %s (use txt, json, md or html)", format)
+	}
+
+	reportPath := reportFilePath(report.OutputFile, format, path)
+
+	// Open file for writing
+	file, err := os.Create(reportPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	switch format {
+	case FormatJSON:
+		err = writeJSONReport(file, report)
+	case FormatMarkdown:
+		err = writeMarkdownReport(file, report)
+	case FormatHTML:
+		err = writeHTMLReport(file, report, rg.captureFrames(report))
+	default:
+		err = writeTextReport(file, report)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return reportPath, nil
+}
+
+// reportFilePath resolves where a report is written
+func reportFilePath(outputFile, format, path string) string {
+	baseName := filepath.Base(outputFile)
+	reportName := strings.TrimSuffix(baseName, filepath.Ext(baseName)) + "_report." + format
+
+	if path == "" {
+		return filepath.Join(filepath.Dir(outputFile), reportName)
+	}
+	if stat, err := os.Stat(path); err == nil && stat.IsDir() {
+		return filepath.Join(path, reportName)
+	}
+	return path
+}
+
+// jsonReport is the serialized form of a Report
+type jsonReport struct {
+	InputFile        string              `json:"input_file"`
+	OutputFile       string              `json:"output_file"`
+	StartTime        time.Time           `json:"start_time"`
+	CompletionTime   time.Time           `json:"completion_time"`
+	OriginalVideo    *ffmpeg.VideoFile   `json:"original_video"`
+	Analysis         jsonAnalysis        `json:"analysis"`
+	Result           jsonResult          `json:"result"`
+	QualityEstimate  string              `json:"quality_estimate"`
+	PerformanceScore float64             `json:"performance_score"`
+	TimeSaved        float64             `json:"transfer_time_saved_seconds"`
+	StorageSaved     float64             `json:"storage_saved_mb"`
+	CompressionTips  []string            `json:"compression_tips"`
+	Decisions        []analyzer.Decision `json:"decisions,omitempty"`
+	FrameDiffs       []FrameDiff         `json:"frame_diffs,omitempty"`
+}
+
+// jsonAnalysis holds the content analysis with enums rendered as strings
+type jsonAnalysis struct {
+	ContentType          string                  `json:"content_type"`
+	MotionComplexity     string                  `json:"motion_complexity"`
+	SceneChanges         int                     `json:"scene_changes"`
+	FrameComplexity      float64                 `json:"frame_complexity"`
+	SpatialComplexity    float64                 `json:"spatial_complexity"`
+	CompressionPotential int                     `json:"compression_potential"`
+	RecommendedCodec     string                  `json:"recommended_codec"`
+	OptimalBitrate       int64                   `json:"optimal_bitrate"`
+	IsHDContent          bool                    `json:"is_hd"`
+	IsUHDContent         bool                    `json:"is_uhd"`
+	Bitrate              *analyzer.BitrateStats  `json:"bitrate,omitempty"`
+	Params               analyzer.AnalysisParams `json:"analysis_params"`
+}
+
+// jsonResult holds the compression result with durations in seconds
+type jsonResult struct {
+	OriginalSize        int64             `json:"original_size"`
+	CompressedSize      int64             `json:"compressed_size"`
+	SavedSpaceBytes     int64             `json:"saved_bytes"`
+	SavedSpacePercent   float64           `json:"saved_percent"`
+	CompressionRatio    float64           `json:"compression_ratio"`
+	ProcessingTime      float64           `json:"processing_time_seconds"`
+	CPUTime             float64           `json:"cpu_seconds,omitempty"`
+	EnergyWh            float64           `json:"energy_wh,omitempty"`
+	AverageFrameQuality float64           `json:"average_frame_quality"`
+	VMAFScore           float64           `json:"vmaf_score,omitempty"`
+	Settings            map[string]string `json:"settings"`
+	Overridden          []string          `json:"overridden,omitempty"`
+	HardwareFallback    *jsonFallback     `json:"hardware_fallback,omitempty"`
+	Recovery            *jsonRecovery     `json:"recovery,omitempty"`
+	Error               string            `json:"error,omitempty"`
+}
+
+// jsonFallback holds a failed hardware encode that was redone in software
+type jsonFallback struct {
+	Encoder  string `json:"encoder"`
+	Reason   string `json:"reason"`
+	Software string `json:"software_encoder"`
+}
+
+// jsonRecovery holds how much of a damaged input was salvaged
+type jsonRecovery struct {
+	SourceDuration float64 `json:"source_duration_seconds"`
+	OutputDuration float64 `json:"output_duration_seconds"`
+	Percent        float64 `json:"recovered_percent,omitempty"`
+}
+
+// writeJSONReport writes the report as an indented JSON document
+func writeJSONReport(w io.Writer, report *Report) error {
+	out := jsonReport{
+		InputFile:        report.InputFile,
+		OutputFile:       report.OutputFile,
+		StartTime:        report.StartTime,
+		CompletionTime:   report.CompletionTime,
+		OriginalVideo:    report.OriginalVideo,
+		QualityEstimate:  report.QualityEstimate,
+		PerformanceScore: report.PerformanceScore,
+		TimeSaved:        report.TimeSaved,
+		StorageSaved:     report.StorageSaved,
+		CompressionTips:  report.CompressionTips,
+		Decisions:        report.Decisions,
+		FrameDiffs:       report.FrameDiffs,
+	}
+
+	if a := report.Analysis; a != nil {
+		out.Analysis = jsonAnalysis{
+			ContentType:          a.ContentType.String(),
+			MotionComplexity:     a.MotionComplexity.String(),
+			SceneChanges:         a.SceneChanges,
+			FrameComplexity:      a.FrameComplexity,
+			SpatialComplexity:    a.SpatialComplexity,
+			CompressionPotential: a.CompressionPotential,
+			RecommendedCodec:     a.RecommendedCodec,
+			OptimalBitrate:       a.OptimalBitrate,
+			IsHDContent:          a.IsHDContent,
+			IsUHDContent:         a.IsUHDContent,
+			Bitrate:              a.Bitrate,
+			Params:               a.AnalyzedWith(),
+		}
+	}
+
+	if r := report.Result; r != nil {
+		out.Result = jsonResult{
+			OriginalSize:        r.OriginalSize,
+			CompressedSize:      r.CompressedSize,
+			SavedSpaceBytes:     r.SavedSpaceBytes,
+			SavedSpacePercent:   r.SavedSpacePercent,
+			CompressionRatio:    r.CompressionRatio,
+			ProcessingTime:      r.ProcessingTime.Seconds(),
+			CPUTime:             r.CPUTime.Seconds(),
+			EnergyWh:            r.EnergyWh,
+			AverageFrameQuality: r.AverageFrameQuality,
+			VMAFScore:           r.VMAFScore,
+			Settings:            r.Settings,
+			Overridden:          r.Overridden,
+		}
+		if f := r.HardwareFallback; f != nil {
+			out.Result.HardwareFallback = &jsonFallback{Encoder: f.Encoder, Reason: f.Reason, Software: f.Software}
+		}
+		if rec := r.Recovery; rec != nil {
+			out.Result.Recovery = &jsonRecovery{SourceDuration: rec.SourceDuration, OutputDuration: rec.OutputDuration, Percent: rec.Percent()}
+		}
+		if r.Error != nil {
+			out.Result.Error = r.Error.Error()
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
+
+// writeMarkdownReport writes the report as Markdown tables
+func writeMarkdownReport(w io.Writer, report *Report) error {
+	video := report.OriginalVideo
+	analysis := report.Analysis
+	result := report.Result
+
+	fmt.Fprintf(w, "# Compression Report: %s\n\n", filepath.Base(report.InputFile))
+
+	fmt.Fprintf(w, "## Files\n\n")
+	fmt.Fprintf(w, "| | Path |\n|---|---|\n")
+	fmt.Fprintf(w, "| Input | `%s` |\n", report.InputFile)
+	fmt.Fprintf(w, "| Output | `%s` |\n\n", report.OutputFile)
+
+	fmt.Fprintf(w, "## Video Details\n\n")
+	fmt.Fprintf(w, "| Property | Value |\n|---|---|\n")
+	fmt.Fprintf(w, "| Resolution | %dx%d |\n", video.VideoInfo.Width, video.VideoInfo.Height)
+	fmt.Fprintf(w, "| Duration | %.2f s |\n", video.Duration)
+	fmt.Fprintf(w, "| Codec | %s |\n", video.VideoInfo.Codec)
+	fmt.Fprintf(w, "| Frame Rate | %.2f fps |\n\n", video.VideoInfo.FPS)
+
+	fmt.Fprintf(w, "## Content Analysis\n\n")
+	fmt.Fprintf(w, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(w, "| Content Type | %s |\n", analysis.ContentType)
+	fmt.Fprintf(w, "| Motion Complexity | %s |\n", analysis.MotionComplexity)
+	fmt.Fprintf(w, "| Scene Changes | %d |\n", analysis.SceneChanges)
+	fmt.Fprintf(w, "| Frame Complexity | %.2f |\n", analysis.FrameComplexity)
+	fmt.Fprintf(w, "| Spatial Complexity | %.2f |\n", analysis.SpatialComplexity)
+	fmt.Fprintf(w, "| Recommended Codec | %s |\n", analysis.RecommendedCodec)
+	fmt.Fprintf(w, "| Optimal Bitrate | %d kbps |\n", analysis.OptimalBitrate/1000)
+	fmt.Fprintf(w, "| Compression Potential | %d%% |\n\n", analysis.CompressionPotential)
+
+	if stats := analysis.Bitrate; stats != nil {
+		fmt.Fprintf(w, "## Source Bitrate\n\n")
+		fmt.Fprintf(w, "| Statistic | Value |\n|---|---|\n")
+		fmt.Fprintf(w, "| Seconds Measured | %d |\n", stats.Seconds)
+		for _, row := range []struct {
+			name  string
+			value int64
+		}{{"Mean", stats.Mean}, {"Minimum", stats.Min}, {"10th Percentile", stats.P10}, {"Median", stats.P50},
+			{"90th Percentile", stats.P90}, {"95th Percentile", stats.P95}, {"99th Percentile", stats.P99}, {"Peak", stats.Max}} {
+			fmt.Fprintf(w, "| %s | %s |\n", row.name, util.FormatBitrate(row.value))
+		}
+		fmt.Fprintf(w, "\n| Bitrate | Seconds | |\n|---|---|---|\n")
+		for _, bucket := range stats.Histogram {
+			fmt.Fprintf(w, "| %s | %d | %s |\n", bitrateRange(bucket), bucket.Seconds, histogramBar(bucket, stats))
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "## Compression Results\n\n")
+	fmt.Fprintf(w, "| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(w, "| Original Size | %.2f MB |\n", float64(result.OriginalSize)/(1024*1024))
+	fmt.Fprintf(w, "| Compressed Size | %.2f MB |\n", float64(result.CompressedSize)/(1024*1024))
+	fmt.Fprintf(w, "| Space Saved | %.2f MB (%.1f%%) |\n", float64(result.SavedSpaceBytes)/(1024*1024), result.SavedSpacePercent)
+	fmt.Fprintf(w, "| Compression Ratio | %.2f:1 |\n", result.CompressionRatio)
+	fmt.Fprintf(w, "| Processing Time | %s |\n", result.ProcessingTime.Round(time.Second))
+	if result.CPUTime > 0 {
+		fmt.Fprintf(w, "| CPU Time | %s (about %.2f Wh) |\n", result.CPUTime.Round(time.Second), result.EnergyWh)
+	}
+	fmt.Fprintf(w, "| Quality Estimate | %s (%.1f/100) |\n", report.QualityEstimate, result.AverageFrameQuality)
+	if result.VMAFScore > 0 {
+		fmt.Fprintf(w, "| Predicted VMAF | %.2f |\n", result.VMAFScore)
+	}
+	if result.HardwareFallback != nil {
+		fmt.Fprintf(w, "| Hardware Fallback | %s |\n", result.HardwareFallback)
+	}
+	if result.Recovery != nil {
+		fmt.Fprintf(w, "| Recovered | %s |\n", result.Recovery)
+	}
+	fmt.Fprintf(w, "| Overall Score | %.1f/100 |\n\n", report.PerformanceScore)
+
+	if len(report.FrameDiffs) > 0 {
+		fmt.Fprintf(w, "## Frame Comparison\n\n")
+		fmt.Fprintf(w, "| Time | SSIM |\n|---|---|\n")
+		for _, diff := range report.FrameDiffs {
+			fmt.Fprintf(w, "| %.3f s | %.4f |\n", diff.Time, diff.SSIM)
+		}
+		fmt.Fprintf(w, "\n")
+	}
+
+	fmt.Fprintf(w, "## Encoding Settings\n\n")
+	fmt.Fprintf(w, "| Setting | Value |\n|---|---|\n")
+	for _, key := range sortedKeys(result.Settings) {
+		fmt.Fprintf(w, "| %s | `%s` |\n", key, result.Settings[key])
+	}
+	if len(result.Overridden) > 0 {
+		fmt.Fprintf(w, "\nOverridden on the command line: `%s`\n", strings.Join(result.Overridden, "`, `"))
+	}
+
+	if len(report.CompressionTips) > 0 {
+		fmt.Fprintf(w, "\n## Optimization Tips\n\n")
+		for _, tip := range report.CompressionTips {
+			fmt.Fprintf(w, "- %s\n", tip)
+		}
+	}
+
+	if len(report.Decisions) > 0 {
+		fmt.Fprintf(w, "\n## Decisions\n\n")
+		for _, decision := range report.Decisions {
+			fmt.Fprintf(w, "- %s\n", decision)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n_Report generated on %s_\n", time.Now().Format("2006-01-02 15:04:05"))
+	return err
+}
+
+// sortedKeys returns the keys of a settings map in alphabetical order
+func sortedKeys(settings map[string]string) []string {
+	keys := make([]string, 0, len(settings))
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,202 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/compressor"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/stretchr/testify/assert"
+)
+
+func sampleReport(outputFile string) *Report {
+	video := &ffmpeg.VideoFile{
+		Duration:  120,
+		VideoInfo: ffmpeg.VideoStreamInfo{Codec: "h264", Width: 1920, Height: 1080, FPS: 30},
+	}
+	return &Report{
+		InputFile:     "input.mp4",
+		OutputFile:    outputFile,
+		OriginalVideo: video,
+		Analysis: &analyzer.VideoAnalysis{
+			VideoFile:        video,
+			ContentType:      analyzer.ContentTypeScreencast,
+			MotionComplexity: analyzer.MotionComplexityLow,
+		},
+		Result: &compressor.CompressionResult{
+			OriginalSize:   100 * 1024 * 1024,
+			CompressedSize: 40 * 1024 * 1024,
+			ProcessingTime: 90 * time.Second,
+			Settings:       map[string]string{"codec": "libx264", "crf": "28"},
+		},
+		QualityEstimate: "Good",
+	}
+}
+
+func TestWriteJSONReport(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeJSONReport(&buf, sampleReport("out.mp4")))
+
+	var decoded map[string]interface{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+
+	analysis := decoded["analysis"].(map[string]interface{})
+	assert.Equal(t, "Screencast", analysis["content_type"])
+	assert.Equal(t, "Low", analysis["motion_complexity"])
+	_, hasBitrate := analysis["bitrate"]
+	assert.False(t, hasBitrate)
+
+	result := decoded["result"].(map[string]interface{})
+	assert.Equal(t, 90.0, result["processing_time_seconds"])
+	assert.Equal(t, "28", result["settings"].(map[string]interface{})["crf"])
+	_, hasError := result["error"]
+	assert.False(t, hasError)
+	_, hasFallback := result["hardware_fallback"]
+	assert.False(t, hasFallback)
+	_, hasCPUTime := result["cpu_seconds"]
+	assert.False(t, hasCPUTime)
+	_, hasDecisions := decoded["decisions"]
+	assert.False(t, hasDecisions)
+
+	// A hardware encode redone in software is recorded
+	report := sampleReport("out.mp4")
+	report.Result.HardwareFallback = &compressor.HardwareFallback{
+		Encoder: "h264_nvenc", Reason: compressor.HWFailureSessionLimit, Software: "libx264"}
+	buf.Reset()
+	assert.NoError(t, writeJSONReport(&buf, report))
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	fallback := decoded["result"].(map[string]interface{})["hardware_fallback"].(map[string]interface{})
+	assert.Equal(t, "h264_nvenc", fallback["encoder"])
+	assert.Equal(t, "encoder session limit reached", fallback["reason"])
+	assert.Equal(t, "libx264", fallback["software_encoder"])
+
+	// So is how much of a damaged input was salvaged
+	report.Result.Recovery = &compressor.Recovery{SourceDuration: 600, OutputDuration: 450}
+	buf.Reset()
+	assert.NoError(t, writeJSONReport(&buf, report))
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	recovery := decoded["result"].(map[string]interface{})["recovery"].(map[string]interface{})
+	assert.Equal(t, 450.0, recovery["output_duration_seconds"])
+	assert.Equal(t, 75.0, recovery["recovered_percent"])
+
+	// The CPU time and its energy estimate are recorded when measured
+	report.Result.CPUTime = 6 * time.Minute
+	report.Result.EnergyWh = 1
+	buf.Reset()
+	assert.NoError(t, writeJSONReport(&buf, report))
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	result = decoded["result"].(map[string]interface{})
+	assert.Equal(t, 360.0, result["cpu_seconds"])
+	assert.Equal(t, 1.0, result["energy_wh"])
+
+	// Settings given on the command line are listed
+	report.Result.Overridden = []string{"crf", "tune"}
+	buf.Reset()
+	assert.NoError(t, writeJSONReport(&buf, report))
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, []interface{}{"crf", "tune"}, decoded["result"].(map[string]interface{})["overridden"])
+}
+
+func TestWriteMarkdownReport(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, writeMarkdownReport(&buf, sampleReport("out.mp4")))
+
+	md := buf.String()
+	assert.True(t, strings.HasPrefix(md, "# Compression Report: input.mp4"))
+	assert.Contains(t, md, "| Content Type | Screencast |")
+	assert.Contains(t, md, "| crf | `28` |")
+	// Settings are listed in a stable order
+	assert.True(t, strings.Index(md, "| codec |") < strings.Index(md, "| crf |"))
+	assert.NotContains(t, md, "## Decisions")
+
+	// Decisions recorded with --explain are listed
+	report := sampleReport("out.mp4")
+	report.Decisions = []analyzer.Decision{{Name: "codec", Value: "libx264", Reason: "content=Screencast", Override: "--codec"}}
+	buf.Reset()
+	assert.NoError(t, writeMarkdownReport(&buf, report))
+	assert.Contains(t, buf.String(), "## Decisions\n\n- codec=libx264 because content=Screencast (override with --codec)\n")
+
+	report.Result.Overridden = []string{"crf", "tune"}
+	buf.Reset()
+	assert.NoError(t, writeMarkdownReport(&buf, report))
+	assert.Contains(t, buf.String(), "Overridden on the command line: `crf`, `tune`\n")
+	assert.NotContains(t, buf.String(), "## Source Bitrate")
+
+	// The bitrate of the source is shown with its histogram
+	report.Analysis.Bitrate = analyzer.NewBitrateStats([]int64{1000000, 1000000, 1000000, 3000000})
+	buf.Reset()
+	assert.NoError(t, writeMarkdownReport(&buf, report))
+	md = buf.String()
+	assert.Contains(t, md, "## Source Bitrate\n\n")
+	assert.Contains(t, md, "| 95th Percentile | 3.00 Mbps |\n")
+	assert.Contains(t, md, "| 1.00 Mbps - 1.20 Mbps | 3 | "+strings.Repeat("█", 30)+" |\n")
+	assert.Contains(t, md, "| 2.80 Mbps - 3.00 Mbps | 1 | "+strings.Repeat("█", 10)+" |\n")
+}
+
+func TestSaveReportPaths(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "video-compressed.mp4")
+	rg := &ReportGenerator{}
+
+	path, err := rg.SaveReport(sampleReport(output), FormatJSON, "")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "video-compressed_report.json"), path)
+
+	// A directory report path keeps the default file name
+	reportDir := filepath.Join(dir, "reports")
+	assert.NoError(t, os.Mkdir(reportDir, 0755))
+	path, err = rg.SaveReport(sampleReport(output), FormatMarkdown, reportDir)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(reportDir, "video-compressed_report.md"), path)
+
+	// An explicit file path is used as-is
+	custom := filepath.Join(dir, "custom.txt")
+	path, err = rg.SaveReport(sampleReport(output), FormatText, custom)
+	assert.NoError(t, err)
+	assert.Equal(t, custom, path)
+
+	_, err = rg.SaveReport(sampleReport(output), "xml", "")
+	assert.Error(t, err)
+}
+
+func TestWriteHTMLReport(t *testing.T) {
+	frames := []frameComparison{
+		{Time: 24, Source: "data:image/jpeg;base64,AAAA", Output: "data:image/jpeg;base64,BBBB"},
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, writeHTMLReport(&buf, sampleReport("out.mp4"), frames))
+
+	html := buf.String()
+	assert.Contains(t, html, "<title>Compression Report: input.mp4</title>")
+	assert.Contains(t, html, `src="data:image/jpeg;base64,AAAA"`)
+	assert.Contains(t, html, `src="data:image/jpeg;base64,BBBB"`)
+	assert.Contains(t, html, "<td>crf</td><td><code>28</code></td>")
+	assert.Contains(t, html, "Screencast, Low motion")
+	assert.NotContains(t, html, "CPU Time")
+
+	report := sampleReport("out.mp4")
+	report.Result.CPUTime = 6 * time.Minute
+	report.Result.EnergyWh = 1
+	buf.Reset()
+	assert.NoError(t, writeHTMLReport(&buf, report, frames))
+	assert.Contains(t, buf.String(), "<tr><th>CPU Time</th><td>6m0s (about 1.00 Wh)</td></tr>")
+	assert.NotContains(t, buf.String(), "Source Bitrate")
+
+	report.Analysis.Bitrate = analyzer.NewBitrateStats([]int64{1000000, 1000000, 1000000, 3000000})
+	buf.Reset()
+	assert.NoError(t, writeHTMLReport(&buf, report, frames))
+	assert.Contains(t, buf.String(), "<h2>Source Bitrate</h2>")
+	assert.Contains(t, buf.String(), `<td>2.80 Mbps - 3.00 Mbps</td><td>1</td><td><div class="bar" style="width: 33%"></div></td>`)
+}
+
+func TestFrameTimestamps(t *testing.T) {
+	assert.Equal(t, []float64{20, 40, 60, 80}, frameTimestamps(100, 4))
+	assert.Nil(t, frameTimestamps(0, 4))
+}
@@ -0,0 +1,110 @@
+package reporter
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+)
+
+// frameDiffCount is the number of points where the source and output frames
+// are compared
+const frameDiffCount = 5
+
+// FrameDiff is the SSIM of the output frame against the source frame shown
+// at the same time. 1 is identical; below about 0.95 artifacts tend to show.
+type FrameDiff struct {
+	Time float64 `json:"time_seconds"`
+	SSIM float64 `json:"ssim"`
+}
+
+// String returns the SSIM and the time of the frames, as HH:MM:SS.mmm so it
+// can be given to a player or to ffmpeg -ss
+func (d FrameDiff) String() string {
+	millis := int64(math.Round(d.Time * 1000))
+	return fmt.Sprintf("SSIM %.4f at %02d:%02d:%02d.%03d", d.SSIM, millis/3600000, millis/60000%60, millis/1000%60, millis%1000)
+}
+
+// ssimRegex matches the overall SSIM FFmpeg's ssim filter prints on exit:
+// "SSIM Y:0.991 (20.4) U:0.995 (23.0) V:0.994 (22.7) All:0.992 (21.2)"
+var ssimRegex = regexp.MustCompile(`SSIM .*All:([0-9.]+)`)
+
+// DiffFrames compares the frames of the source and the output of a report
+// at evenly spaced times and returns their SSIM, worst first, so the report
+// can point at where to look for artifacts. Frames that fail to compare are
+// skipped. Outputs cropped from the source are not compared, as their
+// frames do not line up.
+func (rg *ReportGenerator) DiffFrames(report *Report) []FrameDiff {
+	if report.OriginalVideo == nil {
+		return nil
+	}
+	if report.Result != nil && ffmpeg.VideoFilter(report.Result.Settings, ffmpeg.FilterCrop) != "" {
+		rg.Logger.Debug("Not comparing frames of a cropped output")
+		return nil
+	}
+
+	runner := rg.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		rg.Logger.Debug("FFmpeg not available, frames will not be compared: %v", err)
+		return nil
+	}
+
+	info := report.OriginalVideo.VideoInfo
+	var diffs []FrameDiff
+	for _, t := range frameTimestamps(report.OriginalVideo.Duration, frameDiffCount) {
+		ssim, err := diffFrame(runner, ffmpegPath, report.InputFile, report.OutputFile, t, info.Width, info.Height)
+		if err != nil {
+			rg.Logger.Debug("Failed to compare frames at %.1fs: %v", t, err)
+			continue
+		}
+		diffs = append(diffs, FrameDiff{Time: t, SSIM: ssim})
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].SSIM < diffs[j].SSIM })
+	return diffs
+}
+
+// diffFrame returns the SSIM of the output frame at t against the source
+// frame at t, with the output scaled to the source's width and height
+func diffFrame(runner ffmpeg.Runner, ffmpegPath, source, output string, t float64, width, height int) (float64, error) {
+	seek := []string{"-ss", fmt.Sprintf("%.3f", t)}
+	args := append([]string{"-hide_banner", "-nostats"}, seek...)
+	args = append(args, "-i", ffmpeg.FileArg(output))
+	args = append(args, ffmpeg.RemoteInputArgs(source)...)
+	args = append(args, seek...)
+	args = append(args,
+		"-i", ffmpeg.FileArg(source),
+		"-lavfi", ssimFilter(width, height),
+		"-frames:v", "1",
+		"-an",
+		"-f", "null",
+		"-",
+	)
+	result, err := ffmpeg.CombinedOutput(runner, ffmpegPath, args)
+	if err != nil {
+		return 0, err
+	}
+	return parseSSIM(string(result))
+}
+
+// ssimFilter returns the filter graph comparing the output (input 0) with
+// the source (input 1) at the source's size
+func ssimFilter(width, height int) string {
+	scale := ""
+	if width > 0 && height > 0 {
+		scale = fmt.Sprintf("scale=%d:%d,", width, height)
+	}
+	return "[0:v]" + scale + "format=yuv420p,setpts=PTS-STARTPTS[output];[1:v]format=yuv420p,setpts=PTS-STARTPTS[source];[output][source]ssim"
+}
+
+// parseSSIM reads the overall SSIM from FFmpeg's output
+func parseSSIM(output string) (float64, error) {
+	match := ssimRegex.FindStringSubmatch(output)
+	if match == nil {
+		return 0, fmt.Errorf("SSIM not found in FFmpeg output")
+	}
+	return strconv.ParseFloat(match[1], 64)
+}
@@ -0,0 +1,65 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// ssimRunner stands in for FFmpeg: the ssim filter prints the score of the
+// frame at each time
+type ssimRunner struct {
+	scores map[string]string
+}
+
+func (r *ssimRunner) EncodePath() (string, error) { return "ffmpeg", nil }
+func (r *ssimRunner) ProbePath() (string, error)  { return "ffprobe", nil }
+
+func (r *ssimRunner) Run(ctx context.Context, path string, args []string, stdout, stderr io.Writer) error {
+	score, ok := r.scores[args[3]]
+	if !ok {
+		io.WriteString(stderr, "Error while decoding")
+		return nil
+	}
+	io.WriteString(stderr, "[Parsed_ssim_4 @ 0x1] SSIM Y:"+score+" (20.0) U:0.99 (21.0) V:0.99 (21.0) All:"+score+" (20.5)\n")
+	return nil
+}
+
+func TestDiffFrames(t *testing.T) {
+	report := sampleReport("out.mp4")
+	ff := ffmpeg.NewFFmpeg("input.mp4", "out.mp4", nil, util.NewLogger(false))
+	ff.Runner = &ssimRunner{scores: map[string]string{"20.000": "0.981", "40.000": "0.912", "60.000": "0.990", "100.000": "0.950"}}
+	rg := NewReportGenerator(util.NewLogger(false), ff)
+
+	// Frames that fail to compare are left out, the worst come first
+	assert.Equal(t, []FrameDiff{{Time: 40, SSIM: 0.912}, {Time: 100, SSIM: 0.95}, {Time: 20, SSIM: 0.981}, {Time: 60, SSIM: 0.99}},
+		rg.DiffFrames(report))
+
+	// Cropped outputs do not line up with the source
+	assert.NoError(t, ffmpeg.SetVideoFilter(report.Result.Settings, ffmpeg.FilterCrop, "crop=1920:800:0:140"))
+	assert.Nil(t, rg.DiffFrames(report))
+}
+
+func TestSSIMFilter(t *testing.T) {
+	assert.Equal(t, "[0:v]scale=1920:1080,format=yuv420p,setpts=PTS-STARTPTS[output];[1:v]format=yuv420p,setpts=PTS-STARTPTS[source];[output][source]ssim",
+		ssimFilter(1920, 1080))
+	assert.False(t, strings.Contains(ssimFilter(0, 0), "scale"))
+}
+
+func TestParseSSIM(t *testing.T) {
+	ssim, err := parseSSIM("frame=    1 fps=0.0\n[Parsed_ssim_4 @ 0x55] SSIM Y:0.968 (14.9) U:0.98 (17.1) V:0.979 (16.8) All:0.9723 (15.6)\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 0.9723, ssim)
+
+	_, err = parseSSIM("No such file or directory")
+	assert.Error(t, err)
+}
+
+func TestFrameDiffString(t *testing.T) {
+	assert.Equal(t, "SSIM 0.9120 at 01:02:03.500", FrameDiff{Time: 3723.5, SSIM: 0.912}.String())
+}
@@ -0,0 +1,224 @@
+package reporter
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/analyzer"
+	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/util"
+)
+
+const (
+	// htmlFrameCount is the number of before/after frames captured for the HTML report
+	htmlFrameCount = 4
+	// htmlFrameWidth is the width in pixels of each captured frame
+	htmlFrameWidth = 640
+)
+
+// frameComparison holds one source frame and the matching output frame
+type frameComparison struct {
+	Time   float64
+	Source template.URL
+	Output template.URL
+}
+
+// htmlReportData is passed to the HTML report template
+type htmlReportData struct {
+	Report      *Report
+	Title       string
+	Frames      []frameComparison
+	Settings    [][2]string
+	GeneratedAt string
+}
+
+// barPercent returns the width of the bar of bucket in the HTML histogram,
+// in percent of the widest
+func barPercent(bucket analyzer.BitrateBucket, stats *analyzer.BitrateStats) int {
+	return barLength(bucket, stats, 100)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"mb":           func(bytes int64) string { return fmt.Sprintf("%.2f MB", float64(bytes)/(1024*1024)) },
+	"seconds":      func(d time.Duration) string { return d.Round(time.Second).String() },
+	"bitrate":      util.FormatBitrate,
+	"bitrateRange": bitrateRange,
+	"barPercent":   barPercent,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, sans-serif; margin: 2em auto; max-width: 1400px; color: #222; }
+h1 { font-size: 1.6em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+td, th { border: 1px solid #ddd; padding: 6px 12px; text-align: left; }
+th { background: #f4f4f4; }
+.frames { display: grid; grid-template-columns: 1fr 1fr; gap: 12px; }
+.frames figure { margin: 0; }
+.frames img { width: 100%; border: 1px solid #ccc; }
+.frames figcaption { font-size: 0.85em; color: #555; }
+.bar { background: #4a90d9; height: 1em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{with .Report}}
+<h2>Files</h2>
+<table>
+<tr><th>Input</th><td>{{.InputFile}}</td></tr>
+<tr><th>Output</th><td>{{.OutputFile}}</td></tr>
+</table>
+<h2>Results</h2>
+<table>
+<tr><th>Original Size</th><td>{{mb .Result.OriginalSize}}</td></tr>
+<tr><th>Compressed Size</th><td>{{mb .Result.CompressedSize}}</td></tr>
+<tr><th>Space Saved</th><td>{{mb .Result.SavedSpaceBytes}} ({{printf "%.1f" .Result.SavedSpacePercent}}%)</td></tr>
+<tr><th>Compression Ratio</th><td>{{printf "%.2f" .Result.CompressionRatio}}:1</td></tr>
+<tr><th>Processing Time</th><td>{{seconds .Result.ProcessingTime}}</td></tr>
+{{if gt .Result.CPUTime 0}}<tr><th>CPU Time</th><td>{{seconds .Result.CPUTime}} (about {{printf "%.2f" .Result.EnergyWh}} Wh)</td></tr>{{end}}
+<tr><th>Quality Estimate</th><td>{{.QualityEstimate}} ({{printf "%.1f" .Result.AverageFrameQuality}}/100)</td></tr>
+{{if gt .Result.VMAFScore 0.0}}<tr><th>Predicted VMAF</th><td>{{printf "%.2f" .Result.VMAFScore}}</td></tr>{{end}}
+{{with .Result.HardwareFallback}}<tr><th>Hardware Fallback</th><td>{{.}}</td></tr>{{end}}
+{{with .Result.Recovery}}<tr><th>Recovered</th><td>{{.}}</td></tr>{{end}}
+<tr><th>Overall Score</th><td>{{printf "%.1f" .PerformanceScore}}/100</td></tr>
+</table>
+{{with .FrameDiffs}}
+<h2>Frame Comparison</h2>
+<table>
+<tr><th>Time</th><th>SSIM</th></tr>
+{{range .}}<tr><td>{{printf "%.3f" .Time}} s</td><td>{{printf "%.4f" .SSIM}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Video</h2>
+<table>
+<tr><th>Resolution</th><td>{{.OriginalVideo.VideoInfo.Width}}x{{.OriginalVideo.VideoInfo.Height}}</td></tr>
+<tr><th>Duration</th><td>{{printf "%.2f" .OriginalVideo.Duration}} s</td></tr>
+<tr><th>Content</th><td>{{.Analysis.ContentType}}, {{.Analysis.MotionComplexity}} motion</td></tr>
+</table>
+{{with .Analysis.Bitrate}}{{$stats := .}}
+<h2>Source Bitrate</h2>
+<table>
+<tr><th>Seconds Measured</th><td>{{.Seconds}}</td></tr>
+<tr><th>Mean</th><td>{{bitrate .Mean}}</td></tr>
+<tr><th>Median</th><td>{{bitrate .P50}}</td></tr>
+<tr><th>90th / 95th / 99th Percentile</th><td>{{bitrate .P90}} / {{bitrate .P95}} / {{bitrate .P99}}</td></tr>
+<tr><th>Range</th><td>{{bitrate .Min}} to {{bitrate .Max}}</td></tr>
+</table>
+<table>
+<tr><th>Bitrate</th><th>Seconds</th><th style="width: 300px"></th></tr>
+{{range .Histogram}}<tr><td>{{bitrateRange .}}</td><td>{{.Seconds}}</td><td><div class="bar" style="width: {{barPercent . $stats}}%"></div></td></tr>
+{{end}}</table>
+{{end}}{{end}}
+<h2>Encoding Settings</h2>
+<table>
+<tr><th>Setting</th><th>Value</th></tr>
+{{range .Settings}}<tr><td>{{index . 0}}</td><td><code>{{index . 1}}</code></td></tr>
+{{end}}</table>
+{{with .Report.Result.Overridden}}<p>Overridden on the command line: {{range $i, $name := .}}{{if $i}}, {{end}}<code>{{$name}}</code>{{end}}</p>
+{{end}}{{if .Frames}}
+<h2>Before / After</h2>
+<div class="frames">
+{{range .Frames}}<figure><img src="{{.Source}}" alt="Source frame"><figcaption>Source at {{printf "%.1f" .Time}}s</figcaption></figure>
+<figure><img src="{{.Output}}" alt="Output frame"><figcaption>Output at {{printf "%.1f" .Time}}s</figcaption></figure>
+{{end}}</div>
+{{end}}
+{{with .Report.CompressionTips}}
+<h2>Optimization Tips</h2>
+<ul>
+{{range .}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+{{with .Report.Decisions}}
+<h2>Decisions</h2>
+<ul>
+{{range .}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}
+<p><small>Report generated on {{.GeneratedAt}}</small></p>
+</body>
+</html>
+`))
+
+// writeHTMLReport writes a self-contained HTML report with embedded frames
+func writeHTMLReport(w io.Writer, report *Report, frames []frameComparison) error {
+	data := htmlReportData{
+		Report:      report,
+		Title:       "Compression Report: " + filepath.Base(report.InputFile),
+		Frames:      frames,
+		GeneratedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	for _, key := range sortedKeys(report.Result.Settings) {
+		data.Settings = append(data.Settings, [2]string{key, report.Result.Settings[key]})
+	}
+	return htmlReportTemplate.Execute(w, data)
+}
+
+// captureFrames extracts matching frames from the source and the output at
+// evenly spaced timestamps. Frames that fail to extract are skipped.
+func (rg *ReportGenerator) captureFrames(report *Report) []frameComparison {
+	if report.OriginalVideo == nil {
+		return nil
+	}
+
+	runner := rg.runner()
+	ffmpegPath, err := runner.EncodePath()
+	if err != nil {
+		rg.Logger.Warning("FFmpeg not available, HTML report will not include frames")
+		return nil
+	}
+
+	frames := []frameComparison{}
+	for _, t := range frameTimestamps(report.OriginalVideo.Duration, htmlFrameCount) {
+		source, err := captureFrame(runner, ffmpegPath, report.InputFile, t)
+		if err != nil {
+			rg.Logger.Debug("Failed to capture source frame at %.1fs: %v", t, err)
+			continue
+		}
+		output, err := captureFrame(runner, ffmpegPath, report.OutputFile, t)
+		if err != nil {
+			rg.Logger.Debug("Failed to capture output frame at %.1fs: %v", t, err)
+			continue
+		}
+		frames = append(frames, frameComparison{Time: t, Source: source, Output: output})
+	}
+	return frames
+}
+
+// frameTimestamps spreads count timestamps evenly inside the video, away from the edges
+func frameTimestamps(duration float64, count int) []float64 {
+	if duration <= 0 || count <= 0 {
+		return nil
+	}
+	timestamps := make([]float64, count)
+	for i := range timestamps {
+		timestamps[i] = duration * float64(i+1) / float64(count+1)
+	}
+	return timestamps
+}
+
+// captureFrame grabs a single JPEG frame and returns it as a data URL
+func captureFrame(runner ffmpeg.Runner, ffmpegPath, file string, t float64) (template.URL, error) {
+	data, err := ffmpeg.Output(runner, ffmpegPath, []string{
+		"-ss", fmt.Sprintf("%.3f", t),
+		"-i", ffmpeg.FileArg(file),
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:-2", htmlFrameWidth),
+		"-f", "image2",
+		"-c:v", "mjpeg",
+		"-q:v", "3",
+		"pipe:1",
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("no frame data")
+	}
+	return template.URL("data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(data)), nil
+}
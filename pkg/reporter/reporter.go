@@ -2,14 +2,15 @@ package reporter
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
+	"math"
 	"strings"
 	"time"
 
 	"github.com/cccarv82/compressvideo/pkg/analyzer"
 	"github.com/cccarv82/compressvideo/pkg/compressor"
 	"github.com/cccarv82/compressvideo/pkg/ffmpeg"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 	"github.com/cccarv82/compressvideo/pkg/util"
 )
 
@@ -27,6 +28,8 @@ type Report struct {
 	TimeSaved        float64  // Estimated time saved in transfer or playback
 	StorageSaved     float64  // Amount of storage space saved
 	PerformanceScore float64  // Score from 0-100 on the compression
+	Decisions        []analyzer.Decision // Why each setting was chosen, recorded with --explain
+	FrameDiffs       []FrameDiff         // SSIM of the output frames at sampled times, worst first
 }
 
 // ReportGenerator creates and manages compression reports
@@ -43,6 +46,14 @@ func NewReportGenerator(logger *util.Logger, ffmpeg *ffmpeg.FFmpeg) *ReportGener
 	}
 }
 
+// runner returns the Runner that runs FFmpeg for the reports
+func (rg *ReportGenerator) runner() ffmpeg.Runner {
+	if rg.FFmpeg != nil && rg.FFmpeg.Runner != nil {
+		return rg.FFmpeg.Runner
+	}
+	return ffmpeg.DefaultRunner
+}
+
 // CreateReport initializes a new report with basic information
 func (rg *ReportGenerator) CreateReport(inputFile, outputFile string, 
 	originalVideo *ffmpeg.VideoFile, analysis *analyzer.VideoAnalysis) *Report {
@@ -115,6 +126,11 @@ func (rg *ReportGenerator) generateCompressionTips(report *Report) []string {
 		tips = append(tips, "Audio is using a high bitrate. Consider using 128kbps AAC for most content, or 192kbps for music videos.")
 	}
 	
+	// Add tip for sources whose bitrate comes in spikes
+	if report.Analysis.Bitrate.Spiky() {
+		tips = append(tips, fmt.Sprintf("The source bitrate comes in spikes, its 95th percentile %.1f times its median. Constant quality (CRF) follows them; when capping the bitrate, leave room above the average for busy scenes.", report.Analysis.Bitrate.PeakRatio()))
+	}
+	
 	// Add tip about resolution
 	if report.OriginalVideo.VideoInfo.Height >= 1080 && report.Result.SavedSpacePercent < 30 {
 		tips = append(tips, "Consider downscaling to 720p if this video doesn't require full HD resolution.")
@@ -202,17 +218,43 @@ func (rg *ReportGenerator) DisplayReportToConsole(report *Report) {
 	logger.Info("  Duration:   %.2f seconds", report.OriginalVideo.Duration)
 	logger.Info("  Content:    %s, %s motion", report.Analysis.ContentType, report.Analysis.MotionComplexity)
 	
+	// How the bitrate of the source varies
+	if stats := report.Analysis.Bitrate; stats != nil {
+		logger.Info("\n📈 SOURCE BITRATE (%d s):", stats.Seconds)
+		logger.Info("  Mean: %s, median: %s", util.FormatBitrate(stats.Mean), util.FormatBitrate(stats.P50))
+		logger.Info("  Percentiles: 90th %s, 95th %s, 99th %s", util.FormatBitrate(stats.P90), util.FormatBitrate(stats.P95), util.FormatBitrate(stats.P99))
+		logger.Info("  Range: %s to %s", util.FormatBitrate(stats.Min), util.FormatBitrate(stats.Max))
+		for _, bucket := range stats.Histogram {
+			logger.Info("  %-25s %5d s %s", bitrateRange(bucket), bucket.Seconds, histogramBar(bucket, stats))
+		}
+	}
+	
 	// Compression Results
 	logger.Info("\n📊 COMPRESSION RESULTS:")
 	logger.Info("  Original Size:    %.2f MB", float64(report.Result.OriginalSize)/(1024*1024))
 	logger.Info("  Compressed Size:  %.2f MB", float64(report.Result.CompressedSize)/(1024*1024))
 	logger.Info("  Space Saved:      %.2f MB (%.1f%%)", float64(report.Result.SavedSpaceBytes)/(1024*1024), report.Result.SavedSpacePercent)
 	logger.Info("  Compression Ratio: %.2f:1", report.Result.CompressionRatio)
+	if recovery := report.Result.Recovery; recovery != nil {
+		recovered := util.FormatDuration(int(math.Round(recovery.OutputDuration)))
+		if recovery.SourceDuration > 0 {
+			logger.Info("  Recovered:        %s of %s (%.1f%%)", recovered,
+				util.FormatDuration(int(math.Round(recovery.SourceDuration))), recovery.Percent())
+		} else {
+			logger.Info("  Recovered:        %s (source duration unknown)", recovered)
+		}
+	}
 	
 	// Performance
 	logger.Info("\n⏱️ PERFORMANCE:")
 	logger.Info("  Processing Time:  %s", report.Result.ProcessingTime.Round(time.Second))
+	if report.Result.CPUTime > 0 {
+		logger.Info("  CPU Time:         %s (about %.2f Wh)", report.Result.CPUTime.Round(time.Second), report.Result.EnergyWh)
+	}
 	logger.Info("  Quality Estimate: %s (%.1f/100)", report.QualityEstimate, report.Result.AverageFrameQuality)
+	if report.Result.VMAFScore > 0 {
+		logger.Info("  Predicted VMAF:   %.2f", report.Result.VMAFScore)
+	}
 	logger.Info("  Overall Score:    %.1f/100", report.PerformanceScore)
 	
 	if report.TimeSaved > 0 {
@@ -225,15 +267,30 @@ func (rg *ReportGenerator) DisplayReportToConsole(report *Report) {
 		}
 	}
 	
+	// Where to look for artifacts
+	if len(report.FrameDiffs) > 0 {
+		logger.Info("\n🔍 FRAME COMPARISON (worst first):")
+		for _, diff := range report.FrameDiffs {
+			logger.Info("  • %s", diff)
+		}
+	}
+	
 	// Codec & Settings
 	logger.Info("\n⚙️ ENCODING SETTINGS:")
 	logger.Info("  Video Codec: %s", report.Result.Settings["codec"])
+	if fallback := report.Result.HardwareFallback; fallback != nil {
+		logger.Info("  Hardware Fallback: %s failed (%s), re-encoded with %s",
+			fallback.Encoder, i18n.Tr(fallback.Reason), fallback.Software)
+	}
 	if crf, ok := report.Result.Settings["crf"]; ok {
 		logger.Info("  Quality (CRF): %s", crf)
 	}
 	if preset, ok := report.Result.Settings["preset"]; ok {
 		logger.Info("  Preset: %s", preset)
 	}
+	if len(report.Result.Overridden) > 0 {
+		logger.Info("  Overridden: %s", strings.Join(report.Result.Overridden, ", "))
+	}
 	
 	// Display tips
 	if len(report.CompressionTips) > 0 {
@@ -243,24 +300,24 @@ func (rg *ReportGenerator) DisplayReportToConsole(report *Report) {
 		}
 	}
 	
+	// Display why the settings were chosen
+	if len(report.Decisions) > 0 {
+		logger.Info("\n🧠 DECISIONS:")
+		for _, decision := range report.Decisions {
+			logger.Info("  • %s", decision)
+		}
+	}
+	
 	logger.Info("\n═════════════════════════════════════════════")
 }
 
 // SaveReportToFile saves the report as a text file
 func (rg *ReportGenerator) SaveReportToFile(report *Report) (string, error) {
-	// Create report name based on output filename
-	baseName := filepath.Base(report.OutputFile)
-	ext := filepath.Ext(baseName)
-	reportName := strings.TrimSuffix(baseName, ext) + "_report.txt"
-	reportPath := filepath.Join(filepath.Dir(report.OutputFile), reportName)
-	
-	// Open file for writing
-	file, err := os.Create(reportPath)
-	if err != nil {
-		return "", err
-	}
-	defer file.Close()
-	
+	return rg.SaveReport(report, FormatText, "")
+}
+
+// writeTextReport writes the report in the plain text format
+func writeTextReport(file io.Writer, report *Report) error {
 	// Write report content
 	fmt.Fprintf(file, "COMPRESSION REPORT\n")
 	fmt.Fprintf(file, "=======================================\n\n")
@@ -274,20 +331,55 @@ func (rg *ReportGenerator) SaveReportToFile(report *Report) (string, error) {
 	fmt.Fprintf(file, "  Duration:   %.2f seconds\n", report.OriginalVideo.Duration)
 	fmt.Fprintf(file, "  Content:    %s, %s motion\n\n", report.Analysis.ContentType, report.Analysis.MotionComplexity)
 	
+	if stats := report.Analysis.Bitrate; stats != nil {
+		fmt.Fprintf(file, "SOURCE BITRATE (%d s):\n", stats.Seconds)
+		fmt.Fprintf(file, "  Mean: %s, median: %s\n", util.FormatBitrate(stats.Mean), util.FormatBitrate(stats.P50))
+		fmt.Fprintf(file, "  Percentiles: 90th %s, 95th %s, 99th %s\n", util.FormatBitrate(stats.P90), util.FormatBitrate(stats.P95), util.FormatBitrate(stats.P99))
+		fmt.Fprintf(file, "  Range: %s to %s\n", util.FormatBitrate(stats.Min), util.FormatBitrate(stats.Max))
+		for _, bucket := range stats.Histogram {
+			fmt.Fprintf(file, "  %-25s %5d s %s\n", bitrateRange(bucket), bucket.Seconds, histogramBar(bucket, stats))
+		}
+		fmt.Fprintf(file, "\n")
+	}
+	
 	fmt.Fprintf(file, "COMPRESSION RESULTS:\n")
 	fmt.Fprintf(file, "  Original Size:    %.2f MB\n", float64(report.Result.OriginalSize)/(1024*1024))
 	fmt.Fprintf(file, "  Compressed Size:  %.2f MB\n", float64(report.Result.CompressedSize)/(1024*1024))
 	fmt.Fprintf(file, "  Space Saved:      %.2f MB (%.1f%%)\n", float64(report.Result.SavedSpaceBytes)/(1024*1024), report.Result.SavedSpacePercent)
-	fmt.Fprintf(file, "  Compression Ratio: %.2f:1\n\n", report.Result.CompressionRatio)
+	fmt.Fprintf(file, "  Compression Ratio: %.2f:1\n", report.Result.CompressionRatio)
+	if report.Result.Recovery != nil {
+		fmt.Fprintf(file, "  Recovered:        %s\n", report.Result.Recovery)
+	}
+	fmt.Fprintf(file, "\n")
 	
 	fmt.Fprintf(file, "PERFORMANCE:\n")
 	fmt.Fprintf(file, "  Processing Time:  %s\n", report.Result.ProcessingTime.Round(time.Second))
+	if report.Result.CPUTime > 0 {
+		fmt.Fprintf(file, "  CPU Time:         %s (about %.2f Wh)\n", report.Result.CPUTime.Round(time.Second), report.Result.EnergyWh)
+	}
 	fmt.Fprintf(file, "  Quality Estimate: %s (%.1f/100)\n", report.QualityEstimate, report.Result.AverageFrameQuality)
+	if report.Result.VMAFScore > 0 {
+		fmt.Fprintf(file, "  Predicted VMAF:   %.2f\n", report.Result.VMAFScore)
+	}
 	fmt.Fprintf(file, "  Overall Score:    %.1f/100\n\n", report.PerformanceScore)
 	
+	if len(report.FrameDiffs) > 0 {
+		fmt.Fprintf(file, "FRAME COMPARISON (worst first):\n")
+		for _, diff := range report.FrameDiffs {
+			fmt.Fprintf(file, "  • %s\n", diff)
+		}
+		fmt.Fprintf(file, "\n")
+	}
+	
 	fmt.Fprintf(file, "ENCODING SETTINGS:\n")
-	for key, value := range report.Result.Settings {
-		fmt.Fprintf(file, "  %s: %s\n", key, value)
+	if report.Result.HardwareFallback != nil {
+		fmt.Fprintf(file, "  Hardware Fallback: %s\n", report.Result.HardwareFallback)
+	}
+	if len(report.Result.Overridden) > 0 {
+		fmt.Fprintf(file, "  Overridden: %s\n", strings.Join(report.Result.Overridden, ", "))
+	}
+	for _, key := range sortedKeys(report.Result.Settings) {
+		fmt.Fprintf(file, "  %s: %s\n", key, report.Result.Settings[key])
 	}
 	
 	if len(report.CompressionTips) > 0 {
@@ -297,7 +389,13 @@ func (rg *ReportGenerator) SaveReportToFile(report *Report) (string, error) {
 		}
 	}
 	
-	fmt.Fprintf(file, "\nReport generated on %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	if len(report.Decisions) > 0 {
+		fmt.Fprintf(file, "\nDECISIONS:\n")
+		for _, decision := range report.Decisions {
+			fmt.Fprintf(file, "  • %s\n", decision)
+		}
+	}
 	
-	return reportPath, nil
-} 
\ No newline at end of file
+	_, err := fmt.Fprintf(file, "\nReport generated on %s\n", time.Now().Format("2006-01-02 15:04:05"))
+	return err
+}
@@ -0,0 +1,233 @@
+// Package sidecar finds the files that travel with a video, such as
+// subtitles, artwork and NFO metadata, and brings them along to its output
+package sidecar
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kind is the sort of data a sidecar file holds
+type Kind string
+
+// Sidecar kinds
+const (
+	Subtitle Kind = "subtitle"
+	Image    Kind = "image"
+	Info     Kind = "info"
+)
+
+// Modes accepted by --sidecars
+const (
+	ModeCopy = "copy" // Copy the sidecars next to the output
+	ModeMove = "move" // Move them, so they follow the output
+)
+
+// kinds maps the extensions of sidecar files to what they hold
+var kinds = map[string]Kind{
+	".srt": Subtitle, ".ass": Subtitle, ".ssa": Subtitle, ".vtt": Subtitle,
+	".sub": Subtitle, ".idx": Subtitle, ".sup": Subtitle,
+	".jpg": Image, ".jpeg": Image, ".png": Image, ".tbn": Image,
+	".nfo": Info,
+}
+
+// textSubtitles are the subtitle formats FFmpeg converts into the text
+// subtitles of any container; image-based ones such as VobSub are not
+var textSubtitles = map[string]bool{".srt": true, ".ass": true, ".ssa": true, ".vtt": true}
+
+// artworkSuffixes are the names media centers give the artwork of a video,
+// as in clip-poster.jpg
+var artworkSuffixes = []string{"-thumb", "-poster", "-fanart", "-banner", "-landscape"}
+
+// languageRegex matches language tags such as en, por or pt-BR
+var languageRegex = regexp.MustCompile(`^[a-z]{2,3}([-_][A-Za-z]{2,4})?$`)
+
+// File is a sidecar of a video
+type File struct {
+	Path   string // Where the file is
+	Suffix string // What follows the video's name, e.g. ".en.srt" or "-poster.jpg"
+	Kind   Kind
+}
+
+// ValidMode reports whether mode is a supported --sidecars value
+func ValidMode(mode string) bool {
+	return mode == "" || mode == ModeCopy || mode == ModeMove
+}
+
+// Find returns the sidecars of video: files next to it named as the video
+// followed by language tags and a sidecar extension, or by an artwork
+// suffix. Files that belong to another video whose name extends this one,
+// such as clip.part2.srt next to clip.part2.mp4, are left to it.
+func Find(video string, isVideo func(name string) bool) ([]File, error) {
+	dir := filepath.Dir(video)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	base := filepath.Base(video)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	var others []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if name != base && !entry.IsDir() && isVideo(name) {
+			others = append(others, strings.TrimSuffix(name, filepath.Ext(name)))
+		}
+	}
+
+	var files []File
+	for _, entry := range entries {
+		name := entry.Name()
+		kind, ok := kinds[strings.ToLower(filepath.Ext(name))]
+		if !ok || entry.IsDir() || !strings.HasPrefix(name, stem) {
+			continue
+		}
+		suffix := name[len(stem):]
+		if !isSidecarSuffix(suffix, kind) || ownedByOther(name, stem, others) {
+			continue
+		}
+		files = append(files, File{Path: filepath.Join(dir, name), Suffix: suffix, Kind: kind})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// isSidecarSuffix reports whether what follows a video's name is an
+// extension, possibly after tags such as .en or .forced, or artwork
+func isSidecarSuffix(suffix string, kind Kind) bool {
+	if strings.HasPrefix(suffix, ".") {
+		return true
+	}
+	if kind != Image {
+		return false
+	}
+	tag := strings.ToLower(strings.TrimSuffix(suffix, filepath.Ext(suffix)))
+	for _, artwork := range artworkSuffixes {
+		if tag == artwork {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedByOther reports whether a video with a longer name than stem is also
+// a prefix of name, so the file is that video's sidecar
+func ownedByOther(name, stem string, others []string) bool {
+	for _, other := range others {
+		if len(other) > len(stem) && strings.HasPrefix(name, other) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTextSubtitle reports whether name is a text subtitle file, which FFmpeg
+// can add to any container or draw into the picture
+func IsTextSubtitle(name string) bool {
+	return textSubtitles[strings.ToLower(filepath.Ext(name))]
+}
+
+// Muxable reports whether the file is a text subtitle FFmpeg can add to an
+// output container
+func (f File) Muxable() bool {
+	return f.Kind == Subtitle && IsTextSubtitle(f.Path)
+}
+
+// tags returns the dot-separated words between the video's name and the
+// extension, e.g. en and forced for .en.forced.srt
+func (f File) tags() []string {
+	tags := strings.TrimSuffix(f.Suffix, filepath.Ext(f.Suffix))
+	return strings.FieldsFunc(tags, func(r rune) bool { return r == '.' })
+}
+
+// Language returns the language tag of a subtitle named like clip.en.srt,
+// or "" when it has none
+func (f File) Language() string {
+	for _, tag := range f.tags() {
+		if tag != "forced" && tag != "sdh" && languageRegex.MatchString(tag) {
+			return tag
+		}
+	}
+	return ""
+}
+
+// Forced reports whether a subtitle is named as forced, like
+// clip.en.forced.srt: it only translates foreign dialogue and signs
+func (f File) Forced() bool {
+	for _, tag := range f.tags() {
+		if strings.EqualFold(tag, "forced") {
+			return true
+		}
+	}
+	return false
+}
+
+// Target returns the path of f next to output, named after it
+func (f File) Target(output string) string {
+	return strings.TrimSuffix(output, filepath.Ext(output)) + f.Suffix
+}
+
+// Place copies or moves each file next to output, named after it, and
+// returns the paths written. Targets that exist are kept unless overwrite
+// is set, and are returned as skipped.
+func Place(files []File, output string, move, overwrite bool) (placed, skipped []string, err error) {
+	for _, file := range files {
+		target := file.Target(output)
+		if target == file.Path {
+			continue
+		}
+		if _, statErr := os.Stat(target); statErr == nil && !overwrite {
+			skipped = append(skipped, target)
+			continue
+		}
+		if move {
+			err = moveFile(file.Path, target)
+		} else {
+			err = copyFile(file.Path, target)
+		}
+		if err != nil {
+			return placed, skipped, err
+		}
+		placed = append(placed, target)
+	}
+	return placed, skipped, nil
+}
+
+// moveFile renames src to dst, copying it when they are on different
+// file systems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyFile(src, dst); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// copyFile copies src to dst, keeping its permissions
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
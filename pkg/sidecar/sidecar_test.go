@@ -0,0 +1,97 @@
+package sidecar
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func isMP4(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".mp4")
+}
+
+// touch creates files with their names as content under dir
+func touch(t *testing.T, dir string, names ...string) {
+	for _, name := range names {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0644))
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	touch(t, dir, "clip.mp4", "clip.srt", "clip.en.forced.srt", "clip.pt-BR.ass", "clip.nfo", "clip-poster.jpg",
+		"clip.jpg", "clipper.srt", "clip-notes.jpg", "clip.txt", "clip.part2.mp4", "clip.part2.srt", "other.srt")
+
+	files, err := Find(filepath.Join(dir, "clip.mp4"), isMP4)
+	assert.NoError(t, err)
+
+	var suffixes []string
+	for _, file := range files {
+		suffixes = append(suffixes, file.Suffix)
+	}
+	assert.Equal(t, []string{"-poster.jpg", ".en.forced.srt", ".jpg", ".nfo", ".pt-BR.ass", ".srt"}, suffixes)
+	assert.Equal(t, Image, files[0].Kind)
+	assert.Equal(t, Info, files[3].Kind)
+
+	// The sidecars of clip.part2.mp4 are its own
+	files, err = Find(filepath.Join(dir, "clip.part2.mp4"), isMP4)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.Equal(t, ".srt", files[0].Suffix)
+}
+
+func TestSubtitleTags(t *testing.T) {
+	forced := File{Path: "clip.en.forced.srt", Suffix: ".en.forced.srt", Kind: Subtitle}
+	assert.Equal(t, "en", forced.Language())
+	assert.True(t, forced.Forced())
+	assert.True(t, forced.Muxable())
+
+	regional := File{Path: "clip.pt-BR.ass", Suffix: ".pt-BR.ass", Kind: Subtitle}
+	assert.Equal(t, "pt-BR", regional.Language())
+	assert.False(t, regional.Forced())
+
+	plain := File{Path: "clip.srt", Suffix: ".srt", Kind: Subtitle}
+	assert.Equal(t, "", plain.Language())
+
+	// Image subtitles cannot be converted to text
+	assert.False(t, File{Path: "clip.idx", Suffix: ".idx", Kind: Subtitle}.Muxable())
+	assert.False(t, File{Path: "clip.nfo", Suffix: ".nfo", Kind: Info}.Muxable())
+}
+
+func TestPlace(t *testing.T) {
+	dir := t.TempDir()
+	outDir := filepath.Join(dir, "out")
+	assert.NoError(t, os.Mkdir(outDir, 0755))
+	touch(t, dir, "clip.mp4", "clip.en.srt", "clip.nfo")
+	touch(t, outDir, "clip-compressed.nfo")
+
+	files, err := Find(filepath.Join(dir, "clip.mp4"), isMP4)
+	assert.NoError(t, err)
+	output := filepath.Join(outDir, "clip-compressed.mp4")
+
+	// Copies follow the output name and existing targets are kept
+	placed, skipped, err := Place(files, output, false, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(outDir, "clip-compressed.en.srt")}, placed)
+	assert.Equal(t, []string{filepath.Join(outDir, "clip-compressed.nfo")}, skipped)
+	data, err := os.ReadFile(filepath.Join(outDir, "clip-compressed.en.srt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "clip.en.srt", string(data))
+	data, err = os.ReadFile(filepath.Join(outDir, "clip-compressed.nfo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "clip-compressed.nfo", string(data))
+
+	// Moves replace them when asked and remove the originals
+	placed, skipped, err = Place(files, output, true, true)
+	assert.NoError(t, err)
+	assert.Len(t, placed, 2)
+	assert.Empty(t, skipped)
+	_, err = os.Stat(filepath.Join(dir, "clip.nfo"))
+	assert.True(t, os.IsNotExist(err))
+	data, err = os.ReadFile(filepath.Join(outDir, "clip-compressed.nfo"))
+	assert.NoError(t, err)
+	assert.Equal(t, "clip.nfo", string(data))
+}
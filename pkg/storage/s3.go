@@ -0,0 +1,182 @@
+// Package storage moves videos between object storage and the local disk
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+const (
+	// PartSize is the size of each part of multipart transfers
+	PartSize = 16 * 1024 * 1024
+	// Concurrency is the number of parts transferred at the same time
+	Concurrency = 4
+	// defaultRegion is used when no region is configured, which MinIO accepts
+	defaultRegion = "us-east-1"
+)
+
+// IsS3 reports whether path is an s3://bucket/key URI
+func IsS3(path string) bool {
+	return strings.HasPrefix(strings.ToLower(path), "s3://")
+}
+
+// ParseS3URI splits an s3://bucket/key URI into its bucket and key
+func ParseS3URI(uri string) (bucket, key string, err error) {
+	if !IsS3(uri) {
+		return "", "", i18n.Errorf("not an s3:// URI: %s", uri)
+	}
+	rest := uri[len("s3://"):]
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" || key == "" || strings.HasSuffix(key, "/") {
+		return "", "", i18n.Errorf("S3 URI must name a bucket and an object key (s3://bucket/key): %s", uri)
+	}
+	return bucket, key, nil
+}
+
+// BaseName returns the file name of the object an S3 URI points to
+func BaseName(uri string) string {
+	_, key, err := ParseS3URI(uri)
+	if err != nil {
+		return ""
+	}
+	return path.Base(key)
+}
+
+// S3 transfers files to and from S3 or an S3-compatible service such as MinIO
+type S3 struct {
+	client *s3.Client
+}
+
+// NewS3 creates an S3 client from the standard AWS configuration
+// (environment, shared config files, instance roles). A non-empty endpoint
+// points the client at an S3-compatible service such as MinIO, using
+// path-style addressing.
+func NewS3(ctx context.Context, endpoint string) (*S3, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, i18n.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+	return &S3{client: newClient(cfg, endpoint)}, nil
+}
+
+// newClient creates the S3 service client, pointed at endpoint when set
+func newClient(cfg aws.Config, endpoint string) *s3.Client {
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			if !strings.Contains(endpoint, "://") {
+				endpoint = "https://" + endpoint
+			}
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+}
+
+// Download stages the object at uri in the local file localPath, fetching
+// parts in parallel
+func (s *S3) Download(ctx context.Context, uri, localPath string) (int64, error) {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.Create(localPath)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	downloader := manager.NewDownloader(s.client, func(d *manager.Downloader) {
+		d.PartSize = PartSize
+		d.Concurrency = Concurrency
+	})
+	n, err := downloader.Download(ctx, file, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		os.Remove(localPath)
+		return 0, i18n.Errorf("failed to download %s: %w", uri, err)
+	}
+	return n, nil
+}
+
+// Upload stores the local file localPath at uri. Files larger than
+// PartSize are sent as a multipart upload.
+func (s *S3) Upload(ctx context.Context, localPath, uri string) error {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	uploader := manager.NewUploader(s.client, func(u *manager.Uploader) {
+		u.PartSize = PartSize
+		u.Concurrency = Concurrency
+	})
+	_, err = uploader.Upload(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String(contentType(key)),
+	})
+	if err != nil {
+		return i18n.Errorf("failed to upload %s: %w", uri, err)
+	}
+	return nil
+}
+
+// Exists reports whether an object is stored at uri
+func (s *S3) Exists(ctx context.Context, uri string) (bool, error) {
+	bucket, key, err := ParseS3URI(uri)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err == nil {
+		return true, nil
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && (apiErr.ErrorCode() == "NotFound" || apiErr.ErrorCode() == "NoSuchKey") {
+		return false, nil
+	}
+	return false, err
+}
+
+// contentType returns the MIME type stored with an uploaded video
+func contentType(key string) string {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".mp4", ".m4v":
+		return "video/mp4"
+	case ".mkv":
+		return "video/x-matroska"
+	case ".webm":
+		return "video/webm"
+	case ".mov":
+		return "video/quicktime"
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseS3URI(t *testing.T) {
+	bucket, key, err := ParseS3URI("s3://media/raw/talk.mp4")
+	assert.NoError(t, err)
+	assert.Equal(t, "media", bucket)
+	assert.Equal(t, "raw/talk.mp4", key)
+	assert.Equal(t, "talk.mp4", BaseName("s3://media/raw/talk.mp4"))
+
+	for _, uri := range []string{"s3://media", "s3://media/", "s3:///talk.mp4", "s3://media/raw/", "/tmp/talk.mp4"} {
+		_, _, err := ParseS3URI(uri)
+		assert.Error(t, err, uri)
+	}
+
+	assert.True(t, IsS3("S3://media/a.mp4"))
+	assert.False(t, IsS3("https://media/a.mp4"))
+}
+
+// fakeS3 is a minimal path-style object store for single-part requests
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[name] = data
+		f.types[name] = r.Header.Get("Content-Type")
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodHead, http.MethodGet:
+		data, ok := f.objects[name]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			}
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(data)))
+		if r.Method == http.MethodGet {
+			// The downloader asks for the first part; it is the whole object here
+			if r.Header.Get("Range") != "" {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data)))
+				w.WriteHeader(http.StatusPartialContent)
+			}
+			w.Write(data)
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3UploadDownload(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	}
+	store := &S3{client: newClient(cfg, server.URL)}
+	ctx := context.Background()
+
+	dir, err := os.MkdirTemp("", "s3")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	local := filepath.Join(dir, "talk-compressed.mp4")
+	assert.NoError(t, os.WriteFile(local, []byte("compressed video"), 0644))
+
+	exists, err := store.Exists(ctx, "s3://media/out/talk.mp4")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, store.Upload(ctx, local, "s3://media/out/talk.mp4"))
+	assert.Equal(t, []byte("compressed video"), fake.objects["media/out/talk.mp4"])
+	assert.Equal(t, "video/mp4", fake.types["media/out/talk.mp4"])
+
+	exists, err = store.Exists(ctx, "s3://media/out/talk.mp4")
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	staged := filepath.Join(dir, "talk.mp4")
+	n, err := store.Download(ctx, "s3://media/out/talk.mp4", staged)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(16), n)
+	data, err := os.ReadFile(staged)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("compressed video"), data)
+
+	_, err = store.Download(ctx, "s3://media/missing.mp4", filepath.Join(dir, "missing.mp4"))
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(dir, "missing.mp4"))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,36 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// CheckWritable reports whether files can be created in dir, creating the
+// directory when it does not exist yet
+func CheckWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-test-")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// existingParent returns path or its closest existing parent, so the free
+// space of a directory that is only created later can be looked up
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
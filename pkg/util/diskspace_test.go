@@ -0,0 +1,34 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWritable(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	assert.NoError(t, CheckWritable(dir))
+
+	// The test file is removed again
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	file := filepath.Join(t.TempDir(), "file")
+	assert.NoError(t, os.WriteFile(file, nil, 0644))
+	assert.Error(t, CheckWritable(file))
+}
+
+func TestFreeDiskSpace(t *testing.T) {
+	free, err := FreeDiskSpace(t.TempDir())
+	assert.NoError(t, err)
+	assert.True(t, free > 0)
+
+	// Directories that do not exist yet use their closest parent
+	missing, err := FreeDiskSpace(filepath.Join(t.TempDir(), "not", "created"))
+	assert.NoError(t, err)
+	assert.True(t, missing > 0)
+}
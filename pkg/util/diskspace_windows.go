@@ -0,0 +1,21 @@
+//go:build windows
+
+package util
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// FreeDiskSpace returns the bytes available to the current user on the
+// volume holding path
+func FreeDiskSpace(path string) (uint64, error) {
+	name, err := windows.UTF16PtrFromString(existingParent(path))
+	if err != nil {
+		return 0, err
+	}
+	var free uint64
+	if err := windows.GetDiskFreeSpaceEx(name, &free, nil, nil); err != nil {
+		return 0, err
+	}
+	return free, nil
+}
@@ -0,0 +1,163 @@
+package util
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// DownloadLimit caps the speed of FFmpeg downloads in bytes per second;
+// 0 downloads at full speed
+var DownloadLimit int64
+
+// downloadResumes is how many times an interrupted download is resumed
+// before giving up
+const downloadResumes = 5
+
+// downloadIdleTimeout stops a download that received nothing for this
+// long, so a stalled connection is resumed instead of hanging
+var downloadIdleTimeout = time.Minute
+
+// downloadContext returns the context of one download attempt. With
+// DownloadLimit set, how long a download takes is up to the user, so only
+// stalled connections are stopped.
+func downloadContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if DownloadLimit > 0 {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// downloadRange requests url from offset on and returns the response, the
+// offset the body starts at (0 when the server ignored the range) and the
+// total size of the file (-1 when unknown)
+func downloadRange(ctx context.Context, client *http.Client, url string, offset int64) (*http.Response, int64, int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, 0, 0, i18n.Errorf("failed to create request: %v", err)
+	}
+	// Some mirrors answer 403 Forbidden without a browser User-Agent
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, 0, i18n.Errorf("download failed: %v", err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		total := resp.ContentLength
+		return resp, 0, total, nil
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			resp.Body.Close()
+			return nil, 0, 0, i18n.Errorf("invalid Content-Range: %s", resp.Header.Get("Content-Range"))
+		}
+		return resp, start, total, nil
+	default:
+		resp.Body.Close()
+		return nil, 0, 0, i18n.Errorf("invalid status code: %d", resp.StatusCode)
+	}
+}
+
+// parseContentRange parses a Content-Range header such as
+// "bytes 1000-1999/2000", returning the first byte and the total size (-1
+// when the server does not know it)
+func parseContentRange(value string) (int64, int64, bool) {
+	value, ok := strings.CutPrefix(value, "bytes ")
+	if !ok {
+		return 0, 0, false
+	}
+	byteRange, size, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0, false
+	}
+	first, _, ok := strings.Cut(byteRange, "-")
+	if !ok {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if size == "*" {
+		return start, -1, true
+	}
+	total, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, total, true
+}
+
+// rateLimitedReader reads no faster than limit bytes per second on average
+type rateLimitedReader struct {
+	ctx    context.Context
+	reader io.Reader
+	limit  int64
+	start  time.Time
+	read   int64
+}
+
+func newRateLimitedReader(ctx context.Context, reader io.Reader, limit int64) *rateLimitedReader {
+	return &rateLimitedReader{ctx: ctx, reader: reader, limit: limit, start: time.Now()}
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	// Small reads keep the speed even instead of bursting a whole buffer
+	if chunk := r.limit / 10; chunk > 0 && int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+	n, err := r.reader.Read(p)
+	r.read += int64(n)
+
+	due := time.Duration(float64(r.read) / float64(r.limit) * float64(time.Second))
+	if wait := due - time.Since(r.start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-r.ctx.Done():
+			return n, r.ctx.Err()
+		}
+	}
+	return n, err
+}
+
+// idleReader calls cancel when no data arrives for timeout
+type idleReader struct {
+	reader  io.Reader
+	timer   *time.Timer
+	timeout time.Duration
+}
+
+func newIdleReader(reader io.Reader, timeout time.Duration, cancel context.CancelFunc) *idleReader {
+	return &idleReader{reader: reader, timer: time.AfterFunc(timeout, cancel), timeout: timeout}
+}
+
+func (r *idleReader) Read(p []byte) (int, error) {
+	r.timer.Reset(r.timeout)
+	n, err := r.reader.Read(p)
+	r.timer.Stop()
+	return n, err
+}
+
+// truncateDownload empties a partial download the server cannot resume
+func truncateDownload(out *os.File) error {
+	if err := out.Truncate(0); err != nil {
+		return err
+	}
+	_, err := out.Seek(0, io.SeekStart)
+	return err
+}
@@ -0,0 +1,91 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDownloadResume tests that a download cut off mid-way is resumed with a
+// Range request instead of starting over
+func TestDownloadResume(t *testing.T) {
+	content := bytes.Repeat([]byte("ffmpeg"), 50000)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if len(ranges) == 1 {
+			// Promise the whole file but drop the connection half-way
+			w.Header().Set("Content-Length", "300000")
+			w.Write(content[:100000])
+			return
+		}
+		http.ServeContent(w, r, "ffmpeg.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "ffmpeg.zip")
+	err := downloadFileWithProgress(context.Background(), server.URL, dest, NewLogger(false))
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.True(t, bytes.Equal(content, data), "downloaded file differs from the original")
+	assert.Equal(t, []string{"", "bytes=100000-"}, ranges)
+}
+
+// TestDownloadRestart tests that a download is started over when the
+// server ignores the Range request
+func TestDownloadRestart(t *testing.T) {
+	content := bytes.Repeat([]byte("ffmpeg"), 50000)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Length", "300000")
+		if requests == 1 {
+			w.Write(content[:100000])
+			return
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "ffmpeg.zip")
+	assert.NoError(t, downloadFileWithProgress(context.Background(), server.URL, dest, NewLogger(false)))
+	data, err := os.ReadFile(dest)
+	assert.NoError(t, err)
+	assert.Len(t, data, len(content))
+	assert.Equal(t, 2, requests)
+}
+
+// TestRateLimitedReader tests that reads are slowed down to the limit
+func TestRateLimitedReader(t *testing.T) {
+	start := time.Now()
+	reader := newRateLimitedReader(context.Background(), bytes.NewReader(make([]byte, 30000)), 100000)
+	n, err := io.Copy(io.Discard, reader)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(30000), n)
+	assert.True(t, time.Since(start) >= 250*time.Millisecond, "read 30000 bytes at 100000 B/s in %v", time.Since(start))
+}
+
+func TestParseContentRange(t *testing.T) {
+	start, total, ok := parseContentRange("bytes 1000-1999/2000")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1000), start)
+	assert.Equal(t, int64(2000), total)
+
+	start, total, ok = parseContentRange("bytes 500-999/*")
+	assert.True(t, ok)
+	assert.Equal(t, int64(500), start)
+	assert.Equal(t, int64(-1), total)
+
+	_, _, ok = parseContentRange("items 0-1/2")
+	assert.False(t, ok)
+}
@@ -0,0 +1,163 @@
+package util
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/cccarv82/compressvideo/pkg/config"
+)
+
+// MinFFmpegVersion é a versão mais antiga do FFmpeg com que o CompressVideo
+// funciona
+const MinFFmpegVersion = "4.0"
+
+// MinSystemFFmpegVersion é a versão mínima de um FFmpeg do sistema para ser
+// usado no lugar de uma cópia baixada
+const MinSystemFFmpegVersion = "4.4"
+
+// PreferDownloaded faz o FindFFmpeg usar a cópia baixada pelo CompressVideo
+// mesmo com um FFmpeg instalado no sistema (--prefer-downloaded)
+var PreferDownloaded bool
+
+// firstSnapshotYear é o ano dos builds de desenvolvimento (versões como
+// 2023-01-01-git-...) a partir do qual eles atendem à versão mínima
+const firstSnapshotYear = 2021
+
+// ffprobeNames são os nomes do FFprobe ao lado do FFmpeg; o snap o instala
+// como ffmpeg.ffprobe
+var ffprobeNames = []string{"ffprobe", "ffmpeg.ffprobe"}
+
+// packageManagerDirs retorna os diretórios onde gerenciadores de pacotes
+// instalam o FFmpeg, que podem faltar no PATH de serviços e atalhos gráficos
+func packageManagerDirs() []string {
+	homeDir, _ := os.UserHomeDir()
+	var dirs []string
+	switch GetCurrentOS() {
+	case MacOS:
+		dirs = []string{
+			"/opt/homebrew/bin", // Homebrew em Apple Silicon
+			"/usr/local/bin",    // Homebrew em Intel
+			"/opt/local/bin",    // MacPorts
+		}
+	case Linux:
+		dirs = []string{
+			"/home/linuxbrew/.linuxbrew/bin",
+			filepath.Join(homeDir, ".linuxbrew", "bin"),
+			"/snap/bin",
+			"/var/lib/flatpak/exports/bin",
+			filepath.Join(homeDir, ".local", "share", "flatpak", "exports", "bin"),
+			"/usr/local/bin",
+			"/usr/bin",
+		}
+	case Windows:
+		scoop := os.Getenv("SCOOP")
+		if scoop == "" {
+			scoop = filepath.Join(homeDir, "scoop")
+		}
+		chocolatey := os.Getenv("ChocolateyInstall")
+		if chocolatey == "" {
+			chocolatey = `C:\ProgramData\chocolatey`
+		}
+		dirs = []string{
+			filepath.Join(scoop, "shims"),
+			filepath.Join(chocolatey, "bin"),
+			filepath.Join(os.Getenv("LOCALAPPDATA"), "Microsoft", "WinGet", "Links"),
+		}
+	}
+	return dirs
+}
+
+// systemFFmpegCandidates lista os FFmpeg do sistema, primeiro o do PATH e
+// depois os dos gerenciadores de pacotes, com o FFprobe de cada um (vazio
+// se não houver)
+func systemFFmpegCandidates() []ffmpegCandidate {
+	var candidates []ffmpegCandidate
+	seen := map[string]int{} // Índice de cada executável já listado
+	add := func(ffmpegPath, ffprobePath string) {
+		resolved, err := filepath.EvalSymlinks(ffmpegPath)
+		if err != nil {
+			resolved = ffmpegPath
+		}
+		if i, ok := seen[resolved]; ok {
+			// O mesmo FFmpeg, achado no PATH sem o FFprobe
+			if candidates[i].probePath == "" {
+				candidates[i].probePath = ffprobePath
+			}
+			return
+		}
+		seen[resolved] = len(candidates)
+		candidates = append(candidates, ffmpegCandidate{path: ffmpegPath, probePath: ffprobePath})
+	}
+
+	if ffmpegPath, err := exec.LookPath("ffmpeg"); err == nil {
+		ffprobePath, _ := exec.LookPath("ffprobe")
+		add(ffmpegPath, ffprobePath)
+	}
+	for _, dir := range packageManagerDirs() {
+		ffmpegPath := filepath.Join(dir, "ffmpeg"+GetExecutableExtension())
+		if !fileExists(ffmpegPath) {
+			continue
+		}
+		ffprobePath := ""
+		for _, name := range ffprobeNames {
+			if path := filepath.Join(dir, name+GetExecutableExtension()); fileExists(path) {
+				ffprobePath = path
+				break
+			}
+		}
+		add(ffmpegPath, ffprobePath)
+	}
+	return candidates
+}
+
+// preferDownloaded informa se a cópia baixada tem prioridade sobre o FFmpeg
+// do sistema: com --prefer-downloaded ou com um build fixado na configuração
+func preferDownloaded() bool {
+	if PreferDownloaded {
+		return true
+	}
+	cfg, err := config.Load(config.DefaultPath())
+	return err == nil && cfg.FFmpeg.URL != ""
+}
+
+// FFmpegVersionAtLeast informa se a versão do FFmpeg (como "6.1",
+// "4.4.2-0ubuntu0.22.04.1" ou "n5.1") é igual ou posterior a minimum.
+// Builds de desenvolvimento e versões desconhecidas são aceitos.
+func FFmpegVersionAtLeast(version, minimum string) bool {
+	major, minor, ok := parseFFmpegVersion(version)
+	if !ok {
+		// Builds do branch master (N-112345-g...) e versões ilegíveis
+		return true
+	}
+	if major >= 1000 {
+		// Snapshots datados, como 2023-01-01-git-...
+		return major >= firstSnapshotYear
+	}
+	minMajor, minMinor, _ := parseFFmpegVersion(minimum)
+	return major > minMajor || (major == minMajor && minor >= minMinor)
+}
+
+// parseFFmpegVersion extrai a versão principal e secundária de uma versão
+// do FFmpeg
+func parseFFmpegVersion(version string) (int, int, bool) {
+	version = strings.TrimPrefix(version, "n")
+	end := strings.IndexFunc(version, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if end >= 0 {
+		version = version[:end]
+	}
+	parts := strings.Split(version, ".")
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor := 0
+	if len(parts) > 1 {
+		minor, _ = strconv.Atoi(parts[1])
+	}
+	return major, minor, true
+}
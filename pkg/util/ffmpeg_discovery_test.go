@@ -0,0 +1,81 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFFmpegVersionAtLeast(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"6.1":                    true,
+		"4.4":                    true,
+		"4.4.2-0ubuntu0.22.04.1": true,
+		"n5.1.2":                 true,
+		"4.2.7":                  false,
+		"3.4.11":                 false,
+		"N-112345-gabcdef0":      true, // Master builds
+		"2023-01-01-git-abc-essentials_build-www.gyan.dev": true,
+		"2019-05-01-git-abc": false,
+		"Unknown":            true,
+	} {
+		assert.Equal(t, expected, FFmpegVersionAtLeast(version, MinSystemFFmpegVersion), version)
+	}
+}
+
+// writeFakeFFmpeg writes ffmpeg and ffprobe scripts reporting version to dir
+func writeFakeFFmpeg(t *testing.T, dir, version string) {
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	script := "#!/bin/sh\necho \"ffmpeg version " + version + " Copyright\"\n"
+	for _, name := range []string{"ffmpeg", "ffprobe"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0755))
+	}
+}
+
+// TestFindFFmpegPreference tests the choice between the system FFmpeg and
+// the downloaded copy
+func TestFindFFmpegPreference(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as FFmpeg")
+	}
+	home := t.TempDir()
+	systemDir := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", systemDir)
+	if len(systemFFmpegCandidates()) > 0 {
+		t.Skip("FFmpeg is installed in a package manager directory")
+	}
+	downloadedDir := filepath.Join(home, ".compressvideo", "bin")
+	writeFakeFFmpeg(t, downloadedDir, "7.0")
+
+	// A recent system FFmpeg is used instead of the downloaded copy
+	writeFakeFFmpeg(t, systemDir, "6.1")
+	info, err := FindFFmpeg()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(systemDir, "ffmpeg"), info.Path)
+	assert.False(t, info.IsDownloaded)
+
+	// Unless the downloaded copy is preferred
+	PreferDownloaded = true
+	info, err = FindFFmpeg()
+	PreferDownloaded = false
+	assert.NoError(t, err)
+	assert.True(t, info.IsDownloaded)
+
+	// An old system FFmpeg gives way to the downloaded copy
+	writeFakeFFmpeg(t, systemDir, "4.2.7")
+	info, err = FindFFmpeg()
+	assert.NoError(t, err)
+	assert.True(t, info.IsDownloaded)
+
+	// And is only used, marked outdated, when there is no copy
+	assert.NoError(t, os.RemoveAll(downloadedDir))
+	info, err = FindFFmpeg()
+	assert.NoError(t, err)
+	assert.True(t, info.Available)
+	assert.True(t, info.Outdated)
+	assert.Equal(t, "4.2.7", info.Version)
+}
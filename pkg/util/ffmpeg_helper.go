@@ -4,7 +4,6 @@ import (
 	"archive/zip"
 	"bufio"
 	"context"
-	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -13,6 +12,8 @@ import (
 	"runtime"
 	"strings"
 	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 )
 
 const (
@@ -32,25 +33,32 @@ type FFmpegInfo struct {
 	FFprobePath  string // Caminho para o executável do FFprobe
 	Version      string // Versão do FFmpeg
 	IsDownloaded bool   // Se esta é uma versão baixada por nós
+	ProbeMissing bool   // Se apenas o FFmpeg foi encontrado (análise limitada, sem FFprobe)
+	Outdated     bool   // Se é um FFmpeg do sistema abaixo de MinSystemFFmpegVersion
+	Build        *FFmpegBuild // Origem e checksum do build baixado, se registrado
 }
 
-// URLs de download para diferentes sistemas operacionais
-var ffmpegMirrors = map[OSType][]string{
+// URLs de download para diferentes sistemas operacionais, com as listas
+// SHA-256 publicadas pelos mirrors que as oferecem
+var ffmpegMirrors = map[OSType][]ffmpegSource{
 	Windows: {
-		"https://github.com/BtbN/FFmpeg-Builds/releases/download/latest/ffmpeg-master-latest-win64-gpl.zip",
-		"https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip",
-		"https://github.com/GyanD/codexffmpeg/releases/download/5.1.2/ffmpeg-5.1.2-essentials_build.zip",
+		{URL: "https://github.com/BtbN/FFmpeg-Builds/releases/download/latest/ffmpeg-master-latest-win64-gpl.zip", ChecksumURL: btbnChecksums},
+		{URL: "https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip", ChecksumURL: "https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip.sha256"},
+		{URL: "https://github.com/GyanD/codexffmpeg/releases/download/5.1.2/ffmpeg-5.1.2-essentials_build.zip"},
 	},
 	Linux: {
-		"https://johnvansickle.com/ffmpeg/releases/ffmpeg-release-amd64-static.tar.xz",
-		"https://github.com/BtbN/FFmpeg-Builds/releases/download/latest/ffmpeg-master-latest-linux64-gpl.tar.xz",
+		{URL: "https://github.com/BtbN/FFmpeg-Builds/releases/download/latest/ffmpeg-master-latest-linux64-gpl.tar.xz", ChecksumURL: btbnChecksums},
+		{URL: "https://johnvansickle.com/ffmpeg/releases/ffmpeg-release-amd64-static.tar.xz"},
 	},
 	MacOS: {
-		"https://evermeet.cx/ffmpeg/getrelease/ffmpeg/zip",
-		"https://evermeet.cx/ffmpeg/getrelease/ffprobe/zip",
+		{URL: "https://evermeet.cx/ffmpeg/getrelease/ffmpeg/zip"},
+		{URL: "https://evermeet.cx/ffmpeg/getrelease/ffprobe/zip"},
 	},
 }
 
+// btbnChecksums é a lista SHA-256 publicada com os builds do BtbN
+const btbnChecksums = "https://github.com/BtbN/FFmpeg-Builds/releases/download/latest/checksums.sha256"
+
 // Diretório onde o FFmpeg será armazenado
 func getFFmpegDir() string {
 	homeDir, err := os.UserHomeDir()
@@ -61,43 +69,84 @@ func getFFmpegDir() string {
 	return filepath.Join(homeDir, ".compressvideo")
 }
 
-// FindFFmpeg procura pelo FFmpeg no sistema
+// FindFFmpeg procura pelo FFmpeg no sistema. Um FFmpeg do sistema (PATH ou
+// gerenciadores de pacotes) com a versão mínima tem prioridade sobre a cópia
+// baixada, a menos que PreferDownloaded esteja ativo ou um build esteja
+// fixado na configuração.
 func FindFFmpeg() (*FFmpegInfo, error) {
-	// Primeiro, verifica se temos uma versão baixada
 	downloadedPath := filepath.Join(getFFmpegDir(), "bin", "ffmpeg"+GetExecutableExtension())
 	downloadedProbePath := filepath.Join(getFFmpegDir(), "bin", "ffprobe"+GetExecutableExtension())
 	
-	if fileExists(downloadedPath) && fileExists(downloadedProbePath) {
-		// Testar se a versão baixada funciona
-		if err := testFFmpegInstallation(downloadedPath, downloadedProbePath); err == nil {
-			version := getFFmpegVersion(downloadedPath)
-			return &FFmpegInfo{
-				Available:    true,
-				Path:         downloadedPath,
-				FFprobePath:  downloadedProbePath,
-				Version:      version,
-				IsDownloaded: true,
-			}, nil
+	// Versão baixada, se existir e funcionar
+	findDownloaded := func() *FFmpegInfo {
+		if !fileExists(downloadedPath) || !fileExists(downloadedProbePath) {
+			return nil
+		}
+		if err := testFFmpegInstallation(downloadedPath, downloadedProbePath); err != nil {
+			return nil
+		}
+		return &FFmpegInfo{
+			Available:    true,
+			Path:         downloadedPath,
+			FFprobePath:  downloadedProbePath,
+			Version:      getFFmpegVersion(downloadedPath),
+			IsDownloaded: true,
+			Build:        readFFmpegBuild(),
 		}
 	}
 	
-	// Se não encontrou a versão baixada, procura no PATH
-	ffmpegPath, err := exec.LookPath("ffmpeg")
-	if err == nil {
-		// Encontrou o FFmpeg, agora procura o FFprobe
-		ffprobePath, probErr := exec.LookPath("ffprobe")
-		if probErr == nil {
-			// Testar se funciona
-			if err := testFFmpegInstallation(ffmpegPath, ffprobePath); err == nil {
-				version := getFFmpegVersion(ffmpegPath)
-				return &FFmpegInfo{
-					Available:    true,
-					Path:         ffmpegPath,
-					FFprobePath:  ffprobePath,
-					Version:      version,
-					IsDownloaded: false,
-				}, nil
-			}
+	if preferDownloaded() {
+		if info := findDownloaded(); info != nil {
+			return info, nil
+		}
+	}
+	
+	// FFmpeg do sistema com FFprobe, separando os abaixo da versão mínima
+	systemCandidates := systemFFmpegCandidates()
+	var outdated *FFmpegInfo
+	for _, candidate := range systemCandidates {
+		if candidate.probePath == "" {
+			continue
+		}
+		if err := testFFmpegInstallation(candidate.path, candidate.probePath); err != nil {
+			continue
+		}
+		info := &FFmpegInfo{
+			Available:   true,
+			Path:        candidate.path,
+			FFprobePath: candidate.probePath,
+			Version:     getFFmpegVersion(candidate.path),
+		}
+		if FFmpegVersionAtLeast(info.Version, MinSystemFFmpegVersion) {
+			return info, nil
+		}
+		if outdated == nil {
+			info.Outdated = true
+			outdated = info
+		}
+	}
+	
+	if info := findDownloaded(); info != nil {
+		return info, nil
+	}
+	
+	// Um FFmpeg antigo ainda é melhor que nenhum; EnsureFFmpeg tenta baixar
+	// um mais novo
+	if outdated != nil {
+		return outdated, nil
+	}
+	
+	// Sem FFprobe, ainda é possível comprimir usando apenas o FFmpeg
+	// (os metadados são extraídos da saída de "ffmpeg -i")
+	for _, candidate := range ffmpegOnlyCandidates(downloadedPath, systemCandidates) {
+		if err := testFFmpegInstallation(candidate.path, ""); err == nil {
+			return &FFmpegInfo{
+				Available:    true,
+				Path:         candidate.path,
+				Version:      getFFmpegVersion(candidate.path),
+				IsDownloaded: candidate.downloaded,
+				ProbeMissing: true,
+			}, nil
 		}
 	}
 	
@@ -107,6 +156,24 @@ func FindFFmpeg() (*FFmpegInfo, error) {
 	}, nil
 }
 
+type ffmpegCandidate struct {
+	path       string
+	probePath  string // FFprobe ao lado do FFmpeg, vazio se não houver
+	downloaded bool
+}
+
+// ffmpegOnlyCandidates lista os executáveis do FFmpeg que podem ser usados sem FFprobe
+func ffmpegOnlyCandidates(downloadedPath string, systemCandidates []ffmpegCandidate) []ffmpegCandidate {
+	candidates := []ffmpegCandidate{}
+	if fileExists(downloadedPath) {
+		candidates = append(candidates, ffmpegCandidate{path: downloadedPath, downloaded: true})
+	}
+	for _, candidate := range systemCandidates {
+		candidates = append(candidates, ffmpegCandidate{path: candidate.path})
+	}
+	return candidates
+}
+
 // EnsureFFmpeg garante que o FFmpeg está disponível, baixando se necessário
 func EnsureFFmpeg(logger *Logger) (*FFmpegInfo, error) {
 	// Tenta encontrar o FFmpeg
@@ -115,26 +182,46 @@ func EnsureFFmpeg(logger *Logger) (*FFmpegInfo, error) {
 		return nil, err
 	}
 	
+	// Um build fixado na configuração substitui qualquer outra instalação
+	pin := loadFFmpegPin(logger)
+	
 	// Se já está disponível, retorna
-	if info.Available {
-		logger.Info("FFmpeg encontrado: %s", info.Path)
+	if info.Available && pinMatches(pin, info.Build) && !info.Outdated && (info.IsDownloaded || !PreferDownloaded) {
+		logger.Info("FFmpeg found: %s", info.Path)
+		if info.ProbeMissing {
+			logger.Warning("FFprobe not found: video analysis will be limited (use 'repair-ffmpeg' to install it)")
+		}
 		return info, nil
 	}
 	
 	// FFmpeg não encontrado, baixar
-	logger.Info("FFmpeg não encontrado. Baixando automaticamente...")
+	switch {
+	case !info.Available:
+		logger.Info("FFmpeg not found. Downloading automatically...")
+	case pin.URL != "":
+		logger.Info("Pinned FFmpeg build is not installed, downloading %s...", pin.URL)
+	case info.Outdated:
+		logger.Info("System FFmpeg %s at %s is older than %s, downloading a newer build...", info.Version, info.Path, MinSystemFFmpegVersion)
+	default:
+		logger.Info("Downloading the FFmpeg build managed by CompressVideo (--prefer-downloaded)...")
+	}
 	ffmpegPath, ffprobePath, err := DownloadFFmpeg(logger)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao baixar FFmpeg: %v", err)
+		// Um FFmpeg antigo ainda serve se não há como baixar outro
+		if info.Outdated {
+			logger.Warning("Failed to download FFmpeg, using system FFmpeg %s: %v", info.Version, err)
+			return info, nil
+		}
+		return nil, i18n.Errorf("failed to download FFmpeg: %v", err)
 	}
 	
 	// Verificar se o FFmpeg foi baixado corretamente
 	if err := testFFmpegInstallation(ffmpegPath, ffprobePath); err != nil {
-		return nil, fmt.Errorf("erro ao verificar FFmpeg baixado: %v", err)
+		return nil, i18n.Errorf("failed to verify downloaded FFmpeg: %v", err)
 	}
 	
 	version := getFFmpegVersion(ffmpegPath)
-	logger.Success("FFmpeg baixado com sucesso: %s (versão %s)", ffmpegPath, version)
+	logger.Success("FFmpeg downloaded successfully: %s (version %s)", ffmpegPath, version)
 	
 	return &FFmpegInfo{
 		Available:    true,
@@ -142,32 +229,34 @@ func EnsureFFmpeg(logger *Logger) (*FFmpegInfo, error) {
 		FFprobePath:  ffprobePath,
 		Version:      version,
 		IsDownloaded: true,
+		Build:        readFFmpegBuild(),
 	}, nil
 }
 
 // RepairFFmpeg força o download e reinstalação do FFmpeg
 func RepairFFmpeg(logger *Logger) (*FFmpegInfo, error) {
-	// Remover a instalação existente
+	// Remover a instalação existente (apenas os binários: o diretório também
+	// guarda a configuração, com o build fixado, e o cache)
 	ffmpegDir := getFFmpegDir()
-	if err := os.RemoveAll(ffmpegDir); err != nil {
-		logger.Warning("Falha ao remover diretório do FFmpeg: %v", err)
+	if err := os.RemoveAll(filepath.Join(ffmpegDir, "bin")); err != nil {
+		logger.Warning("Failed to remove FFmpeg directory: %v", err)
 		// Continua mesmo se falhar a remoção
 	}
 
 	// Baixar novamente
-	logger.Info("Reinstalando FFmpeg...")
+	logger.Info("Reinstalling FFmpeg...")
 	ffmpegPath, ffprobePath, err := DownloadFFmpeg(logger)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao reinstalar FFmpeg: %v", err)
+		return nil, i18n.Errorf("failed to reinstall FFmpeg: %v", err)
 	}
 
 	// Verificar instalação
 	if err := testFFmpegInstallation(ffmpegPath, ffprobePath); err != nil {
-		return nil, fmt.Errorf("FFmpeg instalado, mas com problemas: %v", err)
+		return nil, i18n.Errorf("FFmpeg installed, but it has problems: %v", err)
 	}
 
 	version := getFFmpegVersion(ffmpegPath)
-	logger.Success("FFmpeg reinstalado com sucesso: %s (versão %s)", ffmpegPath, version)
+	logger.Success("FFmpeg reinstalled successfully: %s (version %s)", ffmpegPath, version)
 
 	return &FFmpegInfo{
 		Available:    true,
@@ -175,6 +264,7 @@ func RepairFFmpeg(logger *Logger) (*FFmpegInfo, error) {
 		FFprobePath:  ffprobePath,
 		Version:      version,
 		IsDownloaded: true,
+		Build:        readFFmpegBuild(),
 	}, nil
 }
 
@@ -182,35 +272,38 @@ func RepairFFmpeg(logger *Logger) (*FFmpegInfo, error) {
 func DownloadFFmpeg(logger *Logger) (string, string, error) {
 	osType := GetCurrentOS()
 	if osType == Unknown {
-		return "", "", fmt.Errorf("sistema operacional não suportado: %s", runtime.GOOS)
+		return "", "", i18n.Errorf("unsupported operating system: %s", runtime.GOOS)
 	}
 	
 	// Criar diretório para armazenar o FFmpeg
 	ffmpegDir := getFFmpegDir()
 	binDir := filepath.Join(ffmpegDir, "bin")
-	tempDir := filepath.Join(ffmpegDir, "temp")
-	
-	// Limpar diretório temporário, mas criar novos
-	os.RemoveAll(tempDir)
 	
 	if err := os.MkdirAll(binDir, 0755); err != nil {
-		return "", "", fmt.Errorf("falha ao criar diretório para FFmpeg: %v", err)
+		return "", "", i18n.Errorf("failed to create FFmpeg directory: %v", err)
 	}
-	if err := os.MkdirAll(tempDir, 0755); err != nil {
-		return "", "", fmt.Errorf("falha ao criar diretório temporário: %v", err)
+	
+	// Baixar num diretório temporário próprio, que 'compressvideo cleanup'
+	// remove se o download for interrompido
+	tempDir, err := NewJobTempDir()
+	if err != nil {
+		return "", "", i18n.Errorf("failed to create temporary directory: %v", err)
 	}
+	defer os.RemoveAll(tempDir)
 	
 	// Definir caminhos para os executáveis
 	ffmpegExe := filepath.Join(binDir, "ffmpeg"+GetExecutableExtension())
 	ffprobeExe := filepath.Join(binDir, "ffprobe"+GetExecutableExtension())
 	
+	pin := loadFFmpegPin(logger)
+	
 	// Se já existem e funcionam, retorna
-	if fileExists(ffmpegExe) && fileExists(ffprobeExe) {
+	if fileExists(ffmpegExe) && fileExists(ffprobeExe) && pinMatches(pin, readFFmpegBuild()) {
 		if err := testFFmpegInstallation(ffmpegExe, ffprobeExe); err == nil {
-			logger.Info("FFmpeg já instalado em: %s", ffmpegExe)
+			logger.Info("FFmpeg already installed at: %s", ffmpegExe)
 			return ffmpegExe, ffprobeExe, nil
 		} else {
-			logger.Warning("FFmpeg existente com problemas: %v", err)
+			logger.Warning("Existing FFmpeg has problems: %v", err)
 			// Vai tentar baixar novamente
 		}
 	}
@@ -218,44 +311,56 @@ func DownloadFFmpeg(logger *Logger) (string, string, error) {
 	// Baixar de múltiplas fontes
 	var lastError error
 	var archivePath string
+	var build *FFmpegBuild
 	
-	for _, url := range ffmpegMirrors[osType] {
-		logger.Info("Tentando baixar FFmpeg de: %s", url)
+	for _, source := range ffmpegSources(osType, pin) {
+		url := source.URL
+		logger.Info("Trying to download FFmpeg from: %s", url)
 		
 		archivePath = filepath.Join(tempDir, "ffmpeg-temp"+getArchiveExtension(url))
 		
-		// Tenta baixar com timeout
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		// Tenta baixar com timeout (só conexões paradas com DownloadLimit)
+		ctx, cancel := downloadContext(5*time.Minute)
 		err := downloadFileWithProgress(ctx, url, archivePath, logger)
 		cancel()
 		
 		if err != nil {
-			logger.Warning("Falha ao baixar de %s: %v", url, err)
+			logger.Warning("Failed to download from %s: %v", url, err)
 			lastError = err
 			continue
 		}
 		
 		// Verificar se o arquivo foi baixado corretamente
 		if !fileExists(archivePath) || getFileSize(archivePath) < 1000000 {
-			logger.Warning("Arquivo baixado inválido ou muito pequeno")
-			lastError = fmt.Errorf("arquivo baixado inválido")
+			logger.Warning("Downloaded file is invalid or too small")
+			lastError = i18n.Errorf("invalid downloaded file")
+			os.Remove(archivePath)
+			continue
+		}
+		
+		// Conferir a integridade antes de extrair
+		sum, verified, err := verifyDownload(source, archivePath, logger)
+		if err != nil {
+			logger.Warning("Integrity check failed for %s: %v", url, err)
+			lastError = err
+			os.Remove(archivePath)
 			continue
 		}
 		
 		// Sucesso no download, parar de tentar
-		logger.Info("Download concluído com sucesso")
+		logger.Info("Download completed successfully")
+		build = &FFmpegBuild{URL: url, SHA256: sum, Verified: verified}
 		break
 	}
 	
 	// Se não conseguiu baixar de nenhuma fonte
-	if archivePath == "" || !fileExists(archivePath) {
-		return "", "", fmt.Errorf("falha ao baixar FFmpeg de todas as fontes: %v", lastError)
+	if build == nil {
+		return "", "", i18n.Errorf("failed to download FFmpeg from every source: %v", lastError)
 	}
 	
 	// Extrair o arquivo baixado
-	logger.Info("Extraindo FFmpeg...")
+	logger.Info("Extracting FFmpeg...")
 	
-	var err error
 	switch osType {
 	case Windows:
 		err = extractWindowsFFmpeg(archivePath, binDir, logger)
@@ -266,24 +371,24 @@ func DownloadFFmpeg(logger *Logger) (string, string, error) {
 	}
 	
 	if err != nil {
-		return "", "", fmt.Errorf("falha ao extrair FFmpeg: %v", err)
+		return "", "", i18n.Errorf("failed to extract FFmpeg: %v", err)
 	}
 	
 	// Verificar se os executáveis existem
 	if !fileExists(ffmpegExe) {
-		return "", "", fmt.Errorf("ffmpeg não encontrado após extração")
+		return "", "", i18n.Errorf("ffmpeg not found after extraction")
 	}
 	
 	if !fileExists(ffprobeExe) {
 		// No macOS podemos precisar baixar o FFprobe separadamente
 		if osType == MacOS {
-			logger.Info("FFprobe não encontrado, tentando baixar separadamente...")
+			logger.Info("FFprobe not found, trying to download it separately...")
 			err = downloadMacOSFFprobe(binDir, logger)
 			if err != nil {
-				return "", "", fmt.Errorf("falha ao baixar FFprobe para macOS: %v", err)
+				return "", "", i18n.Errorf("failed to download FFprobe for macOS: %v", err)
 			}
 		} else {
-			return "", "", fmt.Errorf("ffprobe não encontrado após extração")
+			return "", "", i18n.Errorf("ffprobe not found after extraction")
 		}
 	}
 	
@@ -295,11 +400,15 @@ func DownloadFFmpeg(logger *Logger) (string, string, error) {
 	
 	// Testar instalação
 	if err := testFFmpegInstallation(ffmpegExe, ffprobeExe); err != nil {
-		return "", "", fmt.Errorf("FFmpeg instalado, mas com problemas: %v", err)
+		return "", "", i18n.Errorf("FFmpeg installed, but it has problems: %v", err)
 	}
 	
-	// Limpar arquivos temporários
-	os.RemoveAll(tempDir)
+	// Registrar o build instalado
+	build.Version = getFFmpegVersion(ffmpegExe)
+	build.InstalledAt = time.Now()
+	if err := writeFFmpegBuild(build); err != nil {
+		logger.Warning("Failed to record FFmpeg build: %v", err)
+	}
 	
 	return ffmpegExe, ffprobeExe, nil
 }
@@ -307,11 +416,11 @@ func DownloadFFmpeg(logger *Logger) (string, string, error) {
 // Função específica para extrair FFmpeg no Windows
 func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 	// Ler o arquivo zip
-	logger.Info("Extraindo arquivo zip em: %s", destDir)
+	logger.Info("Extracting zip file to: %s", destDir)
 	
 	archive, err := zip.OpenReader(zipPath)
 	if err != nil {
-		return fmt.Errorf("falha ao abrir arquivo zip: %v", err)
+		return i18n.Errorf("failed to open zip file: %v", err)
 	}
 	defer archive.Close()
 	
@@ -326,7 +435,7 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 			// Abrir o arquivo do zip
 			srcFile, err := file.Open()
 			if err != nil {
-				logger.Warning("Falha ao abrir %s do zip: %v", fileName, err)
+				logger.Warning("Failed to open %s from zip: %v", fileName, err)
 				continue
 			}
 			
@@ -335,7 +444,7 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 			destFile, err := os.Create(destPath)
 			if err != nil {
 				srcFile.Close()
-				logger.Warning("Falha ao criar %s: %v", destPath, err)
+				logger.Warning("Failed to create %s: %v", destPath, err)
 				continue
 			}
 			
@@ -345,16 +454,16 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 			destFile.Close()
 			
 			if err != nil {
-				logger.Warning("Falha ao copiar %s: %v", fileName, err)
+				logger.Warning("Failed to copy %s: %v", fileName, err)
 				continue
 			}
 			
 			if strings.EqualFold(fileName, "ffmpeg.exe") {
 				ffmpegFound = true
-				logger.Info("FFmpeg extraído para: %s", destPath)
+				logger.Info("FFmpeg extracted to: %s", destPath)
 			} else {
 				ffprobeFound = true
-				logger.Info("FFprobe extraído para: %s", destPath)
+				logger.Info("FFprobe extracted to: %s", destPath)
 			}
 			
 			// Se encontrou ambos, pode parar
@@ -369,7 +478,7 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 		searchDirs := []string{"bin", "ffmpeg-*-win64-static/bin", "ffmpeg-*-essentials_build/bin"}
 		
 		for _, searchPath := range searchDirs {
-			logger.Debug("Procurando executáveis em: %s", searchPath)
+			logger.Debug("Looking for executables in: %s", searchPath)
 			
 			for _, file := range archive.File {
 				if !strings.Contains(strings.ToLower(file.Name), strings.ToLower(searchPath)) {
@@ -381,7 +490,7 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 					// Extrair o arquivo
 					srcFile, err := file.Open()
 					if err != nil {
-						logger.Warning("Falha ao abrir %s do zip: %v", fileName, err)
+						logger.Warning("Failed to open %s from zip: %v", fileName, err)
 						continue
 					}
 					
@@ -389,7 +498,7 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 					destFile, err := os.Create(destPath)
 					if err != nil {
 						srcFile.Close()
-						logger.Warning("Falha ao criar %s: %v", destPath, err)
+						logger.Warning("Failed to create %s: %v", destPath, err)
 						continue
 					}
 					
@@ -398,16 +507,16 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 					destFile.Close()
 					
 					if err != nil {
-						logger.Warning("Falha ao copiar %s: %v", fileName, err)
+						logger.Warning("Failed to copy %s: %v", fileName, err)
 						continue
 					}
 					
 					if strings.EqualFold(fileName, "ffmpeg.exe") {
 						ffmpegFound = true
-						logger.Info("FFmpeg extraído para: %s", destPath)
+						logger.Info("FFmpeg extracted to: %s", destPath)
 					} else {
 						ffprobeFound = true
-						logger.Info("FFprobe extraído para: %s", destPath)
+						logger.Info("FFprobe extracted to: %s", destPath)
 					}
 					
 					if ffmpegFound && ffprobeFound {
@@ -423,11 +532,11 @@ func extractWindowsFFmpeg(zipPath, destDir string, logger *Logger) error {
 	}
 	
 	if !ffmpegFound {
-		return fmt.Errorf("ffmpeg.exe não encontrado no arquivo zip")
+		return i18n.Errorf("ffmpeg.exe not found in zip file")
 	}
 	
 	if !ffprobeFound {
-		return fmt.Errorf("ffprobe.exe não encontrado no arquivo zip")
+		return i18n.Errorf("ffprobe.exe not found in zip file")
 	}
 	
 	return nil
@@ -438,7 +547,7 @@ func extractLinuxFFmpeg(archivePath, destDir string, logger *Logger) error {
 	// No Linux, normalmente é um arquivo tar.xz ou tar.gz
 	// Usar o comando tar externo é mais fácil e confiável
 	
-	logger.Info("Extraindo arquivo para: %s", destDir)
+	logger.Info("Extracting archive to: %s", destDir)
 	
 	// Criar diretório temporário para extração
 	tempExtractDir := filepath.Join(filepath.Dir(archivePath), "extracted")
@@ -451,12 +560,12 @@ func extractLinuxFFmpeg(archivePath, destDir string, logger *Logger) error {
 	} else if strings.HasSuffix(archivePath, ".tar.gz") {
 		cmd = exec.Command("tar", "-xzf", archivePath, "-C", tempExtractDir)
 	} else {
-		return fmt.Errorf("formato de arquivo não suportado: %s", archivePath)
+		return i18n.Errorf("unsupported archive format: %s", archivePath)
 	}
 	
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("falha ao extrair com tar: %v (output: %s)", err, string(output))
+		return i18n.Errorf("failed to extract with tar: %v (output: %s)", err, string(output))
 	}
 	
 	// Procurar os executáveis ffmpeg e ffprobe
@@ -472,12 +581,12 @@ func extractLinuxFFmpeg(archivePath, destDir string, logger *Logger) error {
 				destPath := filepath.Join(destDir, "ffmpeg")
 				copyFile(path, destPath)
 				os.Chmod(destPath, 0755)
-				logger.Info("FFmpeg copiado para: %s", destPath)
+				logger.Info("FFmpeg copied to: %s", destPath)
 			} else if fileName == "ffprobe" {
 				destPath := filepath.Join(destDir, "ffprobe")
 				copyFile(path, destPath)
 				os.Chmod(destPath, 0755)
-				logger.Info("FFprobe copiado para: %s", destPath)
+				logger.Info("FFprobe copied to: %s", destPath)
 			}
 		}
 		
@@ -485,16 +594,16 @@ func extractLinuxFFmpeg(archivePath, destDir string, logger *Logger) error {
 	})
 	
 	if err != nil {
-		return fmt.Errorf("erro ao procurar executáveis: %v", err)
+		return i18n.Errorf("failed to search for executables: %v", err)
 	}
 	
 	// Verificar se os arquivos foram copiados
 	if !fileExists(filepath.Join(destDir, "ffmpeg")) {
-		return fmt.Errorf("ffmpeg não encontrado após extração")
+		return i18n.Errorf("ffmpeg not found after extraction")
 	}
 	
 	if !fileExists(filepath.Join(destDir, "ffprobe")) {
-		return fmt.Errorf("ffprobe não encontrado após extração")
+		return i18n.Errorf("ffprobe not found after extraction")
 	}
 	
 	return nil
@@ -504,11 +613,11 @@ func extractLinuxFFmpeg(archivePath, destDir string, logger *Logger) error {
 func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 	// No macOS, geralmente é um zip com um único executável
 	
-	logger.Info("Extraindo FFmpeg para macOS em: %s", destDir)
+	logger.Info("Extracting FFmpeg for macOS to: %s", destDir)
 	
 	archive, err := zip.OpenReader(archivePath)
 	if err != nil {
-		return fmt.Errorf("falha ao abrir arquivo zip: %v", err)
+		return i18n.Errorf("failed to open zip file: %v", err)
 	}
 	defer archive.Close()
 	
@@ -517,7 +626,7 @@ func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 		// Extrair todos os arquivos (normalmente é apenas um)
 		srcFile, err := file.Open()
 		if err != nil {
-			logger.Warning("Falha ao abrir arquivo do zip: %v", err)
+			logger.Warning("Failed to open file from zip: %v", err)
 			continue
 		}
 		
@@ -525,7 +634,7 @@ func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 		destFile, err := os.Create(destPath)
 		if err != nil {
 			srcFile.Close()
-			logger.Warning("Falha ao criar arquivo: %v", err)
+			logger.Warning("Failed to create file: %v", err)
 			continue
 		}
 		
@@ -534,7 +643,7 @@ func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 		destFile.Close()
 		
 		if err != nil {
-			logger.Warning("Falha ao copiar arquivo: %v", err)
+			logger.Warning("Failed to copy file: %v", err)
 			continue
 		}
 		
@@ -542,7 +651,7 @@ func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 		if strings.HasPrefix(file.Name, "ffmpeg") {
 			os.Rename(destPath, filepath.Join(destDir, "ffmpeg"))
 			os.Chmod(filepath.Join(destDir, "ffmpeg"), 0755)
-			logger.Info("FFmpeg extraído para: %s", filepath.Join(destDir, "ffmpeg"))
+			logger.Info("FFmpeg extracted to: %s", filepath.Join(destDir, "ffmpeg"))
 		}
 	}
 	
@@ -553,10 +662,13 @@ func extractMacOSFFmpeg(archivePath, destDir string, logger *Logger) error {
 func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 	// No macOS, FFprobe é frequentemente distribuído separadamente
 	
-	logger.Info("Baixando FFprobe para macOS...")
+	logger.Info("Downloading FFprobe for macOS...")
 	
-	tempDir := filepath.Join(destDir, "temp")
-	os.MkdirAll(tempDir, 0755)
+	tempDir, err := NewJobTempDir()
+	if err != nil {
+		return i18n.Errorf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
 	
 	archivePath := filepath.Join(tempDir, "ffprobe.zip")
 	
@@ -566,14 +678,14 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 		"https://evermeet.cx/ffmpeg/getrelease/ffprobe/zip",
 		"https://evermeet.cx/ffmpeg/ffprobe-5.1.zip",
 	} {
-		logger.Info("Tentando baixar FFprobe de: %s", url)
+		logger.Info("Trying to download FFprobe from: %s", url)
 		
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		ctx, cancel := downloadContext(2*time.Minute)
 		err := downloadFileWithProgress(ctx, url, archivePath, logger)
 		cancel()
 		
 		if err != nil {
-			logger.Warning("Falha ao baixar de %s: %v", url, err)
+			logger.Warning("Failed to download from %s: %v", url, err)
 			lastError = err
 			continue
 		}
@@ -581,7 +693,7 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 		// Extrair o arquivo zip
 		archive, err := zip.OpenReader(archivePath)
 		if err != nil {
-			logger.Warning("Falha ao abrir arquivo zip: %v", err)
+			logger.Warning("Failed to open zip file: %v", err)
 			lastError = err
 			continue
 		}
@@ -590,7 +702,7 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 			// Extrair todos os arquivos
 			srcFile, err := file.Open()
 			if err != nil {
-				logger.Warning("Falha ao abrir arquivo do zip: %v", err)
+				logger.Warning("Failed to open file from zip: %v", err)
 				continue
 			}
 			
@@ -598,7 +710,7 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 			destFile, err := os.Create(destPath)
 			if err != nil {
 				srcFile.Close()
-				logger.Warning("Falha ao criar arquivo: %v", err)
+				logger.Warning("Failed to create file: %v", err)
 				continue
 			}
 			
@@ -607,7 +719,7 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 			destFile.Close()
 			
 			if err != nil {
-				logger.Warning("Falha ao copiar arquivo: %v", err)
+				logger.Warning("Failed to copy file: %v", err)
 				continue
 			}
 			
@@ -615,7 +727,7 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 			if strings.HasPrefix(file.Name, "ffprobe") {
 				os.Rename(destPath, filepath.Join(destDir, "ffprobe"))
 				os.Chmod(filepath.Join(destDir, "ffprobe"), 0755)
-				logger.Info("FFprobe extraído para: %s", filepath.Join(destDir, "ffprobe"))
+				logger.Info("FFprobe extracted to: %s", filepath.Join(destDir, "ffprobe"))
 				
 				// FFprobe baixado e extraído com sucesso
 				archive.Close()
@@ -626,75 +738,89 @@ func downloadMacOSFFprobe(destDir string, logger *Logger) error {
 		archive.Close()
 	}
 	
-	return fmt.Errorf("falha ao baixar FFprobe: %v", lastError)
+	return i18n.Errorf("failed to download FFprobe: %v", lastError)
 }
 
 // Helpers
 
-// Baixar arquivo com barra de progresso
+// Baixar arquivo com barra de progresso, limitado a DownloadLimit e
+// retomado com requisições Range quando a conexão cai no meio
 func downloadFileWithProgress(ctx context.Context, url, destPath string, logger *Logger) error {
 	// Criar diretório de destino se não existir
 	err := os.MkdirAll(filepath.Dir(destPath), 0755)
 	if err != nil {
-		return fmt.Errorf("falha ao criar diretório: %v", err)
-	}
-	
-	// Criar request com context para timeout e cancelamento
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("erro ao criar request: %v", err)
-	}
-	
-	// Adicionar User-Agent para evitar 403 Forbidden
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-	
-	// Fazer request com cliente HTTP
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
-	
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("erro ao fazer download: %v", err)
-	}
-	defer resp.Body.Close()
-	
-	// Verificar status code
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status code inválido: %d", resp.StatusCode)
+		return i18n.Errorf("failed to create directory: %v", err)
 	}
 	
 	// Criar arquivo de destino
 	out, err := os.Create(destPath)
 	if err != nil {
-		return fmt.Errorf("erro ao criar arquivo: %v", err)
+		return i18n.Errorf("failed to create file: %v", err)
 	}
 	defer out.Close()
 	
-	// Configurar barra de progresso
-	fileSize := resp.ContentLength
-	
-	progressOptions := ProgressTrackerOptions{
-		Total:       fileSize,
-		Description: "Baixando FFmpeg",
-		Logger:      logger,
-		ShowBytes:   true,
-		ShowSpeed:   true,
-	}
+	// O tempo total é limitado pelo contexto; conexões paradas, pelo
+	// downloadIdleTimeout de cada tentativa
+	client := &http.Client{}
 	
-	progress := NewProgressTrackerWithOptions(progressOptions)
-	
-	// Configurar reader com progresso
-	progressReader := &progressReader{
-		reader:  resp.Body,
-		tracker: progress,
-		total:   fileSize,
-	}
-	
-	// Copiar dados com buffer
-	_, err = io.Copy(out, progressReader)
-	if err != nil {
-		return fmt.Errorf("erro ao salvar arquivo: %v", err)
+	var progress *ProgressTracker
+	var written int64
+	for resumes := 0; ; resumes++ {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		resp, start, total, err := downloadRange(attemptCtx, client, url, written)
+		if err != nil {
+			cancel()
+			return err
+		}
+		
+		// Servidor sem suporte a Range: recomeçar do início
+		if written > 0 && start == 0 {
+			logger.Warning("Server does not support resuming downloads, starting over")
+			if err := truncateDownload(out); err != nil {
+				resp.Body.Close()
+				cancel()
+				return i18n.Errorf("failed to save file: %v", err)
+			}
+			written = 0
+			progress.Restart()
+		}
+		
+		// Configurar barra de progresso
+		if progress == nil {
+			progress = NewProgressTrackerWithOptions(ProgressTrackerOptions{
+				Total:       total,
+				Description: i18n.Tr("Downloading FFmpeg"),
+				Logger:      logger,
+				ShowBytes:   true,
+				ShowSpeed:   true,
+			})
+		}
+		
+		// Configurar reader com progresso e limite de velocidade
+		var reader io.Reader = newIdleReader(resp.Body, downloadIdleTimeout, cancel)
+		if DownloadLimit > 0 {
+			reader = newRateLimitedReader(attemptCtx, reader, DownloadLimit)
+		}
+		reader = &progressReader{
+			reader:  reader,
+			tracker: progress,
+			read:    written,
+		}
+		
+		// Copiar dados com buffer
+		n, err := io.Copy(out, reader)
+		resp.Body.Close()
+		cancel()
+		written += n
+		if err == nil {
+			break
+		}
+		
+		// Retomar de onde parou se a conexão caiu depois de receber dados
+		if ctx.Err() != nil || n == 0 || resumes == downloadResumes {
+			return i18n.Errorf("failed to save file: %v", err)
+		}
+		logger.Warning("Download interrupted after %s, resuming: %v", FormatSize(written), err)
 	}
 	
 	progress.Finish()
@@ -719,25 +845,30 @@ func getArchiveExtension(url string) string {
 func testFFmpegInstallation(ffmpegPath, ffprobePath string) error {
 	// Verificar se os executáveis existem
 	if !fileExists(ffmpegPath) {
-		return fmt.Errorf("executável do FFmpeg não encontrado: %s", ffmpegPath)
+		return i18n.Errorf("FFmpeg executable not found: %s", ffmpegPath)
 	}
 	
-	if !fileExists(ffprobePath) {
-		return fmt.Errorf("executável do FFprobe não encontrado: %s", ffprobePath)
+	// Um caminho vazio indica modo sem FFprobe
+	if ffprobePath != "" && !fileExists(ffprobePath) {
+		return i18n.Errorf("FFprobe executable not found: %s", ffprobePath)
 	}
 	
 	// Testar execução do FFmpeg
 	cmd := exec.Command(ffmpegPath, "-version")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("erro ao executar FFmpeg: %v (output: %s)", err, string(output))
+		return i18n.Errorf("failed to run FFmpeg: %v (output: %s)", err, string(output))
+	}
+	
+	if ffprobePath == "" {
+		return nil
 	}
 	
 	// Testar execução do FFprobe
 	cmd = exec.Command(ffprobePath, "-version")
 	output, err = cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("erro ao executar FFprobe: %v (output: %s)", err, string(output))
+		return i18n.Errorf("failed to run FFprobe: %v (output: %s)", err, string(output))
 	}
 	
 	return nil
@@ -802,13 +933,14 @@ func getFFmpegVersion(ffmpegPath string) string {
 type progressReader struct {
 	reader  io.Reader
 	tracker *ProgressTracker
-	total   int64
+	read    int64 // Bytes baixados, incluindo os de tentativas anteriores
 }
 
 func (pr *progressReader) Read(p []byte) (int, error) {
 	n, err := pr.reader.Read(p)
 	if n > 0 {
-		pr.tracker.Update(pr.tracker.lastProgress + int64(n))
+		pr.read += int64(n)
+		pr.tracker.Update(pr.read)
 	}
 	return n, err
 } 
\ No newline at end of file
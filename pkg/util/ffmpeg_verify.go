@@ -0,0 +1,197 @@
+package util
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/cccarv82/compressvideo/pkg/i18n"
+)
+
+// ffmpegSource é um arquivo do FFmpeg para download e a forma de verificá-lo
+type ffmpegSource struct {
+	URL         string // Arquivo a ser baixado
+	ChecksumURL string // Lista SHA-256 publicada pelo mirror, se houver
+	SHA256      string // Checksum esperado (build fixado na configuração)
+}
+
+// FFmpegBuild registra qual build do FFmpeg foi baixado, para reprodutibilidade
+type FFmpegBuild struct {
+	URL         string    `json:"url"`          // Origem do arquivo baixado
+	SHA256      string    `json:"sha256"`       // SHA-256 do arquivo baixado
+	Verified    bool      `json:"verified"`     // Se o checksum foi conferido com um valor publicado ou fixado
+	Version     string    `json:"version"`      // Versão reportada pelo FFmpeg
+	InstalledAt time.Time `json:"installed_at"` // Data da instalação
+}
+
+// ffmpegBuildFile é onde o registro do build instalado é salvo
+func ffmpegBuildFile() string {
+	return filepath.Join(getFFmpegDir(), "bin", "ffmpeg-build.json")
+}
+
+// readFFmpegBuild lê o registro do build baixado, se existir
+func readFFmpegBuild() *FFmpegBuild {
+	data, err := os.ReadFile(ffmpegBuildFile())
+	if err != nil {
+		return nil
+	}
+	build := &FFmpegBuild{}
+	if err := json.Unmarshal(data, build); err != nil {
+		return nil
+	}
+	return build
+}
+
+// writeFFmpegBuild salva o registro do build baixado
+func writeFFmpegBuild(build *FFmpegBuild) error {
+	data, err := json.MarshalIndent(build, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ffmpegBuildFile(), data, 0644)
+}
+
+// loadFFmpegPin lê o build fixado em ~/.compressvideo/config.yaml
+func loadFFmpegPin(logger *Logger) config.FFmpegConfig {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		logger.Warning("%v", err)
+		return config.FFmpegConfig{}
+	}
+	return cfg.FFmpeg
+}
+
+// pinMatches informa se o build instalado corresponde ao build fixado
+func pinMatches(pin config.FFmpegConfig, build *FFmpegBuild) bool {
+	if pin.URL == "" {
+		return true
+	}
+	if build == nil || build.URL != pin.URL {
+		return false
+	}
+	return pin.SHA256 == "" || strings.EqualFold(build.SHA256, pin.SHA256)
+}
+
+// ffmpegSources retorna as fontes de download: apenas o build fixado, se houver,
+// ou os mirrors conhecidos para o sistema
+func ffmpegSources(osType OSType, pin config.FFmpegConfig) []ffmpegSource {
+	if pin.URL != "" {
+		return []ffmpegSource{{URL: pin.URL, SHA256: strings.ToLower(pin.SHA256)}}
+	}
+	return ffmpegMirrors[osType]
+}
+
+// verifyDownload confere o SHA-256 do arquivo baixado. Retorna o checksum
+// calculado e se ele foi comparado com um valor esperado.
+func verifyDownload(source ffmpegSource, archivePath string, logger *Logger) (string, bool, error) {
+	actual, err := sha256File(archivePath)
+	if err != nil {
+		return "", false, err
+	}
+
+	expected := source.SHA256
+	if expected == "" && source.ChecksumURL != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		expected, err = fetchPublishedChecksum(ctx, source.ChecksumURL, archiveName(source.URL))
+		if err != nil {
+			return actual, false, i18n.Errorf("failed to get published checksum: %v", err)
+		}
+	}
+
+	if expected == "" {
+		logger.Warning("No published checksum for %s; download not verified (SHA-256 %s)", source.URL, actual)
+		return actual, false, nil
+	}
+	if !strings.EqualFold(expected, actual) {
+		return actual, false, i18n.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+
+	logger.Info("SHA-256 verified: %s", actual)
+	return actual, true, nil
+}
+
+// sha256File calcula o SHA-256 de um arquivo
+func sha256File(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fetchPublishedChecksum baixa uma lista de checksums e retorna o do arquivo indicado
+func fetchPublishedChecksum(ctx context.Context, checksumURL, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", checksumURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", i18n.Errorf("invalid status code: %d", resp.StatusCode)
+	}
+
+	// Listas de checksums são pequenas; limita a leitura por segurança
+	return parseChecksumList(io.LimitReader(resp.Body, 1<<20), name)
+}
+
+// parseChecksumList lê o formato do sha256sum ("<hash>  <arquivo>") ou um
+// arquivo com apenas o hash
+func parseChecksumList(r io.Reader, name string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || !isSHA256(fields[0]) {
+			continue
+		}
+		if len(fields) == 1 {
+			return strings.ToLower(fields[0]), nil
+		}
+		if path.Base(strings.TrimPrefix(fields[1], "*")) == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", i18n.Errorf("no checksum for %s in list", name)
+}
+
+// isSHA256 informa se s é um hash SHA-256 em hexadecimal
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// archiveName retorna o nome do arquivo de uma URL de download
+func archiveName(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		return path.Base(parsed.Path)
+	}
+	return path.Base(rawURL)
+}
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cccarv82/compressvideo/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	sumA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	sumB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func TestParseChecksumList(t *testing.T) {
+	list := sumA + "  ffmpeg-master-latest-win64-gpl.zip\n" +
+		sumB + " *ffmpeg-master-latest-linux64-gpl.tar.xz\n"
+
+	sum, err := parseChecksumList(strings.NewReader(list), "ffmpeg-master-latest-linux64-gpl.tar.xz")
+	assert.NoError(t, err)
+	assert.Equal(t, sumB, sum)
+
+	_, err = parseChecksumList(strings.NewReader(list), "other.zip")
+	assert.Error(t, err)
+
+	// A .sha256 file with only the hash
+	sum, err = parseChecksumList(strings.NewReader(strings.ToUpper(sumA)+"\n"), "any.zip")
+	assert.NoError(t, err)
+	assert.Equal(t, sumA, sum)
+}
+
+func TestVerifyDownload(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "ffmpeg.zip")
+	assert.NoError(t, os.WriteFile(archive, []byte("hello"), 0644))
+	// sha256("hello")
+	expected := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"
+
+	logger := NewLogger(false)
+	logger.SetLevel(LogLevelError)
+
+	sum, verified, err := verifyDownload(ffmpegSource{URL: "https://example.com/ffmpeg.zip", SHA256: expected}, archive, logger)
+	assert.NoError(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, expected, sum)
+
+	_, _, err = verifyDownload(ffmpegSource{URL: "https://example.com/ffmpeg.zip", SHA256: sumA}, archive, logger)
+	assert.Error(t, err)
+
+	// Without a published checksum the download is accepted but not verified
+	sum, verified, err = verifyDownload(ffmpegSource{URL: "https://example.com/ffmpeg.zip"}, archive, logger)
+	assert.NoError(t, err)
+	assert.False(t, verified)
+	assert.Equal(t, expected, sum)
+}
+
+func TestPinMatches(t *testing.T) {
+	build := &FFmpegBuild{URL: "https://example.com/ffmpeg.zip", SHA256: sumA}
+
+	assert.True(t, pinMatches(config.FFmpegConfig{}, nil))
+	assert.True(t, pinMatches(config.FFmpegConfig{URL: build.URL}, build))
+	assert.True(t, pinMatches(config.FFmpegConfig{URL: build.URL, SHA256: strings.ToUpper(sumA)}, build))
+	assert.False(t, pinMatches(config.FFmpegConfig{URL: build.URL, SHA256: sumB}, build))
+	assert.False(t, pinMatches(config.FFmpegConfig{URL: "https://example.com/other.zip"}, build))
+	assert.False(t, pinMatches(config.FFmpegConfig{URL: build.URL}, nil))
+}
+
+func TestArchiveName(t *testing.T) {
+	assert.Equal(t, "ffmpeg-release-essentials.zip", archiveName("https://www.gyan.dev/ffmpeg/builds/ffmpeg-release-essentials.zip"))
+	assert.Equal(t, "build.tar.xz", archiveName("https://example.com/build.tar.xz?token=1"))
+}
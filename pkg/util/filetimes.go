@@ -0,0 +1,25 @@
+package util
+
+import (
+	"os"
+	"runtime"
+)
+
+// PreserveFileAttributes copies the access and modification times of src to
+// dst and, on Unix, its permission bits, so a compressed file keeps its place
+// in date-sorted media libraries and backup tools do not see it as new
+func PreserveFileAttributes(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	// Windows only maps the read-only attribute to a mode, skip it there
+	if runtime.GOOS != "windows" {
+		if err := os.Chmod(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+
+	return os.Chtimes(dst, accessTime(info), info.ModTime())
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreserveFileAttributes(t *testing.T) {
+	dir, err := os.MkdirTemp("", "filetimes")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "source.mp4")
+	dst := filepath.Join(dir, "output.mp4")
+	assert.NoError(t, os.WriteFile(src, []byte("source"), 0640))
+	assert.NoError(t, os.WriteFile(dst, []byte("out"), 0644))
+
+	atime := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	mtime := time.Date(2019, 6, 7, 8, 9, 10, 0, time.UTC)
+	assert.NoError(t, os.Chtimes(src, atime, mtime))
+
+	assert.NoError(t, PreserveFileAttributes(src, dst))
+
+	info, err := os.Stat(dst)
+	assert.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(mtime))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	}
+	if runtime.GOOS == "linux" {
+		assert.True(t, accessTime(info).Equal(atime))
+	}
+
+	assert.Error(t, PreserveFileAttributes(filepath.Join(dir, "missing.mp4"), dst))
+}
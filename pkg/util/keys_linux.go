@@ -0,0 +1,11 @@
+package util
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// Requests that read and change the terminal settings
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
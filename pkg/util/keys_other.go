@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly && !windows
+
+package util
+
+import (
+	"errors"
+	"os"
+)
+
+// EnableKeyPresses is not supported on this platform
+func EnableKeyPresses(f *os.File) (restore func(), err error) {
+	return nil, errors.New("key presses are not supported on this platform")
+}
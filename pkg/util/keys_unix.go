@@ -0,0 +1,30 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// EnableKeyPresses switches the terminal f to deliver key presses one at a
+// time without echoing them, so single keys can control a running encode.
+// Ctrl+C and the output of the tool are unaffected. The returned function
+// restores the previous terminal settings.
+func EnableKeyPresses(f *os.File) (restore func(), err error) {
+	fd := int(f.Fd())
+	previous, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, err
+	}
+
+	termios := *previous
+	termios.Lflag &^= unix.ICANON | unix.ECHO
+	termios.Cc[unix.VMIN] = 1
+	termios.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &termios); err != nil {
+		return nil, err
+	}
+	return func() { unix.IoctlSetTermios(fd, ioctlSetTermios, previous) }, nil
+}
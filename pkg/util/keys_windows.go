@@ -0,0 +1,27 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// EnableKeyPresses switches the console f to deliver key presses one at a
+// time without echoing them, so single keys can control a running encode.
+// Ctrl+C and the output of the tool are unaffected. The returned function
+// restores the previous console mode.
+func EnableKeyPresses(f *os.File) (restore func(), err error) {
+	handle := windows.Handle(f.Fd())
+	var previous uint32
+	if err := windows.GetConsoleMode(handle, &previous); err != nil {
+		return nil, err
+	}
+
+	mode := previous &^ (windows.ENABLE_LINE_INPUT | windows.ENABLE_ECHO_INPUT)
+	if err := windows.SetConsoleMode(handle, mode); err != nil {
+		return nil, err
+	}
+	return func() { windows.SetConsoleMode(handle, previous) }, nil
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// cpuZoneNames are parts of the names Linux gives to the thermal sensors of
+// the processor
+var cpuZoneNames = []string{"cpu", "x86_pkg_temp", "coretemp", "k10temp", "zenpower", "soc", "package"}
+
+// hottestZone returns the highest temperature in °C of the thermal zones
+// under dir, laid out like /sys/class/thermal: thermal_zone*/type names the
+// sensor and thermal_zone*/temp holds millidegrees. Processor zones are
+// preferred; without any, every zone counts.
+func hottestZone(dir string) (float64, error) {
+	zones, _ := filepath.Glob(filepath.Join(dir, "thermal_zone*"))
+	cpu, any := -1.0, -1.0
+	for _, zone := range zones {
+		data, err := os.ReadFile(filepath.Join(zone, "temp"))
+		if err != nil {
+			continue
+		}
+		milli, err := strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+		if err != nil || milli <= 0 {
+			continue
+		}
+		celsius := milli / 1000
+		if celsius > any {
+			any = celsius
+		}
+		kind, _ := os.ReadFile(filepath.Join(zone, "type"))
+		for _, name := range cpuZoneNames {
+			if strings.Contains(strings.ToLower(string(kind)), name) && celsius > cpu {
+				cpu = celsius
+			}
+		}
+	}
+	switch {
+	case cpu >= 0:
+		return cpu, nil
+	case any >= 0:
+		return any, nil
+	}
+	return 0, errors.New("no thermal sensor found")
+}
@@ -0,0 +1,32 @@
+package util
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/sys/unix"
+)
+
+// LoadAverage returns the load average of the last minute: the processes
+// running or waiting for a CPU core
+func LoadAverage() (float64, error) {
+	// struct loadavg: three fixed-point averages, then their scale
+	raw, err := unix.SysctlRaw("vm.loadavg")
+	if err != nil {
+		return 0, err
+	}
+	if len(raw) < 24 {
+		return 0, errors.New("unexpected vm.loadavg")
+	}
+	scale := binary.LittleEndian.Uint64(raw[16:24])
+	if scale == 0 {
+		return 0, errors.New("unexpected vm.loadavg")
+	}
+	return float64(binary.LittleEndian.Uint32(raw[0:4])) / float64(scale), nil
+}
+
+// CPUTemperature is not available on macOS without privileged access to
+// the SMC; callers treat the temperature as unknown
+func CPUTemperature() (float64, error) {
+	return 0, errors.New("the CPU temperature cannot be read on this system")
+}
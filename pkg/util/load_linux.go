@@ -0,0 +1,23 @@
+//go:build linux
+
+package util
+
+import (
+	"syscall"
+)
+
+// LoadAverage returns the load average of the last minute: the processes
+// running or waiting for a CPU core
+func LoadAverage() (float64, error) {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0, err
+	}
+	return float64(info.Loads[0]) / (1 << 16), nil
+}
+
+// CPUTemperature returns the temperature of the processor in °C, from the
+// kernel's thermal zones
+func CPUTemperature() (float64, error) {
+	return hottestZone("/sys/class/thermal")
+}
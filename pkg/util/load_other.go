@@ -0,0 +1,19 @@
+//go:build !linux && !darwin && !windows
+
+package util
+
+import (
+	"errors"
+)
+
+// LoadAverage is not supported on this system; callers treat the load as
+// unknown
+func LoadAverage() (float64, error) {
+	return 0, errors.New("the load average cannot be read on this system")
+}
+
+// CPUTemperature is not supported on this system; callers treat the
+// temperature as unknown
+func CPUTemperature() (float64, error) {
+	return 0, errors.New("the CPU temperature cannot be read on this system")
+}
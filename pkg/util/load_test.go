@@ -0,0 +1,45 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHottestZone(t *testing.T) {
+	dir := t.TempDir()
+	zone := func(name, kind, temp string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(path, 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(path, "type"), []byte(kind+"\n"), 0644))
+		assert.NoError(t, os.WriteFile(filepath.Join(path, "temp"), []byte(temp+"\n"), 0644))
+	}
+
+	_, err := hottestZone(dir)
+	assert.Error(t, err)
+
+	// Without a processor sensor, the hottest zone counts
+	zone("thermal_zone0", "acpitz", "48000")
+	zone("thermal_zone1", "iwlwifi_1", "52500")
+	celsius, err := hottestZone(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 52.5, celsius)
+
+	// Processor sensors are preferred, and broken ones are left out
+	zone("thermal_zone2", "x86_pkg_temp", "71000")
+	zone("thermal_zone3", "x86_pkg_temp", "-1")
+	zone("thermal_zone4", "TCPU", "68000")
+	celsius, err = hottestZone(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 71.0, celsius)
+}
+
+func TestLoadAverage(t *testing.T) {
+	load, err := LoadAverage()
+	if err != nil {
+		t.Skip(err)
+	}
+	assert.True(t, load >= 0, "load %f", load)
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package util
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var getSystemTimes = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemTimes")
+
+// cpuTimes is the last reading of GetSystemTimes, which LoadAverage
+// measures the busy time since
+var cpuTimes struct {
+	mu                 sync.Mutex
+	idle, kernel, user uint64
+}
+
+// readSystemTimes returns the idle, kernel and user time of every core
+// since boot, in 100 ns units. Kernel time includes the idle time.
+func readSystemTimes() (idle, kernel, user uint64, err error) {
+	var idleTime, kernelTime, userTime windows.Filetime
+	if ok, _, callErr := getSystemTimes.Call(uintptr(unsafe.Pointer(&idleTime)),
+		uintptr(unsafe.Pointer(&kernelTime)), uintptr(unsafe.Pointer(&userTime))); ok == 0 {
+		return 0, 0, 0, callErr
+	}
+	value := func(t windows.Filetime) uint64 { return uint64(t.HighDateTime)<<32 | uint64(t.LowDateTime) }
+	return value(idleTime), value(kernelTime), value(userTime), nil
+}
+
+// LoadAverage estimates what the load average is on other systems: the
+// busy share of the CPU since the last call, times the number of cores.
+// Windows keeps no load average. The first call measures half a second.
+func LoadAverage() (float64, error) {
+	cpuTimes.mu.Lock()
+	defer cpuTimes.mu.Unlock()
+
+	if cpuTimes.kernel == 0 {
+		idle, kernel, user, err := readSystemTimes()
+		if err != nil {
+			return 0, err
+		}
+		cpuTimes.idle, cpuTimes.kernel, cpuTimes.user = idle, kernel, user
+		time.Sleep(500 * time.Millisecond)
+	}
+	idle, kernel, user, err := readSystemTimes()
+	if err != nil {
+		return 0, err
+	}
+	total := (kernel - cpuTimes.kernel) + (user - cpuTimes.user)
+	busy := total - (idle - cpuTimes.idle)
+	cpuTimes.idle, cpuTimes.kernel, cpuTimes.user = idle, kernel, user
+	if total == 0 {
+		return 0, nil
+	}
+	return float64(busy) / float64(total) * float64(runtime.NumCPU()), nil
+}
+
+// CPUTemperature is not available on Windows without administrator rights;
+// callers treat the temperature as unknown
+func CPUTemperature() (float64, error) {
+	return 0, errors.New("the CPU temperature cannot be read on this system")
+}
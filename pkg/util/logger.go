@@ -1,11 +1,17 @@
 package util
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
+
+	"github.com/cccarv82/compressvideo/pkg/i18n"
 )
 
 // LogLevel defines the level of logging
@@ -20,6 +26,27 @@ const (
 	LogLevelDebug
 )
 
+// LogFormat defines how log records are written
+type LogFormat string
+
+const (
+	// LogFormatText writes human-readable lines
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes one JSON object per line
+	LogFormatJSON LogFormat = "json"
+)
+
+// ParseLogFormat validates a --log-format value
+func ParseLogFormat(value string) (LogFormat, error) {
+	switch LogFormat(strings.ToLower(value)) {
+	case "", LogFormatText:
+		return LogFormatText, nil
+	case LogFormatJSON:
+		return LogFormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid log format: %s (use text or json)", value)
+}
+
 // ANSI color codes
 const (
 	colorReset   = "\033[0m"
@@ -31,18 +58,38 @@ const (
 	colorCyan    = "\033[36m"
 	colorGray    = "\033[37m"
 	colorWhite   = "\033[97m"
-	
+
 	colorBold      = "\033[1m"
 	colorUnderline = "\033[4m"
 )
 
-// Logger handles logging and user feedback
+// Logger handles logging and user feedback. Messages go to the terminal
+// according to Level, and every message, debug included, is also written to
+// the log file when one is set. Format strings and field labels are
+// translated to the selected language (see pkg/i18n).
 type Logger struct {
 	Level       LogLevel
 	Verbose     bool
 	UseColors   bool
 	TimeFormat  string
 	ShowLogTime bool
+	Format      LogFormat
+
+	// PlainProgress makes progress trackers log a line every few percent
+	// instead of redrawing a bar, for logs, CI and containers
+	PlainProgress bool
+
+	stderrColors bool // Whether stderr, where errors go, is a color terminal too
+
+	mu      sync.Mutex
+	logFile io.WriteCloser
+}
+
+// logRecord is a single log entry in JSON format
+type logRecord struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Message string `json:"msg"`
 }
 
 // NewLogger creates a new Logger with the specified log level and verbosity
@@ -51,41 +98,134 @@ func NewLogger(verbose bool) *Logger {
 	if verbose {
 		level = LogLevelDebug
 	}
-	
-	// Determine if we should use colors (disable on Windows unless in ANSICON/ConEmu/WSL)
-	useColors := true
-	if runtime.GOOS == "windows" {
-		// Check for common Windows terminals that support ANSI
-		_, hasAnsiCon := os.LookupEnv("ANSICON")
-		_, hasConEmu := os.LookupEnv("ConEmuANSI")
-		_, hasWT := os.LookupEnv("WT_SESSION")
-		
-		// Disable colors by default on Windows unless in compatible terminal
-		useColors = hasAnsiCon || hasConEmu || hasWT
-	}
-	
+
+	// Colors and redrawn progress bars only make sense on a terminal; piped
+	// to a file they litter the log with ANSI codes and carriage returns
+	stdoutTerminal := IsTerminal(os.Stdout)
+	colors := terminalSupportsColors()
+
 	return &Logger{
 		Level:       level,
 		Verbose:     verbose,
-		UseColors:   useColors,
+		UseColors:   colors && stdoutTerminal,
 		TimeFormat:  "15:04:05",
 		ShowLogTime: true,
+		Format:      LogFormatText,
+
+		PlainProgress: !stdoutTerminal,
+		stderrColors:  colors && IsTerminal(os.Stderr),
+	}
+}
+
+// terminalSupportsColors reports whether the terminal understands ANSI colors
+func terminalSupportsColors() bool {
+	if runtime.GOOS != "windows" {
+		return true
+	}
+
+	// Check for common Windows terminals that support ANSI
+	_, hasAnsiCon := os.LookupEnv("ANSICON")
+	_, hasConEmu := os.LookupEnv("ConEmuANSI")
+	_, hasWT := os.LookupEnv("WT_SESSION")
+
+	// Disable colors by default on Windows unless in compatible terminal
+	return hasAnsiCon || hasConEmu || hasWT
+}
+
+// SetLogFile writes all messages, debug included, to a rotating log file.
+// The file is rotated when it reaches maxSize bytes, keeping maxBackups old files.
+func (l *Logger) SetLogFile(path string, maxSize int64, maxBackups int) error {
+	file, err := OpenRotatingFile(path, maxSize, maxBackups)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.logFile != nil {
+		l.logFile.Close()
+	}
+	l.logFile = file
+	return nil
+}
+
+// SetFormat sets the record format. With a log file the format applies to the
+// file; otherwise JSON records replace the colored terminal output.
+func (l *Logger) SetFormat(format LogFormat) {
+	l.Format = format
+}
+
+// Close closes the log file, if any
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.logFile == nil {
+		return nil
+	}
+	err := l.logFile.Close()
+	l.logFile = nil
+	return err
+}
+
+// jsonConsole reports whether terminal output is replaced by JSON records
+func (l *Logger) jsonConsole() bool {
+	return l.Format == LogFormatJSON && l.logFile == nil
+}
+
+// formatRecord renders a record for the log file or JSON console output
+func (l *Logger) formatRecord(level, message string) string {
+	now := time.Now()
+	if l.Format == LogFormatJSON {
+		data, _ := json.Marshal(logRecord{
+			Time:    now.Format(time.RFC3339Nano),
+			Level:   strings.ToLower(level),
+			Message: message,
+		})
+		return string(data)
+	}
+	return fmt.Sprintf("%s %-7s %s", now.Format("2006-01-02 15:04:05.000"), level, message)
+}
+
+// record writes a message to the log file
+func (l *Logger) record(level, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.logFile == nil {
+		return
+	}
+	fmt.Fprintln(l.logFile, l.formatRecord(level, message))
+}
+
+// emit records a message and prints it to the terminal if visible is set
+func (l *Logger) emit(w io.Writer, visible bool, level, color, message string) {
+	l.record(level, message)
+	if !visible {
+		return
+	}
+	if l.jsonConsole() {
+		fmt.Fprintln(w, l.formatRecord(level, message))
+		return
+	}
+	colors := l.UseColors
+	if w == os.Stderr {
+		colors = colors && l.stderrColors
 	}
+	fmt.Fprintln(w, l.formatMessage(level, color, message, colors))
 }
 
 // formatMessage creates a formatted log message with timestamp and level
-func (l *Logger) formatMessage(level, color, message string) string {
+func (l *Logger) formatMessage(level, color, message string, colors bool) string {
 	var timePrefix string
 	if l.ShowLogTime {
 		timestamp := time.Now().Format(l.TimeFormat)
 		timePrefix = fmt.Sprintf("[%s] ", timestamp)
 	}
-	
-	if l.UseColors {
-		return fmt.Sprintf("%s%s%s%s: %s%s", 
+
+	if colors {
+		return fmt.Sprintf("%s%s%s%s: %s%s",
 			timePrefix, color, level, colorReset, color, message)
 	}
-	
+
 	return fmt.Sprintf("%s%s: %s", timePrefix, level, message)
 }
 
@@ -99,104 +239,184 @@ func (l *Logger) colorize(color, message string) string {
 
 // Info logs information messages
 func (l *Logger) Info(format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		message := fmt.Sprintf(format, args...)
-		fmt.Println(l.formatMessage("INFO", colorBlue, message))
-	}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stdout, l.Level >= LogLevelInfo, "INFO", colorBlue, message)
 }
 
 // Debug logs debug messages
 func (l *Logger) Debug(format string, args ...interface{}) {
-	if l.Level >= LogLevelDebug {
-		message := fmt.Sprintf(format, args...)
-		fmt.Println(l.formatMessage("DEBUG", colorCyan, message))
-	}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stdout, l.Level >= LogLevelDebug, "DEBUG", colorCyan, message)
 }
 
 // Error logs error messages
 func (l *Logger) Error(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Fprintln(os.Stderr, l.formatMessage("ERROR", colorRed, message))
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stderr, true, "ERROR", colorRed, message)
 }
 
 // Warning logs warning messages
 func (l *Logger) Warning(format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		message := fmt.Sprintf(format, args...)
-		fmt.Println(l.formatMessage("WARNING", colorYellow, message))
-	}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stdout, l.Level >= LogLevelInfo, "WARNING", colorYellow, message)
 }
 
 // Fatal logs a fatal error message and exits the program
 func (l *Logger) Fatal(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Fprintln(os.Stderr, l.formatMessage("FATAL", colorRed+colorBold, message))
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stderr, true, "FATAL", colorRed+colorBold, message)
+	l.Close()
 	os.Exit(1)
 }
 
 // Success logs a success message
 func (l *Logger) Success(format string, args ...interface{}) {
-	message := fmt.Sprintf(format, args...)
-	fmt.Println(l.formatMessage("SUCCESS", colorGreen, message))
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stdout, true, "SUCCESS", colorGreen, message)
 }
 
 // Title displays a title section in the log
 func (l *Logger) Title(format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		message := fmt.Sprintf(format, args...)
-		
-		// Create a line of equal signs the same length as the message
-		lineLength := len(message)
-		line := strings.Repeat("=", lineLength)
-		
-		if l.UseColors {
-			fmt.Println(l.colorize(colorYellow+colorBold, line))
-			fmt.Println(l.colorize(colorYellow+colorBold, message))
-			fmt.Println(l.colorize(colorYellow+colorBold, line))
-		} else {
-			fmt.Println(line)
-			fmt.Println(message)
-			fmt.Println(line)
-		}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.record("INFO", message)
+	if l.Level < LogLevelInfo {
+		return
+	}
+	if l.jsonConsole() {
+		fmt.Println(l.formatRecord("INFO", message))
+		return
+	}
+
+	// Create a line of equal signs the same length as the message
+	lineLength := utf8.RuneCountInString(message)
+	line := strings.Repeat("=", lineLength)
+
+	if l.UseColors {
+		fmt.Println(l.colorize(colorYellow+colorBold, line))
+		fmt.Println(l.colorize(colorYellow+colorBold, message))
+		fmt.Println(l.colorize(colorYellow+colorBold, line))
+	} else {
+		fmt.Println(line)
+		fmt.Println(message)
+		fmt.Println(line)
 	}
 }
 
 // Section displays a section header in the log
 func (l *Logger) Section(format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		message := fmt.Sprintf(format, args...)
-		
-		if l.UseColors {
-			fmt.Println(l.colorize(colorMagenta+colorBold, message))
-			fmt.Println(l.colorize(colorMagenta, strings.Repeat("-", len(message))))
-		} else {
-			fmt.Println(message)
-			fmt.Println(strings.Repeat("-", len(message)))
-		}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.record("INFO", message)
+	if l.Level < LogLevelInfo {
+		return
+	}
+	if l.jsonConsole() {
+		fmt.Println(l.formatRecord("INFO", message))
+		return
+	}
+
+	if l.UseColors {
+		fmt.Println(l.colorize(colorMagenta+colorBold, message))
+		fmt.Println(l.colorize(colorMagenta, strings.Repeat("-", utf8.RuneCountInString(message))))
+	} else {
+		fmt.Println(message)
+		fmt.Println(strings.Repeat("-", utf8.RuneCountInString(message)))
 	}
 }
 
 // Field logs a labeled field value
 func (l *Logger) Field(label, format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		value := fmt.Sprintf(format, args...)
-		
-		if l.UseColors {
-			fmt.Printf("%s: %s\n", 
-				l.colorize(colorYellow, label),
-				value)
-		} else {
-			fmt.Printf("%s: %s\n", label, value)
+	label = i18n.Tr(label)
+	value := fmt.Sprintf(i18n.Tr(format), args...)
+	l.record("INFO", label+": "+value)
+	if l.Level < LogLevelInfo {
+		return
+	}
+	if l.jsonConsole() {
+		fmt.Println(l.formatRecord("INFO", label+": "+value))
+		return
+	}
+
+	if l.UseColors {
+		fmt.Printf("%s: %s\n",
+			l.colorize(colorYellow, label),
+			value)
+	} else {
+		fmt.Printf("%s: %s\n", label, value)
+	}
+}
+
+// Table displays rows in aligned columns under a header line. The headers
+// are translated; the cells are printed as given.
+func (l *Logger) Table(headers []string, rows [][]string) {
+	translated := make([]string, len(headers))
+	for i, header := range headers {
+		translated[i] = i18n.Tr(header)
+	}
+	lines := formatTable(translated, rows)
+	for _, line := range lines {
+		l.record("INFO", line)
+	}
+	if l.Level < LogLevelInfo {
+		return
+	}
+
+	for i, line := range lines {
+		switch {
+		case l.jsonConsole():
+			fmt.Println(l.formatRecord("INFO", line))
+		case i < 2:
+			fmt.Println(l.colorize(colorBold, line))
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// formatTable pads the cells of each column to the widest one and returns
+// the header, a separator and the rows as lines
+func formatTable(headers []string, rows [][]string) []string {
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = utf8.RuneCountInString(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) && utf8.RuneCountInString(cell) > widths[i] {
+				widths[i] = utf8.RuneCountInString(cell)
+			}
+		}
+	}
+
+	format := func(cells []string) string {
+		var b strings.Builder
+		for i, cell := range cells {
+			if i >= len(widths) {
+				break
+			}
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(cell)
+			b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
 		}
+		return strings.TrimRight(b.String(), " ")
 	}
+
+	separator := make([]string, len(widths))
+	for i, width := range widths {
+		separator[i] = strings.Repeat("-", width)
+	}
+	lines := []string{format(headers), format(separator)}
+	for _, row := range rows {
+		lines = append(lines, format(row))
+	}
+	return lines
 }
 
 // Progress logs a progress message
 func (l *Logger) Progress(format string, args ...interface{}) {
-	if l.Level >= LogLevelInfo {
-		message := fmt.Sprintf(format, args...)
-		fmt.Println(l.formatMessage("PROGRESS", colorMagenta, message))
-	}
+	message := fmt.Sprintf(i18n.Tr(format), args...)
+	l.emit(os.Stdout, l.Level >= LogLevelInfo, "PROGRESS", colorMagenta, message)
 }
 
 // SetUseColors enables or disables color output
@@ -212,4 +432,4 @@ func (l *Logger) SetLevel(level LogLevel) {
 // IsVerbose returns true if verbose mode is enabled
 func (l *Logger) IsVerbose() bool {
 	return l.Verbose
-} 
\ No newline at end of file
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLogFormat(t *testing.T) {
+	format, err := ParseLogFormat("")
+	assert.NoError(t, err)
+	assert.Equal(t, LogFormatText, format)
+
+	format, err = ParseLogFormat("JSON")
+	assert.NoError(t, err)
+	assert.Equal(t, LogFormatJSON, format)
+
+	_, err = ParseLogFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestLogFileKeepsDebugOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "compressvideo.log")
+
+	logger := NewLogger(false)
+	logger.SetLevel(LogLevelError) // Nothing but errors on the terminal
+	logger.SetFormat(LogFormatJSON)
+	assert.NoError(t, logger.SetLogFile(path, 0, 0))
+
+	logger.Debug("ffmpeg args: %s", "-crf 23")
+	logger.Field("Preset", "%s", "fast")
+	assert.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+
+	var record logRecord
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "debug", record.Level)
+	assert.Equal(t, "ffmpeg args: -crf 23", record.Message)
+
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "Preset: fast", record.Message)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	file, err := OpenRotatingFile(path, 10, 2)
+	assert.NoError(t, err)
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err := file.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, file.Close())
+
+	read := func(name string) string {
+		data, _ := os.ReadFile(name)
+		return string(data)
+	}
+	assert.Equal(t, "fourth\n", read(path))
+	assert.Equal(t, "third\n", read(path+".1"))
+	assert.Equal(t, "second\n", read(path+".2"))
+	// Only maxBackups rotated files are kept
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestNewLoggerPlainWhenPiped(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	defer r.Close()
+	defer w.Close()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() { os.Stdout, os.Stderr = stdout, stderr }()
+
+	assert.False(t, IsTerminal(w))
+	logger := NewLogger(false)
+	assert.False(t, logger.UseColors)
+	assert.True(t, logger.PlainProgress)
+	assert.False(t, logger.stderrColors)
+}
+
+func TestFormatTable(t *testing.T) {
+	lines := formatTable([]string{"Check", "Status", "Details"}, [][]string{
+		{"FFmpeg", "PASS", "7.1"},
+		{"Espaço em disco", "FAIL", ""},
+	})
+	assert.Equal(t, []string{
+		"Check            Status  Details",
+		"---------------  ------  -------",
+		"FFmpeg           PASS    7.1",
+		"Espaço em disco  FAIL",
+	}, lines)
+}
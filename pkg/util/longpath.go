@@ -0,0 +1,34 @@
+package util
+
+import "strings"
+
+// Windows limits classic paths to MAX_PATH (260) characters, and directories
+// to 12 less so an 8.3 file name still fits. Longer paths need the
+// extended-length \\?\ form, which FFmpeg and the Win32 API both accept.
+const maxClassicPathLength = 248
+
+// extendedLengthPath converts an absolute, clean Windows path to its
+// extended-length form: C:\dir\file becomes \\?\C:\dir\file and the UNC path
+// \\server\share\file becomes \\?\UNC\server\share\file
+func extendedLengthPath(path string) string {
+	switch {
+	case strings.HasPrefix(path, `\\?\`), strings.HasPrefix(path, `\\.\`):
+		// Already extended, or a device path
+		return path
+	case strings.HasPrefix(path, `\\`):
+		return `\\?\UNC\` + path[2:]
+	case len(path) >= 3 && path[1] == ':' && path[2] == '\\':
+		return `\\?\` + path
+	}
+	return path
+}
+
+// needsExtendedLength reports whether a clean Windows path must use the
+// extended-length form: it is too long for the classic API, or it is on a
+// network share
+func needsExtendedLength(path string) bool {
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return false
+	}
+	return len(path) >= maxClassicPathLength || strings.HasPrefix(path, `\\`)
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package util
+
+// LongPath returns path unchanged; only Windows limits the path length
+func LongPath(path string) string {
+	return path
+}
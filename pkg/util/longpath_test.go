@@ -0,0 +1,32 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendedLengthPath(t *testing.T) {
+	assert.Equal(t, `\\?\C:\Videos\movie.mp4`, extendedLengthPath(`C:\Videos\movie.mp4`))
+	assert.Equal(t, `\\?\UNC\nas\media\movie.mp4`, extendedLengthPath(`\\nas\media\movie.mp4`))
+
+	// Extended and device paths are left alone
+	assert.Equal(t, `\\?\C:\Videos\movie.mp4`, extendedLengthPath(`\\?\C:\Videos\movie.mp4`))
+	assert.Equal(t, `\\?\UNC\nas\media\movie.mp4`, extendedLengthPath(`\\?\UNC\nas\media\movie.mp4`))
+	assert.Equal(t, `\\.\pipe\ffmpeg`, extendedLengthPath(`\\.\pipe\ffmpeg`))
+
+	// Relative paths cannot be extended
+	assert.Equal(t, `Videos\movie.mp4`, extendedLengthPath(`Videos\movie.mp4`))
+}
+
+func TestNeedsExtendedLength(t *testing.T) {
+	assert.False(t, needsExtendedLength(`C:\Videos\movie.mp4`))
+	assert.True(t, needsExtendedLength(`\\nas\media\movie.mp4`))
+	assert.False(t, needsExtendedLength(`\\?\UNC\nas\media\movie.mp4`))
+
+	deep := `C:\Videos\` + strings.Repeat(`Season 01\`, 25) + "episode.mkv"
+	assert.True(t, len(deep) > 260)
+	assert.True(t, needsExtendedLength(deep))
+	assert.Equal(t, `\\?\`+deep, extendedLengthPath(deep))
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// LongPath returns path in the extended-length \\?\ form when it is longer
+// than the classic Windows limit or on a UNC share, so file operations,
+// temporary segment directories and FFmpeg work on deep NAS libraries.
+// Short local paths and URLs are returned unchanged.
+func LongPath(path string) string {
+	if path == "" || strings.Contains(path, "://") || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil || !needsExtendedLength(abs) {
+		return path
+	}
+	return extendedLengthPath(abs)
+}
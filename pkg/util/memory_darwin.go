@@ -0,0 +1,10 @@
+package util
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// TotalMemory returns the bytes of RAM of the machine
+func TotalMemory() (uint64, error) {
+	return unix.SysctlUint64("hw.memsize")
+}
@@ -0,0 +1,16 @@
+//go:build linux
+
+package util
+
+import (
+	"syscall"
+)
+
+// TotalMemory returns the bytes of RAM of the machine
+func TotalMemory() (uint64, error) {
+	var info syscall.Sysinfo_t
+	if err := syscall.Sysinfo(&info); err != nil {
+		return 0, err
+	}
+	return uint64(info.Totalram) * uint64(info.Unit), nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux && !darwin && !windows
+
+package util
+
+import (
+	"errors"
+)
+
+// TotalMemory is not supported on this system; callers treat the amount of
+// RAM as unknown
+func TotalMemory() (uint64, error) {
+	return 0, errors.New("the amount of memory cannot be read on this system")
+}
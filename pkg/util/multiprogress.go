@@ -0,0 +1,113 @@
+package util
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// multiBarWidth is the width in characters of each bar in a multi-bar display
+	multiBarWidth = 30
+	// multiRenderInterval limits how often the multi-bar display is redrawn
+	multiRenderInterval = 100 * time.Millisecond
+)
+
+// SubBar is one line of a multi-bar display, such as a single segment or file
+type SubBar struct {
+	label   string
+	percent int64
+	stats   EncodeStats
+}
+
+// multiRenderer draws an aggregate line followed by one line per sub-bar,
+// redrawing them in place with ANSI cursor movement
+type multiRenderer struct {
+	mu         sync.Mutex
+	writer     io.Writer
+	aggregate  string
+	panel      string // Encoder figures shown under the aggregate line, if any
+	bars       []*SubBar
+	linesDrawn int
+	lastRender time.Time
+}
+
+// newMultiRenderer creates a renderer that writes to the given writer
+func newMultiRenderer(writer io.Writer) *multiRenderer {
+	return &multiRenderer{writer: writer}
+}
+
+// lines returns the text of every line in the display, aggregate first
+func (m *multiRenderer) lines() []string {
+	lines := []string{m.aggregate}
+	if m.panel != "" {
+		lines = append(lines, "  "+m.panel)
+	}
+	for _, bar := range m.bars {
+		suffix := ""
+		if bar.percent >= 100 {
+			suffix = "done"
+		} else if bar.stats.Speed > 0 {
+			suffix = fmt.Sprintf("%.0f fps, %.2fx", bar.stats.FPS, bar.stats.Speed)
+		}
+		lines = append(lines, "  "+formatBarLine(bar.label, bar.percent, multiBarWidth, suffix))
+	}
+	return lines
+}
+
+// render redraws the display. Unless force is set, redraws are throttled.
+// The caller must hold m.mu.
+func (m *multiRenderer) render(force bool) {
+	if !force && time.Since(m.lastRender) < multiRenderInterval {
+		return
+	}
+	m.lastRender = time.Now()
+
+	var out strings.Builder
+	if m.linesDrawn > 0 {
+		// Move back to the first line of the previous drawing
+		fmt.Fprintf(&out, "\033[%dA", m.linesDrawn)
+	}
+
+	lines := m.lines()
+	for _, line := range lines {
+		out.WriteString("\r\033[2K")
+		out.WriteString(line)
+		out.WriteString("\n")
+	}
+
+	// Blank out lines left over from a taller previous drawing
+	extra := m.linesDrawn - len(lines)
+	for i := 0; i < extra; i++ {
+		out.WriteString("\r\033[2K\n")
+	}
+	if extra > 0 {
+		fmt.Fprintf(&out, "\033[%dA", extra)
+	}
+
+	m.linesDrawn = len(lines)
+	io.WriteString(m.writer, out.String())
+}
+
+// formatBarLine renders "label [=====>    ]  45% suffix"
+func formatBarLine(label string, percent int64, width int, suffix string) string {
+	if percent < 0 {
+		percent = 0
+	} else if percent > 100 {
+		percent = 100
+	}
+
+	filled := int(percent) * width / 100
+	bar := strings.Repeat("=", filled)
+	if filled < width {
+		bar += ">" + strings.Repeat(" ", width-filled-1)
+	}
+
+	line := fmt.Sprintf("%s [%s] %3d%%", label, bar, percent)
+	if suffix != "" {
+		line += " " + suffix
+	}
+	return line
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBarLine(t *testing.T) {
+	assert.Equal(t, "Seg [>         ]   0%", formatBarLine("Seg", 0, 10, ""))
+	assert.Equal(t, "Seg [=====>    ]  50% 1.20x", formatBarLine("Seg", 50, 10, "1.20x"))
+	assert.Equal(t, "Seg [==========] 100%", formatBarLine("Seg", 150, 10, ""))
+}
+
+func TestMultiRendererRedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	m := newMultiRenderer(&out)
+	m.aggregate = "Total"
+	m.bars = []*SubBar{{label: "A"}, {label: "B"}}
+
+	m.render(true)
+	first := out.String()
+	assert.Equal(t, 3, strings.Count(first, "\n"))
+	assert.False(t, strings.Contains(first, "\033[3A"))
+
+	// The second drawing moves up over the three previous lines
+	out.Reset()
+	m.bars[0].percent = 100
+	m.render(true)
+	assert.True(t, strings.HasPrefix(out.String(), "\033[3A"))
+	assert.True(t, strings.Contains(out.String(), "done"))
+
+	// Removing the sub-bars blanks the lines they used
+	out.Reset()
+	m.bars = nil
+	m.render(true)
+	assert.Equal(t, 3, strings.Count(out.String(), "\n"))
+	assert.True(t, strings.HasSuffix(out.String(), "\033[2A"))
+	assert.Equal(t, 1, m.linesDrawn)
+}
+
+func TestMultiRendererPanel(t *testing.T) {
+	var out bytes.Buffer
+	m := newMultiRenderer(&out)
+	m.aggregate = "Total"
+	m.panel = "48 fps | 1.62x"
+	m.bars = []*SubBar{{label: "A"}}
+
+	assert.Equal(t, []string{"Total", "  48 fps | 1.62x", "  A [>                             ]   0%"}, m.lines())
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errPowerUnknown is returned when the power source cannot be told
+var errPowerUnknown = errors.New("the power source cannot be read on this system")
+
+// onBatteryFromSupplies tells from the power supplies under dir, laid out
+// like /sys/class/power_supply, whether the machine runs on battery: it
+// has a battery, and no mains or USB supply is online. Machines without a
+// battery, or without any supply, run on mains.
+func onBatteryFromSupplies(dir string) (bool, error) {
+	supplies, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	battery, external := false, false
+	for _, supply := range supplies {
+		kind, err := os.ReadFile(filepath.Join(dir, supply.Name(), "type"))
+		if err != nil {
+			continue
+		}
+		switch strings.TrimSpace(string(kind)) {
+		case "Battery":
+			// Batteries of mice and headsets are not the machine's
+			if scope, err := os.ReadFile(filepath.Join(dir, supply.Name(), "scope")); err == nil && strings.TrimSpace(string(scope)) == "Device" {
+				continue
+			}
+			battery = true
+		case "Mains", "USB", "USB_C", "USB_PD":
+			if online, err := os.ReadFile(filepath.Join(dir, supply.Name(), "online")); err == nil && strings.TrimSpace(string(online)) == "1" {
+				external = true
+			}
+		}
+	}
+	return battery && !external, nil
+}
+
+// onBatteryFromPmset tells from the output of 'pmset -g batt' whether the
+// machine runs on battery
+func onBatteryFromPmset(output string) (bool, error) {
+	switch {
+	case strings.Contains(output, "'Battery Power'"):
+		return true, nil
+	case strings.Contains(output, "'AC Power'"), strings.Contains(output, "'UPS Power'"):
+		return false, nil
+	}
+	return false, errPowerUnknown
+}
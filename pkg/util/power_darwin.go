@@ -0,0 +1,14 @@
+package util
+
+import (
+	"os/exec"
+)
+
+// OnBattery reports whether the machine runs on battery, as pmset tells
+func OnBattery() (bool, error) {
+	output, err := exec.Command("pmset", "-g", "batt").Output()
+	if err != nil {
+		return false, err
+	}
+	return onBatteryFromPmset(string(output))
+}
@@ -0,0 +1,9 @@
+//go:build linux
+
+package util
+
+// OnBattery reports whether the machine runs on battery, from the kernel's
+// power supplies
+func OnBattery() (bool, error) {
+	return onBatteryFromSupplies("/sys/class/power_supply")
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package util
+
+// OnBattery is not supported on this system; callers treat the machine as
+// running on mains
+func OnBattery() (bool, error) {
+	return false, errPowerUnknown
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnBatteryFromSupplies(t *testing.T) {
+	dir := t.TempDir()
+	supply := func(name string, files map[string]string) {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(path, 0755))
+		for file, value := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(path, file), []byte(value+"\n"), 0644))
+		}
+	}
+
+	// A desktop without a battery runs on mains
+	onBattery, err := onBatteryFromSupplies(dir)
+	assert.NoError(t, err)
+	assert.False(t, onBattery)
+
+	// The battery of a mouse is not the machine's
+	supply("hidpp_battery_0", map[string]string{"type": "Battery", "scope": "Device"})
+	onBattery, _ = onBatteryFromSupplies(dir)
+	assert.False(t, onBattery)
+
+	supply("BAT0", map[string]string{"type": "Battery", "status": "Discharging"})
+	onBattery, _ = onBatteryFromSupplies(dir)
+	assert.True(t, onBattery)
+
+	supply("AC", map[string]string{"type": "Mains", "online": "0"})
+	onBattery, _ = onBatteryFromSupplies(dir)
+	assert.True(t, onBattery)
+
+	supply("AC", map[string]string{"type": "Mains", "online": "1"})
+	onBattery, _ = onBatteryFromSupplies(dir)
+	assert.False(t, onBattery)
+
+	onBattery, err = onBatteryFromSupplies(filepath.Join(dir, "missing"))
+	assert.NoError(t, err)
+	assert.False(t, onBattery)
+}
+
+func TestOnBatteryFromPmset(t *testing.T) {
+	onBattery, err := onBatteryFromPmset("Now drawing from 'Battery Power'\n -InternalBattery-0 (id=1234)\t81%; discharging; 5:12 remaining present: true\n")
+	assert.NoError(t, err)
+	assert.True(t, onBattery)
+
+	onBattery, err = onBatteryFromPmset("Now drawing from 'AC Power'\n -InternalBattery-0 (id=1234)\t100%; charged; 0:00 remaining present: true\n")
+	assert.NoError(t, err)
+	assert.False(t, onBattery)
+
+	_, err = onBatteryFromPmset("")
+	assert.Error(t, err)
+}
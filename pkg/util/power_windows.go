@@ -0,0 +1,33 @@
+//go:build windows
+
+package util
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// systemPowerStatus is the SYSTEM_POWER_STATUS structure filled by GetSystemPowerStatus
+type systemPowerStatus struct {
+	ACLineStatus        byte
+	BatteryFlag         byte
+	BatteryLifePercent  byte
+	SystemStatusFlag    byte
+	BatteryLifeTime     uint32
+	BatteryFullLifeTime uint32
+}
+
+// acOffline is the ACLineStatus of a machine running on battery
+const acOffline = 0
+
+var getSystemPowerStatus = windows.NewLazySystemDLL("kernel32.dll").NewProc("GetSystemPowerStatus")
+
+// OnBattery reports whether the machine runs on battery
+func OnBattery() (bool, error) {
+	var status systemPowerStatus
+	if ok, _, err := getSystemPowerStatus.Call(uintptr(unsafe.Pointer(&status))); ok == 0 {
+		return false, err
+	}
+	return status.ACLineStatus == acOffline, nil
+}
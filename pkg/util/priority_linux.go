@@ -0,0 +1,76 @@
+//go:build linux
+
+package util
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"syscall"
+)
+
+// ioprio_set arguments for the idle-adjacent "best effort, lowest level" class
+// (what "ionice -c 2 -n 7" sets)
+const (
+	ioprioWhoProcess = 1
+	ioprioClassBE    = 2
+	ioprioClassShift = 13
+	ioprioLowest     = 7
+)
+
+// lowerIOPriority moves the thread id to the lowest best-effort I/O priority
+func lowerIOPriority(id int) error {
+	prio := ioprioClassBE<<ioprioClassShift | ioprioLowest
+	_, _, errno := syscall.Syscall(syscall.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(id), uintptr(prio))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// threadIDs returns the IDs of the threads of the process pid, or pid alone
+// when they cannot be listed
+func threadIDs(pid int) []int {
+	entries, err := os.ReadDir("/proc/" + strconv.Itoa(pid) + "/task")
+	if err != nil || len(entries) == 0 {
+		return []int{pid}
+	}
+	var ids []int
+	for _, entry := range entries {
+		if id, err := strconv.Atoi(entry.Name()); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// StartLowPriority starts cmd at a lower CPU and disk I/O scheduling
+// priority, so every thread it creates has it from the start. Linux keeps
+// priorities per thread, so cmd is forked from an OS thread lowered for it,
+// which ends afterwards as an unprivileged process cannot raise it again.
+// A priority that cannot be lowered leaves cmd at the normal one, as it
+// does the tool.
+func StartLowPriority(cmd *exec.Cmd) error {
+	started := make(chan error, 1)
+	go startLowPriority(cmd, started)
+	return <-started
+}
+
+// startLowPriority lowers the priority of the thread it runs on and starts
+// cmd from it
+func startLowPriority(cmd *exec.Cmd, started chan<- error) {
+	// Returning without unlocking ends the thread with the goroutine
+	runtime.LockOSThread()
+	if syscall.Gettid() == os.Getpid() {
+		// Go keeps the main thread rather than ending it, so it is held
+		// while another thread is lowered
+		defer runtime.UnlockOSThread()
+		inner := make(chan error, 1)
+		go startLowPriority(cmd, inner)
+		started <- <-inner
+		return
+	}
+	lowerPriority(syscall.Gettid())
+	started <- cmd.Start()
+}
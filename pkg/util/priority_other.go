@@ -0,0 +1,26 @@
+//go:build !linux && !windows
+
+package util
+
+import "os/exec"
+
+// lowerIOPriority is a no-op where there is no per-process I/O priority
+func lowerIOPriority(id int) error {
+	return nil
+}
+
+// threadIDs returns pid, whose priority covers all of its threads
+func threadIDs(pid int) []int {
+	return []int{pid}
+}
+
+// StartLowPriority starts cmd and lowers its CPU scheduling priority, which
+// covers the threads it creates afterwards as well
+func StartLowPriority(cmd *exec.Cmd) error {
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// cmd runs at the normal priority if this fails, as the tool does
+	lowerPriority(cmd.Process.Pid)
+	return nil
+}
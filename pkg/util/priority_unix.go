@@ -0,0 +1,32 @@
+//go:build !windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lowPriorityNice is the nice value used by LowerProcessPriority
+const lowPriorityNice = 10
+
+// LowerProcessPriority lowers the CPU (and, on Linux, disk I/O) scheduling
+// priority of the current process
+func LowerProcessPriority() error {
+	for _, id := range threadIDs(os.Getpid()) {
+		// A thread may end while the others are lowered
+		if err := lowerPriority(id); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+	return nil
+}
+
+// lowerPriority lowers the CPU and disk I/O priority of the thread id
+func lowerPriority(id int) error {
+	if err := syscall.Setpriority(syscall.PRIO_PROCESS, id, lowPriorityNice); err != nil {
+		return err
+	}
+	return lowerIOPriority(id)
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package util
+
+import (
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// LowerProcessPriority moves the current process to the below-normal
+// priority class
+func LowerProcessPriority() error {
+	return windows.SetPriorityClass(windows.CurrentProcess(), windows.BELOW_NORMAL_PRIORITY_CLASS)
+}
+
+// StartLowPriority starts cmd in the below-normal priority class
+func StartLowPriority(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= windows.BELOW_NORMAL_PRIORITY_CLASS
+	return cmd.Start()
+}
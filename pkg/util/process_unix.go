@@ -0,0 +1,30 @@
+//go:build !windows
+
+package util
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// PauseSignals toggle the pause of running encodes, so runs without a
+// keyboard, such as services and cron jobs, can be paused with kill -USR1
+var PauseSignals = []os.Signal{syscall.SIGUSR1}
+
+// processRunning reports whether a process with the given ID exists
+func processRunning(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	// EPERM means it exists but belongs to another user
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// SuspendProcess stops the process with the given ID until ResumeProcess
+func SuspendProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGSTOP)
+}
+
+// ResumeProcess continues a process stopped by SuspendProcess
+func ResumeProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGCONT)
+}
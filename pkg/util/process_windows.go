@@ -0,0 +1,68 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// stillActive is the exit code GetExitCodeProcess reports for running processes
+const stillActive = 259
+
+// PauseSignals is empty on Windows, which has no user signals; encodes are
+// paused from the keyboard only
+var PauseSignals []os.Signal
+
+// ntdll exports the undocumented but stable calls that suspend and resume
+// every thread of a process at once
+var (
+	ntdll              = windows.NewLazySystemDLL("ntdll.dll")
+	procSuspendProcess = ntdll.NewProc("NtSuspendProcess")
+	procResumeProcess  = ntdll.NewProc("NtResumeProcess")
+)
+
+// processRunning reports whether a process with the given ID exists
+func processRunning(pid int) bool {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means it exists but belongs to another user
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return true
+	}
+	return code == stillActive
+}
+
+// SuspendProcess stops the process with the given ID until ResumeProcess
+func SuspendProcess(pid int) error {
+	return callProcess(procSuspendProcess, pid)
+}
+
+// ResumeProcess continues a process stopped by SuspendProcess
+func ResumeProcess(pid int) error {
+	return callProcess(procResumeProcess, pid)
+}
+
+// callProcess calls an ntdll process function with a handle to pid
+func callProcess(proc *windows.LazyProc, pid int) error {
+	if err := proc.Find(); err != nil {
+		return err
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_SUSPEND_RESUME, false, uint32(pid))
+	if err != nil {
+		return err
+	}
+	defer windows.CloseHandle(handle)
+
+	status, _, _ := proc.Call(uintptr(handle))
+	if status != 0 {
+		return windows.NTStatus(status)
+	}
+	return nil
+}
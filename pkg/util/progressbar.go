@@ -2,7 +2,9 @@ package util
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/schollz/progressbar/v3"
@@ -19,7 +21,32 @@ type ProgressTracker struct {
 	showSpeed      bool
 	lastUpdate     time.Time
 	lastProgress   int64
-	statusCallback func(progress int64, timeRemaining time.Duration, rate float64)
+	statusCallback func(status ProgressStatus)
+	encodeStats    *EncodeStats // Latest encoder figures reported by FFmpeg, if any
+	currentDesc    string       // Description including the latest ETA
+	multi          *multiRenderer // Multi-bar display, once sub-bars are added
+	plain          bool           // Log progress lines instead of drawing a bar
+	lastLogged     int64          // Last percentage logged in plain mode
+}
+
+// plainProgressStep is the percentage interval of plain progress lines
+const plainProgressStep = 10
+
+// EncodeStats holds the encoder figures reported by FFmpeg
+type EncodeStats struct {
+	FPS       float64       // Frames encoded per second
+	Speed     float64       // Encode speed relative to real time (1.7 = 1.7x)
+	Remaining time.Duration // Time left, derived from the remaining media duration and speed
+	Bitrate   float64       // Average bitrate of the output so far in kbit/s (0 when unknown)
+	Q         float64       // Quantizer of the latest frame, lower is higher quality (0 when unknown)
+}
+
+// ProgressStatus is the state of an operation passed to status callbacks
+type ProgressStatus struct {
+	Progress      int64         // Current progress out of the tracker's total
+	TimeRemaining time.Duration // Estimated time left
+	Rate          float64       // Progress units per second
+	Encode        *EncodeStats  // Latest encoder figures, nil until FFmpeg reports them
 }
 
 // NewProgressTrackerOptions configures a new progress tracker
@@ -30,7 +57,7 @@ type ProgressTrackerOptions struct {
 	ShowBytes      bool
 	ShowPercentage bool
 	ShowSpeed      bool
-	StatusCallback func(progress int64, timeRemaining time.Duration, rate float64)
+	StatusCallback func(status ProgressStatus)
 }
 
 // NewProgressTracker creates a new progress tracker
@@ -47,10 +74,17 @@ func NewProgressTracker(total int64, description string, logger *Logger) *Progre
 
 // NewProgressTrackerWithOptions creates a new progress tracker with advanced options
 func NewProgressTrackerWithOptions(options ProgressTrackerOptions) *ProgressTracker {
+	// Plain trackers log their progress and never draw the bar
+	plain := options.Logger != nil && options.Logger.PlainProgress
+	var writer io.Writer = os.Stdout
+	if plain {
+		writer = io.Discard
+	}
+	
 	// Set up progress bar options
 	barOptions := []progressbar.Option{
 		progressbar.OptionSetDescription(options.Description),
-		progressbar.OptionSetWriter(os.Stdout),
+		progressbar.OptionSetWriter(writer),
 		progressbar.OptionShowCount(),
 		progressbar.OptionSetTheme(progressbar.Theme{
 			Saucer:        "=",
@@ -89,6 +123,8 @@ func NewProgressTrackerWithOptions(options ProgressTrackerOptions) *ProgressTrac
 		lastUpdate:     time.Now(),
 		lastProgress:   0,
 		statusCallback: options.StatusCallback,
+		currentDesc:    options.Description,
+		plain:          plain,
 	}
 }
 
@@ -113,8 +149,16 @@ func (p *ProgressTracker) Update(current int64) error {
 			
 			description := fmt.Sprintf("%s [%s remain, %s]", 
 				p.description, remainingStr, rateStr)
+			
+			// FFmpeg's own figures are shown in the panel under the bar
+			if p.encodeStats != nil {
+				description = fmt.Sprintf("%s [%s remain]", p.description, remainingStr)
+			}
 				
-			p.bar.Describe(description)
+			p.currentDesc = description
+			if p.multi == nil {
+				p.bar.Describe(description)
+			}
 		}
 		
 		p.lastUpdate = now
@@ -122,15 +166,191 @@ func (p *ProgressTracker) Update(current int64) error {
 		
 		// Call status callback if set
 		if p.statusCallback != nil {
-			p.statusCallback(current, p.EstimateTimeRemaining(current), p.processingRate)
+			p.statusCallback(p.status(current))
 		}
 	} else if p.lastProgress == 0 {
 		p.lastProgress = current
 	}
 	
+	if p.plain {
+		p.logPlain(current)
+		return nil
+	}
+	
+	if p.multi != nil {
+		p.multi.mu.Lock()
+		p.multi.aggregate = formatBarLine(p.currentDesc, current*100/p.totalOrOne(), multiBarWidth, "")
+		p.multi.render(false)
+		p.multi.mu.Unlock()
+		return nil
+	}
+	
 	return p.bar.Set64(current)
 }
 
+// logPlain logs the progress every plainProgressStep percent, for output
+// that is not a terminal and cannot redraw a bar
+func (p *ProgressTracker) logPlain(current int64) {
+	percent := current * 100 / p.totalOrOne()
+	step := percent / plainProgressStep * plainProgressStep
+	if step <= p.lastLogged || step >= 100 {
+		return
+	}
+	p.lastLogged = step
+	
+	remaining := p.EstimateTimeRemaining(current)
+	switch {
+	case remaining > 0 && p.encodeStats != nil:
+		p.logger.Progress("%s: %d%% (%s remaining, %s)", p.description, step, formatDuration(remaining),
+			formatEncodeStats(*p.encodeStats))
+	case remaining > 0:
+		p.logger.Progress("%s: %d%% (%s remaining)", p.description, step, formatDuration(remaining))
+	default:
+		p.logger.Progress("%s: %d%%", p.description, step)
+	}
+}
+
+// Restart sets the progress back to zero for work that starts over, such as
+// an encode retried with another encoder
+func (p *ProgressTracker) Restart() {
+	p.ClearSubBars()
+	if p.multi != nil {
+		p.multi.mu.Lock()
+		p.multi.panel = ""
+		p.multi.mu.Unlock()
+	}
+	p.lastProgress = 0
+	p.lastLogged = 0
+	p.encodeStats = nil
+	p.Update(0)
+}
+
+// AddSubBars switches the tracker to a multi-bar display, with the overall
+// progress on top and one bar per label (e.g. per segment or per file) below it
+func (p *ProgressTracker) AddSubBars(labels ...string) []*SubBar {
+	// Plain output only logs the overall progress
+	if p.plain {
+		bars := make([]*SubBar, len(labels))
+		for i, label := range labels {
+			bars[i] = &SubBar{label: label}
+		}
+		return bars
+	}
+	
+	p.startMulti()
+	
+	p.multi.mu.Lock()
+	defer p.multi.mu.Unlock()
+	
+	bars := make([]*SubBar, len(labels))
+	for i, label := range labels {
+		bars[i] = &SubBar{label: label}
+	}
+	p.multi.bars = append(p.multi.bars, bars...)
+	p.multi.render(true)
+	
+	return bars
+}
+
+// startMulti switches from the single-line bar to the multi-line display
+func (p *ProgressTracker) startMulti() {
+	if p.multi != nil {
+		return
+	}
+	p.multi = newMultiRenderer(os.Stdout)
+	p.multi.aggregate = formatBarLine(p.currentDesc, p.lastProgress*100/p.totalOrOne(), multiBarWidth, "")
+	// Clear the single-line bar before taking over the output
+	fmt.Fprint(os.Stdout, "\r\033[2K")
+}
+
+// UpdateSubBar sets the progress (0-100) and encoder figures of one sub-bar
+func (p *ProgressTracker) UpdateSubBar(bar *SubBar, percent int64, stats EncodeStats) {
+	if p.multi == nil || bar == nil {
+		return
+	}
+	
+	p.multi.mu.Lock()
+	defer p.multi.mu.Unlock()
+	
+	bar.percent = percent
+	if stats.Speed > 0 {
+		bar.stats = stats
+	}
+	p.multi.render(percent >= 100)
+}
+
+// ClearSubBars removes all sub-bars, leaving only the overall progress line
+func (p *ProgressTracker) ClearSubBars() {
+	if p.multi == nil {
+		return
+	}
+	
+	p.multi.mu.Lock()
+	defer p.multi.mu.Unlock()
+	
+	p.multi.bars = nil
+	p.multi.render(true)
+}
+
+// totalOrOne returns the total, avoiding division by zero for unbounded trackers
+func (p *ProgressTracker) totalOrOne() int64 {
+	if p.total <= 0 {
+		return 1
+	}
+	return p.total
+}
+
+// UpdateWithStats updates the progress bar and records the encoder's speed so
+// the ETA is based on it instead of the percentage rate
+func (p *ProgressTracker) UpdateWithStats(current int64, stats EncodeStats) error {
+	// speed=N/A is reported until the encoder warms up
+	if stats.Speed > 0 {
+		p.encodeStats = &stats
+		p.showEncodeStats(stats)
+	}
+	return p.Update(current)
+}
+
+// showEncodeStats shows the encoder figures in a panel under the bar, which
+// takes over the output as a multi-line display
+func (p *ProgressTracker) showEncodeStats(stats EncodeStats) {
+	if p.plain {
+		return
+	}
+	p.startMulti()
+	p.multi.mu.Lock()
+	p.multi.panel = formatEncodeStats(stats)
+	p.multi.mu.Unlock()
+}
+
+// formatEncodeStats renders the encoder figures, e.g.
+// "48 fps | 2.45 Mbps | q 28.0 | 1.62x"
+func formatEncodeStats(stats EncodeStats) string {
+	parts := []string{fmt.Sprintf("%.0f fps", stats.FPS)}
+	if stats.Bitrate > 0 {
+		parts = append(parts, FormatBitrate(int64(stats.Bitrate*1000)))
+	}
+	if stats.Q > 0 {
+		parts = append(parts, fmt.Sprintf("q %.1f", stats.Q))
+	}
+	parts = append(parts, fmt.Sprintf("%.2fx", stats.Speed))
+	return strings.Join(parts, " | ")
+}
+
+// status returns the state passed to the status callback
+func (p *ProgressTracker) status(current int64) ProgressStatus {
+	status := ProgressStatus{
+		Progress:      current,
+		TimeRemaining: p.EstimateTimeRemaining(current),
+		Rate:          p.processingRate,
+	}
+	if p.encodeStats != nil {
+		stats := *p.encodeStats
+		status.Encode = &stats
+	}
+	return status
+}
+
 // Increment increments the progress bar by the given amount
 func (p *ProgressTracker) Increment(amount int64) error {
 	current := p.lastProgress + amount
@@ -140,7 +360,18 @@ func (p *ProgressTracker) Increment(amount int64) error {
 // Finish completes the progress bar and displays final stats
 func (p *ProgressTracker) Finish() {
 	// Ensure bar shows 100%
-	p.bar.Finish()
+	if p.plain {
+		p.logger.Progress("%s: %d%%", p.description, 100)
+	} else if p.multi != nil {
+		p.multi.mu.Lock()
+		p.multi.bars = nil
+		p.multi.panel = ""
+		p.multi.aggregate = formatBarLine(p.description, 100, multiBarWidth, "")
+		p.multi.render(true)
+		p.multi.mu.Unlock()
+	} else {
+		p.bar.Finish()
+	}
 	duration := time.Since(p.startTime).Round(time.Second)
 	
 	// Show final stats
@@ -169,6 +400,10 @@ func (p *ProgressTracker) GetProcessingRate() float64 {
 
 // EstimateTimeRemaining estimates the remaining time based on progress
 func (p *ProgressTracker) EstimateTimeRemaining(current int64) time.Duration {
+	if p.encodeStats != nil {
+		return p.encodeStats.Remaining
+	}
+	
 	if current <= 0 {
 		return 0
 	}
@@ -184,8 +419,9 @@ func (p *ProgressTracker) EstimateTimeRemaining(current int64) time.Duration {
 	return time.Duration(remaining) * time.Second
 }
 
-// SetStatusCallback sets a callback function to receive progress updates
-func (p *ProgressTracker) SetStatusCallback(callback func(progress int64, timeRemaining time.Duration, rate float64)) {
+// SetStatusCallback sets a callback function to receive progress updates,
+// including the encoder figures once FFmpeg reports them
+func (p *ProgressTracker) SetStatusCallback(callback func(status ProgressStatus)) {
 	p.statusCallback = callback
 }
 
@@ -0,0 +1,68 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlainProgressLogsSteps(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.log")
+
+	logger := NewLogger(false)
+	logger.SetLevel(LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+	assert.NoError(t, logger.SetLogFile(path, 0, 0))
+
+	progress := NewProgressTracker(100, "Compressing", logger)
+	progress.AddSubBars("segment 1", "segment 2")
+	for _, current := range []int64{3, 12, 15, 19, 47, 99} {
+		assert.NoError(t, progress.Update(current))
+	}
+	progress.Finish()
+	assert.NoError(t, logger.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	var steps []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "Compressing: "); i >= 0 {
+			steps = append(steps, line[i:])
+		}
+	}
+	assert.Equal(t, []string{"Compressing: 10%", "Compressing: 40%", "Compressing: 90%", "Compressing: 100%"}, steps)
+}
+
+func TestFormatEncodeStats(t *testing.T) {
+	stats := EncodeStats{FPS: 47.6, Speed: 1.615, Bitrate: 2450, Q: 28}
+	assert.Equal(t, "48 fps | 2.45 Mbps | q 28.0 | 1.61x", formatEncodeStats(stats))
+
+	// Figures FFmpeg did not report are left out
+	assert.Equal(t, "30 fps | 0.50x", formatEncodeStats(EncodeStats{FPS: 30, Speed: 0.5}))
+}
+
+func TestProgressStatusEncodeStats(t *testing.T) {
+	logger := NewLogger(false)
+	logger.SetLevel(LogLevelError) // Keep the test output clean
+	logger.PlainProgress = true
+
+	progress := NewProgressTracker(100, "Compressing", logger)
+
+	status := progress.status(10)
+	assert.Nil(t, status.Encode)
+
+	stats := EncodeStats{FPS: 60, Speed: 2, Bitrate: 1200, Q: 23}
+	assert.NoError(t, progress.UpdateWithStats(20, stats))
+	status = progress.status(20)
+	assert.Equal(t, int64(20), status.Progress)
+	if assert.NotNil(t, status.Encode) {
+		assert.Equal(t, stats, *status.Encode)
+	}
+
+	// speed=N/A keeps the last figures
+	assert.NoError(t, progress.UpdateWithStats(21, EncodeStats{}))
+	assert.Equal(t, 60.0, progress.status(21).Encode.FPS)
+}
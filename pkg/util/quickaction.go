@@ -0,0 +1,314 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// QuickActionName is the name of the file manager entry that compresses the
+// selected videos
+const QuickActionName = "Compress with CompressVideo"
+
+// quickActionFile is a file of a file manager entry
+type quickActionFile struct {
+	name    string // Path relative to the entry's path
+	content string
+	mode    os.FileMode
+}
+
+// QuickActionPath returns where the file manager entry is installed on
+// this system: the SendTo folder on Windows, a Quick Action in
+// ~/Library/Services on macOS, and a Nautilus script on Linux
+func QuickActionPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return quickActionPath(GetCurrentOS(), home, os.Getenv("APPDATA"))
+}
+
+// quickActionPath returns where the file manager entry of system goes
+func quickActionPath(system OSType, home, appData string) (string, error) {
+	switch system {
+	case Windows:
+		if appData == "" {
+			appData = filepath.Join(home, "AppData", "Roaming")
+		}
+		return filepath.Join(appData, "Microsoft", "Windows", "SendTo", QuickActionName+".cmd"), nil
+	case MacOS:
+		return filepath.Join(home, "Library", "Services", QuickActionName+".workflow"), nil
+	case Linux:
+		return filepath.Join(home, ".local", "share", "nautilus", "scripts", QuickActionName), nil
+	}
+	return "", fmt.Errorf("file manager entries are not supported on %s", system)
+}
+
+// InstallQuickAction adds the file manager entry that runs
+// "executable quick" on the selected files, and returns its path
+func InstallQuickAction(executable string) (string, error) {
+	path, err := QuickActionPath()
+	if err != nil {
+		return "", err
+	}
+	return path, installQuickAction(GetCurrentOS(), path, executable)
+}
+
+// installQuickAction writes the files of the entry of system to path,
+// replacing an earlier one
+func installQuickAction(system OSType, path, executable string) error {
+	files := quickActionFiles(system, executable)
+	if len(files) > 1 {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		target := filepath.Join(path, file.name)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, []byte(file.content), file.mode); err != nil {
+			return err
+		}
+		// WriteFile keeps the mode of a file that already exists
+		if err := os.Chmod(target, file.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UninstallQuickAction removes the file manager entry, and returns the path
+// it was at
+func UninstallQuickAction() (string, error) {
+	path, err := QuickActionPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return path, fmt.Errorf("no file manager entry at %s", path)
+		}
+		return path, err
+	}
+	return path, os.RemoveAll(path)
+}
+
+// quickActionFiles returns the files of the entry of system, which runs
+// executable
+func quickActionFiles(system OSType, executable string) []quickActionFile {
+	switch system {
+	case Windows:
+		// SendTo runs batch files with the selected files as arguments; the
+		// window stays open after a failure so the error can be read. cmd.exe
+		// reads the file in the console code page, so it switches to UTF-8
+		// for paths such as C:\Users\João, and expands % even in quotes.
+		script := strings.Join([]string{
+			"@echo off",
+			"chcp 65001 >nul",
+			fmt.Sprintf(`"%s" quick %%*`, strings.ReplaceAll(executable, "%", "%%")),
+			"if errorlevel 1 pause",
+			"",
+		}, "\r\n")
+		return []quickActionFile{{name: ".", content: script, mode: 0644}}
+	case MacOS:
+		return []quickActionFile{
+			{name: filepath.Join("Contents", "Info.plist"), content: quickActionInfoPlist, mode: 0644},
+			{name: filepath.Join("Contents", "document.wflow"), content: fmt.Sprintf(quickActionWorkflow, plistEscape(shellQuote(executable)+` quick "$@"`)), mode: 0644},
+		}
+	}
+	// Nautilus runs the scripts of its scripts folder with the selected files
+	return []quickActionFile{{name: ".", content: fmt.Sprintf("#!/bin/sh\nexec %s quick \"$@\"\n", shellQuote(executable)), mode: 0755}}
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// plistEscape escapes s for the text of a property list element
+func plistEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// quickActionInfoPlist offers the Quick Action in Finder for movie files
+const quickActionInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>NSServices</key>
+	<array>
+		<dict>
+			<key>NSMenuItem</key>
+			<dict>
+				<key>default</key>
+				<string>` + QuickActionName + `</string>
+			</dict>
+			<key>NSMessage</key>
+			<string>runWorkflowAsService</string>
+			<key>NSRequiredContext</key>
+			<dict>
+				<key>NSApplicationIdentifier</key>
+				<string>com.apple.finder</string>
+			</dict>
+			<key>NSSendFileTypes</key>
+			<array>
+				<string>public.movie</string>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+// quickActionWorkflow is an Automator workflow with a single Run Shell
+// Script action that takes the selected files as arguments; %s is the
+// command
+const quickActionWorkflow = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>AMApplicationBuild</key>
+	<string>523</string>
+	<key>AMApplicationVersion</key>
+	<string>2.10</string>
+	<key>AMDocumentVersion</key>
+	<string>2</string>
+	<key>actions</key>
+	<array>
+		<dict>
+			<key>action</key>
+			<dict>
+				<key>AMAccepts</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Optional</key>
+					<true/>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>AMActionVersion</key>
+				<string>2.0.3</string>
+				<key>AMApplication</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>AMParameterProperties</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<dict/>
+					<key>CheckedForUserDefaultShell</key>
+					<dict/>
+					<key>inputMethod</key>
+					<dict/>
+					<key>shell</key>
+					<dict/>
+					<key>source</key>
+					<dict/>
+				</dict>
+				<key>AMProvides</key>
+				<dict>
+					<key>Container</key>
+					<string>List</string>
+					<key>Types</key>
+					<array>
+						<string>com.apple.cocoa.string</string>
+					</array>
+				</dict>
+				<key>ActionBundlePath</key>
+				<string>/System/Library/Automator/Run Shell Script.action</string>
+				<key>ActionName</key>
+				<string>Run Shell Script</string>
+				<key>ActionParameters</key>
+				<dict>
+					<key>COMMAND_STRING</key>
+					<string>%s</string>
+					<key>CheckedForUserDefaultShell</key>
+					<true/>
+					<key>inputMethod</key>
+					<integer>1</integer>
+					<key>shell</key>
+					<string>/bin/sh</string>
+					<key>source</key>
+					<string></string>
+				</dict>
+				<key>BundleIdentifier</key>
+				<string>com.apple.RunShellScript</string>
+				<key>CFBundleVersion</key>
+				<string>2.0.3</string>
+				<key>CanShowSelectedItemsWhenRun</key>
+				<false/>
+				<key>CanShowWhenRun</key>
+				<true/>
+				<key>Category</key>
+				<array>
+					<string>AMCategoryUtilities</string>
+				</array>
+				<key>Class Name</key>
+				<string>RunShellScriptAction</string>
+				<key>InputUUID</key>
+				<string>6F0B1A5C-3D7E-4C59-9A3B-2B8C4D1E0F11</string>
+				<key>Keywords</key>
+				<array>
+					<string>Shell</string>
+					<string>Script</string>
+				</array>
+				<key>OutputUUID</key>
+				<string>8E2C4B6D-5F1A-4E3B-8C7D-9A0B1C2D3E22</string>
+				<key>UUID</key>
+				<string>A1B2C3D4-E5F6-4A7B-8C9D-0E1F2A3B4C33</string>
+				<key>UnlocalizedApplications</key>
+				<array>
+					<string>Automator</string>
+				</array>
+				<key>arguments</key>
+				<dict/>
+				<key>isViewVisible</key>
+				<integer>1</integer>
+				<key>location</key>
+				<string>309.000000:253.000000</string>
+				<key>nibPath</key>
+				<string>/System/Library/Automator/Run Shell Script.action/Contents/Resources/Base.lproj/main.nib</string>
+			</dict>
+			<key>isViewVisible</key>
+			<integer>1</integer>
+		</dict>
+	</array>
+	<key>connectors</key>
+	<dict/>
+	<key>workflowMetaData</key>
+	<dict>
+		<key>applicationBundleID</key>
+		<string>com.apple.finder</string>
+		<key>inputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject.movie</string>
+		<key>outputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>presentationMode</key>
+		<integer>15</integer>
+		<key>processesInput</key>
+		<false/>
+		<key>serviceApplicationBundleID</key>
+		<string>com.apple.finder</string>
+		<key>serviceInputTypeIdentifier</key>
+		<string>com.apple.Automator.fileSystemObject.movie</string>
+		<key>serviceOutputTypeIdentifier</key>
+		<string>com.apple.Automator.nothing</string>
+		<key>serviceProcessesInput</key>
+		<false/>
+		<key>systemImageName</key>
+		<string>NSActionTemplate</string>
+		<key>useAutomaticInputType</key>
+		<false/>
+		<key>workflowTypeIdentifier</key>
+		<string>com.apple.Automator.servicesMenu</string>
+	</dict>
+</dict>
+</plist>
+`
@@ -0,0 +1,67 @@
+package util
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuickActionPath(t *testing.T) {
+	path, err := quickActionPath(Windows, "/home", `C:\Users\ana\AppData\Roaming`)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(`C:\Users\ana\AppData\Roaming`, "Microsoft", "Windows", "SendTo", "Compress with CompressVideo.cmd"), path)
+
+	path, _ = quickActionPath(MacOS, "/Users/ana", "")
+	assert.Equal(t, filepath.Join("/Users/ana", "Library", "Services", "Compress with CompressVideo.workflow"), path)
+
+	_, err = quickActionPath(Unknown, "/home", "")
+	assert.Error(t, err)
+}
+
+func TestInstallQuickAction(t *testing.T) {
+	dir := t.TempDir()
+
+	// SendTo passes the selected files on to quick
+	sendTo := filepath.Join(dir, "SendTo", "Compress with CompressVideo.cmd")
+	assert.NoError(t, installQuickAction(Windows, sendTo, `C:\Tools\compressvideo.exe`))
+	data, _ := os.ReadFile(sendTo)
+	assert.Equal(t, "@echo off\r\nchcp 65001 >nul\r\n\"C:\\Tools\\compressvideo.exe\" quick %*\r\nif errorlevel 1 pause\r\n", string(data))
+
+	// Paths outside ASCII and with % survive cmd.exe
+	assert.NoError(t, installQuickAction(Windows, sendTo, `C:\Users\João\100%\compressvideo.exe`))
+	data, _ = os.ReadFile(sendTo)
+	assert.Contains(t, string(data), "chcp 65001 >nul\r\n\"C:\\Users\\João\\100%%\\compressvideo.exe\" quick %*\r\n")
+
+	script := filepath.Join(dir, "scripts", "Compress with CompressVideo")
+	assert.NoError(t, installQuickAction(Linux, script, "/opt/it's here/compressvideo"))
+	data, _ = os.ReadFile(script)
+	assert.Equal(t, "#!/bin/sh\nexec '/opt/it'\\''s here/compressvideo' quick \"$@\"\n", string(data))
+	if runtime.GOOS != "windows" {
+		info, _ := os.Stat(script)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+
+	// The workflow is a valid property list running the command
+	workflow := filepath.Join(dir, "Services", "Compress with CompressVideo.workflow")
+	assert.NoError(t, os.MkdirAll(filepath.Join(workflow, "Contents", "stale"), 0755))
+	assert.NoError(t, installQuickAction(MacOS, workflow, "/Applications/A&B/compressvideo"))
+	_, err := os.Stat(filepath.Join(workflow, "Contents", "stale"))
+	assert.True(t, os.IsNotExist(err))
+	for _, name := range []string{"Info.plist", "document.wflow"} {
+		data, err := os.ReadFile(filepath.Join(workflow, "Contents", name))
+		assert.NoError(t, err)
+		decoder := xml.NewDecoder(strings.NewReader(string(data)))
+		for err == nil {
+			_, err = decoder.Token()
+		}
+		assert.Equal(t, io.EOF, err, name)
+	}
+	data, _ = os.ReadFile(filepath.Join(workflow, "Contents", "document.wflow"))
+	assert.Contains(t, string(data), "<string>'/Applications/A&amp;B/compressvideo' quick \"$@\"</string>")
+}
@@ -0,0 +1,110 @@
+package util
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jobDirPrefix starts the name of every per-job temporary directory, which
+// is followed by the owning process ID and a timestamp: job-1234-1700000000
+const jobDirPrefix = "job-"
+
+// TempRoot returns the directory under which every job keeps its temporary
+// files (segments, VMAF probes, two-pass logs, staged downloads)
+func TempRoot() string {
+	return LongPath(filepath.Join(os.TempDir(), "compressvideo"))
+}
+
+// NewJobTempDir creates a temporary directory for one job under TempRoot.
+// The directory is named after the current process, so CleanTempFiles can
+// tell the directories of running jobs from those left behind by crashes.
+func NewJobTempDir() (string, error) {
+	root := TempRoot()
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", err
+	}
+	pattern := fmt.Sprintf("%s%d-%d-", jobDirPrefix, os.Getpid(), time.Now().Unix())
+	return os.MkdirTemp(root, pattern)
+}
+
+// jobDirOwner returns the process ID in the name of a job directory
+func jobDirOwner(name string) (int, bool) {
+	if !strings.HasPrefix(name, jobDirPrefix) {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.SplitN(strings.TrimPrefix(name, jobDirPrefix), "-", 2)[0])
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// StaleTempFile is a temporary file or directory that CleanTempFiles found
+type StaleTempFile struct {
+	Path string
+	Size int64
+}
+
+// CleanTempFiles removes the temporary files that no running job uses: job
+// directories whose process has exited, anything else under TempRoot older
+// than maxAge (left by older versions), and an FFmpeg download interrupted
+// more than maxAge ago. With dryRun set, nothing is removed.
+func CleanTempFiles(maxAge time.Duration, dryRun bool) ([]StaleTempFile, error) {
+	var stale []StaleTempFile
+	cutoff := time.Now().Add(-maxAge)
+
+	entries, err := os.ReadDir(TempRoot())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if pid, ok := jobDirOwner(entry.Name()); ok {
+			if pid == os.Getpid() || processRunning(pid) {
+				continue
+			}
+		} else if info.ModTime().After(cutoff) {
+			continue
+		}
+		stale = append(stale, StaleTempFile{Path: filepath.Join(TempRoot(), entry.Name())})
+	}
+
+	downloadDir := filepath.Join(getFFmpegDir(), "temp")
+	if info, err := os.Stat(downloadDir); err == nil && info.ModTime().Before(cutoff) {
+		stale = append(stale, StaleTempFile{Path: downloadDir})
+	}
+
+	for i := range stale {
+		stale[i].Size = diskUsage(stale[i].Path)
+		if dryRun {
+			continue
+		}
+		if err := os.RemoveAll(stale[i].Path); err != nil {
+			return stale[:i], err
+		}
+	}
+	return stale, nil
+}
+
+// diskUsage returns the total size of the files under path
+func diskUsage(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
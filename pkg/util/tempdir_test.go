@@ -0,0 +1,69 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobDirOwner(t *testing.T) {
+	pid, ok := jobDirOwner("job-4242-1700000000-123456")
+	assert.True(t, ok)
+	assert.Equal(t, 4242, pid)
+
+	_, ok = jobDirOwner("segments_1700000000")
+	assert.False(t, ok)
+	_, ok = jobDirOwner("job-abc-1700000000")
+	assert.False(t, ok)
+}
+
+func TestCleanTempFiles(t *testing.T) {
+	tmp := t.TempDir()
+	for _, name := range []string{"TMPDIR", "TMP", "TEMP", "HOME", "USERPROFILE"} {
+		t.Setenv(name, tmp)
+	}
+
+	running, err := NewJobTempDir()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(filepath.Base(running), "job-"))
+
+	root := TempRoot()
+	crashed := filepath.Join(root, "job-999999999-1700000000-1")
+	legacy := filepath.Join(root, "segments_1700000000")
+	recent := filepath.Join(root, "vmaf_1700000001")
+	for _, dir := range []string{crashed, legacy, recent} {
+		assert.NoError(t, os.MkdirAll(dir, 0755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(crashed, "out_0000.mp4"), make([]byte, 1024), 0644))
+	old := time.Now().Add(-48 * time.Hour)
+	assert.NoError(t, os.Chtimes(legacy, old, old))
+
+	stale, err := CleanTempFiles(24*time.Hour, true)
+	assert.NoError(t, err)
+	assert.Len(t, stale, 2)
+	assert.DirExists(t, crashed)
+
+	stale, err = CleanTempFiles(24*time.Hour, false)
+	assert.NoError(t, err)
+	paths := []string{}
+	for _, file := range stale {
+		paths = append(paths, file.Path)
+		if file.Path == crashed {
+			assert.Equal(t, int64(1024), file.Size)
+		}
+	}
+	assert.ElementsMatch(t, []string{crashed, legacy}, paths)
+
+	_, err = os.Stat(crashed)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(legacy)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(running)
+	assert.NoError(t, err)
+	_, err = os.Stat(recent)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,15 @@
+package util
+
+import (
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// IsTerminal reports whether f is an interactive terminal, including the
+// Cygwin and MSYS2 terminals on Windows, rather than a pipe, a file or a
+// container log
+func IsTerminal(f *os.File) bool {
+	fd := f.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
@@ -1,16 +1,90 @@
 package util
 
+import (
+	"runtime"
+	"runtime/debug"
+)
+
 // Version information
 const (
 	// AppName is the name of the application
 	AppName = "CompressVideo"
 	// Version is the current version of the application
 	Version = "1.5.6"
+)
+
+// Build metadata, set at link time by the Makefile:
+//
+//	-ldflags "-X github.com/cccarv82/compressvideo/pkg/util.GitCommit=... -X github.com/cccarv82/compressvideo/pkg/util.BuildDate=..."
+//
+// Binaries built without them fall back to the VCS information Go embeds.
+var (
+	// GitCommit is the commit the application was built from
+	GitCommit = ""
 	// BuildDate is the date the application was built
-	BuildDate = "development"
+	BuildDate = ""
 )
 
+// BuildInfo describes the build of the running binary
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`     // Empty when unknown
+	Date      string `json:"build_date,omitempty"` // Empty when unknown
+	Modified  bool   `json:"modified,omitempty"`   // Built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"` // GOOS/GOARCH
+}
+
+// GetBuildInfo returns the build metadata of the running binary
+func GetBuildInfo() BuildInfo {
+	info := BuildInfo{
+		Version:   Version,
+		Commit:    GitCommit,
+		Date:      BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.time":
+				if info.Date == "" {
+					info.Date = setting.Value
+				}
+			case "vcs.modified":
+				info.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if len(info.Commit) > 12 {
+		info.Commit = info.Commit[:12]
+	}
+	return info
+}
+
+// String formats the build for the version line, e.g.
+// "CompressVideo v1.5.6 (3f66a79, 2026-10-17)"
+func (b BuildInfo) String() string {
+	details := "development"
+	if b.Commit != "" {
+		details = b.Commit
+		if b.Modified {
+			details += "-dirty"
+		}
+		if b.Date != "" {
+			details += ", " + b.Date
+		}
+	} else if b.Date != "" {
+		details = b.Date
+	}
+	return AppName + " v" + b.Version + " (" + details + ")"
+}
+
 // GetVersionInfo returns a formatted string with version information
 func GetVersionInfo() string {
-	return AppName + " v" + Version + " (" + BuildDate + ")"
-} 
\ No newline at end of file
+	return GetBuildInfo().String()
+}
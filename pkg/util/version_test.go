@@ -0,0 +1,37 @@
+package util
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGetBuildInfo tests that link-time metadata is reported
+func TestGetBuildInfo(t *testing.T) {
+	defer func(commit, date string) { GitCommit, BuildDate = commit, date }(GitCommit, BuildDate)
+	GitCommit, BuildDate = "3f66a79e5d4c3b2a", "2026-10-17T12:00:00Z"
+
+	info := GetBuildInfo()
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, "3f66a79e5d4c", info.Commit)
+	assert.Equal(t, "2026-10-17T12:00:00Z", info.Date)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+}
+
+// TestBuildInfoString tests the version line
+func TestBuildInfoString(t *testing.T) {
+	info := BuildInfo{Version: "1.5.6"}
+	assert.Equal(t, "CompressVideo v1.5.6 (development)", info.String())
+
+	info.Date = "2026-10-17"
+	assert.Equal(t, "CompressVideo v1.5.6 (2026-10-17)", info.String())
+
+	info.Commit = "3f66a79"
+	assert.Equal(t, "CompressVideo v1.5.6 (3f66a79, 2026-10-17)", info.String())
+
+	info.Modified = true
+	info.Date = ""
+	assert.Equal(t, "CompressVideo v1.5.6 (3f66a79-dirty)", info.String())
+}